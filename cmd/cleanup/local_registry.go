@@ -166,6 +166,25 @@ func (cmd *localRegistryCmd) RunCleanupLocalRegistry(f factory.Factory, cobraCmd
 		}
 	}
 
+	// the cache service only exists if the cache was enabled, the secrets and the node config only
+	// exist if the registry was secured, so they are deleted without checking for them first
+	err = client.KubeClient().CoreV1().Services(options.Namespace).Delete(ctx, options.CacheServiceName(), v1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrap(err, "clean up cache service")
+	}
+
+	err = client.KubeClient().AppsV1().DaemonSets(options.Namespace).Delete(ctx, options.NodeConfigName(), v1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return errors.Wrap(err, "clean up node config")
+	}
+
+	for _, secretName := range []string{options.TLSSecretName(), options.AuthSecretName()} {
+		err = client.KubeClient().CoreV1().Secrets(options.Namespace).Delete(ctx, secretName, v1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrap(err, "clean up secret")
+		}
+	}
+
 	log.Donef("Successfully cleaned up local registry")
 	return nil
 }
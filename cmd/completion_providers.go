@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultPipelines are the pipelines that can always be run, even if they are not defined in the devspace.yaml
+var defaultPipelines = []string{"build", "deploy", "dev", "purge"}
+
+// registerCompletions registers the dynamic shell completions for all commands that take names
+// from the devspace.yaml, such as pipelines, dependencies and profiles
+func registerCompletions(rootCmd *cobra.Command, rawConfig *RawConfig) {
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", completeNames(rawConfig.profileNames))
+
+	flagCompletions := map[string]func() []string{
+		"dependency":      rawConfig.dependencyNames,
+		"skip-dependency": rawConfig.dependencyNames,
+		"dependency-tag":  rawConfig.dependencyTags,
+		"pipeline":        rawConfig.pipelineNames,
+		"label-selector":  rawConfig.labelSelectors,
+		"image-selector":  rawConfig.imageSelectors,
+	}
+	walkCommands(rootCmd, func(cmd *cobra.Command) {
+		for flagName, names := range flagCompletions {
+			if cmd.Flags().Lookup(flagName) != nil {
+				_ = cmd.RegisterFlagCompletionFunc(flagName, completeNames(names))
+			}
+		}
+
+		// the pipelines of the devspace.yaml are already completed as sub commands
+		if cmd.Name() == "run-pipeline" && cmd.ValidArgsFunction == nil {
+			cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				if len(args) > 0 {
+					return nil, cobra.ShellCompDirectiveNoFileComp
+				}
+
+				return completeNames(rawConfig.defaultPipelineNames)(cmd, args, toComplete)
+			}
+		}
+	})
+}
+
+func walkCommands(cmd *cobra.Command, fn func(cmd *cobra.Command)) {
+	fn(cmd)
+	for _, child := range cmd.Commands() {
+		walkCommands(child, fn)
+	}
+}
+
+// completeNames returns a completion function for the given names. Comma separated flag
+// values are supported, so that string slice flags can be completed as well
+func completeNames(names func() []string) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		prefix := ""
+		if index := strings.LastIndex(toComplete, ","); index != -1 {
+			prefix = toComplete[:index+1]
+			toComplete = toComplete[index+1:]
+		}
+
+		completions := []string{}
+		for _, name := range names() {
+			if strings.HasPrefix(name, toComplete) {
+				completions = append(completions, prefix+name)
+			}
+		}
+
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func (r *RawConfig) profileNames() []string {
+	if r == nil {
+		return nil
+	}
+
+	profiles, _ := r.OriginalRawConfig["profiles"].([]interface{})
+	names := []string{}
+	for _, profile := range profiles {
+		profileMap, _ := profile.(map[string]interface{})
+		if name, ok := profileMap["name"].(string); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func (r *RawConfig) pipelineNames() []string {
+	names := map[string]bool{}
+	for _, name := range defaultPipelines {
+		names[name] = true
+	}
+	if r != nil && r.Config != nil {
+		for name := range r.Config.Pipelines {
+			names[name] = true
+		}
+	}
+
+	return sortedKeys(names)
+}
+
+// defaultPipelineNames returns the default pipelines that are not overwritten in the devspace.yaml
+func (r *RawConfig) defaultPipelineNames() []string {
+	names := []string{}
+	for _, name := range defaultPipelines {
+		if r == nil || r.Config == nil || r.Config.Pipelines[name] == nil {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// dependencyNames returns the names of the dependencies of the devspace.yaml and the names of
+// their dependencies, if they were already downloaded
+func (r *RawConfig) dependencyNames() []string {
+	if r == nil || r.ConfigPath == "" {
+		return nil
+	}
+
+	names := map[string]bool{}
+	collectDependencyNames(r.rawConfig(), filepath.Dir(r.ConfigPath), names, map[string]bool{})
+	return sortedKeys(names)
+}
+
+func collectDependencyNames(rawConfig map[string]interface{}, basePath string, names map[string]bool, visited map[string]bool) {
+	dependencies, _ := rawConfig["dependencies"].(map[string]interface{})
+	for name, rawDependency := range dependencies {
+		names[name] = true
+
+		// check if the dependency was already resolved before
+		out, err := yaml.Marshal(rawDependency)
+		if err != nil {
+			continue
+		}
+		dependency := &latest.DependencyConfig{}
+		err = yaml.Unmarshal(out, dependency)
+		if err != nil || dependency.Source == nil || (dependency.Source.Git == "" && dependency.Source.Path == "" && dependency.Source.OCI == "" && dependency.Source.Helm == nil) {
+			continue
+		}
+
+		configPath, err := util.GetDependencyPath(basePath, dependency.Source)
+		if err != nil || visited[configPath] {
+			continue
+		}
+		visited[configPath] = true
+
+		out, err = os.ReadFile(configPath)
+		if err != nil {
+			continue
+		}
+		dependencyConfig := map[string]interface{}{}
+		err = yaml.Unmarshal(out, &dependencyConfig)
+		if err != nil {
+			continue
+		}
+
+		collectDependencyNames(dependencyConfig, filepath.Dir(configPath), names, visited)
+	}
+}
+
+// dependencyTags returns the tags of the dependencies of the devspace.yaml
+func (r *RawConfig) dependencyTags() []string {
+	names := map[string]bool{}
+	for _, dependency := range r.rawMap("dependencies") {
+		dependencyMap, _ := dependency.(map[string]interface{})
+		tags, _ := dependencyMap["tags"].([]interface{})
+		for _, tag := range tags {
+			if tagStr, ok := tag.(string); ok && tagStr != "" {
+				names[tagStr] = true
+			}
+		}
+	}
+
+	return sortedKeys(names)
+}
+
+// labelSelectors returns the label selectors of the dev configurations
+func (r *RawConfig) labelSelectors() []string {
+	names := map[string]bool{}
+	for _, devPod := range r.rawMap("dev") {
+		devPodMap, _ := devPod.(map[string]interface{})
+		labelSelector, _ := devPodMap["labelSelector"].(map[string]interface{})
+		if len(labelSelector) == 0 {
+			continue
+		}
+
+		labels := []string{}
+		for key, value := range labelSelector {
+			if valueStr, ok := value.(string); ok {
+				labels = append(labels, key+"="+valueStr)
+			}
+		}
+		sort.Strings(labels)
+		names[strings.Join(labels, ",")] = true
+	}
+
+	return sortedKeys(names)
+}
+
+// imageSelectors returns the image selectors of the dev configurations and the images
+func (r *RawConfig) imageSelectors() []string {
+	names := map[string]bool{}
+	for _, devPod := range r.rawMap("dev") {
+		devPodMap, _ := devPod.(map[string]interface{})
+		if imageSelector, ok := devPodMap["imageSelector"].(string); ok && imageSelector != "" {
+			names[imageSelector] = true
+		}
+	}
+	for _, image := range r.rawMap("images") {
+		imageMap, _ := image.(map[string]interface{})
+		if imageName, ok := imageMap["image"].(string); ok && imageName != "" {
+			names[imageName] = true
+		}
+	}
+
+	return sortedKeys(names)
+}
+
+func (r *RawConfig) rawConfig() map[string]interface{} {
+	if r == nil {
+		return nil
+	} else if r.RawConfig != nil {
+		return r.RawConfig
+	}
+
+	return r.OriginalRawConfig
+}
+
+func (r *RawConfig) rawMap(key string) map[string]interface{} {
+	rawMap, _ := r.rawConfig()[key].(map[string]interface{})
+	return rawMap
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
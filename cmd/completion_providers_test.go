@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/spf13/cobra"
+	"gotest.tools/assert"
+)
+
+func TestCompletionProviders(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "backend"), 0755)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "backend", "devspace.yaml"), []byte(`version: v2beta1
+dependencies:
+  database:
+    path: ../database
+  frontend:
+    path: ..
+`), 0644)
+	assert.NilError(t, err)
+
+	rawConfig := &RawConfig{
+		ConfigPath: filepath.Join(dir, "devspace.yaml"),
+		OriginalRawConfig: map[string]interface{}{
+			"profiles": []interface{}{
+				map[string]interface{}{"name": "production"},
+				map[string]interface{}{"name": "staging"},
+			},
+		},
+		RawConfig: map[string]interface{}{
+			"dependencies": map[string]interface{}{
+				"backend": map[string]interface{}{
+					"path": "./backend",
+				},
+				"api": map[string]interface{}{
+					"git":  "https://github.com/loft-sh/does-not-exist",
+					"tags": []interface{}{"backend", "infra"},
+				},
+			},
+			"dev": map[string]interface{}{
+				"app": map[string]interface{}{
+					"labelSelector": map[string]interface{}{
+						"tier": "web",
+						"app":  "frontend",
+					},
+				},
+				"worker": map[string]interface{}{
+					"imageSelector": "worker:latest",
+				},
+			},
+		},
+		Config: &latest.Config{
+			Pipelines: map[string]*latest.Pipeline{
+				"dev":       {Name: "dev"},
+				"integrate": {Name: "integrate"},
+			},
+		},
+	}
+
+	assert.DeepEqual(t, rawConfig.profileNames(), []string{"production", "staging"})
+	assert.DeepEqual(t, rawConfig.dependencyNames(), []string{"api", "backend", "database", "frontend"})
+	assert.DeepEqual(t, rawConfig.pipelineNames(), []string{"build", "deploy", "dev", "integrate", "purge"})
+	assert.DeepEqual(t, rawConfig.defaultPipelineNames(), []string{"build", "deploy", "purge"})
+	assert.DeepEqual(t, rawConfig.dependencyTags(), []string{"backend", "infra"})
+	assert.DeepEqual(t, rawConfig.labelSelectors(), []string{"app=frontend,tier=web"})
+	assert.DeepEqual(t, rawConfig.imageSelectors(), []string{"worker:latest"})
+
+	completions, directive := completeNames(rawConfig.dependencyNames)(&cobra.Command{}, nil, "api,b")
+	assert.DeepEqual(t, completions, []string{"api,backend"})
+	assert.Equal(t, directive, cobra.ShellCompDirectiveNoFileComp)
+
+	// completions without a config
+	var noConfig *RawConfig
+	assert.Equal(t, len(noConfig.dependencyNames()), 0)
+	assert.DeepEqual(t, noConfig.defaultPipelineNames(), []string{"build", "deploy", "dev", "purge"})
+}
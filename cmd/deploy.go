@@ -31,11 +31,13 @@ Deploys the current project to a Space or namespace:
 devspace deploy
 devspace deploy -n some-namespace
 devspace deploy --kube-context=deploy-context
+devspace deploy --history
 #######################################################`,
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			return cmd.Run(cobraCmd, args, f, "deployCommand")
 		},
 	}
 	cmd.AddPipelineFlags(f, deployCmd, pipeline)
+	deployCmd.Flags().BoolVar(&cmd.History, "history", cmd.History, "If true will print who deployed the deployments when and what instead of deploying them")
 	return deployCmd
 }
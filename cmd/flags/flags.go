@@ -13,10 +13,14 @@ type GlobalFlags struct {
 	DisableProfileActivation bool
 	SwitchContext            bool
 	InactivityTimeout        int
+	ProfileReport            string
 	KubeConfig               string
+	DockerContext            string
 	OverrideName             string
 	Namespace                string
 	KubeContext              string
+	Impersonate              string
+	ImpersonateGroups        []string
 	ConfigPath               string
 	Profiles                 []string
 	Vars                     []string
@@ -55,7 +59,11 @@ func SetGlobalFlags(flags *flag.FlagSet) *GlobalFlags {
 	flags.StringVar(&globalFlags.KubeContext, "kube-context", "", "The kubernetes context to use")
 	flags.StringSliceVar(&globalFlags.Vars, "var", []string{}, "Variables to override during execution (e.g. --var=MYVAR=MYVALUE)")
 	flags.StringVar(&globalFlags.KubeConfig, "kubeconfig", "", "The kubeconfig path to use")
+	flags.StringVar(&globalFlags.DockerContext, "docker-context", "", "The docker context to use for the docker client")
+	flags.StringVar(&globalFlags.Impersonate, "as", "", "Username to impersonate for the kubernetes operations")
+	flags.StringSliceVar(&globalFlags.ImpersonateGroups, "as-group", []string{}, "Groups to impersonate for the kubernetes operations, can be repeated to specify multiple groups")
 
+	flags.StringVar(&globalFlags.ProfileReport, "profile-report", "", "If set, DevSpace will time the major phases of the command and write a json and flamegraph (folded stacks) performance report to the given path")
 	flags.IntVar(&globalFlags.InactivityTimeout, "inactivity-timeout", 0, "Minutes the current user is inactive (no mouse or keyboard interaction) until DevSpace will exit automatically. 0 to disable. Only supported on windows and mac operating systems")
 	flags.AddFlag(&flag.Flag{
 		Name:   "config",
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kustomize"
+	"github.com/spf13/cobra"
+)
+
+// HelmPostRendererCmd holds the cmd flags
+type HelmPostRendererCmd struct {
+	KubectlPath   string
+	KustomizePath string
+}
+
+// NewHelmPostRendererCmd creates the command helm runs as post renderer for the kustomize post renderer of a
+// helm deployment
+func NewHelmPostRendererCmd() *cobra.Command {
+	cmd := &HelmPostRendererCmd{}
+	helmPostRendererCmd := &cobra.Command{
+		Use:    "helm-post-renderer",
+		Short:  "Applies a kustomize overlay to the chart helm rendered",
+		Hidden: true,
+		Args:   cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return kustomize.PostRender(context.Background(), args[0], cmd.KubectlPath, cmd.KustomizePath, os.Stdin, os.Stdout, os.Stderr)
+		},
+	}
+
+	helmPostRendererCmd.Flags().StringVar(&cmd.KubectlPath, "kubectl", "kubectl", "The kubectl binary to build the overlay with")
+	helmPostRendererCmd.Flags().StringVar(&cmd.KustomizePath, "kustomize", "", "The kustomize binary to build the overlay with instead of kubectl")
+	return helmPostRendererCmd
+}
@@ -0,0 +1,106 @@
+package list
+
+import (
+	"context"
+	"strings"
+
+	"github.com/loft-sh/devspace/cmd/flags"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/util/factory"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/message"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type dependenciesCmd struct {
+	*flags.GlobalFlags
+}
+
+func newDependenciesCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &dependenciesCmd{GlobalFlags: globalFlags}
+
+	return &cobra.Command{
+		Use:   "dependencies",
+		Short: "Lists all dependencies and if they have changed since the last deployment",
+		Long: `
+#######################################################
+############ devspace list dependencies ###############
+#######################################################
+Lists all dependencies and shows if they are up-to-date,
+out-of-date or not deployed
+#######################################################
+	`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.RunListDependencies(f, cobraCmd, args)
+		}}
+}
+
+// RunListDependencies executes the devspace list dependencies command logic
+func (cmd *dependenciesCmd) RunListDependencies(f factory.Factory, cobraCmd *cobra.Command, args []string) error {
+	// Set config root
+	logger := f.GetLog()
+	configOptions := cmd.ToConfigOptions()
+	configLoader, err := f.NewConfigLoader(cmd.ConfigPath)
+	if err != nil {
+		return err
+	}
+	configExists, err := configLoader.SetDevSpaceRoot(logger)
+	if err != nil {
+		return err
+	}
+	if !configExists {
+		return errors.New(message.ConfigNotFound)
+	}
+
+	// Create new kube client
+	client, err := f.NewKubeClientFromContext(cmd.KubeContext, cmd.Namespace)
+	if err != nil {
+		return err
+	}
+
+	// Load generated
+	localCache, err := configLoader.LoadLocalCache()
+	if err != nil {
+		return err
+	}
+
+	// If the current kube context or namespace is different from old,
+	// show warnings and reset kube client if necessary
+	client, err = kubectl.CheckKubeContext(client, localCache, cmd.NoWarn, cmd.SwitchContext, false, logger)
+	if err != nil {
+		return err
+	}
+
+	// Get config with adjusted cluster config
+	configInterface, err := configLoader.LoadWithCache(context.Background(), localCache, client, configOptions, logger)
+	if err != nil {
+		return err
+	}
+
+	// Create context
+	ctx := devspacecontext.NewContext(context.Background(), configInterface.Variables(), logger).
+		WithConfig(configInterface).
+		WithKubeClient(client)
+
+	statuses, err := f.NewDependencyManager(ctx, configOptions).StatusAll(ctx, dependency.ResolveOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+
+	values := [][]string{}
+	for _, status := range statuses {
+		values = append(values, []string{
+			status.Name,
+			string(status.State),
+			strings.Join(status.Reasons, ", "),
+		})
+	}
+
+	logpkg.PrintTable(logger, []string{"NAME", "STATUS", "CHANGES"}, values)
+	return nil
+}
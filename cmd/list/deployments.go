@@ -3,11 +3,15 @@ package list
 import (
 	"context"
 	"github.com/loft-sh/devspace/cmd/flags"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
 	deployHelm "github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/helm"
 	deployKubectl "github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kubectl"
+	deployKustomize "github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kustomize"
+	deployPlugin "github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/plugin"
 	"github.com/loft-sh/devspace/pkg/devspace/helm"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/loft-sh/devspace/pkg/util/factory"
@@ -97,7 +101,7 @@ func (cmd *deploymentsCmd) RunDeploymentsStatus(f factory.Factory, cobraCmd *cob
 		WithKubeClient(client)
 
 	// Resolve dependencies
-	dependencies, err := f.NewDependencyManager(ctx, configOptions).ResolveAll(ctx, dependency.ResolveOptions{})
+	dependencies, err := f.NewDependencyManager(ctx, configOptions).ResolveAll(ctx, dependency.ResolveOptions{ReadOnly: true})
 	if err != nil {
 		return err
 	}
@@ -105,47 +109,91 @@ func (cmd *deploymentsCmd) RunDeploymentsStatus(f factory.Factory, cobraCmd *cob
 
 	if ctx.Config().Config().Deployments != nil {
 		for _, deployConfig := range ctx.Config().Config().Deployments {
-			var deployClient deployer.Interface
-
-			// Delete kubectl engine
-			if deployConfig.Kubectl != nil {
-				deployClient, err = deployKubectl.New(ctx, deployConfig)
-				if err != nil {
-					logger.Warnf("Unable to create kubectl deploy config for %s: %v", deployConfig.Name, err)
-					continue
+			// deployments to other kube contexts show the status of every kube context
+			kubeContexts := deploy.KubeContexts(deployConfig)
+			if len(kubeContexts) == 0 {
+				status, ok := deploymentStatus(ctx, deployConfig)
+				if ok {
+					values = append(values, status)
 				}
-			} else if deployConfig.Helm != nil {
-				helmClient, err := helm.NewClient(logger)
-				if err != nil {
-					logger.Warnf("Unable to create helm deploy config for %s: %v", deployConfig.Name, err)
-					continue
-				}
-
-				deployClient, err = deployHelm.New(helmClient, deployConfig)
-				if err != nil {
-					logger.Warnf("Unable to create helm deploy config for %s: %v", deployConfig.Name, err)
-					continue
-				}
-			} else {
-				logger.Warnf("No deployment method defined for deployment %s", deployConfig.Name)
 				continue
 			}
 
-			status, err := deployClient.Status(ctx)
-			if err != nil {
-				logger.Warnf("Error retrieving status for deployment %s: %v", deployConfig.Name, err)
-				continue
-			}
+			for _, kubeContext := range kubeContexts {
+				targetCtx := ctx
+				if client.CurrentContext() != kubeContext {
+					targetCtx, _, err = deploy.WithKubeContext(ctx, kubeContext, deployConfig.Namespace)
+					if err != nil {
+						logger.Warnf("Error retrieving status for deployment %s in kube context %s: %v", deployConfig.Name, kubeContext, err)
+						continue
+					}
+				}
 
-			values = append(values, []string{
-				status.Name,
-				status.Type,
-				status.Target,
-				status.Status,
-			})
+				status, ok := deploymentStatus(targetCtx, deployConfig)
+				if ok {
+					status[0] += " (" + kubeContext + ")"
+					values = append(values, status)
+				}
+			}
 		}
 	}
 
 	logpkg.PrintTable(logger, headerValues, values)
 	return nil
 }
+
+// deploymentStatus returns the status row of the deployment in the cluster of the context
+func deploymentStatus(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig) ([]string, bool) {
+	var (
+		logger       = ctx.Log()
+		deployClient deployer.Interface
+		err          error
+	)
+	if deployConfig.Kubectl != nil {
+		deployClient, err = deployKubectl.New(ctx, deployConfig)
+		if err != nil {
+			logger.Warnf("Unable to create kubectl deploy config for %s: %v", deployConfig.Name, err)
+			return nil, false
+		}
+	} else if deployConfig.Helm != nil {
+		helmClient, err := helm.NewClient(logger)
+		if err != nil {
+			logger.Warnf("Unable to create helm deploy config for %s: %v", deployConfig.Name, err)
+			return nil, false
+		}
+
+		deployClient, err = deployHelm.New(helmClient, deployConfig)
+		if err != nil {
+			logger.Warnf("Unable to create helm deploy config for %s: %v", deployConfig.Name, err)
+			return nil, false
+		}
+	} else if deployConfig.Kustomize != nil {
+		deployClient, err = deployKustomize.New(ctx, deployConfig)
+		if err != nil {
+			logger.Warnf("Unable to create kustomize deploy config for %s: %v", deployConfig.Name, err)
+			return nil, false
+		}
+	} else if deployConfig.Plugin != nil {
+		deployClient, err = deployPlugin.New(ctx, deployConfig)
+		if err != nil {
+			logger.Warnf("Unable to create plugin deploy config for %s: %v", deployConfig.Name, err)
+			return nil, false
+		}
+	} else {
+		logger.Warnf("No deployment method defined for deployment %s", deployConfig.Name)
+		return nil, false
+	}
+
+	status, err := deployClient.Status(ctx)
+	if err != nil {
+		logger.Warnf("Error retrieving status for deployment %s: %v", deployConfig.Name, err)
+		return nil, false
+	}
+
+	return []string{
+		status.Name,
+		status.Type,
+		status.Target,
+		status.Status,
+	}, true
+}
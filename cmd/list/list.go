@@ -25,6 +25,7 @@ func NewListCmd(f factory.Factory, globalFlags *flags.GlobalFlags, plugins []plu
 	listCmd.AddCommand(newProfilesCmd(f))
 	listCmd.AddCommand(newVarsCmd(f, globalFlags))
 	listCmd.AddCommand(newDeploymentsCmd(f, globalFlags))
+	listCmd.AddCommand(newDependenciesCmd(f, globalFlags))
 	listCmd.AddCommand(newContextsCmd(f))
 	listCmd.AddCommand(newPluginsCmd(f))
 	listCmd.AddCommand(newCommandsCmd(f, globalFlags))
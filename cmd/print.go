@@ -103,7 +103,7 @@ func (cmd *PrintCmd) Run(f factory.Factory) error {
 		WithKubeClient(client)
 
 	// resolve dependencies
-	dependencies, err := dependency.NewManagerWithParser(ctx, configOptions, parser).ResolveAll(ctx, dependency.ResolveOptions{})
+	dependencies, err := dependency.NewManagerWithParser(ctx, configOptions, parser).ResolveAll(ctx, dependency.ResolveOptions{ReadOnly: true})
 	if err != nil {
 		log.Warnf("Error resolving dependencies: %v", err)
 	}
@@ -36,5 +36,6 @@ devspace purge
 		},
 	}
 	cmd.AddPipelineFlags(f, purgeCmd, pipeline)
+	purgeCmd.Flags().BoolVar(&cmd.PurgeWithChildren, "with-children", cmd.PurgeWithChildren, "If used with --dependency, will also purge all child dependencies of the specified dependencies that are not used by other dependencies")
 	return purgeCmd
 }
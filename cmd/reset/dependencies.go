@@ -1,21 +1,28 @@
 package reset
 
 import (
+	"context"
 	"os"
 
+	"github.com/loft-sh/devspace/cmd/flags"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency"
 	dependencyutil "github.com/loft-sh/devspace/pkg/devspace/dependency/util"
-
 	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/loft-sh/devspace/pkg/util/message"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 type dependenciesCmd struct {
+	*flags.GlobalFlags
+
+	Hashes bool
 }
 
-func newDependenciesCmd(f factory.Factory) *cobra.Command {
-	cmd := &dependenciesCmd{}
+func newDependenciesCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &dependenciesCmd{GlobalFlags: globalFlags}
 
 	dependenciesCmd := &cobra.Command{
 		Use:   "dependencies",
@@ -24,23 +31,32 @@ func newDependenciesCmd(f factory.Factory) *cobra.Command {
 #######################################################
 ############ devspace reset dependencies ##############
 #######################################################
-Deletes the complete dependency cache
+Deletes the complete dependency cache. If dependency
+names or --hashes are given, only the stored image and
+deployment hashes of these dependencies are cleared,
+so that they are rebuilt and redeployed on the next run
 
 Examples:
 devspace reset dependencies
+devspace reset dependencies --hashes
+devspace reset dependencies backend database
 #######################################################
 	`,
-		Args: cobra.NoArgs,
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			return cmd.RunResetDependencies(f, cobraCmd, args)
 		}}
 
+	dependenciesCmd.Flags().BoolVar(&cmd.Hashes, "hashes", false, "Clears only the stored image and deployment hashes of all dependencies instead of deleting the downloaded dependencies")
 	return dependenciesCmd
 }
 
 // RunResetDependencies executes the reset dependencies command logic
 func (cmd *dependenciesCmd) RunResetDependencies(f factory.Factory, cobraCmd *cobra.Command, args []string) error {
 	log := f.GetLog()
+	if len(args) > 0 || cmd.Hashes {
+		return cmd.invalidateCache(f, args)
+	}
+
 	err := os.RemoveAll(dependencyutil.DependencyFolderPath)
 	if err != nil {
 		return errors.Wrapf(err, "delete %s", dependencyutil.DependencyFolderPath)
@@ -49,3 +65,45 @@ func (cmd *dependenciesCmd) RunResetDependencies(f factory.Factory, cobraCmd *co
 	log.Done("Successfully reseted the dependency cache")
 	return nil
 }
+
+func (cmd *dependenciesCmd) invalidateCache(f factory.Factory, names []string) error {
+	// Set config root
+	log := f.GetLog()
+	configOptions := cmd.ToConfigOptions()
+	configLoader, err := f.NewConfigLoader(cmd.ConfigPath)
+	if err != nil {
+		return err
+	}
+	configExists, err := configLoader.SetDevSpaceRoot(log)
+	if err != nil {
+		return err
+	} else if !configExists {
+		return errors.New(message.ConfigNotFound)
+	}
+
+	// create kubectl client
+	client, err := f.NewKubeClientFromContext(cmd.KubeContext, cmd.Namespace)
+	if err != nil {
+		log.Warnf("Unable to create new kubectl client, only the image hashes will be cleared: %v", err)
+		client = nil
+	}
+
+	// load config
+	config, err := configLoader.Load(context.Background(), client, configOptions, log)
+	if err != nil {
+		return err
+	}
+
+	// create devspace context
+	ctx := devspacecontext.NewContext(context.Background(), config.Variables(), log).
+		WithConfig(config).
+		WithKubeClient(client)
+
+	dependencies, err := f.NewDependencyManager(ctx, configOptions).InvalidateCache(ctx, dependency.ResolveOptions{}, names...)
+	if err != nil {
+		return err
+	}
+
+	log.Donef("Successfully cleared the hashes of %d dependencies", len(dependencies))
+	return nil
+}
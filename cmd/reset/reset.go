@@ -21,7 +21,7 @@ func NewResetCmd(f factory.Factory, globalFlags *flags.GlobalFlags, plugins []pl
 	}
 
 	resetCmd.AddCommand(newVarsCmd(f, globalFlags))
-	resetCmd.AddCommand(newDependenciesCmd(f))
+	resetCmd.AddCommand(newDependenciesCmd(f, globalFlags))
 	resetCmd.AddCommand(newPodsCmd(f, globalFlags))
 
 	// Add plugin commands
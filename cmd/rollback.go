@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/loft-sh/devspace/cmd/flags"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/message"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// RollbackCmd holds the required data for the cmd
+type RollbackCmd struct {
+	*flags.GlobalFlags
+
+	log log.Logger
+}
+
+// NewRollbackCmd creates a new rollback command
+func NewRollbackCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &RollbackCmd{
+		GlobalFlags: globalFlags,
+		log:         log.GetInstance(),
+	}
+
+	rollbackCmd := &cobra.Command{
+		Use:   "rollback [deployment]",
+		Short: "Reverts deployments to the revision before their last deploy",
+		Long: `
+#######################################################
+################## devspace rollback ##################
+#######################################################
+Reverts deployments to the revision before their last
+deploy. Helm releases are rolled back to their previous
+revision, kubectl deployments apply their previously
+applied manifests again:
+
+devspace rollback
+devspace rollback my-deployment
+#######################################################`,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			plugin.SetPluginCommand(cobraCmd, args)
+			return cmd.Run(f, args)
+		},
+	}
+
+	return rollbackCmd
+}
+
+// Run executes the rollback command logic
+func (cmd *RollbackCmd) Run(f factory.Factory, args []string) error {
+	// Set config root
+	cmd.log = f.GetLog()
+	configOptions := cmd.ToConfigOptions()
+	configLoader, err := f.NewConfigLoader(cmd.ConfigPath)
+	if err != nil {
+		return err
+	}
+	configExists, err := configLoader.SetDevSpaceRoot(cmd.log)
+	if err != nil {
+		return err
+	} else if !configExists {
+		return errors.New(message.ConfigNotFound)
+	}
+
+	log.StartFileLogging()
+
+	client, err := f.NewKubeClientFromContext(cmd.KubeContext, cmd.Namespace)
+	if err != nil {
+		return errors.Wrap(err, "create kube client")
+	}
+
+	localCache, err := configLoader.LoadLocalCache()
+	if err != nil {
+		return err
+	}
+
+	// If the current kube context or namespace is different from old,
+	// show warnings and reset kube client if necessary
+	client, err = kubectl.CheckKubeContext(client, localCache, cmd.NoWarn, cmd.SwitchContext, false, cmd.log)
+	if err != nil {
+		return err
+	}
+
+	// Get config with adjusted cluster config
+	config, err := configLoader.LoadWithCache(context.Background(), localCache, client, configOptions, cmd.log)
+	if err != nil {
+		return err
+	}
+
+	// Create context
+	ctx := devspacecontext.NewContext(context.Background(), config.Variables(), cmd.log).
+		WithConfig(config).
+		WithKubeClient(client)
+
+	return f.NewDeployController().Rollback(ctx, args)
+}
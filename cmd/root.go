@@ -17,8 +17,10 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/env"
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/message"
+	"github.com/loft-sh/devspace/pkg/util/timing"
 
 	"github.com/loft-sh/devspace/pkg/devspace/hook"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/loft-sh/devspace/pkg/util/interrupt"
 
 	"github.com/joho/godotenv"
@@ -32,6 +34,7 @@ import (
 	"github.com/loft-sh/devspace/cmd/update"
 	"github.com/loft-sh/devspace/cmd/use"
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader/variable"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine/pipelinehandler"
 	"github.com/loft-sh/devspace/pkg/devspace/plugin"
 	"github.com/loft-sh/devspace/pkg/devspace/upgrade"
 	"github.com/loft-sh/devspace/pkg/util/exit"
@@ -71,6 +74,15 @@ func NewRootCmd(f factory.Factory) *cobra.Command {
 					log.Errorf("Unable to set KUBECONFIG variable: %v", err)
 				}
 			}
+			if globalFlags.DockerContext != "" {
+				err := os.Setenv("DOCKER_CONTEXT", globalFlags.DockerContext)
+				if err != nil {
+					log.Errorf("Unable to set DOCKER_CONTEXT variable: %v", err)
+				}
+			}
+			if globalFlags.Impersonate != "" || len(globalFlags.ImpersonateGroups) > 0 {
+				kubectl.SetImpersonation(globalFlags.Impersonate, globalFlags.ImpersonateGroups)
+			}
 
 			// parse the .env file
 			envFile := env.GlobalGetEnv("DEVSPACE_ENV_FILE")
@@ -96,6 +108,11 @@ func NewRootCmd(f factory.Factory) *cobra.Command {
 					log.SetLevel(logrus.DebugLevel)
 				}
 
+				// start timing the command
+				if globalFlags.ProfileReport != "" {
+					timing.Enable()
+				}
+
 				// call inactivity timeout
 				if globalFlags.InactivityTimeout > 0 {
 					m, err := idle.NewIdleMonitor()
@@ -144,7 +161,12 @@ func Execute() {
 	}
 
 	// execute command
-	err := rootCmd.Execute()
+	writeReportOnce := sync.Once{}
+	err := interrupt.Global.RunAlways(rootCmd.Execute, func() {
+		writeReportOnce.Do(func() {
+			writeProfileReport(f.GetLog())
+		})
+	})
 
 	// after hooks
 	pluginErr = hook.ExecuteHooks(nil, map[string]interface{}{"error": err}, "root.afterExecute", "command:after:execute")
@@ -171,6 +193,21 @@ func Execute() {
 	}
 }
 
+// writeProfileReport writes the performance report if the --profile-report flag was used
+func writeProfileReport(log log.Logger) {
+	if globalFlags == nil || globalFlags.ProfileReport == "" || !timing.Enabled() {
+		return
+	}
+
+	err := timing.WriteReport(globalFlags.ProfileReport, strings.Join(os.Args[1:], " "))
+	if err != nil {
+		log.Warnf("Error writing performance report: %v", err)
+		return
+	}
+
+	log.Infof("Wrote performance report to %s", globalFlags.ProfileReport)
+}
+
 // BuildRoot creates a new root command from the
 func BuildRoot(f factory.Factory, excludePlugins bool) *cobra.Command {
 	// list plugins
@@ -185,6 +222,7 @@ func BuildRoot(f factory.Factory, excludePlugins bool) *cobra.Command {
 		}
 
 		plugin.SetPlugins(plugins)
+		pipelinehandler.RegisterPluginFunctions(f.GetLog())
 	}
 
 	// try to parse the raw config
@@ -268,10 +306,12 @@ Additional run commands:
 	rootCmd.AddCommand(set.NewSetCmd(f, globalFlags, plugins))
 	rootCmd.AddCommand(use.NewUseCmd(f, globalFlags, plugins))
 	rootCmd.AddCommand(update.NewUpdateCmd(f, globalFlags, plugins))
+	rootCmd.AddCommand(NewVendorCmd(f, globalFlags, plugins))
 
 	// Add main commands
 	rootCmd.AddCommand(NewInitCmd(f))
 	rootCmd.AddCommand(NewRestartCmd(f, globalFlags))
+	rootCmd.AddCommand(NewRollbackCmd(f, globalFlags))
 	rootCmd.AddCommand(NewSyncCmd(f, globalFlags))
 	rootCmd.AddCommand(NewRenderCmd(f, globalFlags, rawConfig))
 	rootCmd.AddCommand(NewUpgradeCmd())
@@ -281,11 +321,13 @@ Additional run commands:
 	rootCmd.AddCommand(NewOpenCmd(f, globalFlags))
 	rootCmd.AddCommand(NewUICmd(f, globalFlags))
 	rootCmd.AddCommand(NewRunCmd(f, globalFlags, rawConfig))
+	rootCmd.AddCommand(NewRunDependencyCmd(f, globalFlags))
 	rootCmd.AddCommand(NewAttachCmd(f, globalFlags))
 	rootCmd.AddCommand(NewPrintCmd(f, globalFlags))
 	rootCmd.AddCommand(NewRunPipelineCmd(f, globalFlags, rawConfig))
 	rootCmd.AddCommand(NewCompletionCmd())
 	rootCmd.AddCommand(NewVersionCmd())
+	rootCmd.AddCommand(NewHelmPostRendererCmd())
 
 	// check overwrite commands
 	rootCmd.AddCommand(NewDevCmd(f, globalFlags, rawConfig))
@@ -299,6 +341,9 @@ Additional run commands:
 	}
 	plugin.AddPluginCommands(rootCmd, plugins, "")
 	variable.AddPredefinedVars(plugins)
+
+	// Add dynamic completions for names from the config
+	registerCompletions(rootCmd, rawConfig)
 	return rootCmd
 }
 
@@ -341,7 +386,8 @@ func parseConfig(f factory.Factory) (*RawConfig, error) {
 	defer cancel()
 
 	r := &RawConfig{
-		resolved: map[string]string{},
+		ConfigPath: configLoader.ConfigPath(),
+		resolved:   map[string]string{},
 	}
 	_, err = configLoader.LoadWithParser(timeoutCtx, nil, nil, r, &loader.ConfigOptions{
 		Dry: true,
@@ -359,6 +405,9 @@ type RawConfig struct {
 	RawConfig         map[string]interface{}
 	Resolver          variable.Resolver
 
+	// ConfigPath is the absolute path of the devspace.yaml
+	ConfigPath string
+
 	Config *latest.Config
 
 	resolvedMutex sync.Mutex
@@ -128,29 +128,10 @@ func (cmd *RunCmd) RunRun(f factory.Factory, args []string) error {
 
 	// check if we should execute a dependency command
 	if cmd.Dependency != "" {
-		config, err := configLoader.LoadWithCache(context.Background(), ctx.Config().LocalCache(), nil, configOptions, f.GetLog())
+		ctx, err = cmd.loadDependencyContext(f, ctx, configLoader, configOptions, cmd.Dependency)
 		if err != nil {
 			return err
 		}
-
-		ctx = ctx.WithConfig(config)
-		dependencies, err := f.NewDependencyManager(ctx, configOptions).ResolveAll(ctx, dependency.ResolveOptions{})
-		if err != nil {
-			return err
-		}
-
-		dep := dependency.GetDependencyByPath(dependencies, cmd.Dependency)
-		if dep == nil {
-			return fmt.Errorf("couldn't find dependency %s", cmd.Dependency)
-		}
-
-		ctx = ctx.AsDependency(dep)
-		commandConfig, err := findCommand(ctx.Config(), args[0])
-		if err != nil {
-			return err
-		}
-
-		return executeCommandWithAfter(ctx.Context(), commandConfig, args[1:], ctx.Config().Variables(), ctx.WorkingDir(), cmd.Stdout, cmd.Stderr, os.Stdin, ctx.Log())
 	}
 
 	commandConfig, err := findCommand(ctx.Config(), args[0])
@@ -161,6 +142,28 @@ func (cmd *RunCmd) RunRun(f factory.Factory, args []string) error {
 	return executeCommandWithAfter(ctx.Context(), commandConfig, args[1:], ctx.Config().Variables(), ctx.WorkingDir(), cmd.Stdout, cmd.Stderr, os.Stdin, ctx.Log())
 }
 
+// loadDependencyContext resolves the dependencies and returns the context of the dependency
+// with the given path, e.g. api or api.database
+func (cmd *RunCmd) loadDependencyContext(f factory.Factory, ctx devspacecontext.Context, configLoader loader.ConfigLoader, configOptions *loader.ConfigOptions, dependencyPath string) (devspacecontext.Context, error) {
+	config, err := configLoader.LoadWithCache(context.Background(), ctx.Config().LocalCache(), nil, configOptions, f.GetLog())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = ctx.WithConfig(config)
+	dependencies, err := f.NewDependencyManager(ctx, configOptions).ResolveAll(ctx, dependency.ResolveOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	dep := dependency.GetDependencyByPath(dependencies, dependencyPath)
+	if dep == nil {
+		return nil, fmt.Errorf("couldn't find dependency %s", dependencyPath)
+	}
+
+	return ctx.AsDependency(dep), nil
+}
+
 func findCommand(config config.Config, name string) (*latest.CommandConfig, error) {
 	// Find command
 	if config.Config().Commands == nil || config.Config().Commands[name] == nil {
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/loft-sh/devspace/cmd/flags"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/hook"
+	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/loft-sh/devspace/pkg/util/message"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// RunDependencyCmd holds the run-dependency cmd flags
+type RunDependencyCmd struct {
+	*RunCmd
+}
+
+// NewRunDependencyCmd creates a new run-dependency command
+func NewRunDependencyCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &RunDependencyCmd{
+		RunCmd: &RunCmd{
+			GlobalFlags: globalFlags,
+			Stdout:      os.Stdout,
+			Stderr:      os.Stderr,
+		},
+	}
+
+	runDependencyCmd := &cobra.Command{
+		Use:   "run-dependency [dependency] -- [command] [args...]",
+		Short: "Executes a command in the folder of a dependency",
+		Long: `
+#######################################################
+############### devspace run-dependency ###############
+#######################################################
+Executes a command of the dependency or, if the dependency
+doesn't define a command with this name, any other program
+in the folder and with the variables of the dependency.
+The terminal is passed to the command, so prompts and
+interactive shells work as expected.
+
+Examples:
+devspace run-dependency api -- migrate
+devspace run-dependency api.database -- psql
+#######################################################
+	`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if dash := cobraCmd.ArgsLenAtDash(); dash != -1 && dash != 1 {
+				return errors.New("expected exactly one dependency before --")
+			}
+
+			return cmd.RunRunDependency(f, args[0], args[1:])
+		},
+	}
+
+	return runDependencyCmd
+}
+
+// RunRunDependency executes the functionality "devspace run-dependency"
+func (cmd *RunDependencyCmd) RunRunDependency(f factory.Factory, dependencyPath string, args []string) error {
+	// Execute plugin hook
+	err := hook.ExecuteHooks(nil, nil, "run")
+	if err != nil {
+		return err
+	}
+
+	// Set config root
+	configOptions := cmd.ToConfigOptions()
+	configLoader, err := f.NewConfigLoader(cmd.ConfigPath)
+	if err != nil {
+		return err
+	}
+	configExists, err := configLoader.SetDevSpaceRoot(f.GetLog())
+	if err != nil {
+		return err
+	} else if !configExists {
+		return errors.New(message.ConfigNotFound)
+	}
+
+	// load the config
+	ctx, err := cmd.LoadCommandsConfig(f, configLoader, configOptions, f.GetLog())
+	if err != nil {
+		return err
+	}
+	ctx, err = cmd.loadDependencyContext(f, ctx, configLoader, configOptions, dependencyPath)
+	if err != nil {
+		return err
+	}
+
+	return runDependencyCommand(ctx, args, cmd.Stdout, cmd.Stderr, os.Stdin)
+}
+
+// runDependencyCommand runs a command of the dependency or, if there is no such command, the program
+// with the given args in the folder of the dependency
+func runDependencyCommand(ctx devspacecontext.Context, args []string, stdout io.Writer, stderr io.Writer, stdin io.Reader) error {
+	// prefer the commands of the dependency
+	commandConfig, err := findCommand(ctx.Config(), args[0])
+	if err == nil {
+		return executeCommandWithAfter(ctx.Context(), commandConfig, args[1:], ctx.Config().Variables(), ctx.WorkingDir(), stdout, stderr, stdin, ctx.Log())
+	}
+
+	// stdin is passed as is, so the program is attached to the terminal if there is one
+	return executeShellCommand(ctx.Context(), `"$@"`, ctx.Config().Variables(), args, ctx.WorkingDir(), stdout, stderr, stdin)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestRunDependencyCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands use posix programs")
+	}
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "migrate.sh"), []byte("#!/bin/sh\nread answer\necho \"$NAME migrated: $answer\"\n"), 0755)
+	assert.NilError(t, err)
+
+	configPath := filepath.Join(dir, "devspace.yaml")
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		&latest.Config{Commands: map[string]*latest.CommandConfig{
+			"migrate": {Name: "migrate", Command: "./migrate.sh"},
+		}},
+		localcache.New(localcache.CachePath(configPath)),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{"NAME": "api"},
+		configPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf).WithWorkingDir(dir)
+
+	// commands of the dependency read from stdin
+	stdout := &bytes.Buffer{}
+	err = runDependencyCommand(ctx, []string{"migrate"}, stdout, stdout, strings.NewReader("yes\n"))
+	assert.NilError(t, err)
+	assert.Equal(t, stdout.String(), "api migrated: yes\n")
+
+	// other programs run in the dependency folder with the variables of the dependency
+	stdout.Reset()
+	err = runDependencyCommand(ctx, []string{"./migrate.sh"}, stdout, stdout, strings.NewReader("no\n"))
+	assert.NilError(t, err)
+	assert.Equal(t, stdout.String(), "api migrated: no\n")
+
+	stdout.Reset()
+	err = runDependencyCommand(ctx, []string{"sh", "-c", "cat; pwd"}, stdout, stdout, strings.NewReader("input\n"))
+	assert.NilError(t, err)
+	assert.Equal(t, stdout.String(), "input\n"+dir+"\n")
+}
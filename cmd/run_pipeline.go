@@ -5,15 +5,19 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/loft-sh/devspace/cmd/flags"
 	"github.com/loft-sh/devspace/pkg/devspace/build"
+	imageregistry "github.com/loft-sh/devspace/pkg/devspace/build/registry"
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/loft-sh/devspace/pkg/devspace/context/values"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/cachestore"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/registry"
+	dependencytypes "github.com/loft-sh/devspace/pkg/devspace/dependency/types"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy"
 	"github.com/loft-sh/devspace/pkg/devspace/dev"
 	"github.com/loft-sh/devspace/pkg/devspace/devpod"
@@ -21,6 +25,8 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/kill"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/loft-sh/devspace/pkg/devspace/pipeline"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/checkpoint"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/dryrun"
 	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
 	"github.com/loft-sh/devspace/pkg/devspace/plugin"
 	"github.com/loft-sh/devspace/pkg/devspace/upgrade"
@@ -28,6 +34,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/util/interrupt"
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/message"
+	"github.com/loft-sh/devspace/pkg/util/timing"
 	"github.com/mgutz/ansi"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -41,23 +48,55 @@ type RunPipelineCmd struct {
 
 	Tags                    []string
 	Render                  bool
+	RenderDir               string
+	Validate                bool
+	ValidateKubeVersion     string
 	Pipeline                string
 	SkipPush                bool
 	SkipPushLocalKubernetes bool
 
 	Dependency             []string
+	DependencyTag          []string
 	SkipDependency         []string
 	SequentialDependencies bool
 
 	ForceBuild          bool
+	ForceRebuildImages  []string
 	SkipBuild           bool
 	BuildSequential     bool
 	MaxConcurrentBuilds int
-
-	ForcePurge bool
+	CacheFrom           []string
+	CacheTo             []string
+	MaxConcurrentPushes int
+	PushBandwidth       string
+
+	MaxConcurrentDependencies int
+	MaxDependencyWeight       int
+	DependencyRetryAttempts   int
+	DependencyRetryBackoff    time.Duration
+	DependencyTimeout         time.Duration
+	DependencyDryRun          bool
+	DependencyContinueOnError bool
+	DeterministicDependencies bool
+	DependencyCyclePolicy     string
+	DependencyEventsFile      string
+	DependencyCacheStore      string
+	TraceDependencies         bool
+
+	ForcePurge        bool
+	ConfirmPurge      bool
+	PurgeYes          bool
+	PurgeWithChildren bool
 
 	ForceDeploy bool
 	SkipDeploy  bool
+	Plan        bool
+	ConfirmPlan bool
+	NoPrune     bool
+	History     bool
+
+	Resume bool
+	DryRun bool
 
 	ShowUI bool
 
@@ -70,18 +109,46 @@ type RunPipelineCmd struct {
 func (cmd *RunPipelineCmd) AddPipelineFlags(f factory.Factory, command *cobra.Command, pipeline *latest.Pipeline) {
 	command.Flags().StringSliceVar(&cmd.SkipDependency, "skip-dependency", cmd.SkipDependency, "Skips the following dependencies for deployment")
 	command.Flags().StringSliceVar(&cmd.Dependency, "dependency", cmd.Dependency, "Deploys only the specified named dependencies")
+	command.Flags().StringSliceVar(&cmd.DependencyTag, "dependency-tag", cmd.DependencyTag, "Deploys only the dependencies with one of the specified tags")
 	command.Flags().BoolVar(&cmd.SequentialDependencies, "sequential-dependencies", false, "If set set true dependencies will run sequentially")
+	command.Flags().IntVar(&cmd.MaxConcurrentDependencies, "max-concurrent-dependencies", cmd.MaxConcurrentDependencies, "The maximum number of dependencies run in parallel (0 for infinite)")
+	command.Flags().IntVar(&cmd.MaxDependencyWeight, "max-dependency-weight", cmd.MaxDependencyWeight, "The maximum sum of the build weights of the dependencies run in parallel (0 for infinite)")
+	command.Flags().IntVar(&cmd.DependencyRetryAttempts, "dependency-retry-attempts", cmd.DependencyRetryAttempts, "The maximum number of times a failed dependency is run before giving up")
+	command.Flags().DurationVar(&cmd.DependencyRetryBackoff, "dependency-retry-backoff", time.Second*5, "The time to wait before retrying a failed dependency, doubled after every attempt")
+	command.Flags().DurationVar(&cmd.DependencyTimeout, "dependency-timeout", cmd.DependencyTimeout, "The maximum time a single dependency may run before it is cancelled (0 for no timeout)")
+	command.Flags().BoolVar(&cmd.DependencyDryRun, "dry-run-dependencies", cmd.DependencyDryRun, "Only reports which dependencies would be built or deployed and why, without running them")
+	command.Flags().StringVar(&cmd.DependencyCyclePolicy, "dependency-cycle-policy", cmd.DependencyCyclePolicy, "How dependencies that reference one of their parents are handled: deploy-once (default), ignore-back-edge or fail")
+	command.Flags().BoolVar(&cmd.DependencyContinueOnError, "continue-on-dependency-error", cmd.DependencyContinueOnError, "If true, a failing dependency doesn't stop the other dependencies and all failures are reported at the end")
+	command.Flags().BoolVar(&cmd.DeterministicDependencies, "deterministic-dependencies", cmd.DeterministicDependencies, "If true, dependencies are started in the same order on every run, sorted by their priority and name, even if this is slower")
+	command.Flags().StringVar(&cmd.DependencyEventsFile, "dependency-events-file", cmd.DependencyEventsFile, "If set, DevSpace appends an event as json line to this file whenever a dependency is scheduled, starts, succeeds or fails")
+	command.Flags().StringVar(&cmd.DependencyCacheStore, "dependency-cache-store", cmd.DependencyCacheStore, "Where the dependency caches are kept for clean checkouts, e.g. in CI: configmap:<name> or dir:<path>")
+	command.Flags().BoolVar(&cmd.TraceDependencies, "trace-dependencies", cmd.TraceDependencies, "If true, the decisions of the dependency scheduler are logged, such as which dependency was picked by which worker and how long it waited")
 
 	command.Flags().BoolVarP(&cmd.ForceBuild, "force-build", "b", cmd.ForceBuild, "Forces to build every image")
+	command.Flags().StringSliceVar(&cmd.ForceRebuildImages, "force-rebuild-image", cmd.ForceRebuildImages, "Forces to rebuild the given images, even if their content is unchanged, e.g. --force-rebuild-image=api,web")
 	command.Flags().BoolVar(&cmd.SkipBuild, "skip-build", cmd.SkipBuild, "Skips building of images")
 	command.Flags().BoolVar(&cmd.BuildSequential, "build-sequential", cmd.BuildSequential, "Builds the images one after another instead of in parallel")
 	command.Flags().IntVar(&cmd.MaxConcurrentBuilds, "max-concurrent-builds", cmd.MaxConcurrentBuilds, "The maximum number of image builds built in parallel (0 for infinite)")
+	command.Flags().StringSliceVar(&cmd.CacheFrom, "cache-from", cmd.CacheFrom, "Imports the BuildKit cache of all images from this location, e.g. type=registry,ref=registry.io/app:cache")
+	command.Flags().StringSliceVar(&cmd.CacheTo, "cache-to", cmd.CacheTo, "Exports the BuildKit cache of all images to this location, e.g. type=inline")
+	command.Flags().IntVar(&cmd.MaxConcurrentPushes, "max-concurrent-pushes", cmd.MaxConcurrentPushes, "The maximum number of images pushed in parallel (0 for infinite)")
+	command.Flags().StringVar(&cmd.PushBandwidth, "push-bandwidth", cmd.PushBandwidth, "The maximum bandwidth in bytes per second all image pushes share, e.g. 5Mi")
 	command.Flags().BoolVar(&cmd.Render, "render", cmd.Render, "If true will render manifests and print them instead of actually deploying them")
+	command.Flags().StringVar(&cmd.RenderDir, "render-dir", cmd.RenderDir, "If set will render manifests into this directory with one file per object instead of actually deploying them")
+	command.Flags().BoolVar(&cmd.Validate, "validate", cmd.Validate, "If true will validate the rendered manifests against the OpenAPI schema of the cluster")
+	command.Flags().StringVar(&cmd.ValidateKubeVersion, "validate-kube-version", cmd.ValidateKubeVersion, "If set will validate the rendered manifests against the OpenAPI schema of this kubernetes version instead of the cluster")
 
-	command.Flags().BoolVar(&cmd.ForcePurge, "force-purge", cmd.ForcePurge, "Forces to purge every deployment even though it might be in use by another DevSpace project")
+	command.Flags().BoolVar(&cmd.ForcePurge, "force-purge", cmd.ForcePurge, "Forces to purge every deployment even though it might be in use by another DevSpace project or is protected")
+	command.Flags().BoolVar(&cmd.ConfirmPurge, "confirm-purge", cmd.ConfirmPurge, "If true will list the deployments that will be deleted and ask for confirmation before purging them")
+	command.Flags().BoolVar(&cmd.PurgeYes, "yes", cmd.PurgeYes, "If true will purge the deployments without asking for confirmation, e.g. with --force-purge in non-interactive mode")
 	command.Flags().BoolVarP(&cmd.ForceDeploy, "force-deploy", "d", cmd.ForceDeploy, "Forces to deploy every deployment")
 	command.Flags().BoolVar(&cmd.SkipDeploy, "skip-deploy", cmd.SkipDeploy, "If enabled will skip deploying")
+	command.Flags().BoolVar(&cmd.Plan, "plan", cmd.Plan, "If true will print the changes the deployments would make to the cluster before deploying them")
+	command.Flags().BoolVar(&cmd.ConfirmPlan, "confirm-plan", cmd.ConfirmPlan, "If true will print the changes the deployments would make to the cluster and ask for confirmation before deploying them")
+	command.Flags().BoolVar(&cmd.NoPrune, "no-prune", cmd.NoPrune, "If true will not delete objects that were removed from the manifests of a deployment")
 	command.Flags().StringVar(&cmd.Pipeline, "pipeline", cmd.Pipeline, "The pipeline to execute")
+	command.Flags().BoolVar(&cmd.Resume, "resume", cmd.Resume, "If true will skip the steps, such as build_images or create_deployments, that were completed by the last failed run of the pipeline")
+	command.Flags().BoolVar(&cmd.DryRun, "dry-run", cmd.DryRun, "If true will print the steps of the pipeline and the images, deployments and dependencies they use without executing them")
 
 	command.Flags().StringSliceVarP(&cmd.Tags, "tag", "t", cmd.Tags, "Use the given tag for all built images")
 	command.Flags().BoolVar(&cmd.SkipPush, "skip-push", cmd.SkipPush, "Skips image pushing, useful for minikube deployment")
@@ -228,6 +295,10 @@ func (cmd *RunPipelineCmd) Run(cobraCmd *cobra.Command, args []string, f factory
 		cmd.Log.SetLevel(logrus.FatalLevel)
 	}
 
+	if cmd.RenderDir != "" {
+		cmd.Render = true
+	}
+
 	// Print upgrade message if new version available
 	if !cmd.Render {
 		upgrade.PrintUpgradeMessage(cmd.Log)
@@ -250,10 +321,33 @@ func (cmd *RunPipelineCmd) Run(cobraCmd *cobra.Command, args []string, f factory
 		cmd.Ctx = values.WithCommandFlags(cmd.Ctx, cobraCmd.Flags())
 	}
 	options := cmd.BuildOptions(cmd.ToConfigOptions())
+	if cmd.DependencyEventsFile != "" {
+		eventsFile, err := os.OpenFile(cmd.DependencyEventsFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return errors.Wrap(err, "open dependency events file")
+		}
+		defer eventsFile.Close()
+
+		options.DependencyOptions.Events = dependencytypes.NewJSONEventWriter(eventsFile)
+	}
+	if !cmd.Render {
+		var stopProgress func()
+		options.DependencyOptions.Events, stopProgress = dependency.NewProgressRenderer(cmd.Log, options.DependencyOptions.Events)
+		defer stopProgress()
+	}
+
+	// a dry run only prints the plan, so it must not resolve dependencies or touch the cluster and caches
+	if cmd.DryRun && !cmd.History {
+		return dryRunPipeline(cmd.Ctx, f, options, cmd.Log)
+	}
+
 	ctx, err := initialize(cmd.Ctx, f, options, cmd.Log)
 	if err != nil {
 		return err
 	}
+	if cmd.History {
+		return deploy.PrintHistory(ctx, args)
+	}
 
 	return runWithHooks(ctx, hookName, func() error {
 		return runPipeline(ctx, args, options)
@@ -268,7 +362,11 @@ type CommandOptions struct {
 
 	Pipeline string
 	ShowUI   bool
+	Resume   bool
 	UIPort   int
+
+	// DependencyCacheStore is where the local caches of the dependencies are stored
+	DependencyCacheStore string
 }
 
 func initialize(ctx context.Context, f factory.Factory, options *CommandOptions, logger log.Logger) (devspacecontext.Context, error) {
@@ -284,6 +382,19 @@ func initialize(ctx context.Context, f factory.Factory, options *CommandOptions,
 	// add temp folder to context
 	ctx = values.WithTempFolder(ctx, tempFolder)
 
+	// share image builds between the dependencies
+	ctx = build.WithSharedBuilds(ctx)
+
+	// share the push limits between the dependencies
+	if options.BuildOptions.MaxConcurrentPushes > 0 || options.BuildOptions.PushBandwidth != "" {
+		scheduler, err := imageregistry.NewPushScheduler(options.BuildOptions.MaxConcurrentPushes, options.BuildOptions.PushBandwidth)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx = imageregistry.WithPushScheduler(ctx, scheduler)
+	}
+
 	// set config root
 	configLoader, err := f.NewConfigLoader(options.ConfigPath)
 	if err != nil {
@@ -319,8 +430,18 @@ func initialize(ctx context.Context, f factory.Factory, options *CommandOptions,
 		}
 	}
 
+	// create the store of the dependency caches
+	if options.DependencyCacheStore != "" {
+		options.DependencyOptions.CacheStore, err = cachestore.Parse(options.DependencyCacheStore, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// load config
-	configInterface, err := configLoader.LoadWithCache(ctx, localCache, client, options.ConfigOptions, logger)
+	spanCtx, done := timing.Start(ctx, "load config")
+	configInterface, err := configLoader.LoadWithCache(spanCtx, localCache, client, options.ConfigOptions, logger)
+	done()
 	if err != nil {
 		return nil, err
 	}
@@ -340,7 +461,18 @@ func initialize(ctx context.Context, f factory.Factory, options *CommandOptions,
 	}
 
 	// resolve dependencies
-	dependencies, err := f.NewDependencyManager(devCtx, options.ConfigOptions).ResolveAll(devCtx, dependency.ResolveOptions{SkipDependencies: options.DependencyOptions.Exclude})
+	spanCtx, done = timing.Start(ctx, "resolve dependencies")
+	dependencies, err := f.NewDependencyManager(devCtx, options.ConfigOptions).ResolveAll(devCtx.WithContext(spanCtx), dependency.ResolveOptions{
+		SkipDependencies: options.DependencyOptions.Exclude,
+		CyclePolicy:      options.DependencyOptions.CyclePolicy,
+		CacheStore:       options.DependencyOptions.CacheStore,
+		ReadOnly:         options.DependencyOptions.DryRun,
+		Retry: dependencytypes.RetryOptions{
+			Attempts: options.DependencyOptions.RetryAttempts,
+			Backoff:  options.DependencyOptions.RetryBackoff,
+		},
+	})
+	done()
 	if err != nil {
 		return nil, errors.Wrap(err, "deploy dependencies")
 	}
@@ -362,23 +494,36 @@ func runWithHooks(ctx devspacecontext.Context, command string, fn func() error)
 	}
 
 	defer func() {
+		// stop session hooks
+		hook.StopSessionHooks()
+
 		// delete temp folder
 		deleteTempFolder(ctx.Context(), ctx.Log())
 
 		// execute hooks
 		if err != nil {
-			hook.LogExecuteHooks(ctx, map[string]interface{}{"error": err}, command+":after:execute", command+":error")
+			abortErr := hook.LogExecuteHooks(ctx, map[string]interface{}{"error": err}, command+":after:execute", command+":error")
+			if abortErr != nil {
+				err = errors.Wrap(abortErr, err.Error())
+			}
 		} else {
 			err = hook.ExecuteHooks(ctx, nil, command+":after:execute")
 		}
 	}()
 
 	return interrupt.Global.Run(fn, func() {
+		// stop session hooks
+		hook.StopSessionHooks()
+
 		// delete temp folder
 		deleteTempFolder(ctx.Context(), ctx.Log())
 
 		// execute hooks
-		hook.LogExecuteHooks(ctx, nil, command+":interrupt")
+		// the command is exiting, so an abort can only be reported
+		abortErr := hook.LogExecuteHooks(ctx, nil, command+":interrupt")
+		if abortErr != nil {
+			ctx.Log().Error(abortErr)
+		}
 	})
 }
 
@@ -403,46 +548,127 @@ func (cmd *RunPipelineCmd) BuildOptions(configOptions *loader.ConfigOptions) *Co
 				SkipPush:                  cmd.SkipPush,
 				SkipPushOnLocalKubernetes: cmd.SkipPushLocalKubernetes,
 				ForceRebuild:              cmd.ForceBuild,
+				ForceRebuildImages:        cmd.ForceRebuildImages,
 				Sequential:                cmd.BuildSequential,
 				MaxConcurrentBuilds:       cmd.MaxConcurrentBuilds,
+				CacheFrom:                 cmd.CacheFrom,
+				CacheTo:                   cmd.CacheTo,
+				MaxConcurrentPushes:       cmd.MaxConcurrentPushes,
+				PushBandwidth:             cmd.PushBandwidth,
 			},
 			DeployOptions: deploy.Options{
-				ForceDeploy:  cmd.ForceDeploy,
-				Render:       cmd.Render,
-				RenderWriter: cmd.RenderWriter,
-				SkipDeploy:   cmd.SkipDeploy,
+				ForceDeploy:         cmd.ForceDeploy,
+				Render:              cmd.Render,
+				RenderWriter:        cmd.RenderWriter,
+				RenderDir:           cmd.RenderDir,
+				Validate:            cmd.Validate,
+				ValidateKubeVersion: cmd.ValidateKubeVersion,
+				SkipDeploy:          cmd.SkipDeploy,
+				Plan:                cmd.Plan,
+				ConfirmPlan:         cmd.ConfirmPlan,
+				NoPrune:             cmd.NoPrune,
 			},
 			PurgeOptions: deploy.PurgeOptions{
 				ForcePurge: cmd.ForcePurge,
+				Confirm:    cmd.ConfirmPurge,
+				Yes:        cmd.PurgeYes,
+				Recursive:  cmd.PurgeWithChildren,
 			},
 			DependencyOptions: types.DependencyOptions{
-				Exclude:    cmd.SkipDependency,
-				Only:       cmd.Dependency,
-				Sequential: cmd.SequentialDependencies,
+				Exclude:                   cmd.SkipDependency,
+				Only:                      cmd.Dependency,
+				Tags:                      cmd.DependencyTag,
+				Sequential:                cmd.SequentialDependencies,
+				MaxConcurrentDependencies: cmd.MaxConcurrentDependencies,
+				MaxWeight:                 cmd.MaxDependencyWeight,
+				RetryAttempts:             cmd.DependencyRetryAttempts,
+				RetryBackoff:              cmd.DependencyRetryBackoff,
+				Timeout:                   cmd.DependencyTimeout,
+				DryRun:                    cmd.DependencyDryRun,
+				ContinueOnError:           cmd.DependencyContinueOnError,
+				Deterministic:             cmd.DeterministicDependencies,
+				CyclePolicy:               dependencytypes.CyclePolicy(cmd.DependencyCyclePolicy),
+				Trace:                     cmd.TraceDependencies,
 			},
 		},
-		ConfigOptions: configOptions,
-		Pipeline:      cmd.Pipeline,
-		ShowUI:        cmd.ShowUI,
+		ConfigOptions:        configOptions,
+		Pipeline:             cmd.Pipeline,
+		ShowUI:               cmd.ShowUI,
+		Resume:               cmd.Resume,
+		DependencyCacheStore: cmd.DependencyCacheStore,
 	}
 }
 
-func runPipeline(ctx devspacecontext.Context, args []string, options *CommandOptions) error {
-	var configPipeline *latest.Pipeline
-	if ctx.Config().Config().Pipelines != nil && ctx.Config().Config().Pipelines[options.Pipeline] != nil {
-		configPipeline = ctx.Config().Config().Pipelines[options.Pipeline]
+// getConfigPipeline returns the pipeline of the config or the default pipeline with the name
+func getConfigPipeline(ctx devspacecontext.Context, name string) (*latest.Pipeline, error) {
+	if ctx.Config().Config().Pipelines != nil && ctx.Config().Config().Pipelines[name] != nil {
+		configPipeline := ctx.Config().Config().Pipelines[name]
 		if configPipeline.Run == "" {
-			defaultPipeline, _ := types.GetDefaultPipeline(options.Pipeline)
+			defaultPipeline, _ := types.GetDefaultPipeline(name)
 			if defaultPipeline != nil {
 				configPipeline.Run = defaultPipeline.Run
 			}
 		}
-	} else {
-		var err error
-		configPipeline, err = types.GetDefaultPipeline(options.Pipeline)
-		if err != nil {
-			return err
-		}
+
+		return configPipeline, nil
+	}
+
+	return types.GetDefaultPipeline(name)
+}
+
+// dryRunPipeline loads the config without saving the local cache and prints the plan of the pipeline
+func dryRunPipeline(ctx context.Context, f factory.Factory, options *CommandOptions, logger log.Logger) error {
+	configLoader, err := f.NewConfigLoader(options.ConfigPath)
+	if err != nil {
+		return err
+	}
+	configExists, err := configLoader.SetDevSpaceRoot(logger)
+	if err != nil {
+		return err
+	} else if !configExists {
+		return errors.New(message.ConfigNotFound)
+	}
+
+	configOptions := &loader.ConfigOptions{}
+	if options.ConfigOptions != nil {
+		copied := *options.ConfigOptions
+		configOptions = &copied
+	}
+	configOptions.Dry = true
+	configInterface, err := configLoader.Load(ctx, nil, configOptions, logger)
+	if err != nil {
+		return err
+	}
+
+	devCtx := devspacecontext.NewContext(ctx, configInterface.Variables(), logger).WithConfig(configInterface)
+	return printPipelinePlan(devCtx, options.Pipeline)
+}
+
+// printPipelinePlan prints the execution graph of the pipeline without executing any of its steps
+func printPipelinePlan(ctx devspacecontext.Context, name string) error {
+	configPipeline, err := getConfigPipeline(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	plan, err := dryrun.New(ctx.Config().Config(), configPipeline)
+	if err != nil {
+		return err
+	}
+
+	ctx.Log().Infof("Pipeline %s would run the following steps (dry run, nothing was executed):", name)
+	ctx.Log().WriteString(logrus.InfoLevel, "\n"+plan.String()+"\n")
+	if errs := plan.Errors(); len(errs) > 0 {
+		return fmt.Errorf("pipeline %s would fail: %v", name, errs[0])
+	}
+
+	return nil
+}
+
+func runPipeline(ctx devspacecontext.Context, args []string, options *CommandOptions) error {
+	configPipeline, err := getConfigPipeline(ctx, options.Pipeline)
+	if err != nil {
+		return err
 	}
 
 	// marshal pipeline
@@ -451,6 +677,16 @@ func runPipeline(ctx devspacecontext.Context, args []string, options *CommandOpt
 		ctx.Log().Debugf("Run pipeline:\n%s\n", string(configPipelineBytes))
 	}
 
+	// record the completed steps, so that a failed pipeline can be resumed
+	var cp *checkpoint.Checkpoint
+	if !options.DeployOptions.Render {
+		cp, err = checkpoint.New(ctx.Config().LocalCache(), configPipeline, args, options.Resume, ctx.Log())
+		if err != nil {
+			return errors.Wrap(err, "create pipeline checkpoint")
+		}
+		ctx = ctx.WithContext(checkpoint.WithCheckpoint(ctx.Context(), cp))
+	}
+
 	// create dev context
 	devCtxCancel, cancelDevCtx := context.WithCancel(ctx.Context())
 	ctx = ctx.WithContext(values.WithDevContext(ctx.Context(), devCtxCancel))
@@ -499,6 +735,12 @@ func runPipeline(ctx devspacecontext.Context, args []string, options *CommandOpt
 
 		return err
 	}
+	if cp != nil {
+		err = cp.Finish()
+		if err != nil {
+			ctx.Log().Debugf("Error removing pipeline checkpoint: %v", err)
+		}
+	}
 	ctx.Log().Debugf("Wait for dev to finish")
 
 	// wait for dev
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/cmd/flags"
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/util"
+	fakefactory "github.com/loft-sh/devspace/pkg/util/factory/testing"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+const dryRunConfig = `version: v2beta1
+name: dry-run
+vars:
+  IMAGE: nginx
+dependencies:
+  dep:
+    path: ./dep
+pipelines:
+  deploy: |-
+    run_dependencies --all
+    create_deployments --all
+deployments:
+  app:
+    kubectl:
+      manifests:
+      - ${IMAGE}.yaml
+`
+
+func TestRunPipelineDryRun(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NilError(t, err)
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+	t.Setenv("DEVSPACE_SKIP_VERSION_CHECK", "true")
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "devspace.yaml")
+	err = os.WriteFile(configPath, []byte(dryRunConfig), 0644)
+	assert.NilError(t, err)
+	configLoader, err := loader.NewConfigLoader(configPath)
+	assert.NilError(t, err)
+
+	// the factory has no kube client and no dependency manager, a dry run must not need them
+	cmd := &RunPipelineCmd{
+		GlobalFlags: &flags.GlobalFlags{ConfigPath: configPath},
+		Pipeline:    "deploy",
+		DryRun:      true,
+		Log:         log.Discard,
+		Ctx:         context.Background(),
+	}
+	err = cmd.Run(nil, nil, &fakefactory.Factory{ConfigLoader: configLoader, Log: log.Discard}, "deploy")
+	assert.NilError(t, err)
+
+	// neither the lock file nor the local cache were written
+	_, err = os.Stat(filepath.Join(dir, util.LockFileName))
+	assert.Assert(t, os.IsNotExist(err), err)
+	_, err = os.Stat(filepath.Join(dir, ".devspace"))
+	assert.Assert(t, os.IsNotExist(err), err)
+}
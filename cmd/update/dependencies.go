@@ -0,0 +1,81 @@
+package update
+
+import (
+	"context"
+
+	"github.com/loft-sh/devspace/cmd/flags"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/util"
+	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/loft-sh/devspace/pkg/util/message"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type dependenciesCmd struct {
+	*flags.GlobalFlags
+}
+
+func newDependenciesCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &dependenciesCmd{GlobalFlags: globalFlags}
+	dependenciesCmd := &cobra.Command{
+		Use:   "dependencies",
+		Short: "Updates the git dependencies to their latest commits",
+		Long: `
+#######################################################
+########### devspace update dependencies ##############
+#######################################################
+Pulls the latest commits of all git dependencies and
+pins them in the ` + util.LockFileName + `
+#######################################################
+	`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(f)
+		}}
+
+	return dependenciesCmd
+}
+
+// Run executes the command logic
+func (cmd *dependenciesCmd) Run(f factory.Factory) error {
+	// Set config root
+	log := f.GetLog()
+	configOptions := cmd.ToConfigOptions()
+	configLoader, err := f.NewConfigLoader(cmd.ConfigPath)
+	if err != nil {
+		return err
+	}
+	configExists, err := configLoader.SetDevSpaceRoot(log)
+	if err != nil {
+		return err
+	} else if !configExists {
+		return errors.New(message.ConfigNotFound)
+	}
+
+	// create kubectl client
+	client, err := f.NewKubeClientFromContext(cmd.KubeContext, cmd.Namespace)
+	if err != nil {
+		log.Warnf("Unable to create new kubectl client: %v", err)
+	}
+
+	// load config
+	config, err := configLoader.Load(context.Background(), client, configOptions, log)
+	if err != nil {
+		return err
+	}
+
+	// create devspace context
+	ctx := devspacecontext.NewContext(context.Background(), config.Variables(), log).
+		WithConfig(config).
+		WithKubeClient(client)
+
+	_, err = f.NewDependencyManager(ctx, configOptions).UpdateAll(ctx, dependency.ResolveOptions{})
+	if err != nil {
+		return err
+	}
+
+	log.Done("Successfully updated dependencies")
+	return nil
+}
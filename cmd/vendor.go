@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/loft-sh/devspace/cmd/flags"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/util"
+	"github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/loft-sh/devspace/pkg/util/message"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewVendorCmd creates a new cobra command for the vendor sub command
+func NewVendorCmd(f factory.Factory, globalFlags *flags.GlobalFlags, plugins []plugin.Metadata) *cobra.Command {
+	vendorCmd := &cobra.Command{
+		Use:   "vendor",
+		Short: "Copies remote sources into the project",
+		Long: `
+#######################################################
+################## devspace vendor ####################
+#######################################################
+	`,
+		Args: cobra.NoArgs,
+	}
+	vendorCmd.AddCommand(newVendorDependenciesCmd(f, globalFlags))
+
+	// Add plugin commands
+	plugin.AddPluginCommands(vendorCmd, plugins, "vendor")
+	return vendorCmd
+}
+
+type vendorDependenciesCmd struct {
+	*flags.GlobalFlags
+}
+
+func newVendorDependenciesCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &vendorDependenciesCmd{GlobalFlags: globalFlags}
+	return &cobra.Command{
+		Use:   "dependencies",
+		Short: "Copies all remote dependencies into " + util.VendorFolder,
+		Long: `
+#######################################################
+########## devspace vendor dependencies ###############
+#######################################################
+Downloads all git, oci and url dependencies and copies
+them into ` + util.VendorFolder + `. Vendored
+dependencies are used instead of the remote sources,
+so that no network access to the remotes is needed.
+Run this command again to update the vendored copies.
+#######################################################
+	`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(f)
+		}}
+}
+
+// Run executes the command logic
+func (cmd *vendorDependenciesCmd) Run(f factory.Factory) error {
+	// Set config root
+	log := f.GetLog()
+	configOptions := cmd.ToConfigOptions()
+	configLoader, err := f.NewConfigLoader(cmd.ConfigPath)
+	if err != nil {
+		return err
+	}
+	configExists, err := configLoader.SetDevSpaceRoot(log)
+	if err != nil {
+		return err
+	} else if !configExists {
+		return errors.New(message.ConfigNotFound)
+	}
+
+	// create kubectl client
+	client, err := f.NewKubeClientFromContext(cmd.KubeContext, cmd.Namespace)
+	if err != nil {
+		log.Warnf("Unable to create new kubectl client: %v", err)
+	}
+
+	// load config
+	config, err := configLoader.Load(context.Background(), client, configOptions, log)
+	if err != nil {
+		return err
+	}
+
+	// create devspace context
+	ctx := devspacecontext.NewContext(context.Background(), config.Variables(), log).
+		WithConfig(config).
+		WithKubeClient(client)
+
+	_, err = f.NewDependencyManager(ctx, configOptions).VendorAll(ctx, dependency.ResolveOptions{})
+	if err != nil {
+		return err
+	}
+
+	log.Donef("Successfully vendored dependencies into %s", util.VendorFolder)
+	return nil
+}
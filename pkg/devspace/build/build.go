@@ -1,9 +1,12 @@
 package build
 
 import (
+	"os"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/build/registry"
 	dockerclient "github.com/loft-sh/devspace/pkg/devspace/docker"
 	"github.com/loft-sh/devspace/pkg/devspace/pullsecrets"
-	"strings"
 
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder"
 	"github.com/loft-sh/devspace/pkg/devspace/build/types"
@@ -11,6 +14,7 @@ import (
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/loft-sh/devspace/pkg/util/randutil"
 	"github.com/loft-sh/devspace/pkg/util/stringutil"
+	"github.com/loft-sh/devspace/pkg/util/timing"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/hook"
@@ -31,10 +35,17 @@ type Options struct {
 	SkipBuild                 bool     `long:"skip" description:"If enabled will skip building"`
 	SkipPush                  bool     `long:"skip-push" description:"Skip pushing"`
 	SkipPushOnLocalKubernetes bool     `long:"skip-push-on-local-kubernetes" description:"Skip pushing"`
-	ForceRebuild              bool     `long:"force-rebuild" description:"Skip pushing"`
+	ForceRebuild              bool     `long:"force-rebuild" description:"Forces to rebuild every image"`
 	Sequential                bool     `long:"sequential" description:"Skip pushing"`
+	ForceRebuildImages        []string `long:"force-rebuild-image" description:"Forces to rebuild the given images, even if their content is unchanged"`
 
 	MaxConcurrentBuilds int `long:"max-concurrent" description:"A pointer to an integer"`
+
+	MaxConcurrentPushes int    `long:"max-concurrent-pushes" description:"The maximum number of images pushed in parallel (0 for infinite)"`
+	PushBandwidth       string `long:"push-bandwidth" description:"The maximum bandwidth in bytes per second all image pushes share, e.g. 5Mi"`
+
+	CacheFrom []string `long:"cache-from" description:"Import the BuildKit cache of all images from this location, e.g. type=registry,ref=registry.io/app:cache"`
+	CacheTo   []string `long:"cache-to" description:"Export the BuildKit cache of all images to this location, e.g. type=inline"`
 }
 
 // Controller is the main building interface
@@ -69,6 +80,16 @@ func (c *controller) Build(ctx devspacecontext.Context, images []string, options
 		return nil
 	}
 
+	// Limit pushes if the pipeline doesn't limit them already
+	if registry.PushSchedulerFrom(ctx.Context()) == nil && (options.MaxConcurrentPushes > 0 || options.PushBandwidth != "") {
+		scheduler, err := registry.NewPushScheduler(options.MaxConcurrentPushes, options.PushBandwidth)
+		if err != nil {
+			return err
+		}
+
+		ctx = ctx.WithContext(registry.WithPushScheduler(ctx.Context(), scheduler))
+	}
+
 	// Build not in parallel when we only have one image to build
 	if !options.Sequential {
 		// check if all images are disabled besides one
@@ -127,11 +148,10 @@ func (c *controller) Build(ctx devspacecontext.Context, images []string, options
 			imageTags = append(imageTags, randutil.GenerateRandomString(7))
 		}
 
-		// replace the # in the tags
-		for i := range imageTags {
-			for strings.Contains(imageTags[i], "#") {
-				imageTags[i] = strings.Replace(imageTags[i], "#", randutil.GenerateRandomString(1), 1)
-			}
+		// resolve the tag variables and the # in the tags
+		imageTags, err := resolveTags(ctx, imageConfigName, imageTags)
+		if err != nil {
+			return err
 		}
 
 		// Create new builder
@@ -181,7 +201,8 @@ func (c *controller) Build(ctx devspacecontext.Context, images []string, options
 		}
 
 		// Check if rebuild is needed
-		needRebuild, err := builder.ShouldRebuild(ctx, options.ForceRebuild)
+		forceRebuild := options.ForceRebuild || stringutil.Contains(options.ForceRebuildImages, imageConfigName)
+		needRebuild, err := builder.ShouldRebuild(ctx, forceRebuild)
 		if err != nil {
 			pluginErr := hook.ExecuteHooks(ctx, map[string]interface{}{
 				"IMAGE_CONFIG_NAME": imageConfigName,
@@ -196,7 +217,7 @@ func (c *controller) Build(ctx devspacecontext.Context, images []string, options
 			return errors.Errorf("error during shouldRebuild check: %v", err)
 		}
 
-		if !options.ForceRebuild && !needRebuild {
+		if !forceRebuild && !needRebuild {
 			// Execute before images build hook
 			pluginErr := hook.ExecuteHooks(ctx, map[string]interface{}{
 				"IMAGE_CONFIG_NAME": imageConfigName,
@@ -211,10 +232,68 @@ func (c *controller) Build(ctx devspacecontext.Context, images []string, options
 			continue
 		}
 
+		// Reuse the image if an image with the same content was already built by another dependency
+		shared, owner := c.claimSharedBuild(ctx, &cImageConf, imageTags)
+		if shared != nil && !owner {
+			image, err := shared.wait(ctx.Context())
+			if err != nil {
+				return errors.Wrapf(err, "error building image %s:%s, because the image with the same content failed", resolvedImage, imageTags[0])
+			}
+
+			imageCache, _ := ctx.Config().LocalCache().GetImageCache(imageConfigName)
+			imageCache.ImageName = imageName
+			imageCache.Tag = image.ImageTag
+			imageCache.Tags = image.ImageTags
+			imageCache.Digest = image.ImageDigest
+			ctx.Config().LocalCache().SetImageCache(imageConfigName, imageCache)
+
+			builtImages[imageConfigName] = types.ImageNameTag{
+				ImageConfigName: imageConfigName,
+				ImageName:       imageName,
+				ImageTag:        image.ImageTag,
+				ImageTags:       image.ImageTags,
+				ImageDigest:     image.ImageDigest,
+			}
+
+			pluginErr := hook.ExecuteHooks(ctx, map[string]interface{}{
+				"IMAGE_CONFIG_NAME": imageConfigName,
+				"IMAGE_NAME":        resolvedImage,
+				"IMAGE_CONFIG":      cImageConf,
+				"IMAGE_TAGS":        []string{image.ImageTag},
+			}, hook.EventsForSingle("skip:build", imageConfigName)...)
+			if pluginErr != nil {
+				return pluginErr
+			}
+			ctx.Log().Infof("Skip building image '%s', because the same image was already built as %s:%s", imageConfigName, image.ImageName, image.ImageTag)
+			continue
+		}
+
+		// images with the same content wait for this build and get its error if it fails
+		finishSharedBuild := func(err error) {
+			if shared != nil && owner {
+				imageCache, _ := ctx.Config().LocalCache().GetImageCache(imageConfigName)
+				sharedBuildsFrom(ctx.Context()).finish(shared, types.ImageNameTag{
+					ImageConfigName: imageConfigName,
+					ImageName:       imageName,
+					ImageTag:        imageTags[0],
+					ImageTags:       imageTags,
+					ImageDigest:     imageCache.Digest,
+				}, err)
+			}
+		}
+
+		// The digest of the previous build is stale, builders record the new one after pushing
+		imageCache, _ = ctx.Config().LocalCache().GetImageCache(imageConfigName)
+		imageCache.Digest = ""
+		ctx.Config().LocalCache().SetImageCache(imageConfigName, imageCache)
+
 		// Sequential or parallel build?
 		if options.Sequential {
 			// Build the image
-			err = builder.Build(ctx)
+			spanCtx, done := timing.Start(ctx.Context(), "build image "+imageConfigName)
+			err = builder.Build(ctx.WithContext(spanCtx))
+			done()
+			finishSharedBuild(err)
 			if err != nil {
 				pluginErr := hook.ExecuteHooks(ctx, map[string]interface{}{
 					"IMAGE_CONFIG_NAME": imageConfigName,
@@ -237,6 +316,7 @@ func (c *controller) Build(ctx devspacecontext.Context, images []string, options
 
 			imageCache.ImageName = imageName
 			imageCache.Tag = imageTags[0]
+			imageCache.Tags = imageTags
 			ctx.Config().LocalCache().SetImageCache(imageConfigName, imageCache)
 
 			// Track built images
@@ -244,15 +324,12 @@ func (c *controller) Build(ctx devspacecontext.Context, images []string, options
 				ImageConfigName: imageConfigName,
 				ImageName:       imageName,
 				ImageTag:        imageTags[0],
+				ImageTags:       imageTags,
+				ImageDigest:     imageCache.Digest,
 			}
 
 			// Execute before images build hook
-			pluginErr := hook.ExecuteHooks(ctx, map[string]interface{}{
-				"IMAGE_CONFIG_NAME": imageConfigName,
-				"IMAGE_NAME":        resolvedImage,
-				"IMAGE_CONFIG":      cImageConf,
-				"IMAGE_TAGS":        imageTags,
-			}, hook.EventsForSingle("after:build", imageConfigName).With("build.afterBuild")...)
+			pluginErr := hook.ExecuteHooks(ctx, afterBuildEnv(ctx, imageConfigName, resolvedImage, cImageConf, imageTags), hook.EventsForSingle("after:build", imageConfigName).With("build.afterBuild")...)
 			if pluginErr != nil {
 				return pluginErr
 			}
@@ -261,6 +338,7 @@ func (c *controller) Build(ctx devspacecontext.Context, images []string, options
 			if options.MaxConcurrentBuilds > 0 && imagesToBuild >= options.MaxConcurrentBuilds {
 				err = c.waitForBuild(ctx, errChan, cacheChan, builtImages)
 				if err != nil {
+					finishSharedBuild(errors.Wrapf(err, "image %s was not built", imageConfigName))
 					return err
 				}
 
@@ -270,16 +348,23 @@ func (c *controller) Build(ctx devspacecontext.Context, images []string, options
 			imagesToBuild++
 			go func(ctx devspacecontext.Context) {
 				// Build the image
-				err := builder.Build(ctx)
+				spanCtx, done := timing.Start(ctx.Context(), "build image "+imageConfigName)
+				err := builder.Build(ctx.WithContext(spanCtx))
+				done()
+				finishSharedBuild(err)
 				if err != nil {
-					hook.LogExecuteHooks(ctx, map[string]interface{}{
+					abortErr := hook.LogExecuteHooks(ctx, map[string]interface{}{
 						"IMAGE_CONFIG_NAME": imageConfigName,
 						"IMAGE_NAME":        resolvedImage,
 						"IMAGE_CONFIG":      cImageConf,
 						"IMAGE_TAGS":        imageTags,
 						"ERROR":             err,
 					}, hook.EventsForSingle("error:build", imageConfigName).With("build.errorBuild")...)
-					errChan <- errors.Errorf("error building image %s:%s: %v", resolvedImage, imageTags[0], err)
+					err = errors.Errorf("error building image %s:%s: %v", resolvedImage, imageTags[0], err)
+					if abortErr != nil {
+						err = errors.Wrap(abortErr, err.Error())
+					}
+					errChan <- err
 					return
 				}
 
@@ -340,6 +425,22 @@ func (c *controller) Build(ctx devspacecontext.Context, images []string, options
 	return nil
 }
 
+// claimSharedBuild looks up the shared build of an image with the same content. If the returned build is not
+// owned, another dependency builds the image already, otherwise the caller has to finish the build
+func (c *controller) claimSharedBuild(ctx devspacecontext.Context, imageConf *latest.Image, imageTags []string) (*sharedBuild, bool) {
+	builds := sharedBuildsFrom(ctx.Context())
+	if builds == nil {
+		return nil, false
+	}
+
+	contentHash := imageContentHash(ctx, imageConf, imageTags)
+	if contentHash == "" {
+		return nil, false
+	}
+
+	return builds.claim(contentHash)
+}
+
 func (c *controller) waitForBuild(ctx devspacecontext.Context, errChan <-chan error, cacheChan <-chan imageNameAndTag, builtImages map[string]types.ImageNameTag) error {
 	select {
 	case err := <-errChan:
@@ -358,6 +459,7 @@ func (c *controller) waitForBuild(ctx devspacecontext.Context, errChan <-chan er
 
 		imageCache.ImageName = done.imageName
 		imageCache.Tag = done.imageTag
+		imageCache.Tags = done.imageTags
 		ctx.Config().LocalCache().SetImageCache(done.imageConfigName, imageCache)
 
 		// Track built images
@@ -365,15 +467,12 @@ func (c *controller) waitForBuild(ctx devspacecontext.Context, errChan <-chan er
 			ImageConfigName: done.imageConfigName,
 			ImageName:       done.imageName,
 			ImageTag:        done.imageTag,
+			ImageTags:       done.imageTags,
+			ImageDigest:     imageCache.Digest,
 		}
 
 		// Execute plugin hook
-		pluginErr := hook.ExecuteHooks(ctx, map[string]interface{}{
-			"IMAGE_CONFIG_NAME": done.imageConfigName,
-			"IMAGE_NAME":        resolvedImage,
-			"IMAGE_CONFIG":      done.imageConfig,
-			"IMAGE_TAGS":        done.imageTags,
-		}, hook.EventsForSingle("after:build", done.imageConfigName).With("build.afterBuild")...)
+		pluginErr := hook.ExecuteHooks(ctx, afterBuildEnv(ctx, done.imageConfigName, resolvedImage, done.imageConfig, done.imageTags), hook.EventsForSingle("after:build", done.imageConfigName).With("build.afterBuild")...)
 		if pluginErr != nil {
 			return pluginErr
 		}
@@ -381,3 +480,21 @@ func (c *controller) waitForBuild(ctx devspacecontext.Context, errChan <-chan er
 
 	return nil
 }
+
+// afterBuildEnv returns the env of the after:build hooks, which includes the path of the sbom if one was saved
+func afterBuildEnv(ctx devspacecontext.Context, imageConfigName, resolvedImage string, imageConf latest.Image, imageTags []string) map[string]interface{} {
+	extraEnv := map[string]interface{}{
+		"IMAGE_CONFIG_NAME": imageConfigName,
+		"IMAGE_NAME":        resolvedImage,
+		"IMAGE_CONFIG":      imageConf,
+		"IMAGE_TAGS":        imageTags,
+	}
+	if imageConf.Attestations != nil && imageConf.Attestations.SBOM {
+		sbomPath := helper.SBOMPath(ctx, imageConfigName)
+		if _, err := os.Stat(sbomPath); err == nil {
+			extraEnv["SBOM_PATH"] = sbomPath
+		}
+	}
+
+	return extraEnv
+}
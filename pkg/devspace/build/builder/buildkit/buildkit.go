@@ -121,10 +121,34 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 
 	// Should we build with cli?
 	skipPush := b.skipPush || b.helper.ImageConf.SkipPush
-	return buildWithCLI(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), body, writer, ctx.KubeClient(), builder, buildKitConfig, *buildOptions, useMinikubeDocker, skipPush, ctx.Log())
+	buildOptions.CacheFrom = b.helper.ImageConf.CacheFrom
+	platforms := b.helper.ImageConf.Platforms
+	if len(platforms) > 1 && skipPush {
+		// buildx can't load multi-arch images into the docker daemon
+		ctx.Log().Infof("Build image %s only for the platform of the builder, because it is not pushed", b.helper.ImageName)
+		platforms = nil
+	}
+	buildOptions.Platform = strings.Join(platforms, ",")
+
+	// attestations can only be attached to pushed images
+	extraArgs := helper.SecretArgs(ctx, b.helper.ImageConf.Secrets)
+	if !skipPush {
+		extraArgs = append(extraArgs, helper.AttestationArgs(b.helper.ImageConf.Attestations)...)
+	}
+
+	err = buildWithCLI(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), body, writer, ctx.KubeClient(), builder, buildKitConfig, *buildOptions, b.helper.ImageConf.CacheTo, extraArgs, useMinikubeDocker, skipPush, ctx.Log())
+	if err != nil {
+		return err
+	}
+
+	if !skipPush {
+		b.helper.RecordDigest(ctx, buildOptions.Tags[0])
+		b.helper.SaveSBOM(ctx, buildOptions.Tags[0])
+	}
+	return nil
 }
 
-func buildWithCLI(ctx context.Context, dir string, environ expand.Environ, context io.Reader, writer io.Writer, kubeClient kubectl.Client, builder string, imageConf *latest.BuildKitConfig, options types.ImageBuildOptions, useMinikubeDocker, skipPush bool, log logpkg.Logger) error {
+func buildWithCLI(ctx context.Context, dir string, environ expand.Environ, context io.Reader, writer io.Writer, kubeClient kubectl.Client, builder string, imageConf *latest.BuildKitConfig, options types.ImageBuildOptions, cacheTo, extraArgs []string, useMinikubeDocker, skipPush bool, log logpkg.Logger) error {
 	command := []string{"docker", "buildx"}
 	if len(imageConf.Command) > 0 {
 		command = imageConf.Command
@@ -161,6 +185,16 @@ func buildWithCLI(ctx context.Context, dir string, environ expand.Environ, conte
 	if options.Target != "" {
 		args = append(args, "--target", options.Target)
 	}
+	if options.Platform != "" {
+		args = append(args, "--platform", options.Platform)
+	}
+	for _, cache := range options.CacheFrom {
+		args = append(args, "--cache-from", cache)
+	}
+	for _, cache := range cacheTo {
+		args = append(args, "--cache-to", cache)
+	}
+	args = append(args, extraArgs...)
 	if builder != "" {
 		tempFile, err := tempKubeContextFromClient(kubeClient)
 		if err != nil {
@@ -271,6 +305,8 @@ func ensureBuilder(ctx context.Context, workingDir string, environ expand.Enviro
 	if imageConf.InCluster.NodeSelector != "" {
 		args = append(args, "--driver-opt", "nodeselector="+imageConf.InCluster.NodeSelector)
 	}
+	schedulingOpts := schedulingDriverOpts(imageConf.InCluster)
+	args = append(args, schedulingDriverOptArgs(schedulingOpts)...)
 	if len(imageConf.InCluster.CreateArgs) > 0 {
 		args = append(args, imageConf.InCluster.CreateArgs...)
 	}
@@ -314,9 +350,15 @@ func ensureBuilder(ctx context.Context, workingDir string, environ expand.Enviro
 			rootlessCorrect := strconv.FormatBool(imageConf.InCluster.Rootless) == node.DriverOpts["rootless"]
 			imageCorrect := imageConf.InCluster.Image == node.DriverOpts["image"]
 			nodeSelectorCorrect := imageConf.InCluster.NodeSelector == node.DriverOpts["nodeselector"]
+			schedulingCorrect := true
+			for _, key := range schedulingDriverOptKeys {
+				if schedulingOpts[key] != node.DriverOpts[key] {
+					schedulingCorrect = false
+				}
+			}
 
 			// if builder up to date, exit here
-			if namespaceCorrect && rootlessCorrect && imageCorrect && nodeSelectorCorrect {
+			if namespaceCorrect && rootlessCorrect && imageCorrect && nodeSelectorCorrect && schedulingCorrect {
 				return name, nil
 			}
 		}
@@ -385,6 +427,7 @@ func tempKubeContextFromClient(kubeClient kubectl.Client) (string, error) {
 	if !kubeClient.IsInCluster() {
 		rawConfig.CurrentContext = kubeClient.CurrentContext()
 	}
+	kubectl.ImpersonateKubeConfig(&rawConfig)
 
 	bytes, err := clientcmd.Write(rawConfig)
 	if err != nil {
@@ -0,0 +1,144 @@
+package buildkit
+
+import (
+	"context"
+	"net"
+	"net/url"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/localregistry"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	dockerpkg "github.com/loft-sh/devspace/pkg/devspace/docker"
+	buildkitclient "github.com/moby/buildkit/client"
+	"github.com/pkg/errors"
+)
+
+// kubePodScheme is the address scheme of BuildKit daemons that run in a pod of the cluster
+const kubePodScheme = "kube-pod"
+
+// RemoteBuilder builds images with a remote BuildKit daemon, which doesn't need docker on the local machine
+type RemoteBuilder struct {
+	helper   *helper.BuildHelper
+	skipPush bool
+}
+
+// NewRemoteBuilder creates a new remote BuildKit builder
+func NewRemoteBuilder(ctx devspacecontext.Context, imageConf *latest.Image, imageTags []string, skipPush bool) (*RemoteBuilder, error) {
+	return &RemoteBuilder{
+		helper:   helper.NewBuildHelper(ctx, EngineName, imageConf, imageTags),
+		skipPush: skipPush,
+	}, nil
+}
+
+// Build implements the interface
+func (b *RemoteBuilder) Build(ctx devspacecontext.Context) error {
+	return b.helper.Build(ctx, b)
+}
+
+// ShouldRebuild determines if an image has to be rebuilt
+func (b *RemoteBuilder) ShouldRebuild(ctx devspacecontext.Context, forceRebuild bool) (bool, error) {
+	return b.helper.ShouldRebuild(ctx, forceRebuild)
+}
+
+// BuildImage builds the image with the remote BuildKit daemon and pushes it from there
+func (b *RemoteBuilder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfilePath string, entrypoint []string, cmd []string) error {
+	secrets, err := helper.ReadSecrets(ctx, b.helper.ImageConf.Secrets)
+	if err != nil {
+		return err
+	}
+
+	remoteConfig := b.helper.ImageConf.BuildKit.Remote
+	client, err := newRemoteClient(ctx, remoteConfig)
+	if err != nil {
+		return errors.Wrapf(err, "connect to BuildKit at %s", remoteConfig.Address)
+	}
+	defer client.Close()
+
+	// create the context stream
+	body, writer, _, buildOptions, err := b.helper.CreateContextStream(contextPath, dockerfilePath, entrypoint, cmd, ctx.Log())
+	defer writer.Close()
+	if err != nil {
+		return err
+	}
+
+	// the daemon pulls and pushes with the credentials of the local docker config
+	dockerConfig, err := dockerpkg.LoadDockerConfig()
+	if err != nil {
+		return err
+	}
+
+	// there is no local docker daemon to load the image into, so it stays in the BuildKit cache if not pushed
+	push := !b.skipPush && !b.helper.ImageConf.SkipPush
+	ctx.Log().Infof("Build image %s with BuildKit at %s", b.helper.ImageName, remoteConfig.Address)
+	err = localregistry.Solve(ctx, client, dockerConfig, body, writer, buildOptions, b.helper.ImageConf.Platforms, b.helper.ImageConf.CacheFrom, b.helper.ImageConf.CacheTo, b.helper.ImageConf.Attestations, secrets, push)
+	if err != nil {
+		return err
+	}
+
+	if !push {
+		ctx.Log().Infof("Skip image push for %s", b.helper.ImageName)
+		return nil
+	}
+
+	b.helper.RecordDigest(ctx, buildOptions.Tags[0])
+	b.helper.SaveSBOM(ctx, buildOptions.Tags[0])
+	return nil
+}
+
+// newRemoteClient connects to the BuildKit daemon at the address of the config
+func newRemoteClient(ctx devspacecontext.Context, config *latest.BuildKitRemoteConfig) (*buildkitclient.Client, error) {
+	address, err := url.Parse(config.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// BuildKit pods are reached with buildctl dial-stdio, so they don't have to be exposed
+	if address.Scheme == kubePodScheme {
+		if ctx.KubeClient() == nil {
+			return nil, errors.Errorf("a valid kube context is required to connect to %s", config.Address)
+		}
+
+		pod, namespace, container := parseKubePodAddress(address, ctx.KubeClient().Namespace())
+		return buildkitclient.New(ctx.Context(), "", buildkitclient.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return localregistry.ExecConn(ctx, namespace, pod, container, []string{"buildctl", "dial-stdio"})
+		}))
+	}
+
+	// the client certificate is only sent over TLS, which needs the certificate authority of the daemon
+	if (config.Cert != "" || config.Key != "") && config.CACert == "" {
+		return nil, errors.Errorf("caCert is required to connect to %s with a client certificate", config.Address)
+	} else if (config.Cert == "") != (config.Key == "") {
+		return nil, errors.Errorf("cert and key are required together to connect to %s", config.Address)
+	}
+
+	options := []buildkitclient.ClientOpt{}
+	if config.CACert != "" {
+		serverName := config.ServerName
+		if serverName == "" {
+			serverName = address.Hostname()
+		}
+
+		cert, key := "", ""
+		if config.Cert != "" {
+			cert = ctx.ResolvePath(config.Cert)
+		}
+		if config.Key != "" {
+			key = ctx.ResolvePath(config.Key)
+		}
+
+		options = append(options, buildkitclient.WithCredentials(serverName, ctx.ResolvePath(config.CACert), cert, key))
+	}
+
+	return buildkitclient.New(ctx.Context(), config.Address, options...)
+}
+
+// parseKubePodAddress returns the pod, namespace and container of a kube-pod://pod?namespace=ns&container=c address
+func parseKubePodAddress(address *url.URL, defaultNamespace string) (string, string, string) {
+	namespace := address.Query().Get("namespace")
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	return address.Host, namespace, address.Query().Get("container")
+}
@@ -0,0 +1,130 @@
+package buildkit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestParseKubePodAddress(t *testing.T) {
+	address, err := url.Parse("kube-pod://buildkitd-0?namespace=build&container=buildkitd")
+	assert.NilError(t, err)
+
+	pod, namespace, container := parseKubePodAddress(address, "default")
+	assert.Equal(t, pod, "buildkitd-0")
+	assert.Equal(t, namespace, "build")
+	assert.Equal(t, container, "buildkitd")
+
+	// without a namespace the namespace of the kube context is used
+	address, err = url.Parse("kube-pod://buildkitd-0")
+	assert.NilError(t, err)
+
+	pod, namespace, container = parseKubePodAddress(address, "default")
+	assert.Equal(t, pod, "buildkitd-0")
+	assert.Equal(t, namespace, "default")
+	assert.Equal(t, container, "")
+}
+
+type remoteClientTestCase struct {
+	name string
+
+	config latest.BuildKitRemoteConfig
+
+	expectedErr string
+}
+
+func TestNewRemoteClient(t *testing.T) {
+	dir := t.TempDir()
+	writeCertificate(t, dir)
+
+	testCases := []remoteClientTestCase{
+		{
+			name:   "Plain tcp",
+			config: latest.BuildKitRemoteConfig{Address: "tcp://buildkitd:1234"},
+		},
+		{
+			name:   "TLS",
+			config: latest.BuildKitRemoteConfig{Address: "tcp://buildkitd:1234", CACert: "ca.pem"},
+		},
+		{
+			name:   "TLS with client certificate",
+			config: latest.BuildKitRemoteConfig{Address: "tcp://buildkitd:1234", ServerName: "buildkitd", CACert: "ca.pem", Cert: "cert.pem", Key: "key.pem"},
+		},
+		{
+			name:        "Client certificate without ca",
+			config:      latest.BuildKitRemoteConfig{Address: "tcp://buildkitd:1234", Cert: "cert.pem", Key: "key.pem"},
+			expectedErr: "caCert is required to connect to tcp://buildkitd:1234 with a client certificate",
+		},
+		{
+			name:        "Key without ca",
+			config:      latest.BuildKitRemoteConfig{Address: "tcp://buildkitd:1234", Key: "key.pem"},
+			expectedErr: "caCert is required to connect to tcp://buildkitd:1234 with a client certificate",
+		},
+		{
+			name:        "Client certificate without key",
+			config:      latest.BuildKitRemoteConfig{Address: "tcp://buildkitd:1234", CACert: "ca.pem", Cert: "cert.pem"},
+			expectedErr: "cert and key are required together to connect to tcp://buildkitd:1234",
+		},
+		{
+			name:        "Missing ca",
+			config:      latest.BuildKitRemoteConfig{Address: "tcp://buildkitd:1234", CACert: "missing.pem"},
+			expectedErr: "could not read ca certificate",
+		},
+		{
+			name:        "Kube pod without kube context",
+			config:      latest.BuildKitRemoteConfig{Address: "kube-pod://buildkitd-0"},
+			expectedErr: "a valid kube context is required to connect to kube-pod://buildkitd-0",
+		},
+	}
+
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithWorkingDir(dir)
+	for _, testCase := range testCases {
+		client, err := newRemoteClient(ctx, &testCase.config)
+		if testCase.expectedErr != "" {
+			assert.ErrorContains(t, err, testCase.expectedErr, "Unexpected error in testCase %s", testCase.name)
+			continue
+		}
+
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		_ = client.Close()
+	}
+}
+
+// writeCertificate writes a self-signed certificate that is used as ca and client certificate
+func writeCertificate(t *testing.T, dir string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "buildkitd"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err)
+	rawKey, err := x509.MarshalECPrivateKey(key)
+	assert.NilError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "ca.pem"), certPEM, 0600))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0600))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "key.pem"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: rawKey}), 0600))
+}
@@ -0,0 +1,71 @@
+package buildkit
+
+import (
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+)
+
+// schedulingDriverOptKeys are the driver options of the buildx kubernetes driver that DevSpace sets
+// to schedule the BuildKit deployment
+var schedulingDriverOptKeys = []string{"requests.cpu", "requests.memory", "limits.cpu", "limits.memory", "tolerations"}
+
+// schedulingDriverOpts returns the driver options for the resources and tolerations of the BuildKit deployment
+func schedulingDriverOpts(inCluster *latest.BuildKitInClusterConfig) map[string]string {
+	opts := map[string]string{}
+	requests, limits := helper.PodResources(inCluster.Resources)
+	for _, resource := range []string{"cpu", "memory"} {
+		if requests[resource] != "" {
+			opts["requests."+resource] = requests[resource]
+		}
+		if limits[resource] != "" {
+			opts["limits."+resource] = limits[resource]
+		}
+	}
+
+	// tolerations have the format key=foo,value=bar,effect=NoSchedule;key=foo2,operator=Exists
+	tolerations := []string{}
+	for _, toleration := range inCluster.Tolerations {
+		fields := []string{}
+		if toleration.Key != "" {
+			fields = append(fields, "key="+toleration.Key)
+		}
+		if toleration.Operator != "" {
+			fields = append(fields, "operator="+string(toleration.Operator))
+		}
+		if toleration.Value != "" {
+			fields = append(fields, "value="+toleration.Value)
+		}
+		if toleration.Effect != "" {
+			fields = append(fields, "effect="+string(toleration.Effect))
+		}
+		if len(fields) > 0 {
+			tolerations = append(tolerations, strings.Join(fields, ","))
+		}
+	}
+	if len(tolerations) > 0 {
+		opts["tolerations"] = strings.Join(tolerations, ";")
+	}
+
+	return opts
+}
+
+// schedulingDriverOptArgs returns the --driver-opt arguments for the scheduling driver options. Buildx splits
+// the argument at commas like a csv line, so options that contain commas, e.g. tolerations, are double-quoted
+func schedulingDriverOptArgs(opts map[string]string) []string {
+	args := []string{}
+	for _, key := range schedulingDriverOptKeys {
+		if opts[key] == "" {
+			continue
+		}
+
+		opt := key + "=" + opts[key]
+		if strings.ContainsAny(opt, ",\"") {
+			opt = `"` + strings.ReplaceAll(opt, `"`, `""`) + `"`
+		}
+		args = append(args, "--driver-opt", opt)
+	}
+
+	return args
+}
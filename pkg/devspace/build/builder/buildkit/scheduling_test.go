@@ -0,0 +1,48 @@
+package buildkit
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"gotest.tools/assert"
+	k8sv1 "k8s.io/api/core/v1"
+)
+
+func TestSchedulingDriverOpts(t *testing.T) {
+	opts := schedulingDriverOpts(&latest.BuildKitInClusterConfig{
+		Resources: &latest.PodResources{
+			Preset: latest.PodResourcesPresetLarge,
+			Limits: map[string]string{"memory": "16Gi"},
+		},
+		Tolerations: []k8sv1.Toleration{
+			{Key: "builds", Operator: k8sv1.TolerationOpEqual, Value: "true", Effect: k8sv1.TaintEffectNoSchedule},
+			{Key: "spot", Operator: k8sv1.TolerationOpExists},
+		},
+	})
+
+	assert.DeepEqual(t, opts, map[string]string{
+		"requests.cpu":    "2",
+		"requests.memory": "4Gi",
+		"limits.cpu":      "4",
+		"limits.memory":   "16Gi",
+		"tolerations":     "key=builds,operator=Equal,value=true,effect=NoSchedule;key=spot,operator=Exists",
+	})
+}
+
+func TestSchedulingDriverOptArgs(t *testing.T) {
+	args := schedulingDriverOptArgs(map[string]string{
+		"requests.cpu": "2",
+		"limits.cpu":   "4",
+		"tolerations":  "key=builds,operator=Equal,value=true,effect=NoSchedule;key=spot,operator=Exists",
+	})
+
+	assert.DeepEqual(t, args, []string{
+		"--driver-opt", "requests.cpu=2",
+		"--driver-opt", "limits.cpu=4",
+		"--driver-opt", `"tolerations=key=builds,operator=Equal,value=true,effect=NoSchedule;key=spot,operator=Exists"`,
+	})
+
+	// a single toleration without commas doesn't need quotes
+	args = schedulingDriverOptArgs(map[string]string{"tolerations": "key=spot"})
+	assert.DeepEqual(t, args, []string{"--driver-opt", "tolerations=key=spot"})
+}
@@ -1,6 +1,8 @@
 package custom
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader/variable/runtime"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
@@ -28,13 +30,15 @@ var (
 type Builder struct {
 	imageConf *latest.Image
 	imageTags []string
+	skipPush  bool
 }
 
 // NewBuilder creates a new custom builder
-func NewBuilder(imageConf *latest.Image, imageTags []string) *Builder {
+func NewBuilder(imageConf *latest.Image, imageTags []string, skipPush bool) *Builder {
 	return &Builder{
 		imageConf: imageConf,
 		imageTags: imageTags,
+		skipPush:  skipPush,
 	}
 }
 
@@ -159,20 +163,72 @@ func (b *Builder) Build(ctx devspacecontext.Context) error {
 	}
 	defer writer.Close()
 
+	// with the json protocol the request is passed on stdin and stdout contains messages
+	var (
+		stdin    io.Reader
+		out      io.Writer = writer
+		messages *messageWriter
+	)
+	if b.imageConf.Custom.Protocol == latest.CustomProtocolJSON {
+		request, err := json.Marshal(b.request(ctx))
+		if err != nil {
+			return errors.Wrap(err, "marshal custom build request")
+		}
+
+		stdin = bytes.NewReader(request)
+		messages = &messageWriter{log: ctx.Log(), writer: writer}
+		out = messages
+	}
+
 	ctx.Log().Infof("Build %s:%s with custom command", b.imageConf.Image, b.imageTags[0])
 	ctx.Log().Debugf("Build %s:%s with custom command '%s %s' in working dir %s", b.imageConf.Image, b.imageTags[0], commandPath, strings.Join(args, " "), ctx.WorkingDir)
 	if len(args) == 0 {
-		err = engine.ExecuteSimpleShellCommand(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), writer, writer, nil, commandPath, args...)
+		err = engine.ExecuteSimpleShellCommand(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), out, writer, stdin, commandPath, args...)
 		if err != nil {
 			return errors.Errorf("error building image: %v", err)
 		}
 	} else {
-		err = command.Command(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), writer, writer, nil, commandPath, args...)
+		err = command.Command(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), out, writer, stdin, commandPath, args...)
+		if err != nil {
+			return errors.Errorf("error building image: %v", err)
+		}
+	}
+
+	if messages != nil {
+		digest, err := messages.Result()
 		if err != nil {
 			return errors.Errorf("error building image: %v", err)
 		}
+
+		// record the reported digest, so that deployments reference the pushed image by digest
+		if digest != "" {
+			imageCache, _ := ctx.Config().LocalCache().GetImageCache(b.imageConf.Name)
+			imageCache.Digest = digest
+			ctx.Config().LocalCache().SetImageCache(b.imageConf.Name, imageCache)
+		}
 	}
 
 	ctx.Log().Done("Done processing image '" + b.imageConf.Image + "'")
 	return nil
 }
+
+// request returns the request that is passed to commands using the json protocol
+func (b *Builder) request(ctx devspacecontext.Context) *Request {
+	request := &Request{
+		Name:      b.imageConf.Name,
+		Image:     b.imageConf.Image,
+		Tags:      b.imageTags,
+		Context:   ctx.WorkingDir(),
+		BuildArgs: b.imageConf.BuildArgs,
+		Platforms: b.imageConf.Platforms,
+		SkipPush:  b.skipPush || b.imageConf.SkipPush,
+	}
+	if b.imageConf.Context != "" {
+		request.Context = ctx.ResolvePath(b.imageConf.Context)
+	}
+	if b.imageConf.Dockerfile != "" {
+		request.Dockerfile = ctx.ResolvePath(b.imageConf.Dockerfile)
+	}
+
+	return request
+}
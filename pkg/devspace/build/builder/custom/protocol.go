@@ -0,0 +1,116 @@
+package custom
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// Request is written as json to stdin of custom build commands that use the json protocol
+type Request struct {
+	// Name is the name of the image config
+	Name string `json:"name"`
+	// Image is the image name without tag
+	Image string `json:"image"`
+	// Tags are the tags the image should be pushed with
+	Tags []string `json:"tags"`
+	// Context is the absolute path of the build context
+	Context string `json:"context"`
+	// Dockerfile is the absolute path of the dockerfile, if one is configured
+	Dockerfile string `json:"dockerfile,omitempty"`
+	// BuildArgs are the build args of the image config
+	BuildArgs map[string]*string `json:"buildArgs,omitempty"`
+	// Platforms are the platforms the image should be built for
+	Platforms []string `json:"platforms,omitempty"`
+	// SkipPush is true if the image should not be pushed
+	SkipPush bool `json:"skipPush"`
+}
+
+// Message is a line of json that custom build commands using the json protocol write to stdout
+type Message struct {
+	// Log is printed with the log level
+	Log string `json:"log,omitempty"`
+	// Level is the log level, one of debug, info, warn or error. Defaults to info
+	Level string `json:"level,omitempty"`
+	// Digest is the digest of the pushed image, e.g. sha256:...
+	Digest string `json:"digest,omitempty"`
+	// Error fails the build, even if the command exits successfully
+	Error string `json:"error,omitempty"`
+}
+
+// messageWriter parses the messages the command writes to stdout. Lines that aren't json are printed as they are
+type messageWriter struct {
+	log    logpkg.Logger
+	writer io.Writer
+
+	buffer []byte
+	digest string
+	err    error
+}
+
+func (m *messageWriter) Write(p []byte) (int, error) {
+	m.buffer = append(m.buffer, p...)
+	for {
+		i := bytes.IndexByte(m.buffer, '\n')
+		if i < 0 {
+			break
+		}
+
+		m.handle(m.buffer[:i])
+		m.buffer = m.buffer[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Result handles the last line and returns the digest the command reported
+func (m *messageWriter) Result() (string, error) {
+	if len(m.buffer) > 0 {
+		m.handle(m.buffer)
+		m.buffer = nil
+	}
+
+	return m.digest, m.err
+}
+
+func (m *messageWriter) handle(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	message := &Message{}
+	err := json.Unmarshal(line, message)
+	if err != nil {
+		_, _ = m.writer.Write(append(line, '\n'))
+		return
+	}
+
+	if message.Log != "" {
+		switch message.Level {
+		case "debug":
+			m.log.Debug(message.Log)
+		case "warn":
+			m.log.Warn(message.Log)
+		case "error":
+			m.log.Error(message.Log)
+		default:
+			_, _ = m.writer.Write([]byte(message.Log + "\n"))
+		}
+	}
+	if message.Digest != "" {
+		_, err := v1.NewHash(message.Digest)
+		if err != nil {
+			m.err = errors.Wrapf(err, "parse digest %s", message.Digest)
+		} else {
+			m.digest = message.Digest
+		}
+	}
+	if message.Error != "" && m.err == nil {
+		m.err = errors.New(message.Error)
+	}
+}
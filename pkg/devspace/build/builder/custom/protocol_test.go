@@ -0,0 +1,65 @@
+package custom
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+type messageWriterTestCase struct {
+	name string
+
+	output []string
+
+	expectedOutput string
+	expectedDigest string
+	expectedErr    string
+}
+
+func TestMessageWriter(t *testing.T) {
+	testCases := []messageWriterTestCase{
+		{
+			name:           "Logs and digest",
+			output:         []string{`{"log":"Step 1/2"}` + "\n" + `{"log":"debug","level":"debug"}` + "\n", `{"log":"Step 2/2"}`, "\n", `{"digest":"sha256:` + digestHex + `"}`},
+			expectedOutput: "Step 1/2\nStep 2/2\n",
+			expectedDigest: "sha256:" + digestHex,
+		},
+		{
+			name:           "Plain output",
+			output:         []string{"Building...\n", `{"log":"Done"}` + "\n"},
+			expectedOutput: "Building...\nDone\n",
+		},
+		{
+			name:        "Invalid digest",
+			output:      []string{`{"digest":"latest"}` + "\n"},
+			expectedErr: "parse digest latest: cannot parse hash: \"latest\"",
+		},
+		{
+			name:        "Error",
+			output:      []string{`{"error":"pack build failed"}` + "\n"},
+			expectedErr: "pack build failed",
+		},
+	}
+
+	for _, testCase := range testCases {
+		output := &bytes.Buffer{}
+		writer := &messageWriter{log: log.Discard, writer: output}
+		for _, chunk := range testCase.output {
+			_, err := writer.Write([]byte(chunk))
+			assert.NilError(t, err, testCase.name)
+		}
+
+		digest, err := writer.Result()
+		if testCase.expectedErr != "" {
+			assert.Error(t, err, testCase.expectedErr, testCase.name)
+		} else {
+			assert.NilError(t, err, testCase.name)
+		}
+		assert.Equal(t, output.String(), testCase.expectedOutput, testCase.name)
+		assert.Equal(t, digest, testCase.expectedDigest, testCase.name)
+	}
+}
+
+const digestHex = "4b825dc642cb6eb9a060e54bf8d69288fbee4904f1f0f7a3f8d5b9a3c4c5e6f7"
@@ -7,6 +7,8 @@ import (
 	"github.com/docker/cli/cli/streams"
 	"github.com/docker/distribution/reference"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/build/registry"
+	"github.com/loft-sh/devspace/pkg/devspace/build/scan"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	dockerclient "github.com/loft-sh/devspace/pkg/devspace/docker"
@@ -14,8 +16,14 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/pullsecrets"
 	command2 "github.com/loft-sh/utils/pkg/command"
 	"io"
+	"strings"
 
 	"github.com/docker/docker/api/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/loft-sh/devspace/pkg/devspace/build/localregistry"
 	"github.com/pkg/errors"
 
 	"github.com/docker/docker/pkg/jsonmessage"
@@ -32,6 +40,9 @@ type Builder struct {
 	client                    dockerclient.Client
 	skipPush                  bool
 	skipPushOnLocalKubernetes bool
+
+	// registryProvider loads the image instead of pushing it, if set
+	registryProvider registry.RegistryProvider
 }
 
 // NewBuilder creates a new docker Builder instance
@@ -44,6 +55,17 @@ func NewBuilder(ctx devspacecontext.Context, client dockerclient.Client, imageCo
 	}, nil
 }
 
+// NewRegistryProviderBuilder creates a docker builder that makes the images available to the cluster
+// with the given provider instead of pushing them to their registry
+func NewRegistryProviderBuilder(ctx devspacecontext.Context, client dockerclient.Client, imageConf *latest.Image, imageTags []string, provider registry.RegistryProvider) (*Builder, error) {
+	return &Builder{
+		helper:           helper.NewBuildHelper(ctx, EngineName, imageConf, imageTags),
+		client:           client,
+		skipPush:         true,
+		registryProvider: provider,
+	}, nil
+}
+
 // Build implements the interface
 func (b *Builder) Build(ctx devspacecontext.Context) error {
 	return b.helper.Build(ctx, b)
@@ -111,13 +133,6 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 		}
 	}
 
-	// create context stream
-	body, writer, outStream, buildOptions, err := b.helper.CreateContextStream(contextPath, dockerfilePath, entrypoint, cmd, ctx.Log())
-	defer writer.Close()
-	if err != nil {
-		return err
-	}
-
 	// Should we build with cli?
 	useBuildKit := false
 	useDockerCli := b.helper.ImageConf.Docker != nil && b.helper.ImageConf.Docker.UseCLI
@@ -128,29 +143,67 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 			useBuildKit = true
 		}
 	}
-	if useDockerCli || useBuildKit || len(cliArgs) > 0 {
-		err = b.client.ImageBuildCLI(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), useBuildKit, body, writer, cliArgs, *buildOptions, ctx.Log())
-		if err != nil {
-			return err
-		}
-	} else {
-		// make sure to use the correct proxy configuration
-		buildOptions.BuildArgs = b.client.ParseProxyConfig(buildOptions.BuildArgs)
+	if len(b.helper.ImageConf.Secrets) > 0 {
+		// secrets can only be mounted with BuildKit, which the docker api client doesn't support
+		useBuildKit = true
+		cliArgs = append(helper.SecretArgs(ctx, b.helper.ImageConf.Secrets), cliArgs...)
+	}
+	useCli := useDockerCli || useBuildKit || len(cliArgs) > 0
+
+	if b.helper.ImageConf.Attestations != nil {
+		ctx.Log().Warnf("Skip attestations of image %s, because they are only generated by BuildKit builds", b.helper.ImageName)
+	}
 
-		response, err := b.client.ImageBuild(ctx.Context(), body, *buildOptions)
+	// multiple platforms are built one after another and pushed as multi-arch index, each build
+	// creates its own context stream
+	platforms := b.helper.ImageConf.Platforms
+	pushImage := b.registryProvider == nil && !b.skipPush && !b.helper.ImageConf.SkipPush
+	if len(platforms) > 1 && pushImage {
+		err = b.buildPlatformIndex(ctx, contextPath, dockerfilePath, entrypoint, cmd, useCli, useBuildKit, cliArgs)
 		if err != nil {
-			return err
+			return errors.Errorf("error during multi-platform build: %v", err)
 		}
-		defer response.Body.Close()
 
-		err = jsonmessage.DisplayJSONMessagesStream(response.Body, outStream, outStream.FD(), outStream.IsTerminal(), nil)
+		ctx.Log().Info("Image index pushed to registry (" + displayRegistryURL + ")")
+		b.helper.RecordDigest(ctx, b.helper.ImageName+":"+b.helper.ImageTags[0])
+		return nil
+	}
+
+	// create context stream
+	body, writer, outStream, buildOptions, err := b.helper.CreateContextStream(contextPath, dockerfilePath, entrypoint, cmd, ctx.Log())
+	defer writer.Close()
+	if err != nil {
+		return err
+	}
+
+	if len(platforms) > 1 {
+		ctx.Log().Infof("Build image %s only for the platform of the docker daemon, because it is not pushed", b.helper.ImageName)
+	} else if len(platforms) == 1 {
+		buildOptions.Platform = platforms[0]
+	}
+
+	err = b.build(ctx, body, writer, outStream, buildOptions, useCli, useBuildKit, cliArgs)
+	if err != nil {
+		return err
+	}
+
+	// Scan the image before it is pushed or loaded
+	if b.helper.ImageConf.Scan != nil {
+		err = scan.Gate(ctx, b.helper.ImageConf.Scan, buildOptions.Tags[0])
 		if err != nil {
 			return err
 		}
 	}
 
 	// Check if we skip push
-	if !b.skipPush && !b.helper.ImageConf.SkipPush {
+	if b.registryProvider != nil && !b.helper.ImageConf.SkipPush {
+		err = b.registryProvider.Load(ctx, b.client, writer, buildOptions.Tags)
+		if err != nil {
+			return errors.Errorf("error during image load: %v", err)
+		}
+
+		ctx.Log().Info("Image loaded with " + b.registryProvider.Name())
+	} else if !b.skipPush && !b.helper.ImageConf.SkipPush {
 		for _, tag := range buildOptions.Tags {
 			err = b.pushImage(ctx.Context(), writer, tag)
 			if err != nil {
@@ -159,6 +212,8 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 
 			ctx.Log().Info("Image pushed to registry (" + displayRegistryURL + ")")
 		}
+
+		b.helper.RecordDigest(ctx, buildOptions.Tags[0])
 	} else if ctx.KubeClient() != nil && kubectl.GetKindContext(ctx.KubeClient().CurrentContext()) != "" {
 		// Load image if it is a kind-context
 		for _, tag := range buildOptions.Tags {
@@ -178,6 +233,105 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 	return nil
 }
 
+// build builds the image with the docker cli or the docker api
+func (b *Builder) build(ctx devspacecontext.Context, body io.Reader, writer io.Writer, outStream *streams.Out, buildOptions *types.ImageBuildOptions, useCli, useBuildKit bool, cliArgs []string) error {
+	if useCli {
+		return b.client.ImageBuildCLI(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), useBuildKit, body, writer, cliArgs, *buildOptions, ctx.Log())
+	}
+
+	// make sure to use the correct proxy configuration
+	buildOptions.BuildArgs = b.client.ParseProxyConfig(buildOptions.BuildArgs)
+
+	response, err := b.client.ImageBuild(ctx.Context(), body, *buildOptions)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return jsonmessage.DisplayJSONMessagesStream(response.Body, outStream, outStream.FD(), outStream.IsTerminal(), nil)
+}
+
+// buildPlatformIndex builds the image for each platform of the image and pushes them as multi-arch index with
+// the tags of the image. The images of the platforms are built with the platform as suffix of the first tag, so
+// that they don't overwrite each other in the docker daemon, and these tags are removed after the push
+func (b *Builder) buildPlatformIndex(ctx devspacecontext.Context, contextPath, dockerfilePath string, entrypoint []string, cmd []string, useCli, useBuildKit bool, cliArgs []string) error {
+	var tags []string
+	platformImages := map[string]v1.Image{}
+	for _, platform := range b.helper.ImageConf.Platforms {
+		body, writer, outStream, buildOptions, err := b.helper.CreateContextStream(contextPath, dockerfilePath, entrypoint, cmd, ctx.Log())
+		if err != nil {
+			writer.Close()
+			return err
+		}
+
+		tags = buildOptions.Tags
+		platformTag := tags[0] + "-" + strings.ReplaceAll(platform, "/", "-")
+		buildOptions.Tags = []string{platformTag}
+		buildOptions.Platform = platform
+		err = b.build(ctx, body, writer, outStream, buildOptions, useCli, useBuildKit, cliArgs)
+		writer.Close()
+		if err != nil {
+			return errors.Wrapf(err, "build platform %s", platform)
+		}
+		defer b.removeTag(ctx, platformTag)
+
+		if b.helper.ImageConf.Scan != nil {
+			err = scan.Gate(ctx, b.helper.ImageConf.Scan, platformTag)
+			if err != nil {
+				return err
+			}
+		}
+
+		ref, err := name.ParseReference(platformTag)
+		if err != nil {
+			return err
+		}
+
+		platformImages[platform], err = daemon.Image(ref, daemon.WithContext(ctx.Context()), daemon.WithClient(b.client.DockerAPIClient()))
+		if err != nil {
+			return err
+		}
+	}
+
+	index, err := registry.NewPlatformIndex(platformImages)
+	if err != nil {
+		return errors.Wrap(err, "create image index")
+	}
+
+	scheduler := registry.PushSchedulerFrom(ctx.Context())
+	release, err := scheduler.Acquire(ctx.Context())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	hosts := registry.NewHosts(ctx.Config().Config())
+	options := append(hosts.RemoteOptions(), remote.WithContext(ctx.Context()), remote.WithAuthFromKeychain(localregistry.Keychain))
+	limitedIndex := scheduler.Limit(ctx.Context(), index).(v1.ImageIndex)
+	for _, tag := range tags {
+		ref, err := hosts.ParseReference(tag)
+		if err != nil {
+			return err
+		}
+
+		ctx.Log().Infof("Push image index %s", tag)
+		err = remote.WriteIndex(ref, limitedIndex, options...)
+		if err != nil {
+			return errors.Wrapf(err, "push image index %s", tag)
+		}
+	}
+
+	return nil
+}
+
+// removeTag removes the tag from the docker daemon. The image is only deleted if it has no other tags
+func (b *Builder) removeTag(ctx devspacecontext.Context, tag string) {
+	_, err := b.client.DockerAPIClient().ImageRemove(ctx.Context(), tag, types.ImageRemoveOptions{})
+	if err != nil {
+		ctx.Log().Debugf("Error removing tag %s: %v", tag, err)
+	}
+}
+
 // Authenticate authenticates the client with a remote registry
 func (b *Builder) Authenticate(ctx context.Context) (*types.AuthConfig, error) {
 	registryURL, err := pullsecrets.GetRegistryFromImageName(b.helper.ImageName + ":" + b.helper.ImageTags[0])
@@ -205,6 +359,13 @@ func (b *Builder) pushImage(ctx context.Context, writer io.Writer, imageName str
 		return err
 	}
 
+	// the docker daemon uploads the image, so only the number of concurrent pushes can be limited
+	release, err := registry.PushSchedulerFrom(ctx).Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	out, err := b.client.ImagePush(ctx, reference.FamiliarString(ref), types.ImagePushOptions{
 		RegistryAuth: encodedAuth,
 	})
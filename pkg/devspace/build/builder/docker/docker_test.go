@@ -0,0 +1,249 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	gcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	devspacedocker "github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+// fakeTrivy reports a high vulnerability for every image
+const fakeTrivy = `#!/bin/sh
+echo '{"Results":[{"Vulnerabilities":[{"VulnerabilityID":"CVE-2023-0001","PkgName":"openssl","InstalledVersion":"3.0.1","Severity":"HIGH"}]}]}'
+`
+
+// configImage is an image without layers that only differs by its config
+type configImage string
+
+func (c configImage) RawConfigFile() ([]byte, error) {
+	return []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]},"config":{"Env":["IMAGE=` + string(c) + `"]}}`), nil
+}
+
+func (c configImage) MediaType() (gcrtypes.MediaType, error) {
+	return gcrtypes.DockerManifestSchema2, nil
+}
+
+func (c configImage) LayerByDiffID(v1.Hash) (partial.UncompressedLayer, error) {
+	return nil, fmt.Errorf("image has no layers")
+}
+
+// fakeDaemon records the builds and removals and serves the built images
+type fakeDaemon struct {
+	dockerclient.CommonAPIClient
+
+	m        sync.Mutex
+	builds   []string
+	removed  []string
+	contexts int
+}
+
+func (f *fakeDaemon) image(tag string) (v1.Image, error) {
+	return partial.UncompressedToImage(configImage(tag))
+}
+
+func (f *fakeDaemon) ImageInspectWithRaw(_ context.Context, tag string) (types.ImageInspect, []byte, error) {
+	img, err := f.image(tag)
+	if err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+
+	id, err := img.ConfigName()
+	if err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+
+	return types.ImageInspect{ID: id.String()}, nil, nil
+}
+
+func (f *fakeDaemon) ImageSave(_ context.Context, tags []string) (io.ReadCloser, error) {
+	ref, err := name.ParseReference(tags[0])
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := f.image(ref.String())
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	err = tarball.Write(ref, img, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(buf), nil
+}
+
+func (f *fakeDaemon) NegotiateAPIVersion(context.Context) {}
+
+func (f *fakeDaemon) ImageRemove(_ context.Context, tag string, _ types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.removed = append(f.removed, tag)
+	return nil, nil
+}
+
+type fakeClient struct {
+	devspacedocker.Client
+
+	daemon *fakeDaemon
+}
+
+func (f *fakeClient) ImageBuild(_ context.Context, body io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+	_, err := io.Copy(io.Discard, body)
+	if err != nil {
+		return types.ImageBuildResponse{}, err
+	}
+
+	f.daemon.m.Lock()
+	defer f.daemon.m.Unlock()
+
+	f.daemon.contexts++
+	f.daemon.builds = append(f.daemon.builds, options.Platform+" "+strings.Join(options.Tags, ","))
+	return types.ImageBuildResponse{Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func (f *fakeClient) ParseProxyConfig(buildArgs map[string]*string) map[string]*string {
+	return buildArgs
+}
+
+func (f *fakeClient) DockerAPIClient() dockerclient.CommonAPIClient {
+	return f.daemon
+}
+
+func TestBuildPlatformIndex(t *testing.T) {
+	var (
+		m         sync.Mutex
+		manifests []string
+		uploads   int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/blobs/uploads/"):
+			uploads++
+			w.Header().Set("Location", fmt.Sprintf("/upload/%d", uploads))
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/upload/"):
+			w.Header().Set("Location", r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/upload/"):
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/manifests/"):
+			manifests = append(manifests, r.Header.Get("Content-Type")+" "+r.URL.Path[strings.Index(r.URL.Path, "/manifests/")+len("/manifests/"):])
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	registryHost := strings.TrimPrefix(server.URL, "http://")
+
+	contextPath := t.TempDir()
+	dockerfilePath := filepath.Join(contextPath, "Dockerfile")
+	err := os.WriteFile(dockerfilePath, []byte("FROM scratch\n"), 0644)
+	assert.NilError(t, err)
+
+	rawConfig := latest.NewRaw()
+	rawConfig.LocalRegistry = &latest.LocalRegistryConfig{
+		Registries: map[string]*latest.RegistryHostConfig{registryHost: {Insecure: true}},
+	}
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		rawConfig,
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf).WithWorkingDir(contextPath)
+
+	imageConf := &latest.Image{
+		Name:      "api",
+		Image:     registryHost + "/api",
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+	}
+	daemon := &fakeDaemon{}
+	builder := &Builder{
+		helper: helper.NewBuildHelper(ctx, EngineName, imageConf, []string{"v1", "latest"}),
+		client: &fakeClient{daemon: daemon},
+	}
+
+	err = builder.buildPlatformIndex(ctx, contextPath, dockerfilePath, nil, nil, false, false, nil)
+	assert.NilError(t, err)
+
+	// every platform is built with its own context and a temporary tag
+	assert.Equal(t, daemon.contexts, 2)
+	assert.DeepEqual(t, daemon.builds, []string{
+		"linux/amd64 " + registryHost + "/api:v1-linux-amd64",
+		"linux/arm64 " + registryHost + "/api:v1-linux-arm64",
+	})
+
+	// the temporary tags are removed after the push
+	sort.Strings(daemon.removed)
+	assert.DeepEqual(t, daemon.removed, []string{
+		registryHost + "/api:v1-linux-amd64",
+		registryHost + "/api:v1-linux-arm64",
+	})
+
+	// the index is pushed with every tag of the image
+	indexes := []string{}
+	for _, manifest := range manifests {
+		if strings.HasPrefix(manifest, string(gcrtypes.DockerManifestList)) {
+			indexes = append(indexes, strings.TrimPrefix(manifest, string(gcrtypes.DockerManifestList)+" "))
+		}
+	}
+	sort.Strings(indexes)
+	assert.DeepEqual(t, indexes, []string{"latest", "v1"})
+
+	// images with vulnerabilities are not pushed
+	if runtime.GOOS == "windows" {
+		return
+	}
+	binDir := t.TempDir()
+	err = os.WriteFile(filepath.Join(binDir, "trivy"), []byte(fakeTrivy), 0755)
+	assert.NilError(t, err)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	manifests = nil
+	daemon.removed = nil
+	imageConf.Scan = &latest.ImageScan{}
+	err = builder.buildPlatformIndex(ctx, contextPath, dockerfilePath, nil, nil, false, false, nil)
+	assert.ErrorContains(t, err, "image "+registryHost+"/api:v1-linux-amd64 has 1 vulnerabilities with severity high or higher")
+	assert.Equal(t, len(manifests), 0)
+	assert.DeepEqual(t, daemon.removed, []string{registryHost + "/api:v1-linux-amd64"})
+}
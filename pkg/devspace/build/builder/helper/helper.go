@@ -5,7 +5,11 @@ import (
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/pkg/streamformatter"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/restart"
+	"github.com/loft-sh/devspace/pkg/devspace/build/localregistry"
+	"github.com/loft-sh/devspace/pkg/devspace/build/registry"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
 	"github.com/loft-sh/devspace/pkg/util/kubeconfig"
 	logpkg "github.com/loft-sh/devspace/pkg/util/log"
 	dockerterm "github.com/moby/term"
@@ -101,61 +105,126 @@ func (b *BuildHelper) Build(ctx devspacecontext.Context, imageBuilder BuildHelpe
 	return nil
 }
 
-// ShouldRebuild determines if the image should be rebuilt
-func (b *BuildHelper) ShouldRebuild(ctx devspacecontext.Context, forceRebuild bool) (bool, error) {
+// RecordDigest resolves the digest of the pushed image and stores it in the image cache, so that
+// deployments reference the image by its digest instead of the mutable tag
+func (b *BuildHelper) RecordDigest(ctx devspacecontext.Context, image string, options ...remote.Option) {
+	digest, err := localregistry.GetImageDigest(ctx.Context(), image, registry.NewHosts(ctx.Config().Config()), options...)
+	if err != nil {
+		ctx.Log().Debugf("Error resolving digest of image %s: %v", image, err)
+		return
+	}
+
 	imageCache, _ := ctx.Config().LocalCache().GetImageCache(b.ImageConf.Name)
+	imageCache.Digest = digest
+	ctx.Config().LocalCache().SetImageCache(b.ImageConf.Name, imageCache)
+}
 
-	// if rebuild strategy is always, we return here
-	if b.ImageConf.RebuildStrategy == latest.RebuildStrategyAlways {
-		ctx.Log().Infof("Rebuild image %s because strategy is always rebuild", imageCache.ImageName)
-		return true, nil
+// SaveSBOM downloads the SBOM attestation of the pushed image and saves it to SBOMPath, if the image
+// is configured to generate one
+func (b *BuildHelper) SaveSBOM(ctx devspacecontext.Context, image string, options ...remote.Option) {
+	if b.ImageConf.Attestations == nil || !b.ImageConf.Attestations.SBOM {
+		return
 	}
 
-	// Hash dockerfile
-	_, err := os.Stat(b.DockerfilePath)
-	if err != nil {
-		return false, errors.Errorf("Dockerfile %s missing: %v", b.DockerfilePath, err)
+	// make sure the hooks don't pick up the sbom of a previous build
+	sbomPath := SBOMPath(ctx, b.ImageConf.Name)
+	_ = os.Remove(sbomPath)
+
+	hosts := registry.NewHosts(ctx.Config().Config())
+	if len(options) == 0 {
+		options = append(hosts.RemoteOptions(), remote.WithAuthFromKeychain(localregistry.Keychain))
 	}
-	dockerfileHash, err := hash.Directory(b.DockerfilePath)
+
+	sbom, err := registry.GetImageSBOM(ctx.Context(), image, hosts, options...)
 	if err != nil {
-		return false, errors.Wrap(err, "hash dockerfile")
+		ctx.Log().Warnf("Error retrieving sbom of image %s: %v", image, err)
+		return
 	}
 
-	// Hash image config
-	configStr, err := yaml.Marshal(*b.ImageConf)
+	err = os.MkdirAll(filepath.Dir(sbomPath), 0755)
+	if err == nil {
+		err = os.WriteFile(sbomPath, sbom, 0644)
+	}
 	if err != nil {
-		return false, errors.Wrap(err, "marshal image config")
+		ctx.Log().Warnf("Error saving sbom of image %s: %v", image, err)
+		return
 	}
 
-	imageConfigHash := hash.String(string(configStr))
+	ctx.Log().Infof("Saved sbom of image %s to %s", image, sbomPath)
+}
 
-	// Hash entrypoint
-	entrypointHash := ""
-	if len(b.Entrypoint) > 0 {
-		for _, str := range b.Entrypoint {
-			entrypointHash += str
-		}
+// SBOMPath returns the path the SBOM of the image is saved to
+func SBOMPath(ctx devspacecontext.Context, imageConfigName string) string {
+	return ctx.ResolvePath(filepath.Join(constants.DefaultCacheFolder, "sbom", imageConfigName+".spdx.json"))
+}
+
+// AttestationArgs returns the arguments for docker buildx to generate the attestations of the image
+func AttestationArgs(attestations *latest.BuildAttestations) []string {
+	args := []string{}
+	if attestations != nil && attestations.SBOM {
+		args = append(args, "--sbom=true")
 	}
-	if len(b.Cmd) > 0 {
-		for _, str := range b.Cmd {
-			entrypointHash += str
-		}
+	if attestations != nil && attestations.Provenance {
+		args = append(args, "--provenance=mode=max")
 	}
-	if entrypointHash != "" {
-		entrypointHash = hash.String(entrypointHash)
+
+	return args
+}
+
+// ShouldRebuild determines if the image should be rebuilt
+func (b *BuildHelper) ShouldRebuild(ctx devspacecontext.Context, forceRebuild bool) (bool, error) {
+	imageCache, _ := ctx.Config().LocalCache().GetImageCache(b.ImageConf.Name)
+
+	// if rebuild strategy is always, we return here
+	if b.ImageConf.RebuildStrategy == latest.RebuildStrategyAlways {
+		ctx.Log().Infof("Rebuild image %s because strategy is always rebuild", imageCache.ImageName)
+		return true, nil
+	}
+
+	dockerfileHash, imageConfigHash, entrypointHash, err := b.configHashes()
+	if err != nil {
+		return false, err
 	}
 
 	// only rebuild Docker image when Dockerfile or context has changed since latest build
-	mustRebuild := imageCache.Tag == "" || imageCache.DockerfileHash != dockerfileHash || imageCache.ImageConfigHash != imageConfigHash || imageCache.EntrypointHash != entrypointHash
+	reason := ""
 	if imageCache.Tag == "" {
-		ctx.Log().Infof("Rebuild image %s because tag is missing", imageCache.ImageName)
+		reason = "tag is missing"
 	} else if imageCache.DockerfileHash != dockerfileHash {
-		ctx.Log().Infof("Rebuild image %s because dockerfile has changed", imageCache.ImageName)
+		reason = "dockerfile has changed"
 	} else if imageCache.ImageConfigHash != imageConfigHash {
-		ctx.Log().Infof("Rebuild image %s because image config has changed", imageCache.ImageName)
+		reason = "image config has changed"
 	} else if imageCache.EntrypointHash != entrypointHash {
-		ctx.Log().Infof("Rebuild image %s because entrypoint has changed", imageCache.ImageName)
+		reason = "entrypoint has changed"
+	}
+
+	// Check if should consider context path changes for rebuilding
+	contextHash := ""
+	if b.ImageConf.RebuildStrategy != latest.RebuildStrategyIgnoreContextChanges {
+		contextHash, err = b.contextHash()
+		if err != nil {
+			return false, err
+		}
+
+		if reason == "" && imageCache.ContextHash != contextHash {
+			reason = "build context has changed"
+		}
+	}
+
+	// the dockerfile hash also changes if the file was only touched, so skip the rebuild if
+	// the dockerfile content, the context and the build args are the same as in the last build
+	contentHash, err := b.buildContentHash(imageConfigHash, entrypointHash, contextHash)
+	if err != nil {
+		return false, err
+	}
+	if reason != "" && imageCache.Tag != "" && imageCache.ContentHash == contentHash {
+		ctx.Log().Debugf("Skip rebuild of image %s, because its content is unchanged although %s", imageCache.ImageName, reason)
+		reason = ""
+	}
+	if reason != "" {
+		ctx.Log().Infof("Rebuild image %s because %s", imageCache.ImageName, reason)
 	}
+	mustRebuild := reason != ""
 
 	var lastContextClient kubectl.Client
 	if ctx.Config().LocalCache().GetLastContext() != nil {
@@ -185,48 +254,166 @@ func (b *BuildHelper) ShouldRebuild(ctx devspacecontext.Context, forceRebuild bo
 		})
 	}
 
-	// Check if should consider context path changes for rebuilding
+	// the image is up to date or will be rebuilt from the current content, so the hashes can be updated
+	imageCache.DockerfileHash = dockerfileHash
+	imageCache.ImageConfigHash = imageConfigHash
+	imageCache.EntrypointHash = entrypointHash
+	imageCache.ContentHash = contentHash
+	if contextHash != "" {
+		imageCache.ContextHash = contextHash
+	}
+
+	ctx.Config().LocalCache().SetImageCache(b.ImageConf.Name, imageCache)
+	return mustRebuild, nil
+}
+
+// RebuildReason returns the reason why the image would be rebuilt or an empty string if the
+// image is up to date. In contrast to ShouldRebuild the local cache is not changed
+func (b *BuildHelper) RebuildReason(ctx devspacecontext.Context) (string, error) {
+	imageCache, _ := ctx.Config().LocalCache().GetImageCache(b.ImageConf.Name)
+	if imageCache.Tag == "" {
+		return "image was never built", nil
+	}
+
+	dockerfileHash, imageConfigHash, entrypointHash, err := b.configHashes()
+	if err != nil {
+		return "", err
+	}
+
+	reason := ""
+	if imageCache.DockerfileHash != dockerfileHash {
+		reason = "dockerfile has changed"
+	} else if imageCache.ImageConfigHash != imageConfigHash {
+		reason = "image config has changed"
+	} else if imageCache.EntrypointHash != entrypointHash {
+		reason = "entrypoint has changed"
+	}
+
+	contextHash := ""
 	if b.ImageConf.RebuildStrategy != latest.RebuildStrategyIgnoreContextChanges {
-		// Hash context path
-		contextDir, relDockerfile, err := build.GetContextFromLocalDir(b.ContextPath, b.DockerfilePath)
+		contextHash, err = b.contextHash()
 		if err != nil {
-			return false, errors.Wrap(err, "get context from local dir")
+			return "", err
 		}
 
-		relDockerfile = archive.CanonicalTarNameForPath(relDockerfile)
-		excludes, err := ReadDockerignore(contextDir, relDockerfile)
-		if err != nil {
-			return false, errors.Errorf("Error reading .dockerignore: %v", err)
+		if reason == "" && imageCache.ContextHash != contextHash {
+			reason = "build context has changed"
 		}
+	}
+	if reason == "" {
+		return "", nil
+	}
 
-		contextHash, err := hash.DirectoryExcludes(contextDir, excludes, false)
-		if err != nil {
-			return false, errors.Errorf("Error hashing %s: %v", contextDir, err)
-		}
+	contentHash, err := b.buildContentHash(imageConfigHash, entrypointHash, contextHash)
+	if err != nil {
+		return "", err
+	} else if imageCache.ContentHash == contentHash {
+		return "", nil
+	}
+
+	return reason, nil
+}
 
-		if !mustRebuild && imageCache.ContextHash != contextHash {
-			ctx.Log().Infof("Rebuild image %s because build context has changed", imageCache.ImageName)
+// configHashes returns the hashes of the dockerfile, the image config and the entrypoint
+func (b *BuildHelper) configHashes() (string, string, string, error) {
+	// Hash dockerfile
+	_, err := os.Stat(b.DockerfilePath)
+	if err != nil {
+		return "", "", "", errors.Errorf("Dockerfile %s missing: %v", b.DockerfilePath, err)
+	}
+	dockerfileHash, err := hash.Directory(b.DockerfilePath)
+	if err != nil {
+		return "", "", "", errors.Wrap(err, "hash dockerfile")
+	}
+
+	// Hash image config, the build cache and the scan don't change the image
+	imageConf := *b.ImageConf
+	imageConf.CacheFrom = nil
+	imageConf.CacheTo = nil
+	imageConf.Scan = nil
+	configStr, err := yaml.Marshal(imageConf)
+	if err != nil {
+		return "", "", "", errors.Wrap(err, "marshal image config")
+	}
+
+	imageConfigHash := hash.String(string(configStr))
+
+	// Hash entrypoint
+	entrypointHash := ""
+	if len(b.Entrypoint) > 0 {
+		for _, str := range b.Entrypoint {
+			entrypointHash += str
 		}
-		mustRebuild = mustRebuild || imageCache.ContextHash != contextHash
-
-		// TODO: This is not an ideal solution since there can be the issue that the user runs
-		// devspace dev & the generated.yaml is written without ContextHash and on a subsequent
-		// devspace deploy the image would be rebuild, because the ContextHash was empty and is
-		// now different. However in this case it is probably better to save the context hash computing
-		// time during devspace dev instead of always hashing the context path.
-		if forceRebuild || mustRebuild {
-			imageCache.ContextHash = contextHash
+	}
+	if len(b.Cmd) > 0 {
+		for _, str := range b.Cmd {
+			entrypointHash += str
 		}
 	}
+	if entrypointHash != "" {
+		entrypointHash = hash.String(entrypointHash)
+	}
+
+	return dockerfileHash, imageConfigHash, entrypointHash, nil
+}
+
+// buildContentHash returns a hash of the dockerfile content, the image config, the entrypoint and the build context.
+// In contrast to the dockerfile hash it doesn't change if the dockerfile was only touched
+func (b *BuildHelper) buildContentHash(imageConfigHash, entrypointHash, contextHash string) (string, error) {
+	dockerfileHash, err := hash.File(b.DockerfilePath)
+	if err != nil {
+		return "", errors.Errorf("hash dockerfile %s: %v", b.DockerfilePath, err)
+	}
+
+	return hash.String(strings.Join([]string{dockerfileHash, imageConfigHash, entrypointHash, contextHash}, ";")), nil
+}
 
-	if forceRebuild || mustRebuild {
-		imageCache.DockerfileHash = dockerfileHash
-		imageCache.ImageConfigHash = imageConfigHash
-		imageCache.EntrypointHash = entrypointHash
+// ContentHash returns a hash of the dockerfile content, the build context and the image config without
+// its name and paths. Images with the same content hash produce the same image, even if they are
+// defined by different dependencies
+func (b *BuildHelper) ContentHash() (string, error) {
+	dockerfileHash, err := hash.File(b.DockerfilePath)
+	if err != nil {
+		return "", errors.Errorf("hash dockerfile %s: %v", b.DockerfilePath, err)
 	}
 
-	ctx.Config().LocalCache().SetImageCache(b.ImageConf.Name, imageCache)
-	return mustRebuild, nil
+	imageConf := *b.ImageConf
+	imageConf.Name = ""
+	imageConf.Dockerfile = ""
+	imageConf.Context = ""
+	configStr, err := yaml.Marshal(imageConf)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal image config")
+	}
+
+	contextHash, err := b.contextHash()
+	if err != nil {
+		return "", err
+	}
+
+	return hash.String(strings.Join([]string{dockerfileHash, string(configStr), contextHash}, ";")), nil
+}
+
+// contextHash returns the hash of the build context without the files excluded by the .dockerignore
+func (b *BuildHelper) contextHash() (string, error) {
+	// Hash context path
+	contextDir, relDockerfile, err := build.GetContextFromLocalDir(b.ContextPath, b.DockerfilePath)
+	if err != nil {
+		return "", errors.Wrap(err, "get context from local dir")
+	}
+
+	relDockerfile = archive.CanonicalTarNameForPath(relDockerfile)
+	excludes, err := ReadDockerignore(contextDir, relDockerfile)
+	if err != nil {
+		return "", errors.Errorf("Error reading .dockerignore: %v", err)
+	}
+
+	contextHash, err := hash.DirectoryExcludes(contextDir, excludes, false)
+	if err != nil {
+		return "", errors.Errorf("Error hashing %s: %v", contextDir, err)
+	}
+
+	return contextHash, nil
 }
 
 func (b *BuildHelper) IsImageAvailableLocally(ctx devspacecontext.Context, dockerClient dockerclient.Client) (bool, error) {
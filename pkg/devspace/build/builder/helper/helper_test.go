@@ -2,8 +2,11 @@ package helper
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config"
 	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
@@ -224,3 +227,51 @@ func hasBuildKit() bool {
 	err := cmd.Run()
 	return err == nil
 }
+
+func TestShouldRebuildContentHash(t *testing.T) {
+	dir := t.TempDir()
+	dockerfilePath := filepath.Join(dir, "Dockerfile")
+	err := os.WriteFile(dockerfilePath, []byte("FROM alpine"), 0644)
+	assert.NilError(t, err)
+
+	helper := &BuildHelper{
+		DockerfilePath: dockerfilePath,
+		ContextPath:    dir,
+		ImageConf: &latest.Image{
+			Name:  "ImageConf",
+			Image: "image1",
+		},
+	}
+	cache := &localcache.LocalCache{
+		Images: map[string]localcache.ImageCache{},
+	}
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(config.NewConfig(nil, nil, latest.NewRaw(), cache, &remotecache.RemoteCache{}, nil, ""))
+
+	rebuild, err := helper.ShouldRebuild(ctx, false)
+	assert.NilError(t, err)
+	assert.Assert(t, rebuild, "Expected rebuild because the image was never built")
+	imageCache, _ := cache.GetImageCache("ImageConf")
+	assert.Assert(t, imageCache.ContentHash != "", "Expected the content hash to be saved")
+	imageCache.Tag = "dbysxsH"
+	cache.SetImageCache("ImageConf", imageCache)
+
+	rebuild, err = helper.ShouldRebuild(ctx, false)
+	assert.NilError(t, err)
+	assert.Assert(t, !rebuild, "Expected no rebuild because nothing has changed")
+
+	// touching the dockerfile changes the dockerfile hash, but not the content
+	err = os.Chtimes(dockerfilePath, time.Now(), time.Now().Add(time.Hour))
+	assert.NilError(t, err)
+	rebuild, err = helper.ShouldRebuild(ctx, false)
+	assert.NilError(t, err)
+	assert.Assert(t, !rebuild, "Expected no rebuild because the dockerfile content is unchanged")
+
+	err = os.WriteFile(dockerfilePath, []byte("FROM ubuntu"), 0644)
+	assert.NilError(t, err)
+	reason, err := helper.RebuildReason(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, reason, "dockerfile has changed")
+	rebuild, err = helper.ShouldRebuild(ctx, false)
+	assert.NilError(t, err)
+	assert.Assert(t, rebuild, "Expected rebuild because the dockerfile has changed")
+}
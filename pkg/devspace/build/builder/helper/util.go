@@ -107,6 +107,97 @@ func GetDockerfileAndContext(ctx devspacecontext.Context, imageConf *latest.Imag
 	return ctx.ResolvePath(dockerfilePath), ctx.ResolvePath(contextPath)
 }
 
+// SecretArgs returns the --secret arguments for the docker and BuildKit cli. Environment variables are
+// read by the cli itself, so only the name of the variable shows up in the command
+func SecretArgs(ctx devspacecontext.Context, secrets []*latest.BuildSecret) []string {
+	args := []string{}
+	for _, secret := range secrets {
+		if secret.Env != "" {
+			args = append(args, "--secret", "id="+secret.ID+",env="+secret.Env)
+		} else {
+			args = append(args, "--secret", "id="+secret.ID+",src="+ctx.ResolvePath(secret.File))
+		}
+	}
+
+	return args
+}
+
+// ReadSecret returns the value of the build secret
+func ReadSecret(ctx devspacecontext.Context, secret *latest.BuildSecret) ([]byte, error) {
+	if secret.Env != "" {
+		value := ctx.Environ().Get(secret.Env)
+		if !value.IsSet() {
+			return nil, fmt.Errorf("environment variable %s of build secret %s is not set", secret.Env, secret.ID)
+		}
+
+		return []byte(value.String()), nil
+	}
+
+	out, err := os.ReadFile(ctx.ResolvePath(secret.File))
+	if err != nil {
+		return nil, errors.Wrapf(err, "read build secret %s", secret.ID)
+	}
+
+	return out, nil
+}
+
+// resourcePresets are the requests and limits of the build pod resource presets
+var resourcePresets = map[string]latest.PodResources{
+	latest.PodResourcesPresetSmall: {
+		Requests: map[string]string{"cpu": "500m", "memory": "1Gi"},
+		Limits:   map[string]string{"cpu": "1", "memory": "2Gi"},
+	},
+	latest.PodResourcesPresetMedium: {
+		Requests: map[string]string{"cpu": "1", "memory": "2Gi"},
+		Limits:   map[string]string{"cpu": "2", "memory": "4Gi"},
+	},
+	latest.PodResourcesPresetLarge: {
+		Requests: map[string]string{"cpu": "2", "memory": "4Gi"},
+		Limits:   map[string]string{"cpu": "4", "memory": "8Gi"},
+	},
+}
+
+// PodResources returns the requests and limits of a build pod. The explicit requests and limits
+// override the ones of the preset
+func PodResources(resources *latest.PodResources) (map[string]string, map[string]string) {
+	requests, limits := map[string]string{}, map[string]string{}
+	if resources == nil {
+		return requests, limits
+	}
+
+	preset := resourcePresets[resources.Preset]
+	for k, v := range preset.Requests {
+		requests[k] = v
+	}
+	for k, v := range preset.Limits {
+		limits[k] = v
+	}
+	for k, v := range resources.Requests {
+		requests[k] = v
+	}
+	for k, v := range resources.Limits {
+		limits[k] = v
+	}
+
+	return requests, limits
+}
+
+// ReadSecrets returns the values of the build secrets by their id, for builds that pass the secrets to
+// the BuildKit session themselves
+func ReadSecrets(ctx devspacecontext.Context, secrets []*latest.BuildSecret) (map[string][]byte, error) {
+	values := map[string][]byte{}
+	for _, secret := range secrets {
+		value, err := ReadSecret(ctx, secret)
+		if err != nil {
+			return nil, err
+		}
+
+		values[secret.ID] = value
+	}
+
+	return values, nil
+}
+
 // InjectBuildScriptInContext will add the restart helper script to the build context
 func InjectBuildScriptInContext(helperScript string, buildCtx io.ReadCloser) (io.ReadCloser, error) {
 	now := time.Now()
@@ -1,10 +1,16 @@
 package helper
 
 import (
+	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/loft-sh/devspace/pkg/util/fsutil"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"mvdan.cc/sh/v3/expand"
 
 	"gotest.tools/assert"
 )
@@ -37,3 +43,36 @@ func TestCreateTempDockerfile(t *testing.T) {
 	assert.NilError(t, err, "Temporary Dockerfile not created.")
 	assert.Equal(t, "\n\nENTRYPOINT [\"echo\"]\n\n\nCMD [\"\"]\n", string(dockerfileContent), "Temporary dockerfile has wrong content")
 }
+
+func TestSecrets(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, ".npmrc"), []byte("//registry.npmjs.org/:_authToken=file"), 0600)
+	assert.NilError(t, err)
+
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).
+		WithWorkingDir(dir).
+		WithEnviron(expand.ListEnviron("NPM_TOKEN=env"))
+	secrets := []*latest.BuildSecret{
+		{ID: "token", Env: "NPM_TOKEN"},
+		{ID: "npmrc", File: ".npmrc"},
+	}
+
+	// the cli reads the environment variable itself, files are passed with their absolute path
+	assert.DeepEqual(t, SecretArgs(ctx, secrets), []string{
+		"--secret", "id=token,env=NPM_TOKEN",
+		"--secret", "id=npmrc,src=" + filepath.Join(dir, ".npmrc"),
+	})
+
+	values, err := ReadSecrets(ctx, secrets)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, map[string][]byte{
+		"token": []byte("env"),
+		"npmrc": []byte("//registry.npmjs.org/:_authToken=file"),
+	})
+
+	// missing secrets are errors instead of empty values
+	_, err = ReadSecret(ctx, &latest.BuildSecret{ID: "missing", Env: "MISSING_TOKEN"})
+	assert.Error(t, err, "environment variable MISSING_TOKEN of build secret missing is not set")
+	_, err = ReadSecrets(ctx, []*latest.BuildSecret{{ID: "missing", File: "missing"}})
+	assert.ErrorContains(t, err, "read build secret missing")
+}
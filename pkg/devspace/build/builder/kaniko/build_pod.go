@@ -2,8 +2,12 @@ package kaniko
 
 import (
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/kaniko/util"
+	"github.com/loft-sh/devspace/pkg/devspace/build/registry"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 
 	"github.com/docker/distribution/reference"
@@ -25,11 +29,15 @@ import (
 const kanikoInitImage = "alpine"
 
 // The kaniko build image we use by default
-const kanikoBuildImage = "gcr.io/kaniko-project/executor:v1.8.1"
+const kanikoBuildImage = "gcr.io/kaniko-project/executor:v1.9.1"
 
 // The context path within the kaniko pod
 const kanikoContextPath = "/context"
 
+// The path within the kaniko pod the build secrets are written to. This is the
+// path RUN --mount=type=secret uses by default, so Dockerfiles work unchanged
+const kanikoSecretsPath = "/run/secrets"
+
 // The file the init container will wait for
 const doneFile = "/tmp/done"
 
@@ -89,6 +97,13 @@ func (b *Builder) getBuildPod(ctx devspacecontext.Context, buildID string, optio
 		"--context=dir://" + kanikoContextPath,
 	}
 
+	// kaniko builds for the platform it runs on and can only select the base images of another platform
+	if len(b.helper.ImageConf.Platforms) > 1 {
+		return nil, errors.Errorf("kaniko can only build image %s for a single platform, please use docker or buildKit to build it for %s", b.helper.ImageName, strings.Join(b.helper.ImageConf.Platforms, ", "))
+	} else if len(b.helper.ImageConf.Platforms) == 1 {
+		kanikoArgs = append(kanikoArgs, "--custom-platform="+b.helper.ImageConf.Platforms[0])
+	}
+
 	// specify destinations
 	for _, tag := range b.helper.ImageTags {
 		kanikoArgs = append(kanikoArgs, "--destination="+b.helper.ImageName+":"+tag)
@@ -111,6 +126,10 @@ func (b *Builder) getBuildPod(ctx devspacecontext.Context, buildID string, optio
 		kanikoArgs = append(kanikoArgs, "--insecure", "--skip-tls-verify")
 	}
 
+	// pull through the cache and the configured mirrors, kaniko falls back to the original registry
+	// if the mirrors fail
+	kanikoArgs = append(kanikoArgs, registryMapArgs(registry.NewHosts(ctx.Config().Config()), b.cacheRegistry, b.cacheHost)...)
+
 	// build args
 	for key, value := range options.BuildArgs {
 		newKanikoArg := fmt.Sprintf("%v=%v", key, *value)
@@ -219,6 +238,31 @@ func (b *Builder) getBuildPod(ctx devspacecontext.Context, buildID string, optio
 			SubPath:   mount.SubPath,
 		})
 	}
+
+	// add the volume the build secrets are copied to
+	initVolumeMounts := []k8sv1.VolumeMount{
+		{
+			Name:      "context",
+			MountPath: kanikoContextPath,
+		},
+	}
+	if len(b.helper.ImageConf.Secrets) > 0 {
+		volumes = append(volumes, k8sv1.Volume{
+			Name: "secrets",
+			VolumeSource: k8sv1.VolumeSource{
+				EmptyDir: &k8sv1.EmptyDirVolumeSource{
+					Medium: k8sv1.StorageMediumMemory,
+				},
+			},
+		})
+		secretsMount := k8sv1.VolumeMount{
+			Name:      "secrets",
+			MountPath: kanikoSecretsPath,
+		}
+		initVolumeMounts = append(initVolumeMounts, secretsMount)
+		volumeMounts = append(volumeMounts, secretsMount)
+	}
+
 	// create the build pod
 	pod := &k8sv1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -238,12 +282,7 @@ func (b *Builder) getBuildPod(ctx devspacecontext.Context, buildID string, optio
 					Command:         []string{"sh"},
 					Args:            []string{"-c", "while [ ! -f " + doneFile + " ]; do sleep 2; done"},
 					ImagePullPolicy: k8sv1.PullIfNotPresent,
-					VolumeMounts: []k8sv1.VolumeMount{
-						{
-							Name:      "context",
-							MountPath: kanikoContextPath,
-						},
-					},
+					VolumeMounts:    initVolumeMounts,
 				},
 			},
 			Containers: []k8sv1.Container{
@@ -258,6 +297,7 @@ func (b *Builder) getBuildPod(ctx devspacecontext.Context, buildID string, optio
 			},
 			NodeSelector:       kanikoOptions.NodeSelector,
 			Tolerations:        kanikoOptions.Tolerations,
+			PriorityClassName:  kanikoOptions.PriorityClassName,
 			ServiceAccountName: kanikoOptions.ServiceAccount,
 			Volumes:            volumes,
 			RestartPolicy:      k8sv1.RestartPolicyNever,
@@ -347,11 +387,12 @@ func (b *Builder) getBuildPod(ctx devspacecontext.Context, buildID string, optio
 		}
 	} else {
 		// convert resources
-		limits, err := util.ConvertMap(kanikoOptions.Resources.Limits)
+		requestsMap, limitsMap := helper.PodResources(kanikoOptions.Resources)
+		limits, err := util.ConvertMap(limitsMap)
 		if err != nil {
 			return nil, errors.Wrap(err, "limits")
 		}
-		requests, err := util.ConvertMap(kanikoOptions.Resources.Requests)
+		requests, err := util.ConvertMap(requestsMap)
 		if err != nil {
 			return nil, errors.Wrap(err, "requests")
 		}
@@ -447,3 +488,45 @@ func getAvailableResourceQuantity(defaultQuantity resource.Quantity, resourceNam
 
 	return retLimit, nil
 }
+
+// registryMapArgs returns the kaniko flags that pull the images of the configured registries through their
+// mirrors. The pull-through cache of the local registry is the first mirror of the registry it caches
+func registryMapArgs(hosts registry.Hosts, cacheRegistry, cacheHost string) []string {
+	mirrors := map[string][]string{}
+	insecure := map[string]string{}
+	if cacheHost != "" {
+		mirrors[cacheRegistry] = []string{cacheHost}
+		insecure[cacheHost] = "--insecure-registry="
+	}
+	for host, hostConfig := range hosts {
+		for _, mirror := range hostConfig.Mirrors {
+			mirrorHost := mirror
+			if i := strings.Index(mirror, "://"); i != -1 {
+				mirrorHost = mirror[i+3:]
+			}
+			mirrorHost = strings.TrimSuffix(mirrorHost, "/")
+			mirrors[host] = append(mirrors[host], mirrorHost)
+
+			// plain http mirrors are insecure registries for kaniko, insecure https mirrors skip the
+			// certificate verification
+			if strings.HasPrefix(mirror, "http://") {
+				insecure[mirrorHost] = "--insecure-registry="
+			} else if hostConfig.Insecure {
+				insecure[mirrorHost] = "--skip-tls-verify-registry="
+			}
+		}
+	}
+
+	args := []string{}
+	for host, hostMirrors := range mirrors {
+		args = append(args, "--registry-map="+host+"="+strings.Join(hostMirrors, ";"))
+	}
+	sort.Strings(args)
+
+	insecureArgs := []string{}
+	for host, flag := range insecure {
+		insecureArgs = append(insecureArgs, flag+host)
+	}
+	sort.Strings(insecureArgs)
+	return append(args, insecureArgs...)
+}
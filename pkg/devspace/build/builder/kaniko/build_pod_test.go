@@ -0,0 +1,102 @@
+package kaniko
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/build/registry"
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	kubectltesting "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRegistryMapArgs(t *testing.T) {
+	hosts := registry.NewHosts(&latest.Config{
+		LocalRegistry: &latest.LocalRegistryConfig{
+			Registries: map[string]*latest.RegistryHostConfig{
+				"docker.io": {
+					Mirrors: []string{"mirror.internal:5000"},
+				},
+				"gcr.io": {
+					Mirrors:  []string{"http://gcr-mirror.internal/", "gcr-mirror.example.com"},
+					Insecure: true,
+				},
+				"registry.internal:5000": {
+					Insecure: true,
+				},
+			},
+		},
+	})
+
+	// the cache is the first mirror of the registry it caches
+	args := registryMapArgs(hosts, "index.docker.io", "registry-cache.test.svc.cluster.local:5001")
+	assert.DeepEqual(t, args, []string{
+		"--registry-map=gcr.io=gcr-mirror.internal;gcr-mirror.example.com",
+		"--registry-map=index.docker.io=registry-cache.test.svc.cluster.local:5001;mirror.internal:5000",
+		"--insecure-registry=gcr-mirror.internal",
+		"--insecure-registry=registry-cache.test.svc.cluster.local:5001",
+		"--skip-tls-verify-registry=gcr-mirror.example.com",
+	})
+
+	// a cache of another registry doesn't replace the mirrors of docker hub
+	args = registryMapArgs(nil, "mirror.gcr.io", "registry-cache.test.svc.cluster.local:5001")
+	assert.DeepEqual(t, args, []string{
+		"--registry-map=mirror.gcr.io=registry-cache.test.svc.cluster.local:5001",
+		"--insecure-registry=registry-cache.test.svc.cluster.local:5001",
+	})
+
+	assert.DeepEqual(t, registryMapArgs(nil, "", ""), []string{})
+}
+
+func TestBuildPodSecrets(t *testing.T) {
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		latest.NewRaw(),
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).
+		WithConfig(conf).
+		WithKubeClient(&kubectltesting.Client{Client: fake.NewSimpleClientset()})
+
+	imageConf := &latest.Image{
+		Name:    "api",
+		Image:   "registry.io/api",
+		Kaniko:  &latest.KanikoConfig{},
+		Secrets: []*latest.BuildSecret{{ID: "token", Env: "NPM_TOKEN"}},
+	}
+	b := &Builder{
+		FullImageName: "registry.io/api:v1",
+		helper:        &helper.BuildHelper{ImageConf: imageConf, ImageName: "registry.io/api", ImageTags: []string{"v1"}},
+	}
+
+	// the secrets are written to an in-memory volume, which the init container and kaniko mount
+	pod, err := b.getBuildPod(ctx, "id", &types.ImageBuildOptions{}, "Dockerfile")
+	assert.NilError(t, err)
+	volume := pod.Spec.Volumes[len(pod.Spec.Volumes)-1]
+	assert.Equal(t, volume.Name, "secrets")
+	assert.Equal(t, volume.EmptyDir.Medium, k8sv1.StorageMediumMemory)
+	secretsMount := k8sv1.VolumeMount{Name: "secrets", MountPath: kanikoSecretsPath}
+	assert.DeepEqual(t, pod.Spec.InitContainers[0].VolumeMounts[1], secretsMount)
+	assert.DeepEqual(t, pod.Spec.Containers[0].VolumeMounts[len(pod.Spec.Containers[0].VolumeMounts)-1], secretsMount)
+
+	// without secrets there is no secrets volume
+	imageConf.Secrets = nil
+	pod, err = b.getBuildPod(ctx, "id", &types.ImageBuildOptions{}, "Dockerfile")
+	assert.NilError(t, err)
+	for _, volume := range pod.Spec.Volumes {
+		assert.Assert(t, volume.Name != "secrets")
+	}
+	assert.Equal(t, len(pod.Spec.InitContainers[0].VolumeMounts), 1)
+}
@@ -1,6 +1,7 @@
 package kaniko
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/services/logs"
 	"github.com/sirupsen/logrus"
 
+	"github.com/loft-sh/devspace/pkg/util/dockerfile"
 	"github.com/loft-sh/devspace/pkg/util/interrupt"
 	"github.com/loft-sh/devspace/pkg/util/progressreader"
 
@@ -22,6 +24,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/restart"
+	"github.com/loft-sh/devspace/pkg/devspace/build/localregistry"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
@@ -36,6 +39,7 @@ import (
 	"github.com/docker/docker/api/types"
 	dockerterm "github.com/moby/term"
 	"github.com/pkg/errors"
+	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -55,6 +59,11 @@ type Builder struct {
 	BuildNamespace string
 
 	allowInsecureRegistry bool
+
+	// cacheRegistry is the registry that the pull-through cache of the local registry mirrors
+	// and cacheHost the host of the cache, if it is enabled
+	cacheRegistry string
+	cacheHost     string
 }
 
 // Wait timeout is the maximum time to wait for the kaniko init and build container to get ready
@@ -110,6 +119,10 @@ func (b *Builder) ShouldRebuild(ctx devspacecontext.Context, forceRebuild bool)
 func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfilePath string, entrypoint []string, cmd []string) error {
 	var err error
 
+	if b.helper.ImageConf.Attestations != nil {
+		ctx.Log().Warnf("Skip attestations of image %s, because they are only generated by BuildKit builds", b.helper.ImageName)
+	}
+
 	contextPath, err = build.ResolveAndValidateContextPath(contextPath)
 	if err != nil {
 		return errors.Wrap(err, "resolve context path")
@@ -138,6 +151,21 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 		defer os.RemoveAll(filepath.Dir(dockerfilePath))
 	}
 
+	// Pull base images through the cache of the local registry
+	if localregistry.IsCacheEnabled(ctx.Config().Config()) {
+		registryOptions := localregistry.NewDefaultOptions().
+			WithNamespace(ctx.KubeClient().Namespace()).
+			WithLocalRegistryConfig(ctx.Config().Config().LocalRegistry)
+
+		_, err = localregistry.GetOrCreateLocalRegistry(ctx, registryOptions)
+		if err != nil {
+			return errors.Wrap(err, "get or create local registry")
+		}
+
+		b.cacheRegistry = registryOptions.CacheRegistry()
+		b.cacheHost = registryOptions.CacheHost()
+	}
+
 	// Generate the build pod spec
 	randString := randutil.GenerateRandomString(12)
 	buildID := strings.ToLower(randString)
@@ -176,6 +204,8 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 					return false, nil
 				}
 
+				return false, err
+			} else if err := podFailure(buildPod, b.helper.ImageConf.Name); err != nil {
 				return false, err
 			} else if len(buildPod.Status.InitContainerStatuses) > 0 {
 				status := buildPod.Status.InitContainerStatuses[0]
@@ -244,6 +274,24 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 			return errors.Errorf("error uploading dockerfile to container: %v", err)
 		}
 
+		// Copy build secrets
+		for _, secret := range b.helper.ImageConf.Secrets {
+			value, err := helper.ReadSecret(ctx, secret)
+			if err != nil {
+				return err
+			}
+
+			secretPath := kanikoSecretsPath + "/" + secret.ID
+			_, stderr, err := ctx.KubeClient().ExecBuffered(ctx.Context(), buildPod, buildPod.Spec.InitContainers[0].Name, []string{"sh", "-c", "cat > \"$0\"", secretPath}, bytes.NewReader(value))
+			if err != nil {
+				if stderr != nil {
+					return errors.Errorf("copy build secret %s: %s: %v", secret.ID, string(stderr), err)
+				}
+
+				return errors.Wrapf(err, "copy build secret %s", secret.ID)
+			}
+		}
+
 		// Copy restart helper script
 		if injectRestartHelper {
 			tempDir, err := os.MkdirTemp("", "")
@@ -317,6 +365,8 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 					return false, nil
 				}
 
+				return false, err
+			} else if err := podFailure(buildPod, b.helper.ImageConf.Name); err != nil {
 				return false, err
 			} else if len(buildPod.Status.ContainerStatuses) > 0 {
 				status := buildPod.Status.ContainerStatuses[0]
@@ -362,7 +412,19 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 		}
 		defer writer.Close()
 
-		stdoutLogger := kanikoLogger{out: writer}
+		// count the instructions to show the build progress
+		steps := 0
+		dockerfileInstructions, err := dockerfile.GetInstructions(b.helper.DockerfilePath)
+		if err != nil {
+			ctx.Log().Debugf("Error reading instructions of %s: %v", b.helper.DockerfilePath, err)
+		}
+		for _, instruction := range dockerfileInstructions {
+			if instruction != "FROM" {
+				steps++
+			}
+		}
+
+		stdoutLogger := &kanikoLogger{log: ctx.Log(), out: writer, steps: steps}
 
 		// Stream the logs
 		options := targetselector.NewOptionsFromFlags(buildPod.Spec.Containers[0].Name, "", nil, buildPod.Namespace, buildPod.Name).
@@ -372,6 +434,10 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 		if err != nil {
 			return errors.Errorf("error printing build logs: %v", err)
 		}
+		err = stdoutLogger.Flush()
+		if err != nil {
+			return errors.Errorf("error printing build logs: %v", err)
+		}
 
 		ctx.Log().Info("Checking build status...")
 		for {
@@ -384,6 +450,12 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 			}
 
 			// Check if terminated
+			err = podFailure(pod, b.helper.ImageConf.Name)
+			if err != nil {
+				return err
+			} else if pod.Status.Phase == k8sv1.PodFailed {
+				return errors.Errorf("kaniko pod %s/%s has failed: %s", pod.Namespace, pod.Name, pod.Status.Message)
+			}
 			if len(pod.Status.ContainerStatuses) > 0 && pod.Status.ContainerStatuses[0].State.Terminated != nil {
 				if pod.Status.ContainerStatuses[0].State.Terminated.ExitCode != 0 {
 					return errors.Errorf("error building image (Exit Code %d)", pod.Status.ContainerStatuses[0].State.Terminated.ExitCode)
@@ -393,6 +465,7 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath, dockerfil
 			}
 		}
 		ctx.Log().Done("Done building image")
+		b.helper.RecordDigest(ctx, b.FullImageName)
 		return nil
 	}, deleteBuildPod)
 	if err != nil {
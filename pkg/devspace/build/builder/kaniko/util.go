@@ -1,52 +1,182 @@
 package kaniko
 
 import (
+	"bytes"
 	"io"
+	"regexp"
 	"strings"
+
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	k8sv1 "k8s.io/api/core/v1"
+)
+
+// kanikoLogRegEx matches log lines of kaniko, e.g. INFO[0005] RUN apk add git
+var kanikoLogRegEx = regexp.MustCompile(`^(INFO|WARN|ERRO|FATA|DEBU|TRAC)\[\d+\]\s*(.*)$`)
+
+// build phases of kaniko
+const (
+	phaseResolving = "Resolving base image"
+	phaseExecuting = "Executing"
+	phasePushing   = "Pushing"
 )
 
+// instructions are the dockerfile instructions kaniko logs when it executes them
+var instructions = map[string]bool{
+	"ADD":         true,
+	"ARG":         true,
+	"CMD":         true,
+	"COPY":        true,
+	"ENTRYPOINT":  true,
+	"ENV":         true,
+	"EXPOSE":      true,
+	"HEALTHCHECK": true,
+	"LABEL":       true,
+	"ONBUILD":     true,
+	"RUN":         true,
+	"SHELL":       true,
+	"STOPSIGNAL":  true,
+	"USER":        true,
+	"VOLUME":      true,
+	"WORKDIR":     true,
+}
+
+// kanikoLogger parses the kaniko logs, prints the build phases with the progress of the executed instructions
+// and drops noisy lines
 type kanikoLogger struct {
+	log logpkg.Logger
 	out io.Writer
-}
 
-// Implement the io.Writer interface
-func (k kanikoLogger) Write(p []byte) (n int, err error) {
-	str := string(p)
+	// steps is the number of instructions of the dockerfile, it's 0 if it's unknown
+	steps int
+	step  int
+	phase string
 
-	lines := strings.Split(str, "\n")
-	newLines := make([]string, 0, len(lines))
-	for _, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
+	buffer []byte
+}
 
-		if strings.HasSuffix(trimmedLine, ", because it was changed.") {
-			continue
-		}
-		if strings.HasSuffix(trimmedLine, "No matching credentials were found, falling back on anonymous") {
-			continue
-		}
-		if strings.HasPrefix(trimmedLine, "ERROR: logging before flag.Parse:") {
-			continue
-		}
-		if strings.HasSuffix(trimmedLine, "Taking snapshot of full filesystem...") {
-			continue
-		}
-		if strings.HasSuffix(trimmedLine, "Taking snapshot of files...") {
-			continue
+// Implement the io.Writer interface
+func (k *kanikoLogger) Write(p []byte) (n int, err error) {
+	k.buffer = append(k.buffer, p...)
+	for {
+		i := bytes.IndexByte(k.buffer, '\n')
+		if i < 0 {
+			break
 		}
-		if strings.HasSuffix(trimmedLine, "No files changed in this command, skipping snapshotting.") {
-			continue
+
+		err = k.handle(string(k.buffer[:i]))
+		k.buffer = k.buffer[i+1:]
+		if err != nil {
+			return len(p), err
 		}
-		if strings.Contains(trimmedLine, "Error while retrieving image from cache: getting file info") {
-			continue
+	}
+
+	return len(p), nil
+}
+
+// Flush prints the last line, if it didn't end with a new line
+func (k *kanikoLogger) Flush() error {
+	if len(k.buffer) == 0 {
+		return nil
+	}
+
+	line := string(k.buffer)
+	k.buffer = nil
+	return k.handle(line)
+}
+
+func (k *kanikoLogger) handle(line string) error {
+	trimmedLine := strings.TrimSpace(line)
+	if trimmedLine == "" || isNoise(trimmedLine) {
+		return nil
+	}
+
+	match := kanikoLogRegEx.FindStringSubmatch(trimmedLine)
+	if match == nil {
+		_, err := k.out.Write([]byte(line + "\n"))
+		return err
+	}
+
+	level, message := match[1], match[2]
+	if message == "" {
+		return nil
+	}
+
+	switch level {
+	case "WARN":
+		k.log.Warn(message)
+		return nil
+	case "ERRO", "FATA":
+		k.log.Error(message)
+		return nil
+	case "DEBU", "TRAC":
+		k.log.Debug(message)
+		return nil
+	}
+
+	switch {
+	case strings.HasPrefix(message, "Retrieving image"):
+		k.setPhase(phaseResolving)
+	case strings.HasPrefix(message, "Pushing image to"), strings.HasPrefix(message, "Pushing layer"):
+		k.setPhase(phasePushing)
+	case instructions[strings.Fields(message)[0]]:
+		k.setPhase(phaseExecuting)
+		k.step++
+		if k.steps > 0 {
+			k.log.Infof("[%d/%d %d%%] %s", k.step, k.steps, k.progress(), message)
+		} else {
+			k.log.Infof("[%d] %s", k.step, message)
 		}
+		return nil
+	}
 
-		newLines = append(newLines, line)
+	_, err := k.out.Write([]byte(message + "\n"))
+	return err
+}
+
+func (k *kanikoLogger) setPhase(phase string) {
+	if k.phase == phase {
+		return
 	}
 
-	i, err := k.out.Write([]byte(strings.Join(newLines, "\n")))
-	if err != nil {
-		return i, err
+	k.phase = phase
+	k.log.Info(phase + "...")
+}
+
+// progress returns the percentage of the executed instructions, multi stage builds can execute more
+// instructions than the final dockerfile has, so the progress is capped
+func (k *kanikoLogger) progress() int {
+	if k.step >= k.steps {
+		return 100
 	}
 
-	return len(p), nil
+	return k.step * 100 / k.steps
+}
+
+// isNoise returns true for kaniko log lines that aren't interesting for the user
+func isNoise(line string) bool {
+	return strings.HasSuffix(line, ", because it was changed.") ||
+		strings.HasSuffix(line, "No matching credentials were found, falling back on anonymous") ||
+		strings.HasPrefix(line, "ERROR: logging before flag.Parse:") ||
+		strings.HasSuffix(line, "Taking snapshot of full filesystem...") ||
+		strings.HasSuffix(line, "Taking snapshot of files...") ||
+		strings.HasSuffix(line, "No files changed in this command, skipping snapshotting.") ||
+		strings.Contains(line, "Error while retrieving image from cache: getting file info")
+}
+
+// podFailure returns an error that explains how to fix the build, if the kaniko pod was evicted or one of its
+// containers ran out of memory
+func podFailure(pod *k8sv1.Pod, imageConfigName string) error {
+	if pod.Status.Reason == "Evicted" {
+		return errors.Errorf("kaniko pod %s/%s was evicted: %s. Please free up resources on the node or set images.%s.kaniko.nodeSelector to build on another node", pod.Namespace, pod.Name, pod.Status.Message, imageConfigName)
+	}
+
+	statuses := append(append([]k8sv1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Terminated != nil && status.State.Terminated.Reason == "OOMKilled" {
+			return errors.Errorf("kaniko container %s in pod %s/%s ran out of memory (OOMKilled). Please increase images.%s.kaniko.resources.limits.memory", status.Name, pod.Namespace, pod.Name, imageConfigName)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,44 @@
+package kaniko
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestKanikoLogger(t *testing.T) {
+	logs := &bytes.Buffer{}
+	out := &bytes.Buffer{}
+	logger := &kanikoLogger{
+		log:   log.NewStreamLoggerWithFormat(logs, logs, logrus.InfoLevel, log.RawFormat),
+		out:   out,
+		steps: 2,
+	}
+
+	_, err := logger.Write([]byte("INFO[0000] Retrieving image manifest alpine\nINFO[0001] Retrieving image alpine from registry index.docker.io\nINFO[0002] RUN apk add git\n(1/2) Installing"))
+	assert.NilError(t, err)
+	_, err = logger.Write([]byte(" git\nINFO[0005] Taking snapshot of full filesystem...\nWARN[0006] no cache found\nINFO[0007] COPY . .\nINFO[0008] Pushing image to registry.io/app:tag\nPushed"))
+	assert.NilError(t, err)
+	err = logger.Flush()
+	assert.NilError(t, err)
+
+	assert.Equal(t, out.String(), "Retrieving image manifest alpine\nRetrieving image alpine from registry index.docker.io\n(1/2) Installing git\nPushing image to registry.io/app:tag\nPushed\n")
+	assert.Equal(t, logs.String(), "Resolving base image...\nExecuting...\n[1/2 50%] RUN apk add git\nno cache found\n[2/2 100%] COPY . .\nPushing...\n")
+}
+
+func TestPodFailure(t *testing.T) {
+	pod := &k8sv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "default"}}
+	assert.NilError(t, podFailure(pod, "app"))
+
+	pod.Status.ContainerStatuses = []k8sv1.ContainerStatus{{Name: "kaniko", State: k8sv1.ContainerState{Terminated: &k8sv1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}}}}
+	assert.Error(t, podFailure(pod, "app"), "kaniko container kaniko in pod default/build ran out of memory (OOMKilled). Please increase images.app.kaniko.resources.limits.memory")
+
+	pod.Status.Reason = "Evicted"
+	pod.Status.Message = "The node was low on resource: memory."
+	assert.Error(t, podFailure(pod, "app"), "kaniko pod default/build was evicted: The node was low on resource: memory.. Please free up resources on the node or set images.app.kaniko.nodeSelector to build on another node")
+}
@@ -5,27 +5,40 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/docker/cli/cli/config/configfile"
+	configtypes "github.com/docker/cli/cli/config/types"
 	"github.com/docker/docker/api/types"
 	"github.com/loft-sh/devspace/pkg/devspace/build/localregistry"
+	"github.com/loft-sh/devspace/pkg/devspace/build/registry"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	dockerclient "github.com/loft-sh/devspace/pkg/devspace/docker"
 
 	buildkit "github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
 	"github.com/moby/buildkit/session/upload/uploadprovider"
 )
 
-func RemoteBuild(ctx devspacecontext.Context, podName, namespace string, buildContext io.Reader, writer io.Writer, buildOptions *types.ImageBuildOptions) error {
+func RemoteBuild(ctx devspacecontext.Context, localRegistry *localregistry.LocalRegistry, podName, namespace string, buildContext io.Reader, writer io.Writer, buildOptions *types.ImageBuildOptions, platforms, cacheFrom, cacheTo []string, attestations *latest.BuildAttestations, secrets map[string][]byte) error {
 	conn, err := ExecConn(ctx, namespace, podName, localregistry.BuildKitContainer, []string{"buildctl", "dial-stdio"})
 	if err != nil {
 		return errors.Wrap(err, "connect to buildkit pod")
@@ -45,6 +58,26 @@ func RemoteBuild(ctx devspacecontext.Context, podName, namespace string, buildCo
 		return err
 	}
 
+	// authenticate the builder at the local registry, an empty credential helper makes sure
+	// the credentials are read from the config instead of the credential store
+	if username, password := localRegistry.Credentials(); username != "" {
+		if dockerConfig.CredentialHelpers == nil {
+			dockerConfig.CredentialHelpers = map[string]string{}
+		}
+		dockerConfig.CredentialHelpers[localregistry.BuilderRegistryHost] = ""
+		dockerConfig.AuthConfigs[localregistry.BuilderRegistryHost] = configtypes.AuthConfig{
+			Username:      username,
+			Password:      password,
+			ServerAddress: localregistry.BuilderRegistryHost,
+		}
+	}
+
+	return Solve(ctx, client, dockerConfig, buildContext, writer, buildOptions, platforms, cacheFrom, cacheTo, attestations, secrets, true)
+}
+
+// Solve builds the image from the build context with the BuildKit client and pushes it to its tags if push is true.
+// The registries are authenticated with the given docker config and the secrets are served to the build by their id
+func Solve(ctx devspacecontext.Context, client *buildkit.Client, dockerConfig *configfile.ConfigFile, buildContext io.Reader, writer io.Writer, buildOptions *types.ImageBuildOptions, platforms, cacheFrom, cacheTo []string, attestations *latest.BuildAttestations, secrets map[string][]byte, push bool) error {
 	// stdin is context
 	up := uploadprovider.New()
 	options := buildkit.SolveOpt{
@@ -61,12 +94,16 @@ func RemoteBuild(ctx devspacecontext.Context, podName, namespace string, buildCo
 				Attrs: map[string]string{
 					"name":           strings.Join(buildOptions.Tags, ","),
 					"name-canonical": "",
-					"push":           "true",
+					"push":           strconv.FormatBool(push),
 				},
 			},
 		},
 	}
 
+	if len(secrets) > 0 {
+		options.Session = append(options.Session, secretsprovider.FromMap(secrets))
+	}
+
 	for key, value := range buildOptions.BuildArgs {
 		if value == nil {
 			continue
@@ -74,6 +111,29 @@ func RemoteBuild(ctx devspacecontext.Context, podName, namespace string, buildCo
 		options.FrontendAttrs["build-arg:"+key] = *value
 	}
 
+	var err error
+	options.CacheImports, err = parseCacheEntries(cacheFrom)
+	if err != nil {
+		return err
+	}
+	options.CacheExports, err = parseCacheEntries(cacheTo)
+	if err != nil {
+		return err
+	}
+
+	// buildkit builds all platforms at once and pushes them as multi-arch index
+	if len(platforms) > 0 {
+		options.FrontendAttrs["platform"] = strings.Join(platforms, ",")
+	}
+
+	// attestations are attached to the image index, so they can only be generated for pushed images
+	if push && attestations != nil && attestations.SBOM {
+		options.FrontendAttrs["attest:sbom"] = ""
+	}
+	if push && attestations != nil && attestations.Provenance {
+		options.FrontendAttrs["attest:provenance"] = "mode=max"
+	}
+
 	pw, err := NewPrinter(context.TODO(), writer)
 	if err != nil {
 		return err
@@ -91,99 +151,275 @@ func RemoteBuild(ctx devspacecontext.Context, podName, namespace string, buildCo
 // contextPath is the absolute path to the context path
 // dockerfilePath is the absolute path to the dockerfile WITHIN the contextPath
 func LocalBuild(ctx devspacecontext.Context, contextPath, dockerfilePath string, entrypoint []string, cmd []string, b *Builder) error {
+	if b.helper.ImageConf.Attestations != nil {
+		ctx.Log().Warnf("Skip attestations of image %s, because they are only generated by BuildKit builds", b.helper.ImageName)
+	}
+
+	dockerContext := ""
+	if b.helper.ImageConf.Docker != nil {
+		dockerContext = b.helper.ImageConf.Docker.DockerContext
+	}
+
+	dockerClient, err := dockerclient.NewClientWithContext(ctx.Context(), nil, false, dockerContext, ctx.Log())
+	if err != nil {
+		return errors.Wrap(err, "create docker client")
+	}
+
+	// a single platform is passed to the docker daemon, multiple platforms are built
+	// one after another and pushed as multi-arch index
+	platforms := b.platforms()
+	if len(platforms) <= 1 {
+		platform := ""
+		if len(platforms) == 1 {
+			platform = platforms[0]
+		}
+
+		tags, err := buildLocalImage(ctx, dockerClient, contextPath, dockerfilePath, entrypoint, cmd, b, platform, "")
+		if err != nil {
+			return err
+		}
+
+		images := map[string]remote.Taggable{}
+		for _, tag := range tags {
+			images[tag], err = daemonImage(ctx.Context(), dockerClient, tag)
+			if err != nil {
+				return err
+			}
+		}
+
+		return CopyImagesToRemote(ctx, images, b)
+	}
+
+	var tags []string
+	platformImages := map[string]v1.Image{}
+	for _, platform := range platforms {
+		suffix := "-" + strings.ReplaceAll(platform, "/", "-")
+		tags, err = buildLocalImage(ctx, dockerClient, contextPath, dockerfilePath, entrypoint, cmd, b, platform, suffix)
+		if err != nil {
+			return err
+		}
+
+		platformImages[platform], err = daemonImage(ctx.Context(), dockerClient, tags[0]+suffix)
+		if err != nil {
+			return err
+		}
+	}
+
+	index, err := registry.NewPlatformIndex(platformImages)
+	if err != nil {
+		return errors.Wrap(err, "create image index")
+	}
+
+	images := map[string]remote.Taggable{}
+	for _, tag := range tags {
+		images[tag] = index
+	}
+
+	return CopyImagesToRemote(ctx, images, b)
+}
+
+// buildLocalImage builds the image for the given platform with the docker daemon and returns its tags. If a
+// suffix is given, only the first tag with the suffix appended is built, so that builds for different
+// platforms don't overwrite each other
+func buildLocalImage(ctx devspacecontext.Context, dockerClient dockerclient.Client, contextPath, dockerfilePath string, entrypoint []string, cmd []string, b *Builder, platform, suffix string) ([]string, error) {
 	// create context stream
 	body, writer, outStream, buildOptions, err := b.helper.CreateContextStream(contextPath, dockerfilePath, entrypoint, cmd, ctx.Log())
 	defer writer.Close()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dockerClient, err := dockerclient.NewClient(ctx.Context(), ctx.Log())
-	if err != nil {
-		return nil
+	tags := buildOptions.Tags
+	if suffix != "" {
+		buildOptions.Tags = []string{tags[0] + suffix}
 	}
+	buildOptions.Platform = platform
 
 	// make sure to use the correct proxy configuration
 	buildOptions.BuildArgs = dockerClient.ParseProxyConfig(buildOptions.BuildArgs)
 
 	response, err := dockerClient.ImageBuild(ctx.Context(), body, *buildOptions)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer response.Body.Close()
 
 	err = jsonmessage.DisplayJSONMessagesStream(response.Body, outStream, outStream.FD(), outStream.IsTerminal(), nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, tag := range buildOptions.Tags {
-		ctx.Log().Info("The push refers to repository [" + tag + "]")
-		err := CopyImageToRemote(ctx.Context(), dockerClient, tag, writer, b)
+	return tags, nil
+}
+
+func daemonImage(ctx context.Context, client dockerclient.Client, imageName string) (v1.Image, error) {
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		return nil, err
+	}
+
+	return daemon.Image(ref, daemon.WithContext(ctx), daemon.WithClient(client.DockerAPIClient()))
+}
+
+// CopyImagesToRemote pushes the images and indexes by their local names to the local registry. Tags of the
+// same image are pushed at once, so that their layers are only uploaded once, different images are pushed
+// concurrently
+func CopyImagesToRemote(ctx devspacecontext.Context, images map[string]remote.Taggable, b *Builder) error {
+	options := append(b.localRegistry.RemoteOptions(), b.registryOptions.remoteOptions()...)
+	scheduler := registry.PushSchedulerFrom(ctx.Context())
+	pushes := map[string]map[name.Reference]remote.Taggable{}
+	names := []string{}
+	for imageName, image := range images {
+		remoteRef, err := name.ParseReference(imageName, name.WithDefaultRegistry(b.localRegistry.GetRegistryURL()))
 		if err != nil {
-			return errors.Errorf("error during local registry image push: %v", err)
+			return err
 		}
 
-		ctx.Log().Info("Image pushed to local registry")
+		// skip images that are already in the registry, e.g. if the same image is deployed again
+		unchanged, digest, err := isImageUnchanged(ctx.Context(), remoteRef, image, options)
+		if err != nil {
+			ctx.Log().Debugf("Error comparing %s with the local registry: %v", remoteRef.String(), err)
+		} else if unchanged {
+			ctx.Log().Infof("Skip push of %s, because the local registry has the same digest %s", remoteRef.String(), digest.String())
+			continue
+		}
+
+		key := digest.String()
+		if digest.Hex == "" {
+			key = remoteRef.String()
+		}
+		if pushes[key] == nil {
+			pushes[key] = map[name.Reference]remote.Taggable{}
+		}
+		pushes[key][remoteRef] = scheduler.Limit(ctx.Context(), image)
+		names = append(names, remoteRef.String())
+	}
+	if len(pushes) == 0 {
+		ctx.Log().Info("Images are up to date in the local registry")
+		return nil
+	}
+	sort.Strings(names)
+	ctx.Log().Info("The push refers to [" + strings.Join(names, ", ") + "]")
+
+	err := pushConcurrently(ctx, pushes, options, b.registryOptions)
+	if err != nil {
+		return errors.Errorf("error during local registry image push: %v", err)
 	}
 
+	ctx.Log().Info("Image pushed to local registry")
 	return nil
 }
 
-// CopyImageToRemote will extract an image from a local registry and stream it to a remote registry
-func CopyImageToRemote(ctx context.Context, client dockerclient.Client, imageName string, writer io.Writer, b *Builder) error {
-	// get local registry data
-	localRef, err := name.ParseReference(imageName)
-	if err != nil {
-		return err
+// pushConcurrently pushes each group of references in its own push slot of the scheduler and retries
+// it on its own, so that a failed image doesn't push the other images again
+func pushConcurrently(ctx devspacecontext.Context, pushes map[string]map[name.Reference]remote.Taggable, options []remote.Option, registryOptions registryOptions) error {
+	scheduler := registry.PushSchedulerFrom(ctx.Context())
+	writer := ctx.Log().Writer(logrus.InfoLevel, false)
+	defer writer.Close()
+
+	var (
+		waitGroup sync.WaitGroup
+		errsMutex sync.Mutex
+		errs      []error
+	)
+	for _, refs := range pushes {
+		waitGroup.Add(1)
+		go func(refs map[name.Reference]remote.Taggable) {
+			defer waitGroup.Done()
+
+			err := func() error {
+				release, err := scheduler.Acquire(ctx.Context())
+				if err != nil {
+					return err
+				}
+				defer release()
+
+				return registryOptions.retry(ctx.Context(), ctx.Log(), "Push to local registry", func() error {
+					return pushImages(ctx.Context(), refs, writer, options)
+				})
+			}()
+			if err != nil {
+				errsMutex.Lock()
+				errs = append(errs, err)
+				errsMutex.Unlock()
+			}
+		}(refs)
 	}
-	// get remote registry data
-	remoteRef, err := name.ParseReference(imageName, name.WithDefaultRegistry(b.localRegistry.GetRegistryURL()))
+
+	waitGroup.Wait()
+	return utilerrors.NewAggregate(errs)
+}
+
+// isImageUnchanged returns true if the tag in the registry already points to the manifest of the image
+func isImageUnchanged(ctx context.Context, ref name.Reference, image remote.Taggable, options []remote.Option) (bool, v1.Hash, error) {
+	digest, err := partial.Digest(image)
 	if err != nil {
-		return err
+		return false, v1.Hash{}, err
 	}
-	// get image data from local registry
-	image, err := daemon.Image(localRef, daemon.WithContext(ctx), daemon.WithClient(client.DockerAPIClient()))
+
+	descriptor, err := remote.Head(ref, append([]remote.Option{remote.WithContext(ctx)}, options...)...)
 	if err != nil {
-		return err
+		transportError, ok := err.(*transport.Error)
+		if ok && transportError.StatusCode == http.StatusNotFound {
+			return false, digest, nil
+		}
+		return false, digest, err
 	}
 
+	return descriptor.Digest == digest, digest, nil
+}
+
+// pushImages writes the images to the registry and prints the combined progress, prefixed with the
+// first of their names
+func pushImages(ctx context.Context, refs map[name.Reference]remote.Taggable, writer io.Writer, options []remote.Option) error {
+	names := []string{}
+	for ref := range refs {
+		names = append(names, ref.String())
+	}
+	sort.Strings(names)
+
 	progressChan := make(chan v1.Update, 200)
 	errChan := make(chan error, 1)
-	// push image to remote registry
 	go func() {
-		errChan <- remote.Write(
-			remoteRef,
-			image,
-			remote.WithContext(ctx),
-			remote.WithProgress(progressChan),
+		errChan <- remote.MultiWrite(
+			refs,
+			append([]remote.Option{
+				remote.WithContext(ctx),
+				remote.WithProgress(progressChan),
+			}, options...)...,
 		)
 	}()
 
-	for update := range progressChan {
-		if update.Error != nil {
-			return err
-		}
+	for {
+		select {
+		case update, ok := <-progressChan:
+			if !ok {
+				// wait for the result
+				progressChan = nil
+				continue
+			} else if update.Error != nil {
+				continue
+			}
 
-		status := "Pushing"
-		if update.Complete == update.Total {
-			status = "Pushed"
-		}
+			status := "Pushing"
+			if update.Complete == update.Total {
+				status = "Pushed"
+			}
 
-		jm := &jsonmessage.JSONMessage{
-			ID:     localRef.Identifier(),
-			Status: status,
-			Progress: &jsonmessage.JSONProgress{
-				Current: update.Complete,
-				Total:   update.Total,
-			},
-		}
+			jm := &jsonmessage.JSONMessage{
+				Status: status,
+				Progress: &jsonmessage.JSONProgress{
+					Current: update.Complete,
+					Total:   update.Total,
+				},
+			}
 
-		_, err := fmt.Fprintf(writer, "%s %s\n", jm.Status, jm.Progress.String())
-		if err != nil {
+			_, err := fmt.Fprintf(writer, "%s %s %s\n", jm.Status, names[0], jm.Progress.String())
+			if err != nil {
+				return err
+			}
+		case err := <-errChan:
 			return err
 		}
 	}
-
-	return <-errChan
 }
@@ -0,0 +1,160 @@
+package localregistry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+type rawManifest []byte
+
+func (r rawManifest) RawManifest() ([]byte, error) {
+	return r, nil
+}
+
+func TestIsImageUnchanged(t *testing.T) {
+	image := rawManifest(`{"schemaVersion":2}`)
+	digest, err := partial.Digest(image)
+	assert.NilError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/app/manifests/same":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Header().Set("Content-Length", "19")
+			w.Header().Set("Docker-Content-Digest", digest.String())
+			w.WriteHeader(http.StatusOK)
+		case "/v2/app/manifests/other":
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Header().Set("Content-Length", "19")
+			w.Header().Set("Docker-Content-Digest", "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registry := strings.TrimPrefix(server.URL, "http://")
+	for tag, expected := range map[string]bool{
+		"same":    true,
+		"other":   false,
+		"missing": false,
+	} {
+		ref, err := name.ParseReference(registry+"/app:"+tag, name.Insecure)
+		assert.NilError(t, err)
+
+		unchanged, _, err := isImageUnchanged(context.Background(), ref, image, nil)
+		assert.NilError(t, err, tag)
+		assert.Equal(t, unchanged, expected, tag)
+	}
+}
+
+// configImage is an image without layers that only differs by its config
+type configImage string
+
+func (c configImage) RawConfigFile() ([]byte, error) {
+	return []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]},"config":{"Env":["IMAGE=` + string(c) + `"]}}`), nil
+}
+
+func (c configImage) MediaType() (types.MediaType, error) {
+	return types.DockerManifestSchema2, nil
+}
+
+func (c configImage) LayerByDiffID(v1.Hash) (partial.UncompressedLayer, error) {
+	return nil, fmt.Errorf("image has no layers")
+}
+
+func TestPushConcurrently(t *testing.T) {
+	apiImage, err := partial.UncompressedToImage(configImage("api"))
+	assert.NilError(t, err)
+	workerImage, err := partial.UncompressedToImage(configImage("worker"))
+	assert.NilError(t, err)
+
+	// the manifests are only accepted once the manifests of both images are pushed at the same time
+	var (
+		m            sync.Mutex
+		manifests    []string
+		repositories = map[string]bool{}
+		uploads      int
+	)
+	bothPushed := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/blobs/uploads/"):
+			m.Lock()
+			uploads++
+			w.Header().Set("Location", fmt.Sprintf("/upload/%d", uploads))
+			m.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/upload/"):
+			w.Header().Set("Location", r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/upload/"):
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/manifests/"):
+			m.Lock()
+			manifests = append(manifests, r.URL.Path)
+			repository := strings.Split(r.URL.Path, "/")[2]
+			if !repositories[repository] {
+				repositories[repository] = true
+				if len(repositories) == 2 {
+					close(bothPushed)
+				}
+			}
+			m.Unlock()
+
+			select {
+			case <-bothPushed:
+				w.WriteHeader(http.StatusCreated)
+			case <-time.After(5 * time.Second):
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reference := func(image string) name.Reference {
+		ref, err := name.ParseReference(strings.TrimPrefix(server.URL, "http://")+"/"+image, name.Insecure)
+		assert.NilError(t, err)
+		return ref
+	}
+
+	// the tags of the same image are pushed together, the images are pushed concurrently
+	options := newRegistryOptions(0)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard)
+	err = pushConcurrently(ctx, map[string]map[name.Reference]remote.Taggable{
+		"api": {
+			reference("api:v1"):     apiImage,
+			reference("api:latest"): apiImage,
+		},
+		"worker": {
+			reference("worker:v1"): workerImage,
+		},
+	}, options.remoteOptions(), options)
+	assert.NilError(t, err)
+
+	sort.Strings(manifests)
+	assert.DeepEqual(t, manifests, []string{"/v2/api/manifests/latest", "/v2/api/manifests/v1", "/v2/worker/manifests/v1"})
+}
@@ -1,7 +1,6 @@
 package localregistry
 
 import (
-	"context"
 	"net/http"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -12,6 +11,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // EngineName is the name of the building engine
@@ -22,6 +22,7 @@ type Builder struct {
 	helper *helper.BuildHelper
 
 	localRegistry             *localregistry.LocalRegistry
+	registryOptions           registryOptions
 	skipPush                  bool
 	skipPushOnLocalKubernetes bool
 }
@@ -31,6 +32,7 @@ func NewBuilder(ctx devspacecontext.Context, localRegistry *localregistry.LocalR
 	return &Builder{
 		helper:                    helper.NewBuildHelper(ctx, EngineName, imageConf, imageTags),
 		localRegistry:             localRegistry,
+		registryOptions:           newRegistryOptions(localRegistry.Retries),
 		skipPush:                  skipPush,
 		skipPushOnLocalKubernetes: skipPushOnLocalKubernetes,
 	}, nil
@@ -58,19 +60,49 @@ func (b *Builder) BuildImage(ctx devspacecontext.Context, contextPath string, do
 		}
 	}
 
-	// in case of localbuilds, start the local build
+	// in case of localbuilds, start the local build. The docker daemon api has no way to pass
+	// secrets, only the BuildKit session of the remote build has
 	if b.localRegistry.LocalBuild {
-		return LocalBuild(ctx, contextPath, dockerfilePath, entrypoint, cmd, b)
-	}
+		if len(b.helper.ImageConf.Secrets) > 0 {
+			return errors.Errorf("images.%s.secrets are not supported when the local registry builds with the local docker daemon (localRegistry.localbuild), please build in the cluster or use docker, buildKit or kaniko to build this image", b.helper.ImageConf.Name)
+		}
+
+		err = LocalBuild(ctx, contextPath, dockerfilePath, entrypoint, cmd, b)
+	} else {
+		var secrets map[string][]byte
+		secrets, err = helper.ReadSecrets(ctx, b.helper.ImageConf.Secrets)
+		if err != nil {
+			return err
+		}
 
-	builderPod, err := b.localRegistry.SelectRegistryPod(ctx)
+		var builderPod *corev1.Pod
+		builderPod, err = b.localRegistry.SelectRegistryPod(ctx)
+		if err != nil {
+			return errors.Wrap(err, "select builder pod")
+		}
+
+		// start the remote build
+		err = RemoteBuild(ctx, b.localRegistry, builderPod.Name, builderPod.Namespace, body, writer, buildOptions, b.platforms(), b.helper.ImageConf.CacheFrom, b.helper.ImageConf.CacheTo, b.helper.ImageConf.Attestations, secrets)
+	}
 	if err != nil {
-		return errors.Wrap(err, "select builder pod")
+		return err
 	}
 
-	// start the remote build
-	return RemoteBuild(ctx, builderPod.Name, builderPod.Namespace, body, writer, buildOptions)
+	imageCache, _ := ctx.Config().LocalCache().GetImageCache(b.helper.ImageConf.Name)
+	options := []remote.Option{remote.WithTransport(remote.DefaultTransport)}
+	options = append(options, b.localRegistry.RemoteOptions()...)
+	b.helper.RecordDigest(ctx, imageCache.ResolveImage()+":"+b.helper.ImageTags[0], options...)
+	b.helper.SaveSBOM(ctx, imageCache.ResolveImage()+":"+b.helper.ImageTags[0], options...)
+	return nil
+}
+
+// platforms returns the platforms of the image or, if the image doesn't specify any, of the local registry
+func (b *Builder) platforms() []string {
+	if len(b.helper.ImageConf.Platforms) > 0 {
+		return b.helper.ImageConf.Platforms
+	}
 
+	return b.localRegistry.Platforms
 }
 
 // ShouldRebuild determines if an image has to be rebuilt
@@ -80,7 +112,7 @@ func (b *Builder) ShouldRebuild(ctx devspacecontext.Context, forceRebuild bool)
 		if imageCache.IsLocalRegistryImage() {
 			imageName := imageCache.ResolveImage()
 
-			found, err := IsImageAvailableRemotely(ctx.Context(), imageName, b)
+			found, err := IsImageAvailableRemotely(ctx, imageName, b)
 			if !found && err == nil {
 				ctx.Log().Infof("Rebuild image %s because it was not found in the local registry", imageName)
 				return true, nil
@@ -104,24 +136,31 @@ func (b *Builder) ShouldRebuild(ctx devspacecontext.Context, forceRebuild bool)
 }
 
 // IsImageAvailableRemotely will check if current image needs to be built or not
-func IsImageAvailableRemotely(ctx context.Context, imageName string, b *Builder) (bool, error) {
+func IsImageAvailableRemotely(ctx devspacecontext.Context, imageName string, b *Builder) (bool, error) {
 	ref, err := name.ParseReference(imageName)
 	if err != nil {
 		return false, err
 	}
 
-	image, err := remote.Image(
-		ref,
-		remote.WithContext(ctx),
+	options := []remote.Option{
+		remote.WithContext(ctx.Context()),
 		remote.WithTransport(remote.DefaultTransport),
-	)
-	if err != nil {
-		transportError, ok := err.(*transport.Error)
-		if ok && transportError.StatusCode == http.StatusNotFound {
-			return false, nil
-		}
-		return false, err
 	}
+	options = append(options, b.localRegistry.RemoteOptions()...)
+
+	found := false
+	err = b.registryOptions.retry(ctx.Context(), ctx.Log(), "Check image "+imageName, func() error {
+		_, err := remote.Image(ref, options...)
+		if err != nil {
+			transportError, ok := err.(*transport.Error)
+			if ok && transportError.StatusCode == http.StatusNotFound {
+				return nil
+			}
+			return err
+		}
 
-	return image != nil, nil
+		found = true
+		return nil
+	})
+	return found, err
 }
@@ -0,0 +1,53 @@
+package localregistry
+
+import (
+	"encoding/csv"
+	"strings"
+
+	buildkit "github.com/moby/buildkit/client"
+	"github.com/pkg/errors"
+)
+
+// parseCacheEntries parses caches in the format of docker buildx --cache-from and --cache-to,
+// e.g. type=registry,ref=registry.io/app:cache. A value without type is an image to import from
+func parseCacheEntries(values []string) ([]buildkit.CacheOptionsEntry, error) {
+	entries := []buildkit.CacheOptionsEntry{}
+	for _, value := range values {
+		if !strings.Contains(value, "=") {
+			entries = append(entries, buildkit.CacheOptionsEntry{
+				Type:  "registry",
+				Attrs: map[string]string{"ref": value},
+			})
+			continue
+		}
+
+		fields, err := csv.NewReader(strings.NewReader(value)).Read()
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse cache %s", value)
+		}
+
+		entry := buildkit.CacheOptionsEntry{
+			Attrs: map[string]string{},
+		}
+		for _, field := range fields {
+			key, val, found := strings.Cut(field, "=")
+			if !found {
+				return nil, errors.Errorf("invalid cache field %s in %s, expected key=value", field, value)
+			}
+
+			key = strings.ToLower(key)
+			if key == "type" {
+				entry.Type = val
+			} else {
+				entry.Attrs[key] = val
+			}
+		}
+		if entry.Type == "" {
+			return nil, errors.Errorf("cache %s has no type", value)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
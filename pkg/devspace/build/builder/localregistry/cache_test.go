@@ -0,0 +1,30 @@
+package localregistry
+
+import (
+	"testing"
+
+	buildkit "github.com/moby/buildkit/client"
+	"gotest.tools/assert"
+)
+
+func TestParseCacheEntries(t *testing.T) {
+	entries, err := parseCacheEntries([]string{
+		"registry.io/app:cache",
+		"type=registry,ref=registry.io/app:cache,mode=max",
+		"type=inline",
+		`type=local,"dest=/tmp/cache,dir"`,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, entries, []buildkit.CacheOptionsEntry{
+		{Type: "registry", Attrs: map[string]string{"ref": "registry.io/app:cache"}},
+		{Type: "registry", Attrs: map[string]string{"ref": "registry.io/app:cache", "mode": "max"}},
+		{Type: "inline", Attrs: map[string]string{}},
+		{Type: "local", Attrs: map[string]string{"dest": "/tmp/cache,dir"}},
+	})
+
+	_, err = parseCacheEntries([]string{"ref=registry.io/app:cache"})
+	assert.ErrorContains(t, err, "has no type")
+
+	_, err = parseCacheEntries([]string{"type=registry,max"})
+	assert.ErrorContains(t, err, "invalid cache field max")
+}
@@ -2,6 +2,7 @@ package localregistry
 
 import (
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/sirupsen/logrus"
 	"io"
 	"net"
@@ -28,7 +29,7 @@ func ExecConn(ctx devspacecontext.Context, namespace, pod, container string, cmd
 			Stderr:    true,
 			TTY:       false,
 		}, scheme.ParameterCodec)
-	exec, err := remotecommand.NewSPDYExecutor(ctx.KubeClient().RestConfig(), "POST", req.URL())
+	exec, err := kubectl.NewExecutor(ctx.KubeClient(), req.URL())
 	if err != nil {
 		return nil, err
 	}
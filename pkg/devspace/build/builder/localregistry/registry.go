@@ -0,0 +1,94 @@
+package localregistry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// registryOptions configure how operations on the local registry are retried. A single flaky
+// request to the registry shouldn't fail the whole pipeline
+type registryOptions struct {
+	// Backoff is used between the attempts of an operation that failed with a transient
+	// error. Steps is the maximum number of attempts
+	Backoff remote.Backoff
+}
+
+// newRegistryOptions returns the options for the given number of retries, the delay
+// between the attempts starts at one second and doubles after each attempt. Without
+// retries, operations are attempted once and go-containerregistry keeps its own retries
+func newRegistryOptions(retries int) registryOptions {
+	if retries <= 0 {
+		return registryOptions{Backoff: remote.Backoff{Steps: 1}}
+	}
+
+	return registryOptions{
+		Backoff: remote.Backoff{
+			Duration: time.Second,
+			Factor:   2,
+			Jitter:   0.1,
+			Steps:    retries + 1,
+			Cap:      30 * time.Second,
+		},
+	}
+}
+
+// remoteOptions returns the options for go-containerregistry. If operations are retried
+// by retry, go-containerregistry doesn't retry the writes again, otherwise every
+// attempt would retry the blob uploads and the delays would add up
+func (o registryOptions) remoteOptions() []remote.Option {
+	if o.Backoff.Steps <= 1 {
+		return nil
+	}
+
+	return []remote.Option{remote.WithRetryBackoff(remote.Backoff{Steps: 1})}
+}
+
+// retry runs the operation until it succeeds, fails with an error that is not transient or
+// runs out of attempts
+func (o registryOptions) retry(ctx context.Context, logger log.Logger, operation string, fn func() error) error {
+	backoff := o.Backoff
+	for {
+		err := fn()
+		if err == nil || backoff.Steps <= 1 || !isTransientError(err) {
+			return err
+		}
+
+		delay := backoff.Step()
+		logger.Debugf("%s failed, retrying in %s: %v", operation, delay.Round(time.Millisecond), err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isTransientError returns true for server errors, rate limits and network errors that
+// might not occur again on the next attempt
+func isTransientError(err error) bool {
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) {
+		return transportErr.StatusCode >= http.StatusInternalServerError ||
+			transportErr.StatusCode == http.StatusTooManyRequests ||
+			transportErr.StatusCode == http.StatusRequestTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE)
+}
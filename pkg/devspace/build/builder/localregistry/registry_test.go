@@ -0,0 +1,73 @@
+package localregistry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestRegistryOptionsRetry(t *testing.T) {
+	options := newRegistryOptions(2)
+	options.Backoff.Duration = time.Millisecond
+
+	// transient errors are retried until the attempts are used up
+	attempts := 0
+	err := options.retry(context.Background(), log.Discard, "test", func() error {
+		attempts++
+		return &transport.Error{StatusCode: http.StatusBadGateway}
+	})
+	assert.ErrorContains(t, err, "status code 502")
+	assert.Equal(t, attempts, 3)
+
+	// the operation stops retrying once it succeeds
+	attempts = 0
+	err = options.retry(context.Background(), log.Discard, "test", func() error {
+		attempts++
+		if attempts == 1 {
+			return &transport.Error{StatusCode: http.StatusTooManyRequests}
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, attempts, 2)
+
+	// other errors are returned right away
+	attempts = 0
+	err = options.retry(context.Background(), log.Discard, "test", func() error {
+		attempts++
+		return &transport.Error{StatusCode: http.StatusUnauthorized}
+	})
+	assert.Assert(t, err != nil)
+	assert.Equal(t, attempts, 1)
+
+	attempts = 0
+	err = options.retry(context.Background(), log.Discard, "test", func() error {
+		attempts++
+		return errors.New("manifest invalid")
+	})
+	assert.Error(t, err, "manifest invalid")
+	assert.Equal(t, attempts, 1)
+
+	// writes are only retried once
+	assert.Equal(t, len(options.remoteOptions()), 1)
+}
+
+func TestRegistryOptionsWithoutRetries(t *testing.T) {
+	options := newRegistryOptions(0)
+
+	// the operation is attempted once and go-containerregistry keeps its default retries
+	attempts := 0
+	err := options.retry(context.Background(), log.Discard, "test", func() error {
+		attempts++
+		return &transport.Error{StatusCode: http.StatusBadGateway}
+	})
+	assert.ErrorContains(t, err, "status code 502")
+	assert.Equal(t, attempts, 1)
+	assert.Equal(t, len(options.remoteOptions()), 0)
+}
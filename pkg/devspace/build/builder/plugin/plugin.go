@@ -0,0 +1,139 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	devspaceplugin "github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"github.com/loft-sh/devspace/pkg/util/hash"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	dockerterm "github.com/moby/term"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	_, stdout, _ = dockerterm.StdStreams()
+)
+
+// Builder holds all the relevant information for a plugin build
+type Builder struct {
+	imageConf *latest.Image
+	imageTags []string
+	skipPush  bool
+
+	plugin  *devspaceplugin.Metadata
+	builder *devspaceplugin.Builder
+}
+
+// NewBuilder creates a new plugin builder
+func NewBuilder(imageConf *latest.Image, imageTags []string, skipPush bool) (*Builder, error) {
+	plugin, pluginBuilder := devspaceplugin.GetBuilder(imageConf.Plugin.Type)
+	if plugin == nil {
+		return nil, fmt.Errorf("couldn't find a plugin that registered build engine %s. Please make sure the plugin is installed", imageConf.Plugin.Type)
+	}
+
+	return &Builder{
+		imageConf: imageConf,
+		imageTags: imageTags,
+		skipPush:  skipPush,
+		plugin:    plugin,
+		builder:   pluginBuilder,
+	}, nil
+}
+
+// ShouldRebuild implements interface
+func (b *Builder) ShouldRebuild(ctx devspacecontext.Context, forceRebuild bool) (bool, error) {
+	// Hash image config
+	configStr, err := yaml.Marshal(*b.imageConf)
+	if err != nil {
+		return false, errors.Wrap(err, "marshal image config")
+	}
+	imageConfigHash := hash.String(string(configStr))
+
+	// Loop over on change globs
+	filesHash := ""
+	for _, pattern := range b.imageConf.Plugin.OnChange {
+		files, err := doublestar.Glob(ctx.ResolvePath(pattern))
+		if err != nil {
+			return false, err
+		}
+
+		for _, file := range files {
+			sha256, err := hash.Directory(file)
+			if err != nil {
+				return false, errors.Wrap(err, "hash "+file)
+			}
+
+			filesHash += sha256
+		}
+	}
+	filesHash = hash.String(filesHash)
+
+	imageCache, _ := ctx.Config().LocalCache().GetImageCache(b.imageConf.Name)
+
+	// only rebuild image when the config or the watched files have changed since latest build
+	mustRebuild := forceRebuild || b.imageConf.RebuildStrategy == latest.RebuildStrategyAlways || imageCache.Tag == "" || imageCache.ImageConfigHash != imageConfigHash || imageCache.CustomFilesHash != filesHash
+
+	imageCache.ImageConfigHash = imageConfigHash
+	imageCache.CustomFilesHash = filesHash
+	ctx.Config().LocalCache().SetImageCache(b.imageConf.Name, imageCache)
+
+	return mustRebuild, nil
+}
+
+// Build implements interface
+func (b *Builder) Build(ctx devspacecontext.Context) error {
+	imageName := b.imageConf.Image
+	if len(b.imageTags) > 0 {
+		imageName += ":" + b.imageTags[0]
+
+		key := fmt.Sprintf("images.%s", b.imageConf.Name)
+		ctx.Config().SetRuntimeVariable(key, b.imageConf.Image+":"+b.imageTags[0])
+		ctx.Config().SetRuntimeVariable(key+".image", b.imageConf.Image)
+		ctx.Config().SetRuntimeVariable(key+".tag", b.imageTags[0])
+	}
+
+	config := map[string]interface{}{}
+	if b.imageConf.Plugin.Config != nil {
+		config = b.imageConf.Plugin.Config
+	}
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return errors.Wrap(err, "marshal plugin config")
+	}
+	tagsBytes, err := json.Marshal(b.imageTags)
+	if err != nil {
+		return errors.Wrap(err, "marshal image tags")
+	}
+
+	// Determine output writer
+	var writer io.WriteCloser
+	if ctx.Log() == logpkg.GetInstance() {
+		writer = logpkg.WithNopCloser(stdout)
+	} else {
+		writer = ctx.Log().Writer(logrus.InfoLevel, false)
+	}
+	defer writer.Close()
+
+	ctx.Log().Infof("Build %s with plugin build engine %s", imageName, b.builder.Name)
+	err = devspaceplugin.CallBuilder(b.plugin, b.builder, map[string]string{
+		devspaceplugin.BuildImageConfigNameEnv: b.imageConf.Name,
+		devspaceplugin.BuildImageEnv:           b.imageConf.Image,
+		devspaceplugin.BuildImageTagsEnv:       string(tagsBytes),
+		devspaceplugin.BuildConfigEnv:          string(configBytes),
+		devspaceplugin.BuildSkipPushEnv:        strconv.FormatBool(b.skipPush),
+	}, writer)
+	if err != nil {
+		return errors.Errorf("error building image: %v", err)
+	}
+
+	ctx.Log().Done("Done processing image '" + b.imageConf.Image + "'")
+	return nil
+}
@@ -0,0 +1,139 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	devspaceplugin "github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+// fakePlugin records the arguments and the build environment it was called with
+const fakePlugin = `#!/bin/sh
+echo "$* $DEVSPACE_PLUGIN_BUILD_IMAGE_CONFIG_NAME $DEVSPACE_PLUGIN_BUILD_IMAGE $DEVSPACE_PLUGIN_BUILD_IMAGE_TAGS $DEVSPACE_PLUGIN_BUILD_SKIP_PUSH $DEVSPACE_PLUGIN_BUILD_CONFIG" >> "$(dirname "$0")/calls"
+`
+
+type buildTestCase struct {
+	name string
+
+	imageTags []string
+	skipPush  bool
+
+	expectedCall string
+	expectedVar  interface{}
+}
+
+func TestPluginBuilder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin binary is a shell script")
+	}
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, devspaceplugin.PluginBinary), []byte(fakePlugin), 0755)
+	assert.NilError(t, err)
+	devspaceplugin.SetPlugins([]devspaceplugin.Metadata{{
+		Name:         "bazel-plugin",
+		PluginFolder: dir,
+		Builders:     []devspaceplugin.Builder{{Name: "bazel", BaseArgs: []string{"builder"}}},
+	}})
+
+	imageConf := &latest.Image{
+		Name:  "api",
+		Image: "registry/api",
+		Plugin: &latest.PluginBuildConfig{
+			Type:   "bazel",
+			Config: map[string]interface{}{"target": "//api"},
+		},
+	}
+
+	// unknown build engines are rejected
+	_, err = NewBuilder(&latest.Image{Plugin: &latest.PluginBuildConfig{Type: "pants"}}, nil, false)
+	assert.ErrorContains(t, err, "couldn't find a plugin that registered build engine pants")
+
+	testCases := []buildTestCase{
+		{
+			name:         "with tags",
+			imageTags:    []string{"v1", "latest"},
+			expectedCall: `builder build api registry/api ["v1","latest"] false {"target":"//api"}`,
+			expectedVar:  "registry/api:v1",
+		},
+		{
+			name:         "without tags",
+			skipPush:     true,
+			expectedCall: `builder build api registry/api null true {"target":"//api"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		_ = os.Remove(filepath.Join(dir, "calls"))
+		conf := config.NewConfig(map[string]interface{}{},
+			map[string]interface{}{},
+			latest.NewRaw(),
+			localcache.New(constants.DefaultCacheFolder),
+			&remotecache.RemoteCache{},
+			map[string]interface{}{},
+			constants.DefaultConfigPath)
+		ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf)
+
+		builder, err := NewBuilder(imageConf, testCase.imageTags, testCase.skipPush)
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		err = builder.Build(ctx)
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+
+		calls, err := os.ReadFile(filepath.Join(dir, "calls"))
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		assert.Equal(t, strings.TrimSpace(string(calls)), testCase.expectedCall, "Unexpected call in testCase %s", testCase.name)
+
+		imageVar, _ := conf.GetRuntimeVariable("images.api")
+		assert.Equal(t, imageVar, testCase.expectedVar, "Unexpected runtime variable in testCase %s", testCase.name)
+	}
+}
+
+func TestPluginBuilderShouldRebuild(t *testing.T) {
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		latest.NewRaw(),
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf)
+
+	imageConf := &latest.Image{Name: "api", Image: "registry/api", Plugin: &latest.PluginBuildConfig{Type: "bazel"}}
+	builder := &Builder{imageConf: imageConf, imageTags: []string{"v1"}}
+
+	// images that were never built are built
+	rebuild, err := builder.ShouldRebuild(ctx, false)
+	assert.NilError(t, err)
+	assert.Assert(t, rebuild)
+
+	imageCache, _ := conf.LocalCache().GetImageCache("api")
+	imageCache.Tag = "v1"
+	conf.LocalCache().SetImageCache("api", imageCache)
+
+	// unchanged images are skipped
+	rebuild, err = builder.ShouldRebuild(ctx, false)
+	assert.NilError(t, err)
+	assert.Assert(t, !rebuild)
+
+	rebuild, err = builder.ShouldRebuild(ctx, true)
+	assert.NilError(t, err)
+	assert.Assert(t, rebuild)
+
+	// config changes trigger a rebuild
+	imageConf.Plugin.Config = map[string]interface{}{"target": "//api"}
+	rebuild, err = builder.ShouldRebuild(ctx, false)
+	assert.NilError(t, err)
+	assert.Assert(t, rebuild)
+}
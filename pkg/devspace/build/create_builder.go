@@ -2,13 +2,17 @@ package build
 
 import (
 	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/buildkit"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/custom"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/docker"
 	"github.com/loft-sh/devspace/pkg/devspace/build/builder/kaniko"
 	localregistry2 "github.com/loft-sh/devspace/pkg/devspace/build/builder/localregistry"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/plugin"
 	"github.com/loft-sh/devspace/pkg/devspace/build/localregistry"
+	"github.com/loft-sh/devspace/pkg/devspace/build/registry"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	dockerclient "github.com/loft-sh/devspace/pkg/devspace/docker"
@@ -21,19 +25,38 @@ func (c *controller) createBuilder(ctx devspacecontext.Context, imageConf *lates
 	var err error
 	var bldr builder.Interface
 
+	// caches from the command line are used for all images
+	imageConf = withBuildCache(imageConf, options)
+
 	// check if we should use local registry
-	if localregistry.UseLocalRegistry(ctx.KubeClient(), ctx.Config().Config(), imageConf, options.SkipPush) && !localregistry.HasPushPermission(imageConf) {
-		return localRegistryBuilder(ctx, imageConf, imageTags, options)
-	} else {
-		// Update cache for non local registry use by default
-		imageCache, _ := ctx.Config().LocalCache().GetImageCache(imageConf.Name)
-		imageCache.ImageName = imageConf.Image
-		imageCache.LocalRegistryImageName = ""
-		ctx.Config().LocalCache().SetImageCache(imageConf.Name, imageCache)
+	if localregistry.UseLocalRegistry(ctx.KubeClient(), ctx.Config().Config(), imageConf, options.SkipPush) {
+		allowed, err := localregistry.CheckPushPermission(imageConf, registry.NewHosts(ctx.Config().Config()))
+		if name.IsErrBadName(err) {
+			return nil, errors.Wrapf(err, "image %s", imageConf.Name)
+		} else if !allowed {
+			ctx.Log().Infof("Using local registry for image %s, because it can't be pushed: %v", imageConf.Image, err)
+			return localRegistryBuilder(ctx, imageConf, imageTags, options)
+		}
 	}
 
+	// Update cache for non local registry use by default
+	imageCache, _ := ctx.Config().LocalCache().GetImageCache(imageConf.Name)
+	imageCache.ImageName = imageConf.Image
+	imageCache.LocalRegistryImageName = ""
+	ctx.Config().LocalCache().SetImageCache(imageConf.Name, imageCache)
+
 	if imageConf.Custom != nil {
-		bldr = custom.NewBuilder(imageConf, imageTags)
+		bldr = custom.NewBuilder(imageConf, imageTags, options.SkipPush)
+	} else if imageConf.Plugin != nil {
+		bldr, err = plugin.NewBuilder(imageConf, imageTags, options.SkipPush)
+		if err != nil {
+			return nil, errors.Errorf("Error creating plugin builder: %v", err)
+		}
+	} else if imageConf.BuildKit != nil && imageConf.BuildKit.Remote != nil {
+		bldr, err = buildkit.NewRemoteBuilder(ctx, imageConf, imageTags, options.SkipPush)
+		if err != nil {
+			return nil, errors.Errorf("Error creating remote BuildKit builder: %v", err)
+		}
 	} else if imageConf.BuildKit != nil {
 		bldr, err = buildkit.NewBuilder(ctx, imageConf, imageTags, options.SkipPush, options.SkipPushOnLocalKubernetes)
 		if err != nil {
@@ -56,11 +79,15 @@ func (c *controller) createBuilder(ctx devspacecontext.Context, imageConf *lates
 		}
 	} else {
 		preferMinikube := true
-		if imageConf.Docker != nil && imageConf.Docker.PreferMinikube != nil {
-			preferMinikube = *imageConf.Docker.PreferMinikube
+		dockerContext := ""
+		if imageConf.Docker != nil {
+			if imageConf.Docker.PreferMinikube != nil {
+				preferMinikube = *imageConf.Docker.PreferMinikube
+			}
+			dockerContext = imageConf.Docker.DockerContext
 		}
 
-		dockerClient, err := dockerclient.NewClientWithMinikube(ctx.Context(), ctx.KubeClient(), preferMinikube, ctx.Log())
+		dockerClient, err := dockerclient.NewClientWithContext(ctx.Context(), ctx.KubeClient(), preferMinikube, dockerContext, ctx.Log())
 		if err != nil {
 			return nil, errors.Errorf("Error creating docker client: %v", err)
 		}
@@ -92,6 +119,19 @@ func localRegistryBuilder(ctx devspacecontext.Context, imageConf *latest.Image,
 		return nil, fmt.Errorf("unable to push image %s and a valid kube context is not available", imageConf.Image)
 	}
 
+	// Use a registry provider instead of the registry in the cluster if configured
+	provider, err := registry.NewRegistryProvider(ctx.KubeClient(), localRegistryProvider(ctx.Config().Config().LocalRegistry))
+	if err != nil {
+		return nil, err
+	} else if provider != nil {
+		bldr, err := registryProviderBuilder(ctx, provider, imageConf, imageTags)
+		if err != nil {
+			return nil, err
+		} else if bldr != nil {
+			return bldr, nil
+		}
+	}
+
 	registryOptions := localregistry.NewDefaultOptions().
 		WithNamespace(ctx.KubeClient().Namespace()).
 		WithLocalRegistryConfig(ctx.Config().Config().LocalRegistry)
@@ -102,6 +142,12 @@ func localRegistryBuilder(ctx devspacecontext.Context, imageConf *latest.Image,
 		return nil, errors.Wrap(err, "get or create local registry")
 	}
 
+	// Allow the pods in the namespace to pull from the local registry
+	err = localRegistry.EnsurePullSecret(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "ensure local registry pull secret")
+	}
+
 	// Update cache for local registry use
 	imageCache, _ := ctx.Config().LocalCache().GetImageCache(imageConf.Name)
 	imageCache.ImageName = imageConf.Image
@@ -111,6 +157,11 @@ func localRegistryBuilder(ctx devspacecontext.Context, imageConf *latest.Image,
 	}
 	ctx.Config().LocalCache().SetImageCache(imageConf.Name, imageCache)
 
+	// The local registry builder pushes the image while building it, so it couldn't be scanned before the push
+	if imageConf.Scan != nil {
+		return nil, errors.Errorf("image %s can't be scanned, because it is built with the local registry. Please make sure docker is running", imageConf.Name)
+	}
+
 	// Create a local registry builder
 	bldr, err := localregistry2.NewBuilder(ctx, localRegistry, imageConf, imageTags, options.SkipPush, options.SkipPushOnLocalKubernetes)
 	if err != nil {
@@ -119,3 +170,61 @@ func localRegistryBuilder(ctx devspacecontext.Context, imageConf *latest.Image,
 
 	return bldr, nil
 }
+
+// registryProviderBuilder creates a docker builder that loads the image with the provider. If there is
+// no docker daemon, nil is returned and the registry in the cluster is used instead
+func registryProviderBuilder(ctx devspacecontext.Context, provider registry.RegistryProvider, imageConf *latest.Image, imageTags []string) (builder.Interface, error) {
+	dockerContext := ""
+	if imageConf.Docker != nil {
+		dockerContext = imageConf.Docker.DockerContext
+	}
+
+	dockerClient, err := dockerclient.NewClientWithContext(ctx.Context(), nil, false, dockerContext, ctx.Log())
+	if err == nil {
+		_, err = dockerClient.Ping(ctx.Context())
+	}
+	if err != nil {
+		ctx.Log().Warnf("Couldn't find a running docker daemon for local registry provider %s. Will fallback to local registry", provider.Name())
+		return nil, nil
+	}
+
+	// Update cache for provider use, the cluster pulls the image under its original name if the
+	// provider doesn't rewrite it
+	imageCache, _ := ctx.Config().LocalCache().GetImageCache(imageConf.Name)
+	imageCache.ImageName = imageConf.Image
+	imageCache.LocalRegistryImageName = ""
+	rewrittenImage, err := provider.RewriteImage(imageConf.Image)
+	if err != nil {
+		return nil, errors.Wrap(err, "rewrite image")
+	} else if originalImage, _ := registry.Repository(imageConf.Image); rewrittenImage != originalImage {
+		imageCache.LocalRegistryImageName = rewrittenImage
+	}
+	ctx.Config().LocalCache().SetImageCache(imageConf.Name, imageCache)
+
+	bldr, err := docker.NewRegistryProviderBuilder(ctx, dockerClient, imageConf, imageTags, provider)
+	if err != nil {
+		return nil, errors.Errorf("Error creating docker builder: %v", err)
+	}
+
+	return bldr, nil
+}
+
+func localRegistryProvider(config *latest.LocalRegistryConfig) string {
+	if config == nil {
+		return ""
+	}
+
+	return config.Provider
+}
+
+// withBuildCache returns a copy of the image config with the caches of the options added
+func withBuildCache(imageConf *latest.Image, options *Options) *latest.Image {
+	if len(options.CacheFrom) == 0 && len(options.CacheTo) == 0 {
+		return imageConf
+	}
+
+	newImageConf := *imageConf
+	newImageConf.CacheFrom = append(append([]string{}, imageConf.CacheFrom...), options.CacheFrom...)
+	newImageConf.CacheTo = append(append([]string{}, imageConf.CacheTo...), options.CacheTo...)
+	return &newImageConf
+}
@@ -0,0 +1,112 @@
+package localregistry
+
+import (
+	"strconv"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/ptr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	CacheContainer = "cache"
+
+	cacheVolume = "registry-cache"
+)
+
+// IsCacheEnabled returns true if the pull-through cache of the local registry is enabled
+func IsCacheEnabled(config *latest.Config) bool {
+	return config.LocalRegistry != nil &&
+		config.LocalRegistry.Cache != nil &&
+		config.LocalRegistry.Cache.Enabled != nil &&
+		*config.LocalRegistry.Cache.Enabled
+}
+
+// cachePodSpec adds the pull-through cache to the registry pod. The cache runs the registry image
+// in proxy mode, which can't be pushed to, and therefore needs its own container and storage
+func (r *LocalRegistry) cachePodSpec(spec *corev1.PodSpec) {
+	if !r.CacheEnabled {
+		return
+	}
+
+	probe := func(initialDelaySeconds, periodSeconds int32) *corev1.Probe {
+		return &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: "/v2/",
+					Port: intstr.IntOrString{
+						IntVal: int32(r.CachePort),
+					},
+				},
+			},
+			InitialDelaySeconds: initialDelaySeconds,
+			TimeoutSeconds:      1,
+			PeriodSeconds:       periodSeconds,
+			SuccessThreshold:    1,
+			FailureThreshold:    3,
+		}
+	}
+
+	spec.Containers = append(spec.Containers, corev1.Container{
+		Name:  CacheContainer,
+		Image: r.RegistryImage,
+		Env: []corev1.EnvVar{
+			{
+				Name:  "REGISTRY_HTTP_ADDR",
+				Value: ":" + strconv.Itoa(r.CachePort),
+			},
+			{
+				Name:  "REGISTRY_PROXY_REMOTEURL",
+				Value: r.CacheRemoteURL,
+			},
+		},
+		LivenessProbe:  probe(10, 20),
+		ReadinessProbe: probe(2, 5),
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:                ptr.Int64(1000),
+			RunAsNonRoot:             ptr.Bool(true),
+			ReadOnlyRootFilesystem:   ptr.Bool(true),
+			AllowPrivilegeEscalation: ptr.Bool(false),
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      cacheVolume,
+				MountPath: "/var/lib/registry",
+			},
+		},
+	})
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: cacheVolume,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+}
+
+// getCacheService returns the cluster ip service of the pull-through cache. The cache is only used
+// by builds within the cluster and mustn't be reachable through the nodes
+func (r *LocalRegistry) getCacheService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: r.CacheServiceName(),
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:     CacheContainer,
+					Protocol: corev1.ProtocolTCP,
+					Port:     int32(r.CachePort),
+					TargetPort: intstr.IntOrString{
+						IntVal: int32(r.CachePort),
+					},
+				},
+			},
+			Selector: map[string]string{
+				"app": r.Name,
+			},
+			Type: corev1.ServiceTypeClusterIP,
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package localregistry
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/ptr"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCachePodSpec(t *testing.T) {
+	options := NewDefaultOptions().
+		WithNamespace("test").
+		WithLocalRegistryConfig(&latest.LocalRegistryConfig{
+			Cache: &latest.LocalRegistryCache{
+				Enabled:   ptr.Bool(true),
+				RemoteURL: "https://mirror.gcr.io",
+			},
+		})
+	assert.Equal(t, options.CacheHost(), "registry-cache.test.svc.cluster.local:5001")
+	assert.Equal(t, options.CacheRegistry(), "mirror.gcr.io")
+	assert.Equal(t, NewDefaultOptions().CacheRegistry(), "index.docker.io")
+
+	registry := newLocalRegistry(options)
+	spec := registry.getStatefulSet().Spec.Template.Spec
+	cache := spec.Containers[len(spec.Containers)-1]
+	assert.Equal(t, cache.Name, CacheContainer)
+	assert.Equal(t, cache.Env[0].Value, ":5001")
+	assert.Equal(t, cache.Env[1].Value, "https://mirror.gcr.io")
+	assert.Equal(t, spec.Volumes[len(spec.Volumes)-1].Name, cacheVolume)
+
+	// the cache isn't exposed through the node port of the registry
+	assert.Equal(t, len(registry.getService().Spec.Ports), 1)
+	service := registry.getCacheService()
+	assert.Equal(t, service.Name, "registry-cache")
+	assert.Equal(t, service.Spec.Type, corev1.ServiceTypeClusterIP)
+	assert.Equal(t, service.Spec.Ports[0].Port, int32(5001))
+
+	// without cache there is no cache container
+	registry = newLocalRegistry(NewDefaultOptions().WithNamespace("test"))
+	for _, container := range registry.getDeployment().Spec.Template.Spec.Containers {
+		assert.Assert(t, container.Name != CacheContainer)
+	}
+	assert.Equal(t, len(registry.getService().Spec.Ports), 1)
+}
@@ -6,6 +6,7 @@ import (
 
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/loft-sh/devspace/pkg/util/ptr"
+	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -58,6 +59,17 @@ func (r *LocalRegistry) ensureDeployment(ctx devspacecontext.Context) (*appsv1.D
 		return nil, err
 	}
 
+	// Registries that were started with other tls or auth settings are updated explicitly,
+	// because the apply below only keeps the fields of the existing registry
+	if r.needsMigration(existing.Spec.Template.Spec) {
+		ctx.Log().Infof("Migrating local registry %s to the configured tls and auth settings", r.Name)
+		existing.Spec.Template.Spec = desired.Spec.Template.Spec
+		existing, err = ctx.KubeClient().KubeClient().AppsV1().Deployments(r.Namespace).Update(ctx.Context(), existing, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "migrate local registry")
+		}
+	}
+
 	// Use server side apply if it does exist
 	applyConfiguration, err := appsapplyv1.ExtractDeployment(existing, ApplyFieldManager)
 	if err != nil {
@@ -74,7 +86,7 @@ func (r *LocalRegistry) ensureDeployment(ctx devspacecontext.Context) (*appsv1.D
 }
 
 func (r *LocalRegistry) getDeployment() *appsv1.Deployment {
-	return &appsv1.Deployment{
+	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: r.Name,
 		},
@@ -112,6 +124,10 @@ func (r *LocalRegistry) getDeployment() *appsv1.Deployment {
 			},
 		},
 	}
+
+	r.securePodSpec(&deployment.Spec.Template.Spec)
+	r.cachePodSpec(&deployment.Spec.Template.Spec)
+	return deployment
 }
 
 func getAnnotations(localbuild bool) map[string]string {
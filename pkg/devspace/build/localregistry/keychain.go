@@ -0,0 +1,78 @@
+package localregistry
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+// Keychain resolves credentials from the docker config first and falls back to the credential
+// helpers of the cloud providers, which hand out short-lived tokens for their registries. This
+// way pushing to ECR, GCR / Artifact Registry and ACR works without running docker login first
+var Keychain = authn.NewMultiKeychain(authn.DefaultKeychain, &cloudKeychain{
+	lookPath: exec.LookPath,
+	program:  client.NewShellProgramFunc,
+})
+
+var ecrRegistryRegex = regexp.MustCompile(`^\d{12}\.dkr\.ecr(-fips)?\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// tokenUsername is returned by credential helpers that return an identity token instead of a password
+const tokenUsername = "<token>"
+
+type cloudKeychain struct {
+	lookPath func(file string) (string, error)
+	program  func(name string) client.ProgramFunc
+}
+
+// Resolve implements authn.Keychain
+func (c *cloudKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	helper := credentialHelper(target.RegistryStr())
+	if helper == "" {
+		return authn.Anonymous, nil
+	}
+
+	// the helpers are optional, without them the registry is accessed anonymously as before
+	program := "docker-credential-" + helper
+	if _, err := c.lookPath(program); err != nil {
+		return authn.Anonymous, nil
+	}
+
+	creds, err := client.Get(c.program(program), target.RegistryStr())
+	if err != nil {
+		if credentials.IsErrCredentialsNotFound(err) {
+			return authn.Anonymous, nil
+		}
+
+		return nil, err
+	}
+
+	if creds.Username == tokenUsername {
+		return authn.FromConfig(authn.AuthConfig{
+			IdentityToken: creds.Secret,
+		}), nil
+	}
+
+	return authn.FromConfig(authn.AuthConfig{
+		Username: creds.Username,
+		Password: creds.Secret,
+	}), nil
+}
+
+// credentialHelper returns the name of the credential helper for the registry or an empty string
+// if the registry doesn't belong to a known cloud provider
+func credentialHelper(registry string) string {
+	switch {
+	case ecrRegistryRegex.MatchString(registry):
+		return "ecr-login"
+	case registry == "gcr.io" || strings.HasSuffix(registry, ".gcr.io") || strings.HasSuffix(registry, "-docker.pkg.dev"):
+		return "gcloud"
+	case strings.HasSuffix(registry, ".azurecr.io"):
+		return "acr-env"
+	}
+
+	return ""
+}
@@ -0,0 +1,92 @@
+package localregistry
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"gotest.tools/assert"
+)
+
+type fakeProgram struct {
+	output string
+	err    error
+}
+
+func (f *fakeProgram) Output() ([]byte, error) {
+	return []byte(f.output), f.err
+}
+
+func (f *fakeProgram) Input(in io.Reader) {}
+
+func TestCredentialHelper(t *testing.T) {
+	testCases := map[string]string{
+		"123456789012.dkr.ecr.eu-west-1.amazonaws.com":     "ecr-login",
+		"123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn": "ecr-login",
+		"gcr.io":                          "gcloud",
+		"eu.gcr.io":                       "gcloud",
+		"europe-west1-docker.pkg.dev":     "gcloud",
+		"myregistry.azurecr.io":           "acr-env",
+		"index.docker.io":                 "",
+		"dkr.ecr.eu-west-1.amazonaws.com": "",
+	}
+
+	for registry, expected := range testCases {
+		assert.Equal(t, credentialHelper(registry), expected, registry)
+	}
+}
+
+func TestCloudKeychain(t *testing.T) {
+	programs := []string{}
+	keychain := &cloudKeychain{
+		lookPath: func(file string) (string, error) {
+			if file == "docker-credential-acr-env" {
+				return "", errors.New("not found")
+			}
+			return file, nil
+		},
+		program: func(name string) client.ProgramFunc {
+			programs = append(programs, name)
+			return func(args ...string) client.Program {
+				return &fakeProgram{output: `{"Username":"AWS","Secret":"token"}`}
+			}
+		},
+	}
+
+	registry, err := name.NewRegistry("123456789012.dkr.ecr.eu-west-1.amazonaws.com")
+	assert.NilError(t, err)
+	auth, err := keychain.Resolve(registry)
+	assert.NilError(t, err)
+	config, err := auth.Authorization()
+	assert.NilError(t, err)
+	assert.Equal(t, config.Username, "AWS")
+	assert.Equal(t, config.Password, "token")
+	assert.DeepEqual(t, programs, []string{"docker-credential-ecr-login"})
+
+	// missing helpers and unknown registries are accessed anonymously
+	for _, host := range []string{"myregistry.azurecr.io", "index.docker.io"} {
+		registry, err = name.NewRegistry(host)
+		assert.NilError(t, err)
+		auth, err = keychain.Resolve(registry)
+		assert.NilError(t, err)
+		assert.Equal(t, auth, authn.Anonymous)
+	}
+	assert.Equal(t, len(programs), 1)
+
+	// identity tokens are passed on as such
+	keychain.program = func(name string) client.ProgramFunc {
+		return func(args ...string) client.Program {
+			return &fakeProgram{output: `{"Username":"<token>","Secret":"refresh"}`}
+		}
+	}
+	registry, err = name.NewRegistry("gcr.io")
+	assert.NilError(t, err)
+	auth, err = keychain.Resolve(registry)
+	assert.NilError(t, err)
+	config, err = auth.Authorization()
+	assert.NilError(t, err)
+	assert.Equal(t, config.IdentityToken, "refresh")
+}
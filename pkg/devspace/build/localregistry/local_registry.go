@@ -33,6 +33,15 @@ type LocalRegistry struct {
 	Options
 	host        string
 	servicePort *corev1.ServicePort
+
+	// tunnel is the local address of the port forwarding, if the registry host can't be
+	// reached from this machine and the connections are redirected to the registry pod
+	tunnel string
+
+	// caCert, username and password are set if the registry is secured with tls or auth
+	caCert   []byte
+	username string
+	password string
 }
 
 func GetOrCreateLocalRegistry(
@@ -69,10 +78,28 @@ func newLocalRegistry(options Options) *LocalRegistry {
 func (r *LocalRegistry) Start(ctx devspacecontext.Context) error {
 	ctx.Log().Info("Starting Local Image Registry")
 
+	// the nodes of a cluster ip service pull via the cluster ip, which is only known once the
+	// service exists and therefore not part of the certificate
+	if r.TLSEnabled && r.ServiceType == string(corev1.ServiceTypeClusterIP) {
+		return errors.Errorf("local registry tls can't be used with service type %s, because the nodes pull via the cluster ip of the service, which isn't part of the certificate", corev1.ServiceTypeClusterIP)
+	}
+
 	if err := r.ensureNamespace(ctx); err != nil {
 		return errors.Wrap(err, "ensure namespace")
 	}
 
+	if r.TLSEnabled {
+		if err := r.ensureTLSSecret(ctx); err != nil {
+			return errors.Wrap(err, "ensure tls secret")
+		}
+	}
+
+	if r.AuthEnabled {
+		if err := r.ensureAuthSecret(ctx); err != nil {
+			return errors.Wrap(err, "ensure auth secret")
+		}
+	}
+
 	if r.StorageEnabled {
 		if _, err := r.ensureStatefulset(ctx); err != nil {
 			return errors.Wrap(err, "ensure statefulset")
@@ -83,26 +110,46 @@ func (r *LocalRegistry) Start(ctx devspacecontext.Context) error {
 		}
 	}
 
-	if _, err := r.ensureService(ctx); err != nil {
+	if _, err := r.ensureService(ctx, r.getService()); err != nil {
 		return errors.Wrap(err, "ensure service")
 	}
+	if r.CacheEnabled {
+		if _, err := r.ensureService(ctx, r.getCacheService()); err != nil {
+			return errors.Wrap(err, "ensure cache service")
+		}
+	}
 
 	// Wait for service to have a node port
 	ctx.Log().Debug("Wait for local registry node port to be assigned...")
-	var err error
-	r.servicePort, err = r.waitForNodePort(ctx)
+	service, err := r.waitForNodePort(ctx)
 	if err != nil {
 		return errors.Wrap(err, "wait for node port")
 	}
+	r.servicePort = GetServicePort(service)
+
+	// Save registry host for rewriting images. Without a node port, the nodes
+	// pull the images via the cluster ip of the service
+	if r.servicePort.NodePort != 0 {
+		r.host = fmt.Sprintf("localhost:%d", r.servicePort.NodePort)
+	} else {
+		r.host = fmt.Sprintf("%s:%d", service.Spec.ClusterIP, r.servicePort.Port)
+	}
 
-	// Save registry host for rewriting images
-	r.host = fmt.Sprintf("localhost:%d", r.servicePort.NodePort)
+	// Let the nodes trust the certificate of the registry
+	if r.TLSEnabled {
+		if err := r.ensureNodeConfig(ctx); err != nil {
+			return errors.Wrap(err, "ensure node config")
+		}
+	}
 
-	// Check if local registry is already available
-	ctx.Log().Debug("Check for running local registry")
-	isRegistryAvailable, err := r.ping(ctx.Context())
-	if err != nil {
-		return errors.Wrap(err, "ping local registry")
+	// Check if local registry is already available, the cluster ip can't be reached from outside the cluster
+	isRegistryAvailable := false
+	if r.servicePort.NodePort != 0 {
+		ctx.Log().Debug("Check for running local registry")
+		isRegistryAvailable, err = r.ping(ctx.Context())
+		if err != nil {
+			return errors.Wrap(err, "ping local registry")
+		}
 	}
 
 	// Select the registry pod
@@ -151,7 +198,7 @@ func (r *LocalRegistry) RewriteImage(image string) (string, error) {
 }
 
 func (r *LocalRegistry) RewriteImageForBuilder(image string) (string, error) {
-	registry, err := name.NewRegistry("localhost:5000")
+	registry, err := name.NewRegistry(BuilderRegistryHost)
 	if err != nil {
 		return "", err
 	}
@@ -186,8 +233,10 @@ func (r *LocalRegistry) SelectRegistryPod(ctx devspacecontext.Context) (*corev1.
 	return selector.SelectSinglePod(ctx.Context(), ctx.KubeClient(), &log.DiscardLogger{})
 }
 
-func (r *LocalRegistry) waitForNodePort(ctx devspacecontext.Context) (*corev1.ServicePort, error) {
-	var servicePort *corev1.ServicePort
+// waitForNodePort waits until the node port of the service is assigned. A service of type ClusterIP
+// is returned right away, as it never gets a node port
+func (r *LocalRegistry) waitForNodePort(ctx devspacecontext.Context) (*corev1.Service, error) {
+	var service *corev1.Service
 
 	kubeClient := ctx.KubeClient().KubeClient()
 	err := wait.PollImmediateWithContext(
@@ -195,19 +244,23 @@ func (r *LocalRegistry) waitForNodePort(ctx devspacecontext.Context) (*corev1.Se
 		time.Second,
 		30*time.Second,
 		func(ctx context.Context) (done bool, err error) {
-			service, err := kubeClient.CoreV1().
+			service, err = kubeClient.CoreV1().
 				Services(r.Namespace).
 				Get(ctx, r.Name, metav1.GetOptions{})
 			if err != nil {
 				return false, err
 			}
 
-			servicePort = GetServicePort(service)
-			return servicePort.NodePort != 0, nil
+			servicePort := GetServicePort(service)
+			if servicePort == nil {
+				return false, fmt.Errorf("service %s has no port named registry", r.Name)
+			}
+
+			return service.Spec.Type == corev1.ServiceTypeClusterIP || servicePort.NodePort != 0, nil
 		},
 	)
 
-	return servicePort, err
+	return service, err
 }
 
 // GetRegistryURL returns the host:port of the current registry
@@ -221,14 +274,13 @@ func (r *LocalRegistry) startPortForwarding(
 	ctx devspacecontext.Context,
 	imageRegistryPod *corev1.Pod,
 ) error {
+	// forward the node port, so that the registry is reachable with the same host as from
+	// the nodes. Without a node port, the forwarder listens on a free port and the connections
+	// to the cluster ip are redirected to it
 	localPort := r.servicePort.NodePort
 	remotePort := r.servicePort.TargetPort.IntVal
 	ports := []string{fmt.Sprintf("%d:%d", localPort, remotePort)}
 	addresses := []string{"localhost"}
-	portsFormatted := ansi.Color(
-		fmt.Sprintf("%d -> %d", int(localPort), int(remotePort)),
-		"white+b",
-	)
 	readyChan := make(chan struct{})
 	errorChan := make(chan error, 1)
 	pf, err := kubectl.NewPortForwarder(
@@ -256,6 +308,22 @@ func (r *LocalRegistry) startPortForwarding(
 		ctx.Log().Donef("Port forwarding to local registry stopped")
 		return nil
 	case <-readyChan:
+		// the port is only chosen when the forwarder listens, so that no other process can take it
+		// in the meantime
+		if localPort == 0 {
+			forwardedPorts, err := pf.GetPorts()
+			if err != nil {
+				return errors.Wrap(err, "get forwarded port")
+			}
+
+			localPort = int32(forwardedPorts[0].Local)
+			r.tunnel = fmt.Sprintf("localhost:%d", localPort)
+		}
+
+		portsFormatted := ansi.Color(
+			fmt.Sprintf("%d -> %d", int(localPort), int(remotePort)),
+			"white+b",
+		)
 		ctx.Log().Donef("Port forwarding to local registry started on: %s", portsFormatted)
 	case err := <-errorChan:
 		if ctx.IsDone() {
@@ -287,7 +355,7 @@ func (r *LocalRegistry) ping(ctx context.Context) (done bool, err error) {
 		return false, err
 	}
 
-	_, err = remote.Catalog(ctx, registry)
+	_, err = remote.Catalog(ctx, registry, append(r.RemoteOptions(), remote.WithContext(ctx))...)
 	if err != nil {
 		return false, nil
 	}
@@ -0,0 +1,36 @@
+package localregistry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestTunnel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"repositories":[]}`))
+	}))
+	defer server.Close()
+
+	// the cluster ip isn't reachable, the connections go through the tunnel
+	registry := newLocalRegistry(NewDefaultOptions().WithServiceType("ClusterIP"))
+	registry.host = "10.96.0.42:5000"
+	registry.tunnel = strings.TrimPrefix(server.URL, "http://")
+	assert.Equal(t, string(registry.getService().Spec.Type), "ClusterIP")
+
+	available, err := registry.ping(context.Background())
+	assert.NilError(t, err)
+	assert.Assert(t, available)
+}
+
+func TestStartTLSWithClusterIP(t *testing.T) {
+	registry := newLocalRegistry(NewDefaultOptions().WithServiceType("ClusterIP").WithTLS(true))
+	err := registry.Start(devspacecontext.NewContext(context.Background(), nil, log.Discard))
+	assert.ErrorContains(t, err, "local registry tls can't be used with service type ClusterIP")
+}
@@ -1,8 +1,11 @@
 package localregistry
 
 import (
+	"fmt"
+	"net/url"
 	"path"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 )
 
@@ -12,6 +15,11 @@ var (
 	BuildKitImage          = "moby/buildkit:master-rootless"
 	RegistryPort           = 5000
 	RegistryDefaultStorage = "5Gi"
+	HtpasswdImage          = "httpd:2.4-alpine"
+	RegistryRetries        = 3
+	CacheRemoteURL         = "https://registry-1.docker.io"
+	CachePort              = 5001
+	RegistryServiceType    = "NodePort"
 )
 
 type Options struct {
@@ -24,6 +32,14 @@ type Options struct {
 	StorageEnabled   bool
 	StorageSize      string
 	StorageClassName string
+	TLSEnabled       bool
+	AuthEnabled      bool
+	Platforms        []string
+	Retries          int
+	CacheEnabled     bool
+	CacheRemoteURL   string
+	CachePort        int
+	ServiceType      string
 }
 
 func getID(o Options) string {
@@ -41,6 +57,13 @@ func NewDefaultOptions() Options {
 		StorageEnabled:   false,
 		StorageSize:      RegistryDefaultStorage,
 		StorageClassName: "",
+		TLSEnabled:       false,
+		AuthEnabled:      false,
+		Retries:          RegistryRetries,
+		CacheEnabled:     false,
+		CacheRemoteURL:   CacheRemoteURL,
+		CachePort:        CachePort,
+		ServiceType:      RegistryServiceType,
 	}
 }
 
@@ -84,6 +107,14 @@ func (o Options) WithPort(port *int) Options {
 	return newOptions
 }
 
+func (o Options) WithServiceType(serviceType string) Options {
+	newOptions := o
+	if serviceType != "" {
+		newOptions.ServiceType = serviceType
+	}
+	return newOptions
+}
+
 func (o Options) WithLocalBuild(localbuild bool) Options {
 	newOptions := o
 	newOptions.LocalBuild = localbuild
@@ -91,6 +122,100 @@ func (o Options) WithLocalBuild(localbuild bool) Options {
 	return newOptions
 }
 
+func (o Options) WithTLS(enabled bool) Options {
+	newOptions := o
+	newOptions.TLSEnabled = enabled
+	return newOptions
+}
+
+func (o Options) WithAuth(enabled bool) Options {
+	newOptions := o
+	newOptions.AuthEnabled = enabled
+	return newOptions
+}
+
+func (o Options) WithPlatforms(platforms []string) Options {
+	newOptions := o
+	if len(platforms) > 0 {
+		newOptions.Platforms = platforms
+	}
+	return newOptions
+}
+
+func (o Options) WithRetries(retries *int) Options {
+	newOptions := o
+	if retries != nil {
+		newOptions.Retries = *retries
+	}
+	return newOptions
+}
+
+func (o Options) EnableCache() Options {
+	newOptions := o
+	newOptions.CacheEnabled = true
+	return newOptions
+}
+
+func (o Options) WithCacheRemoteURL(remoteURL string) Options {
+	newOptions := o
+	if remoteURL != "" {
+		newOptions.CacheRemoteURL = remoteURL
+	}
+	return newOptions
+}
+
+func (o Options) WithCachePort(port *int) Options {
+	newOptions := o
+	if port != nil {
+		newOptions.CachePort = *port
+	}
+	return newOptions
+}
+
+// CacheServiceName is the name of the service of the pull-through cache. The cache has its own
+// cluster ip service, so that it isn't exposed through the node port of the registry
+func (o Options) CacheServiceName() string {
+	return o.Name + "-cache"
+}
+
+// CacheHost is the host of the pull-through cache within the cluster
+func (o Options) CacheHost() string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local:%d", o.CacheServiceName(), o.Namespace, o.CachePort)
+}
+
+// CacheRegistry is the registry host that the pull-through cache mirrors, e.g. index.docker.io
+func (o Options) CacheRegistry() string {
+	host := o.CacheRemoteURL
+	if remoteURL, err := url.Parse(o.CacheRemoteURL); err == nil && remoteURL.Host != "" {
+		host = remoteURL.Host
+	}
+	if host == "registry-1.docker.io" {
+		return name.DefaultRegistry
+	}
+
+	registry, err := name.NewRegistry(host)
+	if err != nil {
+		return host
+	}
+
+	return registry.RegistryStr()
+}
+
+// TLSSecretName is the name of the secret that holds the certificate of the registry
+func (o Options) TLSSecretName() string {
+	return o.Name + "-tls"
+}
+
+// AuthSecretName is the name of the secret that holds the credentials of the registry
+func (o Options) AuthSecretName() string {
+	return o.Name + "-auth"
+}
+
+// NodeConfigName is the name of the daemon set that installs the certificate authority on the nodes
+func (o Options) NodeConfigName() string {
+	return o.Name + "-node-config"
+}
+
 func (o Options) EnableStorage() Options {
 	newOptions := o
 	newOptions.StorageEnabled = true
@@ -122,7 +247,12 @@ func (o Options) WithLocalRegistryConfig(config *latest.LocalRegistryConfig) Opt
 			WithImage(config.Image).
 			WithBuildKitImage(config.BuildKitImage).
 			WithPort(config.Port).
-			WithLocalBuild(config.LocalBuild)
+			WithServiceType(config.ServiceType).
+			WithLocalBuild(config.LocalBuild).
+			WithTLS(config.TLS).
+			WithAuth(config.Auth).
+			WithPlatforms(config.Platforms).
+			WithRetries(config.Retries)
 
 		if config.Persistence != nil && config.Persistence.Enabled != nil && *config.Persistence.Enabled {
 			newOptions = newOptions.
@@ -130,6 +260,13 @@ func (o Options) WithLocalRegistryConfig(config *latest.LocalRegistryConfig) Opt
 				WithStorageClassName(config.Persistence.StorageClassName).
 				WithStorageSize(config.Persistence.Size)
 		}
+
+		if config.Cache != nil && config.Cache.Enabled != nil && *config.Cache.Enabled {
+			newOptions = newOptions.
+				EnableCache().
+				WithCacheRemoteURL(config.Cache.RemoteURL).
+				WithCachePort(config.Cache.Port)
+		}
 	}
 	return newOptions
 }
@@ -0,0 +1,506 @@
+package localregistry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/pullsecrets"
+	"github.com/loft-sh/devspace/pkg/util/ptr"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// BuilderRegistryHost is the host the buildkit container pushes the images to
+	BuilderRegistryHost = "localhost:5000"
+
+	registryUsername = "devspace"
+
+	certsVolume        = "registry-certs"
+	certsMountPath     = "/certs"
+	authVolume         = "registry-auth"
+	authMountPath      = "/auth"
+	buildKitConfigPath = "/etc/buildkit"
+)
+
+// nodeConfigTimeout is the time the nodes have to install the certificate of the registry
+var nodeConfigTimeout = 2 * time.Minute
+
+// ensureTLSSecret creates the secret with the certificate of the registry, if it doesn't exist yet
+func (r *LocalRegistry) ensureTLSSecret(ctx devspacecontext.Context) error {
+	secrets := ctx.KubeClient().KubeClient().CoreV1().Secrets(r.Namespace)
+	secret, err := secrets.Get(ctx.Context(), r.TLSSecretName(), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		caCert, cert, key, err := generateCertificates(r.certificateHosts())
+		if err != nil {
+			return errors.Wrap(err, "generate certificate")
+		}
+
+		secret, err = secrets.Create(ctx.Context(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: r.TLSSecretName(),
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       cert,
+				corev1.TLSPrivateKeyKey: key,
+				"ca.crt":                caCert,
+				"buildkitd.toml":        []byte(fmt.Sprintf("[registry.%q]\n  ca = [%q]\n", BuilderRegistryHost, buildKitConfigPath+"/ca.crt")),
+			},
+		}, metav1.CreateOptions{})
+		if kerrors.IsAlreadyExists(err) {
+			secret, err = secrets.Get(ctx.Context(), r.TLSSecretName(), metav1.GetOptions{})
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	r.caCert = secret.Data["ca.crt"]
+	return nil
+}
+
+// ensureAuthSecret creates the secret with the credentials of the registry, if it doesn't exist yet
+func (r *LocalRegistry) ensureAuthSecret(ctx devspacecontext.Context) error {
+	secrets := ctx.KubeClient().KubeClient().CoreV1().Secrets(r.Namespace)
+	secret, err := secrets.Get(ctx.Context(), r.AuthSecretName(), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		password := make([]byte, 24)
+		_, err = rand.Read(password)
+		if err != nil {
+			return errors.Wrap(err, "generate password")
+		}
+
+		secret, err = secrets.Create(ctx.Context(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: r.AuthSecretName(),
+			},
+			Type: corev1.SecretTypeBasicAuth,
+			Data: map[string][]byte{
+				corev1.BasicAuthUsernameKey: []byte(registryUsername),
+				corev1.BasicAuthPasswordKey: []byte(hex.EncodeToString(password)),
+			},
+		}, metav1.CreateOptions{})
+		if kerrors.IsAlreadyExists(err) {
+			secret, err = secrets.Get(ctx.Context(), r.AuthSecretName(), metav1.GetOptions{})
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	r.username = string(secret.Data[corev1.BasicAuthUsernameKey])
+	r.password = string(secret.Data[corev1.BasicAuthPasswordKey])
+	return nil
+}
+
+// ensureNodeConfig deploys a daemon set that installs the certificate authority of the registry for
+// docker and containerd on every node, so that the nodes can pull from the registry, and waits until
+// all nodes were configured. The pods of the daemon set run as root and mount /etc/docker/certs.d and
+// /etc/containerd/certs.d of the nodes, so the namespace of the registry needs to admit privileged pods
+// and the user needs to be allowed to create daemon sets in it
+func (r *LocalRegistry) ensureNodeConfig(ctx devspacecontext.Context) error {
+	daemonSets := ctx.KubeClient().KubeClient().AppsV1().DaemonSets(r.Namespace)
+	desired := r.getNodeConfigDaemonSet()
+	existing, err := daemonSets.Get(ctx.Context(), desired.Name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = daemonSets.Create(ctx.Context(), desired, metav1.CreateOptions{})
+	} else if err == nil {
+		existing.Spec = desired.Spec
+		_, err = daemonSets.Update(ctx.Context(), existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	// images can only be pulled once the nodes trust the certificate
+	ctx.Log().Debug("Wait for the local registry certificate to be installed on the nodes...")
+	return wait.PollImmediateWithContext(ctx.Context(), time.Second, nodeConfigTimeout, func(ctx context.Context) (bool, error) {
+		daemonSet, err := daemonSets.Get(ctx, desired.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		status := daemonSet.Status
+		return status.ObservedGeneration >= daemonSet.Generation &&
+			status.UpdatedNumberScheduled == status.DesiredNumberScheduled &&
+			status.NumberAvailable == status.DesiredNumberScheduled, nil
+	})
+}
+
+// needsMigration checks if the pod spec of an existing registry doesn't match the tls and auth
+// settings, because the registry was started before they were changed
+func (r *LocalRegistry) needsMigration(spec corev1.PodSpec) bool {
+	hasTLS, hasAuth := false, false
+	for _, volume := range spec.Volumes {
+		switch volume.Name {
+		case certsVolume:
+			hasTLS = true
+		case authVolume:
+			hasAuth = true
+		}
+	}
+
+	return hasTLS != r.TLSEnabled || hasAuth != r.AuthEnabled
+}
+
+func (r *LocalRegistry) getNodeConfigDaemonSet() *appsv1.DaemonSet {
+	script := `set -e
+for folder in /host/docker /host/containerd; do
+  mkdir -p "$folder/$REGISTRY_HOST"
+  cp ` + certsMountPath + `/ca.crt "$folder/$REGISTRY_HOST/ca.crt"
+done
+printf 'server = "https://%s"\n\n[host."https://%s"]\n  ca = "/etc/containerd/certs.d/%s/ca.crt"\n' "$REGISTRY_HOST" "$REGISTRY_HOST" "$REGISTRY_HOST" > "/host/containerd/$REGISTRY_HOST/hosts.toml"
+while true; do sleep 3600; done`
+
+	hostPathType := corev1.HostPathDirectoryOrCreate
+	labels := map[string]string{
+		"app": r.NodeConfigName(),
+	}
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: r.NodeConfigName(),
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					EnableServiceLinks: new(bool),
+					Tolerations: []corev1.Toleration{
+						{
+							Operator: corev1.TolerationOpExists,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:    "node-config",
+							Image:   r.RegistryImage,
+							Command: []string{"sh", "-c", script},
+							Env: []corev1.EnvVar{
+								{
+									Name:  "REGISTRY_HOST",
+									Value: r.host,
+								},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								RunAsUser: ptr.Int64(0),
+							},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									Exec: &corev1.ExecAction{
+										Command: []string{"sh", "-c", `test -f "/host/containerd/$REGISTRY_HOST/hosts.toml"`},
+									},
+								},
+								PeriodSeconds: 1,
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      certsVolume,
+									MountPath: certsMountPath,
+									ReadOnly:  true,
+								},
+								{
+									Name:      "docker-certs",
+									MountPath: "/host/docker",
+								},
+								{
+									Name:      "containerd-certs",
+									MountPath: "/host/containerd",
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: certsVolume,
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: r.TLSSecretName(),
+								},
+							},
+						},
+						{
+							Name: "docker-certs",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/etc/docker/certs.d",
+									Type: &hostPathType,
+								},
+							},
+						},
+						{
+							Name: "containerd-certs",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/etc/containerd/certs.d",
+									Type: &hostPathType,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// securePodSpec configures the registry pod to serve over https and to require authentication
+func (r *LocalRegistry) securePodSpec(spec *corev1.PodSpec) {
+	if r.AuthEnabled {
+		spec.InitContainers = append(spec.InitContainers, corev1.Container{
+			Name:    "htpasswd",
+			Image:   HtpasswdImage,
+			Command: []string{"sh", "-c", `htpasswd -Bbn "$REGISTRY_USERNAME" "$REGISTRY_PASSWORD" > ` + authMountPath + `/htpasswd`},
+			Env: []corev1.EnvVar{
+				secretEnvVar("REGISTRY_USERNAME", r.AuthSecretName(), corev1.BasicAuthUsernameKey),
+				secretEnvVar("REGISTRY_PASSWORD", r.AuthSecretName(), corev1.BasicAuthPasswordKey),
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      authVolume,
+					MountPath: authMountPath,
+				},
+			},
+		})
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: authVolume,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+	if r.TLSEnabled {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: certsVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: r.TLSSecretName(),
+				},
+			},
+		})
+	}
+
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		switch container.Name {
+		case "registry":
+			if r.TLSEnabled {
+				container.Env = append(container.Env,
+					corev1.EnvVar{Name: "REGISTRY_HTTP_TLS_CERTIFICATE", Value: certsMountPath + "/" + corev1.TLSCertKey},
+					corev1.EnvVar{Name: "REGISTRY_HTTP_TLS_KEY", Value: certsMountPath + "/" + corev1.TLSPrivateKeyKey},
+				)
+				container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+					Name:      certsVolume,
+					MountPath: certsMountPath,
+					ReadOnly:  true,
+				})
+			}
+			if r.AuthEnabled {
+				container.Env = append(container.Env,
+					corev1.EnvVar{Name: "REGISTRY_AUTH", Value: "htpasswd"},
+					corev1.EnvVar{Name: "REGISTRY_AUTH_HTPASSWD_REALM", Value: "DevSpace Local Registry"},
+					corev1.EnvVar{Name: "REGISTRY_AUTH_HTPASSWD_PATH", Value: authMountPath + "/htpasswd"},
+				)
+				container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+					Name:      authVolume,
+					MountPath: authMountPath,
+					ReadOnly:  true,
+				})
+			}
+
+			// /v2/ requires authentication, while / is always served
+			for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe} {
+				if probe == nil || probe.HTTPGet == nil {
+					continue
+				}
+				if r.TLSEnabled {
+					probe.HTTPGet.Scheme = corev1.URISchemeHTTPS
+				}
+				if r.AuthEnabled {
+					probe.HTTPGet.Path = "/"
+				}
+			}
+		case BuildKitContainer:
+			if r.TLSEnabled {
+				container.Args = append(container.Args, "--config", buildKitConfigPath+"/buildkitd.toml")
+				container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+					Name:      certsVolume,
+					MountPath: buildKitConfigPath,
+					ReadOnly:  true,
+				})
+			}
+		}
+	}
+}
+
+// EnsurePullSecret creates an image pull secret for the registry in the namespace of the context and
+// adds it to the default service account, if the registry requires authentication
+func (r *LocalRegistry) EnsurePullSecret(ctx devspacecontext.Context) error {
+	if !r.AuthEnabled {
+		return nil
+	}
+
+	return pullsecrets.NewClient().EnsurePullSecretConfig(ctx, ctx.KubeClient().Namespace(), &latest.PullSecretConfig{
+		Registry: r.host,
+		Username: r.username,
+		Password: r.password,
+	})
+}
+
+// Credentials returns the username and password of the registry, which are empty
+// if the registry doesn't require authentication
+func (r *LocalRegistry) Credentials() (string, string) {
+	return r.username, r.password
+}
+
+// RemoteOptions returns the options to access the registry, which trust its certificate
+// authority and authenticate with the registry credentials or the keychain
+func (r *LocalRegistry) RemoteOptions() []remote.Option {
+	options := []remote.Option{}
+	if len(r.caCert) > 0 || r.tunnel != "" {
+		transport := remote.DefaultTransport.(*http.Transport).Clone()
+		if len(r.caCert) > 0 {
+			certPool := x509.NewCertPool()
+			certPool.AppendCertsFromPEM(r.caCert)
+			transport.TLSClientConfig = &tls.Config{RootCAs: certPool}
+		}
+
+		// connections to the registry host go through the port forwarding, the certificate
+		// is verified for localhost, because it doesn't contain the cluster ip
+		if r.tunnel != "" {
+			dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+			transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+				if address == r.host {
+					address = r.tunnel
+				}
+				return dialer.DialContext(ctx, network, address)
+			}
+			if transport.TLSClientConfig != nil {
+				transport.TLSClientConfig.ServerName = "localhost"
+			}
+		}
+
+		options = append(options, remote.WithTransport(transport))
+	}
+	if r.username != "" {
+		options = append(options, remote.WithAuth(&authn.Basic{
+			Username: r.username,
+			Password: r.password,
+		}))
+	} else {
+		options = append(options, remote.WithAuthFromKeychain(Keychain))
+	}
+
+	return options
+}
+
+// certificateHosts returns the hosts the registry is reachable with from the nodes, the builder and within the cluster
+func (r *LocalRegistry) certificateHosts() []string {
+	return []string{
+		"localhost",
+		"127.0.0.1",
+		r.Name,
+		r.Name + "." + r.Namespace,
+		r.Name + "." + r.Namespace + ".svc",
+		r.Name + "." + r.Namespace + ".svc.cluster.local",
+	}
+}
+
+// generateCertificates creates a certificate authority and a certificate for the given hosts that
+// is signed by it. All are returned PEM encoded
+func generateCertificates(hosts []string) ([]byte, []byte, []byte, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := notBefore.Add(10 * 365 * 24 * time.Hour)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "DevSpace Local Registry CA"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		nil
+}
+
+func secretEnvVar(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: secretName,
+				},
+				Key: key,
+			},
+		},
+	}
+}
@@ -0,0 +1,127 @@
+package localregistry
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	kubectltesting "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestGenerateCertificates(t *testing.T) {
+	registry := newLocalRegistry(NewDefaultOptions().WithNamespace("test"))
+	caCert, cert, key, err := generateCertificates(registry.certificateHosts())
+	assert.NilError(t, err)
+
+	block, _ := pem.Decode(key)
+	assert.Assert(t, block != nil)
+
+	roots := x509.NewCertPool()
+	assert.Assert(t, roots.AppendCertsFromPEM(caCert))
+
+	block, _ = pem.Decode(cert)
+	assert.Assert(t, block != nil)
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	assert.NilError(t, err)
+
+	for _, host := range []string{"localhost", "127.0.0.1", "registry.test.svc.cluster.local"} {
+		_, err = leaf.Verify(x509.VerifyOptions{
+			DNSName: host,
+			Roots:   roots,
+		})
+		assert.NilError(t, err, host)
+	}
+}
+
+func TestSecurePodSpec(t *testing.T) {
+	registry := newLocalRegistry(NewDefaultOptions().WithNamespace("test").WithTLS(true).WithAuth(true))
+	spec := registry.getDeployment().Spec.Template.Spec
+
+	assert.Equal(t, len(spec.InitContainers), 1)
+	assert.Equal(t, len(spec.Volumes), 4)
+
+	env := map[string]string{}
+	for _, container := range spec.Containers {
+		switch container.Name {
+		case "registry":
+			for _, envVar := range container.Env {
+				env[envVar.Name] = envVar.Value
+			}
+			assert.Equal(t, container.ReadinessProbe.HTTPGet.Scheme, corev1.URISchemeHTTPS)
+			assert.Equal(t, container.ReadinessProbe.HTTPGet.Path, "/")
+		case BuildKitContainer:
+			assert.DeepEqual(t, container.Args, []string{"--oci-worker-no-process-sandbox", "--config", "/etc/buildkit/buildkitd.toml"})
+		}
+	}
+	assert.Equal(t, env["REGISTRY_HTTP_TLS_CERTIFICATE"], "/certs/tls.crt")
+	assert.Equal(t, env["REGISTRY_AUTH"], "htpasswd")
+
+	// without tls and auth the pod spec stays untouched
+	registry = newLocalRegistry(NewDefaultOptions().WithNamespace("test"))
+	spec = registry.getDeployment().Spec.Template.Spec
+	assert.Equal(t, len(spec.InitContainers), 0)
+	assert.Equal(t, len(spec.Volumes), 2)
+}
+
+func TestNeedsMigration(t *testing.T) {
+	// registries started before tls or auth were enabled are migrated
+	spec := newLocalRegistry(NewDefaultOptions().WithNamespace("test")).getDeployment().Spec.Template.Spec
+	assert.Assert(t, !newLocalRegistry(NewDefaultOptions().WithNamespace("test")).needsMigration(spec))
+	assert.Assert(t, newLocalRegistry(NewDefaultOptions().WithNamespace("test").WithTLS(true)).needsMigration(spec))
+	assert.Assert(t, newLocalRegistry(NewDefaultOptions().WithNamespace("test").WithAuth(true)).needsMigration(spec))
+
+	// and so are registries that are started without them again
+	spec = newLocalRegistry(NewDefaultOptions().WithNamespace("test").WithTLS(true).WithAuth(true)).getStatefulSet().Spec.Template.Spec
+	assert.Assert(t, !newLocalRegistry(NewDefaultOptions().WithNamespace("test").WithTLS(true).WithAuth(true)).needsMigration(spec))
+	assert.Assert(t, newLocalRegistry(NewDefaultOptions().WithNamespace("test").WithTLS(true)).needsMigration(spec))
+}
+
+func TestEnsureNodeConfig(t *testing.T) {
+	defer func(timeout time.Duration) { nodeConfigTimeout = timeout }(nodeConfigTimeout)
+	nodeConfigTimeout = 5 * time.Second
+
+	// the daemon set configures one node after the other
+	client := fake.NewSimpleClientset()
+	gets := 0
+	client.PrependReactor("get", "daemonsets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		gets++
+		daemonSet := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry-node-config", Generation: 1},
+			Status: appsv1.DaemonSetStatus{
+				ObservedGeneration:     1,
+				DesiredNumberScheduled: 2,
+				UpdatedNumberScheduled: 2,
+				NumberAvailable:        int32(gets / 2),
+			},
+		}
+		if gets == 1 {
+			return true, nil, kerrors.NewNotFound(appsv1.Resource("daemonsets"), daemonSet.Name)
+		}
+		return true, daemonSet, nil
+	})
+
+	registry := newLocalRegistry(NewDefaultOptions().WithNamespace("test").WithTLS(true))
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithKubeClient(&kubectltesting.Client{Client: client})
+	err := registry.ensureNodeConfig(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, gets, 4)
+
+	// the daemon set runs privileged on every node and is only ready once the certificate was installed
+	daemonSet := registry.getNodeConfigDaemonSet()
+	container := daemonSet.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, *container.SecurityContext.RunAsUser, int64(0))
+	assert.Assert(t, container.ReadinessProbe != nil && container.ReadinessProbe.Exec != nil)
+	assert.Equal(t, daemonSet.Spec.Template.Spec.Tolerations[0].Operator, corev1.TolerationOpExists)
+}
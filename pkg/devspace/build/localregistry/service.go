@@ -13,15 +13,14 @@ import (
 	applyv1 "k8s.io/client-go/applyconfigurations/core/v1"
 )
 
-func (r *LocalRegistry) ensureService(ctx devspacecontext.Context) (*corev1.Service, error) {
+func (r *LocalRegistry) ensureService(ctx devspacecontext.Context, desired *corev1.Service) (*corev1.Service, error) {
 	// Create if it does not exist
 	var existing *corev1.Service
-	desired := r.getService()
 	kubeClient := ctx.KubeClient()
 	err := wait.PollImmediateWithContext(ctx.Context(), time.Second, 30*time.Second, func(ctx context.Context) (bool, error) {
 		var err error
 
-		existing, err = kubeClient.KubeClient().CoreV1().Services(r.Namespace).Get(ctx, r.Name, metav1.GetOptions{})
+		existing, err = kubeClient.KubeClient().CoreV1().Services(r.Namespace).Get(ctx, desired.Name, metav1.GetOptions{})
 		if err == nil {
 			return true, nil
 		}
@@ -80,7 +79,7 @@ func (r *LocalRegistry) getService() *corev1.Service {
 			Selector: map[string]string{
 				"app": r.Name,
 			},
-			Type: corev1.ServiceTypeNodePort,
+			Type: corev1.ServiceType(r.ServiceType),
 		},
 	}
 }
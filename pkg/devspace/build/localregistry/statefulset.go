@@ -5,6 +5,7 @@ import (
 	"time"
 
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -54,6 +55,17 @@ func (r *LocalRegistry) ensureStatefulset(ctx devspacecontext.Context) (*appsv1.
 		return nil, err
 	}
 
+	// Registries that were started with other tls or auth settings are updated explicitly,
+	// because the apply below only keeps the fields of the existing registry
+	if r.needsMigration(existing.Spec.Template.Spec) {
+		ctx.Log().Infof("Migrating local registry %s to the configured tls and auth settings", r.Name)
+		existing.Spec.Template.Spec = desired.Spec.Template.Spec
+		existing, err = ctx.KubeClient().KubeClient().AppsV1().StatefulSets(r.Namespace).Update(ctx.Context(), existing, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "migrate local registry")
+		}
+	}
+
 	// Use server side apply if it does exist
 	applyConfiguration, err := appsapplyv1.ExtractStatefulSet(existing, ApplyFieldManager)
 	if err != nil {
@@ -74,7 +86,7 @@ func (r *LocalRegistry) getStatefulSet() *appsv1.StatefulSet {
 	if r.StorageClassName != "" {
 		storageClassName = &r.StorageClassName
 	}
-	return &appsv1.StatefulSet{
+	statefulSet := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: r.Name,
 		},
@@ -126,4 +138,8 @@ func (r *LocalRegistry) getStatefulSet() *appsv1.StatefulSet {
 			},
 		},
 	}
+
+	r.securePodSpec(&statefulSet.Spec.Template.Spec)
+	r.cachePodSpec(&statefulSet.Spec.Template.Spec)
+	return statefulSet
 }
@@ -1,38 +1,87 @@
 package localregistry
 
 import (
+	"context"
 	"fmt"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"net/http"
 	"strings"
 
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/loft-sh/devspace/pkg/devspace/build/registry"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 )
 
-func HasPushPermission(image *latest.Image) bool {
-	ref, err := name.ParseReference(image.Image)
+// CheckPushPermission checks if the image can be pushed to its registry. If it can't, the
+// returned error explains why, e.g. missing credentials or an unreachable registry. An invalid
+// image name is returned as name.ErrBadName
+func CheckPushPermission(image *latest.Image, hosts registry.Hosts) (bool, error) {
+	ref, err := hosts.ParseReference(image.Image)
 	if err != nil {
-		panic(err)
+		return false, err
 	}
 
-	pushErr := remote.CheckPushPermission(ref, authn.DefaultKeychain, http.DefaultTransport)
-
+	transport := hosts.Transport(http.DefaultTransport)
+	pushErr := remote.CheckPushPermission(ref, Keychain, transport)
 	if isInsecureRegistry(pushErr) {
 		// Retry with insecure registry
-		ref, err := name.ParseReference(image.Image, name.Insecure)
+		ref, err = name.ParseReference(image.Image, name.Insecure)
 		if err != nil {
-			panic(err)
+			return false, err
 		}
 
-		pushErr = remote.CheckPushPermission(ref, authn.DefaultKeychain, http.DefaultTransport)
+		pushErr = remote.CheckPushPermission(ref, Keychain, transport)
+	}
+	if pushErr != nil {
+		return false, errors.Wrapf(pushErr, "push to %s", ref.Context().Name())
 	}
 
-	return pushErr == nil
+	return true, nil
+}
+
+// GetImageDigest returns the content digest of a pushed image, e.g. sha256:..., which can be used
+// to reference the image immutably. Without options, the credentials are resolved via the keychain
+// and the registry is reached as configured in hosts
+func GetImageDigest(ctx context.Context, image string, hosts registry.Hosts, options ...remote.Option) (string, error) {
+	ref, err := hosts.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	// the digest of the tag is resolved by the registry, because a mirror might still serve
+	// a previous image
+	if len(options) == 0 {
+		options = append(hosts.WithoutMirrors().RemoteOptions(), remote.WithAuthFromKeychain(Keychain))
+	}
+	options = append([]remote.Option{remote.WithContext(ctx)}, options...)
+
+	descriptor, err := remote.Head(ref, options...)
+	if isInsecureRegistry(err) {
+		// Retry with insecure registry
+		ref, err = name.ParseReference(image, name.Insecure)
+		if err != nil {
+			return "", err
+		}
+
+		descriptor, err = remote.Head(ref, options...)
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "resolve digest of %s", image)
+	}
+
+	return descriptor.Digest.String(), nil
+}
+
+// HasPushPermission returns true if the image can be pushed to its registry
+//
+// Deprecated: use CheckPushPermission, which returns why the image can't be pushed
+func HasPushPermission(image *latest.Image) bool {
+	allowed, _ := CheckPushPermission(image, nil)
+	return allowed
 }
 
 func IsLocalRegistryFallback(config *latest.Config) bool {
@@ -65,11 +114,19 @@ func UseLocalRegistry(client kubectl.Client, config *latest.Config, imageConfig
 			return false
 		} else if imageConfig.Custom != nil {
 			return false
+		} else if imageConfig.Plugin != nil {
+			return false
 		} else if imageConfig.BuildKit != nil && imageConfig.BuildKit.InCluster != nil {
 			return false
 		}
 	}
 
+	// providers load the images into the nodes of local clusters, so they are used without
+	// the local registry being enabled
+	if usesRegistryProvider(client, config) {
+		return true
+	}
+
 	// check if fallback
 	if IsLocalRegistryEnabled(config) {
 		return true
@@ -86,6 +143,24 @@ func UseLocalRegistry(client kubectl.Client, config *latest.Config, imageConfig
 	return !isLocalKubernetes && !(isVClusterContext && isLocalKubernetes)
 }
 
+// usesRegistryProvider returns true if a provider other than the registry in the cluster is
+// configured. With auto, the provider has to be found for the current context
+func usesRegistryProvider(client kubectl.Client, config *latest.Config) bool {
+	if config.LocalRegistry == nil {
+		return false
+	}
+
+	switch config.LocalRegistry.Provider {
+	case "", registry.ProviderRegistry:
+		return false
+	case registry.ProviderAuto:
+		provider, err := registry.NewRegistryProvider(client, registry.ProviderAuto)
+		return err == nil && provider != nil
+	}
+
+	return true
+}
+
 func IsImageAvailableInLocalRegistry(ctx devspacecontext.Context, registryPod *corev1.Pod, imageName string) (bool, error) {
 	ref, err := name.NewTag(imageName)
 	if err != nil {
@@ -1,8 +1,15 @@
 package localregistry
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/registry"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	kubectltesting "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
@@ -56,6 +63,56 @@ func TestUseLocalRegistry(t *testing.T) {
 			skipPush: true,
 			expected: false,
 		},
+		{
+			name: "KinD Cluster Kind Provider",
+			client: &kubectltesting.Client{
+				Context: "kind-kind",
+			},
+			config: &latest.Config{
+				LocalRegistry: &latest.LocalRegistryConfig{
+					Provider: "kind",
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "KinD Cluster Auto Provider",
+			client: &kubectltesting.Client{
+				Context: "kind-kind",
+			},
+			config: &latest.Config{
+				LocalRegistry: &latest.LocalRegistryConfig{
+					Enabled:  ptr.Bool(false),
+					Provider: "auto",
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "Minikube Cluster Registry Provider",
+			client: &kubectltesting.Client{
+				Context: "minikube",
+			},
+			config: &latest.Config{
+				LocalRegistry: &latest.LocalRegistryConfig{
+					Provider: "registry",
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "Remote Cluster Auto Provider Disabled",
+			client: &kubectltesting.Client{
+				Context: "gke_project_zone_cluster",
+			},
+			config: &latest.Config{
+				LocalRegistry: &latest.LocalRegistryConfig{
+					Enabled:  ptr.Bool(false),
+					Provider: "auto",
+				},
+			},
+			expected: false,
+		},
 		{
 			name: "KinD Cluster Local Registry Fallback",
 			client: &kubectltesting.Client{
@@ -366,3 +423,66 @@ func TestUseLocalRegistry(t *testing.T) {
 		assert.Equal(t, actual, testCase.expected, "Unexpected result in test case %s", testCase.name)
 	}
 }
+
+func TestCheckPushPermission(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	allowed, err := CheckPushPermission(&latest.Image{Image: "Invalid Image"}, nil)
+	assert.Assert(t, !allowed)
+	assert.Assert(t, name.IsErrBadName(err))
+
+	image := strings.TrimPrefix(server.URL, "http://") + "/app"
+	allowed, err = CheckPushPermission(&latest.Image{Image: image}, nil)
+	assert.Assert(t, !allowed)
+	assert.ErrorContains(t, err, "push to "+image)
+	assert.ErrorContains(t, err, "403")
+}
+
+func TestGetImageDigest(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		assert.Equal(t, r.Method, http.MethodHead)
+		assert.Equal(t, r.URL.Path, "/v2/app/manifests/latest")
+		w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+		w.Header().Set("Content-Length", "100")
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	image := strings.TrimPrefix(server.URL, "http://") + "/app:latest"
+	resolved, err := GetImageDigest(context.Background(), image, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, resolved, digest)
+
+	// a mirror with a stale tag is not asked
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+		w.Header().Set("Content-Length", "100")
+		w.Header().Set("Docker-Content-Digest", "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer mirror.Close()
+
+	hosts := registry.NewHosts(&latest.Config{
+		LocalRegistry: &latest.LocalRegistryConfig{
+			Registries: map[string]*latest.RegistryHostConfig{
+				strings.TrimPrefix(server.URL, "http://"): {
+					Mirrors:  []string{mirror.URL},
+					Insecure: true,
+				},
+			},
+		},
+	})
+	resolved, err = GetImageDigest(context.Background(), image, hosts)
+	assert.NilError(t, err)
+	assert.Equal(t, resolved, digest)
+}
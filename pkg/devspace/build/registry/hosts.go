@@ -0,0 +1,154 @@
+package registry
+
+import (
+	"crypto/tls"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+)
+
+// Hosts holds the mirrors and insecure flags of the registries, keyed by registry host
+type Hosts map[string]*latest.RegistryHostConfig
+
+// NewHosts returns the registry hosts configured in localRegistry.registries
+func NewHosts(config *latest.Config) Hosts {
+	if config == nil || config.LocalRegistry == nil || len(config.LocalRegistry.Registries) == 0 {
+		return nil
+	}
+
+	// normalize the hosts, so that e.g. docker.io matches index.docker.io
+	hosts := Hosts{}
+	for host, hostConfig := range config.LocalRegistry.Registries {
+		if hostConfig == nil {
+			continue
+		}
+
+		registry, err := name.NewRegistry(host)
+		if err != nil {
+			hosts[host] = hostConfig
+			continue
+		}
+
+		hosts[registry.RegistryStr()] = hostConfig
+	}
+
+	return hosts
+}
+
+// IsInsecure returns true if the registry host may be reached over plain http or with an untrusted certificate
+func (h Hosts) IsInsecure(host string) bool {
+	return h[host] != nil && h[host].Insecure
+}
+
+// WithoutMirrors returns the hosts with their insecure flags, but without mirrors. A mirror can serve
+// a stale tag, so operations that need the current state of a tag have to ask the registry itself
+func (h Hosts) WithoutMirrors() Hosts {
+	if len(h) == 0 {
+		return h
+	}
+
+	hosts := Hosts{}
+	for host, hostConfig := range h {
+		hosts[host] = &latest.RegistryHostConfig{Insecure: hostConfig.Insecure}
+	}
+	return hosts
+}
+
+// ParseReference parses the image and allows plain http if its registry is insecure
+func (h Hosts) ParseReference(image string) (name.Reference, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, err
+	} else if !h.IsInsecure(ref.Context().RegistryStr()) {
+		return ref, nil
+	}
+
+	return name.ParseReference(image, name.Insecure)
+}
+
+// Transport wraps the base transport, so that manifest and blob reads from a configured registry are
+// sent to its mirrors first and the certificates of insecure registries and their mirrors are not verified
+func (h Hosts) Transport(base http.RoundTripper) http.RoundTripper {
+	if len(h) == 0 {
+		return base
+	}
+
+	insecure := remote.DefaultTransport.(*http.Transport).Clone()
+	if transport, ok := base.(*http.Transport); ok {
+		insecure = transport.Clone()
+	}
+	insecure.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+	return &hostsTransport{
+		hosts:    h,
+		base:     base,
+		insecure: insecure,
+	}
+}
+
+// RemoteOptions returns the options that route the remote operations through the hosts transport
+func (h Hosts) RemoteOptions() []remote.Option {
+	return []remote.Option{remote.WithTransport(h.Transport(remote.DefaultTransport))}
+}
+
+// mirrorPathRegEx matches the manifest and blob reads a mirror can serve. The ping and the token
+// requests have to reach the registry, so that it can ask for credentials
+var mirrorPathRegEx = regexp.MustCompile(`^/v2/.+/(manifests|blobs)/[^/]+$`)
+
+type hostsTransport struct {
+	hosts    Hosts
+	base     http.RoundTripper
+	insecure http.RoundTripper
+}
+
+func (t *hostsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	hostConfig := t.hosts[req.URL.Host]
+	if hostConfig == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	transport := t.base
+	if hostConfig.Insecure {
+		transport = t.insecure
+	}
+
+	// only reads of manifests and blobs can be served by a mirror, if no mirror has the content the
+	// registry is asked
+	if isMirrorable(req) {
+		for _, mirror := range hostConfig.Mirrors {
+			mirrorReq := req.Clone(req.Context())
+			if i := strings.Index(mirror, "://"); i != -1 {
+				mirrorReq.URL.Scheme = mirror[:i]
+				mirror = mirror[i+3:]
+			}
+			mirrorReq.URL.Host = strings.TrimSuffix(mirror, "/")
+			mirrorReq.Host = mirrorReq.URL.Host
+
+			// the credentials of the registry must not be sent to another host
+			mirrorReq.Header.Del("Authorization")
+
+			resp, err := transport.RoundTrip(mirrorReq)
+			if err == nil && resp.StatusCode < http.StatusBadRequest {
+				return resp, nil
+			} else if err == nil {
+				_ = resp.Body.Close()
+			}
+		}
+	}
+
+	return transport.RoundTrip(req)
+}
+
+func isMirrorable(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	} else if req.Body != nil && req.Body != http.NoBody {
+		return false
+	}
+
+	return mirrorPathRegEx.MatchString(req.URL.Path)
+}
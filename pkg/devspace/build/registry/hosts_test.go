@@ -0,0 +1,113 @@
+package registry
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"gotest.tools/assert"
+)
+
+func TestHostsTransport(t *testing.T) {
+	newServer := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if body == "mirror" && strings.HasSuffix(r.URL.Path, "/missing") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			_, _ = w.Write([]byte(body + r.Header.Get("Authorization")))
+		}))
+	}
+	origin := newServer("origin")
+	defer origin.Close()
+	mirror := newServer("mirror")
+	defer mirror.Close()
+
+	originHost := strings.TrimPrefix(origin.URL, "http://")
+	hosts := NewHosts(&latest.Config{
+		LocalRegistry: &latest.LocalRegistryConfig{
+			Registries: map[string]*latest.RegistryHostConfig{
+				originHost: {
+					Mirrors:  []string{mirror.URL},
+					Insecure: true,
+				},
+			},
+		},
+	})
+	client := &http.Client{Transport: hosts.Transport(http.DefaultTransport)}
+	request := func(method, path string) string {
+		req, err := http.NewRequest(method, origin.URL+path, nil)
+		assert.NilError(t, err)
+		resp, err := client.Do(req)
+		assert.NilError(t, err)
+		defer resp.Body.Close()
+
+		out, err := io.ReadAll(resp.Body)
+		assert.NilError(t, err)
+		return string(out)
+	}
+
+	// reads are served by the mirror, unless it doesn't have the content
+	assert.Equal(t, request(http.MethodGet, "/v2/app/manifests/latest"), "mirror")
+	assert.Equal(t, request(http.MethodHead, "/v2/team/app/blobs/sha256:abc"), "")
+	assert.Equal(t, request(http.MethodGet, "/v2/team/app/blobs/sha256:abc"), "mirror")
+	assert.Equal(t, request(http.MethodGet, "/v2/app/manifests/missing"), "origin")
+	// the ping and the auth flow always go to the registry
+	assert.Equal(t, request(http.MethodGet, "/v2/"), "origin")
+	assert.Equal(t, request(http.MethodGet, "/token?scope=repository:app:pull"), "origin")
+	assert.Equal(t, request(http.MethodGet, "/v2/app/tags/list"), "origin")
+	// writes always go to the registry
+	assert.Equal(t, request(http.MethodPut, "/v2/app/manifests/latest"), "origin")
+
+	// the credentials of the registry are only sent to the registry
+	req, err := http.NewRequest(http.MethodGet, origin.URL+"/v2/app/manifests/latest", nil)
+	assert.NilError(t, err)
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	resp, err := client.Do(req)
+	assert.NilError(t, err)
+	out, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), "mirror")
+
+	req, err = http.NewRequest(http.MethodPut, origin.URL+"/v2/app/manifests/latest", nil)
+	assert.NilError(t, err)
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	resp, err = client.Do(req)
+	assert.NilError(t, err)
+	out, err = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), "originBasic dXNlcjpwYXNz")
+
+	// without mirrors everything goes to the registry
+	client = &http.Client{Transport: hosts.WithoutMirrors().Transport(http.DefaultTransport)}
+	assert.Equal(t, request(http.MethodGet, "/v2/app/manifests/latest"), "origin")
+
+	ref, err := hosts.ParseReference(originHost + "/app")
+	assert.NilError(t, err)
+	assert.Equal(t, ref.Context().Scheme(), "http")
+
+	ref, err = hosts.ParseReference("gcr.io/project/app")
+	assert.NilError(t, err)
+	assert.Equal(t, ref.Context().Scheme(), "https")
+}
+
+func TestNewHosts(t *testing.T) {
+	assert.Assert(t, NewHosts(&latest.Config{}) == nil)
+
+	hosts := NewHosts(&latest.Config{
+		LocalRegistry: &latest.LocalRegistryConfig{
+			Registries: map[string]*latest.RegistryHostConfig{
+				"docker.io": {Insecure: true},
+			},
+		},
+	})
+	assert.Assert(t, hosts.IsInsecure(name.DefaultRegistry))
+	assert.Assert(t, !hosts.IsInsecure("gcr.io"))
+}
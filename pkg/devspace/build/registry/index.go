@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// PlatformIndex is a multi-arch image index of images that were built for different platforms
+type PlatformIndex struct {
+	images   map[v1.Hash]v1.Image
+	manifest *v1.IndexManifest
+	raw      []byte
+}
+
+// NewPlatformIndex creates an index of the given images by their platform, e.g. linux/arm64
+func NewPlatformIndex(images map[string]v1.Image) (*PlatformIndex, error) {
+	index := &PlatformIndex{
+		images: map[v1.Hash]v1.Image{},
+		manifest: &v1.IndexManifest{
+			SchemaVersion: 2,
+			MediaType:     types.DockerManifestList,
+		},
+	}
+
+	for platform, image := range images {
+		parsedPlatform, err := v1.ParsePlatform(platform)
+		if err != nil {
+			return nil, err
+		}
+
+		mediaType, err := image.MediaType()
+		if err != nil {
+			return nil, err
+		}
+		digest, err := image.Digest()
+		if err != nil {
+			return nil, err
+		}
+		size, err := image.Size()
+		if err != nil {
+			return nil, err
+		}
+
+		index.images[digest] = image
+		index.manifest.Manifests = append(index.manifest.Manifests, v1.Descriptor{
+			MediaType: mediaType,
+			Size:      size,
+			Digest:    digest,
+			Platform:  parsedPlatform,
+		})
+	}
+
+	// keep the manifest and therefore its digest stable
+	sort.Slice(index.manifest.Manifests, func(a, b int) bool {
+		return index.manifest.Manifests[a].Platform.String() < index.manifest.Manifests[b].Platform.String()
+	})
+
+	raw, err := json.Marshal(index.manifest)
+	if err != nil {
+		return nil, err
+	}
+	index.raw = raw
+	return index, nil
+}
+
+// MediaType implements v1.ImageIndex
+func (i *PlatformIndex) MediaType() (types.MediaType, error) {
+	return i.manifest.MediaType, nil
+}
+
+// Digest implements v1.ImageIndex
+func (i *PlatformIndex) Digest() (v1.Hash, error) {
+	hash, _, err := v1.SHA256(bytes.NewReader(i.raw))
+	return hash, err
+}
+
+// Size implements v1.ImageIndex
+func (i *PlatformIndex) Size() (int64, error) {
+	return int64(len(i.raw)), nil
+}
+
+// IndexManifest implements v1.ImageIndex
+func (i *PlatformIndex) IndexManifest() (*v1.IndexManifest, error) {
+	return i.manifest.DeepCopy(), nil
+}
+
+// RawManifest implements v1.ImageIndex
+func (i *PlatformIndex) RawManifest() ([]byte, error) {
+	return i.raw, nil
+}
+
+// Image implements v1.ImageIndex
+func (i *PlatformIndex) Image(hash v1.Hash) (v1.Image, error) {
+	image, ok := i.images[hash]
+	if !ok {
+		return nil, fmt.Errorf("image %s not found in index", hash)
+	}
+
+	return image, nil
+}
+
+// ImageIndex implements v1.ImageIndex
+func (i *PlatformIndex) ImageIndex(hash v1.Hash) (v1.ImageIndex, error) {
+	return nil, fmt.Errorf("index %s not found in index", hash)
+}
@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"gotest.tools/assert"
+)
+
+type fakeImage struct {
+	v1.Image
+
+	digest v1.Hash
+}
+
+func (f *fakeImage) MediaType() (types.MediaType, error) {
+	return types.DockerManifestSchema2, nil
+}
+
+func (f *fakeImage) Digest() (v1.Hash, error) {
+	return f.digest, nil
+}
+
+func (f *fakeImage) Size() (int64, error) {
+	return 42, nil
+}
+
+func TestNewPlatformIndex(t *testing.T) {
+	amd64 := &fakeImage{digest: v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("a", 64)}}
+	arm64 := &fakeImage{digest: v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("b", 64)}}
+
+	index, err := NewPlatformIndex(map[string]v1.Image{
+		"linux/arm64": arm64,
+		"linux/amd64": amd64,
+	})
+	assert.NilError(t, err)
+
+	manifest, err := index.IndexManifest()
+	assert.NilError(t, err)
+	assert.Equal(t, manifest.MediaType, types.DockerManifestList)
+	assert.Equal(t, len(manifest.Manifests), 2)
+	assert.Equal(t, manifest.Manifests[0].Platform.String(), "linux/amd64")
+	assert.Equal(t, manifest.Manifests[0].Digest, amd64.digest)
+	assert.Equal(t, manifest.Manifests[1].Platform.String(), "linux/arm64")
+
+	image, err := index.Image(arm64.digest)
+	assert.NilError(t, err)
+	assert.Equal(t, image, v1.Image(arm64))
+
+	// the digest doesn't depend on the order of the platforms
+	other, err := NewPlatformIndex(map[string]v1.Image{
+		"linux/amd64": amd64,
+		"linux/arm64": arm64,
+	})
+	assert.NilError(t, err)
+	digest, err := index.Digest()
+	assert.NilError(t, err)
+	otherDigest, err := other.Digest()
+	assert.NilError(t, err)
+	assert.Equal(t, digest, otherDigest)
+}
@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"io"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	dockerclient "github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/loft-sh/utils/pkg/command"
+	"github.com/pkg/errors"
+)
+
+type kindProvider struct {
+	cluster string
+}
+
+// NewKindProvider creates a provider that loads the images into the nodes of the kind cluster
+func NewKindProvider(cluster string) RegistryProvider {
+	return &kindProvider{
+		cluster: cluster,
+	}
+}
+
+func (k *kindProvider) Name() string {
+	return ProviderKind
+}
+
+// RewriteImage keeps the image, because the nodes find it under its original name
+func (k *kindProvider) RewriteImage(image string) (string, error) {
+	return Repository(image)
+}
+
+func (k *kindProvider) Load(ctx devspacecontext.Context, client dockerclient.Client, writer io.Writer, tags []string) error {
+	for _, tag := range tags {
+		err := command.Command(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), writer, writer, nil, "kind", "load", "docker-image", "--name", k.cluster, tag)
+		if err != nil {
+			return errors.Wrapf(err, "load image %s into kind cluster %s", tag, k.cluster)
+		}
+	}
+
+	return nil
+}
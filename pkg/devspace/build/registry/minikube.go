@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"io"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	dockerclient "github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/loft-sh/utils/pkg/command"
+	"github.com/pkg/errors"
+)
+
+type minikubeProvider struct {
+	profile string
+}
+
+// NewMinikubeProvider creates a provider that loads the images into the nodes of the minikube profile
+func NewMinikubeProvider(profile string) RegistryProvider {
+	return &minikubeProvider{
+		profile: profile,
+	}
+}
+
+func (m *minikubeProvider) Name() string {
+	return ProviderMinikube
+}
+
+// RewriteImage keeps the image, because the nodes find it under its original name
+func (m *minikubeProvider) RewriteImage(image string) (string, error) {
+	return Repository(image)
+}
+
+func (m *minikubeProvider) Load(ctx devspacecontext.Context, client dockerclient.Client, writer io.Writer, tags []string) error {
+	for _, tag := range tags {
+		err := command.Command(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), writer, writer, nil, "minikube", "image", "load", "--profile", m.profile, tag)
+		if err != nil {
+			return errors.Wrapf(err, "load image %s into minikube profile %s", tag, m.profile)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package registry
+
+import (
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	dockerclient "github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ProviderRegistry deploys a registry into the cluster, this is the default
+	ProviderRegistry = "registry"
+	// ProviderAuto selects the provider by the type of the cluster and falls back to the registry
+	ProviderAuto = "auto"
+	// ProviderKind loads the images into the nodes with kind load docker-image
+	ProviderKind = "kind"
+	// ProviderMinikube loads the images into the nodes with minikube image load
+	ProviderMinikube = "minikube"
+	// ProviderTTLSh pushes the images to the ephemeral public registry ttl.sh
+	ProviderTTLSh = "ttl.sh"
+)
+
+// RegistryProvider makes images that were built with the local docker daemon available to the
+// cluster, if they can't be pushed to their own registry
+type RegistryProvider interface {
+	// Name returns the name of the provider
+	Name() string
+
+	// RewriteImage returns the repository the cluster pulls the image from
+	RewriteImage(image string) (string, error)
+
+	// Load makes the images with the given tags available to the cluster
+	Load(ctx devspacecontext.Context, client dockerclient.Client, writer io.Writer, tags []string) error
+}
+
+// NewRegistryProvider returns the provider with the given name. If the images should be pushed to
+// a registry in the cluster, nil is returned
+func NewRegistryProvider(kubeClient kubectl.Client, provider string) (RegistryProvider, error) {
+	switch provider {
+	case "", ProviderRegistry:
+		return nil, nil
+	case ProviderAuto:
+		if cluster := kubectl.GetKindContext(kubeClient.CurrentContext()); cluster != "" {
+			return NewKindProvider(cluster), nil
+		} else if kubectl.IsMinikubeKubernetes(kubeClient) {
+			return NewMinikubeProvider(kubeClient.CurrentContext()), nil
+		}
+
+		return nil, nil
+	case ProviderKind:
+		cluster := kubectl.GetKindContext(kubeClient.CurrentContext())
+		if cluster == "" {
+			return nil, errors.Errorf("local registry provider %s requires a kind context, but the current context is %s", ProviderKind, kubeClient.CurrentContext())
+		}
+
+		return NewKindProvider(cluster), nil
+	case ProviderMinikube:
+		if !kubectl.IsMinikubeKubernetes(kubeClient) {
+			return nil, errors.Errorf("local registry provider %s requires a minikube context, but the current context is %s", ProviderMinikube, kubeClient.CurrentContext())
+		}
+
+		return NewMinikubeProvider(kubeClient.CurrentContext()), nil
+	case ProviderTTLSh:
+		return NewTTLShProvider(), nil
+	}
+
+	return nil, errors.Errorf("unknown local registry provider %s, expected one of %s, %s, %s, %s or %s", provider, ProviderRegistry, ProviderAuto, ProviderKind, ProviderMinikube, ProviderTTLSh)
+}
+
+// Repository returns the repository of the image without the tag
+func Repository(image string) (string, error) {
+	tag, err := name.NewTag(image)
+	if err != nil {
+		return "", err
+	}
+
+	return tag.Repository.Name(), nil
+}
@@ -0,0 +1,94 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	kubectltesting "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"gotest.tools/assert"
+)
+
+type newRegistryProviderTestCase struct {
+	name        string
+	context     string
+	provider    string
+	expected    string
+	expectedErr string
+}
+
+func TestNewRegistryProvider(t *testing.T) {
+	testCases := []newRegistryProviderTestCase{
+		{
+			name:     "Default",
+			context:  "kind-dev",
+			provider: "",
+		},
+		{
+			name:     "Auto kind",
+			context:  "kind-dev",
+			provider: ProviderAuto,
+			expected: ProviderKind,
+		},
+		{
+			name:     "Auto minikube",
+			context:  "minikube",
+			provider: ProviderAuto,
+			expected: ProviderMinikube,
+		},
+		{
+			name:     "Auto remote cluster",
+			context:  "gke_project_europe-west1_cluster",
+			provider: ProviderAuto,
+		},
+		{
+			name:        "Kind on remote cluster",
+			context:     "gke_project_europe-west1_cluster",
+			provider:    ProviderKind,
+			expectedErr: "requires a kind context",
+		},
+		{
+			name:     "ttl.sh",
+			context:  "gke_project_europe-west1_cluster",
+			provider: ProviderTTLSh,
+			expected: ProviderTTLSh,
+		},
+		{
+			name:        "Unknown",
+			context:     "kind-dev",
+			provider:    "harbor",
+			expectedErr: "unknown local registry provider harbor",
+		},
+	}
+
+	for _, testCase := range testCases {
+		provider, err := NewRegistryProvider(&kubectltesting.Client{Context: testCase.context}, testCase.provider)
+		if testCase.expectedErr != "" {
+			assert.ErrorContains(t, err, testCase.expectedErr, testCase.name)
+			continue
+		}
+
+		assert.NilError(t, err, testCase.name)
+		if testCase.expected == "" {
+			assert.Assert(t, provider == nil, testCase.name)
+		} else {
+			assert.Equal(t, provider.Name(), testCase.expected, testCase.name)
+		}
+	}
+}
+
+func TestRewriteImage(t *testing.T) {
+	image, err := NewKindProvider("dev").RewriteImage("backend")
+	assert.NilError(t, err)
+	assert.Equal(t, image, "index.docker.io/library/backend")
+
+	provider := NewTTLShProvider()
+	image, err = provider.RewriteImage("gcr.io/project/backend:latest")
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(image, "ttl.sh/"), image)
+	assert.Assert(t, strings.HasSuffix(image, "-backend"), image)
+
+	// the prefix is the same for all images of a run
+	other, err := NewTTLShProvider().RewriteImage("backend")
+	assert.NilError(t, err)
+	assert.Equal(t, other, image)
+}
@@ -0,0 +1,186 @@
+package registry
+
+import (
+	"context"
+	"io"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// maxBurst is the maximum number of bytes a limited push sends at once
+const maxBurst = 32 * 1024
+
+type pushSchedulerKey struct{}
+
+// PushScheduler limits the number of images that are pushed at the same time and the bandwidth
+// all pushes share, so that pushes don't saturate slow connections
+type PushScheduler struct {
+	slots   chan struct{}
+	limiter *rate.Limiter
+}
+
+// NewPushScheduler creates a scheduler that allows concurrency pushes at the same time and limits their
+// combined bandwidth, e.g. 5Mi for 5 MiB/s. A concurrency of 0 and an empty bandwidth don't limit pushes
+func NewPushScheduler(concurrency int, bandwidth string) (*PushScheduler, error) {
+	scheduler := &PushScheduler{}
+	if concurrency > 0 {
+		scheduler.slots = make(chan struct{}, concurrency)
+	}
+	if bandwidth != "" {
+		quantity, err := resource.ParseQuantity(bandwidth)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse push bandwidth %s", bandwidth)
+		} else if quantity.Value() <= 0 {
+			return nil, errors.Errorf("push bandwidth %s has to be greater than 0", bandwidth)
+		}
+
+		burst := maxBurst
+		if quantity.Value() < maxBurst {
+			burst = int(quantity.Value())
+		}
+		scheduler.limiter = rate.NewLimiter(rate.Limit(quantity.Value()), burst)
+	}
+
+	return scheduler, nil
+}
+
+// WithPushScheduler returns a copy of the context in which pushes are limited by the scheduler
+func WithPushScheduler(parent context.Context, scheduler *PushScheduler) context.Context {
+	return context.WithValue(parent, pushSchedulerKey{}, scheduler)
+}
+
+// PushSchedulerFrom returns the scheduler of the context or nil, which doesn't limit pushes
+func PushSchedulerFrom(ctx context.Context) *PushScheduler {
+	scheduler, _ := ctx.Value(pushSchedulerKey{}).(*PushScheduler)
+	return scheduler
+}
+
+// Acquire waits until the image may be pushed. The returned function has to be called after the push
+func (s *PushScheduler) Acquire(ctx context.Context) (func(), error) {
+	if s == nil || s.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		return func() { <-s.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Limit returns the image or index with layers that are uploaded no faster than the bandwidth of the scheduler
+func (s *PushScheduler) Limit(ctx context.Context, taggable remote.Taggable) remote.Taggable {
+	if s == nil || s.limiter == nil {
+		return taggable
+	}
+
+	switch t := taggable.(type) {
+	case v1.Image:
+		return &limitedImage{Image: t, ctx: ctx, limiter: s.limiter}
+	case v1.ImageIndex:
+		return &limitedIndex{index: t, ctx: ctx, limiter: s.limiter}
+	}
+
+	return taggable
+}
+
+type limitedImage struct {
+	v1.Image
+
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (i *limitedImage) Layers() ([]v1.Layer, error) {
+	layers, err := i.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	limitedLayers := make([]v1.Layer, 0, len(layers))
+	for _, layer := range layers {
+		limitedLayers = append(limitedLayers, &limitedLayer{Layer: layer, ctx: i.ctx, limiter: i.limiter})
+	}
+
+	return limitedLayers, nil
+}
+
+func (i *limitedImage) LayerByDigest(hash v1.Hash) (v1.Layer, error) {
+	layer, err := i.Image.LayerByDigest(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &limitedLayer{Layer: layer, ctx: i.ctx, limiter: i.limiter}, nil
+}
+
+// index is embedded under another name, as the interface has a method called ImageIndex
+type index = v1.ImageIndex
+
+type limitedIndex struct {
+	index
+
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (i *limitedIndex) Image(hash v1.Hash) (v1.Image, error) {
+	image, err := i.index.Image(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &limitedImage{Image: image, ctx: i.ctx, limiter: i.limiter}, nil
+}
+
+func (i *limitedIndex) ImageIndex(hash v1.Hash) (v1.ImageIndex, error) {
+	child, err := i.index.ImageIndex(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &limitedIndex{index: child, ctx: i.ctx, limiter: i.limiter}, nil
+}
+
+type limitedLayer struct {
+	v1.Layer
+
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (l *limitedLayer) Compressed() (io.ReadCloser, error) {
+	reader, err := l.Layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+
+	return &limitedReader{ReadCloser: reader, ctx: l.ctx, limiter: l.limiter}, nil
+}
+
+type limitedReader struct {
+	io.ReadCloser
+
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.limiter.Burst() {
+		p = p[:r.limiter.Burst()]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+
+	return n, err
+}
@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestPushSchedulerAcquire(t *testing.T) {
+	scheduler, err := NewPushScheduler(1, "")
+	assert.NilError(t, err)
+
+	release, err := scheduler.Acquire(context.TODO())
+	assert.NilError(t, err)
+
+	// the second push has to wait until the first one is done
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	_, err = scheduler.Acquire(ctx)
+	assert.Error(t, err, context.DeadlineExceeded.Error())
+
+	release()
+	release, err = scheduler.Acquire(context.TODO())
+	assert.NilError(t, err)
+	release()
+
+	// no scheduler doesn't limit pushes
+	var noScheduler *PushScheduler
+	release, err = noScheduler.Acquire(context.TODO())
+	assert.NilError(t, err)
+	release()
+}
+
+func TestNewPushScheduler(t *testing.T) {
+	_, err := NewPushScheduler(0, "fast")
+	assert.ErrorContains(t, err, "parse push bandwidth fast")
+
+	_, err = NewPushScheduler(0, "0")
+	assert.Error(t, err, "push bandwidth 0 has to be greater than 0")
+
+	scheduler, err := NewPushScheduler(0, "1Ki")
+	assert.NilError(t, err)
+	assert.Equal(t, scheduler.limiter.Burst(), 1024)
+}
+
+func TestLimitedLayer(t *testing.T) {
+	scheduler, err := NewPushScheduler(0, "10Ki")
+	assert.NilError(t, err)
+
+	content := strings.Repeat("a", 15*1024)
+	layer := &limitedLayer{Layer: &fakeLayer{content: content}, ctx: context.TODO(), limiter: scheduler.limiter}
+	reader, err := layer.Compressed()
+	assert.NilError(t, err)
+
+	// the first 10Ki are sent at once, the remaining 5Ki take about half a second
+	start := time.Now()
+	read, err := io.ReadAll(reader)
+	assert.NilError(t, err)
+	assert.Equal(t, string(read), content)
+	assert.Assert(t, time.Since(start) >= 400*time.Millisecond, "upload was not limited")
+}
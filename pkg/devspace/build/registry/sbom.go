@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+)
+
+const (
+	// attestationReferenceType is the annotation value of the attestation manifests BuildKit adds to the image index
+	attestationReferenceType = "attestation-manifest"
+
+	// spdxPredicateType is the predicate type of in-toto statements that contain an SPDX document
+	spdxPredicateType = "https://spdx.dev/Document"
+)
+
+// GetImageSBOM returns the SPDX document of the SBOM attestation BuildKit attached to the pushed image. For multi-arch
+// images the SBOM of the first platform with an attestation is returned
+func GetImageSBOM(ctx context.Context, image string, hosts Hosts, options ...remote.Option) ([]byte, error) {
+	ref, err := hosts.ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := remote.Index(ref, append([]remote.Option{remote.WithContext(ctx)}, options...)...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get image index of %s", image)
+	}
+
+	return findSBOM(index)
+}
+
+// findSBOM searches the attestation manifests of the index for an in-toto statement with an SPDX document
+func findSBOM(index v1.ImageIndex) ([]byte, error) {
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, descriptor := range indexManifest.Manifests {
+		if descriptor.Annotations["vnd.docker.reference.type"] != attestationReferenceType {
+			continue
+		}
+
+		attestation, err := index.Image(descriptor.Digest)
+		if err != nil {
+			return nil, err
+		}
+		manifest, err := attestation.Manifest()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, layerDescriptor := range manifest.Layers {
+			if layerDescriptor.Annotations["in-toto.io/predicate-type"] != spdxPredicateType {
+				continue
+			}
+
+			layer, err := attestation.LayerByDigest(layerDescriptor.Digest)
+			if err != nil {
+				return nil, err
+			}
+
+			// in-toto statements are stored uncompressed, so the blob is the statement itself
+			reader, err := layer.Compressed()
+			if err != nil {
+				return nil, err
+			}
+			out, err := io.ReadAll(reader)
+			_ = reader.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			statement := struct {
+				Predicate json.RawMessage `json:"predicate"`
+			}{}
+			err = json.Unmarshal(out, &statement)
+			if err != nil {
+				return nil, errors.Wrap(err, "parse sbom attestation")
+			}
+
+			return statement.Predicate, nil
+		}
+	}
+
+	return nil, fmt.Errorf("image has no sbom attestation")
+}
@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"gotest.tools/assert"
+)
+
+// imageIndex is embedded under another name, as the interface has a method called ImageIndex
+type imageIndex = v1.ImageIndex
+
+type fakeIndex struct {
+	imageIndex
+
+	manifest *v1.IndexManifest
+	images   map[v1.Hash]v1.Image
+}
+
+func (f *fakeIndex) IndexManifest() (*v1.IndexManifest, error) {
+	return f.manifest, nil
+}
+
+func (f *fakeIndex) Image(hash v1.Hash) (v1.Image, error) {
+	return f.images[hash], nil
+}
+
+type fakeAttestation struct {
+	v1.Image
+
+	manifest *v1.Manifest
+	layers   map[v1.Hash]v1.Layer
+}
+
+func (f *fakeAttestation) Manifest() (*v1.Manifest, error) {
+	return f.manifest, nil
+}
+
+func (f *fakeAttestation) LayerByDigest(hash v1.Hash) (v1.Layer, error) {
+	return f.layers[hash], nil
+}
+
+type fakeLayer struct {
+	v1.Layer
+
+	content string
+}
+
+func (f *fakeLayer) Compressed() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader([]byte(f.content))), nil
+}
+
+func TestFindSBOM(t *testing.T) {
+	attestationDigest := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("a", 64)}
+	provenanceDigest := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("b", 64)}
+	sbomDigest := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("c", 64)}
+
+	index := &fakeIndex{
+		manifest: &v1.IndexManifest{
+			Manifests: []v1.Descriptor{
+				{
+					Digest: v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("d", 64)},
+				},
+				{
+					Digest: attestationDigest,
+					Annotations: map[string]string{
+						"vnd.docker.reference.type": attestationReferenceType,
+					},
+				},
+			},
+		},
+		images: map[v1.Hash]v1.Image{
+			attestationDigest: &fakeAttestation{
+				manifest: &v1.Manifest{
+					Layers: []v1.Descriptor{
+						{
+							Digest: provenanceDigest,
+							Annotations: map[string]string{
+								"in-toto.io/predicate-type": "https://slsa.dev/provenance/v0.2",
+							},
+						},
+						{
+							Digest: sbomDigest,
+							Annotations: map[string]string{
+								"in-toto.io/predicate-type": spdxPredicateType,
+							},
+						},
+					},
+				},
+				layers: map[v1.Hash]v1.Layer{
+					provenanceDigest: &fakeLayer{content: `{"predicate":{"builder":{}}}`},
+					sbomDigest:       &fakeLayer{content: `{"_type":"https://in-toto.io/Statement/v0.1","predicate":{"spdxVersion":"SPDX-2.3"}}`},
+				},
+			},
+		},
+	}
+
+	sbom, err := findSBOM(index)
+	assert.NilError(t, err)
+	assert.Equal(t, string(sbom), `{"spdxVersion":"SPDX-2.3"}`)
+
+	// images without attestations have no sbom
+	_, err = findSBOM(&fakeIndex{manifest: &v1.IndexManifest{}})
+	assert.ErrorContains(t, err, "image has no sbom attestation")
+}
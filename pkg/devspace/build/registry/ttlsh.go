@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	dockerclient "github.com/loft-sh/devspace/pkg/devspace/docker"
+	"github.com/pkg/errors"
+)
+
+// TTLShRegistry is the host of the ephemeral registry
+var TTLShRegistry = "ttl.sh"
+
+var (
+	ttlShPrefix     string
+	ttlShPrefixOnce sync.Once
+)
+
+type ttlShProvider struct {
+	prefix string
+}
+
+// NewTTLShProvider creates a provider that pushes the images to ttl.sh. As the registry is public,
+// the images are pushed under a random prefix. ttl.sh deletes images after the duration in their
+// tag, which is 24 hours for tags that are no duration
+func NewTTLShProvider() RegistryProvider {
+	ttlShPrefixOnce.Do(func() {
+		prefix := make([]byte, 8)
+		_, _ = rand.Read(prefix)
+		ttlShPrefix = hex.EncodeToString(prefix)
+	})
+
+	return &ttlShProvider{
+		prefix: ttlShPrefix,
+	}
+}
+
+func (t *ttlShProvider) Name() string {
+	return ProviderTTLSh
+}
+
+func (t *ttlShProvider) RewriteImage(image string) (string, error) {
+	repo, err := Repository(image)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s-%s", TTLShRegistry, t.prefix, path.Base(repo)), nil
+}
+
+func (t *ttlShProvider) Load(ctx devspacecontext.Context, client dockerclient.Client, writer io.Writer, tags []string) error {
+	scheduler := PushSchedulerFrom(ctx.Context())
+	release, err := scheduler.Acquire(ctx.Context())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	for _, tag := range tags {
+		localRef, err := name.NewTag(tag)
+		if err != nil {
+			return err
+		}
+
+		repo, err := t.RewriteImage(tag)
+		if err != nil {
+			return err
+		}
+		remoteRef, err := name.NewTag(repo + ":" + localRef.TagStr())
+		if err != nil {
+			return err
+		}
+
+		image, err := daemon.Image(localRef, daemon.WithContext(ctx.Context()), daemon.WithClient(client.DockerAPIClient()))
+		if err != nil {
+			return errors.Wrapf(err, "read image %s", tag)
+		}
+
+		_, _ = fmt.Fprintf(writer, "Pushing %s\n", remoteRef.String())
+		err = remote.Write(remoteRef, scheduler.Limit(ctx.Context(), image).(v1.Image), remote.WithContext(ctx.Context()))
+		if err != nil {
+			return errors.Wrapf(err, "push image %s", remoteRef.String())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package scan
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// grype scans images with https://github.com/anchore/grype
+type grype struct{}
+
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+}
+
+func (g *grype) Name() string {
+	return ScannerGrype
+}
+
+func (g *grype) Args(image string) []string {
+	return []string{image, "--output", "json", "--quiet"}
+}
+
+func (g *grype) Parse(report []byte) ([]Vulnerability, error) {
+	parsed := &grypeReport{}
+	err := json.Unmarshal(report, parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	vulnerabilities := []Vulnerability{}
+	for _, match := range parsed.Matches {
+		vulnerabilities = append(vulnerabilities, Vulnerability{
+			ID:       match.Vulnerability.ID,
+			Package:  match.Artifact.Name,
+			Version:  match.Artifact.Version,
+			Severity: strings.ToUpper(match.Vulnerability.Severity),
+		})
+	}
+
+	return vulnerabilities, nil
+}
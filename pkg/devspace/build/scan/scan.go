@@ -0,0 +1,130 @@
+package scan
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/utils/pkg/command"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ScannerTrivy = "trivy"
+	ScannerGrype = "grype"
+)
+
+// Severities of vulnerabilities, ordered from least to most severe
+const (
+	SeverityUnknown = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+var severityNames = []string{"unknown", "low", "medium", "high", "critical"}
+
+var severities = map[string]int{
+	"LOW":      SeverityLow,
+	"MEDIUM":   SeverityMedium,
+	"HIGH":     SeverityHigh,
+	"CRITICAL": SeverityCritical,
+}
+
+// Vulnerability is a vulnerability the scanner found in a package of the image
+type Vulnerability struct {
+	ID       string
+	Package  string
+	Version  string
+	Severity string
+}
+
+// Scanner scans images for vulnerabilities
+type Scanner interface {
+	// Name returns the name of the scanner
+	Name() string
+
+	// Args returns the arguments to scan the image with the scanner cli, which prints a json report
+	Args(image string) []string
+
+	// Parse parses the json report of the scanner
+	Parse(report []byte) ([]Vulnerability, error)
+}
+
+// NewScanner returns the scanner with the given name, trivy is used by default
+func NewScanner(name string) (Scanner, error) {
+	switch name {
+	case "", ScannerTrivy:
+		return &trivy{}, nil
+	case ScannerGrype:
+		return &grype{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown image scanner %s, please use one of %v", name, []string{ScannerTrivy, ScannerGrype})
+}
+
+// ParseSeverity returns the severity of the given name, e.g. high. Unknown names return SeverityUnknown
+func ParseSeverity(name string) int {
+	return severities[strings.ToUpper(name)]
+}
+
+// Gate scans the image and returns an error if it has vulnerabilities with at least the failOn severity. Vulnerabilities
+// with at least the warnOn severity are logged. If neither is configured, the gate fails on high vulnerabilities
+func Gate(ctx devspacecontext.Context, config *latest.ImageScan, image string) error {
+	scanner, err := NewScanner(config.Scanner)
+	if err != nil {
+		return err
+	}
+
+	ctx.Log().Infof("Scan image %s with %s...", image, scanner.Name())
+	stdout := &bytes.Buffer{}
+	stderr := ctx.Log().Writer(logrus.DebugLevel, false)
+	defer stderr.Close()
+
+	args := append(scanner.Args(image), config.Args...)
+	err = command.Command(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), stdout, stderr, nil, scanner.Name(), args...)
+	if err != nil {
+		return errors.Wrapf(err, "scan image %s", image)
+	}
+
+	vulnerabilities, err := scanner.Parse(stdout.Bytes())
+	if err != nil {
+		return errors.Wrapf(err, "parse %s report", scanner.Name())
+	}
+
+	return evaluate(ctx, config, image, vulnerabilities)
+}
+
+func evaluate(ctx devspacecontext.Context, config *latest.ImageScan, image string, vulnerabilities []Vulnerability) error {
+	failOn, warnOn := ParseSeverity(config.FailOn), ParseSeverity(config.WarnOn)
+	if config.FailOn == "" && config.WarnOn == "" {
+		failOn = SeverityHigh
+	}
+
+	// list the most severe vulnerabilities first
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		return ParseSeverity(vulnerabilities[i].Severity) > ParseSeverity(vulnerabilities[j].Severity)
+	})
+
+	failed := 0
+	for _, vulnerability := range vulnerabilities {
+		severity := ParseSeverity(vulnerability.Severity)
+		if failOn != SeverityUnknown && severity >= failOn {
+			ctx.Log().Errorf("%s %s in %s %s", vulnerability.Severity, vulnerability.ID, vulnerability.Package, vulnerability.Version)
+			failed++
+		} else if warnOn != SeverityUnknown && severity >= warnOn {
+			ctx.Log().Warnf("%s %s in %s %s", vulnerability.Severity, vulnerability.ID, vulnerability.Package, vulnerability.Version)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("image %s has %d vulnerabilities with severity %s or higher", image, failed, severityNames[failOn])
+	}
+
+	ctx.Log().Donef("Image %s passed the vulnerability scan", image)
+	return nil
+}
@@ -0,0 +1,66 @@
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestParse(t *testing.T) {
+	vulnerabilities, err := (&trivy{}).Parse([]byte(`{"Results":[{"Vulnerabilities":[{"VulnerabilityID":"CVE-2023-0001","PkgName":"openssl","InstalledVersion":"3.0.1","Severity":"HIGH"}]},{}]}`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, vulnerabilities, []Vulnerability{{ID: "CVE-2023-0001", Package: "openssl", Version: "3.0.1", Severity: "HIGH"}})
+
+	vulnerabilities, err = (&grype{}).Parse([]byte(`{"matches":[{"vulnerability":{"id":"CVE-2023-0002","severity":"Critical"},"artifact":{"name":"zlib","version":"1.2.11"}}]}`))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, vulnerabilities, []Vulnerability{{ID: "CVE-2023-0002", Package: "zlib", Version: "1.2.11", Severity: "CRITICAL"}})
+}
+
+type evaluateTestCase struct {
+	name        string
+	config      *latest.ImageScan
+	expectedErr string
+}
+
+func TestEvaluate(t *testing.T) {
+	vulnerabilities := []Vulnerability{
+		{ID: "CVE-2023-0001", Severity: "MEDIUM"},
+		{ID: "CVE-2023-0002", Severity: "HIGH"},
+		{ID: "CVE-2023-0003", Severity: "UNKNOWN"},
+	}
+
+	testCases := []evaluateTestCase{
+		{
+			name:        "Fail on high by default",
+			config:      &latest.ImageScan{},
+			expectedErr: "image app:latest has 1 vulnerabilities with severity high or higher",
+		},
+		{
+			name:        "Fail on medium",
+			config:      &latest.ImageScan{FailOn: "medium"},
+			expectedErr: "image app:latest has 2 vulnerabilities with severity medium or higher",
+		},
+		{
+			name:   "Fail on critical",
+			config: &latest.ImageScan{FailOn: "critical", WarnOn: "low"},
+		},
+		{
+			name:   "Only warn",
+			config: &latest.ImageScan{WarnOn: "medium"},
+		},
+	}
+
+	ctx := devspacecontext.NewContext(context.TODO(), nil, log.Discard)
+	for _, testCase := range testCases {
+		err := evaluate(ctx, testCase.config, "app:latest", vulnerabilities)
+		if testCase.expectedErr != "" {
+			assert.Error(t, err, testCase.expectedErr, testCase.name)
+		} else {
+			assert.NilError(t, err, testCase.name)
+		}
+	}
+}
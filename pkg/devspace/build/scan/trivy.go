@@ -0,0 +1,47 @@
+package scan
+
+import "encoding/json"
+
+// trivy scans images with https://github.com/aquasecurity/trivy
+type trivy struct{}
+
+type trivyReport struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			Severity         string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+func (t *trivy) Name() string {
+	return ScannerTrivy
+}
+
+func (t *trivy) Args(image string) []string {
+	return []string{"image", "--format", "json", "--quiet", image}
+}
+
+func (t *trivy) Parse(report []byte) ([]Vulnerability, error) {
+	parsed := &trivyReport{}
+	err := json.Unmarshal(report, parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	vulnerabilities := []Vulnerability{}
+	for _, result := range parsed.Results {
+		for _, vulnerability := range result.Vulnerabilities {
+			vulnerabilities = append(vulnerabilities, Vulnerability{
+				ID:       vulnerability.VulnerabilityID,
+				Package:  vulnerability.PkgName,
+				Version:  vulnerability.InstalledVersion,
+				Severity: vulnerability.Severity,
+			})
+		}
+	}
+
+	return vulnerabilities, nil
+}
@@ -0,0 +1,93 @@
+package build
+
+import (
+	"context"
+	"sync"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/build/types"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+)
+
+type sharedBuildsKey struct{}
+
+// WithSharedBuilds returns a copy of the context in which images with the same content are only built
+// once. The dependencies of a pipeline inherit the context, so identical images of different dependencies
+// reuse the tag of the first build instead of being rebuilt
+func WithSharedBuilds(parent context.Context) context.Context {
+	return context.WithValue(parent, sharedBuildsKey{}, &sharedBuilds{
+		builds: map[string]*sharedBuild{},
+	})
+}
+
+func sharedBuildsFrom(ctx context.Context) *sharedBuilds {
+	builds, _ := ctx.Value(sharedBuildsKey{}).(*sharedBuilds)
+	return builds
+}
+
+// sharedBuilds holds the image builds of a run by the content hash of the image
+type sharedBuilds struct {
+	m      sync.Mutex
+	builds map[string]*sharedBuild
+}
+
+type sharedBuild struct {
+	done  chan struct{}
+	image types.ImageNameTag
+	err   error
+}
+
+// claim returns the build for the given content hash. If there is no build yet, a new build is
+// created and owner is true. The owner has to finish the build
+func (s *sharedBuilds) claim(contentHash string) (build *sharedBuild, owner bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	build, ok := s.builds[contentHash]
+	if ok {
+		return build, false
+	}
+
+	build = &sharedBuild{done: make(chan struct{})}
+	s.builds[contentHash] = build
+	return build, true
+}
+
+// finish stores the result of the build and releases everyone waiting for it at once. A failed build
+// stays in place, so that all images with the same content fail with the error of the build instead
+// of building the same content again one after another
+func (s *sharedBuilds) finish(build *sharedBuild, image types.ImageNameTag, err error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	build.image = image
+	build.err = err
+	close(build.done)
+}
+
+// wait waits for the build to finish and returns the built image
+func (b *sharedBuild) wait(ctx context.Context) (types.ImageNameTag, error) {
+	select {
+	case <-b.done:
+		return b.image, b.err
+	case <-ctx.Done():
+		return types.ImageNameTag{}, ctx.Err()
+	}
+}
+
+// imageContentHash returns the content hash of the image or an empty string if builds of the
+// image can't be shared
+func imageContentHash(ctx devspacecontext.Context, imageConf *latest.Image, imageTags []string) string {
+	if imageConf.Custom != nil {
+		return ""
+	}
+
+	contentHash, err := helper.NewBuildHelper(ctx, "", imageConf, imageTags).ContentHash()
+	if err != nil {
+		ctx.Log().Debugf("Error hashing image %s, build is not shared: %v", imageConf.Name, err)
+		return ""
+	}
+
+	return contentHash
+}
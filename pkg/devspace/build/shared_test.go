@@ -0,0 +1,51 @@
+package build
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build/types"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+)
+
+func TestSharedBuilds(t *testing.T) {
+	ctx := WithSharedBuilds(context.Background())
+	builds := sharedBuildsFrom(ctx)
+	assert.Assert(t, builds != nil)
+	assert.Assert(t, sharedBuildsFrom(context.Background()) == nil)
+
+	// the first image with the content builds it
+	build, owner := builds.claim("hash")
+	assert.Assert(t, owner)
+
+	// images with the same content wait for the build
+	waiting, owner := builds.claim("hash")
+	assert.Assert(t, !owner)
+	assert.Assert(t, waiting == build)
+
+	image := types.ImageNameTag{ImageConfigName: "backend", ImageName: "backend", ImageTag: "abcdefg"}
+	go builds.finish(build, image, nil)
+	built, err := waiting.wait(ctx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, built, image)
+
+	// a failed build fails all images with the same content with the error of the build
+	build, owner = builds.claim("other")
+	assert.Assert(t, owner)
+	waiters := []*sharedBuild{}
+	for i := 0; i < 3; i++ {
+		waiting, owner := builds.claim("other")
+		assert.Assert(t, !owner)
+		waiters = append(waiters, waiting)
+	}
+	builds.finish(build, types.ImageNameTag{}, errors.New("build failed"))
+	for _, waiting := range waiters {
+		_, err = waiting.wait(ctx)
+		assert.Error(t, err, "build failed")
+	}
+	later, owner := builds.claim("other")
+	assert.Assert(t, !owner)
+	_, err = later.wait(ctx)
+	assert.Error(t, err, "build failed")
+}
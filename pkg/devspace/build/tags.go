@@ -0,0 +1,153 @@
+package build
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	runtimevar "github.com/loft-sh/devspace/pkg/devspace/config/loader/variable/runtime"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/git"
+	"github.com/loft-sh/devspace/pkg/util/randutil"
+	"github.com/pkg/errors"
+)
+
+// tagVariableRegEx matches the variables in image tags, e.g. ${GIT_COMMIT_SHORT}
+var tagVariableRegEx = regexp.MustCompile(`\$\{([A-Za-z0-9_]+)\}`)
+
+// invalidTagCharRegEx matches the characters that are not allowed in image tags
+var invalidTagCharRegEx = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// semverTagRegEx matches tags that are semantic versions, e.g. v1.2.3
+var semverTagRegEx = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)$`)
+
+// maxTagLength is the maximum length of an image tag
+const maxTagLength = 128
+
+// tagVariables are the variables that can be used in image tags
+var tagVariables = map[string]func(ctx devspacecontext.Context, imageConfigName string, now time.Time) (string, error){
+	"GIT_COMMIT": func(ctx devspacecontext.Context, imageConfigName string, now time.Time) (string, error) {
+		return git.GetHash(ctx.Context(), ctx.WorkingDir())
+	},
+	"GIT_COMMIT_SHORT": func(ctx devspacecontext.Context, imageConfigName string, now time.Time) (string, error) {
+		hash, err := git.GetHash(ctx.Context(), ctx.WorkingDir())
+		if err != nil {
+			return "", err
+		} else if len(hash) > 7 {
+			hash = hash[:7]
+		}
+
+		return hash, nil
+	},
+	"GIT_BRANCH": func(ctx devspacecontext.Context, imageConfigName string, now time.Time) (string, error) {
+		return git.GetBranch(ctx.WorkingDir())
+	},
+	"TIMESTAMP": func(ctx devspacecontext.Context, imageConfigName string, now time.Time) (string, error) {
+		return strconv.FormatInt(now.Unix(), 10), nil
+	},
+	"DATE": func(ctx devspacecontext.Context, imageConfigName string, now time.Time) (string, error) {
+		return now.UTC().Format("20060102"), nil
+	},
+	"DATETIME": func(ctx devspacecontext.Context, imageConfigName string, now time.Time) (string, error) {
+		return now.UTC().Format("20060102-150405"), nil
+	},
+	"SEMVER_BUMP": func(ctx devspacecontext.Context, imageConfigName string, now time.Time) (string, error) {
+		imageCache, _ := ctx.Config().LocalCache().GetImageCache(imageConfigName)
+		return bumpVersion(imageCache.Tags), nil
+	},
+}
+
+// resolveTags replaces the variables and the # in the image tags. The tags are not resolved while loading
+// the config, so config and runtime variables are resolved first. The remaining variables are tag variables
+// or read from the environment
+func resolveTags(ctx devspacecontext.Context, imageConfigName string, tags []string) ([]string, error) {
+	now := time.Now()
+	values := map[string]string{}
+	resolvedTags := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		resolvedTag := tag
+		if ctx.Config() != nil {
+			var err error
+			resolvedTag, err = runtimevar.NewRuntimeResolver(ctx.WorkingDir(), false).FillRuntimeVariablesAsString(ctx.Context(), tag, ctx.Config(), ctx.Dependencies())
+			if err != nil {
+				return nil, errors.Wrapf(err, "images.%s.tags %s", imageConfigName, tag)
+			}
+		}
+
+		var resolveErr error
+		resolvedTag = tagVariableRegEx.ReplaceAllStringFunc(resolvedTag, func(match string) string {
+			name := tagVariableRegEx.FindStringSubmatch(match)[1]
+			if value, ok := values[name]; ok {
+				return value
+			}
+
+			variable := ctx.Environ().Get(name)
+			value := variable.String()
+			if resolveVariable, ok := tagVariables[name]; ok {
+				var err error
+				value, err = resolveVariable(ctx, imageConfigName, now)
+				if err != nil && resolveErr == nil {
+					resolveErr = errors.Wrapf(err, "resolve %s", name)
+				}
+			} else if !variable.IsSet() && resolveErr == nil {
+				resolveErr = fmt.Errorf("unknown variable %s, please use an environment variable or one of %v", name, tagVariableNames())
+			}
+
+			values[name] = value
+			return value
+		})
+		if resolveErr != nil {
+			return nil, errors.Wrapf(resolveErr, "images.%s.tags %s", imageConfigName, tag)
+		}
+
+		// replace the # in the tags
+		for strings.Contains(resolvedTag, "#") {
+			resolvedTag = strings.Replace(resolvedTag, "#", randutil.GenerateRandomString(1), 1)
+		}
+
+		resolvedTag = sanitizeTag(resolvedTag)
+		if resolvedTag == "" {
+			return nil, fmt.Errorf("images.%s.tags %s resolves to an empty tag", imageConfigName, tag)
+		}
+
+		resolvedTags = append(resolvedTags, resolvedTag)
+	}
+
+	return resolvedTags, nil
+}
+
+// sanitizeTag replaces the characters that are not allowed in image tags, e.g. the / of a git branch
+func sanitizeTag(tag string) string {
+	tag = strings.TrimLeft(invalidTagCharRegEx.ReplaceAllString(tag, "-"), ".-")
+	if len(tag) > maxTagLength {
+		tag = tag[:maxTagLength]
+	}
+
+	return tag
+}
+
+// bumpVersion returns the next patch version of the highest semantic version in the tags or 0.0.1
+func bumpVersion(tags []string) string {
+	prefix, major, minor, patch := "", 0, 0, 0
+	for _, tag := range tags {
+		match := semverTagRegEx.FindStringSubmatch(tag)
+		if match == nil {
+			continue
+		}
+
+		tagMajor, _ := strconv.Atoi(match[2])
+		tagMinor, _ := strconv.Atoi(match[3])
+		tagPatch, _ := strconv.Atoi(match[4])
+		if tagMajor > major || (tagMajor == major && tagMinor > minor) || (tagMajor == major && tagMinor == minor && tagPatch > patch) {
+			prefix, major, minor, patch = match[1], tagMajor, tagMinor, tagPatch
+		}
+	}
+
+	return fmt.Sprintf("%s%d.%d.%d", prefix, major, minor, patch+1)
+}
+
+func tagVariableNames() []string {
+	return []string{"GIT_COMMIT", "GIT_COMMIT_SHORT", "GIT_BRANCH", "TIMESTAMP", "DATE", "DATETIME", "SEMVER_BUMP"}
+}
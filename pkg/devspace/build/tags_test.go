@@ -0,0 +1,91 @@
+package build
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+	"mvdan.cc/sh/v3/expand"
+)
+
+type resolveTagsTestCase struct {
+	name string
+
+	tags       []string
+	cachedTags []string
+
+	expectedTags []string
+	expectedErr  string
+}
+
+func TestResolveTags(t *testing.T) {
+	testCases := []resolveTagsTestCase{
+		{
+			name:         "Environment variables",
+			tags:         []string{"dev-${USER}", "${USER}"},
+			expectedTags: []string{"dev-john.doe", "john.doe"},
+		},
+		{
+			name:         "Config variables",
+			tags:         []string{"v${VERSION}", "${VERSION}-${USER}"},
+			expectedTags: []string{"v1.2.0", "1.2.0-john.doe"},
+		},
+		{
+			name:         "Invalid characters",
+			tags:         []string{"${BRANCH}"},
+			expectedTags: []string{"feature-login"},
+		},
+		{
+			name:         "First version",
+			tags:         []string{"${SEMVER_BUMP}", "latest"},
+			expectedTags: []string{"0.0.1", "latest"},
+		},
+		{
+			name:         "Bump version",
+			tags:         []string{"${SEMVER_BUMP}"},
+			cachedTags:   []string{"abcdefg", "v1.2.3", "v1.10.0"},
+			expectedTags: []string{"v1.10.1"},
+		},
+		{
+			name:        "Unknown variable",
+			tags:        []string{"${UNKNOWN}"},
+			expectedErr: "images.test.tags ${UNKNOWN}: unknown variable UNKNOWN, please use an environment variable or one of [GIT_COMMIT GIT_COMMIT_SHORT GIT_BRANCH TIMESTAMP DATE DATETIME SEMVER_BUMP]",
+		},
+		{
+			name:        "Empty tag",
+			tags:        []string{"${EMPTY}."},
+			expectedErr: "images.test.tags ${EMPTY}. resolves to an empty tag",
+		},
+	}
+
+	for _, testCase := range testCases {
+		cache := localcache.New("")
+		cache.SetImageCache("test", localcache.ImageCache{Tags: testCase.cachedTags})
+		ctx := devspacecontext.NewContext(context.TODO(), nil, log.Discard).
+			WithConfig(config.NewConfig(nil, nil, latest.NewRaw(), cache, &remotecache.RemoteCache{}, map[string]interface{}{"VERSION": "1.2.0"}, "")).
+			WithEnviron(expand.ListEnviron("USER=john.doe", "BRANCH=feature/login", "EMPTY="))
+
+		tags, err := resolveTags(ctx, "test", testCase.tags)
+		if testCase.expectedErr != "" {
+			assert.Error(t, err, testCase.expectedErr, testCase.name)
+		} else {
+			assert.NilError(t, err, testCase.name)
+		}
+		assert.DeepEqual(t, tags, testCase.expectedTags)
+	}
+}
+
+func TestResolveTagsRandom(t *testing.T) {
+	ctx := devspacecontext.NewContext(context.TODO(), nil, log.Discard)
+	tags, err := resolveTags(ctx, "test", []string{"dev-####"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(tags), 1)
+	assert.Equal(t, len(tags[0]), len("dev-####"))
+	assert.Assert(t, tags[0] != "dev-####")
+}
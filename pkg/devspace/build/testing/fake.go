@@ -16,7 +16,7 @@ type FakeController struct {
 func NewFakeController(config *latest.Config) build.Controller {
 	builtImages := map[string]string{}
 	for _, image := range config.Images {
-		if image != nil && image.Docker == nil && image.Kaniko == nil && image.BuildKit == nil && image.Custom == nil {
+		if image != nil && image.Docker == nil && image.Kaniko == nil && image.BuildKit == nil && image.Custom == nil && image.Plugin == nil {
 			continue
 		}
 
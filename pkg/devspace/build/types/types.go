@@ -5,4 +5,6 @@ type ImageNameTag struct {
 	ImageName              string
 	LocalRegistryImageName string
 	ImageTag               string
+	ImageTags              []string
+	ImageDigest            string
 }
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"path/filepath"
+	"sync"
+
 	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
 	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
-	"path/filepath"
 )
 
 type Config interface {
@@ -13,6 +15,9 @@ type Config interface {
 	// Config returns the parsed config
 	Config() *latest.Config
 
+	// SetConfig replaces the parsed config, e.g. after plugin hooks mutated it
+	SetConfig(parsed *latest.Config)
+
 	// Raw returns the config as it was loaded from the devspace.yaml
 	// including all sections
 	Raw() map[string]interface{}
@@ -88,6 +93,7 @@ type config struct {
 	rawConfig           map[string]interface{}
 	rawBeforeConversion map[string]interface{}
 	parsedConfig        *latest.Config
+	parsedConfigLock    sync.RWMutex
 	localCache          localcache.Cache
 	remoteCache         remotecache.Cache
 	resolvedVariables   map[string]interface{}
@@ -103,9 +109,19 @@ func (c *config) RemoteCache() remotecache.Cache {
 }
 
 func (c *config) Config() *latest.Config {
+	c.parsedConfigLock.RLock()
+	defer c.parsedConfigLock.RUnlock()
+
 	return c.parsedConfig
 }
 
+func (c *config) SetConfig(parsed *latest.Config) {
+	c.parsedConfigLock.Lock()
+	defer c.parsedConfigLock.Unlock()
+
+	c.parsedConfig = parsed
+}
+
 func (c *config) Raw() map[string]interface{} {
 	return c.rawConfig
 }
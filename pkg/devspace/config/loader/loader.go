@@ -139,7 +139,12 @@ func (l *configLoader) LoadWithParser(ctx context.Context, localCache localcache
 	}
 
 	// load the raw data
-	data, err := l.LoadRaw()
+	var data map[string]interface{}
+	if len(options.RawConfig) > 0 {
+		data, err = copyRaw(options.RawConfig)
+	} else {
+		data, err = l.LoadRaw()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -336,6 +341,12 @@ func (l *configLoader) parseConfig(
 		return nil, nil, nil, err
 	}
 
+	// apply the overrides
+	copiedRawConfig, err = applySet(copiedRawConfig, options.Set)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	// reload variables to make sure they are loaded correctly
 	err = reloadVariables(resolver, copiedRawConfig, log)
 	if err != nil {
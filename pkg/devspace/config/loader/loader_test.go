@@ -142,6 +142,7 @@ type loadTestCase struct {
 	options           ConfigOptions
 	returnedGenerated localcache.LocalCache
 	files             map[string]interface{}
+	env               map[string]string
 	withProfile       bool
 
 	expectedConfig *latest.Config
@@ -172,6 +173,28 @@ func TestLoad(t *testing.T) {
 				Dev:     latest.NewRaw().Dev,
 			},
 		},
+		{
+			name:    "Tag variables are resolved when the image is built",
+			options: ConfigOptions{},
+			env:     map[string]string{"GIT_COMMIT_SHORT": "abcdefg"},
+			files: map[string]interface{}{
+				"devspace.yaml": latest.Config{
+					Version: latest.Version,
+					Name:    "devspace",
+					Images: map[string]*latest.Image{
+						"api": {Image: "registry/api", Tags: []string{"dev-${GIT_COMMIT_SHORT}", "${SEMVER_BUMP}"}},
+					},
+				},
+			},
+			expectedConfig: &latest.Config{
+				Version: latest.Version,
+				Name:    "devspace",
+				Images: map[string]*latest.Image{
+					"api": {Name: "api", Image: "registry/api", Tags: []string{"dev-${GIT_COMMIT_SHORT}", "${SEMVER_BUMP}"}},
+				},
+				Dev: latest.NewRaw().Dev,
+			},
+		},
 		{
 			name:    "Get from default file without profile",
 			options: ConfigOptions{},
@@ -219,6 +242,9 @@ func testLoad(testCase *loadTestCase, t *testing.T) {
 			os.Remove(path)
 		}
 	}()
+	for name, value := range testCase.env {
+		t.Setenv(name, value)
+	}
 	for path, data := range testCase.files {
 		dataAsYaml, err := yaml.Marshal(data)
 		assert.NilError(t, err, "Error parsing data of file %s in testCase %s", path, testCase.name)
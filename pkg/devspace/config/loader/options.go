@@ -19,6 +19,14 @@ type ConfigOptions struct {
 	DisableProfileActivation bool
 
 	Vars []string
+
+	// Set overrides config values after the profiles were applied. The keys are
+	// config paths such as deployments.api.helm.values.replicas
+	Set map[string]interface{}
+
+	// RawConfig is loaded instead of the config file, e.g. for dependencies that
+	// don't have a devspace.yaml
+	RawConfig map[string]interface{}
 }
 
 func (co *ConfigOptions) Clone() (*ConfigOptions, error) {
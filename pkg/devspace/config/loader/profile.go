@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 
 	jsonpatch "github.com/evanphx/json-patch/v5"
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader/patch"
@@ -220,6 +221,35 @@ func ApplyPatches(data map[string]interface{}, profile *latest.ProfileConfig) (m
 	return ApplyPatchesOnObject(data, profile.Patches)
 }
 
+// applySet replaces the values at the given config paths, the paths are applied in alphabetical order
+func applySet(data map[string]interface{}, set map[string]interface{}) (map[string]interface{}, error) {
+	if len(set) == 0 {
+		return data, nil
+	}
+
+	paths := []string{}
+	for path := range set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	patches := []*latest.PatchConfig{}
+	for _, path := range paths {
+		patches = append(patches, &latest.PatchConfig{
+			Operation: "replace",
+			Path:      path,
+			Value:     set[path],
+		})
+	}
+
+	newConfig, err := ApplyPatchesOnObject(data, patches)
+	if err != nil {
+		return nil, errors.Wrap(err, "set")
+	}
+
+	return newConfig, nil
+}
+
 func ApplyPatchesOnObject(data map[string]interface{}, configPatches []*latest.PatchConfig) (map[string]interface{}, error) {
 	out, err := yaml.Marshal(data)
 	if err != nil {
@@ -222,3 +222,43 @@ func TestPatches(t *testing.T) {
 		}
 	}
 }
+
+func TestApplySet(t *testing.T) {
+	in := map[string]interface{}{
+		"deployments": map[string]interface{}{
+			"api": map[string]interface{}{
+				"helm": map[string]interface{}{
+					"values": map[string]interface{}{
+						"replicas": 1,
+					},
+				},
+			},
+		},
+	}
+
+	out, err := applySet(in, map[string]interface{}{
+		"deployments.api.helm.values.replicas": 2,
+		"deployments.api.helm.values.color":    "blue",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"deployments": map[string]interface{}{
+			"api": map[string]interface{}{
+				"helm": map[string]interface{}{
+					"values": map[string]interface{}{
+						"replicas": 2,
+						"color":    "blue",
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(out, expected) {
+		outYaml, _ := yaml.Marshal(out)
+		expectedYaml, _ := yaml.Marshal(expected)
+		t.Fatalf("Unexpected result:\n%s\nexpected:\n%s", outYaml, expectedYaml)
+	}
+}
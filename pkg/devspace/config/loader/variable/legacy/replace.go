@@ -147,7 +147,8 @@ func resolveImage(value string, config config2.Config, dependencies []types.Depe
 			return true, shouldRedeploy, imageCache.Tag, nil
 		}
 
-		return true, shouldRedeploy, imageCache.ResolveImage() + ":" + imageCache.Tag, nil
+		// pin the image to its digest, so that rollouts are reproducible
+		return true, shouldRedeploy, imageCache.ResolveImageWithDigest(), nil
 	}
 
 	// config images
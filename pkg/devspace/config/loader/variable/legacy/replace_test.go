@@ -111,6 +111,37 @@ func TestReplaceContainerNames(t *testing.T) {
 				"": "myimage:someTag",
 			},
 		},
+		{
+			name: "Image in cache pinned to digest",
+			overwriteValues: map[string]interface{}{
+				"": "myimage",
+			},
+			imagesConf: map[string]*latest.Image{
+				"test": {
+					Image: "myimage",
+				},
+			},
+			cache: &localcache.LocalCache{
+				Images: map[string]localcache.ImageCache{
+					"test": {
+						ImageName: "myimage",
+						Tag:       "someTag",
+						Digest:    "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+					},
+				},
+			},
+			builtImages: map[string]buildtypes.ImageNameTag{
+				"test": {
+					ImageName:   "myimage",
+					ImageTag:    "someTag",
+					ImageDigest: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				},
+			},
+			expectedShouldRedeploy: true,
+			expectedOverwriteValues: map[string]interface{}{
+				"": "myimage:someTag@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			},
+		},
 		{
 			name: "Replace image & tag helpers",
 			overwriteValues: map[string]interface{}{
@@ -0,0 +1,45 @@
+package runtime
+
+import (
+	"context"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/pkg/errors"
+)
+
+// OutputsPrefix is the prefix of the runtime variables and cache entries that hold the outputs of a config
+const OutputsPrefix = "outputs."
+
+// ResolveOutputs resolves the outputs of the given dependency after its pipeline has run and
+// sets them as runtime variables, so that the parent config can use them via
+// ${runtime.dependencies.NAME.outputs.KEY}. The outputs are also stored in the local cache
+// of the dependency, which allows using them in later runs that skip the dependency
+func ResolveOutputs(ctx context.Context, dependency types.Dependency) error {
+	c := dependency.Config()
+	if c == nil || c.Config() == nil || len(c.Config().Outputs) == 0 {
+		return nil
+	}
+
+	resolver := NewRuntimeResolver(dependency.Path(), false)
+	for key, value := range c.Config().Outputs {
+		out, err := resolver.FillRuntimeVariablesAsString(ctx, value, c, dependency.Children())
+		if err != nil {
+			return errors.Wrapf(err, "resolve output %s of dependency %s", key, dependency.Name())
+		}
+
+		c.SetRuntimeVariable(OutputsPrefix+key, out)
+		c.LocalCache().SetData(OutputsPrefix+key, out)
+	}
+
+	return c.LocalCache().Save()
+}
+
+// cachedOutput returns the output of a previous run from the local cache of the config
+func cachedOutput(c config.Config, name string) (string, bool) {
+	if c.LocalCache() == nil {
+		return "", false
+	}
+
+	return c.LocalCache().GetData(name)
+}
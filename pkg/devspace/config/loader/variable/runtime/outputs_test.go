@@ -0,0 +1,69 @@
+package runtime
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"gotest.tools/assert"
+)
+
+type fakeDependency struct {
+	name   string
+	path   string
+	config config.Config
+}
+
+func (f *fakeDependency) Name() string                               { return f.name }
+func (f *fakeDependency) Config() config.Config                      { return f.config }
+func (f *fakeDependency) KubeClient() kubectl.Client                 { return nil }
+func (f *fakeDependency) Children() []types.Dependency               { return nil }
+func (f *fakeDependency) Root() bool                                 { return false }
+func (f *fakeDependency) Path() string                               { return f.path }
+func (f *fakeDependency) DependencyConfig() *latest.DependencyConfig { return nil }
+
+func TestResolveOutputs(t *testing.T) {
+	dir := t.TempDir()
+	localCache := localcache.New(filepath.Join(dir, ".devspace", "cache.yaml"))
+	localCache.SetImageCache("api", localcache.ImageCache{
+		ImageName: "registry.example.com/api",
+		Tag:       "abcdef",
+	})
+	dependency := &fakeDependency{
+		name: "api",
+		path: dir,
+		config: config.NewConfig(nil, nil, &latest.Config{
+			Name: "api",
+			Outputs: map[string]string{
+				"serviceUrl": "http://api.${runtime.config}",
+				"image":      "${runtime.images.api}",
+			},
+		}, localCache, nil, map[string]interface{}{}, filepath.Join(dir, "devspace.yaml")),
+	}
+	err := ResolveOutputs(context.Background(), dependency)
+	assert.NilError(t, err)
+
+	parent := config.NewConfig(nil, nil, &latest.Config{Name: "parent"}, localcache.New(""), nil, map[string]interface{}{}, filepath.Join(dir, "parent", "devspace.yaml"))
+	_, value, err := NewRuntimeVariable("runtime.dependencies.api.outputs.image", parent, []types.Dependency{dependency}).Load()
+	assert.NilError(t, err)
+	assert.Equal(t, value, "registry.example.com/api:abcdef")
+	_, value, err = NewRuntimeVariable("runtime.dependencies.api.outputs.serviceUrl", parent, []types.Dependency{dependency}).Load()
+	assert.NilError(t, err)
+	assert.Equal(t, value, "http://api."+filepath.Join(dir, "devspace.yaml"))
+
+	// outputs of a previous run are loaded from the cache
+	loadedCache, err := localcache.NewCacheLoader().Load(filepath.Join(dir, "devspace.yaml"))
+	assert.NilError(t, err)
+	dependency.config = config.NewConfig(nil, nil, &latest.Config{Name: "api"}, loadedCache, nil, map[string]interface{}{}, filepath.Join(dir, "devspace.yaml"))
+	_, value, err = NewRuntimeVariable("runtime.dependencies.api.outputs.image", parent, []types.Dependency{dependency}).Load()
+	assert.NilError(t, err)
+	assert.Equal(t, value, "registry.example.com/api:abcdef")
+
+	_, _, err = NewRuntimeVariable("runtime.dependencies.api.outputs.missing", parent, []types.Dependency{dependency}).Load()
+	assert.ErrorContains(t, err, "make sure the dependency was deployed before")
+}
@@ -12,6 +12,7 @@ import (
 )
 
 var Locations = []string{
+	"/images/*/tags/*",
 	"/images/*/build/custom/command",
 	"/images/*/build/custom/commands/*/command",
 	"/images/*/build/custom/args/**",
@@ -39,6 +40,7 @@ var Locations = []string{
 	"/commands/*/command",
 	"/functions/**",
 	"/imports/**",
+	"/outputs/*",
 }
 
 // NewRuntimeVariable creates a new variable that is loaded during runtime
@@ -95,6 +97,16 @@ func (e *runtimeVariable) Load() (bool, interface{}, error) {
 		return false, out, nil
 	}
 
+	// get outputs of a dependency that didn't run in this session from the cache
+	if strings.HasPrefix(runtimeVar, OutputsPrefix) {
+		out, ok := cachedOutput(c, runtimeVar)
+		if !ok {
+			return false, nil, fmt.Errorf("couldn't find runtime variable %s, make sure the dependency was deployed before", e.name)
+		}
+
+		return false, out, nil
+	}
+
 	// get image info from generated config
 	if strings.HasPrefix(runtimeVar, "images.") {
 		runtimeVar = strings.TrimPrefix(runtimeVar, "images.")
@@ -138,8 +150,9 @@ func GetImage(c config.Config, imageName string, onlyImage, onlyTag bool) (bool,
 	if c.Config().Images != nil && c.Config().Images[imageName] != nil {
 		configImage := c.Config().Images[imageName]
 
+		// tags with variables are only resolved when the image is built
 		tag := ""
-		if len(configImage.Tags) > 0 {
+		if len(configImage.Tags) > 0 && !strings.Contains(configImage.Tags[0], "${") {
 			tag = configImage.Tags[0]
 		}
 
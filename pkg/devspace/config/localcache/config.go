@@ -51,7 +51,7 @@ func (l *cacheLoader) Load(devSpaceFilePath string) (Cache, error) {
 		return nil, err
 	}
 
-	cachePath := cachePath(absPath)
+	cachePath := CachePath(absPath)
 	data, readErr := os.ReadFile(cachePath)
 	if readErr != nil {
 		loadedConfig = New(cachePath).(*LocalCache)
@@ -103,9 +103,9 @@ func (l *cacheLoader) Load(devSpaceFilePath string) (Cache, error) {
 	return loadedConfig, nil
 }
 
-// cachePath returns the cache absolute path. The if the default devspace.yaml is given the cache path
+// CachePath returns the cache absolute path. The if the default devspace.yaml is given the cache path
 // will be $PWD/.devspace/cache.yaml. For any other file name it will be $PWD/.devspace/cache-[file name]
-func cachePath(devSpaceConfigPath string) string {
+func CachePath(devSpaceConfigPath string) string {
 	fileDir := filepath.Dir(devSpaceConfigPath)
 	fileName := filepath.Base(devSpaceConfigPath)
 	if fileName == constants.DefaultConfigPath {
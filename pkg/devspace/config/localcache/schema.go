@@ -64,11 +64,19 @@ type ImageCache struct {
 	ContextHash    string `yaml:"contextHash,omitempty"`
 	EntrypointHash string `yaml:"entrypointHash,omitempty"`
 
+	// ContentHash is the hash of the dockerfile content, the image config and the build context
+	// the image was built from
+	ContentHash string `yaml:"contentHash,omitempty"`
+
 	CustomFilesHash string `yaml:"customFilesHash,omitempty"`
 
 	ImageName              string `yaml:"imageName,omitempty"`
 	LocalRegistryImageName string `yaml:"localRegistryImageName,omitempty"`
 	Tag                    string `yaml:"tag,omitempty"`
+	Digest                 string `yaml:"digest,omitempty"`
+
+	// Tags are all tags of the last build, Tag is the first of them
+	Tags []string `yaml:"tags,omitempty"`
 }
 
 func (ic ImageCache) IsLocalRegistryImage() bool {
@@ -83,6 +91,16 @@ func (ic ImageCache) ResolveImage() string {
 	return ic.ImageName
 }
 
+// ResolveImageWithDigest returns the image with its tag and, if the image was pushed, its digest
+func (ic ImageCache) ResolveImageWithDigest() string {
+	image := ic.ResolveImage() + ":" + ic.Tag
+	if ic.Digest != "" {
+		image += "@" + ic.Digest
+	}
+
+	return image
+}
+
 func (l *LocalCache) ListImageCache() map[string]ImageCache {
 	l.accessMutex.Lock()
 	defer l.accessMutex.Unlock()
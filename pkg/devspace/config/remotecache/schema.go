@@ -92,11 +92,44 @@ type DeploymentCache struct {
 	// DeploymentConfigHash is the deployment config hashed
 	DeploymentConfigHash string `yaml:"deploymentConfigHash,omitempty"`
 
+	// Protected is true if the deployment was deployed with protect and is skipped by purge
+	Protected bool `yaml:"protected,omitempty"`
+
 	// Helm holds the helm cache
 	Helm *HelmCache `yaml:"helmCache,omitempty"`
 
 	// Kubectl holds the kubectl cache
 	Kubectl *KubectlCache `yaml:"kubectlCache,omitempty"`
+
+	// Plugin holds the plugin deployer cache
+	Plugin *PluginCache `yaml:"pluginCache,omitempty"`
+
+	// History holds the last deploys of this deployment, the most recent deploy is the last entry
+	History []DeploymentHistory `yaml:"history,omitempty"`
+}
+
+// DeploymentHistory records who deployed a deployment when and what was deployed
+type DeploymentHistory struct {
+	// Timestamp is the unix time of the deploy
+	Timestamp int64 `yaml:"timestamp,omitempty"`
+
+	// User is the local user that deployed the deployment
+	User string `yaml:"user,omitempty"`
+
+	// GitCommit is the commit the project was checked out at
+	GitCommit string `yaml:"gitCommit,omitempty"`
+
+	// Images are the images with the tags that were deployed
+	Images []string `yaml:"images,omitempty"`
+
+	// Profiles are the profiles that were active
+	Profiles []string `yaml:"profiles,omitempty"`
+}
+
+type PluginCache struct {
+	Type      string `yaml:"type,omitempty"`
+	Config    string `yaml:"config,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
 }
 
 type HelmCache struct {
@@ -112,6 +145,17 @@ type HelmCache struct {
 type KubectlCache struct {
 	Objects       []KubectlObject `yaml:"kubectlObjects,omitempty"`
 	ManifestsHash string          `yaml:"kubectlManifestsHash,omitempty"`
+
+	// Manifest is the gzipped and base64 encoded manifest that was applied by the last deploy
+	Manifest string `yaml:"kubectlManifest,omitempty"`
+
+	// Previous is the revision before the last deploy, which is applied again by a rollback
+	Previous *KubectlRevision `yaml:"previous,omitempty"`
+}
+
+type KubectlRevision struct {
+	Objects  []KubectlObject `yaml:"kubectlObjects,omitempty"`
+	Manifest string          `yaml:"kubectlManifest,omitempty"`
 }
 
 type KubectlObject struct {
@@ -102,6 +102,17 @@ type Config struct {
 	// the same project multiple times, make sure to use a different name for each of those instances.
 	Dependencies map[string]*DependencyConfig `yaml:"dependencies,omitempty" json:"dependencies,omitempty"`
 
+	// Workspace discovers the DevSpace projects of a mono repo and adds them as path dependencies, so
+	// that they don't need to be listed in dependencies. The name of a discovered dependency is the name
+	// of its folder. Dependencies with the same name or path take precedence over discovered ones.
+	Workspace *WorkspaceConfig `yaml:"workspace,omitempty" json:"workspace,omitempty"`
+
+	// Outputs are values this project exports to projects that use it as a dependency, such as a service url
+	// or the tag of a built image. Outputs can contain runtime variables, e.g. ${runtime.images.api.tag}, and are
+	// resolved after the pipeline of the dependency has run. The parent project can reference an output via
+	// ${runtime.dependencies.NAME.outputs.KEY}.
+	Outputs map[string]string `yaml:"outputs,omitempty" json:"outputs,omitempty"`
+
 	// PullSecrets are image pull secrets that will be created by devspace in the target namespace
 	// during devspace dev or devspace deploy. DevSpace will merge all defined pull secrets into a single
 	// one or the one specified.
@@ -149,6 +160,15 @@ type Pipeline struct {
 	// ContinueOnError will not fail the whole job and pipeline if
 	// a call within the step fails.
 	ContinueOnError bool `yaml:"continueOnError,omitempty" json:"continueOnError,omitempty"`
+
+	// Timeout is the amount of seconds the pipeline may run, before it is cancelled together with the
+	// pipelines it started and fails. Dev configurations started by the pipeline are stopped as well.
+	// Defaults to no timeout
+	Timeout int64 `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// StepTimeout is the amount of seconds a single command of the pipeline may run, before it is
+	// cancelled and fails. Defaults to no timeout
+	StepTimeout int64 `yaml:"stepTimeout,omitempty" json:"stepTimeout,omitempty"`
 }
 
 // PipelineFlag defines an extra pipeline flag
@@ -243,7 +263,10 @@ type Image struct {
 	Image string `yaml:"image" json:"image" jsonschema:"required"`
 
 	// Tags is an array that specifies all tags that should be build during
-	// the build process. If this is empty, devspace will generate a random tag
+	// the build process. If this is empty, devspace will generate a random tag.
+	// Tags can contain the variables ${GIT_COMMIT}, ${GIT_COMMIT_SHORT}, ${GIT_BRANCH},
+	// ${TIMESTAMP}, ${DATE}, ${DATETIME} and ${SEMVER_BUMP}, which are resolved when the
+	// image is built, and # which is replaced with a random character, e.g. dev-${USER}-####
 	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
 
 	// Dockerfile specifies a path (relative or absolute) to the dockerfile. Defaults
@@ -274,6 +297,36 @@ type Image struct {
 	// Network is the network that should get used to build the image
 	Network string `yaml:"network,omitempty" json:"network,omitempty" jsonschema_extras:"group=buildConfig"`
 
+	// Platforms the image is built for, e.g. linux/amd64 and linux/arm64. With more than one platform, the
+	// image is pushed as multi-arch index, unless pushing is skipped, then it is built for the platform of
+	// the builder. Kaniko can only build for a single platform
+	Platforms []string `yaml:"platforms,omitempty" json:"platforms,omitempty" jsonschema_extras:"group=buildConfig"`
+
+	// Attestations are generated while building the image and attached to the pushed image. Only builds
+	// with BuildKit can generate attestations
+	Attestations *BuildAttestations `yaml:"attestations,omitempty" json:"attestations,omitempty" jsonschema_extras:"group=buildConfig"`
+
+	// Scan scans the image for vulnerabilities after it was built and before it is pushed. Depending on the
+	// severity of the vulnerabilities, the build fails or a warning is printed. Only images built with docker
+	// can be scanned, because the other builders push the image while building it
+	Scan *ImageScan `yaml:"scan,omitempty" json:"scan,omitempty" jsonschema_extras:"group=buildConfig"`
+
+	// Secrets are available to RUN instructions that mount them with `--mount=type=secret,id=...`. In contrast
+	// to build args, secrets don't end up in the image history. Docker and BuildKit builds pass them with
+	// --secret, remote BuildKit daemons and the local registry builder get them through the BuildKit session
+	// and kaniko builds provide them as files in /run/secrets while building. Local registry builds with
+	// `localbuild` don't support secrets
+	Secrets []*BuildSecret `yaml:"secrets,omitempty" json:"secrets,omitempty" jsonschema_extras:"group=buildConfig"`
+
+	// CacheFrom are the caches BuildKit imports layers from, e.g. `type=registry,ref=registry.io/app:cache`,
+	// `type=local,src=.devspace/cache` or the name of an image that was pushed with an inline cache.
+	// Only builds with BuildKit use the caches
+	CacheFrom []string `yaml:"cacheFrom,omitempty" json:"cacheFrom,omitempty" jsonschema_extras:"group=buildConfig"`
+
+	// CacheTo are the caches BuildKit exports layers to, e.g. `type=registry,ref=registry.io/app:cache,mode=max`,
+	// `type=inline` or `type=local,dest=.devspace/cache`
+	CacheTo []string `yaml:"cacheTo,omitempty" json:"cacheTo,omitempty" jsonschema_extras:"group=buildConfig"`
+
 	// RebuildStrategy is used to determine when DevSpace should rebuild an image. By default, devspace will
 	// rebuild an image if one of the following conditions is true:
 	// - The dockerfile has changed
@@ -303,6 +356,10 @@ type Image struct {
 	// a custom script.
 	Custom *CustomConfig `yaml:"custom,omitempty" json:"custom,omitempty" jsonschema_extras:"group=engines"`
 
+	// Plugin if plugin is specified, DevSpace will build the image with a build engine that was
+	// registered by a plugin.
+	Plugin *PluginBuildConfig `yaml:"plugin,omitempty" json:"plugin,omitempty" jsonschema_extras:"group=engines"`
+
 	// InjectRestartHelper will inject a small restart script into the container and wraps the entrypoint of that
 	// container, so that devspace is able to restart the complete container during sync.
 	// Please make sure you either have an Entrypoint defined in the devspace config or in the
@@ -319,6 +376,58 @@ type Image struct {
 	RestartHelperPath string `yaml:"restartHelperPath,omitempty" json:"restartHelperPath,omitempty" jsonschema:"-"`
 }
 
+// BuildAttestations configures the attestations that are attached to the image
+type BuildAttestations struct {
+	// SBOM generates a software bill of materials in the SPDX format, which is compatible with syft. The SBOM
+	// is also saved to .devspace/sbom/$IMAGE_CONFIG_NAME.spdx.json and exposed to the after:build hooks
+	// as $DEVSPACE_HOOK_SBOM_PATH
+	SBOM bool `yaml:"sbom,omitempty" json:"sbom,omitempty"`
+
+	// Provenance generates a SLSA provenance attestation, which records how the image was built
+	Provenance bool `yaml:"provenance,omitempty" json:"provenance,omitempty"`
+}
+
+// ImageScan configures the vulnerability scan of an image
+type ImageScan struct {
+	// Scanner is the cli that scans the image and needs to be installed locally. Defaults to trivy
+	Scanner string `yaml:"scanner,omitempty" json:"scanner,omitempty" jsonschema:"enum=trivy,enum=grype"`
+
+	// FailOn fails the build if the image has vulnerabilities with this severity or higher. If neither
+	// failOn nor warnOn is set, the build fails on high vulnerabilities
+	FailOn string `yaml:"failOn,omitempty" json:"failOn,omitempty" jsonschema:"enum=low,enum=medium,enum=high,enum=critical"`
+
+	// WarnOn prints a warning for vulnerabilities with this severity or higher
+	WarnOn string `yaml:"warnOn,omitempty" json:"warnOn,omitempty" jsonschema:"enum=low,enum=medium,enum=high,enum=critical"`
+
+	// Args are additional arguments for the scanner cli
+	Args []string `yaml:"args,omitempty" json:"args,omitempty"`
+}
+
+// BuildSecret is a secret that is mounted into the build, either from an environment variable or a file
+type BuildSecret struct {
+	// ID of the secret, which is referenced in the Dockerfile, e.g. `RUN --mount=type=secret,id=npmrc`
+	ID string `yaml:"id" json:"id" jsonschema:"required"`
+
+	// Env is the environment variable that holds the secret
+	Env string `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// File is the path of the file that holds the secret
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+}
+
+// PluginBuildConfig tells the DevSpace CLI to build with a build engine registered by a plugin
+type PluginBuildConfig struct {
+	// Type is the name of the build engine the plugin has registered
+	Type string `yaml:"type" json:"type"`
+
+	// Config is passed as json to the plugin build engine
+	Config map[string]interface{} `yaml:"config,omitempty" json:"config,omitempty"`
+
+	// OnChange will determine when the image needs to be rebuilt. If no onChange is specified, the
+	// image will be rebuilt whenever the image config changes.
+	OnChange []string `yaml:"onChange,omitempty" json:"onChange,omitempty"`
+}
+
 // RebuildStrategy is the type of a image rebuild strategy
 type RebuildStrategy string
 
@@ -340,6 +449,9 @@ type DockerConfig struct {
 	UseCLI bool `yaml:"useCli,omitempty" json:"useCli,omitempty"`
 	// Args are additional arguments to pass to the docker cli
 	Args []string `yaml:"args,omitempty" json:"args,omitempty"`
+	// DockerContext is the name of the docker context (see docker context ls) to use for building. If empty,
+	// the DOCKER_CONTEXT environment variable or the current context of the docker config is used
+	DockerContext string `yaml:"dockerContext,omitempty" json:"dockerContext,omitempty"`
 
 	// DEPRECATED: UseBuildKit
 	UseBuildKit bool `yaml:"useBuildKit,omitempty" json:"useBuildKit,omitempty" jsonschema:"-"`
@@ -350,6 +462,10 @@ type BuildKitConfig struct {
 	// InCluster if specified, DevSpace will use BuildKit to build the image within the cluster
 	InCluster *BuildKitInClusterConfig `yaml:"inCluster,omitempty" json:"inCluster,omitempty"`
 
+	// Remote if specified, DevSpace will submit the build to a remote BuildKit daemon directly. This
+	// doesn't need docker or buildx on the local machine
+	Remote *BuildKitRemoteConfig `yaml:"remote,omitempty" json:"remote,omitempty"`
+
 	// PreferMinikube if false, will not try to use the minikube docker daemon to build the image
 	PreferMinikube *bool `yaml:"preferMinikube,omitempty" json:"preferMinikube,omitempty"`
 
@@ -360,6 +476,26 @@ type BuildKitConfig struct {
 	Command []string `yaml:"command,omitempty" json:"command,omitempty"`
 }
 
+// BuildKitRemoteConfig holds the config of a remote BuildKit daemon
+type BuildKitRemoteConfig struct {
+	// Address of the BuildKit daemon, e.g. `tcp://buildkitd.example.com:1234`. A BuildKit pod in the
+	// cluster can be used with `kube-pod://buildkitd-0?namespace=build&container=buildkitd`
+	Address string `yaml:"address" json:"address" jsonschema:"required"`
+
+	// ServerName is the name of the BuildKit daemon in its TLS certificate. Defaults to the host of the address
+	ServerName string `yaml:"serverName,omitempty" json:"serverName,omitempty"`
+
+	// CACert is the path to the certificate authority that verifies the certificate of the BuildKit daemon.
+	// If set, the connection uses TLS
+	CACert string `yaml:"caCert,omitempty" json:"caCert,omitempty"`
+
+	// Cert is the path to the client certificate to authenticate at the BuildKit daemon. Requires caCert and key
+	Cert string `yaml:"cert,omitempty" json:"cert,omitempty"`
+
+	// Key is the path to the key of the client certificate
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+}
+
 // BuildKitInClusterConfig holds the buildkit builder config
 type BuildKitInClusterConfig struct {
 	// Name is the name of the builder to use. If omitted, DevSpace will try to create
@@ -379,6 +515,12 @@ type BuildKitInClusterConfig struct {
 	// NodeSelector is the node selector to use for the BuildKit deployment
 	NodeSelector string `yaml:"nodeSelector,omitempty" json:"nodeSelector,omitempty"`
 
+	// Tolerations are the tolerations of the BuildKit deployment
+	Tolerations []k8sv1.Toleration `yaml:"tolerations,omitempty" json:"tolerations,omitempty"`
+
+	// Resources are the cpu and memory resources of the BuildKit deployment
+	Resources *PodResources `yaml:"resources,omitempty" json:"resources,omitempty"`
+
 	// NoCreate. By default, DevSpace will try to create a new builder if it cannot be found.
 	// If this is true, DevSpace will fail if the specified builder cannot be found.
 	NoCreate bool `yaml:"noCreate,omitempty" json:"noCreate,omitempty"`
@@ -462,10 +604,17 @@ type KanikoConfig struct {
 
 	// Resources are the resources that should be set on the kaniko pod
 	Resources *PodResources `yaml:"resources,omitempty" json:"resources,omitempty"`
+
+	// PriorityClassName is the priority class of the kaniko pod
+	PriorityClassName string `yaml:"priorityClassName,omitempty" json:"priorityClassName,omitempty"`
 }
 
-// PodResources describes the resources section of the started kaniko pod
+// PodResources describes the resources section of the started build pod
 type PodResources struct {
+	// Preset sets the requests and limits for the cpu and memory of a small, medium or large build.
+	// Requests and limits that are set explicitly override the preset
+	Preset string `yaml:"preset,omitempty" json:"preset,omitempty" jsonschema:"enum=small,enum=medium,enum=large"`
+
 	// Requests are the requests part of the resources
 	Requests map[string]string `yaml:"requests,omitempty" json:"requests,omitempty"`
 
@@ -473,6 +622,13 @@ type PodResources struct {
 	Limits map[string]string `yaml:"limits,omitempty" json:"limits,omitempty"`
 }
 
+// Resource presets of build pods
+const (
+	PodResourcesPresetSmall  = "small"
+	PodResourcesPresetMedium = "medium"
+	PodResourcesPresetLarge  = "large"
+)
+
 // KanikoAdditionalMount tells devspace how the additional mount of the kaniko pod should look like
 type KanikoAdditionalMount struct {
 	// The secret that should be mounted
@@ -570,6 +726,10 @@ type CustomConfig struct {
 	Command string `yaml:"command,omitempty" json:"command,omitempty"`
 	// OnChange will determine when the command should be rerun
 	OnChange []string `yaml:"onChange,omitempty" json:"onChange,omitempty"`
+	// Protocol is the protocol DevSpace uses to talk to the command. With `json`, the image name, tags and
+	// build context are written as json to stdin of the command and the command writes json messages
+	// with logs and the digest of the pushed image to stdout.
+	Protocol string `yaml:"protocol,omitempty" json:"protocol,omitempty" jsonschema:"enum=json"`
 
 	// DEPRECATED: Commands
 	Commands []CustomConfigCommand `yaml:"commands,omitempty" json:"commands,omitempty" jsonschema:"-"`
@@ -585,6 +745,9 @@ type CustomConfig struct {
 	SkipImageArg *bool `yaml:"skipImageArg,omitempty" json:"skipImageArg,omitempty" jsonschema:"-"`
 }
 
+// CustomProtocolJSON lets custom build commands exchange json messages with DevSpace
+const CustomProtocolJSON = "json"
+
 // CustomConfigCommand holds the information about a command on a specific operating system
 type CustomConfigCommand struct {
 	// Command to run
@@ -619,8 +782,59 @@ type LocalRegistryConfig struct {
 	// Port that the registry image listens on. Default is `5000`
 	Port *int `yaml:"port,omitempty" json:"port,omitempty"`
 
+	// ServiceType of the registry service. With `ClusterIP`, DevSpace pushes through a port forwarding
+	// and the nodes pull the images via the cluster ip of the service, so they need to trust it as
+	// insecure registry. `ClusterIP` can't be used together with `tls`. Default is `NodePort`
+	ServiceType string `yaml:"serviceType,omitempty" json:"serviceType,omitempty" jsonschema:"enum=NodePort,enum=ClusterIP"`
+
+	// TLS serves the local registry over https with a certificate that is generated by DevSpace. The
+	// certificate authority is installed for docker and containerd on the nodes, so that they can pull
+	// from the registry. Containerd needs to be configured to read hosts from /etc/containerd/certs.d. The
+	// certificate is installed by a daemon set that runs as root and mounts these folders from the nodes,
+	// so the namespace of the registry needs to allow privileged pods. An existing registry is restarted
+	// with the new settings if tls is enabled or disabled
+	TLS bool `yaml:"tls,omitempty" json:"tls,omitempty"`
+
+	// Auth protects the local registry with a generated username and password. DevSpace creates
+	// an image pull secret for the registry and adds it to the default service account
+	Auth bool `yaml:"auth,omitempty" json:"auth,omitempty"`
+
+	// Platforms the images are built for, e.g. linux/amd64 and linux/arm64. With more than one platform, the
+	// images are pushed as multi-arch index. Default is the platform of the builder
+	Platforms []string `yaml:"platforms,omitempty" json:"platforms,omitempty"`
+
+	// Retries of pushes and image checks that failed with a server or network error. The delay
+	// between the attempts doubles, starting at one second. With `0`, only the single requests
+	// are retried by the registry client. Default is `3`
+	Retries *int `yaml:"retries,omitempty" json:"retries,omitempty"`
+
+	// Provider makes the images available to the cluster. `registry` deploys the local registry into
+	// the cluster, `kind` and `minikube` load the images into the nodes, `ttl.sh` pushes them to the
+	// public ephemeral registry ttl.sh and `auto` uses kind or minikube if the current context belongs
+	// to such a cluster. All providers besides `registry` need a docker daemon and are used for images
+	// that can't be pushed, even if `enabled` is false or the cluster is local. Default is `registry`
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty" jsonschema:"enum=registry,enum=auto,enum=kind,enum=minikube,enum=ttl.sh"`
+
 	// Persistence settings for the local registry
 	Persistence *LocalRegistryPersistence `yaml:"persistence,omitempty" json:"persistence,omitempty"`
+
+	// Cache settings for the pull-through cache of the local registry
+	Cache *LocalRegistryCache `yaml:"cache,omitempty" json:"cache,omitempty"`
+
+	// Registries configures mirrors and insecure access per registry host, e.g. `docker.io` or
+	// `registry.internal:5000`. The settings are used when DevSpace talks to these registries itself,
+	// e.g. to check push permissions or to resolve image digests, and kaniko builds pull through the mirrors
+	Registries map[string]*RegistryHostConfig `yaml:"registries,omitempty" json:"registries,omitempty"`
+}
+
+// RegistryHostConfig configures how DevSpace reaches a registry
+type RegistryHostConfig struct {
+	// Mirrors are tried in order before the registry itself when images are read. Pushes always go
+	// to the registry. A mirror is a host, e.g. `mirror.internal:5000`, or an url like `http://mirror.internal`
+	Mirrors []string `yaml:"mirrors,omitempty" json:"mirrors,omitempty"`
+
+	// Insecure allows plain http and skips the certificate verification for the registry and its mirrors
+	Insecure bool `yaml:"insecure,omitempty" json:"insecure,omitempty"`
 }
 
 // LocalRegistryPersistence configures persistence settings for the local registry
@@ -635,6 +849,19 @@ type LocalRegistryPersistence struct {
 	StorageClassName string `yaml:"storageClassName,omitempty" json:"storageClassName,omitempty"`
 }
 
+// LocalRegistryCache configures a pull-through cache that runs next to the local registry and keeps
+// the layers of base images in the cluster
+type LocalRegistryCache struct {
+	// Enabled deploys the pull-through cache, which kaniko builds use as registry mirror
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// RemoteURL of the registry that is cached. Default is `https://registry-1.docker.io`
+	RemoteURL string `yaml:"remoteURL,omitempty" json:"remoteURL,omitempty"`
+
+	// Port that the cache listens on. Default is `5001`
+	Port *int `yaml:"port,omitempty" json:"port,omitempty"`
+}
+
 // DeploymentConfig defines the configuration how the devspace should be deployed
 type DeploymentConfig struct {
 	// Name of the deployment
@@ -644,6 +871,10 @@ type DeploymentConfig struct {
 	Helm *HelmConfig `yaml:"helm,omitempty" json:"helm,omitempty"`
 	// Kubectl tells DevSpace to deploy this deployment via kubectl or kustomize
 	Kubectl *KubectlConfig `yaml:"kubectl,omitempty" json:"kubectl,omitempty"`
+	// Kustomize tells DevSpace to build this deployment with kustomize and apply it via kubectl
+	Kustomize *KustomizeConfig `yaml:"kustomize,omitempty" json:"kustomize,omitempty"`
+	// Plugin tells DevSpace to deploy this deployment via a deployment type that was registered by a plugin
+	Plugin *PluginDeploymentConfig `yaml:"plugin,omitempty" json:"plugin,omitempty"`
 
 	// UpdateImageTags lets you define if DevSpace should update the tags of the images defined in the
 	// images section with their most recent built tag.
@@ -651,6 +882,82 @@ type DeploymentConfig struct {
 
 	// Namespace where to deploy this deployment
 	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Needs are the deployments that have to be deployed before this deployment. Deployments that don't need
+	// each other are deployed in parallel
+	Needs []string `yaml:"needs,omitempty" json:"needs,omitempty"`
+
+	// KubeContext is the kube context this deployment is deployed to instead of the current kube context. If no
+	// namespace is specified, the default namespace of the kube context is used
+	KubeContext string `yaml:"kubeContext,omitempty" json:"kubeContext,omitempty"`
+
+	// KubeContexts are multiple kube contexts this deployment is deployed to, e.g. to deploy the same
+	// manifests to several clusters. DevSpace deploys to all of them and reports the status of each
+	KubeContexts []string `yaml:"kubeContexts,omitempty" json:"kubeContexts,omitempty"`
+
+	// Protect prevents that this deployment is deleted by devspace purge, e.g. for shared infrastructure. A
+	// protected deployment is only purged with --force-purge after the deletion was confirmed
+	Protect bool `yaml:"protect,omitempty" json:"protect,omitempty"`
+
+	// Wait lets DevSpace wait after deploying until the workloads of this deployment are ready
+	Wait *DeploymentWaitConfig `yaml:"wait,omitempty" json:"wait,omitempty"`
+
+	// Canary rolls out changed Deployments progressively. Before deploying, DevSpace creates a canary with
+	// the new pod template and a percentage of the replicas next to each existing Deployment and only
+	// deploys if the canary becomes ready
+	Canary *DeploymentCanaryConfig `yaml:"canary,omitempty" json:"canary,omitempty"`
+}
+
+// DeploymentCanaryConfig defines how a deployment is rolled out progressively
+type DeploymentCanaryConfig struct {
+	// Percentage of the replicas the canary is created with, at least one replica. Defaults to 20
+	Percentage int `yaml:"percentage,omitempty" json:"percentage,omitempty"`
+
+	// Timeout is the amount of seconds to wait until the canary is ready. Defaults to 300 seconds
+	Timeout int64 `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// DeploymentWaitConfig defines when a deployment is ready
+type DeploymentWaitConfig struct {
+	// Timeout is the amount of seconds to wait until the deployment fails. Defaults to 300 seconds
+	Timeout int64 `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// Rollout waits until the Deployments, StatefulSets and DaemonSets of the deployment are rolled out.
+	// Defaults to true
+	Rollout *bool `yaml:"rollout,omitempty" json:"rollout,omitempty"`
+
+	// Jobs waits until the Jobs of the deployment are completed and fails if one of them fails. Defaults to true
+	Jobs *bool `yaml:"jobs,omitempty" json:"jobs,omitempty"`
+
+	// Conditions are custom readiness checks for objects selected by labels
+	Conditions []*DeploymentWaitCondition `yaml:"conditions,omitempty" json:"conditions,omitempty"`
+}
+
+// DeploymentWaitCondition waits until all objects matched by the label selector have the value at the json path
+type DeploymentWaitCondition struct {
+	// APIVersion of the objects, defaults to v1
+	APIVersion string `yaml:"apiVersion,omitempty" json:"apiVersion,omitempty"`
+
+	// Kind of the objects, defaults to Pod
+	Kind string `yaml:"kind,omitempty" json:"kind,omitempty"`
+
+	// LabelSelector selects the objects, at least one object has to match
+	LabelSelector map[string]string `yaml:"labelSelector" json:"labelSelector" jsonschema:"required"`
+
+	// JSONPath selects the field of the objects, e.g. $.status.conditions[?(@.type=='Ready')].status
+	JSONPath string `yaml:"jsonPath" json:"jsonPath" jsonschema:"required"`
+
+	// Value is the value the field needs to have, defaults to True
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+}
+
+// PluginDeploymentConfig defines a deployment that is deployed by a plugin deployer
+type PluginDeploymentConfig struct {
+	// Type is the name of the deployment type the plugin has registered
+	Type string `yaml:"type" json:"type"`
+
+	// Config is passed as json to the plugin deployer
+	Config map[string]interface{} `yaml:"config,omitempty" json:"config,omitempty"`
 }
 
 // ComponentConfig holds the component information
@@ -841,6 +1148,12 @@ type HelmConfig struct {
 	Values map[string]interface{} `yaml:"values,omitempty" json:"values,omitempty"`
 	// ValuesFiles are additional files that hold values for deploying this chart
 	ValuesFiles []string `yaml:"valuesFiles,omitempty" json:"valuesFiles,omitempty"`
+	// TemplateValuesFiles resolves variables and runtime variables, e.g. ${runtime.images.my-image.tag},
+	// within the values files
+	TemplateValuesFiles bool `yaml:"templateValuesFiles,omitempty" json:"templateValuesFiles,omitempty"`
+	// ImagesOverride sets values of the chart to the most recently built image of an image of the images section.
+	// The key is the name of the image in the images section
+	ImagesOverride map[string]*HelmImageOverride `yaml:"imagesOverride,omitempty" json:"imagesOverride,omitempty"`
 	// DisplayOutput allows you to display the helm output to the console
 	DisplayOutput bool `yaml:"displayOutput,omitempty" json:"output,omitempty"`
 
@@ -851,17 +1164,63 @@ type HelmConfig struct {
 
 	// DisableDependencyUpdate disables helm dependencies update, default to false
 	DisableDependencyUpdate *bool `yaml:"disableDependencyUpdate,omitempty" json:"disableDependencyUpdate,omitempty"`
+
+	// PostRenderer modifies the rendered chart before helm installs it, e.g. to add sidecars or labels
+	// without forking the chart
+	PostRenderer *HelmPostRendererConfig `yaml:"postRenderer,omitempty" json:"postRenderer,omitempty"`
+}
+
+// HelmPostRendererConfig defines the post renderer helm passes the rendered chart to. Either command or
+// kustomize is required
+type HelmPostRendererConfig struct {
+	// Command is the binary that receives the rendered chart on stdin and prints the modified manifests to stdout
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
+	// Args are the arguments that are passed to the command
+	Args []string `yaml:"args,omitempty" json:"args,omitempty"`
+
+	// Kustomize applies kustomize patches and components to the rendered chart
+	Kustomize *HelmKustomizePostRenderer `yaml:"kustomize,omitempty" json:"kustomize,omitempty"`
+}
+
+// HelmKustomizePostRenderer defines the kustomize patches and components that are applied to a rendered chart
+type HelmKustomizePostRenderer struct {
+	// Patches are files with strategic merge patches
+	Patches []string `yaml:"patches,omitempty" json:"patches,omitempty"`
+	// Components are folders of kustomize components
+	Components []string `yaml:"components,omitempty" json:"components,omitempty"`
+	// KubectlBinaryPath is the optional path where to find the kubectl binary
+	KubectlBinaryPath string `yaml:"kubectlBinaryPath,omitempty" json:"kubectlBinaryPath,omitempty"`
+	// KustomizeBinaryPath is the optional path where to find the kustomize binary. If set, the patches are
+	// applied with `kustomize build` instead of `kubectl kustomize`
+	KustomizeBinaryPath string `yaml:"kustomizeBinaryPath,omitempty" json:"kustomizeBinaryPath,omitempty"`
+}
+
+// HelmImageOverride defines which values are set to the built image. The values are dot separated paths,
+// e.g. `backend.image.tag`
+type HelmImageOverride struct {
+	// Image is the value that is set to the image with its tag and digest, e.g. my-registry/my-image:tag@sha256:...
+	Image string `yaml:"image,omitempty" json:"image,omitempty"`
+	// Repository is the value that is set to the image without tag, e.g. my-registry/my-image
+	Repository string `yaml:"repository,omitempty" json:"repository,omitempty"`
+	// Tag is the value that is set to the built tag of the image
+	Tag string `yaml:"tag,omitempty" json:"tag,omitempty"`
+	// Digest is the value that is set to the digest of the image, if it was pushed
+	Digest string `yaml:"digest,omitempty" json:"digest,omitempty"`
 }
 
 // ChartConfig defines the helm chart options
 type ChartConfig struct {
 	// Name is the name of the helm chart to deploy. Can also be a local path or an oci url
 	Name string `yaml:"name,omitempty" json:"name,omitempty" jsonschema:"required" jsonschema_extras:"group=repo,group_name=Source: Helm Repository"`
-	// Version is the version of the helm chart to deploy
+	// Version is the version of the helm chart to deploy. For OCI charts this can also be a
+	// constraint, e.g. ~> 1.2 or >= 1.2, < 2.0, and the highest matching version is deployed.
+	// If it is empty, the highest version of the OCI chart is deployed
 	Version string `yaml:"version,omitempty" json:"version,omitempty" jsonschema_extras:"group=repo"`
 	// RepoURL is the url of the repo to deploy the chart from
 	RepoURL string `yaml:"repo,omitempty" json:"repo,omitempty" jsonschema_extras:"group=repo"`
-	// Username is the username to authenticate to the chart repo. When using an OCI chart, used for registry auth
+	// Username is the username to authenticate to the chart repo. When using an OCI chart, used for registry auth.
+	// Without username and password, OCI charts are pulled with the credentials of the pull secret for the
+	// registry or of the docker config
 	Username string `yaml:"username,omitempty" json:"username,omitempty" jsonschema_extras:"group=repo"`
 	// Password is the password to authenticate to the chart repo, When using an OCI chart, used for registry auth
 	Password string `yaml:"password,omitempty" json:"password,omitempty" jsonschema_extras:"group=repo"`
@@ -882,6 +1241,14 @@ type KubectlConfig struct {
 	// KubectlBinaryPath is the optional path where to find the kubectl binary
 	KubectlBinaryPath string `yaml:"kubectlBinaryPath,omitempty" json:"kubectlBinaryPath,omitempty"`
 
+	// ServerSideApply applies the manifests with `kubectl apply --server-side`, so that fields which are managed
+	// by controllers, e.g. replicas set by a HPA or injected sidecars, are not overwritten
+	ServerSideApply *bool `yaml:"serverSideApply,omitempty" json:"serverSideApply,omitempty" jsonschema_extras:"group=serverSideApply,group_name=Server-Side Apply"`
+	// FieldManager is the name of the field manager used for server-side apply. Defaults to `devspace`
+	FieldManager string `yaml:"fieldManager,omitempty" json:"fieldManager,omitempty" jsonschema_extras:"group=serverSideApply"`
+	// ForceConflicts lets server-side apply take ownership of fields that are managed by another field manager
+	ForceConflicts *bool `yaml:"forceConflicts,omitempty" json:"forceConflicts,omitempty" jsonschema_extras:"group=serverSideApply"`
+
 	// InlineManifests is a block containing the manifest to deploy
 	InlineManifest string `yaml:"inlineManifest,omitempty" json:"inlineManifest,omitempty"`
 	// Kustomize can be used to enable kustomize instead of kubectl
@@ -895,6 +1262,24 @@ type KubectlConfig struct {
 	Patches []*PatchTarget `yaml:"patches,omitempty" json:"patches,omitempty" jsonschema_extras:"group=modifications"`
 }
 
+// KustomizeConfig defines a deployment that is built with kustomize. The images of the images section are
+// replaced with their most recent built tag through the images field of kustomize
+type KustomizeConfig struct {
+	// Path is the folder of the kustomization to deploy, e.g. an overlay
+	Path string `yaml:"path" json:"path" jsonschema:"required"`
+	// Components are folders of kustomize components that are added to the kustomization
+	Components []string `yaml:"components,omitempty" json:"components,omitempty"`
+	// BuildArgs are extra arguments for `kustomize build` and require kustomizeBinaryPath
+	BuildArgs []string `yaml:"buildArgs,omitempty" json:"buildArgs,omitempty"`
+	// ApplyArgs are extra arguments for `kubectl apply`
+	ApplyArgs []string `yaml:"applyArgs,omitempty" json:"applyArgs,omitempty"`
+	// KubectlBinaryPath is the optional path where to find the kubectl binary
+	KubectlBinaryPath string `yaml:"kubectlBinaryPath,omitempty" json:"kubectlBinaryPath,omitempty"`
+	// KustomizeBinaryPath is the optional path where to find the kustomize binary. If set, the kustomization
+	// is built with `kustomize build` instead of the kustomize library built into devspace
+	KustomizeBinaryPath string `yaml:"kustomizeBinaryPath,omitempty" json:"kustomizeBinaryPath,omitempty"`
+}
+
 // DevPod holds configurations for selecting a pod and starting dev services for that pod
 type DevPod struct {
 	// Name of the dev configuration
@@ -1312,9 +1697,18 @@ type DependencyConfig struct {
 	// Disabled excludes this dependency from variable resolution and pipeline runs
 	Disabled bool `yaml:"disabled,omitempty" json:"disabled,omitempty"`
 
+	// When is a condition that is evaluated when the dependencies are resolved, e.g.
+	// "${DEVSPACE_PROFILE} != 'minimal'". If the condition is false, the dependency is
+	// excluded the same way as a disabled dependency. Regular expressions (=~) need to match
+	// the whole value, like the conditions of hooks
+	When string `yaml:"when,omitempty" json:"when,omitempty"`
+
 	// Source holds the dependency project
 	Source *SourceConfig `yaml:",inline" json:",inline"`
 
+	// Tags can be used to select a group of dependencies, e.g. via devspace deploy --dependency-tag=infra
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
 	// Pipeline is the pipeline to deploy by default. Defaults to 'deploy'
 	Pipeline string `yaml:"pipeline,omitempty" json:"pipeline,omitempty" jsonschema:"default=deploy" jsonschema_extras:"group=execution,group_name=Execution"`
 
@@ -1330,13 +1724,82 @@ type DependencyConfig struct {
 	// Namespace specifies the namespace this dependency should be deployed to
 	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty" jsonschema_extras:"group=execution"`
 
+	// KubeContext specifies the kube context this dependency should be deployed to. If no namespace
+	// is specified, the default namespace of the kube context is used
+	KubeContext string `yaml:"kubeContext,omitempty" json:"kubeContext,omitempty" jsonschema_extras:"group=execution"`
+
+	// Protect prevents that this dependency and its children are purged by devspace purge, unless
+	// --force-purge is used
+	Protect bool `yaml:"protect,omitempty" json:"protect,omitempty" jsonschema_extras:"group=execution"`
+
+	// Priority defines which dependencies are started first, if dependencies run in parallel and not
+	// all of them can be started at once. Dependencies with a higher priority are started first.
+	// Dependencies with the same priority are started by the duration of their last run, longest first
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty" jsonschema_extras:"group=execution"`
+
+	// BuildWeight is the cost of running this dependency relative to other dependencies, e.g. the memory
+	// its image builds need. If a weight budget is set, dependencies are only started in parallel while
+	// the sum of their weights stays within the budget. Defaults to 1
+	BuildWeight *int `yaml:"buildWeight,omitempty" json:"buildWeight,omitempty" jsonschema_extras:"group=execution"`
+
+	// Timeout is the amount of seconds the pipeline of this dependency may run, before it
+	// is cancelled and fails. Defaults to no timeout
+	Timeout int64 `yaml:"timeout,omitempty" json:"timeout,omitempty" jsonschema_extras:"group=execution"`
+
 	// Profiles specifies which profiles should be applied while loading the dependency
-	Profiles []string `yaml:"profiles,omitempty" json:"profiles,omitempty" jsonschema:"-"`
+	Profiles []string `yaml:"profiles,omitempty" json:"profiles,omitempty" jsonschema_extras:"group=execution"`
+
+	// Set overrides values in the config of the dependency after its profiles were applied. The keys
+	// are config paths, e.g. deployments.api.helm.values.color: blue
+	Set map[string]interface{} `yaml:"set,omitempty" json:"set,omitempty" jsonschema_extras:"group=execution"`
+
+	// Commands turns the dependency into a virtual dependency without a devspace.yaml, which only
+	// runs these commands in its folder after its own dependencies, e.g. terraform apply. Its files
+	// are tracked with the hash change detection, unless a different one is configured
+	Commands []string `yaml:"commands,omitempty" json:"commands,omitempty" jsonschema_extras:"group=execution"`
+
+	// ChangeDetection defines how DevSpace detects if the files of the dependency have changed since
+	// its last successful run. By default, the images and deployments of the dependency are checked
+	ChangeDetection *DependencyChangeDetection `yaml:"changeDetection,omitempty" json:"changeDetection,omitempty" jsonschema_extras:"group=execution"`
 
 	// DisableProfileActivation disabled automatic profile activation of dependency profiles
 	DisableProfileActivation bool `yaml:"disableProfileActivation,omitempty" json:"disableProfileActivation,omitempty" jsonschema:"-"`
 }
 
+// WorkspaceConfig configures which folders are discovered as dependencies
+type WorkspaceConfig struct {
+	// Include are glob patterns relative to the project folder, e.g. services/*. Every matched
+	// folder that contains a devspace.yaml is added as dependency
+	Include []string `yaml:"include,omitempty" json:"include,omitempty"`
+
+	// Exclude are glob patterns of folders that are not added, e.g. services/legacy-*
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+}
+
+// DependencyChangeDetection configures the change detection of a dependency
+type DependencyChangeDetection struct {
+	// Strategy is either hash to hash the files of the dependency or gitDiff to use the current
+	// commit and the uncommitted changes of the git repository of the dependency
+	Strategy ChangeDetectionStrategy `yaml:"strategy,omitempty" json:"strategy,omitempty" jsonschema:"enum=hash,enum=gitDiff"`
+
+	// Include are the paths relative to the dependency folder that are checked for changes, e.g.
+	// src/**. If empty, the complete folder is checked
+	Include []string `yaml:"include,omitempty" json:"include,omitempty"`
+
+	// Exclude are the paths relative to the dependency folder that are ignored, e.g. node_modules.
+	// The .git and .devspace folders are always ignored
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+}
+
+// ChangeDetectionStrategy is the type of a dependency change detection strategy
+type ChangeDetectionStrategy string
+
+// List of values that the change detection strategy can take
+const (
+	ChangeDetectionStrategyHash    ChangeDetectionStrategy = "hash"
+	ChangeDetectionStrategyGitDiff ChangeDetectionStrategy = "gitDiff"
+)
+
 // SourceConfig defines an artifact source
 type SourceConfig struct {
 	// Path is the local path where DevSpace can find the artifact.
@@ -1348,7 +1811,16 @@ type SourceConfig struct {
 	// This option is mutually exclusive with the path option.
 	Git string `yaml:"git,omitempty" json:"git,omitempty" jsonschema_extras:"group=git,group_name=Source: Git Repository"`
 
-	// SubPath is a path within the git repository where the artifact lies in
+	// OCI is an artifact reference in an OCI registry, such as registry.example.com/team/service-config:v1,
+	// that contains the devspace.yaml and its files. The artifact is pulled with the docker credentials
+	// of the local machine. This option is mutually exclusive with the path and git options.
+	OCI string `yaml:"oci,omitempty" json:"oci,omitempty" jsonschema_extras:"group=oci,group_name=Source: OCI Artifact"`
+
+	// Helm is a chart in a helm chart repository. DevSpace generates a devspace.yaml for the dependency
+	// that deploys just this chart. This option is mutually exclusive with the path, git and oci options.
+	Helm *HelmSourceConfig `yaml:"helm,omitempty" json:"helm,omitempty" jsonschema_extras:"group=helm,group_name=Source: Helm Chart Repository"`
+
+	// SubPath is a path within the git repository or oci artifact where the artifact lies in
 	SubPath string `yaml:"subPath,omitempty" json:"subPath,omitempty" jsonschema_extras:"group=git"`
 
 	// Branch is the git branch to pull
@@ -1367,10 +1839,33 @@ type SourceConfig struct {
 	// by devspace
 	DisableShallow bool `yaml:"disableShallow,omitempty" json:"disableShallow,omitempty" jsonschema_extras:"group=git"`
 
+	// Depth is the number of commits a shallow clone fetches. Defaults to 1
+	Depth int `yaml:"depth,omitempty" json:"depth,omitempty" jsonschema_extras:"group=git"`
+
+	// SparsePaths are the directories of the repository that should be checked out. Only the files
+	// of these directories and the files in the root of the repository are downloaded. The subPath
+	// is added automatically
+	SparsePaths []string `yaml:"sparsePaths,omitempty" json:"sparsePaths,omitempty" jsonschema_extras:"group=git"`
+
+	// Submodules specifies if the submodules of the repository should be cloned as well
+	Submodules bool `yaml:"submodules,omitempty" json:"submodules,omitempty" jsonschema_extras:"group=git"`
+
 	// DisablePull will disable pulling every time DevSpace is reevaluating this source
 	DisablePull bool `yaml:"disablePull,omitempty" json:"disablePull,omitempty" jsonschema_extras:"group=git"`
 }
 
+// HelmSourceConfig defines a chart in a helm chart repository
+type HelmSourceConfig struct {
+	// Repo is the url of the chart repository
+	Repo string `yaml:"repo,omitempty" json:"repo,omitempty"`
+
+	// Chart is the name of the chart in the repository
+	Chart string `yaml:"chart,omitempty" json:"chart,omitempty" jsonschema:"required"`
+
+	// Version is the version of the chart to deploy
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
 // HookConfig defines a hook
 type HookConfig struct {
 	// Name is the name of the hook
@@ -1379,9 +1874,18 @@ type HookConfig struct {
 	// Disabled can be used to disable the hook
 	Disabled bool `yaml:"disabled,omitempty" json:"disabled,omitempty"`
 
-	// Events are the events when the hook should be executed
+	// Events are the events when the hook should be executed. Events can contain '*' as wildcard,
+	// e.g. after:*Dependency or before:deploy:*
 	Events []string `yaml:"events" json:"events"`
 
+	// Priority defines the execution order of hooks for the same event. Hooks with a higher priority
+	// are executed first, hooks with the same priority in the order they are defined.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Once specifies that the hook is only executed a single time during a DevSpace run, even if
+	// its events are triggered multiple times.
+	Once bool `yaml:"once,omitempty" json:"once,omitempty"`
+
 	// Command is the base command that is either executed locally or in a remote container.
 	// Command is mutually exclusive with other hook actions. In the case this is defined
 	// together with where.container, DevSpace will until the target container is running and
@@ -1391,10 +1895,18 @@ type HookConfig struct {
 	// Args are additional arguments passed together with the command to execute.
 	Args []string `yaml:"args,omitempty" json:"args,omitempty"`
 
+	// OutputVar is the name of a runtime variable the standard output of the command is saved to. The
+	// variable can be used afterwards via ${runtime.NAME}. If the command prints a json object, each
+	// top level field is also accessible via ${runtime.NAME.FIELD}.
+	OutputVar string `yaml:"outputVar,omitempty" json:"outputVar,omitempty"`
+
 	// If an operating system is defined, the hook will only be executed for the given os.
 	// All supported golang OS types are supported and multiple can be combined with ','.
 	OperatingSystem string `yaml:"os,omitempty" json:"os,omitempty"`
 
+	// When defines additional conditions that all need to match for the hook to be executed.
+	When *HookWhen `yaml:"when,omitempty" json:"when,omitempty"`
+
 	// If Upload is specified, DevSpace will upload certain local files or folders into a
 	// remote container.
 	Upload *HookSyncConfig `yaml:"upload,omitempty" json:"upload,omitempty"`
@@ -1410,6 +1922,10 @@ type HookConfig struct {
 
 	// If true, the hook will be executed in the background.
 	Background bool `yaml:"background,omitempty" json:"background,omitempty"`
+	// Session starts the command as long running process that is restarted if it exits and
+	// is stopped as soon as the DevSpace session (e.g. devspace dev) ends. The output of the
+	// process is printed with the hook name as prefix.
+	Session *HookSessionConfig `yaml:"session,omitempty" json:"session,omitempty"`
 	// If true, the hook will not output anything to the standard out of DevSpace except
 	// for the case when the hook fails, where DevSpace will show the error including
 	// the captured output streams of the hook.
@@ -1420,6 +1936,36 @@ type HookConfig struct {
 	Container *HookContainer `yaml:"container,omitempty" json:"container,omitempty"`
 }
 
+// HookSessionConfig defines how a session hook is kept running
+type HookSessionConfig struct {
+	// RestartDelay is the amount of seconds to wait before the command is restarted after
+	// it exited. Defaults to 2 seconds.
+	RestartDelay int64 `yaml:"restartDelay,omitempty" json:"restartDelay,omitempty"`
+
+	// MaxRestarts is the maximum amount of restarts, after which DevSpace will give up. If
+	// omitted, DevSpace will restart the command indefinitely.
+	MaxRestarts *int `yaml:"maxRestarts,omitempty" json:"maxRestarts,omitempty"`
+}
+
+// HookWhen defines conditions that decide if a hook should be executed
+type HookWhen struct {
+	// OperatingSystem restricts the hook to the given operating systems. Multiple can be combined with ','.
+	OperatingSystem string `yaml:"os,omitempty" json:"os,omitempty"`
+
+	// Arch restricts the hook to the given cpu architectures (e.g. amd64 or arm64). Multiple can be combined with ','.
+	Arch string `yaml:"arch,omitempty" json:"arch,omitempty"`
+
+	// Environment defines key/value pairs where the key is the name of the environment variable and the value is a regular expression used to match the variable's value.
+	Environment map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// Vars defines key/value pairs where the key is the name of the variable and the value is a regular expression used to match the variable's value.
+	Vars map[string]string `yaml:"vars,omitempty" json:"vars,omitempty"`
+
+	// Event defines key/value pairs where the key is the name of a field of the triggering event payload (e.g. event, error or
+	// name) and the value is a regular expression used to match the field's value.
+	Event map[string]string `yaml:"event,omitempty" json:"event,omitempty"`
+}
+
 // HookWaitConfig defines a hook wait config
 type HookWaitConfig struct {
 	// If running is true, will wait until the matched containers are running. Can be used together with terminatedWithCode.
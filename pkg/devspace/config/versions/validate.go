@@ -2,18 +2,21 @@ package versions
 
 import (
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"unicode"
 
 	jsonyaml "github.com/ghodss/yaml"
 	"github.com/pkg/errors"
+	"github.com/vmware-labs/yaml-jsonpath/pkg/yamlpath"
 	"gopkg.in/yaml.v3"
 	k8sv1 "k8s.io/api/core/v1"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/util/dockerfile"
 	"github.com/loft-sh/devspace/pkg/util/encoding"
+	"github.com/loft-sh/devspace/pkg/util/stringutil"
 	"github.com/loft-sh/devspace/pkg/util/yamlutil"
 )
 
@@ -93,6 +96,11 @@ func Validate(config *latest.Config) error {
 		return err
 	}
 
+	err = validateWorkspace(config)
+	if err != nil {
+		return err
+	}
+
 	err = validateFunctions(config.Functions)
 	if err != nil {
 		return err
@@ -174,14 +182,48 @@ func validateDependencies(config *latest.Config) error {
 		if dep.Source == nil {
 			return errors.Errorf("dependencies.%s.source is required", name)
 		}
-		if dep.Source.Git == "" && dep.Source.Path == "" {
-			return errors.Errorf("dependencies.%s.git or dependencies[%s].path is required", name, name)
+		if dep.Source.Git == "" && dep.Source.Path == "" && dep.Source.OCI == "" && dep.Source.Helm == nil {
+			return errors.Errorf("dependencies.%s.git, dependencies[%s].path, dependencies[%s].oci or dependencies[%s].helm is required", name, name, name, name)
+		}
+		if dep.Source.Helm != nil && dep.Source.Helm.Chart == "" {
+			return errors.Errorf("dependencies.%s.helm.chart is required", name)
+		}
+		if dep.Source.Helm != nil && len(dep.Commands) > 0 {
+			return errors.Errorf("dependencies.%s.commands cannot be used together with dependencies.%s.helm", name, name)
+		}
+		if dep.BuildWeight != nil && *dep.BuildWeight < 0 {
+			return errors.Errorf("dependencies.%s.buildWeight cannot be negative", name)
+		}
+	}
+
+	return nil
+}
+
+func validateWorkspace(config *latest.Config) error {
+	if config.Workspace == nil {
+		return nil
+	}
+	if len(config.Workspace.Include) == 0 {
+		return errors.Errorf("workspace.include is required")
+	}
+	for index, pattern := range config.Workspace.Include {
+		if !isRelativePath(pattern) {
+			return errors.Errorf("workspace.include[%d] has to be a path within the project folder", index)
+		}
+	}
+	for index, pattern := range config.Workspace.Exclude {
+		if !isRelativePath(pattern) {
+			return errors.Errorf("workspace.exclude[%d] has to be a path within the project folder", index)
 		}
 	}
 
 	return nil
 }
 
+func isRelativePath(path string) bool {
+	return !filepath.IsAbs(path) && !strings.HasPrefix(filepath.ToSlash(filepath.Clean(path)), "../") && filepath.Clean(path) != ".."
+}
+
 func validateCommands(config *latest.Config) error {
 	for key, command := range config.Commands {
 		if encoding.IsUnsafeCommandName(command.Name) {
@@ -237,6 +279,18 @@ func validateHooks(config *latest.Config) error {
 		if hookConfig.Wait != nil && !hookConfig.Wait.Running && hookConfig.Wait.TerminatedWithCode == nil {
 			return errors.Errorf("hooks[%d].wait.running or hooks[%d].wait.terminatedWithCode is required if hooks[%d].wait is used", index, index, index)
 		}
+		if hookConfig.OutputVar != "" && hookConfig.Command == "" {
+			return errors.Errorf("hooks[%d].outputVar can only be used together with hooks[%d].command", index, index)
+		}
+		if hookConfig.Session != nil && (hookConfig.Command == "" || hookConfig.Container != nil) {
+			return errors.Errorf("hooks[%d].session can only be used together with a local hooks[%d].command", index, index)
+		}
+		if hookConfig.Session != nil && hookConfig.Background {
+			return errors.Errorf("hooks[%d].session cannot be used together with hooks[%d].background", index, index)
+		}
+		if hookConfig.OutputVar != "" && hookConfig.Background {
+			return errors.Errorf("hooks[%d].outputVar cannot be used together with hooks[%d].background", index, index)
+		}
 		if hookConfig.Container != nil {
 			if hookConfig.Container.ContainerName != "" && len(hookConfig.Container.LabelSelector) == 0 {
 				return errors.Errorf("hooks[%d].container.containerName is defined but hooks[%d].container.labelSelector is not defined", index, index)
@@ -255,8 +309,20 @@ func validateDeployments(config *latest.Config) error {
 		if encoding.IsUnsafeName(deployConfig.Name) {
 			return fmt.Errorf("deployments.%s has to match the following regex: %v", index, encoding.UnsafeNameRegEx.String())
 		}
-		if deployConfig.Helm == nil && deployConfig.Kubectl == nil {
-			return errors.Errorf("Please specify either helm or kubectl as deployment type in deployment %s", deployConfig.Name)
+		if deployConfig.Helm == nil && deployConfig.Kubectl == nil && deployConfig.Kustomize == nil && deployConfig.Plugin == nil {
+			return errors.Errorf("Please specify either helm, kubectl, kustomize or plugin as deployment type in deployment %s", deployConfig.Name)
+		}
+		if deployConfig.Plugin != nil && (deployConfig.Kubectl != nil || deployConfig.Helm != nil || deployConfig.Kustomize != nil) {
+			return errors.Errorf("deployments[%s].plugin cannot be used together with helm, kubectl or kustomize", index)
+		}
+		if deployConfig.Kustomize != nil && (deployConfig.Kubectl != nil || deployConfig.Helm != nil) {
+			return errors.Errorf("deployments[%s].kustomize cannot be used together with helm or kubectl", index)
+		}
+		if deployConfig.Kustomize != nil && deployConfig.Kustomize.Path == "" {
+			return errors.Errorf("deployments[%s].kustomize.path is required", index)
+		}
+		if deployConfig.Plugin != nil && deployConfig.Plugin.Type == "" {
+			return errors.Errorf("deployments[%s].plugin.type is required", index)
 		}
 		if deployConfig.Kubectl != nil && deployConfig.Kubectl.Manifests == nil && deployConfig.Kubectl.InlineManifest == "" {
 			return errors.Errorf("deployments[%s].kubectl.manifests or deployments[%s].kubectl.InlineManifest is required", index, index)
@@ -264,9 +330,86 @@ func validateDeployments(config *latest.Config) error {
 		if deployConfig.Kubectl != nil && deployConfig.Kubectl.Manifests != nil && deployConfig.Kubectl.InlineManifest != "" {
 			return errors.Errorf("deployments[%s].kubectl.manifests and deployments[%s].kubectl.inlineManifest cannot be used together", index, index)
 		}
+		if deployConfig.Kubectl != nil && (deployConfig.Kubectl.ServerSideApply == nil || !*deployConfig.Kubectl.ServerSideApply) {
+			if deployConfig.Kubectl.FieldManager != "" {
+				return errors.Errorf("deployments[%s].kubectl.fieldManager can only be used with serverSideApply", index)
+			}
+			if deployConfig.Kubectl.ForceConflicts != nil && *deployConfig.Kubectl.ForceConflicts {
+				return errors.Errorf("deployments[%s].kubectl.forceConflicts can only be used with serverSideApply", index)
+			}
+		}
 		if deployConfig.Kubectl != nil && deployConfig.Helm != nil {
 			return errors.Errorf("deployments[%s].kubectl and deployments[%s].helm cannot be used together", index, index)
 		}
+		if deployConfig.Wait != nil {
+			if deployConfig.Wait.Timeout < 0 {
+				return errors.Errorf("deployments[%s].wait.timeout cannot be negative", index)
+			}
+			for i, condition := range deployConfig.Wait.Conditions {
+				if len(condition.LabelSelector) == 0 {
+					return errors.Errorf("deployments[%s].wait.conditions[%d].labelSelector is required", index, i)
+				}
+				if condition.JSONPath == "" {
+					return errors.Errorf("deployments[%s].wait.conditions[%d].jsonPath is required", index, i)
+				}
+				if _, err := yamlpath.NewPath(condition.JSONPath); err != nil {
+					return errors.Errorf("deployments[%s].wait.conditions[%d].jsonPath is invalid: %v", index, i, err)
+				}
+			}
+		}
+		for i, need := range deployConfig.Needs {
+			if need == index {
+				return errors.Errorf("deployments[%s].needs[%d]: deployment cannot need itself", index, i)
+			} else if config.Deployments[need] == nil {
+				return errors.Errorf("deployments[%s].needs[%d]: deployment %s does not exist", index, i, need)
+			}
+		}
+		if deployConfig.KubeContext != "" && len(deployConfig.KubeContexts) > 0 {
+			return errors.Errorf("deployments[%s].kubeContext and deployments[%s].kubeContexts cannot be used together", index, index)
+		}
+		seenKubeContexts := map[string]bool{}
+		for i, kubeContext := range deployConfig.KubeContexts {
+			if kubeContext == "" {
+				return errors.Errorf("deployments[%s].kubeContexts[%d] is empty", index, i)
+			} else if seenKubeContexts[kubeContext] {
+				return errors.Errorf("deployments[%s].kubeContexts[%d]: kube context %s is defined twice", index, i, kubeContext)
+			}
+
+			seenKubeContexts[kubeContext] = true
+		}
+		if deployConfig.Canary != nil {
+			if deployConfig.Plugin != nil {
+				return errors.Errorf("deployments[%s].canary cannot be used together with plugin", index)
+			}
+			if deployConfig.Canary.Percentage < 0 || deployConfig.Canary.Percentage >= 100 {
+				return errors.Errorf("deployments[%s].canary.percentage has to be between 1 and 99", index)
+			}
+			if deployConfig.Canary.Timeout < 0 {
+				return errors.Errorf("deployments[%s].canary.timeout cannot be negative", index)
+			}
+		}
+		if deployConfig.Helm != nil && deployConfig.Helm.PostRenderer != nil {
+			postRenderer := deployConfig.Helm.PostRenderer
+			if (postRenderer.Command == "") == (postRenderer.Kustomize == nil) {
+				return errors.Errorf("deployments[%s].helm.postRenderer needs either command or kustomize", index)
+			}
+			if postRenderer.Command == "" && len(postRenderer.Args) > 0 {
+				return errors.Errorf("deployments[%s].helm.postRenderer.args can only be used with command", index)
+			}
+			if postRenderer.Kustomize != nil && len(postRenderer.Kustomize.Patches) == 0 && len(postRenderer.Kustomize.Components) == 0 {
+				return errors.Errorf("deployments[%s].helm.postRenderer.kustomize needs at least one patch or component", index)
+			}
+		}
+		if deployConfig.Helm != nil {
+			for imageName, override := range deployConfig.Helm.ImagesOverride {
+				if config.Images[imageName] == nil {
+					return errors.Errorf("deployments[%s].helm.imagesOverride.%s has to be the name of an image in the images section", index, imageName)
+				}
+				if override == nil || (override.Image == "" && override.Repository == "" && override.Tag == "" && override.Digest == "") {
+					return errors.Errorf("deployments[%s].helm.imagesOverride.%s needs at least one of image, repository, tag or digest", index, imageName)
+				}
+			}
+		}
 		if deployConfig.Kubectl != nil && deployConfig.Kubectl.Patches != nil {
 			for patch := range deployConfig.Kubectl.Patches {
 				if deployConfig.Kubectl.Patches[patch].Target.Name == "" {
@@ -319,6 +462,12 @@ func validatePullSecrets(config *latest.Config) error {
 	return nil
 }
 
+var resourcesPresets = []string{latest.PodResourcesPresetSmall, latest.PodResourcesPresetMedium, latest.PodResourcesPresetLarge}
+
+func validResourcesPreset(preset string) bool {
+	return preset == "" || stringutil.Contains(resourcesPresets, preset)
+}
+
 func validateImages(config *latest.Config) error {
 	// images lists all the image names in order to check for duplicates
 	images := map[string]bool{}
@@ -338,6 +487,21 @@ func validateImages(config *latest.Config) error {
 		if imageConf.Custom != nil && imageConf.Custom.Command == "" && len(imageConf.Custom.Commands) == 0 {
 			return errors.Errorf("images.%s.build.custom.command or images.%s.build.custom.commands is required", imageConfigName, imageConfigName)
 		}
+		if imageConf.Custom != nil && imageConf.Custom.Protocol != "" && imageConf.Custom.Protocol != latest.CustomProtocolJSON {
+			return errors.Errorf("images.%s.custom.protocol %s is invalid. Please choose one of %v", imageConfigName, imageConf.Custom.Protocol, []string{latest.CustomProtocolJSON})
+		}
+		if imageConf.Kaniko != nil && imageConf.Kaniko.Resources != nil && !validResourcesPreset(imageConf.Kaniko.Resources.Preset) {
+			return errors.Errorf("images.%s.kaniko.resources.preset %s is invalid. Please choose one of %v", imageConfigName, imageConf.Kaniko.Resources.Preset, resourcesPresets)
+		}
+		if imageConf.BuildKit != nil && imageConf.BuildKit.InCluster != nil && imageConf.BuildKit.InCluster.Resources != nil && !validResourcesPreset(imageConf.BuildKit.InCluster.Resources.Preset) {
+			return errors.Errorf("images.%s.buildKit.inCluster.resources.preset %s is invalid. Please choose one of %v", imageConfigName, imageConf.BuildKit.InCluster.Resources.Preset, resourcesPresets)
+		}
+		if imageConf.BuildKit != nil && imageConf.BuildKit.Remote != nil && imageConf.BuildKit.Remote.Address == "" {
+			return errors.Errorf("images.%s.buildKit.remote.address is required", imageConfigName)
+		}
+		if imageConf.Plugin != nil && imageConf.Plugin.Type == "" {
+			return errors.Errorf("images.%s.plugin.type is required", imageConfigName)
+		}
 		if images[imageConf.Image] {
 			return errors.Errorf("multiple image definitions with the same image name are not allowed")
 		}
@@ -357,6 +521,28 @@ func validateImages(config *latest.Config) error {
 				}
 			}
 		}
+		if imageConf.Scan != nil {
+			// the other builders push the image while building it, so it couldn't be scanned before the push
+			if imageConf.Custom != nil || imageConf.Plugin != nil || imageConf.BuildKit != nil || (imageConf.Kaniko != nil && imageConf.Docker == nil) {
+				return errors.Errorf("images.%s.scan is only supported for images that are built with docker", imageConfigName)
+			}
+			if imageConf.Scan.Scanner != "" && imageConf.Scan.Scanner != "trivy" && imageConf.Scan.Scanner != "grype" {
+				return errors.Errorf("images.%s.scan.scanner %s is invalid. Please choose one of %v", imageConfigName, imageConf.Scan.Scanner, []string{"trivy", "grype"})
+			}
+			for key, severity := range map[string]string{"failOn": imageConf.Scan.FailOn, "warnOn": imageConf.Scan.WarnOn} {
+				if severity != "" && severity != "low" && severity != "medium" && severity != "high" && severity != "critical" {
+					return errors.Errorf("images.%s.scan.%s %s is invalid. Please choose one of %v", imageConfigName, key, severity, []string{"low", "medium", "high", "critical"})
+				}
+			}
+		}
+		for i, secret := range imageConf.Secrets {
+			if secret == nil || encoding.IsUnsafeUpperName(secret.ID) {
+				return errors.Errorf("images.%s.secrets[%d].id has to match the following regex: %v", imageConfigName, i, encoding.UnsafeUpperNameRegEx.String())
+			}
+			if (secret.Env == "") == (secret.File == "") {
+				return errors.Errorf("images.%s.secrets[%d] needs either env or file", imageConfigName, i)
+			}
+		}
 		images[imageConf.Image] = true
 	}
 
@@ -30,6 +30,47 @@ func TestValidateImageName(t *testing.T) {
 	assert.Error(t, err, "images.default.image 'localhost:5000/node:latest' can not have tag 'latest'")
 }
 
+func TestValidateImageSecrets(t *testing.T) {
+	secrets := map[string]*latest.BuildSecret{
+		"": {ID: "npmrc", File: ".npmrc"},
+		"images.default.secrets[0] needs either env or file": {ID: "npmrc", Env: "NPM_TOKEN", File: ".npmrc"},
+		"images.default.secrets[0].id has to match":          {ID: "npm/rc", Env: "NPM_TOKEN"},
+	}
+	for expectedErr, secret := range secrets {
+		err := validateImages(&latest.Config{
+			Images: map[string]*latest.Image{
+				"default": {
+					Image:   "localhost:5000/node",
+					Secrets: []*latest.BuildSecret{secret},
+				},
+			},
+		})
+		if expectedErr == "" {
+			assert.NilError(t, err)
+		} else {
+			assert.ErrorContains(t, err, expectedErr)
+		}
+	}
+}
+
+func TestValidateImageScan(t *testing.T) {
+	images := map[string]*latest.Image{
+		"":                           {Image: "localhost:5000/node", Scan: &latest.ImageScan{}},
+		"images.default.scan.failOn": {Image: "localhost:5000/node", Scan: &latest.ImageScan{FailOn: "severe"}},
+		"images.default.scan is only supported for images that are built with docker": {Image: "localhost:5000/node", Scan: &latest.ImageScan{}, Kaniko: &latest.KanikoConfig{}},
+	}
+	for expectedErr, image := range images {
+		err := validateImages(&latest.Config{
+			Images: map[string]*latest.Image{"default": image},
+		})
+		if expectedErr == "" {
+			assert.NilError(t, err)
+		} else {
+			assert.ErrorContains(t, err, expectedErr)
+		}
+	}
+}
+
 func TestValidateHooks(t *testing.T) {
 	config := &latest.Config{
 		Hooks: []*latest.HookConfig{
@@ -173,3 +214,54 @@ func TestValidateDev(t *testing.T) {
 	err = validateDev(config)
 	assert.Error(t, err, "dev.somename.reversePorts will be overwritten by dev.somename.containers[test], please specify dev.somename.containers[test].reversePorts instead")
 }
+
+func TestValidateDeploymentKubeContexts(t *testing.T) {
+	deployments := map[string]*latest.DeploymentConfig{
+		"": {KubeContexts: []string{"eu", "us"}},
+		"deployments[default].kubeContext and deployments[default].kubeContexts cannot be used together": {KubeContext: "eu", KubeContexts: []string{"us"}},
+		"deployments[default].kubeContexts[1] is empty":                                                  {KubeContexts: []string{"eu", ""}},
+		"deployments[default].kubeContexts[1]: kube context eu is defined twice":                         {KubeContexts: []string{"eu", "eu"}},
+	}
+	for expectedErr, deployConfig := range deployments {
+		deployConfig.Name = "default"
+		deployConfig.Kubectl = &latest.KubectlConfig{Manifests: []string{"kube"}}
+		err := validateDeployments(&latest.Config{
+			Deployments: map[string]*latest.DeploymentConfig{
+				"default": deployConfig,
+			},
+		})
+		if expectedErr == "" {
+			assert.NilError(t, err)
+		} else {
+			assert.ErrorContains(t, err, expectedErr)
+		}
+	}
+}
+
+func TestValidateDeploymentNeeds(t *testing.T) {
+	needs := map[string][]string{
+		"": {"database"},
+		"deployments[default].needs[0]: deployment cannot need itself":   {"default"},
+		"deployments[default].needs[1]: deployment cache does not exist": {"database", "cache"},
+	}
+	for expectedErr, need := range needs {
+		err := validateDeployments(&latest.Config{
+			Deployments: map[string]*latest.DeploymentConfig{
+				"default": {
+					Name:    "default",
+					Kubectl: &latest.KubectlConfig{Manifests: []string{"kube"}},
+					Needs:   need,
+				},
+				"database": {
+					Name:    "database",
+					Kubectl: &latest.KubectlConfig{Manifests: []string{"database"}},
+				},
+			},
+		})
+		if expectedErr == "" {
+			assert.NilError(t, err)
+		} else {
+			assert.ErrorContains(t, err, expectedErr)
+		}
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/context/values"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/loft-sh/devspace/pkg/devspace/pipeline/env"
@@ -86,6 +88,7 @@ type Context interface {
 	ResolvePath(relPath string) string
 
 	WithNewTomb() (Context, *tomb.Tomb)
+	WithTimeout(timeout time.Duration) (Context, context2.CancelFunc)
 	WithKubeClient(client kubectl.Client) Context
 	WithWorkingDir(workingDir string) Context
 	WithConfig(conf config.Config) Context
@@ -189,6 +192,32 @@ func (c *context) WithNewTomb() (Context, *tomb.Tomb) {
 	return &n, t
 }
 
+// WithTimeout returns a copy of the context that is cancelled after the timeout. If the timeout triggers,
+// the dev configurations that were started with the returned context are stopped as well
+func (c *context) WithTimeout(timeout time.Duration) (Context, context2.CancelFunc) {
+	if c == nil {
+		return nil, func() {}
+	}
+
+	timeoutCtx, cancel := context2.WithTimeout(c.context, timeout)
+	devCtx, ok := values.DevContextFrom(c.context)
+	if !ok {
+		return c.WithContext(timeoutCtx), cancel
+	}
+
+	// the dev context outlives the context, so that dev configurations keep running after the
+	// context is done, unless the context was done because of the timeout
+	devCtx, cancelDevCtx := context2.WithCancel(devCtx)
+	go func() {
+		<-timeoutCtx.Done()
+		if timeoutCtx.Err() == context2.DeadlineExceeded {
+			cancelDevCtx()
+		}
+	}()
+
+	return c.WithContext(values.WithDevContext(timeoutCtx, devCtx)), cancel
+}
+
 func (c *context) ResolvePath(relPath string) string {
 	if relPath == "" {
 		return c.workingDir
@@ -18,6 +18,7 @@ const (
 	devContextKey
 	flagsKey
 	commandFlagsKey
+	protectedDependencyKey
 )
 
 // WithFlagsMap creates a new context with the given flags
@@ -106,6 +107,17 @@ func IsDependencyFrom(ctx context.Context) (bool, bool) {
 	return isDependency, ok
 }
 
+// WithProtectedDependency marks that the pipeline runs within the protected dependency
+func WithProtectedDependency(parent context.Context, name string) context.Context {
+	return WithValue(parent, protectedDependencyKey, name)
+}
+
+// ProtectedDependencyFrom returns the name of the protected dependency the pipeline runs within
+func ProtectedDependencyFrom(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(protectedDependencyKey).(string)
+	return name, ok
+}
+
 func mergeFlags(maps ...map[string]string) map[string]string {
 	merged := map[string]string{}
 	for _, m := range maps {
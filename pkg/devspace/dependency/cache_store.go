@@ -0,0 +1,88 @@
+package dependency
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// storedCache are the parts of the local cache of a dependency that are kept in a cache store, which are
+// the built images and the fingerprints. Variables and the last kube context are left out, because a store
+// such as a config map can be read by everyone with access to the namespace
+type storedCache struct {
+	Images map[string]localcache.ImageCache `yaml:"images,omitempty"`
+	Data   map[string]string                `yaml:"data,omitempty"`
+}
+
+// RestoreCache writes the stored cache of the dependency to its local cache file, if the
+// dependency has no local cache yet. Existing local caches are never overwritten
+func RestoreCache(ctx context.Context, store types.CacheStore, name, configPath string) error {
+	if store == nil {
+		return nil
+	}
+
+	cachePath := localcache.CachePath(configPath)
+	_, err := os.Stat(cachePath)
+	if err == nil {
+		return nil
+	}
+
+	cache, err := store.Load(ctx, name)
+	if err != nil {
+		return err
+	} else if cache == nil {
+		return nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(cachePath), 0755)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(cachePath, cache, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "restore cache of dependency %s", name)
+	}
+
+	return nil
+}
+
+// StoreCache saves the local cache of the dependency and copies the images and fingerprints of the
+// cache file into the store
+func StoreCache(ctx context.Context, store types.CacheStore, dependency types.Dependency) error {
+	if store == nil || dependency.Config() == nil || dependency.Config().LocalCache() == nil {
+		return nil
+	}
+
+	err := dependency.Config().LocalCache().Save()
+	if err != nil {
+		return err
+	}
+
+	cache, err := os.ReadFile(localcache.CachePath(dependency.Config().Path()))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	stored := &storedCache{}
+	err = yaml.Unmarshal(cache, stored)
+	if err != nil {
+		return errors.Wrapf(err, "parse cache of dependency %s", dependency.Name())
+	}
+
+	cache, err = yaml.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	return store.Save(ctx, dependency.Name(), cache)
+}
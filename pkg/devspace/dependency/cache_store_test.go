@@ -0,0 +1,66 @@
+package dependency
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/cachestore"
+	"gotest.tools/assert"
+)
+
+func TestCacheStore(t *testing.T) {
+	dir := t.TempDir()
+	store := cachestore.NewDirectoryStore(filepath.Join(dir, "store"))
+	configPath := filepath.Join(dir, "backend", "devspace.yaml")
+	cachePath := localcache.CachePath(configPath)
+
+	// nothing to restore
+	assert.NilError(t, RestoreCache(context.Background(), store, "backend", configPath))
+	_, err := os.Stat(cachePath)
+	assert.Assert(t, os.IsNotExist(err))
+
+	// store the local cache of the dependency
+	cache := localcache.New(cachePath)
+	cache.SetData(fingerprintKey, "abc")
+	cache.SetImageCache("api", localcache.ImageCache{ImageName: "api", Tag: "v1"})
+	cache.SetVar("DB_PASSWORD", "secret")
+	cache.SetLastContext(&localcache.LastContextConfig{Context: "prod", Namespace: "backend"})
+	dependency := &Dependency{
+		name:        "backend",
+		localConfig: config.NewConfig(nil, nil, &latest.Config{}, cache, &remotecache.RemoteCache{}, nil, configPath),
+	}
+	assert.NilError(t, StoreCache(context.Background(), store, dependency))
+
+	// a clean checkout restores the stored cache
+	assert.NilError(t, os.RemoveAll(filepath.Dir(cachePath)))
+	assert.NilError(t, RestoreCache(context.Background(), store, "backend", configPath))
+	restored, err := localcache.NewCacheLoader().Load(configPath)
+	assert.NilError(t, err)
+	fingerprint, _ := restored.GetData(fingerprintKey)
+	assert.Equal(t, fingerprint, "abc")
+	imageCache, _ := restored.GetImageCache("api")
+	assert.Equal(t, imageCache.Tag, "v1")
+
+	// variables and the kube context are never stored
+	stored, err := store.Load(context.Background(), "backend")
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(stored), "secret"), string(stored))
+	assert.Assert(t, !strings.Contains(string(stored), "prod"), string(stored))
+	assert.Equal(t, len(restored.ListVars()), 0)
+	assert.Assert(t, restored.GetLastContext() == nil)
+
+	// existing local caches are kept
+	assert.NilError(t, os.WriteFile(cachePath, []byte("data:\n  dependencyFingerprint: local\n"), 0644))
+	assert.NilError(t, RestoreCache(context.Background(), store, "backend", configPath))
+	restored, err = localcache.NewCacheLoader().Load(configPath)
+	assert.NilError(t, err)
+	fingerprint, _ = restored.GetData(fingerprintKey)
+	assert.Equal(t, fingerprint, "local")
+}
@@ -0,0 +1,33 @@
+package cachestore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+)
+
+// Parse creates the cache store for the given value. Supported are configmap:<name>, which
+// stores the caches in a config map in the current namespace, and dir:<path>, which stores
+// the caches in a local folder that can be synced with a bucket or the cache of a CI system
+func Parse(value string, client kubectl.Client) (types.CacheStore, error) {
+	splitted := strings.SplitN(value, ":", 2)
+	if len(splitted) != 2 || splitted[1] == "" {
+		return nil, fmt.Errorf("invalid dependency cache store %s, expected configmap:<name> or dir:<path>", value)
+	}
+
+	kind, location := splitted[0], splitted[1]
+	switch kind {
+	case "configmap":
+		if client == nil {
+			return nil, fmt.Errorf("dependency cache store %s requires a kube context", value)
+		}
+
+		return NewConfigMapStore(client, location), nil
+	case "dir":
+		return NewDirectoryStore(location), nil
+	}
+
+	return nil, fmt.Errorf("unknown dependency cache store %s, expected configmap:<name> or dir:<path>", kind)
+}
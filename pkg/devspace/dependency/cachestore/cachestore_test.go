@@ -0,0 +1,47 @@
+package cachestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	fakekubectl "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"gotest.tools/assert"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStores(t *testing.T) {
+	client := &fakekubectl.Client{Client: fake.NewSimpleClientset()}
+	stores := map[string]types.CacheStore{}
+	for _, value := range []string{"configmap:devspace-dependencies", "dir:" + t.TempDir()} {
+		store, err := Parse(value, client)
+		assert.NilError(t, err)
+		stores[value] = store
+	}
+
+	for value, store := range stores {
+		cache, err := store.Load(context.Background(), "backend")
+		assert.NilError(t, err, value)
+		assert.Assert(t, cache == nil, value)
+
+		assert.NilError(t, store.Save(context.Background(), "backend", []byte("images: {}")), value)
+		assert.NilError(t, store.Save(context.Background(), "frontend", []byte("vars: {}")), value)
+		assert.NilError(t, store.Save(context.Background(), "backend", []byte("data: {}")), value)
+
+		cache, err = store.Load(context.Background(), "backend")
+		assert.NilError(t, err, value)
+		assert.Equal(t, string(cache), "data: {}", value)
+		cache, err = store.Load(context.Background(), "frontend")
+		assert.NilError(t, err, value)
+		assert.Equal(t, string(cache), "vars: {}", value)
+	}
+}
+
+func TestParse(t *testing.T) {
+	_, err := Parse("configmap:devspace-dependencies", nil)
+	assert.ErrorContains(t, err, "requires a kube context")
+	_, err = Parse("s3:bucket", nil)
+	assert.ErrorContains(t, err, "unknown dependency cache store s3")
+	_, err = Parse("dir", nil)
+	assert.ErrorContains(t, err, "invalid dependency cache store dir")
+}
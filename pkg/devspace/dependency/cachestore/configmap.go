@@ -0,0 +1,96 @@
+package cachestore
+
+import (
+	"context"
+	"sync"
+
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// configMapRetries is how often a config map update is retried if somebody else
+// updated the config map in the meantime
+const configMapRetries = 5
+
+type configMapStore struct {
+	m sync.Mutex
+
+	client kubectl.Client
+	name   string
+}
+
+// NewConfigMapStore returns a cache store that keeps the caches of all dependencies
+// in a single config map in the namespace of the client
+func NewConfigMapStore(client kubectl.Client, name string) types.CacheStore {
+	return &configMapStore{
+		client: client,
+		name:   name,
+	}
+}
+
+func (c *configMapStore) Load(ctx context.Context, name string) ([]byte, error) {
+	configMap, err := c.client.KubeClient().CoreV1().ConfigMaps(c.client.Namespace()).Get(ctx, c.name, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "load cache of dependency %s", name)
+	} else if configMap.Data[name] == "" {
+		return nil, nil
+	}
+
+	return []byte(configMap.Data[name]), nil
+}
+
+func (c *configMapStore) Save(ctx context.Context, name string, cache []byte) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	err := c.save(ctx, name, cache, configMapRetries)
+	if err != nil {
+		return errors.Wrapf(err, "save cache of dependency %s", name)
+	}
+
+	return nil
+}
+
+func (c *configMapStore) save(ctx context.Context, name string, cache []byte, retries int) error {
+	configMaps := c.client.KubeClient().CoreV1().ConfigMaps(c.client.Namespace())
+	configMap, err := configMaps.Get(ctx, c.name, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+
+		_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.name,
+				Namespace: c.client.Namespace(),
+			},
+			Data: map[string]string{
+				name: string(cache),
+			},
+		}, metav1.CreateOptions{})
+		if kerrors.IsAlreadyExists(err) && retries > 0 {
+			return c.save(ctx, name, cache, retries-1)
+		}
+
+		return err
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[name] = string(cache)
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	if kerrors.IsConflict(err) && retries > 0 {
+		return c.save(ctx, name, cache, retries-1)
+	}
+
+	return err
+}
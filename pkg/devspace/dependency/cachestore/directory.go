@@ -0,0 +1,53 @@
+package cachestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/pkg/errors"
+)
+
+type directoryStore struct {
+	path string
+}
+
+// NewDirectoryStore returns a cache store that keeps the cache of every dependency
+// as a single file in the given folder
+func NewDirectoryStore(path string) types.CacheStore {
+	return &directoryStore{
+		path: path,
+	}
+}
+
+func (d *directoryStore) Load(ctx context.Context, name string) ([]byte, error) {
+	cache, err := os.ReadFile(d.file(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "load cache of dependency %s", name)
+	}
+
+	return cache, nil
+}
+
+func (d *directoryStore) Save(ctx context.Context, name string, cache []byte) error {
+	err := os.MkdirAll(d.path, 0755)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(d.file(name), cache, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "save cache of dependency %s", name)
+	}
+
+	return nil
+}
+
+func (d *directoryStore) file(name string) string {
+	return filepath.Join(d.path, name+".yaml")
+}
@@ -0,0 +1,70 @@
+package dependency
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine"
+	"github.com/pkg/errors"
+	"mvdan.cc/sh/v3/interp"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// evaluateCondition checks if the when condition of a dependency is true. The condition is evaluated
+// as shell test expression (e.g. "${DEVSPACE_PROFILE} != 'minimal'") within the given directory. An
+// empty condition is always true
+func evaluateCondition(ctx devspacecontext.Context, dir, when string) (bool, error) {
+	when = strings.TrimSpace(when)
+	if when == "" {
+		return true, nil
+	} else if value, err := strconv.ParseBool(when); err == nil {
+		return value, nil
+	}
+
+	script, err := anchorRegexMatches("[[ " + when + " ]]")
+	if err != nil {
+		return false, errors.Errorf("evaluate condition %s: %v", when, err)
+	}
+
+	stderr := &bytes.Buffer{}
+	err = engine.ExecuteSimpleShellCommand(ctx.Context(), dir, ctx.Environ(), nil, stderr, nil, script)
+	if err != nil {
+		if status, ok := interp.IsExitStatus(err); ok && status == 1 && stderr.Len() == 0 {
+			return false, nil
+		}
+
+		return false, errors.Errorf("evaluate condition %s: %v %s", when, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return true, nil
+}
+
+// anchorRegexMatches changes every =~ match of the script to match the whole value, like the
+// conditions of hooks do, e.g. ${PROFILE} =~ dev matches dev, but not development
+func anchorRegexMatches(script string) (string, error) {
+	file, err := syntax.NewParser().Parse(strings.NewReader(script), "")
+	if err != nil {
+		return "", err
+	}
+
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if test, ok := node.(*syntax.BinaryTest); ok && test.Op == syntax.TsReMatch {
+			if word, ok := test.Y.(*syntax.Word); ok {
+				parts := []syntax.WordPart{&syntax.Lit{Value: "^(?:"}}
+				parts = append(parts, word.Parts...)
+				word.Parts = append(parts, &syntax.Lit{Value: ")$"})
+			}
+		}
+		return true
+	})
+
+	out := &bytes.Buffer{}
+	err = syntax.NewPrinter().Print(out, file)
+	if err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
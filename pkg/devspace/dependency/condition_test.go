@@ -0,0 +1,39 @@
+package dependency
+
+import (
+	"context"
+	"testing"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	ctx := devspacecontext.NewContext(context.Background(), map[string]interface{}{
+		"PROFILE": "minimal",
+	}, log.Discard)
+
+	testCases := map[string]bool{
+		"":                              true,
+		"true":                          true,
+		"false":                         false,
+		"minimal != 'minimal'":          false,
+		"full != 'minimal'":             true,
+		"${PROFILE} == 'minimal'":       true,
+		"${PROFILE} == min* && 1 -eq 1": true,
+		"${PROFILE} == 'full'":          false,
+		"${PROFILE} =~ min":             false,
+		"${PROFILE} =~ min.*":           true,
+		"${PROFILE} =~ full|minimal":    true,
+		"${PROFILE} =~ \"min\".*":       true,
+	}
+	for when, expected := range testCases {
+		enabled, err := evaluateCondition(ctx, t.TempDir(), when)
+		assert.NilError(t, err, when)
+		assert.Equal(t, enabled, expected, when)
+	}
+
+	_, err := evaluateCondition(ctx, t.TempDir(), "'unterminated")
+	assert.ErrorContains(t, err, "evaluate condition")
+}
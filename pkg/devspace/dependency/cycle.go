@@ -0,0 +1,118 @@
+package dependency
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/graph"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"gopkg.in/yaml.v3"
+)
+
+// CycleError is returned if dependencies reference each other and the cycle policy is fail. Besides
+// the cycle itself, it names the dependencies entry that closes the cycle and the dependencies that
+// could be skipped to break it
+type CycleError struct {
+	// From is the dependency whose config references To, which closes the cycle
+	From string
+	To   string
+
+	// ConfigPath and Line point to the entry of To in the dependencies of From. Line is 0 if the
+	// entry could not be found, e.g. because it was discovered through a workspace
+	ConfigPath string
+	Line       int
+
+	// SkipCandidates are the dependencies in the cycle that can be skipped with --skip-dependency
+	SkipCandidates []string
+
+	err *graph.CyclicError
+}
+
+// newCycleError creates a cycle error for the rejected edge from the dependency declared in the
+// config at the given path. The root project can't be skipped and is no skip candidate
+func newCycleError(err *graph.CyclicError, root, from, to, configPath string) *CycleError {
+	cycleError := &CycleError{
+		From:       from,
+		To:         to,
+		ConfigPath: configPath,
+		Line:       dependencyLine(configPath, to),
+		err:        err,
+	}
+
+	seen := map[string]bool{root: true}
+	for _, name := range err.Cycle() {
+		if !seen[name] {
+			seen[name] = true
+			cycleError.SkipCandidates = append(cycleError.SkipCandidates, name)
+		}
+	}
+
+	return cycleError
+}
+
+// Error implements error interface
+func (c *CycleError) Error() string {
+	message := []string{c.err.Error()}
+	if c.Line > 0 {
+		message = append(message, fmt.Sprintf("The cycle is closed by dependencies.%s of %s at %s:%d", c.To, c.From, c.ConfigPath, c.Line))
+	} else {
+		message = append(message, fmt.Sprintf("The cycle is closed by dependency %s of %s in %s", c.To, c.From, c.ConfigPath))
+	}
+	if len(c.SkipCandidates) > 0 {
+		skipFlags := []string{}
+		for _, name := range c.SkipCandidates {
+			skipFlags = append(skipFlags, "--skip-dependency "+name)
+		}
+
+		message = append(message, fmt.Sprintf("To break the cycle, skip one of the dependencies with %s, or use --dependency-cycle-policy %s", strings.Join(skipFlags, ", "), types.CyclePolicyIgnoreBackEdge))
+	}
+
+	return strings.Join(message, "\n")
+}
+
+// Unwrap returns the cyclic error of the dependency graph
+func (c *CycleError) Unwrap() error {
+	return c.err
+}
+
+// dependencyLine returns the line of the given entry in the dependencies of the config file
+// or 0 if the file or the entry doesn't exist
+func dependencyLine(configPath, name string) int {
+	out, err := os.ReadFile(configPath)
+	if err != nil {
+		return 0
+	}
+
+	document := &yaml.Node{}
+	err = yaml.Unmarshal(out, document)
+	if err != nil || len(document.Content) == 0 {
+		return 0
+	}
+
+	dependencies := mappingValue(document.Content[0], "dependencies")
+	if dependencies == nil {
+		return 0
+	}
+	for i := 0; i+1 < len(dependencies.Content); i += 2 {
+		if dependencies.Content[i].Value == name {
+			return dependencies.Content[i].Line
+		}
+	}
+
+	return 0
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
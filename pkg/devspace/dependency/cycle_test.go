@@ -0,0 +1,44 @@
+package dependency
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/graph"
+	"gotest.tools/assert"
+)
+
+func TestCycleError(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "devspace.yaml")
+	assert.NilError(t, os.WriteFile(configPath, []byte(`version: v2beta1
+name: backend
+dependencies:
+  database:
+    path: ../database
+  api:
+    path: ../api
+`), 0644))
+
+	dependencyGraph := graph.NewGraph(graph.NewNode("root", nil))
+	_, _ = dependencyGraph.InsertNodeAt("root", "api", nil)
+	_, _ = dependencyGraph.InsertNodeAt("api", "backend", nil)
+	err := dependencyGraph.AddEdge("backend", "api")
+	cyclicErr, ok := err.(*graph.CyclicError)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, cyclicErr.Cycle(), []string{"backend", "api", "backend"})
+
+	cycleErr := newCycleError(cyclicErr, "root", "backend", "api", configPath)
+	assert.Equal(t, cycleErr.Line, 6)
+	assert.DeepEqual(t, cycleErr.SkipCandidates, []string{"backend", "api"})
+	assert.ErrorContains(t, cycleErr, "dependencies.api of backend at "+configPath+":6")
+	assert.ErrorContains(t, cycleErr, "--skip-dependency backend, --skip-dependency api")
+	assert.Assert(t, errors.Is(cycleErr, cyclicErr))
+
+	// entries that are not in the config file have no line
+	cycleErr = newCycleError(cyclicErr, "root", "backend", "web", configPath)
+	assert.Equal(t, cycleErr.Line, 0)
+	assert.ErrorContains(t, cycleErr, "dependency web of backend in "+configPath)
+}
@@ -2,6 +2,7 @@ package dependency
 
 import (
 	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
 	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
@@ -20,6 +21,9 @@ type Dependency struct {
 	dependencyConfig *latest.DependencyConfig
 	dependencyCache  localcache.Cache
 
+	// configOptions are the options the config of the dependency was loaded with
+	configOptions *loader.ConfigOptions
+
 	kubeClient kubectl.Client
 }
 
@@ -48,8 +52,8 @@ func skipDependency(name string, skipDependencies []string) bool {
 	return false
 }
 
-func foundDependency(name string, dependencies []string) bool {
-	if len(dependencies) == 0 {
+func foundDependency(dependency types.Dependency, name string, dependencies []string, tags []string) bool {
+	if len(dependencies) == 0 && len(tags) == 0 {
 		return true
 	}
 
@@ -59,5 +63,21 @@ func foundDependency(name string, dependencies []string) bool {
 		}
 	}
 
+	return hasTag(dependency, tags)
+}
+
+func hasTag(dependency types.Dependency, tags []string) bool {
+	if dependency.DependencyConfig() == nil {
+		return false
+	}
+
+	for _, tag := range dependency.DependencyConfig().Tags {
+		for _, t := range tags {
+			if t == tag {
+				return true
+			}
+		}
+	}
+
 	return false
 }
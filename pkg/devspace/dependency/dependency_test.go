@@ -0,0 +1,25 @@
+package dependency
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"gotest.tools/assert"
+)
+
+func TestFoundDependency(t *testing.T) {
+	dependency := &Dependency{
+		name: "database",
+		dependencyConfig: &latest.DependencyConfig{
+			Name: "database",
+			Tags: []string{"backend", "infra"},
+		},
+	}
+
+	assert.Assert(t, foundDependency(dependency, "database", nil, nil))
+	assert.Assert(t, foundDependency(dependency, "database", []string{"database"}, nil))
+	assert.Assert(t, !foundDependency(dependency, "database", []string{"api"}, nil))
+	assert.Assert(t, foundDependency(dependency, "database", nil, []string{"infra"}))
+	assert.Assert(t, foundDependency(dependency, "database", []string{"api"}, []string{"infra"}))
+	assert.Assert(t, !foundDependency(dependency, "database", nil, []string{"frontend"}))
+}
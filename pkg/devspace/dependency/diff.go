@@ -0,0 +1,255 @@
+package dependency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/git"
+	"github.com/pkg/errors"
+)
+
+// TreeDiff describes how the dependency tree changed between two revisions
+type TreeDiff struct {
+	Added   []TreeNode       `json:"added,omitempty"`
+	Removed []TreeNode       `json:"removed,omitempty"`
+	Changed []TreeNodeChange `json:"changed,omitempty"`
+}
+
+// TreeNodeChange is a dependency that exists in both revisions, but has changed
+type TreeNodeChange struct {
+	Name   string   `json:"name"`
+	Before TreeNode `json:"before"`
+	After  TreeNode `json:"after"`
+
+	// Reasons describes what has changed
+	Reasons []string `json:"reasons"`
+}
+
+// DiffRevision resolves the dependency tree of the project at the given git ref and compares it with the
+// dependency tree of the current working tree. The ref is checked out into a temporary worktree, so the
+// current working tree is not touched
+func DiffRevision(ctx devspacecontext.Context, configOptions *loader.ConfigOptions, ref string) (*TreeDiff, error) {
+	manager := NewManager(ctx, configOptions)
+	_, err := manager.ResolveAll(ctx, ResolveOptions{ReadOnly: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "resolve dependencies")
+	}
+
+	repositoryRoot, err := git.GetRepositoryRoot(ctx.Context(), filepath.Dir(ctx.Config().Path()))
+	if err != nil {
+		return nil, err
+	}
+	relativeConfigPath, err := filepath.Rel(repositoryRoot, ctx.Config().Path())
+	if err != nil {
+		return nil, err
+	}
+
+	tempFolder, err := os.MkdirTemp("", "devspace-diff-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempFolder)
+
+	worktreePath := filepath.Join(tempFolder, "worktree")
+	removeWorktree, err := git.AddWorktree(ctx.Context(), repositoryRoot, ref, worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := removeWorktree()
+		if err != nil {
+			ctx.Log().Debugf("Error removing worktree: %v", err)
+		}
+	}()
+
+	// resolve the dependencies within the worktree
+	var refTree *Tree
+	refConfigPath := filepath.Join(worktreePath, relativeConfigPath)
+	err = executeInDirectory(filepath.Dir(refConfigPath), func() error {
+		configLoader, err := loader.NewConfigLoader(refConfigPath)
+		if err != nil {
+			return err
+		}
+
+		refConfig, err := configLoader.Load(ctx.Context(), ctx.KubeClient(), configOptions, ctx.Log())
+		if err != nil {
+			return errors.Wrapf(err, "load config at %s", ref)
+		}
+
+		refCtx := ctx.WithConfig(refConfig).WithWorkingDir(filepath.Dir(refConfigPath))
+		refManager := NewManager(refCtx, configOptions)
+		_, err = refManager.ResolveAll(refCtx, ResolveOptions{ReadOnly: true})
+		if err != nil {
+			return errors.Wrapf(err, "resolve dependencies at %s", ref)
+		}
+
+		refTree = refManager.Tree()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffTrees(refTree, worktreePath, manager.Tree(), repositoryRoot)
+}
+
+// DiffTrees compares the dependency trees of two revisions. The paths of dependencies within the
+// given root folders are compared relative to them. The files of dependencies that exist in both
+// trees are hashed to find changed contents
+func DiffTrees(before *Tree, beforeRoot string, after *Tree, afterRoot string) (*TreeDiff, error) {
+	diff := &TreeDiff{}
+	beforeNodes := treeNodes(before)
+	afterNodes := treeNodes(after)
+	beforeChildren := treeChildren(before)
+	afterChildren := treeChildren(after)
+	for _, node := range after.Nodes {
+		if node.Root {
+			continue
+		}
+
+		beforeNode, ok := beforeNodes[node.Name]
+		if !ok {
+			diff.Added = append(diff.Added, node)
+			continue
+		}
+
+		reasons := []string{}
+		beforePath, afterPath := relativeTreePath(beforeNode.Path, beforeRoot), relativeTreePath(node.Path, afterRoot)
+		if beforePath != afterPath {
+			reasons = append(reasons, "path has changed")
+		}
+		if !reflect.DeepEqual(beforeNode.Source, node.Source) {
+			reasons = append(reasons, "source has changed")
+		}
+		if beforeNode.Pipeline != node.Pipeline {
+			reasons = append(reasons, "pipeline has changed")
+		}
+		if beforeNode.Namespace != node.Namespace || beforeNode.KubeContext != node.KubeContext {
+			reasons = append(reasons, "target has changed")
+		}
+		if strings.Join(beforeNode.Tags, ",") != strings.Join(node.Tags, ",") {
+			reasons = append(reasons, "tags have changed")
+		}
+		if strings.Join(beforeChildren[node.Name], ",") != strings.Join(afterChildren[node.Name], ",") {
+			reasons = append(reasons, "dependencies have changed")
+		}
+		if beforeNode.Path != "" && node.Path != "" {
+			changed, err := filesChanged(beforeNode.Path, node.Path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "compare files of dependency %s", node.Name)
+			} else if changed {
+				reasons = append(reasons, "files have changed")
+			}
+		}
+
+		if len(reasons) > 0 {
+			diff.Changed = append(diff.Changed, TreeNodeChange{
+				Name:    node.Name,
+				Before:  beforeNode,
+				After:   node,
+				Reasons: reasons,
+			})
+		}
+	}
+	for _, node := range before.Nodes {
+		if _, ok := afterNodes[node.Name]; !ok && !node.Root {
+			diff.Removed = append(diff.Removed, node)
+		}
+	}
+
+	return diff, nil
+}
+
+func treeNodes(tree *Tree) map[string]TreeNode {
+	nodes := map[string]TreeNode{}
+	for _, node := range tree.Nodes {
+		nodes[node.Name] = node
+	}
+
+	return nodes
+}
+
+// treeChildren returns the sorted names of the children of every node
+func treeChildren(tree *Tree) map[string][]string {
+	children := map[string][]string{}
+	for _, edge := range tree.Edges {
+		children[edge.From] = append(children[edge.From], edge.To)
+	}
+	for _, names := range children {
+		sort.Strings(names)
+	}
+
+	return children
+}
+
+// relativeTreePath returns the path relative to the root, if it is within the root
+func relativeTreePath(path, root string) string {
+	relativePath, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(relativePath, "..") {
+		return path
+	}
+
+	return filepath.ToSlash(relativePath)
+}
+
+// filesChanged compares the contents of both dependency folders. Folders of remote dependencies
+// that are shared by both revisions are the same and never changed
+func filesChanged(beforePath, afterPath string) (bool, error) {
+	if beforePath == afterPath {
+		return false, nil
+	}
+
+	beforeHash, err := contentHash(beforePath)
+	if err != nil {
+		return false, err
+	}
+	afterHash, err := contentHash(afterPath)
+	if err != nil {
+		return false, err
+	}
+
+	return beforeHash != afterHash, nil
+}
+
+// contentHash hashes the relative paths and the contents of all files in the folder, so that
+// the same files in different folders have the same hash. The .git and .devspace folders are ignored
+func contentHash(path string) (string, error) {
+	contents := sha256.New()
+	err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if info.IsDir() && (info.Name() == ".git" || info.Name() == ".devspace") {
+			return filepath.SkipDir
+		} else if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(path, filePath)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, _ = io.WriteString(contents, filepath.ToSlash(relativePath)+";")
+		_, err = io.Copy(contents, file)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(contents.Sum(nil)), nil
+}
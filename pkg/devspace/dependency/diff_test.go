@@ -0,0 +1,81 @@
+package dependency
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"gotest.tools/assert"
+)
+
+func TestDiffTrees(t *testing.T) {
+	beforeRoot, afterRoot := t.TempDir(), t.TempDir()
+	writeFile := func(root, path, content string) {
+		assert.NilError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, path)), 0755))
+		assert.NilError(t, os.WriteFile(filepath.Join(root, path), []byte(content), 0644))
+	}
+	for _, root := range []string{beforeRoot, afterRoot} {
+		writeFile(root, "backend/main.go", "package main")
+		writeFile(root, "frontend/index.js", "module.exports = {}")
+		writeFile(root, "worker/main.go", "package main")
+	}
+	writeFile(afterRoot, "frontend/index.js", "module.exports = {a: 1}")
+	writeFile(afterRoot, "backend/.devspace/cache.yaml", "version: v1")
+
+	before := &Tree{
+		Root: "project",
+		Nodes: []TreeNode{
+			{Name: "project", Root: true},
+			{Name: "backend", Path: filepath.Join(beforeRoot, "backend")},
+			{Name: "frontend", Path: filepath.Join(beforeRoot, "frontend")},
+			{Name: "worker", Path: filepath.Join(beforeRoot, "worker"), Source: &latest.SourceConfig{Path: "worker"}},
+			{Name: "legacy", Source: &latest.SourceConfig{Git: "https://github.com/test/legacy"}},
+		},
+		Edges: []TreeEdge{
+			{From: "project", To: "backend"},
+			{From: "project", To: "frontend"},
+			{From: "backend", To: "worker"},
+			{From: "backend", To: "legacy"},
+		},
+	}
+	after := &Tree{
+		Root: "project",
+		Nodes: []TreeNode{
+			{Name: "project", Root: true},
+			{Name: "backend", Path: filepath.Join(afterRoot, "backend")},
+			{Name: "frontend", Path: filepath.Join(afterRoot, "frontend")},
+			{Name: "worker", Path: filepath.Join(afterRoot, "worker"), Source: &latest.SourceConfig{Path: "worker"}, Pipeline: "deploy-worker"},
+			{Name: "database", Source: &latest.SourceConfig{Git: "https://github.com/test/database"}},
+		},
+		Edges: []TreeEdge{
+			{From: "project", To: "frontend"},
+			{From: "project", To: "backend"},
+			{From: "backend", To: "database"},
+			{From: "backend", To: "worker"},
+		},
+	}
+
+	diff, err := DiffTrees(before, beforeRoot, after, afterRoot)
+	assert.NilError(t, err)
+	assert.Equal(t, len(diff.Added), 1)
+	assert.Equal(t, diff.Added[0].Name, "database")
+	assert.Equal(t, len(diff.Removed), 1)
+	assert.Equal(t, diff.Removed[0].Name, "legacy")
+
+	// the order of edges and the .devspace folder don't change a dependency
+	changes := map[string][]string{}
+	for _, change := range diff.Changed {
+		changes[change.Name] = change.Reasons
+	}
+	assert.DeepEqual(t, changes, map[string][]string{
+		"backend":  {"dependencies have changed"},
+		"frontend": {"files have changed"},
+		"worker":   {"pipeline has changed"},
+	})
+
+	// equal trees have no differences
+	diff, err = DiffTrees(after, afterRoot, after, afterRoot)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, diff, &TreeDiff{})
+}
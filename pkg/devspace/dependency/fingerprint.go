@@ -0,0 +1,105 @@
+package dependency
+
+import (
+	"context"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/util/git"
+	"github.com/loft-sh/devspace/pkg/util/hash"
+	"github.com/pkg/errors"
+)
+
+// fingerprintKey is the key in the local cache of a dependency that holds the fingerprint
+// of the dependency files at its last successful run
+const fingerprintKey = "dependencyFingerprint"
+
+// directoryHashKey is the key in the local cache of a dependency that holds the hash
+// of the dependency directory at its last successful run
+const directoryHashKey = "dependencyDirectoryHash"
+
+// Fingerprint returns a fingerprint of the files of the dependency as configured by its change detection.
+// If the dependency has no change detection and is not virtual, an empty string is returned
+func Fingerprint(ctx context.Context, dependency types.Dependency) (string, error) {
+	if dependency.DependencyConfig() == nil {
+		return "", nil
+	}
+
+	// virtual dependencies have no images or deployments, so their files are always hashed
+	changeDetection := dependency.DependencyConfig().ChangeDetection
+	if changeDetection == nil && isVirtual(dependency.DependencyConfig()) {
+		changeDetection = &latest.DependencyChangeDetection{}
+	} else if changeDetection == nil {
+		return "", nil
+	}
+	switch changeDetection.Strategy {
+	case "", latest.ChangeDetectionStrategyHash:
+		patterns := []string{}
+		if len(changeDetection.Include) > 0 {
+			patterns = append(patterns, "**")
+			for _, include := range changeDetection.Include {
+				patterns = append(patterns, "!"+include)
+			}
+		}
+		patterns = append(patterns, ".git/", ".devspace/")
+		patterns = append(patterns, changeDetection.Exclude...)
+
+		fingerprint, err := hash.DirectoryExcludes(dependency.Path(), patterns, true)
+		if err != nil {
+			return "", errors.Wrapf(err, "hash dependency %s", dependency.Name())
+		}
+
+		return fingerprint, nil
+	case latest.ChangeDetectionStrategyGitDiff:
+		pathspecs := append([]string{}, changeDetection.Include...)
+		if len(pathspecs) == 0 {
+			pathspecs = append(pathspecs, ".")
+		}
+		for _, exclude := range changeDetection.Exclude {
+			pathspecs = append(pathspecs, ":(exclude)"+exclude)
+		}
+
+		fingerprint, err := git.GetDiffHash(ctx, dependency.Path(), pathspecs)
+		if err != nil {
+			return "", errors.Wrapf(err, "diff dependency %s", dependency.Name())
+		}
+
+		return fingerprint, nil
+	}
+
+	return "", errors.Errorf("unknown change detection strategy %s of dependency %s", changeDetection.Strategy, dependency.Name())
+}
+
+// DirectoryHash returns the hash of all files in the directory of the dependency
+func DirectoryHash(dependency types.Dependency) (string, error) {
+	if dependency.Path() == "" {
+		return "", nil
+	}
+
+	directoryHash, err := hash.DirectoryExcludes(dependency.Path(), []string{".git/", ".devspace/"}, true)
+	if err != nil {
+		return "", errors.Wrapf(err, "hash directory of dependency %s", dependency.Name())
+	}
+
+	return directoryHash, nil
+}
+
+// SaveFingerprint stores the current fingerprint and directory hash of the dependency in its local
+// cache, so that later status checks only need to compare them
+func SaveFingerprint(ctx context.Context, dependency types.Dependency) error {
+	fingerprint, err := Fingerprint(ctx, dependency)
+	if err != nil {
+		return err
+	} else if fingerprint != "" {
+		dependency.Config().LocalCache().SetData(fingerprintKey, fingerprint)
+	}
+
+	directoryHash, err := DirectoryHash(dependency)
+	if err != nil {
+		return err
+	} else if directoryHash != "" {
+		dependency.Config().LocalCache().SetData(directoryHashKey, directoryHash)
+	}
+
+	return dependency.Config().LocalCache().Save()
+}
@@ -0,0 +1,118 @@
+package dependency
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"gotest.tools/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(path, content string) {
+		assert.NilError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0644))
+	}
+	writeFile("src/main.go", "package main")
+	writeFile("node_modules/module/index.js", "module.exports = {}")
+
+	// without change detection there is no fingerprint
+	dependency := &Dependency{
+		name:             "backend",
+		absolutePath:     dir,
+		dependencyConfig: &latest.DependencyConfig{Name: "backend"},
+	}
+	fingerprint, err := Fingerprint(context.Background(), dependency)
+	assert.NilError(t, err)
+	assert.Equal(t, fingerprint, "")
+
+	// excluded files don't change the fingerprint
+	dependency.dependencyConfig.ChangeDetection = &latest.DependencyChangeDetection{
+		Exclude: []string{"node_modules"},
+	}
+	before, err := Fingerprint(context.Background(), dependency)
+	assert.NilError(t, err)
+	assert.Assert(t, before != "")
+	writeFile("node_modules/other/index.js", "module.exports = {}")
+	after, err := Fingerprint(context.Background(), dependency)
+	assert.NilError(t, err)
+	assert.Equal(t, before, after)
+	writeFile("src/util.go", "package main")
+	after, err = Fingerprint(context.Background(), dependency)
+	assert.NilError(t, err)
+	assert.Assert(t, before != after)
+
+	// only included files change the fingerprint
+	dependency.dependencyConfig.ChangeDetection = &latest.DependencyChangeDetection{
+		Include: []string{"src"},
+	}
+	before, err = Fingerprint(context.Background(), dependency)
+	assert.NilError(t, err)
+	writeFile("README.md", "# backend")
+	after, err = Fingerprint(context.Background(), dependency)
+	assert.NilError(t, err)
+	assert.Equal(t, before, after)
+	writeFile("src/other.go", "package main")
+	after, err = Fingerprint(context.Background(), dependency)
+	assert.NilError(t, err)
+	assert.Assert(t, before != after)
+
+	// unknown strategies fail
+	dependency.dependencyConfig.ChangeDetection = &latest.DependencyChangeDetection{Strategy: "unknown"}
+	_, err = Fingerprint(context.Background(), dependency)
+	assert.ErrorContains(t, err, "unknown change detection strategy")
+}
+
+func TestFingerprintGitDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		out, err := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@test"}, args...)...).CombinedOutput()
+		assert.NilError(t, err, string(out))
+	}
+	git("init")
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("node_modules\n"), 0644))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644))
+	git("add", ".")
+	git("commit", "-m", "initial")
+
+	dependency := &Dependency{
+		name:         "backend",
+		absolutePath: dir,
+		dependencyConfig: &latest.DependencyConfig{
+			Name: "backend",
+			ChangeDetection: &latest.DependencyChangeDetection{
+				Strategy: latest.ChangeDetectionStrategyGitDiff,
+				Exclude:  []string{"docs"},
+			},
+		},
+	}
+	before, err := Fingerprint(context.Background(), dependency)
+	assert.NilError(t, err)
+
+	// ignored and excluded files don't change the fingerprint
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "node_modules"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "node_modules", "index.js"), []byte("module.exports = {}"), 0644))
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "docs", "README.md"), []byte("# backend"), 0644))
+	after, err := Fingerprint(context.Background(), dependency)
+	assert.NilError(t, err)
+	assert.Equal(t, before, after)
+
+	// uncommitted changes and commits change the fingerprint
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644))
+	changed, err := Fingerprint(context.Background(), dependency)
+	assert.NilError(t, err)
+	assert.Assert(t, changed != before)
+	git("commit", "-am", "change")
+	committed, err := Fingerprint(context.Background(), dependency)
+	assert.NilError(t, err)
+	assert.Assert(t, committed != before && committed != changed)
+}
@@ -1,7 +1,9 @@
 package graph
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -12,7 +14,20 @@ type Graph struct {
 
 	Root *Node
 
-	item string
+	item      string
+	observers []*Observer
+}
+
+// Observer is notified about the changes of a graph. The methods are called synchronously
+// after the graph was changed
+type Observer interface {
+	// OnInsert is called when a node is added to the graph
+	OnInsert(node *Node)
+	// OnRemove is called when a node is removed from the graph
+	OnRemove(node *Node)
+	// OnEdgeAdd is called when an edge is added to the graph, including the edge
+	// to the parent of a newly inserted node
+	OnEdgeAdd(from *Node, to *Node)
 }
 
 func NewGraph(root *Node) *Graph {
@@ -41,10 +56,25 @@ type Node struct {
 	ID   string
 	Data interface{}
 
+	// Metadata is serialized together with the graph, while the data is not
+	Metadata Metadata
+
 	Parents []*Node
 	Childs  []*Node
 }
 
+// Metadata describes where the data of a node was resolved from
+type Metadata struct {
+	// Hash identifies the contents the node was resolved to
+	Hash string `json:"hash,omitempty"`
+
+	// SourceType is the kind of source the node was resolved from
+	SourceType string `json:"sourceType,omitempty"`
+
+	// Path is the resolved path of the node
+	Path string `json:"path,omitempty"`
+}
+
 func NewNode(id string, data interface{}) *Node {
 	return &Node{
 		ID:   id,
@@ -55,6 +85,21 @@ func NewNode(id string, data interface{}) *Node {
 	}
 }
 
+// AddObserver subscribes the observer to the changes of the graph. The returned
+// function unsubscribes the observer again
+func (g *Graph) AddObserver(observer Observer) func() {
+	entry := &observer
+	g.observers = append(g.observers, entry)
+	return func() {
+		for i, other := range g.observers {
+			if other == entry {
+				g.observers = append(g.observers[:i], g.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
 // InsertNodeAt inserts a new node at the given parent position
 func (g *Graph) InsertNodeAt(parentID string, id string, data interface{}) (*Node, error) {
 	parentNode, ok := g.Nodes[parentID]
@@ -77,6 +122,11 @@ func (g *Graph) InsertNodeAt(parentID string, id string, data interface{}) (*Nod
 	parentNode.Childs = append(parentNode.Childs, node)
 	node.Parents = append(node.Parents, parentNode)
 
+	for _, observer := range g.observers {
+		(*observer).OnInsert(node)
+		(*observer).OnEdgeAdd(parentNode, node)
+	}
+
 	return node, nil
 }
 
@@ -103,6 +153,9 @@ func (g *Graph) RemoveNode(id string) error {
 
 		// Remove from graph nodes
 		delete(g.Nodes, id)
+		for _, observer := range g.observers {
+			(*observer).OnRemove(node)
+		}
 	}
 
 	return nil
@@ -125,18 +178,23 @@ type CyclicError struct {
 
 // Error implements error interface
 func (c *CyclicError) Error() string {
-	cycle := []string{getNameOrID(c.path[len(c.path)-1])}
-
-	for _, node := range c.path {
-		cycle = append(cycle, getNameOrID(node))
-	}
-
 	what := "dependency"
 	if c.What != "" {
 		what = c.What
 	}
 
-	return fmt.Sprintf("Cyclic %s found: \n%s", what, strings.Join(cycle, "\n"))
+	return fmt.Sprintf("Cyclic %s found: \n%s", what, strings.Join(c.Cycle(), "\n"))
+}
+
+// Cycle returns the ids of the nodes in the cycle. The first and the last id are the node the
+// rejected edge started from
+func (c *CyclicError) Cycle() []string {
+	cycle := []string{getNameOrID(c.path[len(c.path)-1])}
+	for _, node := range c.path {
+		cycle = append(cycle, getNameOrID(node))
+	}
+
+	return cycle
 }
 
 // AddEdge adds a new edge from a node to a node and returns an error if it would result in a cyclic graph
@@ -168,6 +226,95 @@ func (g *Graph) AddEdge(fromID string, toID string) error {
 
 	from.Childs = append(from.Childs, to)
 	to.Parents = append(to.Parents, from)
+	for _, observer := range g.observers {
+		(*observer).OnEdgeAdd(from, to)
+	}
+
+	return nil
+}
+
+type graphJSON struct {
+	Root  string     `json:"root"`
+	Item  string     `json:"item,omitempty"`
+	Nodes []nodeJSON `json:"nodes"`
+}
+
+type nodeJSON struct {
+	ID string `json:"id"`
+	Metadata
+	Children []string `json:"children,omitempty"`
+}
+
+// MarshalJSON serializes the nodes with their metadata and edges. The nodes are sorted by id,
+// the children keep their order. The data of the nodes is not serialized
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	out := graphJSON{
+		Item:  g.item,
+		Nodes: []nodeJSON{},
+	}
+	if g.Root != nil {
+		out.Root = g.Root.ID
+	}
+
+	for _, node := range g.Nodes {
+		children := []string{}
+		for _, child := range node.Childs {
+			children = append(children, child.ID)
+		}
+
+		out.Nodes = append(out.Nodes, nodeJSON{
+			ID:       node.ID,
+			Metadata: node.Metadata,
+			Children: children,
+		})
+	}
+	sort.Slice(out.Nodes, func(i, j int) bool {
+		return out.Nodes[i].ID < out.Nodes[j].ID
+	})
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON restores a graph that was serialized with MarshalJSON. The data of the
+// restored nodes is nil. Observers of the graph are kept, but not notified about the restored nodes
+func (g *Graph) UnmarshalJSON(data []byte) error {
+	in := graphJSON{}
+	err := json.Unmarshal(data, &in)
+	if err != nil {
+		return err
+	}
+
+	restored := &Graph{
+		Nodes: make(map[string]*Node),
+		item:  in.Item,
+	}
+	for _, serialized := range in.Nodes {
+		if _, ok := restored.Nodes[serialized.ID]; ok {
+			return errors.Errorf("node %s exists twice", serialized.ID)
+		}
+
+		node := NewNode(serialized.ID, nil)
+		node.Metadata = serialized.Metadata
+		restored.Nodes[node.ID] = node
+	}
+
+	root, ok := restored.Nodes[in.Root]
+	if !ok {
+		return errors.Errorf("root %s does not exist", in.Root)
+	}
+	restored.Root = root
+
+	for _, serialized := range in.Nodes {
+		for _, child := range serialized.Children {
+			err := restored.AddEdge(serialized.ID, child)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	restored.observers = g.observers
+	*g = *restored
 	return nil
 }
 
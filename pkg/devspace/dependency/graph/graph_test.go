@@ -1,6 +1,8 @@
 package graph
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -92,3 +94,87 @@ rootChild2Child1Child1`
 		t.Fatal("Expected error")
 	}
 }
+
+func TestGraphJSON(t *testing.T) {
+	testGraph := NewGraphOf(NewNode("root", nil), "dependency")
+	backend, _ := testGraph.InsertNodeAt("root", "backend", nil)
+	backend.Metadata = Metadata{Hash: "abc", SourceType: "path", Path: "/project/backend"}
+	_, _ = testGraph.InsertNodeAt("root", "frontend", nil)
+	_, _ = testGraph.InsertNodeAt("backend", "database", nil)
+	_, _ = testGraph.InsertNodeAt("frontend", "database", nil)
+
+	out, err := json.Marshal(testGraph)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := &Graph{}
+	err = json.Unmarshal(out, restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Root.ID != "root" || len(restored.Nodes) != 4 || restored.item != "dependency" {
+		t.Fatalf("Wrong graph restored: %s", string(out))
+	}
+	if restored.Nodes["backend"].Metadata != backend.Metadata {
+		t.Fatalf("Wrong metadata restored: %#+v", restored.Nodes["backend"].Metadata)
+	}
+	if len(restored.Root.Childs) != 2 || restored.Root.Childs[0].ID != "backend" || restored.Root.Childs[1].ID != "frontend" {
+		t.Fatalf("Wrong children restored: %#+v", restored.Root.Childs)
+	}
+	if len(restored.Nodes["database"].Parents) != 2 {
+		t.Fatalf("Wrong parents restored: %#+v", restored.Nodes["database"].Parents)
+	}
+
+	// edges that would create a cycle are rejected
+	err = json.Unmarshal([]byte(`{"root":"a","nodes":[{"id":"a","children":["b"]},{"id":"b","children":["a"]}]}`), &Graph{})
+	if _, ok := err.(*CyclicError); !ok {
+		t.Fatalf("Cyclic error expected, got %v", err)
+	}
+
+	err = json.Unmarshal([]byte(`{"root":"missing","nodes":[{"id":"a"}]}`), &Graph{})
+	if err == nil {
+		t.Fatal("Error expected for a missing root")
+	}
+}
+
+type recordingObserver struct {
+	events []string
+}
+
+func (r *recordingObserver) OnInsert(node *Node) {
+	r.events = append(r.events, "insert "+node.ID)
+}
+
+func (r *recordingObserver) OnRemove(node *Node) {
+	r.events = append(r.events, "remove "+node.ID)
+}
+
+func (r *recordingObserver) OnEdgeAdd(from *Node, to *Node) {
+	r.events = append(r.events, "edge "+from.ID+" "+to.ID)
+}
+
+func TestGraphObserver(t *testing.T) {
+	testGraph := NewGraph(NewNode("root", nil))
+	observer := &recordingObserver{}
+	removeObserver := testGraph.AddObserver(observer)
+
+	_, _ = testGraph.InsertNodeAt("root", "backend", nil)
+	_, _ = testGraph.InsertNodeAt("root", "database", nil)
+	_, _ = testGraph.InsertNodeAt("backend", "database", nil)
+	_, _ = testGraph.InsertNodeAt("backend", "database", nil)
+	_ = testGraph.AddEdge("database", "root")
+	_ = testGraph.RemoveNode("database")
+
+	// existing edges and rejected edges are not reported
+	expected := []string{"insert backend", "edge root backend", "insert database", "edge root database", "edge backend database", "remove database"}
+	if strings.Join(observer.events, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Wrong events: %v, expected %v", observer.events, expected)
+	}
+
+	removeObserver()
+	_, _ = testGraph.InsertNodeAt("root", "frontend", nil)
+	if len(observer.events) != len(expected) {
+		t.Fatalf("Observer was notified after it was removed: %v", observer.events)
+	}
+}
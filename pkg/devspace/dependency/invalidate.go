@@ -0,0 +1,76 @@
+package dependency
+
+import (
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/pkg/errors"
+)
+
+// InvalidateCache resolves the dependencies with the given names, or all dependencies if no names
+// are given, and clears their stored image and deployment hashes
+func (m *manager) InvalidateCache(ctx devspacecontext.Context, options ResolveOptions, names ...string) ([]types.Dependency, error) {
+	if len(names) > 0 {
+		options.Dependencies = names
+	}
+
+	return m.handleDependencies(ctx, options, "Reset", func(ctx devspacecontext.Context, dependency *Dependency) error {
+		return InvalidateCache(ctx, dependency)
+	})
+}
+
+// InvalidateCache clears the hashes DevSpace uses to decide if the images of the dependency have to be
+// rebuilt and its deployments redeployed. The release information is kept, so that the deployments
+// can still be purged. The context is expected to be the context of the dependency itself
+func InvalidateCache(ctx devspacecontext.Context, dependency types.Dependency) error {
+	if dependency.Config() == nil {
+		return nil
+	}
+
+	localCache := dependency.Config().LocalCache()
+	if localCache != nil {
+		for imageConfigName, imageCache := range localCache.ListImageCache() {
+			imageCache.ImageConfigHash = ""
+			imageCache.DockerfileHash = ""
+			imageCache.ContextHash = ""
+			imageCache.EntrypointHash = ""
+			imageCache.CustomFilesHash = ""
+			localCache.SetImageCache(imageConfigName, imageCache)
+		}
+		localCache.SetData(fingerprintKey, "")
+
+		err := localCache.Save()
+		if err != nil {
+			return errors.Wrap(err, "save local cache")
+		}
+	}
+
+	remoteCache := dependency.Config().RemoteCache()
+	if remoteCache != nil {
+		for _, deploymentCache := range remoteCache.ListDeployments() {
+			deploymentCache.DeploymentConfigHash = ""
+			if deploymentCache.Helm != nil {
+				helmCache := *deploymentCache.Helm
+				helmCache.OverridesHash = ""
+				helmCache.ChartHash = ""
+				helmCache.ValuesHash = ""
+				deploymentCache.Helm = &helmCache
+			}
+			if deploymentCache.Kubectl != nil {
+				kubectlCache := *deploymentCache.Kubectl
+				kubectlCache.ManifestsHash = ""
+				deploymentCache.Kubectl = &kubectlCache
+			}
+
+			remoteCache.SetDeployment(deploymentCache.Name, deploymentCache)
+		}
+
+		if ctx.KubeClient() != nil {
+			err := remoteCache.Save(ctx.Context(), ctx.KubeClient())
+			if err != nil {
+				return errors.Wrap(err, "save remote cache")
+			}
+		}
+	}
+
+	return nil
+}
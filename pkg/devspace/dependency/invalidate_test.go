@@ -0,0 +1,71 @@
+package dependency
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestInvalidateCache(t *testing.T) {
+	dir := t.TempDir()
+	localCache := localcache.New(filepath.Join(dir, ".devspace", "cache.yaml"))
+	localCache.SetImageCache("backend", localcache.ImageCache{
+		ImageConfigHash: "config",
+		DockerfileHash:  "dockerfile",
+		ContextHash:     "context",
+		ImageName:       "backend",
+		Tag:             "abc",
+	})
+
+	helmCache := &remotecache.HelmCache{
+		Release:       "backend",
+		ChartHash:     "chart",
+		ValuesHash:    "values",
+		OverridesHash: "overrides",
+	}
+	remoteCache := remotecache.NewCache("test", "test")
+	remoteCache.SetDeployment("backend", remotecache.DeploymentCache{
+		Name:                 "backend",
+		DeploymentConfigHash: "deployment",
+		Helm:                 helmCache,
+	})
+
+	dependency := &Dependency{
+		name:        "backend",
+		localConfig: config.NewConfig(nil, nil, &latest.Config{}, localCache, remoteCache, nil, filepath.Join(dir, "devspace.yaml")),
+	}
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).
+		WithConfig(dependency.Config()).
+		WithWorkingDir(dir)
+
+	err := InvalidateCache(ctx, dependency)
+	assert.NilError(t, err)
+
+	imageCache, _ := localCache.GetImageCache("backend")
+	assert.DeepEqual(t, imageCache, localcache.ImageCache{
+		ImageName: "backend",
+		Tag:       "abc",
+	})
+
+	deploymentCache, _ := remoteCache.GetDeployment("backend")
+	assert.Equal(t, deploymentCache.DeploymentConfigHash, "")
+	assert.DeepEqual(t, *deploymentCache.Helm, remotecache.HelmCache{Release: "backend"})
+
+	// the cache of the original deployment is not modified in place
+	assert.Equal(t, helmCache.ChartHash, "chart")
+
+	// the local cache was saved
+	loaded, err := localcache.NewCacheLoader().Load(filepath.Join(dir, "devspace.yaml"))
+	assert.NilError(t, err)
+	imageCache, _ = loaded.GetImageCache("backend")
+	assert.Equal(t, imageCache.ImageConfigHash, "")
+	assert.Equal(t, imageCache.Tag, "abc")
+}
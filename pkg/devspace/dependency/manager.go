@@ -1,10 +1,10 @@
 package dependency
 
 import (
-	"bytes"
 	"github.com/loft-sh/devspace/pkg/devspace/build"
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/graph"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
 	"github.com/loft-sh/devspace/pkg/devspace/hook"
 	"github.com/loft-sh/devspace/pkg/devspace/plugin"
@@ -16,8 +16,28 @@ import (
 
 // Manager can update, build, deploy and purge dependencies.
 type Manager interface {
-	// ResolveAll resolves all dependencies and returns them
+	// ResolveAll resolves all dependencies and returns them. Git dependencies are checked out at
+	// the commits of the devspace.lock, new dependencies are added to it and unused ones removed
 	ResolveAll(ctx devspacecontext.Context, options ResolveOptions) ([]types.Dependency, error)
+
+	// UpdateAll resolves all dependencies with the latest commits of their branches or tags
+	// and updates the devspace.lock
+	UpdateAll(ctx devspacecontext.Context, options ResolveOptions) ([]types.Dependency, error)
+
+	// StatusAll resolves all dependencies and returns if they have changed since they were deployed
+	StatusAll(ctx devspacecontext.Context, options ResolveOptions) ([]DependencyStatus, error)
+
+	// InvalidateCache resolves the dependencies with the given names (all if none are given) and
+	// clears their stored image and deployment hashes, so that they are rebuilt and redeployed
+	InvalidateCache(ctx devspacecontext.Context, options ResolveOptions, names ...string) ([]types.Dependency, error)
+
+	// VendorAll downloads all remote dependencies and copies them into the vendor folder of the
+	// project, so that they are resolved without network access afterwards
+	VendorAll(ctx devspacecontext.Context, options ResolveOptions) ([]types.Dependency, error)
+
+	// Tree returns the dependency graph of the last resolve with its nodes, edges and their
+	// metadata. If the dependencies were not resolved yet, the tree only contains the root
+	Tree() *Tree
 }
 
 type manager struct {
@@ -40,6 +60,42 @@ func NewManagerWithParser(ctx devspacecontext.Context, configOptions *loader.Con
 type ResolveOptions struct {
 	SkipDependencies []string
 	Dependencies     []string
+
+	// Tags selects the dependencies with one of these tags in addition to
+	// the dependencies selected by name
+	Tags []string
+
+	// Retry defines how often downloading a dependency is retried
+	Retry types.RetryOptions
+
+	// Update ignores the commits in the devspace.lock and pulls the latest
+	// commits of all git dependencies
+	Update bool
+
+	// Observer is notified about the changes of the dependency graph while
+	// the dependencies are resolved
+	Observer graph.Observer
+
+	// Events receives an event whenever an action on a dependency starts, succeeds or fails
+	Events types.EventHandler
+
+	// CyclePolicy defines how dependencies that reference one of their parents are resolved
+	CyclePolicy types.CyclePolicy
+
+	// Vendor downloads all remote dependencies and copies them into the vendor folder of the
+	// project. Without it, vendored dependencies are resolved from the vendor folder
+	Vendor bool
+
+	// CacheStore restores the local caches of dependencies that don't have a local cache yet
+	CacheStore types.CacheStore
+
+	// ReadOnly resolves the dependencies with the commits of the devspace.lock, but never
+	// writes the devspace.lock. It is used by commands that only inspect the dependencies
+	ReadOnly bool
+}
+
+func (m *manager) Tree() *Tree {
+	return m.resolver.Tree()
 }
 
 func (m *manager) ResolveAll(ctx devspacecontext.Context, options ResolveOptions) ([]types.Dependency, error) {
@@ -53,6 +109,30 @@ func (m *manager) ResolveAll(ctx devspacecontext.Context, options ResolveOptions
 	return dependencies, nil
 }
 
+func (m *manager) UpdateAll(ctx devspacecontext.Context, options ResolveOptions) ([]types.Dependency, error) {
+	options.Update = true
+	dependencies, err := m.handleDependencies(ctx, options, "Update", func(ctx devspacecontext.Context, dependency *Dependency) error {
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dependencies, nil
+}
+
+func (m *manager) VendorAll(ctx devspacecontext.Context, options ResolveOptions) ([]types.Dependency, error) {
+	options.Vendor = true
+	dependencies, err := m.handleDependencies(ctx, options, "Vendor", func(ctx devspacecontext.Context, dependency *Dependency) error {
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dependencies, nil
+}
+
 // BuildOptions has all options for building all dependencies
 type BuildOptions struct {
 	BuildOptions build.Options
@@ -63,7 +143,7 @@ type BuildOptions struct {
 }
 
 func (m *manager) handleDependencies(ctx devspacecontext.Context, options ResolveOptions, actionName string, action func(ctx devspacecontext.Context, dependency *Dependency) error) ([]types.Dependency, error) {
-	if ctx.Config() == nil || ctx.Config().Config() == nil || len(ctx.Config().Config().Dependencies) == 0 {
+	if ctx.Config() == nil || !hasDependencies(ctx.Config().Config()) {
 		return nil, nil
 	}
 
@@ -98,6 +178,10 @@ func (m *manager) handleDependencies(ctx devspacecontext.Context, options Resolv
 	return executedDependencies, nil
 }
 
+// dependencyLogTailSize is the number of bytes of the output of a dependency that are kept
+// to show them if the dependency fails
+var dependencyLogTailSize = 64 * 1024
+
 func (m *manager) executeDependenciesRecursive(ctx devspacecontext.Context, base string, dependencies []types.Dependency, options ResolveOptions, actionName string, action func(ctx devspacecontext.Context, dependency *Dependency) error, executedDependenciesIDs map[string]bool) ([]types.Dependency, error) {
 	// Execute all dependencies
 	i := 0
@@ -105,12 +189,17 @@ func (m *manager) executeDependenciesRecursive(ctx devspacecontext.Context, base
 	for i >= 0 && i < len(dependencies) {
 		var (
 			dependency = dependencies[i]
-			buff       = &bytes.Buffer{}
+			buff       = log.NewTailBuffer(dependencyLogTailSize)
 		)
 
 		// Increase counter
 		i++
 
+		// don't start any other dependency if the execution was cancelled
+		if ctx.Context().Err() != nil {
+			return nil, errors.Wrapf(ctx.Context().Err(), "%s dependency %s", actionName, dependency.Name())
+		}
+
 		// skip if dependency was executed already
 		if executedDependenciesIDs[dependency.Name()] {
 			executedDependencies = append(executedDependencies, dependency)
@@ -153,7 +242,7 @@ func (m *manager) executeDependenciesRecursive(ctx devspacecontext.Context, base
 		}
 
 		// Check if we should act on this dependency
-		if !foundDependency(dependencyName, options.Dependencies) {
+		if !foundDependency(dependency, dependencyName, options.Dependencies, options.Tags) {
 			continue
 		} else if skipDependency(dependencyName, options.SkipDependencies) {
 			ctx.Log().Infof("Skip dependency %s", dependencyName)
@@ -173,7 +262,9 @@ func (m *manager) executeDependenciesRecursive(ctx devspacecontext.Context, base
 			}
 		}
 
+		done := options.Events.Track(dependency.Name(), strings.ToLower(actionName))
 		err := action(dependencyCtx, dependency.(*Dependency))
+		done(err)
 		if err != nil {
 			if dependency.Config() != nil {
 				pluginErr := plugin.ExecutePluginHookWithContext(map[string]interface{}{
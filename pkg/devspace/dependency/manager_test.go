@@ -0,0 +1,54 @@
+package dependency
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+)
+
+func TestExecuteDependenciesCancelled(t *testing.T) {
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	executed := false
+	ctx := devspacecontext.NewContext(cancelledCtx, nil, log.Discard)
+	_, err := (&manager{}).executeDependenciesRecursive(ctx, "", []types.Dependency{&Dependency{name: "backend"}}, ResolveOptions{}, "Build", func(ctx devspacecontext.Context, dependency *Dependency) error {
+		executed = true
+		return nil
+	}, map[string]bool{})
+	assert.Assert(t, errors.Is(err, context.Canceled))
+	assert.Assert(t, !executed)
+}
+
+func TestExecuteDependenciesOutputTail(t *testing.T) {
+	defer func(size int) { dependencyLogTailSize = size }(dependencyLogTailSize)
+	dependencyLogTailSize = 64
+
+	dir := t.TempDir()
+	dependency := &Dependency{
+		name:        "backend",
+		localConfig: config.NewConfig(nil, nil, &latest.Config{}, localcache.New(filepath.Join(dir, ".devspace", "cache.yaml")), remotecache.NewCache("test", "test"), nil, filepath.Join(dir, "devspace.yaml")),
+	}
+
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard)
+	_, err := (&manager{}).executeDependenciesRecursive(ctx, "", []types.Dependency{dependency}, ResolveOptions{}, "Deploy", func(ctx devspacecontext.Context, dependency *Dependency) error {
+		for i := 0; i < 10; i++ {
+			ctx.Log().Infof("output line %d", i)
+		}
+		return errors.New("failed")
+	}, map[string]bool{})
+	assert.ErrorContains(t, err, "output line 9")
+	assert.ErrorContains(t, err, "bytes of earlier output omitted")
+	assert.Assert(t, !strings.Contains(err.Error(), "output line 0"))
+}
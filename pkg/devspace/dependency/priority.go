@@ -0,0 +1,66 @@
+package dependency
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+)
+
+const durationKey = "dependencyDuration"
+
+// SaveDuration stores how long the last successful run of the dependency took in its local cache,
+// so that long running dependencies can be started first the next time
+func SaveDuration(dependency types.Dependency, duration time.Duration) error {
+	if dependency.Config() == nil || dependency.Config().LocalCache() == nil {
+		return nil
+	}
+
+	dependency.Config().LocalCache().SetData(durationKey, strconv.FormatInt(duration.Milliseconds(), 10))
+	return dependency.Config().LocalCache().Save()
+}
+
+// LastDuration returns how long the last successful run of the dependency took or 0 if unknown
+func LastDuration(dependency types.Dependency) time.Duration {
+	if dependency.Config() == nil || dependency.Config().LocalCache() == nil {
+		return 0
+	}
+
+	value, ok := dependency.Config().LocalCache().GetData(durationKey)
+	if !ok {
+		return 0
+	}
+
+	milliseconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(milliseconds) * time.Millisecond
+}
+
+// SortByPriority returns the dependencies in the order they should be started. Dependencies with
+// a higher priority come first, dependencies with the same priority are ordered by the duration of
+// their last run, longest first. Otherwise the original order is kept
+func SortByPriority(dependencies []types.Dependency) []types.Dependency {
+	priorities := map[string]int{}
+	durations := map[string]time.Duration{}
+	for _, dependency := range dependencies {
+		if dependency.DependencyConfig() != nil {
+			priorities[dependency.Name()] = dependency.DependencyConfig().Priority
+		}
+		durations[dependency.Name()] = LastDuration(dependency)
+	}
+
+	sorted := append([]types.Dependency{}, dependencies...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		first, second := sorted[i].Name(), sorted[j].Name()
+		if priorities[first] != priorities[second] {
+			return priorities[first] > priorities[second]
+		}
+
+		return durations[first] > durations[second]
+	})
+	return sorted
+}
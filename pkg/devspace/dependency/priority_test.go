@@ -0,0 +1,56 @@
+package dependency
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"gotest.tools/assert"
+)
+
+func TestSortByPriority(t *testing.T) {
+	dir := t.TempDir()
+	newDependency := func(name string, priority int) *Dependency {
+		return &Dependency{
+			name:             name,
+			dependencyConfig: &latest.DependencyConfig{Name: name, Priority: priority},
+			localConfig:      config.NewConfig(nil, nil, &latest.Config{}, localcache.New(filepath.Join(dir, name, ".devspace", "cache.yaml")), nil, nil, filepath.Join(dir, name, "devspace.yaml")),
+		}
+	}
+	frontend := newDependency("frontend", 0)
+	backend := newDependency("backend", 0)
+	database := newDependency("database", 0)
+	worker := newDependency("worker", 0)
+
+	// without priorities and durations the order is kept
+	dependencies := []types.Dependency{frontend, backend, database, worker}
+	assert.DeepEqual(t, namesOf(SortByPriority(dependencies)), []string{"frontend", "backend", "database", "worker"})
+
+	// long running dependencies are started first, unless a higher priority is configured
+	assert.NilError(t, SaveDuration(backend, 3*time.Minute))
+	assert.NilError(t, SaveDuration(worker, time.Minute))
+	assert.Equal(t, LastDuration(backend), 3*time.Minute)
+	assert.Equal(t, LastDuration(frontend), time.Duration(0))
+	assert.DeepEqual(t, namesOf(SortByPriority(dependencies)), []string{"backend", "worker", "frontend", "database"})
+	database.dependencyConfig.Priority = 10
+	assert.DeepEqual(t, namesOf(SortByPriority(dependencies)), []string{"database", "backend", "worker", "frontend"})
+
+	// the duration survives reloading the cache
+	reloaded, err := localcache.NewCacheLoader().Load(filepath.Join(dir, "backend", "devspace.yaml"))
+	assert.NilError(t, err)
+	duration, _ := reloaded.GetData(durationKey)
+	assert.Equal(t, duration, "180000")
+}
+
+func namesOf(dependencies []types.Dependency) []string {
+	names := []string{}
+	for _, dependency := range dependencies {
+		names = append(names, dependency.Name())
+	}
+
+	return names
+}
@@ -0,0 +1,182 @@
+package dependency
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/mgutz/ansi"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	progressPending   = "pending"
+	progressBuilding  = "building"
+	progressDeploying = "deploying"
+	progressPurging   = "purging"
+	progressDone      = "done"
+	progressFailed    = "failed"
+	progressCancelled = "cancelled"
+)
+
+var progressColors = map[string]string{
+	progressPending:   "white+h",
+	progressBuilding:  "cyan+b",
+	progressDeploying: "cyan+b",
+	progressPurging:   "cyan+b",
+	progressDone:      "green+b",
+	progressFailed:    "red+b",
+	progressCancelled: "yellow+b",
+}
+
+// progressEntry is the state of a single dependency
+type progressEntry struct {
+	state    string
+	start    time.Time
+	duration time.Duration
+}
+
+// progressRenderer shows the state of every dependency as a status line of the logger
+type progressRenderer struct {
+	m sync.Mutex
+
+	status  log.StatusLogger
+	names   []string
+	entries map[string]*progressEntry
+
+	stop chan struct{}
+}
+
+// NewProgressRenderer returns an event handler that shows a live status line with the state and elapsed
+// time of every dependency below the log output. The status lines are only shown if the logger runs in a
+// terminal and is not verbose, otherwise the given handler is returned as is. Events are passed on to the
+// given handler. The returned function stops the renderer and removes the status lines
+func NewProgressRenderer(logger log.Logger, handler types.EventHandler) (types.EventHandler, func()) {
+	status, ok := logger.(log.StatusLogger)
+	if !ok || logger.GetLevel() != logrus.InfoLevel {
+		return handler, func() {}
+	}
+
+	r := &progressRenderer{
+		status:  status,
+		entries: map[string]*progressEntry{},
+		stop:    make(chan struct{}),
+	}
+	go r.tick()
+
+	once := sync.Once{}
+	return func(event types.Event) {
+			r.handle(event)
+			handler.Emit(event)
+		}, func() {
+			once.Do(func() {
+				close(r.stop)
+				r.status.SetStatus(nil)
+			})
+		}
+}
+
+// tick redraws the status lines every second, so that the elapsed time is updated
+func (r *progressRenderer) tick() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.m.Lock()
+			if len(r.names) > 0 {
+				r.status.SetStatus(r.lines(time.Now()))
+			}
+			r.m.Unlock()
+		}
+	}
+}
+
+func (r *progressRenderer) handle(event types.Event) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	entry, ok := r.entries[event.Dependency]
+	if !ok {
+		entry = &progressEntry{}
+		r.entries[event.Dependency] = entry
+		r.names = append(r.names, event.Dependency)
+	}
+
+	switch event.Type {
+	case types.EventPending:
+		entry.state = progressPending
+	case types.EventStarted:
+		entry.state = progressStateOf(event.Action)
+		entry.start = event.Time
+	case types.EventCancelled:
+		entry.state = progressCancelled
+	case types.EventSucceeded, types.EventFailed:
+		entry.state = progressDone
+		if event.Type == types.EventFailed {
+			entry.state = progressFailed
+		}
+		entry.duration = time.Duration(event.DurationMs) * time.Millisecond
+	}
+
+	// remove the status as soon as all dependencies are finished
+	for _, entry := range r.entries {
+		if entry.state != progressDone && entry.state != progressFailed && entry.state != progressCancelled {
+			r.status.SetStatus(r.lines(time.Now()))
+			return
+		}
+	}
+
+	r.names = nil
+	r.entries = map[string]*progressEntry{}
+	r.status.SetStatus(nil)
+}
+
+// lines returns a status line for every dependency in the order they were seen first
+func (r *progressRenderer) lines(now time.Time) []string {
+	width := 0
+	for _, name := range r.names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+
+	lines := []string{}
+	for _, name := range r.names {
+		entry := r.entries[name]
+		line := fmt.Sprintf("%-*s %s", width, name, ansi.Color(fmt.Sprintf("%-9s", entry.state), progressColors[entry.state]))
+		switch entry.state {
+		case progressDone, progressFailed:
+			line += " " + formatElapsed(entry.duration)
+		case progressPending, progressCancelled:
+		default:
+			line += " " + formatElapsed(now.Sub(entry.start))
+		}
+
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+
+	return lines
+}
+
+// progressStateOf returns the state of a dependency that runs the given action
+func progressStateOf(action string) string {
+	switch action {
+	case "build":
+		return progressBuilding
+	case "purge":
+		return progressPurging
+	}
+
+	return progressDeploying
+}
+
+func formatElapsed(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,74 @@
+package dependency
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acarl005/stripansi"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	log "github.com/loft-sh/devspace/pkg/util/log/testing"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+)
+
+type fakeStatusLogger struct {
+	*log.FakeLogger
+
+	m      sync.Mutex
+	status []string
+}
+
+func (f *fakeStatusLogger) SetStatus(lines []string) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.status = []string{}
+	for _, line := range lines {
+		f.status = append(f.status, strings.Join(strings.Fields(stripansi.Strip(line)), " "))
+	}
+}
+
+func (f *fakeStatusLogger) lines() []string {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	return f.status
+}
+
+func TestProgressRenderer(t *testing.T) {
+	logger := &fakeStatusLogger{FakeLogger: log.NewFakeLogger()}
+	logger.SetLevel(logrus.InfoLevel)
+
+	events := []types.Event{}
+	handler, stop := NewProgressRenderer(logger, func(event types.Event) {
+		events = append(events, event)
+	})
+	defer stop()
+
+	start := time.Now().Add(-2 * time.Second)
+	handler.Emit(types.Event{Type: types.EventPending, Dependency: "backend"})
+	handler.Emit(types.Event{Type: types.EventPending, Dependency: "frontend"})
+	handler.Emit(types.Event{Time: start, Type: types.EventStarted, Dependency: "backend", Action: "build"})
+	assert.DeepEqual(t, logger.lines(), []string{"backend building 2s", "frontend pending"})
+
+	handler.Emit(types.Event{Time: start, Type: types.EventStarted, Dependency: "frontend", Action: "deploy"})
+	handler.Emit(types.Event{Type: types.EventSucceeded, Dependency: "backend", Action: "build", DurationMs: 3000})
+	assert.DeepEqual(t, logger.lines(), []string{"backend done 3s", "frontend deploying 2s"})
+
+	// the status is removed as soon as all dependencies are finished or cancelled
+	handler.Emit(types.Event{Type: types.EventPending, Dependency: "worker"})
+	handler.Emit(types.Event{Type: types.EventFailed, Dependency: "frontend", Action: "deploy", DurationMs: 1000})
+	assert.DeepEqual(t, logger.lines(), []string{"backend done 3s", "frontend failed 1s", "worker pending"})
+	handler.Emit(types.Event{Type: types.EventCancelled, Dependency: "worker"})
+	assert.Equal(t, len(logger.lines()), 0)
+
+	// events are passed on to the given handler
+	assert.Equal(t, len(events), 8)
+
+	// verbose loggers don't show a status
+	logger.SetLevel(logrus.DebugLevel)
+	handler, _ = NewProgressRenderer(logger, nil)
+	assert.Assert(t, handler == nil)
+}
@@ -18,6 +18,8 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/util"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/util/hash"
+	"github.com/loft-sh/devspace/pkg/util/pathutil"
 	"github.com/pkg/errors"
 )
 
@@ -25,6 +27,9 @@ import (
 type ResolverInterface interface {
 	Resolve(ctx devspacecontext.Context, options ResolveOptions) ([]types.Dependency, error)
 	WithParser(parser loader.Parser) ResolverInterface
+
+	// Tree returns the dependency graph that was resolved so far
+	Tree() *Tree
 }
 
 // Resolver implements the resolver interface
@@ -36,22 +41,33 @@ type resolver struct {
 	BaseParser loader.Parser
 
 	ConfigOptions *loader.ConfigOptions
+
+	lockFile   *util.LockFile
+	vendorPath string
+	cacheStore types.CacheStore
+
+	// skipped is true if a dependency was skipped by its condition, the locked
+	// commits of it and its children are kept
+	skipped bool
 }
 
 // NewResolver creates a new resolver for resolving dependencies
 func NewResolver(ctx devspacecontext.Context, configOptions *loader.ConfigOptions) ResolverInterface {
+	root := graph.NewNode(ctx.Config().Config().Name, &Dependency{
+		name:         ctx.Config().Config().Name,
+		absolutePath: ctx.Config().Path(),
+		localConfig:  ctx.Config(),
+		dependencyConfig: &latest.DependencyConfig{
+			Name: ctx.Config().Config().Name,
+		},
+		dependencyCache: ctx.Config().LocalCache(),
+		kubeClient:      ctx.KubeClient(),
+		root:            true,
+	})
+	root.Metadata.Path = filepath.Dir(ctx.Config().Path())
+
 	return &resolver{
-		DependencyGraph: graph.NewGraph(graph.NewNode(ctx.Config().Config().Name, &Dependency{
-			name:         ctx.Config().Config().Name,
-			absolutePath: ctx.Config().Path(),
-			localConfig:  ctx.Config(),
-			dependencyConfig: &latest.DependencyConfig{
-				Name: ctx.Config().Config().Name,
-			},
-			dependencyCache: ctx.Config().LocalCache(),
-			kubeClient:      ctx.KubeClient(),
-			root:            true,
-		})),
+		DependencyGraph: graph.NewGraph(root),
 
 		BaseConfig: ctx.Config().Config(),
 		BaseCache:  ctx.Config().LocalCache(),
@@ -67,8 +83,29 @@ func (r *resolver) Resolve(ctx devspacecontext.Context, options ResolveOptions)
 		return nil, errors.Wrap(err, "get current working directory")
 	}
 
+	options.CyclePolicy, err = types.ParseCyclePolicy(string(options.CyclePolicy))
+	if err != nil {
+		return nil, err
+	}
+
+	// load the lock file that pins the git dependencies
+	r.lockFile, err = util.LoadLockFile(filepath.Join(filepath.Dir(ctx.Config().Path()), util.LockFileName))
+	if err != nil {
+		return nil, errors.Wrap(err, "load lock file")
+	}
+	r.vendorPath = util.GetVendorPath(ctx.Config().Path())
+	r.cacheStore = options.CacheStore
+	if options.Observer != nil {
+		defer r.DependencyGraph.AddObserver(options.Observer)()
+	}
+
+	dependencies, err := withWorkspace(r.BaseConfig, currentWorkingDirectory)
+	if err != nil {
+		return nil, err
+	}
+
 	// r.DependencyGraph.Root.ID == name here
-	err = r.resolveRecursive(ctx, currentWorkingDirectory, r.DependencyGraph.Root.ID, nil, transformMap(r.BaseConfig.Dependencies), options)
+	err = r.resolveRecursive(ctx, currentWorkingDirectory, r.DependencyGraph.Root.ID, nil, dependencies, options)
 	if err != nil {
 		if _, ok := err.(*graph.CyclicError); ok {
 			return nil, err
@@ -77,6 +114,18 @@ func (r *resolver) Resolve(ctx devspacecontext.Context, options ResolveOptions)
 		return nil, err
 	}
 
+	// Save lock file, dependencies that are not used anymore are only removed if
+	// all dependencies were resolved
+	if !options.ReadOnly {
+		if len(options.SkipDependencies) == 0 && !r.skipped {
+			r.lockFile.Prune()
+		}
+		err = r.lockFile.Save()
+		if err != nil {
+			return nil, errors.Wrap(err, "save lock file")
+		}
+	}
+
 	// Save local cache
 	err = r.BaseCache.Save()
 	if err != nil {
@@ -92,6 +141,61 @@ func (r *resolver) Resolve(ctx devspacecontext.Context, options ResolveOptions)
 	return children, nil
 }
 
+// downloadDependency downloads the source of the dependency and returns the path to its config. Remote
+// dependencies that were vendored are resolved from the vendor folder of the project instead
+func (r *resolver) downloadDependency(ctx devspacecontext.Context, basePath string, dependencyConfig *latest.DependencyConfig, options ResolveOptions) (string, error) {
+	if !options.Vendor && !options.Update {
+		vendoredConfigPath, err := util.GetVendoredDependencyPath(r.vendorPath, dependencyConfig.Source)
+		if err != nil {
+			return "", err
+		} else if vendoredConfigPath != "" {
+			ctx.Log().Debugf("Use vendored dependency %s", dependencyConfig.Name)
+			r.lockFile.Keep(dependencyConfig.Source)
+			return vendoredConfigPath, nil
+		}
+	}
+
+	pinned, err := r.lockFile.Pin(dependencyConfig.Source, options.Update)
+	if err != nil {
+		return "", err
+	}
+
+	// transient failures, such as an unavailable git server, are retried
+	var dependencyConfigPath string
+	err = options.Retry.Retry(ctx.Context(), dependencyConfig.Name, ctx.Log(), func() error {
+		dependencyConfigPath, err = util.DownloadPinnedDependency(ctx.Context(), basePath, dependencyConfig.Source, pinned, ctx.Log())
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = r.lockFile.Lock(ctx.Context(), dependencyConfig.Source)
+	if err != nil {
+		ctx.Log().Warnf("Error locking dependency %s: %v", dependencyConfig.Name, err)
+	}
+
+	if options.Vendor {
+		err = util.VendorDependency(r.vendorPath, dependencyConfig.Source)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return dependencyConfigPath, nil
+}
+
+// skipLocked keeps the locked commit of a dependency that is currently skipped, so that it is
+// checked out at the same commit once it is used again
+func (r *resolver) skipLocked(dependencyConfig *latest.DependencyConfig) {
+	r.skipped = true
+	r.lockFile.Keep(dependencyConfig.Source)
+}
+
+func (r *resolver) Tree() *Tree {
+	return newTree(r.DependencyGraph)
+}
+
 func (r *resolver) WithParser(parser loader.Parser) ResolverInterface {
 	if r == nil {
 		return nil
@@ -122,13 +226,26 @@ func (r *resolver) resolveRecursive(ctx devspacecontext.Context, basePath, paren
 
 		if dependencyConfig.Disabled {
 			ctx.Log().Debugf("Skip dependency %s, because it is disabled", dependencyConfig.Name)
+			r.skipLocked(dependencyConfig)
 			continue
 		}
 
-		dependencyConfigPath, err := util.DownloadDependency(ctx.Context(), basePath, dependencyConfig.Source, ctx.Log())
+		enabled, err := evaluateCondition(ctx, basePath, dependencyConfig.When)
+		if err != nil {
+			return errors.Wrapf(err, "dependency %s", dependencyConfig.Name)
+		} else if !enabled {
+			ctx.Log().Debugf("Skip dependency %s, because its condition %s is false", dependencyConfig.Name, dependencyConfig.When)
+			r.skipLocked(dependencyConfig)
+			continue
+		}
+
+		dependencyConfigPath, err := r.downloadDependency(ctx, basePath, dependencyConfig, options)
 		if err != nil {
 			return err
 		}
+		if isVirtual(dependencyConfig) {
+			dependencyConfigPath = virtualConfigPath(filepath.Dir(dependencyConfigPath), dependencyConfig.Name)
+		}
 
 		// Try to insert new edge
 		var (
@@ -136,32 +253,56 @@ func (r *resolver) resolveRecursive(ctx devspacecontext.Context, basePath, paren
 		)
 		if n, ok := r.DependencyGraph.Nodes[dependencyConfig.Name]; ok {
 			child = n.Data.(*Dependency)
-			if child != nil && child.Config() != nil && child.Config().Path() != dependencyConfigPath && !child.Root() {
+			if child != nil && child.Config() != nil && !pathutil.Equal(child.Config().Path(), dependencyConfigPath) && !child.Root() {
 				ctx.Log().Warnf("Seems like you have multiple dependencies with name %s, but they use different source settings (%s != %s). This can lead to unexpected results and you should make sure that the devspace.yaml name is unique across your dependencies or that you use the dependencies.overrideName option", child.name, child.Config().Path(), dependencyConfigPath)
 			}
 
 			err := r.DependencyGraph.AddEdge(parentConfigName, dependencyConfig.Name)
 			if err != nil {
-				if _, ok := err.(*graph.CyclicError); !ok {
+				cyclicErr, ok := err.(*graph.CyclicError)
+				if !ok {
 					return err
 				}
 
+				switch options.CyclePolicy {
+				case types.CyclePolicyFail:
+					configPath := ctx.Config().Path()
+					if currentDependency != nil && currentDependency.Config() != nil {
+						configPath = currentDependency.Config().Path()
+					}
+
+					return newCycleError(cyclicErr, r.DependencyGraph.Root.ID, parentConfigName, dependencyConfig.Name, configPath)
+				case types.CyclePolicyIgnoreBackEdge:
+					ctx.Log().Debugf("Ignore cyclic reference from %s to dependency %s", parentConfigName, dependencyConfig.Name)
+					continue
+				}
+
 				ctx.Log().Debugf(err.Error())
 			}
 		} else {
-			child, err = r.resolveDependency(ctx, dependencyConfigPath, dependencyConfig.Name, dependencyConfig)
+			child, err = r.resolveDependency(ctx, currentDependency, dependencyConfigPath, dependencyConfig.Name, dependencyConfig)
 			if err != nil {
 				return err
 			}
 
-			_, err = r.DependencyGraph.InsertNodeAt(parentConfigName, dependencyConfig.Name, child)
+			node, err := r.DependencyGraph.InsertNodeAt(parentConfigName, dependencyConfig.Name, child)
 			if err != nil {
 				return errors.Wrap(err, "insert node")
 			}
 
+			node.Metadata, err = nodeMetadata(dependencyConfig, dependencyConfigPath)
+			if err != nil {
+				return errors.Wrapf(err, "dependency %s", dependencyConfig.Name)
+			}
+
 			// load dependencies from dependency
-			if !dependencyConfig.IgnoreDependencies && child.localConfig.Config().Dependencies != nil && len(child.localConfig.Config().Dependencies) > 0 {
-				err = r.resolveRecursive(ctx, child.absolutePath, dependencyConfig.Name, child, transformMap(child.localConfig.Config().Dependencies), options)
+			if !dependencyConfig.IgnoreDependencies && hasDependencies(child.localConfig.Config()) {
+				childDependencies, err := withWorkspace(child.localConfig.Config(), child.absolutePath)
+				if err != nil {
+					return errors.Wrapf(err, "dependency %s", dependencyConfig.Name)
+				}
+
+				err = r.resolveRecursive(ctx, child.absolutePath, dependencyConfig.Name, child, childDependencies, options)
 				if err != nil {
 					return err
 				}
@@ -177,6 +318,40 @@ func (r *resolver) resolveRecursive(ctx devspacecontext.Context, basePath, paren
 	return nil
 }
 
+// nodeMetadata describes where the dependency was resolved from. The hash is the hash of its
+// config file, or of its commands if the dependency is virtual
+func nodeMetadata(dependencyConfig *latest.DependencyConfig, dependencyConfigPath string) (graph.Metadata, error) {
+	metadata := graph.Metadata{
+		Path: filepath.Dir(dependencyConfigPath),
+	}
+	if isVirtual(dependencyConfig) {
+		metadata.SourceType = "virtual"
+		metadata.Hash = hash.String(strings.Join(dependencyConfig.Commands, "\n"))
+		return metadata, nil
+	}
+
+	if dependencyConfig.Source != nil {
+		switch {
+		case dependencyConfig.Source.Git != "":
+			metadata.SourceType = "git"
+		case dependencyConfig.Source.OCI != "":
+			metadata.SourceType = "oci"
+		case dependencyConfig.Source.Helm != nil:
+			metadata.SourceType = "helm"
+		case dependencyConfig.Source.Path != "":
+			metadata.SourceType = "path"
+		}
+	}
+
+	configHash, err := hash.File(dependencyConfigPath)
+	if err != nil {
+		return metadata, errors.Wrap(err, "hash config")
+	}
+
+	metadata.Hash = configHash
+	return metadata, nil
+}
+
 func transformMap(depMap map[string]*latest.DependencyConfig) []*latest.DependencyConfig {
 	dependencies := []*latest.DependencyConfig{}
 	for _, dep := range depMap {
@@ -188,9 +363,18 @@ func transformMap(depMap map[string]*latest.DependencyConfig) []*latest.Dependen
 	return dependencies
 }
 
-func (r *resolver) resolveDependency(ctx devspacecontext.Context, dependencyConfigPath, dependencyName string, dependency *latest.DependencyConfig) (*Dependency, error) {
+// resolveDependency loads the config of the dependency. Nested dependencies inherit the variables of
+// the dependency that references them, while profiles and overrides only apply to the dependency itself
+func (r *resolver) resolveDependency(ctx devspacecontext.Context, parent *Dependency, dependencyConfigPath, dependencyName string, dependency *latest.DependencyConfig) (*Dependency, error) {
+	parentOptions := r.ConfigOptions
+	parentConfig := ctx.Config()
+	if parent != nil && parent.configOptions != nil {
+		parentOptions = parent.configOptions
+		parentConfig = parent.localConfig
+	}
+
 	// clone config options
-	cloned, err := r.ConfigOptions.Clone()
+	cloned, err := parentOptions.Clone()
 	if err != nil {
 		return nil, errors.Wrap(err, "clone config options")
 	}
@@ -200,6 +384,11 @@ func (r *resolver) resolveDependency(ctx devspacecontext.Context, dependencyConf
 	cloned.Profiles = []string{}
 	cloned.Profiles = append(cloned.Profiles, dependency.Profiles...)
 	cloned.DisableProfileActivation = dependency.DisableProfileActivation || r.ConfigOptions.DisableProfileActivation
+	cloned.Set = dependency.Set
+	cloned.RawConfig = nil
+	if isVirtual(dependency) {
+		cloned.RawConfig = virtualConfig(dependency)
+	}
 
 	// load config
 	if cloned.Vars == nil {
@@ -207,7 +396,7 @@ func (r *resolver) resolveDependency(ctx devspacecontext.Context, dependencyConf
 	}
 
 	if dependency.OverwriteVars {
-		for k, v := range ctx.Config().Variables() {
+		for k, v := range parentConfig.Variables() {
 			cloned.Vars = append(cloned.Vars, strings.TrimSpace(k)+"="+strings.TrimSpace(fmt.Sprintf("%v", v)))
 		}
 	}
@@ -217,17 +406,19 @@ func (r *resolver) resolveDependency(ctx devspacecontext.Context, dependencyConf
 
 	// recreate client if necessary
 	client := ctx.KubeClient()
-	if dependency.Namespace != "" {
-		if ctx.KubeClient() == nil {
-			client, err = kubectl.NewClientFromContext("", dependency.Namespace, false, kubeconfig.NewLoader())
-		} else {
-			client, err = kubectl.NewClientFromContext(client.CurrentContext(), dependency.Namespace, false, ctx.KubeClient().KubeConfigLoader())
-		}
+	if dependency.Namespace != "" || dependency.KubeContext != "" {
+		client, err = newDependencyKubeClient(ctx.KubeClient(), dependency)
 		if err != nil {
 			return nil, errors.Wrap(err, "create new client")
 		}
 	}
 
+	// restore the local cache of the dependency
+	err = RestoreCache(ctx.Context(), r.cacheStore, dependencyName, dependencyConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
 	// load the dependency config
 	var dConfigWrapper config.Config
 	err = executeInDirectory(filepath.Dir(dependencyConfigPath), func() error {
@@ -253,7 +444,7 @@ func (r *resolver) resolveDependency(ctx devspacecontext.Context, dependencyConf
 
 	// set parsed variables in parent config
 	if dependency.OverwriteVars {
-		baseVars := ctx.Config().Variables()
+		baseVars := parentConfig.Variables()
 		for k, v := range dConfigWrapper.Variables() {
 			_, ok := baseVars[k]
 			if !ok {
@@ -270,11 +461,33 @@ func (r *resolver) resolveDependency(ctx devspacecontext.Context, dependencyConf
 
 		dependencyConfig: dependency,
 		dependencyCache:  r.BaseCache,
+		configOptions:    cloned,
 
 		kubeClient: client,
 	}, nil
 }
 
+// newDependencyKubeClient creates a client for the namespace and kube context of the dependency. Settings
+// the dependency doesn't override are taken from the parent client
+func newDependencyKubeClient(parent kubectl.Client, dependency *latest.DependencyConfig) (kubectl.Client, error) {
+	if parent == nil {
+		return kubectl.NewClientFromContext(dependency.KubeContext, dependency.Namespace, false, kubeconfig.NewLoader())
+	}
+
+	kubeContext := dependency.KubeContext
+	if kubeContext == "" {
+		kubeContext = parent.CurrentContext()
+	}
+
+	// the parent namespace is only kept within the same kube context
+	namespace := dependency.Namespace
+	if namespace == "" && kubeContext == parent.CurrentContext() {
+		namespace = parent.Namespace()
+	}
+
+	return kubectl.NewClientFromContext(kubeContext, namespace, false, parent.KubeConfigLoader())
+}
+
 func executeInDirectory(dir string, fn func() error) error {
 	oldWorkingDirectory, err := os.Getwd()
 	if err != nil {
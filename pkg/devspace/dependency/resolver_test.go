@@ -2,12 +2,17 @@ package dependency
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/util"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
@@ -15,12 +20,15 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
 	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
 	"github.com/loft-sh/devspace/pkg/util/fsutil"
+	fakekubeconfig "github.com/loft-sh/devspace/pkg/util/kubeconfig/testing"
 	log "github.com/loft-sh/devspace/pkg/util/log/testing"
 
 	"gotest.tools/assert"
 	"k8s.io/client-go/kubernetes/fake"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	yaml "gopkg.in/yaml.v3"
 )
@@ -243,3 +251,240 @@ func mustGetDependencyID(config *latest.DependencyConfig) string {
 	id, _ := util.GetDependencyID(config.Source)
 	return id
 }
+
+func TestNewDependencyKubeClient(t *testing.T) {
+	loader := &fakekubeconfig.Loader{
+		RawConfig: &clientcmdapi.Config{
+			CurrentContext: "dev",
+			Clusters: map[string]*clientcmdapi.Cluster{
+				"dev":     {Server: "https://dev.example.com"},
+				"staging": {Server: "https://staging.example.com"},
+			},
+			AuthInfos: map[string]*clientcmdapi.AuthInfo{
+				"user": {Token: "token"},
+			},
+			Contexts: map[string]*clientcmdapi.Context{
+				"dev":     {Cluster: "dev", AuthInfo: "user", Namespace: "dev-ns"},
+				"staging": {Cluster: "staging", AuthInfo: "user", Namespace: "staging-ns"},
+			},
+		},
+	}
+	parent, err := kubectl.NewClientFromContext("dev", "parent", false, loader)
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		dependency        *latest.DependencyConfig
+		expectedContext   string
+		expectedNamespace string
+	}{
+		{
+			dependency:        &latest.DependencyConfig{Namespace: "other"},
+			expectedContext:   "dev",
+			expectedNamespace: "other",
+		},
+		{
+			dependency:        &latest.DependencyConfig{KubeContext: "dev"},
+			expectedContext:   "dev",
+			expectedNamespace: "parent",
+		},
+		{
+			dependency:        &latest.DependencyConfig{KubeContext: "staging"},
+			expectedContext:   "staging",
+			expectedNamespace: "staging-ns",
+		},
+		{
+			dependency:        &latest.DependencyConfig{KubeContext: "staging", Namespace: "other"},
+			expectedContext:   "staging",
+			expectedNamespace: "other",
+		},
+	}
+
+	for i, testCase := range testCases {
+		client, err := newDependencyKubeClient(parent, testCase.dependency)
+		assert.NilError(t, err, "test case %d", i)
+		assert.Equal(t, client.CurrentContext(), testCase.expectedContext, "test case %d", i)
+		assert.Equal(t, client.Namespace(), testCase.expectedNamespace, "test case %d", i)
+	}
+}
+
+func TestResolverCyclePolicy(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NilError(t, err)
+
+	wdBackup, err := os.Getwd()
+	assert.NilError(t, err)
+	assert.NilError(t, os.Chdir(dir))
+	defer func() {
+		_ = os.Chdir(wdBackup)
+	}()
+
+	// a -> b -> a
+	files := map[string]*latest.Config{
+		"a/devspace.yaml": {
+			Version: latest.Version,
+			Dependencies: map[string]*latest.DependencyConfig{
+				"b": {Name: "b", Source: &latest.SourceConfig{Path: "../b"}},
+			},
+		},
+		"b/devspace.yaml": {
+			Version: latest.Version,
+			Dependencies: map[string]*latest.DependencyConfig{
+				"a": {Name: "a", Source: &latest.SourceConfig{Path: "../a"}},
+			},
+		},
+	}
+	for path, content := range files {
+		asYAML, err := yaml.Marshal(content)
+		assert.NilError(t, err)
+		assert.NilError(t, fsutil.WriteToFile(asYAML, path))
+	}
+
+	testCases := []struct {
+		policy              types.CyclePolicy
+		expectedErr         bool
+		expectedBackEdgeLen int
+	}{
+		{policy: "", expectedBackEdgeLen: 1},
+		{policy: types.CyclePolicyDeployOnce, expectedBackEdgeLen: 1},
+		{policy: types.CyclePolicyIgnoreBackEdge, expectedBackEdgeLen: 0},
+		{policy: types.CyclePolicyFail, expectedErr: true},
+		{policy: "unknown", expectedErr: true},
+	}
+	for _, testCase := range testCases {
+		conf := config.NewConfig(map[string]interface{}{}, map[string]interface{}{}, &latest.Config{
+			Name: "root",
+			Dependencies: map[string]*latest.DependencyConfig{
+				"a": {Name: "a", Source: &latest.SourceConfig{Path: "a"}},
+			},
+		}, localcache.New(constants.DefaultConfigPath), &remotecache.RemoteCache{}, map[string]interface{}{}, constants.DefaultConfigPath)
+		devCtx := devspacecontext.NewContext(context.Background(), nil, log.NewFakeLogger()).WithConfig(conf)
+
+		dependencies, err := NewResolver(devCtx, &loader.ConfigOptions{}).Resolve(devCtx, ResolveOptions{CyclePolicy: testCase.policy})
+		if testCase.expectedErr {
+			assert.Assert(t, err != nil, "expected error for cycle policy %s", testCase.policy)
+			continue
+		}
+
+		assert.NilError(t, err, "cycle policy %s", testCase.policy)
+		assert.Equal(t, len(dependencies), 1)
+		assert.Equal(t, dependencies[0].Name(), "a")
+		assert.Equal(t, len(dependencies[0].Children()), 1)
+		assert.Equal(t, dependencies[0].Children()[0].Name(), "b")
+		assert.Equal(t, len(dependencies[0].Children()[0].Children()), testCase.expectedBackEdgeLen, "cycle policy %s", testCase.policy)
+	}
+}
+
+func TestResolverRetry(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NilError(t, err)
+
+	wdBackup, err := os.Getwd()
+	assert.NilError(t, err)
+	assert.NilError(t, os.Chdir(dir))
+	defer func() {
+		_ = os.Chdir(wdBackup)
+	}()
+
+	dependencyFolderPath := util.DependencyFolderPath
+	util.DependencyFolderPath = filepath.Join(dir, "dependencyFolder")
+	defer func() {
+		util.DependencyFolderPath = dependencyFolderPath
+	}()
+
+	// the first request fails with a transient error
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte("version: " + latest.Version + "\nname: remote\n"))
+	}))
+	defer server.Close()
+
+	resolve := func(retry types.RetryOptions) ([]types.Dependency, error) {
+		requests = 0
+		_ = os.RemoveAll(util.DependencyFolderPath)
+		conf := config.NewConfig(map[string]interface{}{}, map[string]interface{}{}, &latest.Config{
+			Name: "root",
+			Dependencies: map[string]*latest.DependencyConfig{
+				"remote": {Name: "remote", Source: &latest.SourceConfig{Path: server.URL + "/remote"}},
+			},
+		}, localcache.New(constants.DefaultConfigPath), &remotecache.RemoteCache{}, map[string]interface{}{}, constants.DefaultConfigPath)
+		devCtx := devspacecontext.NewContext(context.Background(), nil, log.NewFakeLogger()).WithConfig(conf)
+		return NewResolver(devCtx, &loader.ConfigOptions{}).Resolve(devCtx, ResolveOptions{Retry: retry})
+	}
+
+	_, err = resolve(types.RetryOptions{})
+	assert.ErrorContains(t, err, "unexpected status code 503")
+	assert.Equal(t, requests, 1)
+
+	dependencies, err := resolve(types.RetryOptions{Attempts: 3, Backoff: time.Millisecond})
+	assert.NilError(t, err)
+	assert.Equal(t, requests, 2)
+	assert.Equal(t, len(dependencies), 1)
+	assert.Equal(t, dependencies[0].Name(), "remote")
+}
+
+func TestResolverLockFilePrune(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NilError(t, err)
+
+	wdBackup, err := os.Getwd()
+	assert.NilError(t, err)
+	assert.NilError(t, os.Chdir(dir))
+	defer func() {
+		_ = os.Chdir(wdBackup)
+	}()
+
+	source := &latest.SourceConfig{Git: "https://github.com/loft-sh/example.git", Branch: "main"}
+	ID, err := util.GetDependencyID(source)
+	assert.NilError(t, err)
+
+	// the comment is lost whenever the lock file is rewritten
+	const comment = "# pinned by devspace\n"
+	resolve := func(dependencies map[string]*latest.DependencyConfig, options ResolveOptions) *util.LockFile {
+		lockFile, err := util.LoadLockFile(util.LockFileName)
+		assert.NilError(t, err)
+		lockFile.Dependencies[ID] = &util.LockedDependency{Git: source.Git, Branch: source.Branch, Commit: "f8b2aa8"}
+		out, err := yaml.Marshal(lockFile)
+		assert.NilError(t, err)
+		assert.NilError(t, os.WriteFile(util.LockFileName, append([]byte(comment), out...), 0644))
+
+		conf := config.NewConfig(map[string]interface{}{}, map[string]interface{}{}, &latest.Config{
+			Name:         "root",
+			Dependencies: dependencies,
+		}, localcache.New(constants.DefaultConfigPath), &remotecache.RemoteCache{}, map[string]interface{}{}, constants.DefaultConfigPath)
+		devCtx := devspacecontext.NewContext(context.Background(), nil, log.NewFakeLogger()).WithConfig(conf)
+		_, err = NewResolver(devCtx, &loader.ConfigOptions{}).Resolve(devCtx, options)
+		assert.NilError(t, err)
+
+		lockFile, err = util.LoadLockFile(util.LockFileName)
+		assert.NilError(t, err)
+		return lockFile
+	}
+	rewritten := func() bool {
+		out, err := os.ReadFile(util.LockFileName)
+		assert.NilError(t, err)
+		return !strings.HasPrefix(string(out), comment)
+	}
+
+	// dependencies that are skipped by their condition keep their commit
+	lockFile := resolve(map[string]*latest.DependencyConfig{
+		"example": {Name: "example", Source: source, When: "false"},
+	}, ResolveOptions{})
+	assert.Equal(t, lockFile.Dependencies[ID].Commit, "f8b2aa8")
+	assert.Assert(t, !rewritten(), "lock file was rewritten without changes")
+
+	// read only resolves never write the lock file
+	lockFile = resolve(nil, ResolveOptions{ReadOnly: true})
+	assert.Equal(t, lockFile.Dependencies[ID].Commit, "f8b2aa8")
+	assert.Assert(t, !rewritten(), "read only resolve rewrote the lock file")
+
+	// removed dependencies are pruned
+	lockFile = resolve(nil, ResolveOptions{})
+	assert.Equal(t, len(lockFile.Dependencies), 0)
+	assert.Assert(t, rewritten())
+}
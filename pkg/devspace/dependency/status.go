@@ -0,0 +1,260 @@
+package dependency
+
+import (
+	"os"
+	"strings"
+
+	ghodssyaml "github.com/ghodss/yaml"
+	"github.com/loft-sh/devspace/pkg/devspace/build/builder/helper"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/devspace/helm"
+	"github.com/loft-sh/devspace/pkg/util/hash"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// DependencyState describes if a dependency has changed since it was deployed
+type DependencyState string
+
+const (
+	// DependencyStateUpToDate means nothing has changed since the last deployment
+	DependencyStateUpToDate DependencyState = "up-to-date"
+	// DependencyStateOutOfDate means an image or deployment has changed since the last deployment
+	DependencyStateOutOfDate DependencyState = "out-of-date"
+	// DependencyStateNotDeployed means at least one deployment of the dependency is not deployed
+	DependencyStateNotDeployed DependencyState = "not deployed"
+)
+
+// DependencyStatus is the status of a single dependency
+type DependencyStatus struct {
+	// Name is the name of the dependency
+	Name string
+
+	// State is the drift state of the dependency
+	State DependencyState
+
+	// Reasons describes what has changed
+	Reasons []string
+
+	// DirectoryHash is the current hash of the files in the dependency directory
+	DirectoryHash string
+}
+
+// StatusAll resolves all dependencies and compares the cached directory and image hashes, the built
+// image tags and the deployed releases with the current state of each dependency
+func (m *manager) StatusAll(ctx devspacecontext.Context, options ResolveOptions) ([]DependencyStatus, error) {
+	statuses := []DependencyStatus{}
+	_, err := m.handleDependencies(ctx, options, "Status", func(ctx devspacecontext.Context, dependency *Dependency) error {
+		status, err := Status(ctx, dependency)
+		if err != nil {
+			return err
+		}
+
+		statuses = append(statuses, *status)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// Status compares the current state of a single dependency with its last deployment. The context
+// is expected to be the context of the dependency itself
+func Status(ctx devspacecontext.Context, dependency types.Dependency) (*DependencyStatus, error) {
+	status := &DependencyStatus{
+		Name:  dependency.Name(),
+		State: DependencyStateUpToDate,
+	}
+	if dependency.Config() == nil || dependency.Config().Config() == nil {
+		return status, nil
+	}
+
+	config := dependency.Config().Config()
+	for _, deploymentConfig := range config.Deployments {
+		deployed, reason, err := deploymentDrift(ctx, deploymentConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "status of deployment %s", deploymentConfig.Name)
+		} else if !deployed {
+			status.State = DependencyStateNotDeployed
+			status.Reasons = append(status.Reasons, "deployment "+deploymentConfig.Name+" is not deployed")
+		} else if reason != "" {
+			status.Reasons = append(status.Reasons, "deployment "+deploymentConfig.Name+": "+reason)
+		}
+	}
+
+	// with a change detection only the fingerprint of the dependency files is compared
+	// instead of hashing the build context of every image
+	fingerprint, err := Fingerprint(ctx.Context(), dependency)
+	if err != nil {
+		return nil, err
+	}
+	status.DirectoryHash, err = DirectoryHash(dependency)
+	if err != nil {
+		return nil, err
+	}
+	if fingerprint != "" {
+		savedFingerprint, _ := dependency.Config().LocalCache().GetData(fingerprintKey)
+		if savedFingerprint == "" && isVirtual(dependency.DependencyConfig()) {
+			status.State = DependencyStateNotDeployed
+			status.Reasons = append(status.Reasons, "commands were never run")
+		} else if savedFingerprint != fingerprint {
+			status.Reasons = append(status.Reasons, "files have changed")
+		}
+	} else {
+		savedDirectoryHash, _ := dependency.Config().LocalCache().GetData(directoryHashKey)
+		if savedDirectoryHash != "" && savedDirectoryHash != status.DirectoryHash {
+			status.Reasons = append(status.Reasons, "dependency directory has changed")
+		}
+
+		for _, imageConfig := range config.Images {
+			reason, err := imageDrift(ctx, imageConfig)
+			if err != nil {
+				return nil, errors.Wrapf(err, "status of image %s", imageConfig.Name)
+			} else if reason != "" {
+				status.Reasons = append(status.Reasons, "image "+imageConfig.Name+": "+reason)
+			}
+		}
+	}
+
+	if status.State == DependencyStateUpToDate && len(status.Reasons) > 0 {
+		status.State = DependencyStateOutOfDate
+	}
+
+	return status, nil
+}
+
+// imageDrift returns why the image would be rebuilt or an empty string if it is up to date
+func imageDrift(ctx devspacecontext.Context, imageConfig *latest.Image) (string, error) {
+	if imageConfig.Custom != nil || imageConfig.Plugin != nil {
+		imageCache, _ := ctx.Config().LocalCache().GetImageCache(imageConfig.Name)
+		if imageCache.Tag == "" {
+			return "image was never built", nil
+		}
+
+		return "", nil
+	}
+
+	return helper.NewBuildHelper(ctx, "", imageConfig, nil).RebuildReason(ctx)
+}
+
+// deploymentDrift returns if the deployment is deployed and why it would be redeployed. The hashes are
+// calculated the same way as the helm and kubectl deployers do
+func deploymentDrift(ctx devspacecontext.Context, deploymentConfig *latest.DeploymentConfig) (bool, string, error) {
+	deployCache, ok := ctx.Config().RemoteCache().GetDeployment(deploymentConfig.Name)
+	if !ok {
+		return false, "", nil
+	}
+
+	if deploymentConfig.Helm != nil {
+		return helmDrift(ctx, deploymentConfig, deployCache.DeploymentConfigHash, deployCache.Helm)
+	} else if deploymentConfig.Kubectl != nil {
+		configStr, err := ghodssyaml.Marshal(deploymentConfig)
+		if err != nil {
+			return false, "", errors.Wrap(err, "marshal deployment config")
+		} else if deployCache.DeploymentConfigHash != hash.String(string(configStr)) {
+			return true, "deployment config has changed", nil
+		}
+
+		manifestsHash, err := kubectlManifestsHash(ctx, deploymentConfig.Kubectl)
+		if err != nil {
+			return false, "", err
+		} else if deployCache.Kubectl != nil && deployCache.Kubectl.ManifestsHash != manifestsHash {
+			return true, "manifests have changed", nil
+		}
+	}
+
+	return true, "", nil
+}
+
+func helmDrift(ctx devspacecontext.Context, deploymentConfig *latest.DeploymentConfig, deploymentConfigHash string, helmCache *remotecache.HelmCache) (bool, string, error) {
+	if helmCache == nil {
+		helmCache = &remotecache.HelmCache{}
+	}
+
+	// check if the release still exists
+	if helmCache.Release != "" && ctx.KubeClient() != nil {
+		helmClient, err := helm.NewClient(ctx.Log())
+		if err == nil {
+			releases, err := helmClient.ListReleases(ctx, helmCache.ReleaseNamespace)
+			if err == nil {
+				found := false
+				for _, release := range releases {
+					if release.Name == helmCache.Release {
+						found = true
+						break
+					}
+				}
+				if !found {
+					return false, "", nil
+				}
+			}
+		}
+	}
+
+	configStr, err := yaml.Marshal(deploymentConfig)
+	if err != nil {
+		return false, "", errors.Wrap(err, "marshal deployment config")
+	} else if deploymentConfigHash != hash.String(string(configStr)) {
+		return true, "deployment config has changed", nil
+	}
+
+	// check if the local chart has changed
+	if deploymentConfig.Helm.Chart != nil && deploymentConfig.Helm.Chart.Source == nil && deploymentConfig.Helm.Chart.Name != "" {
+		chartPath := ctx.ResolvePath(deploymentConfig.Helm.Chart.Name)
+		if _, err := os.Stat(chartPath); err == nil {
+			chartHash, err := hash.DirectoryExcludes(chartPath, []string{
+				".git/",
+				".devspace/",
+			}, true)
+			if err != nil {
+				return false, "", errors.Errorf("Error hashing chart directory: %v", err)
+			} else if helmCache.ChartHash != chartHash {
+				return true, "chart has changed", nil
+			}
+		}
+	}
+
+	// check if the values files have changed
+	overridesHash := ""
+	for _, valuesFile := range deploymentConfig.Helm.ValuesFiles {
+		valuesFileHash, err := hash.Directory(ctx.ResolvePath(valuesFile))
+		if err != nil {
+			return false, "", errors.Errorf("Error stating values file %s: %v", valuesFile, err)
+		}
+
+		overridesHash += valuesFileHash
+	}
+	if helmCache.OverridesHash != overridesHash {
+		return true, "values files have changed", nil
+	}
+
+	return true, "", nil
+}
+
+func kubectlManifestsHash(ctx devspacecontext.Context, kubectlConfig *latest.KubectlConfig) (string, error) {
+	manifestsHash := ""
+	for _, manifest := range kubectlConfig.Manifests {
+		manifest = strings.ReplaceAll(manifest, "*", "")
+		if kubectlConfig.Kustomize != nil && *kubectlConfig.Kustomize {
+			manifest = strings.TrimSuffix(manifest, "kustomization.yaml")
+		}
+		if strings.HasPrefix(manifest, "http://") || strings.HasPrefix(manifest, "https://") {
+			manifestsHash += hash.String(manifest)
+			continue
+		}
+
+		manifestHash, err := hash.Directory(ctx.ResolvePath(manifest))
+		if err != nil {
+			return "", errors.Errorf("Error hashing %s: %v", manifest, err)
+		}
+
+		manifestsHash += manifestHash
+	}
+
+	return manifestsHash, nil
+}
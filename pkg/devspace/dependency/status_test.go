@@ -0,0 +1,89 @@
+package dependency
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ghodssyaml "github.com/ghodss/yaml"
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/hash"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestDependencyStatus(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte("kind: Deployment"), 0644)
+	assert.NilError(t, err)
+
+	deploymentConfig := &latest.DeploymentConfig{
+		Name: "backend",
+		Kubectl: &latest.KubectlConfig{
+			Manifests: []string{"deployment.yaml"},
+		},
+	}
+	configStr, err := ghodssyaml.Marshal(deploymentConfig)
+	assert.NilError(t, err)
+
+	remoteCache := remotecache.NewCache("test", "test")
+	dependency := &Dependency{
+		name: "backend",
+		localConfig: config.NewConfig(nil, nil, &latest.Config{
+			Deployments: map[string]*latest.DeploymentConfig{
+				"backend": deploymentConfig,
+			},
+		}, localcache.New(filepath.Join(dir, ".devspace", "cache.yaml")), remoteCache, nil, filepath.Join(dir, "devspace.yaml")),
+	}
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).
+		WithConfig(dependency.Config()).
+		WithWorkingDir(dir)
+
+	// not deployed yet
+	status, err := Status(ctx, dependency)
+	assert.NilError(t, err)
+	assert.Equal(t, status.State, DependencyStateNotDeployed)
+
+	// deployed with the current manifests
+	manifestsHash, err := kubectlManifestsHash(ctx, deploymentConfig.Kubectl)
+	assert.NilError(t, err)
+	remoteCache.SetDeployment("backend", remotecache.DeploymentCache{
+		Name:                 "backend",
+		DeploymentConfigHash: hash.String(string(configStr)),
+		Kubectl: &remotecache.KubectlCache{
+			ManifestsHash: manifestsHash,
+		},
+	})
+	status, err = Status(ctx, dependency)
+	assert.NilError(t, err)
+	assert.Equal(t, status.State, DependencyStateUpToDate)
+	assert.Equal(t, len(status.Reasons), 0)
+
+	// manifests changed after the deployment
+	err = os.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte("kind: StatefulSet"), 0644)
+	assert.NilError(t, err)
+	status, err = Status(ctx, dependency)
+	assert.NilError(t, err)
+	assert.Equal(t, status.State, DependencyStateOutOfDate)
+	assert.DeepEqual(t, status.Reasons, []string{"deployment backend: manifests have changed"})
+
+	// files of the dependency changed after its last run
+	dependency.absolutePath = dir
+	assert.NilError(t, SaveFingerprint(context.Background(), dependency))
+	status, err = Status(ctx, dependency)
+	assert.NilError(t, err)
+	assert.Assert(t, status.DirectoryHash != "")
+	assert.DeepEqual(t, status.Reasons, []string{"deployment backend: manifests have changed"})
+
+	err = os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644)
+	assert.NilError(t, err)
+	status, err = Status(ctx, dependency)
+	assert.NilError(t, err)
+	assert.Equal(t, status.State, DependencyStateOutOfDate)
+	assert.DeepEqual(t, status.Reasons, []string{"deployment backend: manifests have changed", "dependency directory has changed"})
+}
@@ -0,0 +1,83 @@
+package dependency
+
+import (
+	"sort"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/graph"
+)
+
+// Tree is the resolved dependency graph. The nodes and edges are sorted by name, so
+// that the tree can be rendered without knowing the internal graph
+type Tree struct {
+	// Root is the name of the project the dependencies were resolved for
+	Root string `json:"root"`
+
+	Nodes []TreeNode `json:"nodes"`
+	Edges []TreeEdge `json:"edges"`
+}
+
+// TreeNode is a resolved dependency or the root project
+type TreeNode struct {
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+	Root bool   `json:"root,omitempty"`
+
+	Source      *latest.SourceConfig `json:"source,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Pipeline    string               `json:"pipeline,omitempty"`
+	Namespace   string               `json:"namespace,omitempty"`
+	KubeContext string               `json:"kubeContext,omitempty"`
+}
+
+// TreeEdge means that the dependency From depends on the dependency To
+type TreeEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// newTree converts the dependency graph into a tree
+func newTree(dependencyGraph *graph.Graph) *Tree {
+	tree := &Tree{
+		Root:  dependencyGraph.Root.ID,
+		Nodes: []TreeNode{},
+		Edges: []TreeEdge{},
+	}
+
+	for _, node := range dependencyGraph.Nodes {
+		treeNode := TreeNode{
+			Name: node.ID,
+			Root: node == dependencyGraph.Root,
+		}
+		if dependency, ok := node.Data.(*Dependency); ok && dependency != nil {
+			treeNode.Path = dependency.Path()
+			if dependencyConfig := dependency.DependencyConfig(); dependencyConfig != nil {
+				treeNode.Source = dependencyConfig.Source
+				treeNode.Tags = dependencyConfig.Tags
+				treeNode.Pipeline = dependencyConfig.Pipeline
+				treeNode.Namespace = dependencyConfig.Namespace
+				treeNode.KubeContext = dependencyConfig.KubeContext
+			}
+		}
+		tree.Nodes = append(tree.Nodes, treeNode)
+
+		for _, child := range node.Childs {
+			tree.Edges = append(tree.Edges, TreeEdge{
+				From: node.ID,
+				To:   child.ID,
+			})
+		}
+	}
+
+	sort.Slice(tree.Nodes, func(i, j int) bool {
+		return tree.Nodes[i].Name < tree.Nodes[j].Name
+	})
+	sort.Slice(tree.Edges, func(i, j int) bool {
+		if tree.Edges[i].From != tree.Edges[j].From {
+			return tree.Edges[i].From < tree.Edges[j].From
+		}
+
+		return tree.Edges[i].To < tree.Edges[j].To
+	})
+	return tree
+}
@@ -0,0 +1,92 @@
+package dependency
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/fsutil"
+	log "github.com/loft-sh/devspace/pkg/util/log/testing"
+	yaml "gopkg.in/yaml.v3"
+	"gotest.tools/assert"
+)
+
+func TestManagerTree(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NilError(t, err)
+
+	wdBackup, err := os.Getwd()
+	assert.NilError(t, err)
+	assert.NilError(t, os.Chdir(dir))
+	defer func() {
+		_ = os.Chdir(wdBackup)
+	}()
+
+	// root -> a -> c, root -> b -> c
+	files := map[string]*latest.Config{
+		"a/devspace.yaml": {
+			Version: latest.Version,
+			Dependencies: map[string]*latest.DependencyConfig{
+				"c": {Name: "c", Source: &latest.SourceConfig{Path: "../c"}},
+			},
+		},
+		"b/devspace.yaml": {
+			Version: latest.Version,
+			Dependencies: map[string]*latest.DependencyConfig{
+				"c": {Name: "c", Source: &latest.SourceConfig{Path: "../c"}},
+			},
+		},
+		"c/devspace.yaml": {
+			Version: latest.Version,
+		},
+	}
+	for path, content := range files {
+		asYAML, err := yaml.Marshal(content)
+		assert.NilError(t, err)
+		assert.NilError(t, fsutil.WriteToFile(asYAML, path))
+	}
+
+	conf := config.NewConfig(map[string]interface{}{}, map[string]interface{}{}, &latest.Config{
+		Name: "root",
+		Dependencies: map[string]*latest.DependencyConfig{
+			"a": {Name: "a", Source: &latest.SourceConfig{Path: "a"}, Tags: []string{"backend"}},
+			"b": {Name: "b", Source: &latest.SourceConfig{Path: "b"}, Pipeline: "dev"},
+		},
+	}, localcache.New(constants.DefaultConfigPath), &remotecache.RemoteCache{}, map[string]interface{}{}, constants.DefaultConfigPath)
+	devCtx := devspacecontext.NewContext(context.Background(), nil, log.NewFakeLogger()).WithConfig(conf)
+
+	manager := NewManager(devCtx, &loader.ConfigOptions{})
+	tree := manager.Tree()
+	assert.Equal(t, tree.Root, "root")
+	assert.Equal(t, len(tree.Nodes), 1)
+	assert.Equal(t, len(tree.Edges), 0)
+
+	_, err = manager.ResolveAll(devCtx, ResolveOptions{})
+	assert.NilError(t, err)
+
+	tree = manager.Tree()
+	names := []string{}
+	for _, node := range tree.Nodes {
+		names = append(names, node.Name)
+	}
+	assert.DeepEqual(t, names, []string{"a", "b", "c", "root"})
+	assert.Equal(t, tree.Nodes[3].Root, true)
+	assert.DeepEqual(t, tree.Nodes[0].Tags, []string{"backend"})
+	assert.Equal(t, tree.Nodes[0].Source.Path, "a")
+	assert.Equal(t, tree.Nodes[0].Path, filepath.Join(dir, "a"))
+	assert.Equal(t, tree.Nodes[1].Pipeline, "dev")
+	assert.DeepEqual(t, tree.Edges, []TreeEdge{
+		{From: "a", To: "c"},
+		{From: "b", To: "c"},
+		{From: "root", To: "a"},
+		{From: "root", To: "b"},
+	})
+}
@@ -0,0 +1,14 @@
+package types
+
+import "context"
+
+// CacheStore keeps the local caches of dependencies outside of the project, so that a clean
+// checkout, such as a CI runner, can skip the work of dependencies that haven't changed
+type CacheStore interface {
+	// Load returns the stored cache of the dependency with the given name or nil if
+	// nothing was stored yet
+	Load(ctx context.Context, name string) ([]byte, error)
+
+	// Save stores the cache of the dependency with the given name
+	Save(ctx context.Context, name string, cache []byte) error
+}
@@ -0,0 +1,31 @@
+package types
+
+import "fmt"
+
+// CyclePolicy defines how the resolver handles a dependency that references one of its
+// own parents
+type CyclePolicy string
+
+const (
+	// CyclePolicyDeployOnce keeps the cyclic reference, the dependency is only deployed by the
+	// pipeline that reaches it first. This is the default
+	CyclePolicyDeployOnce CyclePolicy = "deploy-once"
+	// CyclePolicyIgnoreBackEdge removes the cyclic reference, so the dependency is only a child
+	// of the dependency that references it first in alphabetical order
+	CyclePolicyIgnoreBackEdge CyclePolicy = "ignore-back-edge"
+	// CyclePolicyFail fails the resolution if there is a cyclic reference
+	CyclePolicyFail CyclePolicy = "fail"
+)
+
+// ParseCyclePolicy returns the cycle policy with the given name, an empty name
+// returns the default policy
+func ParseCyclePolicy(name string) (CyclePolicy, error) {
+	switch CyclePolicy(name) {
+	case "", CyclePolicyDeployOnce:
+		return CyclePolicyDeployOnce, nil
+	case CyclePolicyIgnoreBackEdge, CyclePolicyFail:
+		return CyclePolicy(name), nil
+	}
+
+	return "", fmt.Errorf("unknown cycle policy %s, expected one of %s, %s or %s", name, CyclePolicyDeployOnce, CyclePolicyIgnoreBackEdge, CyclePolicyFail)
+}
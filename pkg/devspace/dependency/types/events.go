@@ -0,0 +1,98 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventType is the type of dependency event
+type EventType string
+
+const (
+	// EventPending is emitted when a dependency is scheduled to be executed
+	EventPending EventType = "pending"
+	// EventStarted is emitted before a dependency action is executed
+	EventStarted EventType = "started"
+	// EventSucceeded is emitted after a dependency action was executed successfully
+	EventSucceeded EventType = "succeeded"
+	// EventFailed is emitted after a dependency action has failed
+	EventFailed EventType = "failed"
+	// EventCancelled is emitted for a pending dependency that is not started anymore, because
+	// the pipeline was cancelled or another dependency failed
+	EventCancelled EventType = "cancelled"
+)
+
+// Event describes the progress of a single dependency action
+type Event struct {
+	Time time.Time `json:"time"`
+	Type EventType `json:"type"`
+
+	// Dependency is the name of the dependency
+	Dependency string `json:"dependency"`
+
+	// Action is the executed action, such as the pipeline of the dependency, e.g. build, deploy or purge
+	Action string `json:"action"`
+
+	// DurationMs is the duration of the action in milliseconds. Only set for succeeded and failed events
+	DurationMs int64 `json:"durationMs,omitempty"`
+
+	// Error is the error message of a failed event
+	Error string `json:"error,omitempty"`
+}
+
+// EventHandler receives dependency events. It might be called concurrently
+type EventHandler func(event Event)
+
+// Emit passes the event to the handler, if there is one
+func (h EventHandler) Emit(event Event) {
+	if h == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	h(event)
+}
+
+// Track emits a started event for the action and returns a function that emits the succeeded or
+// failed event, depending on the passed error
+func (h EventHandler) Track(dependency, action string) func(err error) {
+	start := time.Now()
+	h.Emit(Event{
+		Time:       start,
+		Type:       EventStarted,
+		Dependency: dependency,
+		Action:     action,
+	})
+
+	return func(err error) {
+		event := Event{
+			Type:       EventSucceeded,
+			Dependency: dependency,
+			Action:     action,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			event.Type = EventFailed
+			event.Error = err.Error()
+		}
+
+		h.Emit(event)
+	}
+}
+
+// NewJSONEventWriter returns an event handler that writes every event as a single line of json
+// to the given writer
+func NewJSONEventWriter(w io.Writer) EventHandler {
+	m := sync.Mutex{}
+	encoder := json.NewEncoder(w)
+	return func(event Event) {
+		m.Lock()
+		defer m.Unlock()
+
+		_ = encoder.Encode(event)
+	}
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestJSONEventWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := NewJSONEventWriter(buf)
+	handler.Track("api", "deploy")(nil)
+	handler.Track("database", "deploy")(errors.New("release failed"))
+
+	// a nil handler ignores events
+	var noHandler EventHandler
+	noHandler.Track("api", "deploy")(nil)
+
+	events := []Event{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		event := Event{}
+		assert.NilError(t, json.Unmarshal([]byte(line), &event))
+		assert.Assert(t, !event.Time.IsZero())
+		event.Time = event.Time.UTC()
+		events = append(events, event)
+	}
+
+	assert.Equal(t, len(events), 4)
+	assert.Equal(t, events[0].Type, EventStarted)
+	assert.Equal(t, events[0].Dependency, "api")
+	assert.Equal(t, events[1].Type, EventSucceeded)
+	assert.Equal(t, events[1].Action, "deploy")
+	assert.Equal(t, events[3].Type, EventFailed)
+	assert.Equal(t, events[3].Dependency, "database")
+	assert.Equal(t, events[3].Error, "release failed")
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// RetryOptions describe how often a failed dependency action should be retried
+type RetryOptions struct {
+	// Attempts is the maximum number of times the action is executed. Zero or one
+	// means the action is not retried
+	Attempts int
+
+	// Backoff is the time to wait before the first retry, it is doubled after every
+	// failed attempt
+	Backoff time.Duration
+}
+
+// Retry executes the given action until it succeeds, the attempts are exhausted or the
+// context is cancelled. The error of the last attempt is returned
+func (r RetryOptions) Retry(ctx context.Context, name string, log log.Logger, action func() error) error {
+	backoff := r.Backoff
+	for attempt := 1; ; attempt++ {
+		err := action()
+		if err == nil || attempt >= r.Attempts || ctx.Err() != nil {
+			return err
+		}
+
+		log.Warnf("Dependency %s failed (attempt %d/%d), retrying in %s: %v", name, attempt, r.Attempts, backoff.String(), err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestRetry(t *testing.T) {
+	attempts := 0
+	err := RetryOptions{Attempts: 3, Backoff: time.Millisecond}.Retry(context.Background(), "test", log.Discard, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("registry returned 503")
+		}
+
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, attempts, 3)
+
+	attempts = 0
+	err = RetryOptions{Attempts: 2, Backoff: time.Millisecond}.Retry(context.Background(), "test", log.Discard, func() error {
+		attempts++
+		return errors.New("release failed")
+	})
+	assert.Error(t, err, "release failed")
+	assert.Equal(t, attempts, 2)
+
+	// no retries by default
+	attempts = 0
+	err = RetryOptions{}.Retry(context.Background(), "test", log.Discard, func() error {
+		attempts++
+		return errors.New("release failed")
+	})
+	assert.Error(t, err, "release failed")
+	assert.Equal(t, attempts, 1)
+}
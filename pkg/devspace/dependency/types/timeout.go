@@ -0,0 +1,41 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RunWithTimeout executes the given action and cancels its context if it doesn't finish within
+// the timeout. If the action doesn't return after its context was cancelled, RunWithTimeout
+// still returns, so that a hanging dependency can't block the other dependencies forever.
+// A timeout of zero or less disables the timeout
+func RunWithTimeout(ctx context.Context, name string, timeout time.Duration, action func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return action(ctx)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- action(timeoutCtx)
+	}()
+
+	select {
+	case err := <-errChan:
+		if err != nil && ctx.Err() == nil && timeoutCtx.Err() == context.DeadlineExceeded {
+			return errors.Errorf("dependency %s timed out after %s", name, timeout.String())
+		}
+
+		return err
+	case <-timeoutCtx.Done():
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		return errors.Errorf("dependency %s timed out after %s", name, timeout.String())
+	}
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	err := RunWithTimeout(context.Background(), "test", time.Second, func(ctx context.Context) error {
+		return nil
+	})
+	assert.NilError(t, err)
+
+	err = RunWithTimeout(context.Background(), "test", 0, func(ctx context.Context) error {
+		return errors.New("release failed")
+	})
+	assert.Error(t, err, "release failed")
+
+	// an action that respects the context
+	err = RunWithTimeout(context.Background(), "test", time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.Error(t, err, "dependency test timed out after 1ms")
+
+	// an action that hangs
+	hang := make(chan struct{})
+	defer close(hang)
+	err = RunWithTimeout(context.Background(), "test", time.Millisecond, func(ctx context.Context) error {
+		<-hang
+		return nil
+	})
+	assert.Error(t, err, "dependency test timed out after 1ms")
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/encoding"
+	"gopkg.in/yaml.v3"
+)
+
+// writeHelmDependencyConfig generates a devspace.yaml in the local path that deploys
+// just the given chart. The file is only rewritten if its content has changed
+func writeHelmDependencyConfig(localPath string, source *latest.HelmSourceConfig) error {
+	name := encoding.Convert(path.Base(source.Chart))
+	out, err := yaml.Marshal(&latest.Config{
+		Version: latest.Version,
+		Name:    name,
+		Deployments: map[string]*latest.DeploymentConfig{
+			name: {
+				Helm: &latest.HelmConfig{
+					Chart: &latest.ChartConfig{
+						Name:    source.Chart,
+						RepoURL: source.Repo,
+						Version: source.Version,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(localPath, constants.DefaultConfigPath)
+	existing, err := os.ReadFile(configPath)
+	if err == nil && bytes.Equal(existing, out) {
+		return nil
+	}
+
+	err = os.MkdirAll(localPath, 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(configPath, out, 0644)
+}
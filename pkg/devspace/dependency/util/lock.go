@@ -0,0 +1,161 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/git"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName is the name of the file next to the devspace.yaml that pins git dependencies to a commit
+const LockFileName = "devspace.lock"
+
+// LockFile records the commits the git dependencies were resolved to, so that a dependency
+// that references a branch or tag doesn't change silently between runs
+type LockFile struct {
+	// Dependencies are the locked dependencies by their dependency id
+	Dependencies map[string]*LockedDependency `yaml:"dependencies,omitempty"`
+
+	path    string
+	used    map[string]bool
+	changed bool
+}
+
+// LockedDependency is a git dependency pinned to a commit
+type LockedDependency struct {
+	Git      string `yaml:"git"`
+	Branch   string `yaml:"branch,omitempty"`
+	Tag      string `yaml:"tag,omitempty"`
+	Revision string `yaml:"revision,omitempty"`
+
+	// Commit is the commit the dependency was resolved to
+	Commit string `yaml:"commit"`
+}
+
+// LoadLockFile loads the lock file from the given path. If the file doesn't exist, an empty lock file is returned
+func LoadLockFile(path string) (*LockFile, error) {
+	lockFile := &LockFile{
+		Dependencies: map[string]*LockedDependency{},
+
+		path: path,
+		used: map[string]bool{},
+	}
+
+	out, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lockFile, nil
+		}
+
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(out, lockFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %s", path)
+	}
+	if lockFile.Dependencies == nil {
+		lockFile.Dependencies = map[string]*LockedDependency{}
+	}
+
+	return lockFile, nil
+}
+
+// Pin returns the commit the given git source should be checked out at. If the git dependency was
+// locked before, the locked commit is returned. If update is true or the source already references
+// a revision, an empty string is returned and the latest commit of the branch or tag is used
+func (l *LockFile) Pin(source *latest.SourceConfig, update bool) (string, error) {
+	if source == nil || source.Git == "" || source.Revision != "" || update {
+		return "", nil
+	}
+
+	ID, err := GetDependencyID(source)
+	if err != nil {
+		return "", err
+	}
+
+	locked, ok := l.Dependencies[ID]
+	if !ok || locked.Git != source.Git || locked.Branch != source.Branch || locked.Tag != source.Tag {
+		return "", nil
+	}
+
+	return locked.Commit, nil
+}
+
+// Lock records the commit the given git source was downloaded as
+func (l *LockFile) Lock(ctx context.Context, source *latest.SourceConfig) error {
+	if source == nil || source.Git == "" {
+		return nil
+	}
+
+	ID, err := GetDependencyID(source)
+	if err != nil {
+		return err
+	}
+
+	commit, err := git.GetHash(ctx, filepath.Join(DependencyFolderPath, ID))
+	if err != nil {
+		return errors.Wrapf(err, "get commit of dependency %s", source.Git)
+	}
+
+	l.used[ID] = true
+	locked := &LockedDependency{
+		Git:      source.Git,
+		Branch:   source.Branch,
+		Tag:      source.Tag,
+		Revision: source.Revision,
+		Commit:   commit,
+	}
+	if existing, ok := l.Dependencies[ID]; !ok || *existing != *locked {
+		l.Dependencies[ID] = locked
+		l.changed = true
+	}
+
+	return nil
+}
+
+// Keep marks the locked commit of the given git source as used without downloading the source
+func (l *LockFile) Keep(source *latest.SourceConfig) {
+	if source == nil || source.Git == "" {
+		return
+	}
+
+	ID, err := GetDependencyID(source)
+	if err == nil {
+		l.used[ID] = true
+	}
+}
+
+// Prune removes all dependencies that were not locked since the lock file was loaded
+func (l *LockFile) Prune() {
+	for ID := range l.Dependencies {
+		if !l.used[ID] {
+			delete(l.Dependencies, ID)
+			l.changed = true
+		}
+	}
+}
+
+// Save writes the lock file if it has changed
+func (l *LockFile) Save() error {
+	if !l.changed {
+		return nil
+	}
+
+	out, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(l.path, out, 0644)
+	if err != nil {
+		return err
+	}
+
+	l.changed = false
+	return nil
+}
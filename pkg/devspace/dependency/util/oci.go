@@ -0,0 +1,177 @@
+package util
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ociDigestFile holds the manifest digest of the pulled artifact, so that an artifact
+	// is only downloaded again if the reference points to a different manifest
+	ociDigestFile = ".devspace-oci-digest"
+
+	// ociTitleAnnotation is the annotation oras uses to store the file name of a layer
+	ociTitleAnnotation = "org.opencontainers.image.title"
+
+	// ociUnpackAnnotation is set by oras on layers that contain a packed directory
+	ociUnpackAnnotation = "io.deis.oras.content.unpack"
+)
+
+// pullOCIArtifact downloads the artifact with the given reference into the local path. Layers that
+// were pushed as files (e.g. via oras push) are stored with their title, directories and regular
+// image layers are extracted
+func pullOCIArtifact(ctx context.Context, reference, localPath string) error {
+	ref, err := name.ParseReference(reference)
+	if err != nil {
+		return errors.Wrapf(err, "parse reference %s", reference)
+	}
+
+	image, err := remote.Image(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return err
+	}
+
+	digest, err := image.Digest()
+	if err != nil {
+		return errors.Wrap(err, "get manifest digest")
+	}
+
+	// check if the artifact was already pulled
+	out, err := os.ReadFile(filepath.Join(localPath, ociDigestFile))
+	if err == nil && string(out) == digest.String() {
+		return nil
+	}
+
+	manifest, err := image.Manifest()
+	if err != nil {
+		return errors.Wrap(err, "get manifest")
+	}
+	layers, err := image.Layers()
+	if err != nil {
+		return errors.Wrap(err, "get layers")
+	} else if len(layers) != len(manifest.Layers) {
+		return errors.Errorf("unexpected number of layers in artifact %s", reference)
+	}
+
+	// extract into a temporary folder first, so that a failed pull doesn't destroy the cached artifact
+	tempPath := localPath + ".tmp"
+	_ = os.RemoveAll(tempPath)
+	err = os.MkdirAll(tempPath, 0755)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempPath)
+
+	for i, layer := range layers {
+		err = extractOCILayer(layer, manifest.Layers[i], tempPath)
+		if err != nil {
+			return errors.Wrapf(err, "extract layer %s", manifest.Layers[i].Digest.String())
+		}
+	}
+
+	err = os.WriteFile(filepath.Join(tempPath, ociDigestFile), []byte(digest.String()), 0644)
+	if err != nil {
+		return err
+	}
+
+	err = os.RemoveAll(localPath)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, localPath)
+}
+
+func extractOCILayer(layer v1.Layer, descriptor v1.Descriptor, targetPath string) error {
+	title := descriptor.Annotations[ociTitleAnnotation]
+	if descriptor.Annotations[ociUnpackAnnotation] == "true" || (title == "" && strings.Contains(string(descriptor.MediaType), "tar")) {
+		reader, err := layer.Uncompressed()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		return untar(reader, targetPath)
+	} else if title == "" {
+		return nil
+	}
+
+	filePath, err := safeJoin(targetPath, title)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return err
+	}
+
+	reader, err := layer.Compressed()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	return writeFile(filePath, reader, 0644)
+}
+
+// untar extracts the directories and regular files of the tar stream into the target path
+func untar(reader io.Reader, targetPath string) error {
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		filePath, err := safeJoin(targetPath, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(filePath, 0755)
+		case tar.TypeReg:
+			err = os.MkdirAll(filepath.Dir(filePath), 0755)
+			if err == nil {
+				err = writeFile(filePath, tarReader, os.FileMode(header.Mode).Perm())
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// safeJoin joins the name to the target path and makes sure the result does not escape the target path
+func safeJoin(targetPath, fileName string) (string, error) {
+	filePath := filepath.Join(targetPath, filepath.FromSlash(fileName))
+	if filePath != filepath.Clean(targetPath) && !strings.HasPrefix(filePath, filepath.Clean(targetPath)+string(filepath.Separator)) {
+		return "", errors.Errorf("path %s points outside of the artifact", fileName)
+	}
+
+	return filePath, nil
+}
+
+func writeFile(filePath string, reader io.Reader, mode os.FileMode) error {
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, reader)
+	return err
+}
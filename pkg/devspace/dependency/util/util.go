@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -16,6 +17,8 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/util/git"
 	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/pathutil"
+	"github.com/loft-sh/devspace/pkg/util/stringutil"
 	"github.com/mitchellh/go-homedir"
 	"github.com/pkg/errors"
 )
@@ -44,19 +47,15 @@ func GetDependencyPath(workingDirectory string, source *latest.SourceConfig) (co
 
 	// Resolve source
 	var localPath string
-	if source.Git != "" {
+	if source.Git != "" || source.OCI != "" || source.Helm != nil {
 		localPath = filepath.Join(DependencyFolderPath, ID)
 	} else if source.Path != "" {
 		if isURL(source.Path) {
 			localPath = filepath.Join(DependencyFolderPath, ID)
 		} else {
-			if filepath.IsAbs(source.Path) {
-				localPath = source.Path
-			} else {
-				localPath, err = filepath.Abs(filepath.Join(workingDirectory, filepath.FromSlash(source.Path)))
-				if err != nil {
-					return "", errors.Wrap(err, "filepath absolute")
-				}
+			localPath, err = getLocalDependencyPath(workingDirectory, source.Path)
+			if err != nil {
+				return "", err
 			}
 		}
 	}
@@ -79,6 +78,12 @@ func switchURLType(gitPath string) string {
 }
 
 func DownloadDependency(ctx context.Context, workingDirectory string, source *latest.SourceConfig, log log.Logger) (configPath string, err error) {
+	return DownloadPinnedDependency(ctx, workingDirectory, source, "", log)
+}
+
+// DownloadPinnedDependency downloads the dependency like DownloadDependency. If pinned is not empty, a git
+// dependency is checked out at the pinned commit of its branch or tag instead of the latest commit
+func DownloadPinnedDependency(ctx context.Context, workingDirectory string, source *latest.SourceConfig, pinned string, log log.Logger) (configPath string, err error) {
 	downloadMutex.Lock()
 	defer downloadMutex.Unlock()
 
@@ -110,27 +115,13 @@ func DownloadDependency(ctx context.Context, workingDirectory string, source *la
 				return "", err
 			}
 
-			err = repo.Clone(ctx, git.CloneOptions{
-				URL:            gitPath,
-				Tag:            source.Tag,
-				Branch:         source.Branch,
-				Commit:         source.Revision,
-				Args:           source.CloneArgs,
-				DisableShallow: source.DisableShallow,
-			})
+			err = repo.Clone(ctx, gitCloneOptions(gitPath, source, pinned))
 
 			if err != nil {
 				log.Warn("Error cloning repo: ", err)
 				newGitURL := switchURLType(gitPath)
 				log.Infof("Switching URL from %s to %s and will try cloning again", gitPath, newGitURL)
-				err = repo.Clone(ctx, git.CloneOptions{
-					URL:            newGitURL,
-					Tag:            source.Tag,
-					Branch:         source.Branch,
-					Commit:         source.Revision,
-					Args:           source.CloneArgs,
-					DisableShallow: source.DisableShallow,
-				})
+				err = repo.Clone(ctx, gitCloneOptions(newGitURL, source, pinned))
 
 				if err != nil {
 					log.Warn("Failed to clone repo with both HTTPS and SSH URL. Please make sure if your git login or ssh setup is correct.")
@@ -144,6 +135,34 @@ func DownloadDependency(ctx context.Context, workingDirectory string, source *la
 			}
 			log.Debugf("Pulled %s", gitPath)
 		}
+	} else if source.OCI != "" {
+		reference := strings.TrimSpace(source.OCI)
+
+		_ = os.MkdirAll(DependencyFolderPath, 0755)
+		localPath = filepath.Join(DependencyFolderPath, ID)
+
+		// Check if dependency exists
+		_, statErr := os.Stat(localPath)
+
+		// Update dependency
+		if !source.DisablePull || statErr != nil {
+			err = pullOCIArtifact(ctx, reference, localPath)
+			if err != nil {
+				if statErr == nil {
+					log.Warnf("Error pulling oci artifact %s: %v", reference, err)
+					return getDependencyConfigPath(localPath, source)
+				}
+
+				return "", errors.Wrap(err, "pull oci artifact")
+			}
+			log.Debugf("Pulled %s", reference)
+		}
+	} else if source.Helm != nil {
+		localPath = filepath.Join(DependencyFolderPath, ID)
+		err = writeHelmDependencyConfig(localPath, source.Helm)
+		if err != nil {
+			return "", errors.Wrap(err, "generate helm dependency config")
+		}
 	} else if source.Path != "" {
 		if isURL(source.Path) {
 			localPath = filepath.Join(DependencyFolderPath, ID)
@@ -154,29 +173,33 @@ func DownloadDependency(ctx context.Context, workingDirectory string, source *la
 			_, statErr := os.Stat(configPath)
 
 			if !source.DisablePull || statErr != nil {
-				// Create the file
-				out, err := os.Create(configPath)
+				// Get the data
+				resp, err := http.Get(source.Path)
+				if err == nil && resp.StatusCode != http.StatusOK {
+					_ = resp.Body.Close()
+					err = errors.Errorf("unexpected status code %d", resp.StatusCode)
+				}
 				if err != nil {
 					if statErr == nil {
-						log.Warnf("Error creating file: %v", err)
+						log.Warnf("Error retrieving url %s: %v", source.Path, err)
 						return getDependencyConfigPath(localPath, source)
 					}
 
-					return "", err
+					return "", errors.Wrapf(err, "request %s", source.Path)
 				}
-				defer out.Close()
+				defer resp.Body.Close()
 
-				// Get the data
-				resp, err := http.Get(source.Path)
+				// Create the file
+				out, err := os.Create(configPath)
 				if err != nil {
 					if statErr == nil {
-						log.Warnf("Error retrieving url %s: %v", source.Path, err)
+						log.Warnf("Error creating file: %v", err)
 						return getDependencyConfigPath(localPath, source)
 					}
 
-					return "", errors.Wrapf(err, "request %s", source.Path)
+					return "", err
 				}
-				defer resp.Body.Close()
+				defer out.Close()
 
 				// Write the body to file
 				_, err = io.Copy(out, resp.Body)
@@ -190,13 +213,9 @@ func DownloadDependency(ctx context.Context, workingDirectory string, source *la
 				}
 			}
 		} else {
-			if filepath.IsAbs(source.Path) {
-				localPath = source.Path
-			} else {
-				localPath, err = filepath.Abs(filepath.Join(workingDirectory, filepath.FromSlash(source.Path)))
-				if err != nil {
-					return "", errors.Wrap(err, "filepath absolute")
-				}
+			localPath, err = getLocalDependencyPath(workingDirectory, source.Path)
+			if err != nil {
+				return "", err
 			}
 		}
 	}
@@ -204,6 +223,21 @@ func DownloadDependency(ctx context.Context, workingDirectory string, source *la
 	return getDependencyConfigPath(localPath, source)
 }
 
+// getLocalDependencyPath returns the absolute normalized path of a local dependency, so that
+// the same dependency referenced with a different drive letter casing or slashes resolves to
+// the same path on windows
+func getLocalDependencyPath(workingDirectory, dependencyPath string) (string, error) {
+	if !filepath.IsAbs(dependencyPath) {
+		var err error
+		dependencyPath, err = filepath.Abs(filepath.Join(workingDirectory, filepath.FromSlash(dependencyPath)))
+		if err != nil {
+			return "", errors.Wrap(err, "filepath absolute")
+		}
+	}
+
+	return pathutil.Normalize(dependencyPath), nil
+}
+
 func getDependencyConfigPath(dependencyPath string, source *latest.SourceConfig) (string, error) {
 	var configPath string
 	if source.SubPath != "" {
@@ -235,12 +269,61 @@ func GetDependencyID(source *latest.SourceConfig) (string, error) {
 			id += "@revision:" + source.Revision
 		}
 
+		// a sparse checkout contains different files than a full checkout
+		if sparsePaths := gitSparsePaths(source); len(sparsePaths) > 0 {
+			id += "@sparse:" + strings.Join(sparsePaths, ",")
+		}
+
+		return encoding.Convert(id), nil
+	} else if source.OCI != "" {
+		return encoding.Convert("oci:" + strings.TrimSpace(source.OCI)), nil
+	} else if source.Helm != nil {
+		id := "helm:" + source.Helm.Repo + "/" + source.Helm.Chart
+		if source.Helm.Version != "" {
+			id += "@" + source.Helm.Version
+		}
+
 		return encoding.Convert(id), nil
 	} else if source.Path != "" {
 		return source.Path, nil
 	}
 
-	return "", fmt.Errorf("unexpected dependency config, both source.git and source.path are missing")
+	return "", fmt.Errorf("unexpected dependency config, source.git, source.path, source.oci and source.helm are missing")
+}
+
+func gitCloneOptions(url string, source *latest.SourceConfig, pinned string) git.CloneOptions {
+	return git.CloneOptions{
+		URL:            url,
+		Tag:            source.Tag,
+		Branch:         source.Branch,
+		Commit:         source.Revision,
+		Pinned:         pinned,
+		Args:           source.CloneArgs,
+		DisableShallow: source.DisableShallow,
+		Depth:          source.Depth,
+		SparsePaths:    gitSparsePaths(source),
+		Submodules:     source.Submodules,
+	}
+}
+
+// gitSparsePaths returns the sorted sparse paths of the source including the sub path
+func gitSparsePaths(source *latest.SourceConfig) []string {
+	if len(source.SparsePaths) == 0 {
+		return nil
+	}
+
+	sparsePaths := []string{}
+	for _, sparsePath := range append([]string{source.SubPath}, source.SparsePaths...) {
+		sparsePath = strings.Trim(filepath.ToSlash(filepath.Clean(sparsePath)), "/")
+		if sparsePath == "" || sparsePath == "." || stringutil.Contains(sparsePaths, sparsePath) {
+			continue
+		}
+
+		sparsePaths = append(sparsePaths, sparsePath)
+	}
+
+	sort.Strings(sparsePaths)
+	return sparsePaths
 }
 
 func isURL(path string) bool {
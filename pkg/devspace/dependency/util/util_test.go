@@ -1,8 +1,16 @@
 package util
 
 import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gopkg.in/yaml.v3"
 	"gotest.tools/assert"
 )
 
@@ -13,3 +21,172 @@ func TestSwitchURLType(t *testing.T) {
 	assert.Equal(t, sshURL, switchURLType(httpURL))
 	assert.Equal(t, httpURL, switchURLType(sshURL))
 }
+
+func TestOCIDependencyPath(t *testing.T) {
+	source := &latest.SourceConfig{OCI: "registry.example.com/team/service-config:v1"}
+	ID, err := GetDependencyID(source)
+	assert.NilError(t, err)
+
+	configPath, err := GetDependencyPath("", source)
+	assert.NilError(t, err)
+	assert.Equal(t, configPath, filepath.Join(DependencyFolderPath, ID, "devspace.yaml"))
+}
+
+func TestUntar(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := tar.NewWriter(buf)
+	assert.NilError(t, writer.WriteHeader(&tar.Header{Name: "config/", Typeflag: tar.TypeDir, Mode: 0755}))
+	content := []byte("version: v2beta1")
+	assert.NilError(t, writer.WriteHeader(&tar.Header{Name: "config/devspace.yaml", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+	_, err := writer.Write(content)
+	assert.NilError(t, err)
+	assert.NilError(t, writer.Close())
+
+	dir := t.TempDir()
+	assert.NilError(t, untar(buf, dir))
+	out, err := os.ReadFile(filepath.Join(dir, "config", "devspace.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), string(content))
+
+	// entries outside of the target path are rejected
+	buf = &bytes.Buffer{}
+	writer = tar.NewWriter(buf)
+	assert.NilError(t, writer.WriteHeader(&tar.Header{Name: "../escape.yaml", Typeflag: tar.TypeReg, Mode: 0644}))
+	assert.NilError(t, writer.Close())
+	assert.ErrorContains(t, untar(buf, dir), "points outside of the artifact")
+}
+
+func TestHelmDependency(t *testing.T) {
+	dependencyFolderPath := DependencyFolderPath
+	DependencyFolderPath = t.TempDir()
+	defer func() {
+		DependencyFolderPath = dependencyFolderPath
+	}()
+
+	source := &latest.SourceConfig{Helm: &latest.HelmSourceConfig{
+		Repo:    "https://charts.bitnami.com/bitnami",
+		Chart:   "redis",
+		Version: "17.3.2",
+	}}
+	configPath, err := DownloadDependency(context.Background(), "", source, log.Discard)
+	assert.NilError(t, err)
+
+	expectedPath, err := GetDependencyPath("", source)
+	assert.NilError(t, err)
+	assert.Equal(t, configPath, expectedPath)
+
+	out, err := os.ReadFile(configPath)
+	assert.NilError(t, err)
+	config := &latest.Config{}
+	assert.NilError(t, yaml.Unmarshal(out, config))
+	assert.Equal(t, config.Name, "redis")
+	assert.DeepEqual(t, config.Deployments["redis"].Helm.Chart, &latest.ChartConfig{
+		Name:    "redis",
+		RepoURL: "https://charts.bitnami.com/bitnami",
+		Version: "17.3.2",
+	})
+}
+
+func TestLockFile(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), LockFileName)
+	lockFile, err := LoadLockFile(lockPath)
+	assert.NilError(t, err)
+
+	source := &latest.SourceConfig{Git: "https://github.com/loft-sh/example.git", Branch: "main"}
+	pinned, err := lockFile.Pin(source, false)
+	assert.NilError(t, err)
+	assert.Equal(t, pinned, "")
+
+	ID, err := GetDependencyID(source)
+	assert.NilError(t, err)
+	lockFile.Dependencies[ID] = &LockedDependency{Git: source.Git, Branch: source.Branch, Commit: "f8b2aa8"}
+	lockFile.used[ID] = true
+	lockFile.changed = true
+	assert.NilError(t, lockFile.Save())
+
+	lockFile, err = LoadLockFile(lockPath)
+	assert.NilError(t, err)
+	pinned, err = lockFile.Pin(source, false)
+	assert.NilError(t, err)
+	assert.Equal(t, pinned, "f8b2aa8")
+
+	// the pinned commit is checked out on the shallow clone of the branch
+	options := gitCloneOptions(source.Git, source, pinned)
+	assert.Equal(t, options.Branch, "main")
+	assert.Equal(t, options.Commit, "")
+	assert.Equal(t, options.Pinned, "f8b2aa8")
+	assert.Assert(t, !options.DisableShallow)
+
+	// updates and changed sources are not pinned
+	pinned, err = lockFile.Pin(source, true)
+	assert.NilError(t, err)
+	assert.Equal(t, pinned, "")
+	otherBranch := &latest.SourceConfig{Git: source.Git, Branch: "develop"}
+	pinned, err = lockFile.Pin(otherBranch, false)
+	assert.NilError(t, err)
+	assert.Equal(t, pinned, "")
+
+	// unused dependencies are removed
+	lockFile.Prune()
+	assert.NilError(t, lockFile.Save())
+	lockFile, err = LoadLockFile(lockPath)
+	assert.NilError(t, err)
+	assert.Equal(t, len(lockFile.Dependencies), 0)
+}
+
+func TestGitSparsePaths(t *testing.T) {
+	source := &latest.SourceConfig{
+		Git:         "https://github.com/example/monorepo.git",
+		SubPath:     "services/api",
+		SparsePaths: []string{"libs/", "services/api", "./charts"},
+	}
+	assert.DeepEqual(t, gitSparsePaths(source), []string{"charts", "libs", "services/api"})
+
+	// sparse checkouts don't share the folder with full checkouts
+	sparseID, err := GetDependencyID(source)
+	assert.NilError(t, err)
+	source.SparsePaths = nil
+	assert.Assert(t, gitSparsePaths(source) == nil)
+	ID, err := GetDependencyID(source)
+	assert.NilError(t, err)
+	assert.Assert(t, sparseID != ID)
+}
+
+func TestVendorDependency(t *testing.T) {
+	dependencyFolderPath := DependencyFolderPath
+	DependencyFolderPath = t.TempDir()
+	defer func() {
+		DependencyFolderPath = dependencyFolderPath
+	}()
+
+	source := &latest.SourceConfig{Git: "https://github.com/loft-sh/example.git", Branch: "main", SubPath: "backend"}
+	ID, err := GetDependencyID(source)
+	assert.NilError(t, err)
+
+	downloadedPath := filepath.Join(DependencyFolderPath, ID)
+	assert.NilError(t, os.MkdirAll(filepath.Join(downloadedPath, ".git"), 0755))
+	assert.NilError(t, os.MkdirAll(filepath.Join(downloadedPath, "backend"), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(downloadedPath, ".git", "HEAD"), []byte("ref"), 0644))
+	assert.NilError(t, os.WriteFile(filepath.Join(downloadedPath, "backend", "devspace.yaml"), []byte("version: v2beta1"), 0644))
+
+	// not vendored yet
+	vendorPath := GetVendorPath(filepath.Join(t.TempDir(), "devspace.yaml"))
+	configPath, err := GetVendoredDependencyPath(vendorPath, source)
+	assert.NilError(t, err)
+	assert.Equal(t, configPath, "")
+
+	// vendored by the configured source without the git folder
+	assert.NilError(t, VendorDependency(vendorPath, source))
+	configPath, err = GetVendoredDependencyPath(vendorPath, source)
+	assert.NilError(t, err)
+	out, err := os.ReadFile(configPath)
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), "version: v2beta1")
+	_, err = os.Stat(filepath.Join(filepath.Dir(filepath.Dir(configPath)), ".git"))
+	assert.Assert(t, os.IsNotExist(err))
+
+	// local sources are never vendored
+	configPath, err = GetVendoredDependencyPath(vendorPath, &latest.SourceConfig{Path: "../backend"})
+	assert.NilError(t, err)
+	assert.Equal(t, configPath, "")
+}
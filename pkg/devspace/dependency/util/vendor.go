@@ -0,0 +1,74 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	recursiveCopy "github.com/otiai10/copy"
+	"github.com/pkg/errors"
+)
+
+// VendorFolder is the folder next to the devspace.yaml that holds the vendored remote dependencies
+const VendorFolder = ".devspace/vendor"
+
+// GetVendorPath returns the vendor folder of the project with the given config path
+func GetVendorPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), filepath.FromSlash(VendorFolder))
+}
+
+// GetVendoredDependencyPath returns the config path of the vendored copy of the given remote source. If
+// the source is not remote or was not vendored, an empty string is returned
+func GetVendoredDependencyPath(vendorPath string, source *latest.SourceConfig) (string, error) {
+	if !isRemoteSource(source) {
+		return "", nil
+	}
+
+	ID, err := GetDependencyID(source)
+	if err != nil {
+		return "", err
+	}
+
+	localPath := filepath.Join(vendorPath, ID)
+	_, err = os.Stat(localPath)
+	if err != nil {
+		return "", nil
+	}
+
+	return getDependencyConfigPath(localPath, source)
+}
+
+// VendorDependency copies the downloaded source into the vendor folder, so that the dependency can be
+// resolved without network access. The copy is stored by the id of the configured source
+func VendorDependency(vendorPath string, source *latest.SourceConfig) error {
+	if !isRemoteSource(source) {
+		return nil
+	}
+
+	ID, err := GetDependencyID(source)
+	if err != nil {
+		return err
+	}
+
+	targetPath := filepath.Join(vendorPath, ID)
+	err = os.RemoveAll(targetPath)
+	if err != nil {
+		return errors.Wrapf(err, "remove %s", targetPath)
+	}
+
+	err = recursiveCopy.Copy(filepath.Join(DependencyFolderPath, ID), targetPath, recursiveCopy.Options{
+		Skip: func(src string) (bool, error) {
+			return filepath.Base(src) == ".git", nil
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "vendor dependency %s", ID)
+	}
+
+	return nil
+}
+
+// isRemoteSource checks if the source needs to be downloaded
+func isRemoteSource(source *latest.SourceConfig) bool {
+	return source != nil && (source.Git != "" || source.OCI != "" || (source.Path != "" && isURL(source.Path)))
+}
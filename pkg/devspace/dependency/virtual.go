@@ -0,0 +1,60 @@
+package dependency
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+)
+
+// isVirtual checks if the dependency has no devspace.yaml and only runs commands
+func isVirtual(dependencyConfig *latest.DependencyConfig) bool {
+	return dependencyConfig != nil && len(dependencyConfig.Commands) > 0
+}
+
+// virtualConfigPath returns the config path of a virtual dependency in the given folder. The
+// file is never read, but the local cache of the dependency is stored next to it, so that it
+// doesn't share its cache with a devspace.yaml in the same folder
+func virtualConfigPath(dependencyPath, name string) string {
+	return filepath.Join(dependencyPath, "devspace-"+name+".yaml")
+}
+
+// virtualConfig returns the config of a virtual dependency, which runs its commands in
+// the deploy and dev pipelines
+func virtualConfig(dependencyConfig *latest.DependencyConfig) map[string]interface{} {
+	run := strings.Join(dependencyConfig.Commands, "\n")
+	return map[string]interface{}{
+		"version": latest.Version,
+		"name":    dependencyConfig.Name,
+		"pipelines": map[string]interface{}{
+			"deploy": map[string]interface{}{
+				"run": run,
+			},
+			"dev": map[string]interface{}{
+				"run": run,
+			},
+		},
+	}
+}
+
+// VirtualUnchanged checks if the dependency only runs commands and its files are unchanged since
+// the commands were run successfully the last time, in which case the commands don't need to run again
+func VirtualUnchanged(ctx context.Context, dependency types.Dependency) (bool, error) {
+	if !isVirtual(dependency.DependencyConfig()) || dependency.Config() == nil || dependency.Config().LocalCache() == nil {
+		return false, nil
+	}
+
+	savedFingerprint, _ := dependency.Config().LocalCache().GetData(fingerprintKey)
+	if savedFingerprint == "" {
+		return false, nil
+	}
+
+	fingerprint, err := Fingerprint(ctx, dependency)
+	if err != nil {
+		return false, err
+	}
+
+	return fingerprint == savedFingerprint, nil
+}
@@ -0,0 +1,72 @@
+package dependency
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/fsutil"
+	log "github.com/loft-sh/devspace/pkg/util/log/testing"
+	"gotest.tools/assert"
+)
+
+func TestVirtualDependency(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NilError(t, err)
+
+	wdBackup, err := os.Getwd()
+	assert.NilError(t, err)
+	assert.NilError(t, os.Chdir(dir))
+	defer func() {
+		_ = os.Chdir(wdBackup)
+	}()
+
+	assert.NilError(t, fsutil.WriteToFile([]byte("resource {}"), "infra/main.tf"))
+
+	conf := config.NewConfig(map[string]interface{}{}, map[string]interface{}{}, &latest.Config{
+		Name: "root",
+		Dependencies: map[string]*latest.DependencyConfig{
+			"infra": {
+				Name:     "infra",
+				Source:   &latest.SourceConfig{Path: "infra"},
+				Commands: []string{"terraform init", "terraform apply -auto-approve"},
+			},
+		},
+	}, localcache.New(constants.DefaultConfigPath), &remotecache.RemoteCache{}, map[string]interface{}{}, constants.DefaultConfigPath)
+	devCtx := devspacecontext.NewContext(context.Background(), nil, log.NewFakeLogger()).WithConfig(conf)
+
+	// the dependency is resolved without a devspace.yaml
+	dependencies, err := NewManager(devCtx, &loader.ConfigOptions{}).ResolveAll(devCtx, ResolveOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(dependencies), 1)
+	dependency := dependencies[0]
+	assert.Equal(t, dependency.Path(), filepath.Join(dir, "infra"))
+	assert.Equal(t, dependency.Config().Path(), filepath.Join(dir, "infra", "devspace-infra.yaml"))
+	assert.Equal(t, dependency.Config().Config().Pipelines["deploy"].Run, "terraform init\nterraform apply -auto-approve")
+	assert.Equal(t, dependency.Config().Config().Pipelines["dev"].Run, "terraform init\nterraform apply -auto-approve")
+
+	// the files of the dependency are tracked by its fingerprint
+	dependencyCtx := devCtx.AsDependency(dependency)
+	status, err := Status(dependencyCtx, dependency)
+	assert.NilError(t, err)
+	assert.Equal(t, status.State, DependencyStateNotDeployed)
+
+	assert.NilError(t, SaveFingerprint(context.Background(), dependency))
+	status, err = Status(dependencyCtx, dependency)
+	assert.NilError(t, err)
+	assert.Equal(t, status.State, DependencyStateUpToDate)
+
+	assert.NilError(t, fsutil.WriteToFile([]byte("resource {}\nresource {}"), "infra/main.tf"))
+	status, err = Status(dependencyCtx, dependency)
+	assert.NilError(t, err)
+	assert.Equal(t, status.State, DependencyStateOutOfDate)
+	assert.DeepEqual(t, status.Reasons, []string{"files have changed"})
+}
@@ -0,0 +1,145 @@
+package dependency
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/encoding"
+	"github.com/pkg/errors"
+)
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// hasDependencies checks if the config defines dependencies or discovers them through a workspace
+func hasDependencies(config *latest.Config) bool {
+	return config != nil && (len(config.Dependencies) > 0 || config.Workspace != nil)
+}
+
+// withWorkspace returns the dependencies of the config together with the dependencies that are
+// discovered through its workspace in the given folder
+func withWorkspace(config *latest.Config, basePath string) ([]*latest.DependencyConfig, error) {
+	dependencies := transformMap(config.Dependencies)
+	if config.Workspace == nil {
+		return dependencies, nil
+	}
+
+	discovered, err := discoverWorkspace(config.Workspace, basePath, config.Dependencies)
+	if err != nil {
+		return nil, errors.Wrap(err, "discover workspace")
+	}
+
+	dependencies = append(dependencies, discovered...)
+	sort.SliceStable(dependencies, func(i, j int) bool {
+		return dependencies[i].Name < dependencies[j].Name
+	})
+	return dependencies, nil
+}
+
+// discoverWorkspace returns a path dependency for every folder below the given folder that
+// matches the workspace and contains a devspace.yaml. Folders that are already referenced by
+// one of the given dependencies and names that are already taken are skipped
+func discoverWorkspace(workspace *latest.WorkspaceConfig, basePath string, dependencies map[string]*latest.DependencyConfig) ([]*latest.DependencyConfig, error) {
+	basePath, err := filepath.Abs(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	definedPaths := map[string]bool{}
+	for _, dependency := range dependencies {
+		if dependency.Source != nil && dependency.Source.Path != "" {
+			definedPaths[filepath.Clean(filepath.Join(basePath, dependency.Source.Path))] = true
+		}
+	}
+
+	folders := map[string]string{}
+	for _, pattern := range workspace.Include {
+		matches, err := doublestar.Glob(filepath.Join(basePath, pattern))
+		if err != nil {
+			return nil, errors.Wrapf(err, "include pattern %s", pattern)
+		}
+
+		for _, match := range matches {
+			relativePath, err := filepath.Rel(basePath, match)
+			if err != nil {
+				return nil, err
+			}
+
+			relativePath = filepath.ToSlash(relativePath)
+			if relativePath == "." || definedPaths[filepath.Clean(match)] || isIgnoredWorkspaceFolder(relativePath) {
+				continue
+			}
+
+			excluded, err := matchesAny(workspace.Exclude, relativePath)
+			if err != nil {
+				return nil, err
+			} else if excluded {
+				continue
+			}
+
+			stat, err := os.Stat(filepath.Join(match, constants.DefaultConfigPath))
+			if err != nil || stat.IsDir() {
+				continue
+			}
+
+			name := inferDependencyName(relativePath)
+			if encoding.IsUnsafeName(name) {
+				return nil, errors.Errorf("cannot infer a valid dependency name for folder %s, please add it to dependencies instead", relativePath)
+			} else if _, ok := dependencies[name]; ok {
+				continue
+			} else if other, ok := folders[name]; ok && other != relativePath {
+				return nil, errors.Errorf("folders %s and %s would both be named %s, please add one of them to dependencies or exclude it", other, relativePath, name)
+			}
+
+			folders[name] = relativePath
+		}
+	}
+
+	discovered := []*latest.DependencyConfig{}
+	for name, relativePath := range folders {
+		discovered = append(discovered, &latest.DependencyConfig{
+			Name: name,
+			Source: &latest.SourceConfig{
+				Path: "./" + relativePath,
+			},
+		})
+	}
+
+	return discovered, nil
+}
+
+// isIgnoredWorkspaceFolder checks if the folder lies within a .git or .devspace folder, which
+// contain downloaded and vendored dependencies
+func isIgnoredWorkspaceFolder(relativePath string) bool {
+	for _, segment := range strings.Split(relativePath, "/") {
+		if segment == ".git" || segment == ".devspace" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesAny(patterns []string, relativePath string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := doublestar.Match(filepath.ToSlash(filepath.Clean(pattern)), relativePath)
+		if err != nil {
+			return false, errors.Wrapf(err, "exclude pattern %s", pattern)
+		} else if matched {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// inferDependencyName converts the name of the folder into a valid dependency name
+func inferDependencyName(relativePath string) string {
+	name := strings.ToLower(filepath.Base(relativePath))
+	return strings.Trim(invalidNameCharacters.ReplaceAllString(name, "-"), "-")
+}
@@ -0,0 +1,58 @@
+package dependency
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"gotest.tools/assert"
+)
+
+func TestDiscoverWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig := func(path string) {
+		assert.NilError(t, os.MkdirAll(filepath.Join(dir, path), 0755))
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, path, "devspace.yaml"), []byte("version: v2beta1"), 0644))
+	}
+	writeConfig(".")
+	writeConfig("services/api")
+	writeConfig("services/Web_App")
+	writeConfig("services/legacy-billing")
+	writeConfig("services/custom")
+	writeConfig("tools/db")
+	writeConfig("services/.devspace/dependencies/vendored")
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "services", "docs"), 0755))
+
+	dependencies, err := withWorkspace(&latest.Config{
+		Dependencies: map[string]*latest.DependencyConfig{
+			"db":     {Name: "db", Source: &latest.SourceConfig{Path: "tools/db"}},
+			"custom": {Name: "custom", Source: &latest.SourceConfig{Git: "https://github.com/test/custom"}},
+		},
+		Workspace: &latest.WorkspaceConfig{
+			Include: []string{"services/*", "services/**", "tools/*"},
+			Exclude: []string{"services/legacy-*"},
+		},
+	}, dir)
+	assert.NilError(t, err)
+
+	// folders without a devspace.yaml, excluded folders and defined paths and names are skipped
+	sources := map[string]string{}
+	for _, dependency := range dependencies {
+		sources[dependency.Name] = dependency.Source.Path + dependency.Source.Git
+	}
+	assert.DeepEqual(t, sources, map[string]string{
+		"api":     "./services/api",
+		"custom":  "https://github.com/test/custom",
+		"db":      "tools/db",
+		"web-app": "./services/Web_App",
+	})
+	assert.Equal(t, dependencies[0].Name, "api")
+
+	// folders with the same name conflict
+	writeConfig("tools/api")
+	_, err = withWorkspace(&latest.Config{
+		Workspace: &latest.WorkspaceConfig{Include: []string{"services/*", "tools/*"}},
+	}, dir)
+	assert.ErrorContains(t, err, "would both be named api")
+}
@@ -0,0 +1,322 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// defaultCanaryPercentage is the default percentage of the replicas a canary is created with
+	defaultCanaryPercentage = 20
+
+	// canarySuffix is appended to the name of a deployment to get the name of its canary
+	canarySuffix = "-canary"
+
+	// CanaryLabel is set on the canary and its pods and holds the name of the deployment the canary belongs to
+	CanaryLabel = "devspace.sh/canary"
+)
+
+// startCanary creates a canary next to every existing Deployment of the deployment whose pod template has
+// changed and waits until the canaries are ready. If a canary fails, all canaries are deleted again and an error
+// is returned, so that the deployment is aborted before the existing Deployments are touched
+func startCanary(ctx devspacecontext.Context, deployClient deployer.Interface, deployConfig *latest.DeploymentConfig) ([]*appsv1.Deployment, error) {
+	buffer := &bytes.Buffer{}
+	err := deployClient.Render(ctx, buffer)
+	if err != nil {
+		return nil, errors.Wrap(err, "render manifests for canary")
+	}
+
+	objects, err := deployer.ParseObjects(buffer.String())
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := deployConfig.Namespace
+	if namespace == "" {
+		namespace = ctx.KubeClient().Namespace()
+	}
+
+	percentage := deployConfig.Canary.Percentage
+	if percentage == 0 {
+		percentage = defaultCanaryPercentage
+	}
+
+	client := ctx.KubeClient().KubeClient()
+	canaries := []*appsv1.Deployment{}
+	for _, obj := range objects {
+		if obj.GetKind() != "Deployment" {
+			continue
+		} else if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+
+		current, err := client.AppsV1().Deployments(obj.GetNamespace()).Get(ctx.Context(), obj.GetName(), metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			// there is nothing to protect for new deployments
+			continue
+		} else if err != nil {
+			_ = deleteCanaries(ctx, canaries)
+			return nil, err
+		}
+
+		changed, err := templateChanged(obj.Object, current)
+		if err != nil {
+			_ = deleteCanaries(ctx, canaries)
+			return nil, err
+		} else if !changed {
+			continue
+		}
+
+		desired := &appsv1.Deployment{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, desired)
+		if err != nil {
+			_ = deleteCanaries(ctx, canaries)
+			return nil, errors.Wrapf(err, "convert deployment %s", obj.GetName())
+		}
+
+		canary := newCanary(desired, percentage)
+		ctx.Log().Infof("Creating canary %s with %d replica(s)", canary.Name, *canary.Spec.Replicas)
+		_, err = client.AppsV1().Deployments(canary.Namespace).Create(ctx.Context(), canary, metav1.CreateOptions{})
+		if kerrors.IsAlreadyExists(err) {
+			// a canary of an aborted deploy is left over, so we update it instead
+			var existing *appsv1.Deployment
+			existing, err = client.AppsV1().Deployments(canary.Namespace).Get(ctx.Context(), canary.Name, metav1.GetOptions{})
+			if err == nil {
+				canary.ResourceVersion = existing.ResourceVersion
+				_, err = client.AppsV1().Deployments(canary.Namespace).Update(ctx.Context(), canary, metav1.UpdateOptions{})
+			}
+		}
+		if err != nil {
+			_ = deleteCanaries(ctx, canaries)
+			return nil, errors.Wrapf(err, "create canary %s", canary.Name)
+		}
+
+		canaries = append(canaries, canary)
+	}
+	if len(canaries) == 0 {
+		return nil, nil
+	}
+
+	err = waitForCanaries(ctx, deployConfig, canaries)
+	if err != nil {
+		deleteErr := deleteCanaries(ctx, canaries)
+		if deleteErr != nil {
+			ctx.Log().Warn(deleteErr)
+		}
+
+		return nil, errors.Wrap(err, "canary failed, aborting deployment")
+	}
+
+	return canaries, nil
+}
+
+// waitForCanaries waits until all replicas of the canaries are available
+func waitForCanaries(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig, canaries []*appsv1.Deployment) error {
+	timeout := time.Duration(deployConfig.Canary.Timeout) * time.Second
+	if deployConfig.Canary.Timeout == 0 {
+		timeout = defaultWaitTimeout * time.Second
+	}
+
+	ctx.Log().Infof("Waiting for the canaries of deployment %s to become ready...", deployConfig.Name)
+	lastMessage := ""
+	err := wait.PollImmediateWithContext(ctx.Context(), time.Second, timeout, func(context.Context) (bool, error) {
+		for _, canary := range canaries {
+			deployment, err := ctx.KubeClient().KubeClient().AppsV1().Deployments(canary.Namespace).Get(ctx.Context(), canary.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+
+			message, err := deploymentStatus(deployment)
+			if err != nil {
+				return false, err
+			} else if message != "" {
+				if message != lastMessage {
+					ctx.Log().Info(message)
+				}
+
+				lastMessage = message
+				return false, nil
+			}
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		if err == wait.ErrWaitTimeout {
+			return errors.Errorf("timed out after %s: %s", timeout.String(), lastMessage)
+		}
+
+		return err
+	}
+
+	ctx.Log().Donef("Canaries of deployment %s are ready", deployConfig.Name)
+	return nil
+}
+
+// promoteCanary waits until the Deployments the canaries belong to are rolled out completely
+func promoteCanary(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig, canaries []*appsv1.Deployment) error {
+	workloads := []workload{}
+	for _, canary := range canaries {
+		workloads = append(workloads, workload{
+			Kind:      "Deployment",
+			Name:      canary.Labels[CanaryLabel],
+			Namespace: canary.Namespace,
+		})
+	}
+
+	timeout := time.Duration(deployConfig.Canary.Timeout) * time.Second
+	if deployConfig.Canary.Timeout == 0 {
+		timeout = defaultWaitTimeout * time.Second
+	}
+
+	ctx.Log().Infof("Waiting for deployment %s to be rolled out to all replicas...", deployConfig.Name)
+	lastMessage := ""
+	err := wait.PollImmediateWithContext(ctx.Context(), time.Second, timeout, func(context.Context) (bool, error) {
+		message, err := notReady(ctx, &latest.DeploymentWaitConfig{}, workloads, canaries[0].Namespace)
+		if err != nil {
+			return false, err
+		} else if message != "" && message != lastMessage {
+			ctx.Log().Info(message)
+		}
+
+		lastMessage = message
+		return message == "", nil
+	})
+	if err != nil {
+		if err == wait.ErrWaitTimeout {
+			return errors.Errorf("timed out after %s waiting for deployment %s to be rolled out: %s", timeout.String(), deployConfig.Name, lastMessage)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// deleteCanaries deletes the canaries and their pods
+func deleteCanaries(ctx devspacecontext.Context, canaries []*appsv1.Deployment) error {
+	propagation := metav1.DeletePropagationBackground
+	for _, canary := range canaries {
+		ctx.Log().Infof("Deleting canary %s", canary.Name)
+		err := ctx.KubeClient().KubeClient().AppsV1().Deployments(canary.Namespace).Delete(ctx.Context(), canary.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrapf(err, "delete canary %s", canary.Name)
+		}
+	}
+
+	return nil
+}
+
+// newCanary returns a copy of the deployment with the percentage of its replicas, at least one. The canary
+// pods carry an additional label, so that they are not adopted by the replica sets of the deployment, but
+// still match the selectors of its services
+func newCanary(deployment *appsv1.Deployment, percentage int) *appsv1.Deployment {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	canaryReplicas := (replicas*int32(percentage) + 99) / 100
+	if canaryReplicas < 1 {
+		canaryReplicas = 1
+	}
+
+	canary := &appsv1.Deployment{
+		TypeMeta: deployment.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deployment.Name + canarySuffix,
+			Namespace: deployment.Namespace,
+			Labels:    map[string]string{},
+		},
+		Spec: *deployment.Spec.DeepCopy(),
+	}
+	for key, value := range deployment.Labels {
+		canary.Labels[key] = value
+	}
+	canary.Labels[CanaryLabel] = deployment.Name
+	canary.Spec.Replicas = &canaryReplicas
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{}}
+	if canary.Spec.Selector != nil {
+		selector = canary.Spec.Selector
+	}
+	if selector.MatchLabels == nil {
+		selector.MatchLabels = map[string]string{}
+	}
+	selector.MatchLabels[CanaryLabel] = deployment.Name
+	canary.Spec.Selector = selector
+
+	if canary.Spec.Template.Labels == nil {
+		canary.Spec.Template.Labels = map[string]string{}
+	}
+	canary.Spec.Template.Labels[CanaryLabel] = deployment.Name
+	return canary
+}
+
+// templateChanged returns true if the pod template of the rendered deployment sets a field that differs from
+// the pod template of the deployment in the cluster. Fields the rendered deployment doesn't set are ignored,
+// because the cluster fills them with defaults
+func templateChanged(desired map[string]interface{}, current *appsv1.Deployment) (bool, error) {
+	currentObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(current)
+	if err != nil {
+		return false, err
+	}
+
+	desiredTemplate, ok, _ := unstructured.NestedFieldNoCopy(desired, "spec", "template")
+	if !ok {
+		return false, fmt.Errorf("deployment has no pod template")
+	}
+	currentTemplate, _, _ := unstructured.NestedFieldNoCopy(currentObj, "spec", "template")
+	return !isDerivative(desiredTemplate, currentTemplate), nil
+}
+
+// isDerivative returns true if every value that is set in desired has the same value in current
+func isDerivative(desired, current interface{}) bool {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		currentValue, ok := current.(map[string]interface{})
+		if !ok {
+			return len(desiredValue) == 0
+		}
+
+		for key, value := range desiredValue {
+			if !isDerivative(value, currentValue[key]) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		currentValue, ok := current.([]interface{})
+		if !ok || len(desiredValue) != len(currentValue) {
+			return len(desiredValue) == 0 && current == nil
+		}
+
+		for i := range desiredValue {
+			if !isDerivative(desiredValue[i], currentValue[i]) {
+				return false
+			}
+		}
+
+		return true
+	case nil:
+		return true
+	case int64, int32, int, float64:
+		return fmt.Sprint(desiredValue) == fmt.Sprint(current)
+	default:
+		return reflect.DeepEqual(desired, current)
+	}
+}
@@ -0,0 +1,116 @@
+package deploy
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type newCanaryTestCase struct {
+	name string
+
+	replicas   *int32
+	percentage int
+
+	expectedReplicas int32
+}
+
+func TestNewCanary(t *testing.T) {
+	testCases := []newCanaryTestCase{
+		{
+			name:             "Default replicas",
+			percentage:       20,
+			expectedReplicas: 1,
+		},
+		{
+			name:             "Percentage of replicas",
+			replicas:         int32Ptr(10),
+			percentage:       20,
+			expectedReplicas: 2,
+		},
+		{
+			name:             "Round up",
+			replicas:         int32Ptr(3),
+			percentage:       50,
+			expectedReplicas: 2,
+		},
+		{
+			name:             "At least one replica",
+			replicas:         int32Ptr(0),
+			percentage:       10,
+			expectedReplicas: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "app",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "app"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: testCase.replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "app"}},
+				},
+			},
+		}
+
+		canary := newCanary(deployment, testCase.percentage)
+		assert.Equal(t, canary.Name, "app-canary", "Unexpected name in testCase %s", testCase.name)
+		assert.Equal(t, *canary.Spec.Replicas, testCase.expectedReplicas, "Unexpected replicas in testCase %s", testCase.name)
+		assert.DeepEqual(t, canary.Spec.Selector.MatchLabels, map[string]string{"app": "app", CanaryLabel: "app"})
+		assert.DeepEqual(t, canary.Spec.Template.Labels, map[string]string{"app": "app", CanaryLabel: "app"})
+
+		// the original deployment must not be changed
+		assert.DeepEqual(t, deployment.Spec.Selector.MatchLabels, map[string]string{"app": "app"})
+		assert.DeepEqual(t, deployment.Spec.Template.Labels, map[string]string{"app": "app"})
+	}
+}
+
+type isDerivativeTestCase struct {
+	name string
+
+	desired interface{}
+	current interface{}
+
+	expected bool
+}
+
+func TestIsDerivative(t *testing.T) {
+	testCases := []isDerivativeTestCase{
+		{
+			name:     "Defaulted fields",
+			desired:  map[string]interface{}{"image": "nginx"},
+			current:  map[string]interface{}{"image": "nginx", "imagePullPolicy": "Always"},
+			expected: true,
+		},
+		{
+			name:     "Changed image",
+			desired:  map[string]interface{}{"containers": []interface{}{map[string]interface{}{"image": "nginx:2"}}},
+			current:  map[string]interface{}{"containers": []interface{}{map[string]interface{}{"image": "nginx:1"}}},
+			expected: false,
+		},
+		{
+			name:     "Added container",
+			desired:  map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b"}}},
+			current:  map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "a"}}},
+			expected: false,
+		},
+		{
+			name:     "Different number types",
+			desired:  map[string]interface{}{"containerPort": int64(80)},
+			current:  map[string]interface{}{"containerPort": float64(80)},
+			expected: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, isDerivative(testCase.desired, testCase.current), testCase.expected, "Unexpected result in testCase %s", testCase.name)
+	}
+}
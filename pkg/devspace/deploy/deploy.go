@@ -1,22 +1,29 @@
 package deploy
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"sort"
 	"strings"
 
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/loft-sh/devspace/pkg/devspace/context/values"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/helm"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kustomize"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/plugin"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/validation"
 	helmclient "github.com/loft-sh/devspace/pkg/devspace/helm"
 	"github.com/loft-sh/devspace/pkg/devspace/hook"
 	kubectlclient "github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/util/timing"
 	"github.com/mgutz/ansi"
 	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
 )
 
 // Options describe how the deployments should be deployed
@@ -27,16 +34,35 @@ type Options struct {
 
 	Render       bool `long:"render" description:"If true, prints the rendered manifests to the stdout instead of deploying them"`
 	RenderWriter io.Writer
+	RenderDir    string `long:"render-dir" description:"If set together with render, writes every rendered object into its own file in this directory instead of printing them"`
+
+	Validate            bool   `long:"validate" description:"If true, validates the rendered manifests against the OpenAPI schema of the cluster"`
+	ValidateKubeVersion string `long:"validate-kube-version" description:"If set, validates the rendered manifests against the OpenAPI schema of this kubernetes version instead of the cluster"`
+
+	// validator validates the rendered manifests, it is created once per deploy
+	validator *validation.Validator
+
+	Plan        bool `long:"plan" description:"If true, prints the changes the deployments would make to the cluster before deploying them"`
+	ConfirmPlan bool `long:"confirm-plan" description:"If true, prints the changes the deployments would make to the cluster and asks for confirmation before deploying them"`
+
+	NoPrune bool `long:"no-prune" description:"If true, objects that were removed from the manifests of a deployment are not deleted from the cluster"`
 }
 
 type PurgeOptions struct {
-	ForcePurge bool `long:"force-purge" description:"Forces purging of deployments even though they might be still in use by other DevSpace projects"`
+	ForcePurge bool `long:"force-purge" description:"Forces purging of deployments even though they might be still in use by other DevSpace projects or are protected"`
+	Confirm    bool `long:"confirm" description:"Lists the deployments that will be deleted and asks for confirmation before deleting them"`
+	Yes        bool `long:"yes" description:"Deletes the deployments without asking for confirmation, e.g. to force purge protected deployments in non-interactive mode"`
+
+	// Recursive purges the dependencies selected by name including all of their child dependencies
+	Recursive bool
 }
 
 // Controller is the main deploying interface
 type Controller interface {
 	Deploy(ctx devspacecontext.Context, deployments []string, options *Options) error
 	Purge(ctx devspacecontext.Context, deployments []string, options *PurgeOptions) error
+	Plan(ctx devspacecontext.Context, deployments []string, options *Options) (*Plan, error)
+	Rollback(ctx devspacecontext.Context, deployments []string) error
 }
 
 type controller struct{}
@@ -60,6 +86,35 @@ func (c *controller) Deploy(ctx devspacecontext.Context, deployments []string, o
 	}
 
 	if config.Deployments != nil && len(config.Deployments) > 0 {
+		// Collect the rendered manifests to split them into files afterwards
+		var renderBuffer *bytes.Buffer
+		if options.Render && options.RenderDir != "" {
+			renderBuffer = &bytes.Buffer{}
+			renderOptions := *options
+			renderOptions.RenderWriter = renderBuffer
+			options = &renderOptions
+		}
+
+		// Load the schema to validate the rendered manifests against
+		if options.Render && (options.Validate || options.ValidateKubeVersion != "") {
+			validator, err := newValidator(ctx, options)
+			if err != nil {
+				return err
+			}
+
+			validateOptions := *options
+			validateOptions.validator = validator
+			options = &validateOptions
+		}
+
+		// Print the changes and ask for confirmation before deploying
+		if !options.Render && (options.Plan || options.ConfirmPlan) {
+			err := c.confirmPlan(ctx, deployments, options)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Execute before deployments deploy hook
 		err := hook.ExecuteHooks(ctx, nil, "before:"+event)
 		if err != nil {
@@ -67,25 +122,15 @@ func (c *controller) Deploy(ctx devspacecontext.Context, deployments []string, o
 		}
 
 		// get relevant deployments
-		var (
-			concurrentDeployments []*latest.DeploymentConfig
-			sequentialDeployments []*latest.DeploymentConfig
-		)
+		deployConfigs := []*latest.DeploymentConfig{}
 		if len(deployments) == 0 {
 			for _, deployConfig := range config.Deployments {
-				if !options.Render && !options.Sequential {
-					concurrentDeployments = append(concurrentDeployments, deployConfig)
-				} else {
-					sequentialDeployments = append(sequentialDeployments, deployConfig)
-				}
+				deployConfigs = append(deployConfigs, deployConfig)
 			}
 
 			// make sure --all behaves the same every rung
-			sort.Slice(concurrentDeployments, func(i, j int) bool {
-				return concurrentDeployments[i].Name < concurrentDeployments[j].Name
-			})
-			sort.Slice(sequentialDeployments, func(i, j int) bool {
-				return sequentialDeployments[i].Name < sequentialDeployments[j].Name
+			sort.Slice(deployConfigs, func(i, j int) bool {
+				return deployConfigs[i].Name < deployConfigs[j].Name
 			})
 		} else {
 			deploymentMap := config.Deployments
@@ -99,46 +144,17 @@ func (c *controller) Deploy(ctx devspacecontext.Context, deployments []string, o
 					return fmt.Errorf("couldn't find deployment %v", deployment)
 				}
 
-				if !options.Render && !options.Sequential {
-					concurrentDeployments = append(concurrentDeployments, deployConfig)
-				} else {
-					sequentialDeployments = append(sequentialDeployments, deployConfig)
-				}
+				deployConfigs = append(deployConfigs, deployConfig)
 			}
 		}
 
-		var (
-			errChan      = make(chan error)
-			deployedChan = make(chan bool)
-		)
-		for i, deployConfig := range concurrentDeployments {
-			go func(deployConfig *latest.DeploymentConfig, deployNumber int) {
-				wasDeployed, err := c.deployOne(ctx.WithLogger(ctx.Log().WithPrefix("deploy:"+deployConfig.Name+" ")), deployConfig, options)
-				if err != nil {
-					errChan <- err
-				} else {
-					deployedChan <- wasDeployed
-				}
-			}(deployConfig, i)
-		}
-
-		if len(concurrentDeployments) > 0 {
-			ctx.Log().Debugf("Deploying %d deployments concurrently...", len(concurrentDeployments))
-
-			// Wait for concurrent deployments to complete before starting sequential deployments.
-			for i := 0; i < len(concurrentDeployments); i++ {
-				select {
-				case err := <-errChan:
-					return err
-				case <-deployedChan:
-					ctx.Log().Debugf("Deploying %d deployments concurrently", len(concurrentDeployments)-i-1)
-				}
-			}
+		err = c.deployScheduled(ctx, deployConfigs, options, !options.Render && !options.Sequential, renderBuffer)
+		if err != nil {
+			return err
 		}
 
-		for _, deployConfig := range sequentialDeployments {
-			logsDeploy := ctx.Log().WithPrefix("deploy:" + deployConfig.Name + " ")
-			_, err := c.deployOne(ctx.WithLogger(logsDeploy), deployConfig, options)
+		if renderBuffer != nil {
+			err = writeRenderDir(ctx, options.RenderDir, renderBuffer.String())
 			if err != nil {
 				return err
 			}
@@ -160,46 +176,36 @@ func (c *controller) Deploy(ctx devspacecontext.Context, deployments []string, o
 }
 
 func (c *controller) deployOne(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig, options *Options) (bool, error) {
+	if !options.Render {
+		targets := KubeContexts(deployConfig)
+		if len(targets) > 0 {
+			return c.deployTargets(ctx, deployConfig, options, targets)
+		}
+	}
+
+	return c.deployTarget(ctx, deployConfig, options)
+}
+
+// deployTarget deploys the deployment with the kube client of the context
+func (c *controller) deployTarget(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig, options *Options) (bool, error) {
 	event := "deploy"
 	if options.Render {
 		event = "render"
 	}
-
-	var (
-		deployClient deployer.Interface
-		err          error
-		method       string
-	)
+	spanCtx, done := timing.Start(ctx.Context(), event+" "+deployConfig.Name)
+	defer done()
+	ctx = ctx.WithContext(spanCtx)
 
 	if !options.Render && deployConfig.Namespace != "" {
-		err = kubectlclient.EnsureNamespace(ctx.Context(), ctx.KubeClient(), deployConfig.Namespace, ctx.Log())
+		err := kubectlclient.EnsureNamespace(ctx.Context(), ctx.KubeClient(), deployConfig.Namespace, ctx.Log())
 		if err != nil {
 			return false, err
 		}
 	}
 
-	if deployConfig.Kubectl != nil {
-		deployClient, err = kubectl.New(ctx, deployConfig)
-		if err != nil {
-			return true, errors.Errorf("error deploying: deployment %s error: %v", deployConfig.Name, err)
-		}
-
-		method = "kubectl"
-	} else if deployConfig.Helm != nil {
-		// Get helm client
-		helmClient, err := helmclient.NewClient(ctx.Log())
-		if err != nil {
-			return true, err
-		}
-
-		deployClient, err = helm.New(helmClient, deployConfig)
-		if err != nil {
-			return true, errors.Errorf("error deploying: deployment %s error: %v", deployConfig.Name, err)
-		}
-
-		method = "helm"
-	} else {
-		return true, errors.Errorf("error deploying: deployment %s has no deployment method", deployConfig.Name)
+	deployClient, method, err := newDeployer(ctx, deployConfig)
+	if err != nil {
+		return true, err
 	}
 	// Execute before deployment deploy hook
 	err = hook.ExecuteHooks(ctx, map[string]interface{}{
@@ -212,7 +218,32 @@ func (c *controller) deployOne(ctx devspacecontext.Context, deployConfig *latest
 
 	wasDeployed := false
 	if !options.Render {
-		wasDeployed, err = deployClient.Deploy(ctx, options.ForceDeploy)
+		previousObjects := deployedObjects(ctx, deployConfig.Name)
+		var canaries []*appsv1.Deployment
+		if deployConfig.Canary != nil {
+			canaries, err = startCanary(ctx, deployClient, deployConfig)
+		}
+		if err == nil {
+			wasDeployed, err = deployClient.Deploy(ctx, options.ForceDeploy)
+		}
+		if len(canaries) > 0 {
+			if err == nil {
+				err = promoteCanary(ctx, deployConfig, canaries)
+			}
+
+			deleteErr := deleteCanaries(ctx, canaries)
+			if err == nil {
+				err = deleteErr
+			}
+		}
+		if err == nil && !options.NoPrune {
+			err = pruneObjects(ctx, deployConfig.Name, previousObjects)
+		}
+		if err == nil && deployConfig.Wait != nil {
+			err = waitForDeployment(ctx, deployConfig)
+		}
+	} else if options.validator != nil {
+		err = renderValidated(ctx, deployClient, options)
 	} else {
 		err = deployClient.Render(ctx, options.RenderWriter)
 	}
@@ -228,6 +259,12 @@ func (c *controller) deployOne(ctx devspacecontext.Context, deployConfig *latest
 
 		return true, errors.Errorf("error deploying %s: %v", deployConfig.Name, err)
 	}
+	if !options.Render {
+		markProtected(ctx, deployConfig.Name, deployConfig.Protect)
+		if wasDeployed {
+			recordHistory(ctx, deployConfig.Name)
+		}
+	}
 
 	if wasDeployed {
 		ctx.Log().Donef("Successfully deployed %s with %s", ansi.Color(deployConfig.Name, "white+b"), ansi.Color(method, "white+b"))
@@ -253,6 +290,47 @@ func (c *controller) deployOne(ctx devspacecontext.Context, deployConfig *latest
 	return false, nil
 }
 
+// newDeployer creates the deployer of the deployment and returns it with the name of the deployment method
+func newDeployer(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig) (deployer.Interface, string, error) {
+	if deployConfig.Kubectl != nil {
+		deployClient, err := kubectl.New(ctx, deployConfig)
+		if err != nil {
+			return nil, "", errors.Errorf("error deploying: deployment %s error: %v", deployConfig.Name, err)
+		}
+
+		return deployClient, "kubectl", nil
+	} else if deployConfig.Helm != nil {
+		// Get helm client
+		helmClient, err := helmclient.NewClient(ctx.Log())
+		if err != nil {
+			return nil, "", err
+		}
+
+		deployClient, err := helm.New(helmClient, deployConfig)
+		if err != nil {
+			return nil, "", errors.Errorf("error deploying: deployment %s error: %v", deployConfig.Name, err)
+		}
+
+		return deployClient, "helm", nil
+	} else if deployConfig.Kustomize != nil {
+		deployClient, err := kustomize.New(ctx, deployConfig)
+		if err != nil {
+			return nil, "", errors.Errorf("error deploying: deployment %s error: %v", deployConfig.Name, err)
+		}
+
+		return deployClient, "kustomize", nil
+	} else if deployConfig.Plugin != nil {
+		deployClient, err := plugin.New(ctx, deployConfig)
+		if err != nil {
+			return nil, "", errors.Errorf("error deploying: deployment %s error: %v", deployConfig.Name, err)
+		}
+
+		return deployClient, deployConfig.Plugin.Type, nil
+	}
+
+	return nil, "", errors.Errorf("error deploying: deployment %s has no deployment method", deployConfig.Name)
+}
+
 // Purge removes all deployments or a set of deployments from the cluster
 func (c *controller) Purge(ctx devspacecontext.Context, deployments []string, options *PurgeOptions) error {
 	if options == nil {
@@ -268,32 +346,55 @@ func (c *controller) Purge(ctx devspacecontext.Context, deployments []string, op
 		return err
 	}
 
+	// Deployments to other kube contexts are purged from the remote caches of their clusters
+	targets, err := targetContexts(ctx, deployments)
+	if err != nil {
+		return err
+	}
+
 	// Check if root name is defined
 	rootName, ok := values.RootNameFrom(ctx.Context())
 	if !ok {
 		options.ForcePurge = true
 	}
 
-	// Reverse them
-	deploymentCaches := ctx.Config().RemoteCache().ListDeployments()
-	for i := len(deploymentCaches) - 1; i >= 0; i-- {
-		// Deployment cache
-		deploymentCache := deploymentCaches[i]
-
-		// Check if we should skip deleting deployment
-		if deployments != nil {
-			found := false
-			for _, value := range deployments {
-				if value == deploymentCache.Name {
-					found = true
-					break
-				}
+	// Protected deployments are only purged if force purge was requested by the user. Ask before deleting
+	// the deployments that are not used by other projects
+	targetCaches := make([][]remotecache.DeploymentCache, len(targets))
+	deleteCaches := []remotecache.DeploymentCache{}
+	for i, target := range targets {
+		targetCaches[i] = purgeCandidates(target, deployments, options.ForcePurge)
+		for _, deploymentCache := range targetCaches[i] {
+			if options.ForcePurge || !inUseByOtherProjects(deploymentCache, rootName) {
+				deleteCaches = append(deleteCaches, deploymentCache)
 			}
+		}
+	}
+	err = confirmPurge(ctx, deleteCaches, options)
+	if err != nil {
+		return err
+	}
 
-			if !found {
-				continue
-			}
+	for i, target := range targets {
+		err = c.purgeTarget(target, targetCaches[i], rootName, options)
+		if err != nil {
+			return err
 		}
+	}
+
+	// Execute after deployments purge hook
+	err = hook.ExecuteHooks(ctx, nil, "after:purge")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// purgeTarget deletes the deployments of the remote cache of the context and saves it afterwards
+func (c *controller) purgeTarget(ctx devspacecontext.Context, deploymentCaches []remotecache.DeploymentCache, rootName string, options *PurgeOptions) error {
+	var err error
+	for _, deploymentCache := range deploymentCaches {
 		ctx := ctx.WithLogger(ctx.Log().WithPrefix("purge:" + deploymentCache.Name + " "))
 
 		// Execute before deployment purge hook
@@ -306,7 +407,7 @@ func (c *controller) Purge(ctx devspacecontext.Context, deployments []string, op
 		}
 
 		// Check if we should skip deletion
-		if !options.ForcePurge && len(deploymentCache.Projects) > 0 && (len(deploymentCache.Projects) > 1 || deploymentCache.Projects[0] != rootName) {
+		if !options.ForcePurge && inUseByOtherProjects(deploymentCache, rootName) {
 			newProjects := []string{}
 			for _, p := range deploymentCache.Projects {
 				if p == rootName {
@@ -328,6 +429,8 @@ func (c *controller) Purge(ctx devspacecontext.Context, deployments []string, op
 			err = kubectl.Delete(ctx, deploymentCache.Name)
 		} else if deploymentCache.Helm != nil {
 			err = helm.Delete(ctx, deploymentCache.Name)
+		} else if deploymentCache.Plugin != nil {
+			err = plugin.Delete(ctx, deploymentCache.Name)
 		} else {
 			ctx.Log().Errorf("error purging: deployment %s has no deployment method", deploymentCache.Name)
 			ctx.Config().RemoteCache().DeleteDeployment(deploymentCache.Name)
@@ -360,16 +463,5 @@ func (c *controller) Purge(ctx devspacecontext.Context, deployments []string, op
 		ctx.Config().RemoteCache().DeleteDeployment(deploymentCache.Name)
 	}
 
-	err = ctx.Config().RemoteCache().Save(ctx.Context(), ctx.KubeClient())
-	if err != nil {
-		return err
-	}
-
-	// Execute after deployments purge hook
-	err = hook.ExecuteHooks(ctx, nil, "after:purge")
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return ctx.Config().RemoteCache().Save(ctx.Context(), ctx.KubeClient())
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/loft-sh/devspace/assets"
 	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
 	"github.com/loft-sh/devspace/pkg/devspace/helm"
@@ -91,3 +92,35 @@ func Delete(ctx devspacecontext.Context, deploymentName string) error {
 
 	return nil
 }
+
+// Rollback rolls the release of the deployment back to its previous revision
+func Rollback(ctx devspacecontext.Context, deploymentName string) error {
+	deploymentCache, ok := ctx.Config().RemoteCache().GetDeployment(deploymentName)
+	if !ok || deploymentCache.Helm == nil || deploymentCache.Helm.Release == "" {
+		return fmt.Errorf("deployment %s has no helm release to roll back", deploymentName)
+	}
+
+	helmClient, err := helm.NewClient(ctx.Log())
+	if err != nil {
+		return errors.Wrap(err, "new helm client")
+	}
+
+	return rollback(ctx, helmClient, deploymentName, deploymentCache)
+}
+
+func rollback(ctx devspacecontext.Context, helmClient helmtypes.Client, deploymentName string, deploymentCache remotecache.DeploymentCache) error {
+	err := helmClient.RollbackRelease(ctx, deploymentCache.Helm.Release, deploymentCache.Helm.ReleaseNamespace)
+	if err != nil {
+		return err
+	}
+
+	// make sure the next deploy upgrades the release again
+	helmCache := *deploymentCache.Helm
+	helmCache.OverridesHash = ""
+	helmCache.ChartHash = ""
+	helmCache.ValuesHash = ""
+	deploymentCache.Helm = &helmCache
+	deploymentCache.DeploymentConfigHash = ""
+	ctx.Config().RemoteCache().SetDeployment(deploymentName, deploymentCache)
+	return nil
+}
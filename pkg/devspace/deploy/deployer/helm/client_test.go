@@ -12,6 +12,7 @@ import (
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/loft-sh/devspace/pkg/util/log"
 
+	fakehelm "github.com/loft-sh/devspace/pkg/devspace/helm/testing"
 	helmtypes "github.com/loft-sh/devspace/pkg/devspace/helm/types"
 	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
 	"gotest.tools/assert"
@@ -78,3 +79,40 @@ func TestDelete(t *testing.T) {
 		// assert.Equal(t, string(statusAsYaml), string(expectedAsYaml), "Unexpected status in testCase %s", testCase.name)
 	}
 }
+
+func TestRollback(t *testing.T) {
+	cache := &remotecache.RemoteCache{}
+	cache.SetDeployment("test", remotecache.DeploymentCache{
+		Name:                 "test",
+		DeploymentConfigHash: "hash",
+		Helm: &remotecache.HelmCache{
+			Release:          "test",
+			ReleaseNamespace: "default",
+			OverridesHash:    "overrides",
+			ChartHash:        "chart",
+			ValuesHash:       "values",
+			ReleaseRevision:  "2",
+		},
+	})
+	cg := config.NewConfig(nil, nil, latest.NewRaw(), localcache.New(""), cache, nil, constants.DefaultConfigPath)
+	devContext := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(cg).WithKubeClient(&fakekube.Client{Client: fake.NewSimpleClientset()})
+	helmClient := &fakehelm.Client{}
+
+	// the release has to exist
+	deploymentCache, _ := cache.GetDeployment("test")
+	err := rollback(devContext, helmClient, "test", deploymentCache)
+	assert.Error(t, err, "release test not found")
+
+	// the hashes are reset, so that the next deploy upgrades the release again
+	helmClient.Releases = []*helmtypes.Release{{Name: "test", Namespace: "default"}}
+	err = rollback(devContext, helmClient, "test", deploymentCache)
+	assert.NilError(t, err)
+
+	deploymentCache, _ = cache.GetDeployment("test")
+	assert.Equal(t, deploymentCache.DeploymentConfigHash, "")
+	assert.DeepEqual(t, deploymentCache.Helm, &remotecache.HelmCache{
+		Release:          "test",
+		ReleaseNamespace: "default",
+		ReleaseRevision:  "2",
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/context/values"
 	"github.com/loft-sh/devspace/pkg/util/stringutil"
 
+	"github.com/loft-sh/devspace/pkg/devspace/helm/oci"
 	"github.com/loft-sh/devspace/pkg/devspace/helm/types"
 
 	yaml "gopkg.in/yaml.v3"
@@ -53,6 +54,16 @@ func (d *DeployConfig) Deploy(ctx devspacecontext.Context, forceDeploy bool) (bo
 		chartPath = downloadPath
 	}
 
+	// Charts from oci registries are redeployed if the version resolves to another chart
+	if oci.IsOCI(chartPath) {
+		chart, err := oci.Pull(ctx, d.DeploymentConfig.Helm.Chart)
+		if err != nil {
+			return false, err
+		}
+
+		hash = chart.Digest
+	}
+
 	// Hash the chart directory if there is any
 	_, err := os.Stat(ctx.ResolvePath(chartPath))
 	if err == nil {
@@ -86,6 +97,21 @@ func (d *DeployConfig) Deploy(ctx devspacecontext.Context, forceDeploy bool) (bo
 		}
 	}
 
+	// Check the kustomize patches and components of the post renderer for changes
+	if d.DeploymentConfig.Helm.PostRenderer != nil && d.DeploymentConfig.Helm.PostRenderer.Kustomize != nil {
+		postRenderer := d.DeploymentConfig.Helm.PostRenderer.Kustomize
+		for _, path := range append(append([]string{}, postRenderer.Patches...), postRenderer.Components...) {
+			path = ctx.ResolvePath(path)
+
+			hash, err := hashpkg.Directory(path)
+			if err != nil {
+				return false, errors.Errorf("Error stating post renderer file %s: %v", path, err)
+			}
+
+			helmOverridesHash += hash
+		}
+	}
+
 	// Check deployment config for changes
 	configStr, err := yaml.Marshal(d.DeploymentConfig)
 	if err != nil {
@@ -255,6 +281,18 @@ func (d *DeployConfig) getDeploymentValues(ctx devspacecontext.Context) (bool, m
 				shouldRedeploy = shouldRedeploy || redeploy
 			}
 
+			// Resolve variables within the values file
+			if d.DeploymentConfig.Helm.TemplateValuesFiles {
+				redeploy, resolvedValues, err := runtimevar.NewRuntimeResolver(ctx.WorkingDir(), false).FillRuntimeVariablesWithRebuild(ctx.Context(), overwriteValuesFromPath, ctx.Config(), ctx.Dependencies())
+				if err != nil {
+					return false, nil, errors.Wrapf(err, "values file %s", overridePath)
+				}
+				shouldRedeploy = shouldRedeploy || redeploy
+				if resolvedValuesMap, ok := resolvedValues.(map[string]interface{}); ok {
+					overwriteValuesFromPath = resolvedValuesMap
+				}
+			}
+
 			merge.Values(overwriteValues).MergeInto(overwriteValuesFromPath)
 		}
 	}
@@ -274,6 +312,15 @@ func (d *DeployConfig) getDeploymentValues(ctx devspacecontext.Context) (bool, m
 		merge.Values(overwriteValues).MergeInto(d.DeploymentConfig.Helm.Values)
 	}
 
+	// Set the values of the images override
+	if len(d.DeploymentConfig.Helm.ImagesOverride) > 0 {
+		redeploy, err := d.overrideImages(ctx, overwriteValues)
+		if err != nil {
+			return false, nil, err
+		}
+		shouldRedeploy = shouldRedeploy || redeploy
+	}
+
 	// Validate deployment values
 	err = versions.ValidateComponentConfig(d.DeploymentConfig, overwriteValues)
 	if err != nil {
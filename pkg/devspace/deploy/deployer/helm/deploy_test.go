@@ -2,6 +2,8 @@ package helm
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config"
@@ -14,6 +16,7 @@ import (
 	helmtypes "github.com/loft-sh/devspace/pkg/devspace/helm/types"
 	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
 	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/ptr"
 	yaml "gopkg.in/yaml.v3"
 	"gotest.tools/assert"
 	"k8s.io/client-go/kubernetes/fake"
@@ -144,3 +147,38 @@ func TestDeploy(t *testing.T) {
 		assert.Equal(t, deployed, testCase.expectedDeployed, "Unexpected deployed-bool in testCase %s", testCase.name)
 	}
 }
+
+func TestTemplateValuesFiles(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("tag: ${runtime.images.api.tag}\n"), 0644)
+	assert.NilError(t, err)
+
+	cache := localcache.New(constants.DefaultCacheFolder)
+	cache.SetImageCache("api", localcache.ImageCache{ImageName: "my-registry/api", Tag: "abc"})
+	conf := latest.NewRaw()
+	conf.Images = map[string]*latest.Image{
+		"api": {Image: "my-registry/api"},
+	}
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(config.NewConfig(nil, nil, conf, cache, &remotecache.RemoteCache{}, nil, constants.DefaultConfigPath)).WithWorkingDir(dir)
+
+	deployer := &DeployConfig{
+		DeploymentConfig: &latest.DeploymentConfig{
+			Name:            "test",
+			UpdateImageTags: ptr.Bool(false),
+			Helm: &latest.HelmConfig{
+				Chart:       &latest.ChartConfig{Name: "chart"},
+				ValuesFiles: []string{"values.yaml"},
+			},
+		},
+	}
+
+	// values files are not templated by default
+	_, values, err := deployer.getDeploymentValues(ctx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, map[string]interface{}{"tag": "${runtime.images.api.tag}"})
+
+	deployer.DeploymentConfig.Helm.TemplateValuesFiles = true
+	_, values, err = deployer.getDeploymentValues(ctx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, map[string]interface{}{"tag": "abc"})
+}
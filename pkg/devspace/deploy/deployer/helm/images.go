@@ -0,0 +1,88 @@
+package helm
+
+import (
+	"sort"
+	"strings"
+
+	runtimevar "github.com/loft-sh/devspace/pkg/devspace/config/loader/variable/runtime"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/pkg/errors"
+)
+
+// overrideImages sets the values of the images override to the most recently built images
+func (d *DeployConfig) overrideImages(ctx devspacecontext.Context, values map[string]interface{}) (bool, error) {
+	imageNames := []string{}
+	for imageName := range d.DeploymentConfig.Helm.ImagesOverride {
+		imageNames = append(imageNames, imageName)
+	}
+	sort.Strings(imageNames)
+
+	shouldRedeploy := false
+	for _, imageName := range imageNames {
+		override := d.DeploymentConfig.Helm.ImagesOverride[imageName]
+		if override == nil {
+			continue
+		}
+
+		redeploy, repository, err := runtimevar.GetImage(ctx.Config(), imageName, true, false)
+		if err != nil {
+			return false, errors.Wrapf(err, "images override %s", imageName)
+		}
+		_, tag, err := runtimevar.GetImage(ctx.Config(), imageName, false, true)
+		if err != nil {
+			return false, errors.Wrapf(err, "images override %s", imageName)
+		}
+		imageCache, _ := ctx.Config().LocalCache().GetImageCache(imageName)
+		shouldRedeploy = shouldRedeploy || redeploy
+
+		image := repository
+		if tag != "" {
+			image += ":" + tag
+		}
+		if imageCache.Digest != "" {
+			image += "@" + imageCache.Digest
+		}
+
+		for _, value := range [][2]string{
+			{override.Image, image},
+			{override.Repository, repository},
+			{override.Tag, tag},
+			{override.Digest, imageCache.Digest},
+		} {
+			if value[0] == "" {
+				continue
+			}
+
+			err = setValue(values, value[0], value[1])
+			if err != nil {
+				return false, errors.Wrapf(err, "images override %s", imageName)
+			}
+		}
+	}
+
+	return shouldRedeploy, nil
+}
+
+// setValue sets the value at the dot separated path and creates the maps along the path if necessary
+func setValue(values map[string]interface{}, path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	for i, key := range keys[:len(keys)-1] {
+		next, ok := values[key]
+		if !ok || next == nil {
+			nextMap := map[string]interface{}{}
+			values[key] = nextMap
+			values = nextMap
+			continue
+		}
+
+		nextMap, ok := next.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("can't set %s, because %s is not a map", path, strings.Join(keys[:i+1], "."))
+		}
+
+		values = nextMap
+	}
+
+	values[keys[len(keys)-1]] = value
+	return nil
+}
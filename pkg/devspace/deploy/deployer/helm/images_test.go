@@ -0,0 +1,126 @@
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+type setValueTestCase struct {
+	name string
+
+	values map[string]interface{}
+	path   string
+	value  interface{}
+
+	expectedValues map[string]interface{}
+	expectedErr    string
+}
+
+func TestSetValue(t *testing.T) {
+	testCases := []setValueTestCase{
+		{
+			name:   "Top level value",
+			values: map[string]interface{}{},
+			path:   "tag",
+			value:  "abc",
+			expectedValues: map[string]interface{}{
+				"tag": "abc",
+			},
+		},
+		{
+			name: "Nested value in existing map",
+			values: map[string]interface{}{
+				"image": map[string]interface{}{
+					"pullPolicy": "Always",
+				},
+			},
+			path:  "image.tag",
+			value: "abc",
+			expectedValues: map[string]interface{}{
+				"image": map[string]interface{}{
+					"pullPolicy": "Always",
+					"tag":        "abc",
+				},
+			},
+		},
+		{
+			name:   "Create missing maps",
+			values: map[string]interface{}{},
+			path:   "backend.image.tag",
+			value:  "abc",
+			expectedValues: map[string]interface{}{
+				"backend": map[string]interface{}{
+					"image": map[string]interface{}{
+						"tag": "abc",
+					},
+				},
+			},
+		},
+		{
+			name: "Path through a non-map value",
+			values: map[string]interface{}{
+				"image": "my-image",
+			},
+			path:        "image.tag",
+			value:       "abc",
+			expectedErr: "can't set image.tag, because image is not a map",
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := setValue(testCase.values, testCase.path, testCase.value)
+		if testCase.expectedErr != "" {
+			assert.Error(t, err, testCase.expectedErr, "Wrong or no error in testCase %s", testCase.name)
+			continue
+		}
+
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		assert.DeepEqual(t, testCase.values, testCase.expectedValues)
+	}
+}
+
+func TestOverrideImages(t *testing.T) {
+	cache := localcache.New("")
+	cache.SetImageCache("api", localcache.ImageCache{ImageName: "my-registry/api", Tag: "abc", Digest: "sha256:123"})
+
+	conf := latest.NewRaw()
+	conf.Images = map[string]*latest.Image{
+		"api": {Image: "my-registry/api"},
+	}
+	ctx := devspacecontext.NewContext(context.TODO(), nil, log.Discard).WithConfig(config.NewConfig(nil, nil, conf, cache, &remotecache.RemoteCache{}, nil, ""))
+
+	deployer := &DeployConfig{
+		DeploymentConfig: &latest.DeploymentConfig{
+			Helm: &latest.HelmConfig{
+				ImagesOverride: map[string]*latest.HelmImageOverride{
+					"api": {
+						Image:      "api.image",
+						Repository: "api.repository",
+						Tag:        "api.tag",
+						Digest:     "api.digest",
+					},
+				},
+			},
+		},
+	}
+
+	values := map[string]interface{}{}
+	_, err := deployer.overrideImages(ctx, values)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, map[string]interface{}{
+		"api": map[string]interface{}{
+			"image":      "my-registry/api:abc@sha256:123",
+			"repository": "my-registry/api",
+			"tag":        "abc",
+			"digest":     "sha256:123",
+		},
+	})
+}
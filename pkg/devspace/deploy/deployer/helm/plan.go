@@ -0,0 +1,59 @@
+package helm
+
+import (
+	"bytes"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Plan renders the chart and compares it with the manifest of the deployed release. Helm always deletes the
+// objects that were removed from the chart, so noPrune doesn't apply
+func (d *DeployConfig) Plan(ctx devspacecontext.Context, noPrune bool) ([]deployer.Change, error) {
+	releaseName := d.DeploymentConfig.Name
+	if d.DeploymentConfig.Helm.ReleaseName != "" {
+		releaseName = d.DeploymentConfig.Helm.ReleaseName
+	}
+	releaseNamespace := ctx.KubeClient().Namespace()
+	if d.DeploymentConfig.Namespace != "" {
+		releaseNamespace = d.DeploymentConfig.Namespace
+	}
+
+	rendered := &bytes.Buffer{}
+	err := d.Render(ctx, rendered)
+	if err != nil {
+		return nil, err
+	}
+
+	desired, err := deployer.ParseObjects(rendered.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "parse rendered chart")
+	}
+
+	releases, err := d.Helm.ListReleases(ctx, releaseNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []*unstructured.Unstructured{}
+	for _, release := range releases {
+		if release.Name != releaseName {
+			continue
+		}
+
+		manifest, err := d.Helm.GetManifest(ctx, releaseName, releaseNamespace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get manifest of release %s", releaseName)
+		}
+
+		current, err = deployer.ParseObjects(manifest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse manifest of release %s", releaseName)
+		}
+		break
+	}
+
+	return deployer.Changes(current, desired)
+}
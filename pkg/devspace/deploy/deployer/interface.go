@@ -10,6 +10,7 @@ type Interface interface {
 	Status(ctx devspacecontext.Context) (*StatusResult, error)
 	Deploy(ctx devspacecontext.Context, forceDeploy bool) (bool, error)
 	Render(ctx devspacecontext.Context, out io.Writer) error
+	Plan(ctx devspacecontext.Context, noPrune bool) ([]Change, error)
 }
 
 // StatusResult holds the status of a deployment
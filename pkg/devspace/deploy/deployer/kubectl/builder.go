@@ -2,20 +2,18 @@ package kubectl
 
 import (
 	"context"
-	"fmt"
 	"github.com/loft-sh/devspace/pkg/devspace/pipeline/env"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"mvdan.cc/sh/v3/expand"
 	"os"
 	"os/exec"
-	"regexp"
 	"strings"
 
 	"github.com/loft-sh/devspace/pkg/util/constraint"
 
-	"github.com/ghodss/yaml"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/utils/pkg/command"
 	"github.com/pkg/errors"
@@ -57,7 +55,7 @@ func (k *kustomizeBuilder) Build(ctx context.Context, environ expand.Environ, di
 		return nil, err
 	}
 
-	return stringToUnstructuredArray(string(output))
+	return deployer.ParseObjects(string(output))
 }
 
 type kubectlBuilder struct {
@@ -155,38 +153,5 @@ func (k *kubectlBuilder) Build(ctx context.Context, environ expand.Environ, dir,
 		return nil, err
 	}
 
-	return stringToUnstructuredArray(string(output))
-}
-
-var diffSeparator = regexp.MustCompile(`\n---`)
-
-// stringToUnstructuredArray splits a YAML file into unstructured objects. Returns a list of all unstructured objects
-func stringToUnstructuredArray(out string) ([]*unstructured.Unstructured, error) {
-	parts := diffSeparator.Split(out, -1)
-	var objs []*unstructured.Unstructured
-	var firstErr error
-	for _, part := range parts {
-		var objMap map[string]interface{}
-		err := yaml.Unmarshal([]byte(part), &objMap)
-		if err != nil {
-			if firstErr == nil {
-				firstErr = fmt.Errorf("failed to unmarshal manifest: %v", err)
-			}
-			continue
-		}
-		if len(objMap) == 0 {
-			// handles case where theres no content between `---`
-			continue
-		}
-		var obj unstructured.Unstructured
-		err = yaml.Unmarshal([]byte(part), &obj)
-		if err != nil {
-			if firstErr == nil {
-				firstErr = fmt.Errorf("failed to unmarshal manifest: %v", err)
-			}
-			continue
-		}
-		objs = append(objs, &obj)
-	}
-	return objs, firstErr
+	return deployer.ParseObjects(string(output))
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/loft-sh/devspace/pkg/devspace/context/values"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/loft-sh/devspace/pkg/util/stringutil"
 	"github.com/loft-sh/utils/pkg/command"
 	"github.com/sirupsen/logrus"
@@ -34,6 +35,9 @@ import (
 
 var Cachemanifest = "./.devspace/manifest-cache.yaml"
 
+// DefaultFieldManager is the field manager that is used for server-side apply
+const DefaultFieldManager = "devspace"
+
 // DeployConfig holds the necessary information for kubectl deployment
 type DeployConfig struct {
 	Name           string
@@ -177,12 +181,16 @@ func (d *DeployConfig) Deploy(ctx devspacecontext.Context, _ bool) (bool, error)
 	ctx.Log().Info("Applying manifests with kubectl...")
 	wasDeployed := false
 	kubeObjects := []remotecache.KubectlObject{}
+	appliedManifests := []string{}
 
 	for _, manifest := range d.Manifests {
-		wasDeployed, kubeObjects, err = d.applyManifest(ctx, kubeObjects, forceDeploy, false, manifest)
+		var appliedManifest string
+		wasDeployed, appliedManifest, kubeObjects, err = d.applyManifest(ctx, kubeObjects, forceDeploy, false, manifest)
 		if err != nil {
 			return false, err
 		}
+
+		appliedManifests = append(appliedManifests, appliedManifest)
 	}
 
 	// Special case for inline manifests
@@ -193,15 +201,18 @@ func (d *DeployConfig) Deploy(ctx devspacecontext.Context, _ bool) (bool, error)
 			return false, err
 		}
 		// proceed with regular apply
-		wasDeployed, kubeObjects, err = d.applyManifest(ctx, kubeObjects, forceDeploy, true, resolvedInlineManifest)
+		var appliedManifest string
+		wasDeployed, appliedManifest, kubeObjects, err = d.applyManifest(ctx, kubeObjects, forceDeploy, true, resolvedInlineManifest)
 		if err != nil {
 			return false, err
 		}
+
+		appliedManifests = append(appliedManifests, appliedManifest)
 	}
 
-	deployCache.Kubectl = &remotecache.KubectlCache{
-		Objects:       kubeObjects,
-		ManifestsHash: manifestsHash,
+	deployCache.Kubectl, err = NewCache(deployCache.Kubectl, kubeObjects, manifestsHash, strings.Join(appliedManifests, "\n---\n"))
+	if err != nil {
+		return false, err
 	}
 	deployCache.DeploymentConfigHash = deploymentConfigHash
 	if rootName, ok := values.RootNameFrom(ctx.Context()); ok && !stringutil.Contains(deployCache.Projects, rootName) {
@@ -211,30 +222,34 @@ func (d *DeployConfig) Deploy(ctx devspacecontext.Context, _ bool) (bool, error)
 	return wasDeployed, nil
 }
 
-func (d *DeployConfig) applyManifest(ctx devspacecontext.Context, kubeObjects []remotecache.KubectlObject, forceDeploy, inline bool, manifest string) (bool, []remotecache.KubectlObject, error) {
+func (d *DeployConfig) applyManifest(ctx devspacecontext.Context, kubeObjects []remotecache.KubectlObject, forceDeploy, inline bool, manifest string) (bool, string, []remotecache.KubectlObject, error) {
 	shouldRedeploy, replacedManifest, parsedObjects, err := d.getReplacedManifest(ctx, inline, manifest)
 	if err != nil {
-		return false, nil, errors.Errorf("%v\nPlease make sure `kubectl apply` does work locally with manifest `%s`", err, manifest)
+		return false, "", nil, errors.Errorf("%v\nPlease make sure `kubectl apply` does work locally with manifest `%s`", err, manifest)
 	}
 	writer := ctx.Log().Writer(logrus.InfoLevel, false)
 	defer writer.Close()
 
 	kubeObjects = append(kubeObjects, parsedObjects...)
 	if shouldRedeploy || forceDeploy {
-		args := d.getCmdArgs("apply", "--force")
+		args := d.getCmdArgs("apply", d.applyFlags("--force")...)
 		args = append(args, d.DeploymentConfig.Kubectl.ApplyArgs...)
 
 		stdErrBuffer := &bytes.Buffer{}
 		err = command.Command(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), writer, io.MultiWriter(writer, stdErrBuffer), strings.NewReader(replacedManifest), d.CmdPath, args...)
 		if err != nil {
-			return false, nil, errors.Errorf("%v %v\nPlease make sure the command `kubectl apply` does work locally with manifest `%s`", stdErrBuffer.String(), err, manifest)
+			if d.serverSideApply() && strings.Contains(stdErrBuffer.String(), "conflict") {
+				return false, "", nil, errors.Errorf("%v %v\nSome fields of manifest `%s` are managed by another field manager. Set `forceConflicts: true` to take ownership of them", stdErrBuffer.String(), err, manifest)
+			}
+
+			return false, "", nil, errors.Errorf("%v %v\nPlease make sure the command `kubectl apply` does work locally with manifest `%s`", stdErrBuffer.String(), err, manifest)
 		}
 
 	} else {
 		ctx.Log().Infof("Skipping manifest %s", manifest)
 	}
 
-	return true, kubeObjects, nil
+	return true, replacedManifest, kubeObjects, nil
 }
 
 func (d *DeployConfig) getReplacedManifest(ctx devspacecontext.Context, inline bool, manifest string) (bool, string, []remotecache.KubectlObject, error) {
@@ -247,7 +262,7 @@ func (d *DeployConfig) getReplacedManifest(ctx devspacecontext.Context, inline b
 			return false, "", nil, err
 		}
 	} else {
-		objects, err = stringToUnstructuredArray(manifest)
+		objects, err = deployer.ParseObjects(manifest)
 		if err != nil {
 			return false, "", nil, err
 		}
@@ -302,11 +317,36 @@ func (d *DeployConfig) getReplacedManifest(ctx devspacecontext.Context, inline b
 	return shouldRedeploy, strings.Join(replaceManifests, "\n---\n"), kubeObjects, nil
 }
 
+func (d *DeployConfig) serverSideApply() bool {
+	return d.DeploymentConfig.Kubectl.ServerSideApply != nil && *d.DeploymentConfig.Kubectl.ServerSideApply
+}
+
+// applyFlags returns the flags for kubectl apply. With server-side apply, the fields are owned by the field
+// manager of DevSpace, so fields that are managed by others, e.g. the replicas set by a HPA, are kept
+func (d *DeployConfig) applyFlags(clientSideFlags ...string) []string {
+	if !d.serverSideApply() {
+		return clientSideFlags
+	}
+
+	fieldManager := d.DeploymentConfig.Kubectl.FieldManager
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
+
+	flags := []string{"--server-side", "--field-manager", fieldManager}
+	if d.DeploymentConfig.Kubectl.ForceConflicts != nil && *d.DeploymentConfig.Kubectl.ForceConflicts {
+		flags = append(flags, "--force-conflicts")
+	}
+
+	return flags
+}
+
 func (d *DeployConfig) getCmdArgs(method string, additionalArgs ...string) []string {
 	args := []string{}
 	if d.Context != "" && !d.IsInCluster {
 		args = append(args, "--context", d.Context)
 	}
+	args = append(args, kubectl.ImpersonationArgs("--as", "--as-group")...)
 
 	args = append(args, method)
 	if additionalArgs != nil {
@@ -336,6 +376,7 @@ func (d *DeployConfig) buildManifests(ctx devspacecontext.Context, manifest stri
 	for key := range copied.Contexts {
 		copied.Contexts[key].Namespace = d.Namespace
 	}
+	kubectl.ImpersonateKubeConfig(copied)
 
 	// Build with kubectl
 	return NewKubectlBuilder(d.CmdPath, d.DeploymentConfig, *copied).Build(ctx.Context(), ctx.Environ(), ctx.WorkingDir(), manifest)
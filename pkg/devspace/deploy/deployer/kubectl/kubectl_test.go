@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config"
@@ -21,6 +25,8 @@ import (
 
 	yaml "gopkg.in/yaml.v3"
 	"gotest.tools/assert"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type newTestCase struct {
@@ -522,3 +528,216 @@ func TestGetReplacedManifest(t *testing.T) {
 		assert.Equal(t, replacedManifest, testCase.expectedManifest, "Unexpected replaced manifest in testCase %s", testCase.name)
 	}
 }
+
+type applyFlagsTestCase struct {
+	name string
+
+	kubectlConfig *latest.KubectlConfig
+
+	expectedFlags []string
+}
+
+func TestApplyFlags(t *testing.T) {
+	testCases := []applyFlagsTestCase{
+		{
+			name:          "Client-side apply",
+			kubectlConfig: &latest.KubectlConfig{},
+			expectedFlags: []string{"--force"},
+		},
+		{
+			name: "Server-side apply",
+			kubectlConfig: &latest.KubectlConfig{
+				ServerSideApply: ptr.Bool(true),
+			},
+			expectedFlags: []string{"--server-side", "--field-manager", "devspace"},
+		},
+		{
+			name: "Server-side apply with field manager and forced conflicts",
+			kubectlConfig: &latest.KubectlConfig{
+				ServerSideApply: ptr.Bool(true),
+				FieldManager:    "my-manager",
+				ForceConflicts:  ptr.Bool(true),
+			},
+			expectedFlags: []string{"--server-side", "--field-manager", "my-manager", "--force-conflicts"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		deployer := &DeployConfig{
+			DeploymentConfig: &latest.DeploymentConfig{
+				Kubectl: testCase.kubectlConfig,
+			},
+		}
+
+		assert.DeepEqual(t, deployer.applyFlags("--force"), testCase.expectedFlags)
+	}
+}
+
+func TestGetCmdArgsImpersonation(t *testing.T) {
+	defer kubectl.SetImpersonation("", nil)
+
+	deployer := &DeployConfig{Context: "my-context"}
+	assert.DeepEqual(t, deployer.getCmdArgs("apply", "--force"), []string{"--context", "my-context", "apply", "--force", "-f", "-"})
+
+	// manifests are applied as the impersonated user
+	kubectl.SetImpersonation("jane", []string{"developers", "admins"})
+	assert.DeepEqual(t, deployer.getCmdArgs("apply", "--force"), []string{"--context", "my-context", "--as", "jane", "--as-group", "developers", "--as-group", "admins", "apply", "--force", "-f", "-"})
+}
+
+func TestNewCache(t *testing.T) {
+	firstObjects := []remotecache.KubectlObject{{APIVersion: "v1", Kind: "ConfigMap", Name: "first"}}
+	secondObjects := []remotecache.KubectlObject{{APIVersion: "v1", Kind: "ConfigMap", Name: "second"}}
+
+	first, err := NewCache(nil, firstObjects, "hash", "first manifest")
+	assert.NilError(t, err)
+	assert.Assert(t, first.Previous == nil)
+	manifest, err := decodeManifest(first.Manifest)
+	assert.NilError(t, err)
+	assert.Equal(t, manifest, "first manifest")
+
+	second, err := NewCache(first, secondObjects, "hash", "second manifest")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, second.Previous, &remotecache.KubectlRevision{Objects: firstObjects, Manifest: first.Manifest})
+
+	// deploying the same manifest again keeps the previous revision
+	third, err := NewCache(second, secondObjects, "hash", "second manifest")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, third.Previous, second.Previous)
+}
+
+// liveObjectsClient returns the live objects by name from generic requests
+type liveObjectsClient struct {
+	*fakekube.Client
+
+	objects map[string]string
+}
+
+func (c *liveObjectsClient) GenericRequest(ctx context.Context, options *kubectl.GenericRequestOptions) (string, error) {
+	obj, ok := c.objects[options.Name]
+	if !ok {
+		return "", kerrors.NewNotFound(schema.GroupResource{Resource: options.Kind}, options.Name)
+	}
+
+	return obj, nil
+}
+
+func TestPlanChanges(t *testing.T) {
+	remoteCache := &remotecache.RemoteCache{}
+	remoteCache.SetDeployment("test", remotecache.DeploymentCache{
+		Name: "test",
+		Kubectl: &remotecache.KubectlCache{
+			Objects: []remotecache.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "kept", Namespace: "default"},
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "removed", Namespace: "default"},
+			},
+		},
+	})
+	conf := config.NewConfig(nil, nil, latest.NewRaw(), localcache.New(constants.DefaultCacheFolder), remoteCache, nil, constants.DefaultConfigPath)
+	kubeClient := &liveObjectsClient{
+		Client: &fakekube.Client{},
+		objects: map[string]string{
+			"kept":    `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"kept","namespace":"default"}}`,
+			"removed": `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"removed","namespace":"default"}}`,
+		},
+	}
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.NewFakeLogger()).WithConfig(conf).WithKubeClient(kubeClient)
+
+	desired, err := deployer.ParseObjects(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kept
+  namespace: default
+`)
+	assert.NilError(t, err)
+
+	// objects that were removed from the manifests are deleted
+	changes, err := PlanChanges(ctx, "test", desired, false)
+	assert.NilError(t, err)
+	assert.Equal(t, len(changes), 1)
+	assert.Equal(t, changes[0].Action, deployer.ChangeActionDelete)
+	assert.Equal(t, changes[0].Name, "removed")
+
+	// unless they are not pruned
+	changes, err = PlanChanges(ctx, "test", desired, true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(changes), 0)
+}
+
+// writeFakeKubectl writes a kubectl script that records its arguments and the applied manifest in the dir
+func writeFakeKubectl(t *testing.T, dir string) string {
+	script := filepath.Join(dir, "kubectl")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+filepath.Join(dir, "args")+"\ncat > "+filepath.Join(dir, "manifest")+"\n"), 0755)
+	assert.NilError(t, err)
+	return script
+}
+
+func TestRollback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+
+	dir := t.TempDir()
+	previousManifest, err := encodeManifest("previous manifest")
+	assert.NilError(t, err)
+	currentManifest, err := encodeManifest("current manifest")
+	assert.NilError(t, err)
+	previousObjects := []remotecache.KubectlObject{{APIVersion: "v1", Kind: "ConfigMap", Name: "previous", Namespace: "default"}}
+	currentObjects := []remotecache.KubectlObject{{APIVersion: "v1", Kind: "ConfigMap", Name: "current", Namespace: "default"}}
+
+	remoteCache := &remotecache.RemoteCache{}
+	remoteCache.SetDeployment("test", remotecache.DeploymentCache{
+		Name:                 "test",
+		DeploymentConfigHash: "hash",
+		Kubectl: &remotecache.KubectlCache{
+			Objects:  currentObjects,
+			Manifest: currentManifest,
+			Previous: &remotecache.KubectlRevision{
+				Objects:  previousObjects,
+				Manifest: previousManifest,
+			},
+		},
+	})
+	remoteCache.SetDeployment("new", remotecache.DeploymentCache{
+		Name:    "new",
+		Kubectl: &remotecache.KubectlCache{Objects: currentObjects, Manifest: currentManifest},
+	})
+
+	rawConfig := latest.NewRaw()
+	rawConfig.Deployments = map[string]*latest.DeploymentConfig{
+		"test": {
+			Name: "test",
+			Kubectl: &latest.KubectlConfig{
+				KubectlBinaryPath: writeFakeKubectl(t, dir),
+				ServerSideApply:   ptr.Bool(true),
+			},
+		},
+	}
+	conf := config.NewConfig(nil, nil, rawConfig, localcache.New(constants.DefaultCacheFolder), remoteCache, nil, constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.NewFakeLogger()).WithConfig(conf).WithKubeClient(&fakekube.Client{Context: "test-context"}).WithWorkingDir(dir)
+
+	// a deployment without previous revision can't be rolled back
+	err = Rollback(ctx, "new")
+	assert.Error(t, err, "deployment new has no previous revision to roll back to")
+
+	// the previous manifest is applied with the apply flags of the deployment
+	err = Rollback(ctx, "test")
+	assert.NilError(t, err)
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	assert.NilError(t, err)
+	assert.Equal(t, strings.TrimSpace(string(args)), "--context test-context apply --server-side --field-manager devspace -f -")
+	manifest, err := os.ReadFile(filepath.Join(dir, "manifest"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(manifest), "previous manifest")
+
+	// the revisions are swapped and the next deploy applies the manifests again
+	deploymentCache, _ := remoteCache.GetDeployment("test")
+	assert.Equal(t, deploymentCache.DeploymentConfigHash, "")
+	assert.DeepEqual(t, deploymentCache.Kubectl, &remotecache.KubectlCache{
+		Objects:  previousObjects,
+		Manifest: previousManifest,
+		Previous: &remotecache.KubectlRevision{
+			Objects:  currentObjects,
+			Manifest: currentManifest,
+		},
+	})
+}
@@ -0,0 +1,154 @@
+package kubectl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader/variable/runtime"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/utils/pkg/command"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// Plan applies the manifests with a server-side dry-run and compares the result with the objects in the cluster.
+// Objects that were removed from the manifests are only deleted, if noPrune is false
+func (d *DeployConfig) Plan(ctx devspacecontext.Context, noPrune bool) ([]deployer.Change, error) {
+	manifests := []string{}
+	for _, manifest := range d.Manifests {
+		_, replacedManifest, _, err := d.getReplacedManifest(ctx, false, manifest)
+		if err != nil {
+			return nil, errors.Errorf("%v\nPlease make sure `kubectl apply` does work locally with manifest `%s`", err, manifest)
+		}
+
+		manifests = append(manifests, replacedManifest)
+	}
+	if d.InlineManifest != "" {
+		resolvedInlineManifest, err := runtime.NewRuntimeResolver(ctx.WorkingDir(), false).FillRuntimeVariablesAsString(ctx.Context(), d.InlineManifest, ctx.Config(), ctx.Dependencies())
+		if err != nil {
+			return nil, err
+		}
+
+		_, replacedManifest, _, err := d.getReplacedManifest(ctx, true, resolvedInlineManifest)
+		if err != nil {
+			return nil, err
+		}
+
+		manifests = append(manifests, replacedManifest)
+	}
+
+	args := d.getCmdArgs("apply", append(d.applyFlags(), "--dry-run=server", "--output", "yaml")...)
+	args = append(args, d.DeploymentConfig.Kubectl.ApplyArgs...)
+	desired, err := DryRun(ctx, d.CmdPath, args, strings.Join(manifests, "\n---\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	return PlanChanges(ctx, d.DeploymentConfig.Name, desired, noPrune)
+}
+
+// PlanChanges compares the desired objects with their current state and the objects the deployment has deployed before,
+// which are not pruned if noPrune is true
+func PlanChanges(ctx devspacecontext.Context, deploymentName string, desired []*unstructured.Unstructured, noPrune bool) ([]deployer.Change, error) {
+	// get the current state of the desired objects and the objects that were deployed before
+	current := []*unstructured.Unstructured{}
+	for _, obj := range desired {
+		liveObj, err := getObject(ctx, obj.GetAPIVersion(), obj.GetKind(), obj.GetName(), obj.GetNamespace())
+		if err != nil {
+			return nil, err
+		} else if liveObj != nil {
+			current = append(current, liveObj)
+		}
+	}
+	deployCache, ok := ctx.Config().RemoteCache().GetDeployment(deploymentName)
+	if ok && deployCache.Kubectl != nil && !noPrune {
+		for _, resource := range deployCache.Kubectl.Objects {
+			if containsObject(desired, resource.APIVersion, resource.Kind, resource.Name, resource.Namespace) {
+				continue
+			}
+
+			liveObj, err := getObject(ctx, resource.APIVersion, resource.Kind, resource.Name, resource.Namespace)
+			if err != nil {
+				return nil, err
+			} else if liveObj != nil {
+				current = append(current, liveObj)
+			}
+		}
+	}
+
+	return deployer.Changes(current, desired)
+}
+
+// DryRun runs kubectl with the given dry-run args and returns the objects as they would look like after a kubectl apply
+func DryRun(ctx devspacecontext.Context, cmdPath string, args []string, manifest string) ([]*unstructured.Unstructured, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	err := command.Command(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), stdout, stderr, strings.NewReader(manifest), cmdPath, args...)
+	if err != nil {
+		return nil, errors.Errorf("%v %v\nPlease make sure the command `kubectl apply --dry-run=server` does work locally", stderr.String(), err)
+	}
+
+	objects, err := deployer.ParseObjects(stdout.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// kubectl prints multiple objects as list
+	items := []*unstructured.Unstructured{}
+	for _, obj := range objects {
+		if !obj.IsList() {
+			items = append(items, obj)
+			continue
+		}
+
+		err = obj.EachListItem(func(item k8sruntime.Object) error {
+			items = append(items, item.(*unstructured.Unstructured))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+// getObject returns the object from the cluster or nil if it doesn't exist
+func getObject(ctx devspacecontext.Context, apiVersion, kind, name, namespace string) (*unstructured.Unstructured, error) {
+	out, err := ctx.KubeClient().GenericRequest(ctx.Context(), &kubectl.GenericRequestOptions{
+		Kind:       kind,
+		APIVersion: apiVersion,
+		Name:       name,
+		Namespace:  namespace,
+	})
+	if err != nil {
+		if kerrors.IsNotFound(errors.Cause(err)) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "get %s %s", kind, name)
+	}
+
+	obj := &unstructured.Unstructured{}
+	err = json.Unmarshal([]byte(out), &obj.Object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %s %s", kind, name)
+	}
+
+	return obj, nil
+}
+
+func containsObject(objects []*unstructured.Unstructured, apiVersion, kind, name, namespace string) bool {
+	for _, obj := range objects {
+		if obj.GetAPIVersion() == apiVersion && obj.GetKind() == kind && obj.GetName() == name && obj.GetNamespace() == namespace {
+			return true
+		}
+	}
+
+	return false
+}
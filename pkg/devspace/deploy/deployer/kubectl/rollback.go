@@ -0,0 +1,161 @@
+package kubectl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/utils/pkg/command"
+	"github.com/loft-sh/utils/pkg/downloader"
+	"github.com/loft-sh/utils/pkg/downloader/commands"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// NewCache returns the cache of a deploy that applied the manifest. The previously applied manifest is kept
+// as previous revision, so that a rollback can apply it again
+func NewCache(previous *remotecache.KubectlCache, objects []remotecache.KubectlObject, manifestsHash, manifest string) (*remotecache.KubectlCache, error) {
+	encodedManifest, err := encodeManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := &remotecache.KubectlCache{
+		Objects:       objects,
+		ManifestsHash: manifestsHash,
+		Manifest:      encodedManifest,
+	}
+	if previous != nil {
+		if previous.Manifest == encodedManifest {
+			// nothing has changed, so we keep the revision before
+			cache.Previous = previous.Previous
+		} else if previous.Manifest != "" {
+			cache.Previous = &remotecache.KubectlRevision{
+				Objects:  previous.Objects,
+				Manifest: previous.Manifest,
+			}
+		}
+	}
+
+	return cache, nil
+}
+
+// Rollback applies the manifest of the previous revision of the deployment again. The current revision
+// becomes the previous revision, so another rollback reverts the rollback
+func Rollback(ctx devspacecontext.Context, deploymentName string) error {
+	deploymentCache, ok := ctx.Config().RemoteCache().GetDeployment(deploymentName)
+	if !ok || deploymentCache.Kubectl == nil || deploymentCache.Kubectl.Previous == nil || deploymentCache.Kubectl.Previous.Manifest == "" {
+		return fmt.Errorf("deployment %s has no previous revision to roll back to", deploymentName)
+	}
+
+	manifest, err := decodeManifest(deploymentCache.Kubectl.Previous.Manifest)
+	if err != nil {
+		return errors.Wrap(err, "decode previous manifest")
+	}
+
+	d, err := newRollbackConfig(ctx, deploymentName)
+	if err != nil {
+		return err
+	}
+
+	ctx.Log().Info("Applying previous manifests with kubectl...")
+	writer := ctx.Log().Writer(logrus.InfoLevel, false)
+	defer writer.Close()
+
+	args := d.getCmdArgs("apply", d.applyFlags("--force")...)
+	stdErrBuffer := &bytes.Buffer{}
+	err = command.Command(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), writer, io.MultiWriter(writer, stdErrBuffer), strings.NewReader(manifest), d.CmdPath, args...)
+	if err != nil {
+		return errors.Errorf("%v %v", stdErrBuffer.String(), err)
+	}
+
+	// swap the revisions and make sure the next deploy applies the manifests again
+	deploymentCache.Kubectl = &remotecache.KubectlCache{
+		Objects:  deploymentCache.Kubectl.Previous.Objects,
+		Manifest: deploymentCache.Kubectl.Previous.Manifest,
+		Previous: &remotecache.KubectlRevision{
+			Objects:  deploymentCache.Kubectl.Objects,
+			Manifest: deploymentCache.Kubectl.Manifest,
+		},
+	}
+	deploymentCache.DeploymentConfigHash = ""
+	ctx.Config().RemoteCache().SetDeployment(deploymentName, deploymentCache)
+	return nil
+}
+
+// newRollbackConfig returns the deploy config the previous manifest is applied with. The kubectl options of the
+// deployment are used, if the deployment is still part of the config
+func newRollbackConfig(ctx devspacecontext.Context, deploymentName string) (*DeployConfig, error) {
+	kubectlConfig := &latest.KubectlConfig{}
+	if deploymentConfig, ok := ctx.Config().Config().Deployments[deploymentName]; ok {
+		if deploymentConfig.Kubectl != nil {
+			kubectlConfig = deploymentConfig.Kubectl
+		} else if deploymentConfig.Kustomize != nil {
+			kubectlConfig.KubectlBinaryPath = deploymentConfig.Kustomize.KubectlBinaryPath
+		}
+	}
+
+	cmdPath := kubectlConfig.KubectlBinaryPath
+	if cmdPath == "" {
+		var err error
+		cmdPath, err = downloader.NewDownloader(commands.NewKubectlCommand(), ctx.Log(), constants.DefaultHomeDevSpaceFolder).EnsureCommand(ctx.Context())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &DeployConfig{
+		Name:        deploymentName,
+		CmdPath:     cmdPath,
+		Context:     ctx.KubeClient().CurrentContext(),
+		IsInCluster: ctx.KubeClient().IsInCluster(),
+
+		DeploymentConfig: &latest.DeploymentConfig{
+			Name:    deploymentName,
+			Kubectl: kubectlConfig,
+		},
+	}, nil
+}
+
+func encodeManifest(manifest string) (string, error) {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	_, err := writer.Write([]byte(manifest))
+	if err != nil {
+		return "", err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
+
+func decodeManifest(encodedManifest string) (string, error) {
+	compressed, err := base64.StdEncoding.DecodeString(encodedManifest)
+	if err != nil {
+		return "", err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	manifest, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	return string(manifest), nil
+}
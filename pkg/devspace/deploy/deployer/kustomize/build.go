@@ -0,0 +1,199 @@
+package kustomize
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
+	"github.com/loft-sh/devspace/pkg/util/dockerfile"
+	"github.com/loft-sh/utils/pkg/command"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// overlayFolder is the folder in the working dir where the generated overlays are written to
+const overlayFolder = ".devspace"
+
+// Image replaces an image of the kustomization, see the images field of kustomize
+type Image struct {
+	Name    string `json:"name"`
+	NewName string `json:"newName,omitempty"`
+	NewTag  string `json:"newTag,omitempty"`
+}
+
+// Patch is a patch of the kustomization, see the patches field of kustomize
+type Patch struct {
+	Path string `json:"path"`
+}
+
+// Kustomization is the overlay DevSpace generates on top of the configured kustomization
+type Kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Resources  []string `json:"resources"`
+	Components []string `json:"components,omitempty"`
+	Patches    []Patch  `json:"patches,omitempty"`
+	Images     []Image  `json:"images,omitempty"`
+}
+
+// build generates an overlay that adds the components and image replacements to the kustomization and builds it
+func (d *DeployConfig) build(ctx devspacecontext.Context) ([]*unstructured.Unstructured, error) {
+	err := os.MkdirAll(ctx.ResolvePath(overlayFolder), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	// the overlay is created in the working dir, so that it can reference the kustomization and components relatively
+	overlayDir, err := os.MkdirTemp(ctx.ResolvePath(overlayFolder), "kustomize-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(overlayDir)
+
+	images := []Image{}
+	if d.DeploymentConfig.UpdateImageTags == nil || *d.DeploymentConfig.UpdateImageTags {
+		images = builtImages(ctx)
+	}
+
+	kustomization, err := newKustomization(overlayDir, ctx.ResolvePath(d.DeploymentConfig.Kustomize.Path), resolvePaths(ctx, d.DeploymentConfig.Kustomize.Components), images)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(overlayDir, "kustomization.yaml"), out, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Log().Infof("Render kustomization %s", d.DeploymentConfig.Kustomize.Path)
+	output, err := d.kustomize(ctx, overlayDir)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := deployer.ParseObjects(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range objects {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(d.Namespace)
+		}
+	}
+
+	return objects, nil
+}
+
+// kustomize builds the overlay in-process or with `kustomize build`, if a kustomize binary is configured
+func (d *DeployConfig) kustomize(ctx devspacecontext.Context, overlayDir string) ([]byte, error) {
+	if d.KustomizePath == "" {
+		resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(filesys.MakeFsOnDisk(), overlayDir)
+		if err != nil {
+			return nil, err
+		}
+
+		return resources.AsYaml()
+	}
+
+	args := append([]string{"build", overlayDir}, d.DeploymentConfig.Kustomize.BuildArgs...)
+	output, err := command.Output(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), d.KustomizePath, args...)
+	if err != nil {
+		exitError, ok := err.(*exec.ExitError)
+		if ok {
+			return nil, errors.New(string(exitError.Stderr))
+		}
+
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// newKustomization returns an overlay in the overlay dir for the kustomization and components
+func newKustomization(overlayDir, path string, components []string, images []Image) (*Kustomization, error) {
+	resource, err := filepath.Rel(overlayDir, path)
+	if err != nil {
+		return nil, err
+	}
+
+	kustomization := &Kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  []string{filepath.ToSlash(resource)},
+		Images:     images,
+	}
+	for _, component := range components {
+		relComponent, err := filepath.Rel(overlayDir, component)
+		if err != nil {
+			return nil, err
+		}
+
+		kustomization.Components = append(kustomization.Components, filepath.ToSlash(relComponent))
+	}
+
+	return kustomization, nil
+}
+
+// builtImages returns the images of the images section with their most recent built tag
+func builtImages(ctx devspacecontext.Context) []Image {
+	images := []Image{}
+	for imageConfigName, imageConfig := range ctx.Config().Config().Images {
+		imageCache, ok := ctx.Config().LocalCache().GetImageCache(imageConfigName)
+		if !ok || imageCache.Tag == "" {
+			continue
+		}
+
+		name, _, err := dockerfile.GetStrippedDockerImageName(imageConfig.Image)
+		if err != nil {
+			continue
+		}
+
+		image := Image{Name: name, NewTag: imageCache.Tag}
+		if imageCache.ResolveImage() != name {
+			image.NewName = imageCache.ResolveImage()
+		}
+
+		images = append(images, image)
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Name < images[j].Name
+	})
+
+	return images
+}
+
+func resolvePaths(ctx devspacecontext.Context, paths []string) []string {
+	resolved := []string{}
+	for _, path := range paths {
+		resolved = append(resolved, ctx.ResolvePath(path))
+	}
+
+	return resolved
+}
+
+func marshalObjects(objects []*unstructured.Unstructured) (string, error) {
+	manifests := []string{}
+	for _, obj := range objects {
+		out, err := yaml.Marshal(obj)
+		if err != nil {
+			return "", errors.Wrap(err, "marshal yaml")
+		}
+
+		manifests = append(manifests, string(out))
+	}
+
+	return strings.Join(manifests, "\n---\n"), nil
+}
@@ -0,0 +1,229 @@
+package kustomize
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/context/values"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kubectl"
+	kubectlclient "github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/util/hash"
+	"github.com/loft-sh/devspace/pkg/util/stringutil"
+	"github.com/loft-sh/utils/pkg/command"
+	"github.com/loft-sh/utils/pkg/downloader"
+	"github.com/loft-sh/utils/pkg/downloader/commands"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// DeployConfig holds the necessary information for a kustomize deployment
+type DeployConfig struct {
+	Name          string
+	CmdPath       string
+	KustomizePath string
+	Context       string
+	Namespace     string
+	IsInCluster   bool
+
+	DeploymentConfig *latest.DeploymentConfig
+}
+
+// New creates a new deploy config for kustomize
+func New(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig) (deployer.Interface, error) {
+	if deployConfig.Kustomize == nil {
+		return nil, errors.New("error creating kustomize deploy config: kustomize is nil")
+	} else if deployConfig.Kustomize.Path == "" {
+		return nil, errors.New("no path defined for kustomize deploy")
+	} else if len(deployConfig.Kustomize.BuildArgs) > 0 && deployConfig.Kustomize.KustomizeBinaryPath == "" {
+		return nil, errors.New("kustomize build args require a kustomize binary path, because the kustomization is built in-process otherwise")
+	}
+
+	// make sure kubectl exists
+	var (
+		err     error
+		cmdPath string
+	)
+	if deployConfig.Kustomize.KubectlBinaryPath != "" {
+		cmdPath = deployConfig.Kustomize.KubectlBinaryPath
+	} else {
+		cmdPath, err = downloader.NewDownloader(commands.NewKubectlCommand(), ctx.Log(), constants.DefaultHomeDevSpaceFolder).EnsureCommand(ctx.Context())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deployConfigObj := &DeployConfig{
+		Name:          deployConfig.Name,
+		CmdPath:       cmdPath,
+		KustomizePath: deployConfig.Kustomize.KustomizeBinaryPath,
+
+		DeploymentConfig: deployConfig,
+	}
+	if ctx.KubeClient() == nil {
+		return deployConfigObj, nil
+	}
+
+	deployConfigObj.Context = ctx.KubeClient().CurrentContext()
+	deployConfigObj.IsInCluster = ctx.KubeClient().IsInCluster()
+	deployConfigObj.Namespace = deployConfig.Namespace
+	if deployConfigObj.Namespace == "" {
+		deployConfigObj.Namespace = ctx.KubeClient().Namespace()
+	}
+
+	return deployConfigObj, nil
+}
+
+// Status prints the status of the kustomization
+func (d *DeployConfig) Status(ctx devspacecontext.Context) (*deployer.StatusResult, error) {
+	return &deployer.StatusResult{
+		Name:   d.Name,
+		Type:   "Kustomize",
+		Target: d.DeploymentConfig.Kustomize.Path,
+		Status: "N/A",
+	}, nil
+}
+
+// Render writes the built kustomization to the out stream
+func (d *DeployConfig) Render(ctx devspacecontext.Context, out io.Writer) error {
+	manifest, err := d.manifest(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, _ = out.Write([]byte(manifest))
+	_, _ = out.Write([]byte("\n---\n"))
+	return nil
+}
+
+// Deploy builds the kustomization and applies it via kubectl. The kustomization is skipped, if neither its files,
+// the replaced images nor the deployment config changed since the last deploy
+func (d *DeployConfig) Deploy(ctx devspacecontext.Context, forceDeploy bool) (bool, error) {
+	deployCache, _ := ctx.Config().RemoteCache().GetDeployment(d.DeploymentConfig.Name)
+
+	manifestsHash, deploymentConfigHash, err := d.hashes(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !forceDeploy && deployCache.Kubectl != nil && deployCache.Kubectl.ManifestsHash == manifestsHash && deployCache.DeploymentConfigHash == deploymentConfigHash {
+		ctx.Log().Infof("Skip deployment %s, because the kustomization didn't change", d.DeploymentConfig.Name)
+		return false, nil
+	}
+
+	objects, err := d.build(ctx)
+	if err != nil {
+		return false, err
+	}
+	manifest, err := marshalObjects(objects)
+	if err != nil {
+		return false, err
+	}
+
+	ctx.Log().Info("Applying kustomization with kubectl...")
+	writer := ctx.Log().Writer(logrus.InfoLevel, false)
+	defer writer.Close()
+
+	args := d.getCmdArgs("apply", "--force")
+	args = append(args, d.DeploymentConfig.Kustomize.ApplyArgs...)
+	stdErrBuffer := &bytes.Buffer{}
+	err = command.Command(ctx.Context(), ctx.WorkingDir(), ctx.Environ(), writer, io.MultiWriter(writer, stdErrBuffer), strings.NewReader(manifest), d.CmdPath, args...)
+	if err != nil {
+		return false, errors.Errorf("%v %v\nPlease make sure the command `kubectl apply` does work locally with kustomization `%s`", stdErrBuffer.String(), err, d.DeploymentConfig.Kustomize.Path)
+	}
+
+	// the deployed objects are stored in the kubectl cache, so that they are purged, waited for and rolled back the same way
+	kubeObjects := []remotecache.KubectlObject{}
+	for _, obj := range objects {
+		kubeObjects = append(kubeObjects, remotecache.KubectlObject{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+			Namespace:  obj.GetNamespace(),
+		})
+	}
+	deployCache.Kubectl, err = kubectl.NewCache(deployCache.Kubectl, kubeObjects, manifestsHash, manifest)
+	if err != nil {
+		return false, err
+	}
+	deployCache.DeploymentConfigHash = deploymentConfigHash
+	if rootName, ok := values.RootNameFrom(ctx.Context()); ok && !stringutil.Contains(deployCache.Projects, rootName) {
+		deployCache.Projects = append(deployCache.Projects, rootName)
+	}
+	ctx.Config().RemoteCache().SetDeployment(d.DeploymentConfig.Name, deployCache)
+	return true, nil
+}
+
+// hashes returns the hash of the kustomization, its components and the replaced images as well as the hash of the
+// deployment config
+func (d *DeployConfig) hashes(ctx devspacecontext.Context) (string, string, error) {
+	manifestsHash := ""
+	for _, path := range append([]string{d.DeploymentConfig.Kustomize.Path}, d.DeploymentConfig.Kustomize.Components...) {
+		path = ctx.ResolvePath(path)
+		hash, err := hash.Directory(path)
+		if err != nil {
+			return "", "", errors.Errorf("Error hashing %s: %v", path, err)
+		}
+
+		manifestsHash += hash
+	}
+	if d.DeploymentConfig.UpdateImageTags == nil || *d.DeploymentConfig.UpdateImageTags {
+		imagesStr, err := yaml.Marshal(builtImages(ctx))
+		if err != nil {
+			return "", "", errors.Wrap(err, "marshal images")
+		}
+
+		manifestsHash += hash.String(d.Namespace + string(imagesStr))
+	}
+
+	configStr, err := yaml.Marshal(d.DeploymentConfig)
+	if err != nil {
+		return "", "", errors.Wrap(err, "marshal deployment config")
+	}
+
+	return manifestsHash, hash.String(string(configStr)), nil
+}
+
+// Plan applies the built kustomization with a server-side dry-run and compares the result with the objects in the cluster
+func (d *DeployConfig) Plan(ctx devspacecontext.Context, noPrune bool) ([]deployer.Change, error) {
+	manifest, err := d.manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	args := d.getCmdArgs("apply", "--dry-run=server", "--output", "yaml")
+	args = append(args, d.DeploymentConfig.Kustomize.ApplyArgs...)
+	desired, err := kubectl.DryRun(ctx, d.CmdPath, args, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return kubectl.PlanChanges(ctx, d.DeploymentConfig.Name, desired, noPrune)
+}
+
+func (d *DeployConfig) manifest(ctx devspacecontext.Context) (string, error) {
+	objects, err := d.build(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return marshalObjects(objects)
+}
+
+func (d *DeployConfig) getCmdArgs(method string, additionalArgs ...string) []string {
+	args := []string{}
+	if d.Context != "" && !d.IsInCluster {
+		args = append(args, "--context", d.Context)
+	}
+	args = append(args, kubectlclient.ImpersonationArgs("--as", "--as-group")...)
+
+	args = append(args, method)
+	args = append(args, additionalArgs...)
+	args = append(args, "-f", "-")
+	return args
+}
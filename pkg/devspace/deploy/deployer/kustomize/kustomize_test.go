@@ -0,0 +1,182 @@
+package kustomize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type newKustomizationTestCase struct {
+	name string
+
+	overlayDir string
+	path       string
+	components []string
+	images     []Image
+
+	expected *Kustomization
+}
+
+func TestNewKustomization(t *testing.T) {
+	testCases := []newKustomizationTestCase{
+		{
+			name:       "Overlay",
+			overlayDir: "/project/.devspace/kustomize-123",
+			path:       "/project/kube/overlays/dev",
+			expected: &Kustomization{
+				APIVersion: "kustomize.config.k8s.io/v1beta1",
+				Kind:       "Kustomization",
+				Resources:  []string{"../../kube/overlays/dev"},
+			},
+		},
+		{
+			name:       "Components and images",
+			overlayDir: "/project/.devspace/kustomize-123",
+			path:       "/project/kube/base",
+			components: []string{"/project/kube/components/debug"},
+			images:     []Image{{Name: "my-app", NewTag: "abc"}},
+			expected: &Kustomization{
+				APIVersion: "kustomize.config.k8s.io/v1beta1",
+				Kind:       "Kustomization",
+				Resources:  []string{"../../kube/base"},
+				Components: []string{"../../kube/components/debug"},
+				Images:     []Image{{Name: "my-app", NewTag: "abc"}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		kustomization, err := newKustomization(testCase.overlayDir, testCase.path, testCase.components, testCase.images)
+		assert.NilError(t, err, "Unexpected error in testCase %s", testCase.name)
+		assert.DeepEqual(t, kustomization, testCase.expected)
+	}
+}
+
+func TestBuiltImages(t *testing.T) {
+	cache := localcache.New("")
+	cache.SetImageCache("app", localcache.ImageCache{ImageName: "my-app", Tag: "abc"})
+	cache.SetImageCache("local", localcache.ImageCache{ImageName: "local-app", LocalRegistryImageName: "localhost:5000/local-app", Tag: "def"})
+	cache.SetImageCache("unbuilt", localcache.ImageCache{ImageName: "unbuilt"})
+
+	conf := latest.NewRaw()
+	conf.Images = map[string]*latest.Image{
+		"app":     {Image: "my-app"},
+		"local":   {Image: "local-app"},
+		"unbuilt": {Image: "unbuilt"},
+	}
+
+	ctx := devspacecontext.NewContext(context.TODO(), nil, log.Discard).WithConfig(config.NewConfig(nil, nil, conf, cache, &remotecache.RemoteCache{}, nil, ""))
+	assert.DeepEqual(t, builtImages(ctx), []Image{
+		{Name: "local-app", NewName: "localhost:5000/local-app", NewTag: "def"},
+		{Name: "my-app", NewTag: "abc"},
+	})
+}
+
+const testDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: my-app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: my-app
+`
+
+func newTestContext(t *testing.T) devspacecontext.Context {
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "kube"), 0755)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "kube", "kustomization.yaml"), []byte("resources:\n- deployment.yaml\n"), 0644)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "kube", "deployment.yaml"), []byte(testDeployment), 0644)
+	assert.NilError(t, err)
+
+	cache := localcache.New(constants.DefaultCacheFolder)
+	cache.SetImageCache("app", localcache.ImageCache{ImageName: "my-app", Tag: "abc"})
+
+	conf := latest.NewRaw()
+	conf.Images = map[string]*latest.Image{
+		"app": {Image: "my-app"},
+	}
+
+	return devspacecontext.NewContext(context.Background(), nil, log.Discard).
+		WithConfig(config.NewConfig(nil, nil, conf, cache, &remotecache.RemoteCache{}, nil, constants.DefaultConfigPath)).
+		WithWorkingDir(dir)
+}
+
+func TestBuild(t *testing.T) {
+	ctx := newTestContext(t)
+	d := &DeployConfig{
+		Namespace: "test",
+		DeploymentConfig: &latest.DeploymentConfig{
+			Name:      "test",
+			Kustomize: &latest.KustomizeConfig{Path: "kube"},
+		},
+	}
+
+	objects, err := d.build(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(objects), 1)
+	assert.Equal(t, objects[0].GetName(), "my-app")
+	assert.Equal(t, objects[0].GetNamespace(), "test")
+
+	containers, _, err := unstructured.NestedSlice(objects[0].Object, "spec", "template", "spec", "containers")
+	assert.NilError(t, err)
+	assert.Equal(t, containers[0].(map[string]interface{})["image"], "my-app:abc")
+
+	// the generated overlay is removed again
+	entries, err := os.ReadDir(ctx.ResolvePath(overlayFolder))
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+}
+
+func TestDeployUnchanged(t *testing.T) {
+	ctx := newTestContext(t)
+	d := &DeployConfig{
+		Namespace: "test",
+		DeploymentConfig: &latest.DeploymentConfig{
+			Name:      "test",
+			Kustomize: &latest.KustomizeConfig{Path: "kube"},
+		},
+	}
+
+	manifestsHash, deploymentConfigHash, err := d.hashes(ctx)
+	assert.NilError(t, err)
+	ctx.Config().RemoteCache().SetDeployment("test", remotecache.DeploymentCache{
+		Name:                 "test",
+		DeploymentConfigHash: deploymentConfigHash,
+		Kubectl:              &remotecache.KubectlCache{ManifestsHash: manifestsHash},
+	})
+
+	// the kustomization is neither built nor applied
+	deployed, err := d.Deploy(ctx, false)
+	assert.NilError(t, err)
+	assert.Equal(t, deployed, false)
+
+	// a new image tag changes the hash
+	ctx.Config().LocalCache().SetImageCache("app", localcache.ImageCache{ImageName: "my-app", Tag: "def"})
+	newManifestsHash, _, err := d.hashes(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, newManifestsHash != manifestsHash)
+
+	// a changed file changes the hash
+	ctx.Config().LocalCache().SetImageCache("app", localcache.ImageCache{ImageName: "my-app", Tag: "abc"})
+	err = os.WriteFile(filepath.Join(ctx.WorkingDir(), "kube", "deployment.yaml"), []byte(testDeployment+"  replicas: 2\n"), 0644)
+	assert.NilError(t, err)
+	newManifestsHash, _, err = d.hashes(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, newManifestsHash != manifestsHash)
+}
@@ -0,0 +1,108 @@
+package kustomize
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/utils/pkg/command"
+	"github.com/pkg/errors"
+	"mvdan.cc/sh/v3/expand"
+)
+
+// PostRendererInput is the file of a post renderer overlay the chart rendered by helm is written to
+const PostRendererInput = "helm-output.yaml"
+
+// NewPostRendererOverlay writes an overlay that applies the patches and components to the chart rendered by
+// helm. The caller has to remove the returned folder
+func NewPostRendererOverlay(ctx devspacecontext.Context, postRenderer *latest.HelmKustomizePostRenderer) (string, error) {
+	err := os.MkdirAll(ctx.ResolvePath(overlayFolder), 0755)
+	if err != nil {
+		return "", err
+	}
+
+	overlayDir, err := os.MkdirTemp(ctx.ResolvePath(overlayFolder), "post-renderer-")
+	if err != nil {
+		return "", err
+	}
+
+	kustomization, err := newPostRendererKustomization(overlayDir, resolvePaths(ctx, postRenderer.Patches), resolvePaths(ctx, postRenderer.Components))
+	if err != nil {
+		_ = os.RemoveAll(overlayDir)
+		return "", err
+	}
+
+	out, err := yaml.Marshal(kustomization)
+	if err != nil {
+		_ = os.RemoveAll(overlayDir)
+		return "", err
+	}
+
+	err = os.WriteFile(filepath.Join(overlayDir, "kustomization.yaml"), out, 0644)
+	if err != nil {
+		_ = os.RemoveAll(overlayDir)
+		return "", err
+	}
+
+	return overlayDir, nil
+}
+
+// newPostRendererKustomization returns the overlay for the patches and components. Kustomize only loads patch
+// files from within the kustomization, so the patches are copied into the overlay
+func newPostRendererKustomization(overlayDir string, patches, components []string) (*Kustomization, error) {
+	kustomization := &Kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  []string{PostRendererInput},
+	}
+	for i, patch := range patches {
+		out, err := os.ReadFile(patch)
+		if err != nil {
+			return nil, errors.Wrap(err, "read patch")
+		}
+
+		fileName := fmt.Sprintf("patch-%d-%s", i, filepath.Base(patch))
+		err = os.WriteFile(filepath.Join(overlayDir, fileName), out, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		kustomization.Patches = append(kustomization.Patches, Patch{Path: fileName})
+	}
+	for _, component := range components {
+		relComponent, err := filepath.Rel(overlayDir, component)
+		if err != nil {
+			return nil, err
+		}
+
+		kustomization.Components = append(kustomization.Components, filepath.ToSlash(relComponent))
+	}
+
+	return kustomization, nil
+}
+
+// PostRender writes the chart rendered by helm into the overlay and prints the built overlay. Without a
+// kustomize binary, the overlay is built with `kubectl kustomize`
+func PostRender(ctx context.Context, overlayDir, kubectlPath, kustomizePath string, in io.Reader, out, errOut io.Writer) error {
+	input, err := io.ReadAll(in)
+	if err != nil {
+		return errors.Wrap(err, "read rendered chart")
+	}
+
+	err = os.WriteFile(filepath.Join(overlayDir, PostRendererInput), input, 0644)
+	if err != nil {
+		return err
+	}
+
+	cmdPath, args := kubectlPath, []string{"kustomize", overlayDir}
+	if kustomizePath != "" {
+		cmdPath, args = kustomizePath, []string{"build", overlayDir}
+	}
+
+	return command.Command(ctx, overlayDir, expand.ListEnviron(os.Environ()...), out, errOut, nil, cmdPath, args...)
+}
@@ -0,0 +1,48 @@
+package kustomize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestNewPostRendererOverlay(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "kube", "components", "sidecar"), 0755)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "kube", "labels.yaml"), []byte("kind: Deployment\n"), 0644)
+	assert.NilError(t, err)
+
+	ctx := devspacecontext.NewContext(context.TODO(), nil, log.Discard).WithWorkingDir(dir)
+	overlayDir, err := NewPostRendererOverlay(ctx, &latest.HelmKustomizePostRenderer{
+		Patches:    []string{"kube/labels.yaml"},
+		Components: []string{"kube/components/sidecar"},
+	})
+	assert.NilError(t, err)
+	defer os.RemoveAll(overlayDir)
+
+	out, err := os.ReadFile(filepath.Join(overlayDir, "kustomization.yaml"))
+	assert.NilError(t, err)
+	kustomization := &Kustomization{}
+	err = yaml.Unmarshal(out, kustomization)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, kustomization, &Kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  []string{PostRendererInput},
+		Components: []string{"../../kube/components/sidecar"},
+		Patches:    []Patch{{Path: "patch-0-labels.yaml"}},
+	})
+
+	// kustomize only loads patches from within the overlay
+	patch, err := os.ReadFile(filepath.Join(overlayDir, "patch-0-labels.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(patch), "kind: Deployment\n")
+}
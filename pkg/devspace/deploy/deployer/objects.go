@@ -0,0 +1,42 @@
+package deployer
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var diffSeparator = regexp.MustCompile(`\n---`)
+
+// ParseObjects splits a YAML file into unstructured objects. Returns a list of all unstructured objects
+func ParseObjects(out string) ([]*unstructured.Unstructured, error) {
+	parts := diffSeparator.Split(out, -1)
+	var objs []*unstructured.Unstructured
+	var firstErr error
+	for _, part := range parts {
+		var objMap map[string]interface{}
+		err := yaml.Unmarshal([]byte(part), &objMap)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to unmarshal manifest: %v", err)
+			}
+			continue
+		}
+		if len(objMap) == 0 {
+			// handles case where theres no content between `---`
+			continue
+		}
+		var obj unstructured.Unstructured
+		err = yaml.Unmarshal([]byte(part), &obj)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to unmarshal manifest: %v", err)
+			}
+			continue
+		}
+		objs = append(objs, &obj)
+	}
+	return objs, firstErr
+}
@@ -0,0 +1,190 @@
+package deployer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ErrPlanNotSupported is returned by deployers that can't tell which changes a deployment would make
+var ErrPlanNotSupported = errors.New("plan is not supported")
+
+// ChangeAction is what a deployment would do with a kubernetes object
+type ChangeAction string
+
+const (
+	ChangeActionCreate ChangeAction = "create"
+	ChangeActionUpdate ChangeAction = "update"
+	ChangeActionDelete ChangeAction = "delete"
+)
+
+// Change is a change a deployment would make to a kubernetes object
+type Change struct {
+	Action     ChangeAction
+	APIVersion string
+	Kind       string
+	Name       string
+	Namespace  string
+
+	// Diff shows the changed lines of the object as yaml
+	Diff string
+}
+
+// diffContext is the number of unchanged lines that are shown around changed lines
+const diffContext = 2
+
+// Changes compares the current objects with the desired objects. Desired objects that don't exist yet are created,
+// current objects that aren't desired anymore are deleted
+func Changes(current, desired []*unstructured.Unstructured) ([]Change, error) {
+	currentObjects := map[string]*unstructured.Unstructured{}
+	for _, obj := range current {
+		currentObjects[objectKey(obj)] = obj
+	}
+
+	changes := []Change{}
+	desiredObjects := map[string]bool{}
+	for _, obj := range desired {
+		key := objectKey(obj)
+		desiredObjects[key] = true
+
+		after, err := objectYAML(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		currentObj, ok := currentObjects[key]
+		if !ok {
+			changes = append(changes, newChange(ChangeActionCreate, obj, diff("", after)))
+			continue
+		}
+
+		before, err := objectYAML(currentObj)
+		if err != nil {
+			return nil, err
+		} else if before != after {
+			changes = append(changes, newChange(ChangeActionUpdate, obj, diff(before, after)))
+		}
+	}
+
+	for _, obj := range current {
+		if desiredObjects[objectKey(obj)] {
+			continue
+		}
+
+		before, err := objectYAML(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, newChange(ChangeActionDelete, obj, diff(before, "")))
+	}
+
+	return changes, nil
+}
+
+func newChange(action ChangeAction, obj *unstructured.Unstructured, diff string) Change {
+	return Change{
+		Action:     action,
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		Diff:       diff,
+	}
+}
+
+func objectKey(obj *unstructured.Unstructured) string {
+	return obj.GetAPIVersion() + "/" + obj.GetKind() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// objectYAML returns the object as yaml without the fields the server manages
+func objectYAML(obj *unstructured.Unstructured) (string, error) {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration")
+	annotations, _, _ := unstructured.NestedMap(obj.Object, "metadata", "annotations")
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+	}
+
+	out, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", errors.Wrapf(err, "marshal %s %s", obj.GetKind(), obj.GetName())
+	}
+
+	return string(out), nil
+}
+
+// diff returns the changed lines with - and + and a few unchanged lines around them
+func diff(before, after string) string {
+	dmp := diffmatchpatch.New()
+	beforeChars, afterChars, lines := dmp.DiffLinesToChars(before, after)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(beforeChars, afterChars, false), lines)
+
+	type line struct {
+		prefix string
+		text   string
+	}
+	allLines := []line{}
+	for _, d := range diffs {
+		prefix := "  "
+		if d.Type == diffmatchpatch.DiffDelete {
+			prefix = "- "
+		} else if d.Type == diffmatchpatch.DiffInsert {
+			prefix = "+ "
+		}
+
+		for _, text := range strings.SplitAfter(d.Text, "\n") {
+			if text != "" {
+				allLines = append(allLines, line{prefix: prefix, text: strings.TrimSuffix(text, "\n")})
+			}
+		}
+	}
+
+	// only show the unchanged lines that are close to a changed line
+	show := make([]bool, len(allLines))
+	for i, l := range allLines {
+		if l.prefix == "  " {
+			continue
+		}
+
+		for j := i - diffContext; j <= i+diffContext; j++ {
+			if j >= 0 && j < len(allLines) {
+				show[j] = true
+			}
+		}
+	}
+
+	out := []string{}
+	for i, l := range allLines {
+		if !show[i] {
+			if i == 0 || show[i-1] {
+				out = append(out, "  ...")
+			}
+			continue
+		}
+
+		out = append(out, l.prefix+l.text)
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// SortChanges sorts the changes by action and object
+func SortChanges(changes []Change) {
+	order := map[ChangeAction]int{ChangeActionCreate: 0, ChangeActionUpdate: 1, ChangeActionDelete: 2}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Action != changes[j].Action {
+			return order[changes[i].Action] < order[changes[j].Action]
+		}
+
+		return changes[i].Kind+"/"+changes[i].Namespace+"/"+changes[i].Name < changes[j].Kind+"/"+changes[j].Namespace+"/"+changes[j].Name
+	})
+}
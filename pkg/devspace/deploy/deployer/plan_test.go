@@ -0,0 +1,100 @@
+package deployer
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestChanges(t *testing.T) {
+	current, err := ParseObjects(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: default
+  resourceVersion: "10"
+  uid: 1234
+data:
+  a: "1"
+  b: "2"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: service
+  namespace: default
+spec:
+  type: ClusterIP
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: old
+  namespace: default`)
+	assert.NilError(t, err)
+
+	desired, err := ParseObjects(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: default
+data:
+  a: "1"
+  b: "3"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: service
+  namespace: default
+  resourceVersion: "20"
+spec:
+  type: ClusterIP
+status:
+  loadBalancer: {}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default`)
+	assert.NilError(t, err)
+
+	changes, err := Changes(current, desired)
+	assert.NilError(t, err)
+	SortChanges(changes)
+	assert.DeepEqual(t, changes, []Change{
+		{
+			Action:     ChangeActionCreate,
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       "app",
+			Namespace:  "default",
+			Diff:       "+ apiVersion: apps/v1\n+ kind: Deployment\n+ metadata:\n+   name: app\n+   namespace: default",
+		},
+		{
+			Action:     ChangeActionUpdate,
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Name:       "config",
+			Namespace:  "default",
+			Diff:       "  ...\n  data:\n    a: \"1\"\n-   b: \"2\"\n+   b: \"3\"\n  kind: ConfigMap\n  metadata:\n  ...",
+		},
+		{
+			Action:     ChangeActionDelete,
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Name:       "old",
+			Namespace:  "default",
+			Diff:       "- apiVersion: v1\n- kind: Secret\n- metadata:\n-   name: old\n-   namespace: default",
+		},
+	})
+}
+
+func TestChangesUpToDate(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "config"}}}
+	changes, err := Changes([]*unstructured.Unstructured{obj}, []*unstructured.Unstructured{obj})
+	assert.NilError(t, err)
+	assert.Equal(t, len(changes), 0)
+}
@@ -0,0 +1,177 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/context/values"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
+	devspaceplugin "github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"github.com/loft-sh/devspace/pkg/util/hash"
+	"github.com/loft-sh/devspace/pkg/util/stringutil"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// DeployConfig holds the necessary information for a plugin deployment
+type DeployConfig struct {
+	Name      string
+	Namespace string
+	Context   string
+	Config    string
+
+	Plugin   *devspaceplugin.Metadata
+	Deployer *devspaceplugin.Deployer
+
+	DeploymentConfig *latest.DeploymentConfig
+}
+
+// New creates a new deploy config for a plugin deployer
+func New(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig) (deployer.Interface, error) {
+	if deployConfig.Plugin == nil {
+		return nil, errors.New("error creating plugin deploy config: plugin is nil")
+	}
+
+	plugin, pluginDeployer := devspaceplugin.GetDeployer(deployConfig.Plugin.Type)
+	if plugin == nil {
+		return nil, fmt.Errorf("couldn't find a plugin that registered deployment type %s. Please make sure the plugin is installed", deployConfig.Plugin.Type)
+	}
+
+	config := map[string]interface{}{}
+	if deployConfig.Plugin.Config != nil {
+		config = deployConfig.Plugin.Config
+	}
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal plugin config")
+	}
+
+	deployConfigObj := &DeployConfig{
+		Name:     deployConfig.Name,
+		Config:   string(configBytes),
+		Plugin:   plugin,
+		Deployer: pluginDeployer,
+
+		DeploymentConfig: deployConfig,
+	}
+	if ctx.KubeClient() != nil {
+		deployConfigObj.Context = ctx.KubeClient().CurrentContext()
+		deployConfigObj.Namespace = ctx.KubeClient().Namespace()
+	}
+	if deployConfig.Namespace != "" {
+		deployConfigObj.Namespace = deployConfig.Namespace
+	}
+
+	return deployConfigObj, nil
+}
+
+// Status retrieves the status of the deployment from the plugin. The plugin is expected to print
+// the status as json object with the fields type, target and status
+func (d *DeployConfig) Status(ctx devspacecontext.Context) (*deployer.StatusResult, error) {
+	out := &bytes.Buffer{}
+	err := devspaceplugin.CallDeployer(d.Plugin, d.Deployer, devspaceplugin.DeployerActionStatus, d.env(false), out)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &deployer.StatusResult{}
+	if len(strings.TrimSpace(out.String())) > 0 {
+		err = json.Unmarshal(out.Bytes(), status)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse status of plugin deployer %s", d.Deployer.Name)
+		}
+	}
+
+	status.Name = d.Name
+	if status.Type == "" {
+		status.Type = d.Deployer.Name
+	}
+	if status.Target == "" {
+		status.Target = d.Namespace
+	}
+	if status.Status == "" {
+		status.Status = "N/A"
+	}
+	return status, nil
+}
+
+// Render lets the plugin write the rendered deployment to the out stream
+func (d *DeployConfig) Render(ctx devspacecontext.Context, out io.Writer) error {
+	return devspaceplugin.CallDeployer(d.Plugin, d.Deployer, devspaceplugin.DeployerActionRender, d.env(false), out)
+}
+
+// Plan is not supported by plugin deployers, because they don't report the objects they deploy
+func (d *DeployConfig) Plan(ctx devspacecontext.Context, noPrune bool) ([]deployer.Change, error) {
+	return nil, deployer.ErrPlanNotSupported
+}
+
+// Deploy lets the plugin deploy the deployment
+func (d *DeployConfig) Deploy(ctx devspacecontext.Context, forceDeploy bool) (bool, error) {
+	deployCache, _ := ctx.Config().RemoteCache().GetDeployment(d.DeploymentConfig.Name)
+
+	writer := ctx.Log().Writer(logrus.InfoLevel, false)
+	defer writer.Close()
+
+	ctx.Log().Infof("Deploying with plugin deployer %s...", d.Deployer.Name)
+	err := devspaceplugin.CallDeployer(d.Plugin, d.Deployer, devspaceplugin.DeployerActionDeploy, d.env(forceDeploy), writer)
+	if err != nil {
+		return false, err
+	}
+
+	deployCache.Plugin = &remotecache.PluginCache{
+		Type:      d.Deployer.Name,
+		Config:    d.Config,
+		Namespace: d.Namespace,
+	}
+	deployCache.DeploymentConfigHash = hash.String(d.Config)
+	if rootName, ok := values.RootNameFrom(ctx.Context()); ok && !stringutil.Contains(deployCache.Projects, rootName) {
+		deployCache.Projects = append(deployCache.Projects, rootName)
+	}
+	ctx.Config().RemoteCache().SetDeployment(d.DeploymentConfig.Name, deployCache)
+	return true, nil
+}
+
+func (d *DeployConfig) env(forceDeploy bool) map[string]string {
+	return map[string]string{
+		devspaceplugin.DeployNameEnv:        d.Name,
+		devspaceplugin.DeployConfigEnv:      d.Config,
+		devspaceplugin.DeployKubeContextEnv: d.Context,
+		devspaceplugin.DeployNamespaceEnv:   d.Namespace,
+		devspaceplugin.DeployForceEnv:       strconv.FormatBool(forceDeploy),
+	}
+}
+
+// Delete lets the plugin that deployed the deployment purge it
+func Delete(ctx devspacecontext.Context, deploymentName string) error {
+	deploymentCache, ok := ctx.Config().RemoteCache().GetDeployment(deploymentName)
+	if !ok || deploymentCache.Plugin == nil || deploymentCache.Plugin.Type == "" {
+		return nil
+	}
+
+	plugin, pluginDeployer := devspaceplugin.GetDeployer(deploymentCache.Plugin.Type)
+	if plugin == nil {
+		return fmt.Errorf("couldn't find a plugin that registered deployment type %s. Please make sure the plugin is installed", deploymentCache.Plugin.Type)
+	}
+
+	kubeContext := ""
+	if ctx.KubeClient() != nil {
+		kubeContext = ctx.KubeClient().CurrentContext()
+	}
+
+	writer := ctx.Log().Writer(logrus.InfoLevel, false)
+	defer writer.Close()
+
+	return devspaceplugin.CallDeployer(plugin, pluginDeployer, devspaceplugin.DeployerActionPurge, map[string]string{
+		devspaceplugin.DeployNameEnv:        deploymentName,
+		devspaceplugin.DeployConfigEnv:      deploymentCache.Plugin.Config,
+		devspaceplugin.DeployKubeContextEnv: kubeContext,
+		devspaceplugin.DeployNamespaceEnv:   deploymentCache.Plugin.Namespace,
+	}, writer)
+}
@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	devspaceplugin "github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+// fakePlugin records the action and the deploy environment it was called with and prints a status
+const fakePlugin = `#!/bin/sh
+echo "$* $DEVSPACE_PLUGIN_DEPLOY_NAME $DEVSPACE_PLUGIN_DEPLOY_NAMESPACE $DEVSPACE_PLUGIN_DEPLOY_FORCE $DEVSPACE_PLUGIN_DEPLOY_CONFIG" >> "$(dirname "$0")/calls"
+if [ "$2" = "status" ]; then
+  echo '{"status":"Deployed","target":"my-stack"}'
+fi
+`
+
+func TestPluginDeployer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin binary is a shell script")
+	}
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, devspaceplugin.PluginBinary), []byte(fakePlugin), 0755)
+	assert.NilError(t, err)
+	devspaceplugin.SetPlugins([]devspaceplugin.Metadata{{
+		Name:         "pulumi-plugin",
+		PluginFolder: dir,
+		Deployers:    []devspaceplugin.Deployer{{Name: "pulumi", BaseArgs: []string{"deployer"}}},
+	}})
+
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		latest.NewRaw(),
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf)
+
+	// unknown deployment types are rejected
+	_, err = New(ctx, &latest.DeploymentConfig{Name: "stack", Plugin: &latest.PluginDeploymentConfig{Type: "terraform"}})
+	assert.ErrorContains(t, err, "couldn't find a plugin that registered deployment type terraform")
+
+	deployConfig := &latest.DeploymentConfig{
+		Name:      "stack",
+		Namespace: "infra",
+		Plugin: &latest.PluginDeploymentConfig{
+			Type:   "pulumi",
+			Config: map[string]interface{}{"stack": "dev"},
+		},
+	}
+	d, err := New(ctx, deployConfig)
+	assert.NilError(t, err)
+
+	deployed, err := d.Deploy(ctx, true)
+	assert.NilError(t, err)
+	assert.Assert(t, deployed)
+
+	deployCache, ok := conf.RemoteCache().GetDeployment("stack")
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, deployCache.Plugin, &remotecache.PluginCache{Type: "pulumi", Config: `{"stack":"dev"}`, Namespace: "infra"})
+
+	status, err := d.Status(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, status.Name, "stack")
+	assert.Equal(t, status.Type, "pulumi")
+	assert.Equal(t, status.Target, "my-stack")
+	assert.Equal(t, status.Status, "Deployed")
+
+	// purge uses the config from the cache
+	err = Delete(ctx, "stack")
+	assert.NilError(t, err)
+
+	calls, err := os.ReadFile(filepath.Join(dir, "calls"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, strings.Split(strings.TrimSpace(string(calls)), "\n"), []string{
+		`deployer deploy stack infra true {"stack":"dev"}`,
+		`deployer status stack infra false {"stack":"dev"}`,
+		`deployer purge stack infra  {"stack":"dev"}`,
+	})
+}
@@ -0,0 +1,115 @@
+package deploy
+
+import (
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/git"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/stringutil"
+	"github.com/pkg/errors"
+)
+
+// maxHistory is the number of deploys that are kept in the history of a deployment
+const maxHistory = 10
+
+// recordHistory adds the deploy to the history of the deployment in the remote cache, so that everyone using
+// the namespace can see who deployed what
+func recordHistory(ctx devspacecontext.Context, deploymentName string) {
+	deploymentCache, ok := ctx.Config().RemoteCache().GetDeployment(deploymentName)
+	if !ok {
+		return
+	}
+
+	deploymentCache.History = append(deploymentCache.History, newHistoryEntry(ctx))
+	if len(deploymentCache.History) > maxHistory {
+		deploymentCache.History = deploymentCache.History[len(deploymentCache.History)-maxHistory:]
+	}
+	ctx.Config().RemoteCache().SetDeployment(deploymentName, deploymentCache)
+}
+
+func newHistoryEntry(ctx devspacecontext.Context) remotecache.DeploymentHistory {
+	entry := remotecache.DeploymentHistory{
+		Timestamp: time.Now().Unix(),
+		User:      currentUser(),
+	}
+
+	gitCommit, err := git.GetHash(ctx.Context(), ctx.WorkingDir())
+	if err != nil {
+		ctx.Log().Debugf("Error retrieving git commit for deploy history: %v", err)
+	} else {
+		entry.GitCommit = gitCommit
+	}
+
+	imageNames := []string{}
+	for name := range ctx.Config().Config().Images {
+		imageNames = append(imageNames, name)
+	}
+	sort.Strings(imageNames)
+	for _, name := range imageNames {
+		imageCache, ok := ctx.Config().LocalCache().GetImageCache(name)
+		if !ok || imageCache.ImageName == "" || imageCache.Tag == "" {
+			continue
+		}
+
+		entry.Images = append(entry.Images, imageCache.ImageName+":"+imageCache.Tag)
+	}
+
+	if profiles, ok := ctx.Config().Variables()["DEVSPACE_PROFILES"].(string); ok && profiles != "" {
+		entry.Profiles = strings.Fields(profiles)
+	}
+
+	return entry
+}
+
+func currentUser() string {
+	u, err := user.Current()
+	if err == nil && u.Username != "" {
+		return u.Username
+	}
+
+	return os.Getenv("USER")
+}
+
+// PrintHistory prints the deploy history of the given deployments or of all deployments of the project
+func PrintHistory(ctx devspacecontext.Context, deployments []string) error {
+	if ctx.KubeClient() == nil {
+		return errors.New("a kube context is required to print the deploy history")
+	}
+
+	values := [][]string{}
+	for _, deploymentCache := range ctx.Config().RemoteCache().ListDeployments() {
+		if len(deployments) > 0 && !stringutil.Contains(deployments, deploymentCache.Name) {
+			continue
+		}
+
+		for i := len(deploymentCache.History) - 1; i >= 0; i-- {
+			entry := deploymentCache.History[i]
+			gitCommit := entry.GitCommit
+			if len(gitCommit) > 8 {
+				gitCommit = gitCommit[:8]
+			}
+
+			values = append(values, []string{
+				deploymentCache.Name,
+				time.Unix(entry.Timestamp, 0).Format(time.RFC3339),
+				entry.User,
+				gitCommit,
+				strings.Join(entry.Images, ", "),
+				strings.Join(entry.Profiles, ", "),
+			})
+		}
+	}
+	if len(values) == 0 {
+		ctx.Log().Info("No deploy history found")
+		return nil
+	}
+
+	log.PrintTable(ctx.Log(), []string{"DEPLOYMENT", "DEPLOYED", "USER", "COMMIT", "IMAGES", "PROFILES"}, values)
+	return nil
+}
@@ -0,0 +1,60 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestRecordHistory(t *testing.T) {
+	history := []remotecache.DeploymentHistory{}
+	for i := 0; i < maxHistory; i++ {
+		history = append(history, remotecache.DeploymentHistory{Timestamp: int64(i)})
+	}
+	cache := &remotecache.RemoteCache{
+		Deployments: []remotecache.DeploymentCache{
+			{Name: "app", History: history},
+		},
+	}
+
+	localCache := localcache.New(constants.DefaultCacheFolder)
+	localCache.SetImageCache("api", localcache.ImageCache{ImageName: "registry.io/api", Tag: "abc"})
+	localCache.SetImageCache("web", localcache.ImageCache{ImageName: "registry.io/web"})
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		&latest.Config{
+			Images: map[string]*latest.Image{
+				"api": {Image: "registry.io/api"},
+				"web": {Image: "registry.io/web"},
+			},
+		},
+		localCache,
+		cache,
+		map[string]interface{}{"DEVSPACE_PROFILES": "staging eu"},
+		constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.TODO(), nil, log.Discard).WithConfig(conf).WithWorkingDir(t.TempDir())
+
+	recordHistory(ctx, "app")
+	recordHistory(ctx, "missing")
+
+	deploymentCache, ok := cache.GetDeployment("app")
+	assert.Assert(t, ok)
+	assert.Equal(t, len(deploymentCache.History), maxHistory)
+	assert.Equal(t, deploymentCache.History[0].Timestamp, int64(1))
+
+	entry := deploymentCache.History[maxHistory-1]
+	assert.Assert(t, entry.Timestamp > 0)
+	assert.DeepEqual(t, entry.Images, []string{"registry.io/api:abc"})
+	assert.DeepEqual(t, entry.Profiles, []string{"staging", "eu"})
+
+	_, ok = cache.GetDeployment("missing")
+	assert.Assert(t, !ok)
+}
@@ -0,0 +1,190 @@
+package deploy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/survey"
+	"github.com/mgutz/ansi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Plan holds the changes the deployments would make to the cluster
+type Plan struct {
+	// Changes are the changes by deployment name
+	Changes map[string][]deployer.Change
+
+	// Unsupported are the deployments that can't tell which changes they would make
+	Unsupported []string
+}
+
+// Summary returns the number of objects that would be created, updated and deleted
+func (p *Plan) Summary() (creates int, updates int, deletes int) {
+	for _, changes := range p.Changes {
+		for _, change := range changes {
+			switch change.Action {
+			case deployer.ChangeActionCreate:
+				creates++
+			case deployer.ChangeActionUpdate:
+				updates++
+			case deployer.ChangeActionDelete:
+				deletes++
+			}
+		}
+	}
+
+	return creates, updates, deletes
+}
+
+// Empty returns true if the deployments wouldn't change anything
+func (p *Plan) Empty() bool {
+	creates, updates, deletes := p.Summary()
+	return creates == 0 && updates == 0 && deletes == 0
+}
+
+// Print prints the changes of all deployments and a summary
+func (p *Plan) Print(log log.Logger) {
+	names := make([]string, 0, len(p.Changes))
+	for name := range p.Changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		changes := p.Changes[name]
+		if len(changes) == 0 {
+			log.Infof("Deployment %s is up to date", ansi.Color(name, "white+b"))
+			continue
+		}
+
+		log.Infof("Deployment %s:", ansi.Color(name, "white+b"))
+		for _, change := range changes {
+			object := change.Kind + " " + change.Name
+			if change.Namespace != "" {
+				object = change.Kind + " " + change.Namespace + "/" + change.Name
+			}
+
+			switch change.Action {
+			case deployer.ChangeActionCreate:
+				log.WriteString(logrus.InfoLevel, ansi.Color("  + create "+object, "green")+"\n")
+			case deployer.ChangeActionUpdate:
+				log.WriteString(logrus.InfoLevel, ansi.Color("  ~ update "+object, "yellow")+"\n")
+				log.WriteString(logrus.InfoLevel, indent(change.Diff, "      ")+"\n")
+			case deployer.ChangeActionDelete:
+				log.WriteString(logrus.InfoLevel, ansi.Color("  - delete "+object, "red")+"\n")
+			}
+		}
+	}
+	for _, name := range p.Unsupported {
+		log.Warnf("Deployment %s doesn't support plan, its changes are not shown", name)
+	}
+
+	creates, updates, deletes := p.Summary()
+	log.Infof("Plan: %d to create, %d to update, %d to delete", creates, updates, deletes)
+}
+
+// Plan renders the deployments and compares them with the objects in the cluster
+func (c *controller) Plan(ctx devspacecontext.Context, deployments []string, options *Options) (*Plan, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	deployConfigs, err := selectDeployments(ctx.Config().Config(), deployments)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Changes: map[string][]deployer.Change{}}
+	for _, deployConfig := range deployConfigs {
+		ctx := ctx.WithLogger(ctx.Log().WithPrefix("plan:" + deployConfig.Name + " "))
+		deployClient, _, err := newDeployer(ctx, deployConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		changes, err := deployClient.Plan(ctx, options.NoPrune)
+		if err != nil {
+			if errors.Is(err, deployer.ErrPlanNotSupported) {
+				plan.Unsupported = append(plan.Unsupported, deployConfig.Name)
+				continue
+			}
+
+			return nil, errors.Wrapf(err, "plan deployment %s", deployConfig.Name)
+		}
+
+		deployer.SortChanges(changes)
+		plan.Changes[deployConfig.Name] = changes
+	}
+
+	return plan, nil
+}
+
+// confirmPlan prints the plan of the deployments and asks the user to confirm it, if required
+func (c *controller) confirmPlan(ctx devspacecontext.Context, deployments []string, options *Options) error {
+	plan, err := c.Plan(ctx, deployments, options)
+	if err != nil {
+		return err
+	}
+
+	plan.Print(ctx.Log())
+	if !options.ConfirmPlan || plan.Empty() {
+		return nil
+	}
+
+	answer, err := ctx.Log().Question(&survey.QuestionOptions{
+		Question:     "Do you want to apply these changes?",
+		DefaultValue: "No",
+		Options: []string{
+			"No",
+			"Yes",
+		},
+	})
+	if err != nil {
+		return err
+	} else if answer != "Yes" {
+		return fmt.Errorf("deploy was aborted, because the plan was not confirmed")
+	}
+
+	return nil
+}
+
+// selectDeployments returns the configs of the given deployments or of all deployments sorted by name
+func selectDeployments(config *latest.Config, deployments []string) ([]*latest.DeploymentConfig, error) {
+	deployConfigs := []*latest.DeploymentConfig{}
+	if len(deployments) == 0 {
+		for _, deployConfig := range config.Deployments {
+			deployConfigs = append(deployConfigs, deployConfig)
+		}
+		sort.Slice(deployConfigs, func(i, j int) bool {
+			return deployConfigs[i].Name < deployConfigs[j].Name
+		})
+
+		return deployConfigs, nil
+	}
+
+	for _, deployment := range deployments {
+		deployConfig, ok := config.Deployments[deployment]
+		if !ok {
+			return nil, fmt.Errorf("couldn't find deployment %v", deployment)
+		}
+
+		deployConfigs = append(deployConfigs, deployConfig)
+	}
+
+	return deployConfigs, nil
+}
+
+func indent(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = prefix + lines[i]
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,111 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/survey"
+)
+
+// markProtected stores in the remote cache if the deployment is protected, so that it stays protected even if
+// it is purged with a config that doesn't contain the deployment anymore
+func markProtected(ctx devspacecontext.Context, deploymentName string, protect bool) {
+	deploymentCache, ok := ctx.Config().RemoteCache().GetDeployment(deploymentName)
+	if !ok || deploymentCache.Protected == protect {
+		return
+	}
+
+	deploymentCache.Protected = protect
+	ctx.Config().RemoteCache().SetDeployment(deploymentName, deploymentCache)
+}
+
+// isProtected checks if the deployment was deployed as protected or is protected in the current config
+func isProtected(ctx devspacecontext.Context, deploymentCache remotecache.DeploymentCache) bool {
+	if deploymentCache.Protected {
+		return true
+	}
+
+	if ctx.Config() == nil || ctx.Config().Config() == nil {
+		return false
+	}
+	deployConfig, ok := ctx.Config().Config().Deployments[deploymentCache.Name]
+	return ok && deployConfig != nil && deployConfig.Protect
+}
+
+// purgeCandidates returns the deployments of the remote cache that should be purged in reverse deploy order.
+// Protected deployments are skipped, unless forcePurge is true
+func purgeCandidates(ctx devspacecontext.Context, deployments []string, forcePurge bool) []remotecache.DeploymentCache {
+	candidates := []remotecache.DeploymentCache{}
+	deploymentCaches := ctx.Config().RemoteCache().ListDeployments()
+	for i := len(deploymentCaches) - 1; i >= 0; i-- {
+		deploymentCache := deploymentCaches[i]
+		if deployments != nil {
+			found := false
+			for _, value := range deployments {
+				if value == deploymentCache.Name {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				continue
+			}
+		}
+
+		if !forcePurge && isProtected(ctx, deploymentCache) {
+			ctx.Log().Infof("Skip purging deployment %s as it is protected. Run with '--force-purge' to force deletion", deploymentCache.Name)
+			continue
+		}
+
+		candidates = append(candidates, deploymentCache)
+	}
+
+	return candidates
+}
+
+// confirmPurge lists the deployments that will be deleted and asks for confirmation. The confirmation is
+// asked if it was requested or if protected deployments would be deleted
+func confirmPurge(ctx devspacecontext.Context, deploymentCaches []remotecache.DeploymentCache, options *PurgeOptions) error {
+	protected := false
+	lines := []string{}
+	for _, deploymentCache := range deploymentCaches {
+		if isProtected(ctx, deploymentCache) {
+			protected = true
+			lines = append(lines, "  - "+deploymentCache.Name+" (protected)")
+		} else {
+			lines = append(lines, "  - "+deploymentCache.Name)
+		}
+	}
+	if len(lines) == 0 || (!options.Confirm && !protected) {
+		return nil
+	}
+
+	ctx.Log().Infof("The following deployments will be deleted:\n%s", strings.Join(lines, "\n"))
+	if options.Yes {
+		return nil
+	}
+
+	answer, err := ctx.Log().Question(&survey.QuestionOptions{
+		Question:     "Do you want to delete these deployments?",
+		DefaultValue: "No",
+		Options: []string{
+			"No",
+			"Yes",
+		},
+	})
+	if err != nil {
+		return err
+	} else if answer != "Yes" {
+		return fmt.Errorf("purge was aborted, because the deletion was not confirmed. Run with '--yes' to skip the confirmation")
+	}
+
+	return nil
+}
+
+// inUseByOtherProjects checks if other DevSpace projects have deployed the deployment as well
+func inUseByOtherProjects(deploymentCache remotecache.DeploymentCache, rootName string) bool {
+	return len(deploymentCache.Projects) > 0 && (len(deploymentCache.Projects) > 1 || deploymentCache.Projects[0] != rootName)
+}
@@ -0,0 +1,109 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	fakelogger "github.com/loft-sh/devspace/pkg/util/log/testing"
+	"gotest.tools/assert"
+)
+
+type purgeCandidatesTestCase struct {
+	name string
+
+	deployments []string
+	forcePurge  bool
+	answer      string
+	confirm     bool
+	yes         bool
+
+	expectedCandidates []string
+	expectedErr        string
+}
+
+func TestPurgeCandidates(t *testing.T) {
+	testCases := []purgeCandidatesTestCase{
+		{
+			name:               "Skip protected deployments",
+			expectedCandidates: []string{"app"},
+		},
+		{
+			name:               "Skip protected selected deployments",
+			deployments:        []string{"app", "database"},
+			expectedCandidates: []string{"app"},
+		},
+		{
+			name:               "Force purge asks before deleting protected deployments",
+			forcePurge:         true,
+			answer:             "Yes",
+			expectedCandidates: []string{"app", "database", "ingress"},
+		},
+		{
+			name:               "Force purge aborts if not confirmed",
+			forcePurge:         true,
+			answer:             "No",
+			expectedCandidates: []string{"app", "database", "ingress"},
+			expectedErr:        "purge was aborted, because the deletion was not confirmed. Run with '--yes' to skip the confirmation",
+		},
+		{
+			name:               "Force purge without confirmation",
+			forcePurge:         true,
+			yes:                true,
+			answer:             "No",
+			expectedCandidates: []string{"app", "database", "ingress"},
+		},
+		{
+			name:               "Confirm unprotected deployments",
+			deployments:        []string{"app"},
+			confirm:            true,
+			answer:             "No",
+			expectedCandidates: []string{"app"},
+			expectedErr:        "purge was aborted, because the deletion was not confirmed. Run with '--yes' to skip the confirmation",
+		},
+	}
+
+	for _, testCase := range testCases {
+		cache := &remotecache.RemoteCache{
+			Deployments: []remotecache.DeploymentCache{
+				{Name: "ingress", Protected: true},
+				{Name: "database"},
+				{Name: "app"},
+			},
+		}
+		conf := config.NewConfig(map[string]interface{}{},
+			map[string]interface{}{},
+			&latest.Config{
+				Deployments: map[string]*latest.DeploymentConfig{
+					"database": {Name: "database", Protect: true},
+					"app":      {Name: "app"},
+				},
+			},
+			localcache.New(constants.DefaultCacheFolder),
+			cache,
+			map[string]interface{}{},
+			constants.DefaultConfigPath)
+		logger := fakelogger.NewFakeLogger()
+		logger.SetAnswer(testCase.answer)
+		ctx := devspacecontext.NewContext(context.TODO(), nil, logger).WithConfig(conf)
+
+		candidates := purgeCandidates(ctx, testCase.deployments, testCase.forcePurge)
+		names := []string{}
+		for _, candidate := range candidates {
+			names = append(names, candidate.Name)
+		}
+		assert.DeepEqual(t, names, testCase.expectedCandidates)
+
+		err := confirmPurge(ctx, candidates, &PurgeOptions{ForcePurge: testCase.forcePurge, Confirm: testCase.confirm, Yes: testCase.yes})
+		if testCase.expectedErr == "" {
+			assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		} else {
+			assert.Error(t, err, testCase.expectedErr, "Wrong or no error in testCase %s", testCase.name)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package deploy
+
+import (
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// deployedObjects returns the objects the deployment has applied during its last deploy
+func deployedObjects(ctx devspacecontext.Context, deploymentName string) []remotecache.KubectlObject {
+	deployCache, ok := ctx.Config().RemoteCache().GetDeployment(deploymentName)
+	if !ok || deployCache.Kubectl == nil {
+		return nil
+	}
+
+	return deployCache.Kubectl.Objects
+}
+
+// pruneObjects deletes the objects that were applied by the previous deploy of the deployment, but are not
+// part of its rendered manifests anymore, e.g. because a manifest was renamed. Objects that another deployment
+// has applied in the meantime are kept
+func pruneObjects(ctx devspacecontext.Context, deploymentName string, previous []remotecache.KubectlObject) error {
+	current := deployedObjects(ctx, deploymentName)
+	for _, deployCache := range ctx.Config().RemoteCache().ListDeployments() {
+		if deployCache.Name != deploymentName && deployCache.Kubectl != nil {
+			current = append(current, deployCache.Kubectl.Objects...)
+		}
+	}
+
+	for _, obj := range removedObjects(previous, current) {
+		ctx.Log().Infof("Pruning %s %s/%s", obj.Kind, obj.Namespace, obj.Name)
+		_, err := ctx.KubeClient().GenericRequest(ctx.Context(), &kubectl.GenericRequestOptions{
+			Kind:       obj.Kind,
+			APIVersion: obj.APIVersion,
+			Name:       obj.Name,
+			Namespace:  obj.Namespace,
+			Method:     "delete",
+		})
+		// an object whose kind or api version isn't served anymore is gone as well
+		if err != nil && !kerrors.IsNotFound(errors.Cause(err)) && !meta.IsNoMatchError(errors.Cause(err)) {
+			return errors.Wrapf(err, "prune %s %s", obj.Kind, obj.Name)
+		}
+	}
+
+	return nil
+}
+
+// removedObjects returns the previous objects that are not part of the current objects. The version of the
+// objects is ignored, because the same object can be applied with another api version
+func removedObjects(previous, current []remotecache.KubectlObject) []remotecache.KubectlObject {
+	currentObjects := map[string]bool{}
+	for _, obj := range current {
+		currentObjects[objectKey(obj)] = true
+	}
+
+	removed := []remotecache.KubectlObject{}
+	for _, obj := range previous {
+		if !currentObjects[objectKey(obj)] {
+			removed = append(removed, obj)
+		}
+	}
+
+	return removed
+}
+
+func objectKey(obj remotecache.KubectlObject) string {
+	group := ""
+	if i := strings.LastIndex(obj.APIVersion, "/"); i != -1 {
+		group = obj.APIVersion[:i]
+	}
+
+	return group + "/" + obj.Kind + "/" + obj.Namespace + "/" + obj.Name
+}
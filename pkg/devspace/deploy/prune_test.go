@@ -0,0 +1,134 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type removedObjectsTestCase struct {
+	name string
+
+	previous []remotecache.KubectlObject
+	current  []remotecache.KubectlObject
+
+	expected []remotecache.KubectlObject
+}
+
+func TestRemovedObjects(t *testing.T) {
+	testCases := []removedObjectsTestCase{
+		{
+			name: "Nothing removed",
+			previous: []remotecache.KubectlObject{
+				{APIVersion: "v1", Kind: "Service", Name: "app", Namespace: "default"},
+			},
+			current: []remotecache.KubectlObject{
+				{APIVersion: "v1", Kind: "Service", Name: "app", Namespace: "default"},
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "app", Namespace: "default"},
+			},
+			expected: []remotecache.KubectlObject{},
+		},
+		{
+			name: "Renamed object",
+			previous: []remotecache.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "old", Namespace: "default"},
+			},
+			current: []remotecache.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "new", Namespace: "default"},
+			},
+			expected: []remotecache.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "old", Namespace: "default"},
+			},
+		},
+		{
+			name: "Changed namespace",
+			previous: []remotecache.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "app", Namespace: "old"},
+			},
+			current: []remotecache.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "app", Namespace: "new"},
+			},
+			expected: []remotecache.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "app", Namespace: "old"},
+			},
+		},
+		{
+			name: "Changed api version",
+			previous: []remotecache.KubectlObject{
+				{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", Name: "app", Namespace: "default"},
+			},
+			current: []remotecache.KubectlObject{
+				{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "app", Namespace: "default"},
+			},
+			expected: []remotecache.KubectlObject{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		removed := removedObjects(testCase.previous, testCase.current)
+		assert.DeepEqual(t, removed, testCase.expected)
+	}
+}
+
+// deleteClient records the deleted objects and fails for kinds that aren't served anymore
+type deleteClient struct {
+	*fakekube.Client
+
+	deleted []string
+}
+
+func (c *deleteClient) GenericRequest(ctx context.Context, options *kubectl.GenericRequestOptions) (string, error) {
+	if options.Kind == "Gone" {
+		return "", errors.Wrap(&meta.NoKindMatchError{GroupKind: schema.GroupKind{Kind: options.Kind}}, "delete")
+	}
+
+	c.deleted = append(c.deleted, options.Kind+"/"+options.Name)
+	return "", nil
+}
+
+func TestPruneObjects(t *testing.T) {
+	remoteCache := &remotecache.RemoteCache{}
+	remoteCache.SetDeployment("first", remotecache.DeploymentCache{
+		Name: "first",
+		Kubectl: &remotecache.KubectlCache{
+			Objects: []remotecache.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "kept", Namespace: "default"},
+			},
+		},
+	})
+	remoteCache.SetDeployment("second", remotecache.DeploymentCache{
+		Name: "second",
+		Kubectl: &remotecache.KubectlCache{
+			Objects: []remotecache.KubectlObject{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "moved", Namespace: "default"},
+			},
+		},
+	})
+	conf := config.NewConfig(nil, nil, latest.NewRaw(), localcache.New(constants.DefaultCacheFolder), remoteCache, nil, constants.DefaultConfigPath)
+	kubeClient := &deleteClient{Client: &fakekube.Client{}}
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithKubeClient(kubeClient).WithConfig(conf)
+
+	err := pruneObjects(ctx, "first", []remotecache.KubectlObject{
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "kept", Namespace: "default"},
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "moved", Namespace: "default"},
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "removed", Namespace: "default"},
+		{APIVersion: "example.com/v1", Kind: "Gone", Name: "removed", Namespace: "default"},
+	})
+	assert.NilError(t, err)
+
+	// objects of other deployments and kinds that aren't served anymore are not deleted
+	assert.DeepEqual(t, kubeClient.deleted, []string{"ConfigMap/removed"})
+}
@@ -0,0 +1,206 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ghodss/yaml"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kustomize"
+	"github.com/loft-sh/devspace/pkg/util/stringutil"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// renderIndexFile is the kustomization that lists all rendered files of the render dir
+const renderIndexFile = "kustomization.yaml"
+
+// renderManifestFile records the files DevSpace has rendered into the render dir and the run that rendered them
+const renderManifestFile = ".devspace-rendered.yaml"
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-z0-9.\-]+`)
+
+var renderDirMutex sync.Mutex
+
+// renderManifest is the content of the render manifest file
+type renderManifest struct {
+	Run   string   `json:"run"`
+	Files []string `json:"files"`
+
+	// Owners are the names of the configs that rendered the files of the run
+	Owners map[string]string `json:"owners,omitempty"`
+}
+
+// writeRenderDir writes every rendered object into its own file <kind>_<namespace>_<name>.yaml in the dir and
+// updates the kustomization.yaml that references all rendered files of the dir. Files that were rendered by a
+// previous run are removed first, files of the current run are kept, because dependencies render into the same
+// dir. If a dependency already rendered an object with the same file name, the config name is added to the file
+// name, so that no object of the run overwrites another one
+func writeRenderDir(ctx devspacecontext.Context, dir, manifests string) error {
+	objects, err := deployer.ParseObjects(manifests)
+	if err != nil {
+		return errors.Wrap(err, "parse rendered manifests")
+	}
+
+	namespace := ""
+	if ctx.KubeClient() != nil {
+		namespace = ctx.KubeClient().Namespace()
+	}
+	files, err := renderFiles(objects, namespace)
+	if err != nil {
+		return err
+	}
+
+	owner := ""
+	if ctx.Config() != nil && ctx.Config().Config() != nil {
+		owner = ctx.Config().Config().Name
+	}
+
+	renderDirMutex.Lock()
+	defer renderDirMutex.Unlock()
+
+	dir = ctx.ResolvePath(dir)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := readRenderManifest(dir)
+	if err != nil {
+		return err
+	}
+	if manifest.Run != ctx.RunID() {
+		for _, fileName := range manifest.Files {
+			err = os.Remove(filepath.Join(dir, filepath.Base(fileName)))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		manifest = &renderManifest{Run: ctx.RunID()}
+	}
+	if manifest.Owners == nil {
+		manifest.Owners = map[string]string{}
+	}
+
+	renamed := map[string]*unstructured.Unstructured{}
+	for fileName, obj := range files {
+		if previousOwner, ok := manifest.Owners[fileName]; ok && previousOwner != owner {
+			fileName = renderFileName(owner, strings.TrimSuffix(fileName, ".yaml"))
+			if previousOwner, ok := manifest.Owners[fileName]; ok && previousOwner != owner {
+				return errors.Errorf("%s %s was already rendered into %s by %s", obj.GetKind(), obj.GetName(), fileName, previousOwner)
+			}
+		}
+		if _, ok := renamed[fileName]; ok {
+			return errors.Errorf("%s %s would overwrite another rendered object in %s", obj.GetKind(), obj.GetName(), fileName)
+		}
+
+		renamed[fileName] = obj
+	}
+
+	for fileName, obj := range renamed {
+		out, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return errors.Wrapf(err, "marshal %s %s", obj.GetKind(), obj.GetName())
+		}
+
+		err = os.WriteFile(filepath.Join(dir, fileName), out, 0644)
+		if err != nil {
+			return err
+		}
+
+		if !stringutil.Contains(manifest.Files, fileName) {
+			manifest.Files = append(manifest.Files, fileName)
+		}
+		manifest.Owners[fileName] = owner
+	}
+	sort.Strings(manifest.Files)
+
+	out, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, renderManifestFile), out, 0644)
+	if err != nil {
+		return err
+	}
+
+	// the index includes the files that were rendered by this run into the same dir, e.g. by dependencies
+	out, err = yaml.Marshal(&kustomize.Kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  manifest.Files,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, renderIndexFile), out, 0644)
+	if err != nil {
+		return err
+	}
+
+	ctx.Log().Donef("Rendered %d object(s) into %s", len(objects), dir)
+	return nil
+}
+
+func readRenderManifest(dir string) (*renderManifest, error) {
+	manifest := &renderManifest{}
+	out, err := os.ReadFile(filepath.Join(dir, renderManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(out, manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %s", renderManifestFile)
+	}
+
+	return manifest, nil
+}
+
+// renderFiles returns the file names of the objects. Objects without a namespace are rendered into the given
+// default namespace
+func renderFiles(objects []*unstructured.Unstructured, namespace string) (map[string]*unstructured.Unstructured, error) {
+	files := map[string]*unstructured.Unstructured{}
+	for _, obj := range objects {
+		objNamespace := obj.GetNamespace()
+		if objNamespace == "" {
+			objNamespace = namespace
+		}
+
+		fileName := renderFileName(obj.GetKind(), objNamespace, obj.GetName())
+		if _, ok := files[fileName]; ok {
+			return nil, errors.Errorf("%s %s is rendered twice", obj.GetKind(), obj.GetName())
+		}
+
+		files[fileName] = obj
+	}
+
+	return files, nil
+}
+
+// renderFileName joins the non-empty parts to a file name that is safe on every file system
+func renderFileName(parts ...string) string {
+	safeParts := []string{}
+	for _, part := range parts {
+		for _, subPart := range strings.Split(strings.ToLower(part), "_") {
+			subPart = strings.Trim(unsafeFileNameChars.ReplaceAllString(subPart, "-"), "-")
+			if subPart != "" {
+				safeParts = append(safeParts, subPart)
+			}
+		}
+	}
+
+	return strings.Join(safeParts, "_") + ".yaml"
+}
@@ -0,0 +1,199 @@
+package deploy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+type writeRenderDirTestCase struct {
+	name string
+
+	manifests string
+
+	expectedFiles []string
+	expectedErr   string
+}
+
+func TestWriteRenderDir(t *testing.T) {
+	testCases := []writeRenderDirTestCase{
+		{
+			name: "One file per object",
+			manifests: `apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`,
+			expectedFiles: []string{renderManifestFile, "deployment_app.yaml", "kustomization.yaml", "service_app.yaml"},
+		},
+		{
+			name: "Same name in different namespaces",
+			manifests: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: b
+`,
+			expectedFiles: []string{renderManifestFile, "configmap_a_app.yaml", "configmap_b_app.yaml", "kustomization.yaml"},
+		},
+		{
+			name: "Unsafe names",
+			manifests: `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: system:app
+`,
+			expectedFiles: []string{renderManifestFile, "clusterrole_system-app.yaml", "kustomization.yaml"},
+		},
+		{
+			name: "Same object twice",
+			manifests: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+`,
+			expectedErr: "ConfigMap app is rendered twice",
+		},
+	}
+
+	for _, testCase := range testCases {
+		dir := t.TempDir()
+		ctx := devspacecontext.NewContext(context.TODO(), nil, log.Discard).WithWorkingDir(dir)
+
+		err := writeRenderDir(ctx, "rendered", testCase.manifests)
+		if testCase.expectedErr != "" {
+			assert.Error(t, err, testCase.expectedErr, "Wrong error in testCase %s", testCase.name)
+			continue
+		}
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+
+		entries, err := os.ReadDir(filepath.Join(dir, "rendered"))
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		files := []string{}
+		for _, entry := range entries {
+			files = append(files, entry.Name())
+		}
+		sort.Strings(files)
+		assert.DeepEqual(t, files, testCase.expectedFiles)
+
+		index, err := os.ReadFile(filepath.Join(dir, "rendered", renderIndexFile))
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+		for _, file := range testCase.expectedFiles {
+			if file != renderIndexFile && file != renderManifestFile {
+				assert.Assert(t, strings.Contains(string(index), "- "+file), "Missing %s in index of testCase %s", file, testCase.name)
+			}
+		}
+	}
+}
+
+func TestWriteRenderDirRuns(t *testing.T) {
+	dir := t.TempDir()
+	newRun := func() devspacecontext.Context {
+		return devspacecontext.NewContext(context.TODO(), nil, log.Discard).WithWorkingDir(dir)
+	}
+
+	readDir := func() []string {
+		entries, err := os.ReadDir(dir)
+		assert.NilError(t, err)
+		files := []string{}
+		for _, entry := range entries {
+			files = append(files, entry.Name())
+		}
+		sort.Strings(files)
+		return files
+	}
+
+	// files that weren't rendered by devspace are kept
+	err := os.WriteFile(filepath.Join(dir, "custom.yaml"), []byte("custom"), 0644)
+	assert.NilError(t, err)
+
+	err = writeRenderDir(newRun(), ".", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: old\n")
+	assert.NilError(t, err)
+
+	// the files of the same run are kept, e.g. of a dependency
+	ctx := newRun()
+	err = writeRenderDir(ctx, ".", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: dependency\n")
+	assert.NilError(t, err)
+	err = writeRenderDir(ctx, ".", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: new\n")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readDir(), []string{renderManifestFile, "configmap_dependency.yaml", "configmap_new.yaml", "custom.yaml", renderIndexFile})
+
+	index, err := os.ReadFile(filepath.Join(dir, renderIndexFile))
+	assert.NilError(t, err)
+	assert.Equal(t, string(index), `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- configmap_dependency.yaml
+- configmap_new.yaml
+`)
+
+	// the next run removes the files of the previous run
+	err = writeRenderDir(newRun(), ".", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: new\n")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readDir(), []string{renderManifestFile, "configmap_new.yaml", "custom.yaml", renderIndexFile})
+}
+
+func TestWriteRenderDirDependencies(t *testing.T) {
+	dir := t.TempDir()
+	newContext := func(name string) devspacecontext.Context {
+		conf := config.NewConfig(map[string]interface{}{},
+			map[string]interface{}{},
+			&latest.Config{Name: name},
+			localcache.New(constants.DefaultCacheFolder),
+			&remotecache.RemoteCache{},
+			map[string]interface{}{},
+			constants.DefaultConfigPath)
+		return devspacecontext.NewContext(context.TODO(), nil, log.Discard).WithWorkingDir(dir).WithConfig(conf).WithKubeClient(&fakekube.Client{})
+	}
+
+	// objects without a namespace are rendered into the namespace of the kube client
+	ctx := newContext("app")
+	err := writeRenderDir(ctx, ".", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n")
+	assert.NilError(t, err)
+
+	// a dependency that renders the same object doesn't overwrite it
+	dependencyCtx := ctx.WithConfig(newContext("dependency").Config())
+	err = writeRenderDir(dependencyCtx, ".", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n")
+	assert.NilError(t, err)
+
+	// rendering the same deployments again overwrites their files
+	err = writeRenderDir(ctx, ".", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n")
+	assert.NilError(t, err)
+
+	manifest, err := readRenderManifest(dir)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, manifest.Files, []string{"configmap_testnamespace_config.yaml", "dependency_configmap_testnamespace_config.yaml"})
+	assert.DeepEqual(t, manifest.Owners, map[string]string{
+		"configmap_testnamespace_config.yaml":            "app",
+		"dependency_configmap_testnamespace_config.yaml": "dependency",
+	})
+}
@@ -0,0 +1,105 @@
+package deploy
+
+import (
+	"fmt"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/helm"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/hook"
+	"github.com/loft-sh/devspace/pkg/util/stringutil"
+	"github.com/pkg/errors"
+)
+
+// Rollback reverts all deployments or a set of deployments to the revision before their last deploy. Helm releases
+// are rolled back to their previous revision, kubectl deployments apply their previous manifests again
+func (c *controller) Rollback(ctx devspacecontext.Context, deployments []string) error {
+	// Deployments to other kube contexts are rolled back with the remote caches of their clusters
+	targets, err := targetContexts(ctx, deployments)
+	if err != nil {
+		return err
+	}
+
+	for _, deployment := range deployments {
+		found := false
+		for _, target := range targets {
+			if _, ok := target.Config().RemoteCache().GetDeployment(deployment); ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("couldn't find deployment %s in the cache, was it deployed?", deployment)
+		}
+	}
+
+	for _, target := range targets {
+		err = c.rollbackTarget(target, deployments)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollbackTarget rolls back the deployments of the remote cache of the context and saves it afterwards
+func (c *controller) rollbackTarget(ctx devspacecontext.Context, deployments []string) error {
+	// Reverse them
+	deploymentCaches := ctx.Config().RemoteCache().ListDeployments()
+	for i := len(deploymentCaches) - 1; i >= 0; i-- {
+		deploymentCache := deploymentCaches[i]
+		if len(deployments) > 0 && !stringutil.Contains(deployments, deploymentCache.Name) {
+			continue
+		}
+		ctx := ctx.WithLogger(ctx.Log().WithPrefix("rollback:" + deploymentCache.Name + " "))
+
+		// Execute before deployment rollback hook
+		err := hook.ExecuteHooks(ctx, map[string]interface{}{
+			"DEPLOY_NAME":   deploymentCache.Name,
+			"DEPLOY_CONFIG": deploymentCache,
+		}, hook.EventsForSingle("before:rollback", deploymentCache.Name)...)
+		if err != nil {
+			return err
+		}
+
+		ctx.Log().Info("Rolling back deployment " + deploymentCache.Name + "...")
+		if deploymentCache.Kubectl != nil {
+			previousObjects := deploymentCache.Kubectl.Objects
+			err = kubectl.Rollback(ctx, deploymentCache.Name)
+			if err == nil {
+				err = pruneObjects(ctx, deploymentCache.Name, previousObjects)
+			}
+		} else if deploymentCache.Helm != nil {
+			err = helm.Rollback(ctx, deploymentCache.Name)
+		} else {
+			err = fmt.Errorf("deployment %s doesn't support rollback", deploymentCache.Name)
+		}
+		if err != nil {
+			// Execute on error deployment rollback hook
+			hookErr := hook.ExecuteHooks(ctx, map[string]interface{}{
+				"DEPLOY_NAME":   deploymentCache.Name,
+				"DEPLOY_CONFIG": deploymentCache,
+				"ERROR":         err,
+			}, hook.EventsForSingle("error:rollback", deploymentCache.Name)...)
+			if hookErr != nil {
+				return hookErr
+			}
+
+			return errors.Wrapf(err, "roll back deployment %s", deploymentCache.Name)
+		}
+
+		// Execute after deployment rollback hook
+		err = hook.ExecuteHooks(ctx, map[string]interface{}{
+			"DEPLOY_NAME":   deploymentCache.Name,
+			"DEPLOY_CONFIG": deploymentCache,
+		}, hook.EventsForSingle("after:rollback", deploymentCache.Name)...)
+		if err != nil {
+			return err
+		}
+
+		ctx.Log().Donef("Successfully rolled back deployment %s", deploymentCache.Name)
+	}
+
+	return ctx.Config().RemoteCache().Save(ctx.Context(), ctx.KubeClient())
+}
@@ -0,0 +1,92 @@
+package deploy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func encodedManifest(t *testing.T, manifest string) string {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	_, err := writer.Write([]byte(manifest))
+	assert.NilError(t, err)
+	assert.NilError(t, writer.Close())
+	return base64.StdEncoding.EncodeToString(buffer.Bytes())
+}
+
+func TestRollback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake kubectl is a shell script")
+	}
+
+	dir := t.TempDir()
+	kubectlPath := filepath.Join(dir, "kubectl")
+	err := os.WriteFile(kubectlPath, []byte("#!/bin/sh\ncat > "+filepath.Join(dir, "manifest")+"\n"), 0755)
+	assert.NilError(t, err)
+
+	previousObjects := []remotecache.KubectlObject{{APIVersion: "v1", Kind: "ConfigMap", Name: "previous", Namespace: "default"}}
+	currentObjects := []remotecache.KubectlObject{{APIVersion: "v1", Kind: "ConfigMap", Name: "current", Namespace: "default"}}
+	remoteCache := remotecache.NewCache("", "test")
+	remoteCache.SetDeployment("first", remotecache.DeploymentCache{
+		Name: "first",
+		Kubectl: &remotecache.KubectlCache{
+			Objects:  currentObjects,
+			Manifest: encodedManifest(t, "current manifest"),
+			Previous: &remotecache.KubectlRevision{
+				Objects:  previousObjects,
+				Manifest: encodedManifest(t, "previous manifest"),
+			},
+		},
+	})
+	remoteCache.SetDeployment("second", remotecache.DeploymentCache{
+		Name: "second",
+	})
+
+	rawConfig := latest.NewRaw()
+	rawConfig.Deployments = map[string]*latest.DeploymentConfig{
+		"first": {
+			Name:    "first",
+			Kubectl: &latest.KubectlConfig{KubectlBinaryPath: kubectlPath},
+		},
+	}
+	conf := config.NewConfig(nil, nil, rawConfig, localcache.New(constants.DefaultCacheFolder), remoteCache, nil, constants.DefaultConfigPath)
+	kubeClient := &deleteClient{Client: &fakekube.Client{Client: fake.NewSimpleClientset()}}
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf).WithKubeClient(kubeClient).WithWorkingDir(dir)
+
+	// unknown deployments can't be rolled back
+	err = NewController().Rollback(ctx, []string{"unknown"})
+	assert.Error(t, err, "couldn't find deployment unknown in the cache, was it deployed?")
+
+	// deployments without revisions can't be rolled back
+	err = NewController().Rollback(ctx, []string{"second"})
+	assert.Error(t, err, "roll back deployment second: deployment second doesn't support rollback")
+
+	// the previous manifest is applied again and the objects of the current revision are pruned
+	err = NewController().Rollback(ctx, []string{"first"})
+	assert.NilError(t, err)
+	manifest, err := os.ReadFile(filepath.Join(dir, "manifest"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(manifest), "previous manifest")
+	assert.DeepEqual(t, kubeClient.deleted, []string{"ConfigMap/current"})
+
+	deploymentCache, _ := remoteCache.GetDeployment("first")
+	assert.DeepEqual(t, deploymentCache.Kubectl.Objects, previousObjects)
+	assert.DeepEqual(t, deploymentCache.Kubectl.Previous.Objects, currentObjects)
+}
@@ -0,0 +1,148 @@
+package deploy
+
+import (
+	"bytes"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/graph"
+)
+
+// deployResult is sent when a deployment is finished
+type deployResult struct {
+	deployConfig *latest.DeploymentConfig
+	err          error
+}
+
+// deploySchedule holds the deployments that wait for the deployments they need
+type deploySchedule struct {
+	// remaining is the number of needed deployments a deployment still waits for
+	remaining map[string]int
+	// waiting are the deployments that need a deployment
+	waiting map[string][]*latest.DeploymentConfig
+	// position keeps the order of the deployments for deployments that are ready at the same time
+	position map[string]int
+
+	ready []*latest.DeploymentConfig
+}
+
+// newDeploySchedule creates a schedule for the deployments. Needed deployments that are not deployed are ignored,
+// so that a single deployment can still be deployed on its own
+func newDeploySchedule(deployConfigs []*latest.DeploymentConfig) (*deploySchedule, error) {
+	schedule := &deploySchedule{
+		remaining: map[string]int{},
+		waiting:   map[string][]*latest.DeploymentConfig{},
+		position:  map[string]int{},
+	}
+
+	g := graph.NewGraphOf(graph.NewNode("root", nil), "deployment")
+	for i, deployConfig := range deployConfigs {
+		schedule.position[deployConfig.Name] = i
+		_, err := g.InsertNodeAt("root", deployConfig.Name, deployConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, deployConfig := range deployConfigs {
+		for _, need := range deployConfig.Needs {
+			if _, ok := schedule.position[need]; !ok || need == deployConfig.Name {
+				continue
+			}
+
+			err := g.AddEdge(deployConfig.Name, need)
+			if err != nil {
+				return nil, err
+			}
+
+			schedule.remaining[deployConfig.Name]++
+			schedule.waiting[need] = append(schedule.waiting[need], deployConfig)
+		}
+	}
+
+	for _, deployConfig := range deployConfigs {
+		if schedule.remaining[deployConfig.Name] == 0 {
+			schedule.ready = append(schedule.ready, deployConfig)
+		}
+	}
+
+	return schedule, nil
+}
+
+// pop returns the next deployment that is ready to be deployed
+func (s *deploySchedule) pop() *latest.DeploymentConfig {
+	if len(s.ready) == 0 {
+		return nil
+	}
+
+	next := 0
+	for i, deployConfig := range s.ready {
+		if s.position[deployConfig.Name] < s.position[s.ready[next].Name] {
+			next = i
+		}
+	}
+
+	deployConfig := s.ready[next]
+	s.ready = append(s.ready[:next], s.ready[next+1:]...)
+	return deployConfig
+}
+
+// done marks the deployment as deployed and makes the deployments that only waited for it ready
+func (s *deploySchedule) done(deployConfig *latest.DeploymentConfig) {
+	for _, waiting := range s.waiting[deployConfig.Name] {
+		s.remaining[waiting.Name]--
+		if s.remaining[waiting.Name] == 0 {
+			s.ready = append(s.ready, waiting)
+		}
+	}
+}
+
+// deployScheduled deploys every deployment after the deployments it needs. If concurrent is true, all
+// deployments that are ready are deployed in parallel. After a deployment failed, no new deployments are
+// started and the running ones are awaited
+func (c *controller) deployScheduled(ctx devspacecontext.Context, deployConfigs []*latest.DeploymentConfig, options *Options, concurrent bool, renderBuffer *bytes.Buffer) error {
+	schedule, err := newDeploySchedule(deployConfigs)
+	if err != nil {
+		return err
+	}
+
+	var (
+		results  = make(chan deployResult)
+		running  = 0
+		firstErr error
+	)
+	for {
+		for firstErr == nil && (concurrent || running == 0) {
+			deployConfig := schedule.pop()
+			if deployConfig == nil {
+				break
+			}
+
+			running++
+			go func(deployConfig *latest.DeploymentConfig) {
+				_, err := c.deployOne(ctx.WithLogger(ctx.Log().WithPrefix("deploy:"+deployConfig.Name+" ")), deployConfig, options)
+				results <- deployResult{deployConfig: deployConfig, err: err}
+			}(deployConfig)
+		}
+		if running == 0 {
+			break
+		}
+
+		ctx.Log().Debugf("Waiting for %d deployment(s) to finish...", running)
+		result := <-results
+		running--
+		if result.err != nil {
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			continue
+		}
+		if renderBuffer != nil {
+			renderBuffer.WriteString("\n---\n")
+		}
+
+		schedule.done(result.deployConfig)
+	}
+
+	return firstErr
+}
@@ -0,0 +1,71 @@
+package deploy
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"gotest.tools/assert"
+)
+
+type deployScheduleTestCase struct {
+	name string
+
+	deployConfigs []*latest.DeploymentConfig
+
+	expectedOrder []string
+	expectedErr   string
+}
+
+func TestDeploySchedule(t *testing.T) {
+	testCases := []deployScheduleTestCase{
+		{
+			name: "Keeps the order without needs",
+			deployConfigs: []*latest.DeploymentConfig{
+				{Name: "b"},
+				{Name: "a"},
+			},
+			expectedOrder: []string{"b", "a"},
+		},
+		{
+			name: "Needed deployments first",
+			deployConfigs: []*latest.DeploymentConfig{
+				{Name: "backend", Needs: []string{"database", "cache"}},
+				{Name: "cache"},
+				{Name: "database"},
+				{Name: "frontend", Needs: []string{"backend"}},
+			},
+			expectedOrder: []string{"cache", "database", "backend", "frontend"},
+		},
+		{
+			name: "Ignores deployments that are not deployed",
+			deployConfigs: []*latest.DeploymentConfig{
+				{Name: "backend", Needs: []string{"database"}},
+			},
+			expectedOrder: []string{"backend"},
+		},
+		{
+			name: "Cycle",
+			deployConfigs: []*latest.DeploymentConfig{
+				{Name: "a", Needs: []string{"b"}},
+				{Name: "b", Needs: []string{"a"}},
+			},
+			expectedErr: "Cyclic deployment found",
+		},
+	}
+
+	for _, testCase := range testCases {
+		schedule, err := newDeploySchedule(testCase.deployConfigs)
+		if testCase.expectedErr != "" {
+			assert.ErrorContains(t, err, testCase.expectedErr, "Unexpected error in testCase %s", testCase.name)
+			continue
+		}
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+
+		order := []string{}
+		for deployConfig := schedule.pop(); deployConfig != nil; deployConfig = schedule.pop() {
+			order = append(order, deployConfig.Name)
+			schedule.done(deployConfig)
+		}
+		assert.DeepEqual(t, order, testCase.expectedOrder)
+	}
+}
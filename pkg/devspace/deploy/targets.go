@@ -0,0 +1,152 @@
+package deploy
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/util/kubeconfig"
+	"github.com/loft-sh/devspace/pkg/util/stringutil"
+	"github.com/mgutz/ansi"
+	"github.com/pkg/errors"
+)
+
+// KubeContexts returns the kube contexts the deployment is deployed to or nil if it is deployed with the
+// current kube client
+func KubeContexts(deployConfig *latest.DeploymentConfig) []string {
+	if deployConfig.KubeContext != "" {
+		return []string{deployConfig.KubeContext}
+	}
+
+	return deployConfig.KubeContexts
+}
+
+// deployTargets deploys the deployment to every kube context. A failing kube context doesn't stop the
+// others, the status of each kube context is reported after all of them were deployed
+func (c *controller) deployTargets(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig, options *Options, targets []string) (bool, error) {
+	wasDeployed := false
+	failed := []string{}
+	results := map[string]error{}
+	for _, kubeContext := range targets {
+		deployed, err := c.deployToKubeContext(ctx, deployConfig, options, kubeContext)
+		if deployed {
+			wasDeployed = true
+		}
+		if err != nil {
+			failed = append(failed, kubeContext+": "+err.Error())
+		}
+
+		results[kubeContext] = err
+	}
+
+	if len(targets) > 1 {
+		for _, kubeContext := range targets {
+			if results[kubeContext] != nil {
+				ctx.Log().Errorf("Deployment %s failed in kube context %s", deployConfig.Name, ansi.Color(kubeContext, "white+b"))
+			} else {
+				ctx.Log().Donef("Deployment %s succeeded in kube context %s", deployConfig.Name, ansi.Color(kubeContext, "white+b"))
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return wasDeployed, errors.Errorf("deployment %s failed in %d of %d kube context(s):\n%s", deployConfig.Name, len(failed), len(targets), strings.Join(failed, "\n"))
+	}
+
+	return wasDeployed, nil
+}
+
+// deployToKubeContext deploys the deployment with a kube client for the kube context. Every cluster has its own
+// remote cache, which is loaded before and saved after deploying
+func (c *controller) deployToKubeContext(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig, options *Options, kubeContext string) (bool, error) {
+	if ctx.KubeClient() != nil && ctx.KubeClient().CurrentContext() == kubeContext {
+		return c.deployTarget(ctx, deployConfig, options)
+	}
+
+	ctx, client, err := WithKubeContext(ctx, kubeContext, deployConfig.Namespace)
+	if err != nil {
+		return false, err
+	}
+
+	wasDeployed, err := c.deployTarget(ctx, deployConfig, options)
+	saveErr := ctx.Config().RemoteCache().Save(ctx.Context(), client)
+	if err != nil {
+		return wasDeployed, err
+	} else if saveErr != nil {
+		return wasDeployed, errors.Wrap(saveErr, "save remote cache")
+	}
+
+	return wasDeployed, nil
+}
+
+// targetContexts returns the context of the current kube client and a context for every other kube context
+// the deployments are deployed to, so that their remote caches can be purged or rolled back as well
+func targetContexts(ctx devspacecontext.Context, deployments []string) ([]devspacecontext.Context, error) {
+	targets := []devspacecontext.Context{ctx}
+	for _, kubeContext := range otherKubeContexts(ctx, deployments) {
+		targetCtx, _, err := WithKubeContext(ctx, kubeContext, "")
+		if err != nil {
+			return nil, err
+		}
+
+		targets = append(targets, targetCtx)
+	}
+
+	return targets, nil
+}
+
+// otherKubeContexts returns the sorted kube contexts of all or the given deployments that are not the
+// current kube context
+func otherKubeContexts(ctx devspacecontext.Context, deployments []string) []string {
+	if ctx.Config() == nil || ctx.Config().Config() == nil {
+		return nil
+	}
+
+	currentContext := ""
+	if ctx.KubeClient() != nil {
+		currentContext = ctx.KubeClient().CurrentContext()
+	}
+
+	kubeContexts := []string{}
+	for _, deployConfig := range ctx.Config().Config().Deployments {
+		if len(deployments) > 0 && !stringutil.Contains(deployments, deployConfig.Name) {
+			continue
+		}
+
+		for _, kubeContext := range KubeContexts(deployConfig) {
+			if kubeContext != currentContext && !stringutil.Contains(kubeContexts, kubeContext) {
+				kubeContexts = append(kubeContexts, kubeContext)
+			}
+		}
+	}
+
+	sort.Strings(kubeContexts)
+	return kubeContexts
+}
+
+// WithKubeContext returns a context with a kube client for the kube context and the remote cache of its cluster
+func WithKubeContext(ctx devspacecontext.Context, kubeContext, namespace string) (devspacecontext.Context, kubectl.Client, error) {
+	var kubeLoader kubeconfig.Loader = kubeconfig.NewLoader()
+	if ctx.KubeClient() != nil {
+		kubeLoader = ctx.KubeClient().KubeConfigLoader()
+	}
+
+	client, err := kubectl.NewClientFromContext(kubeContext, namespace, false, kubeLoader)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "create kube client for kube context %s", kubeContext)
+	}
+
+	conf := ctx.Config()
+	remoteCache, err := remotecache.NewCacheLoader(conf.Config().Name).Load(ctx.Context(), client)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "load remote cache of kube context %s", kubeContext)
+	}
+
+	// the runtime variables are shared, so that the built images are the same in every cluster
+	conf = config.NewConfigWithRuntimeVariables(conf.Raw(), conf.RawBeforeConversion(), conf.Config(), conf.LocalCache(), remoteCache, conf.Variables(), conf.Path(), conf)
+	ctx = ctx.WithKubeClient(client).WithConfig(conf).WithLogger(ctx.Log().WithPrefix(kubeContext + " "))
+	return ctx, client, nil
+}
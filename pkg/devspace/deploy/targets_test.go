@@ -0,0 +1,94 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+type kubeContextsTestCase struct {
+	name string
+
+	deployConfig *latest.DeploymentConfig
+
+	expected []string
+}
+
+func TestKubeContexts(t *testing.T) {
+	testCases := []kubeContextsTestCase{
+		{
+			name:         "Current kube context",
+			deployConfig: &latest.DeploymentConfig{},
+		},
+		{
+			name:         "Kube context",
+			deployConfig: &latest.DeploymentConfig{KubeContext: "eu"},
+			expected:     []string{"eu"},
+		},
+		{
+			name:         "Kube contexts",
+			deployConfig: &latest.DeploymentConfig{KubeContexts: []string{"eu", "us"}},
+			expected:     []string{"eu", "us"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.DeepEqual(t, KubeContexts(testCase.deployConfig), testCase.expected)
+	}
+}
+
+type otherKubeContextsTestCase struct {
+	name string
+
+	deployments []string
+
+	expected []string
+}
+
+func TestOtherKubeContexts(t *testing.T) {
+	testCases := []otherKubeContextsTestCase{
+		{
+			name:     "All deployments",
+			expected: []string{"eu", "us"},
+		},
+		{
+			name:        "Selected deployments",
+			deployments: []string{"app", "database"},
+			expected:    []string{"eu"},
+		},
+		{
+			name:        "Current kube context only",
+			deployments: []string{"app", "ingress"},
+			expected:    []string{},
+		},
+	}
+
+	rawConfig := latest.NewRaw()
+	rawConfig.Deployments = map[string]*latest.DeploymentConfig{
+		"app":      {Name: "app", KubeContexts: []string{"current"}},
+		"database": {Name: "database", KubeContexts: []string{"current", "eu"}},
+		"ingress":  {Name: "ingress"},
+		"cdn":      {Name: "cdn", KubeContexts: []string{"us", "eu"}},
+	}
+	conf := config.NewConfig(nil, nil, rawConfig, localcache.New(constants.DefaultCacheFolder), &remotecache.RemoteCache{}, nil, constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf).WithKubeClient(&fakekube.Client{Context: "current"})
+
+	for _, testCase := range testCases {
+		assert.DeepEqual(t, otherKubeContexts(ctx, testCase.deployments), testCase.expected)
+	}
+
+	// without other kube contexts only the current remote cache is used
+	targets, err := targetContexts(ctx, []string{"app"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(targets), 1)
+	assert.Equal(t, targets[0], ctx)
+}
@@ -23,3 +23,13 @@ func (f *FakeController) Deploy(ctx devspacecontext.Context, deployments []strin
 func (f *FakeController) Purge(ctx devspacecontext.Context, deployments []string, options *deploy.PurgeOptions) error {
 	return nil
 }
+
+// Plan returns an empty plan
+func (f *FakeController) Plan(ctx devspacecontext.Context, deployments []string, options *deploy.Options) (*deploy.Plan, error) {
+	return &deploy.Plan{}, nil
+}
+
+// Rollback rolls back the deployments
+func (f *FakeController) Rollback(ctx devspacecontext.Context, deployments []string) error {
+	return nil
+}
@@ -0,0 +1,50 @@
+package deploy
+
+import (
+	"bytes"
+	"strings"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/validation"
+	"github.com/pkg/errors"
+)
+
+// newValidator loads the OpenAPI schema of the kubernetes version or of the cluster
+func newValidator(ctx devspacecontext.Context, options *Options) (*validation.Validator, error) {
+	if options.ValidateKubeVersion != "" {
+		return validation.NewVersionValidator(ctx.Context(), options.ValidateKubeVersion)
+	} else if ctx.KubeClient() == nil {
+		return nil, errors.New("validating against the cluster requires a kube context, use --validate-kube-version to validate offline")
+	}
+
+	return validation.NewClusterValidator(ctx.KubeClient())
+}
+
+// renderValidated renders the manifests of the deployment and only writes them if they are valid
+func renderValidated(ctx devspacecontext.Context, deployClient deployer.Interface, options *Options) error {
+	buffer := &bytes.Buffer{}
+	err := deployClient.Render(ctx, buffer)
+	if err != nil {
+		return err
+	}
+
+	problems, unknownKinds, err := options.validator.Validate(buffer.String())
+	if err != nil {
+		return err
+	}
+	for _, kind := range unknownKinds {
+		ctx.Log().Warnf("Skip validating %s, because the schema doesn't contain it", kind)
+	}
+	if len(problems) > 0 {
+		messages := []string{}
+		for _, problem := range problems {
+			messages = append(messages, problem.Error())
+		}
+
+		return errors.Errorf("rendered manifests are invalid:\n%s", strings.Join(messages, "\n"))
+	}
+
+	_, err = options.RenderWriter.Write(buffer.Bytes())
+	return err
+}
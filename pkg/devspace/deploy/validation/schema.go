@@ -0,0 +1,94 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+// schemaURL is where the OpenAPI schema of a Kubernetes version is downloaded from
+var schemaURL = "https://raw.githubusercontent.com/kubernetes/kubernetes/%s/api/openapi-spec/swagger.json"
+
+// schemaFolder is the folder in the DevSpace home folder where downloaded schemas are cached
+const schemaFolder = "schemas"
+
+// NewClusterValidator creates a validator with the OpenAPI schema of the cluster, which includes the custom
+// resources of the cluster
+func NewClusterValidator(client kubectl.Client) (*Validator, error) {
+	doc, err := client.KubeClient().Discovery().OpenAPISchema()
+	if err != nil {
+		return nil, errors.Wrap(err, "get openapi schema of the cluster")
+	}
+
+	return NewValidator(doc)
+}
+
+// NewVersionValidator creates a validator with the OpenAPI schema of the Kubernetes version. The schema is
+// downloaded once and then cached, so that manifests can be validated offline
+func NewVersionValidator(ctx context.Context, kubeVersion string) (*Validator, error) {
+	kubeVersion = "v" + strings.TrimPrefix(strings.TrimSpace(kubeVersion), "v")
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	schemaPath := filepath.Join(home, constants.DefaultHomeDevSpaceFolder, schemaFolder, "kubernetes-"+kubeVersion+".json")
+	out, err := os.ReadFile(schemaPath)
+	if err != nil {
+		out, err = downloadSchema(ctx, kubeVersion, schemaPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "download openapi schema of kubernetes %s", kubeVersion)
+		}
+	}
+
+	doc, err := openapi_v2.ParseDocument(out)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse openapi schema of kubernetes %s", kubeVersion)
+	}
+
+	return NewValidator(doc)
+}
+
+func downloadSchema(ctx context.Context, kubeVersion, schemaPath string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(schemaURL, kubeVersion), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d, make sure %s is a released kubernetes version", resp.StatusCode, kubeVersion)
+	}
+
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(schemaPath), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(schemaPath, out, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
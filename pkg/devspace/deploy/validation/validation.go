@@ -0,0 +1,323 @@
+package validation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+)
+
+const (
+	gvkExtension                   = "x-kubernetes-group-version-kind"
+	preserveUnknownFieldsExtension = "x-kubernetes-preserve-unknown-fields"
+
+	// sourcePrefix is the comment helm adds in front of every rendered template
+	sourcePrefix = "# Source: "
+)
+
+// FieldError is a problem with a field of a rendered object
+type FieldError struct {
+	// Source is the template the object was rendered from, if known
+	Source string
+	// Line is the line of the field in the rendered manifests
+	Line int
+
+	Kind string
+	Name string
+	Path string
+
+	Message string
+}
+
+func (f *FieldError) Error() string {
+	location := fmt.Sprintf("line %d", f.Line)
+	if f.Source != "" {
+		location = f.Source + " (" + location + ")"
+	}
+
+	object := f.Kind
+	if f.Name != "" {
+		object += " " + f.Name
+	}
+	if f.Path != "" {
+		return fmt.Sprintf("%s: %s: %s: %s", location, object, f.Path, f.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", location, object, f.Message)
+}
+
+// Validator validates rendered manifests against an OpenAPI schema
+type Validator struct {
+	schemas map[schema.GroupVersionKind]proto.Schema
+}
+
+// NewValidator creates a validator for the kinds of the OpenAPI document
+func NewValidator(doc *openapi_v2.Document) (*Validator, error) {
+	models, err := proto.NewOpenAPIData(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse openapi schema")
+	}
+
+	schemas := map[schema.GroupVersionKind]proto.Schema{}
+	for _, name := range models.ListModels() {
+		model := models.LookupModel(name)
+		if model == nil {
+			continue
+		}
+
+		gvks, ok := model.GetExtensions()[gvkExtension].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, gvk := range gvks {
+			values, ok := gvk.(map[interface{}]interface{})
+			if !ok {
+				continue
+			}
+
+			group, _ := values["group"].(string)
+			version, _ := values["version"].(string)
+			kind, _ := values["kind"].(string)
+			schemas[schema.GroupVersionKind{Group: group, Version: version, Kind: kind}] = model
+		}
+	}
+
+	return &Validator{schemas: schemas}, nil
+}
+
+// Validate validates every object of the manifests. It returns the problems it found and the kinds it has
+// no schema for, e.g. custom resources in an offline schema
+func (v *Validator) Validate(manifests string) ([]*FieldError, []string, error) {
+	problems := []*FieldError{}
+	unknownKinds := []string{}
+	decoder := yaml.NewDecoder(strings.NewReader(manifests))
+	for {
+		document := &yaml.Node{}
+		err := decoder.Decode(document)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, errors.Wrap(err, "parse rendered manifests")
+		} else if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+
+		obj := document.Content[0]
+		apiVersion := scalarValue(obj, "apiVersion")
+		kind := scalarValue(obj, "kind")
+		name := ""
+		metadata := mappingValue(obj, "metadata")
+		if metadata != nil {
+			name = scalarValue(metadata, "name")
+		}
+
+		o := &object{source: source(document), kind: kind, name: name}
+		if apiVersion == "" || kind == "" {
+			problems = append(problems, o.error(obj, "", "apiVersion and kind are required"))
+			continue
+		}
+
+		groupVersion, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			problems = append(problems, o.error(obj, "apiVersion", err.Error()))
+			continue
+		}
+
+		model, ok := v.schemas[groupVersion.WithKind(kind)]
+		if !ok {
+			unknownKinds = append(unknownKinds, apiVersion+"/"+kind)
+			continue
+		}
+
+		problems = append(problems, o.validate(obj, model, "")...)
+	}
+
+	return problems, unknownKinds, nil
+}
+
+// object is the rendered object that is validated
+type object struct {
+	source string
+	kind   string
+	name   string
+}
+
+func (o *object) error(node *yaml.Node, path, message string) *FieldError {
+	return &FieldError{
+		Source:  o.source,
+		Line:    node.Line,
+		Kind:    o.kind,
+		Name:    o.name,
+		Path:    path,
+		Message: message,
+	}
+}
+
+// validate returns the problems of the node with the schema
+func (o *object) validate(node *yaml.Node, s proto.Schema, path string) []*FieldError {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Tag == "!!null" {
+		return nil
+	}
+
+	switch t := s.(type) {
+	case proto.Reference:
+		return o.validate(node, t.SubSchema(), path)
+	case *proto.Kind:
+		if node.Kind != yaml.MappingNode {
+			return []*FieldError{o.error(node, path, "expected an object, got "+nodeType(node))}
+		}
+
+		problems := []*FieldError{}
+		preserveUnknownFields, _ := t.GetExtensions()[preserveUnknownFieldsExtension].(bool)
+		fields := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fields[key.Value] = true
+
+			field, ok := t.Fields[key.Value]
+			if !ok {
+				if !preserveUnknownFields {
+					problems = append(problems, o.error(key, path, fmt.Sprintf("unknown field %q", key.Value)))
+				}
+				continue
+			}
+
+			problems = append(problems, o.validate(value, field, fieldPath(path, key.Value))...)
+		}
+		for _, required := range t.RequiredFields {
+			if !fields[required] {
+				problems = append(problems, o.error(node, path, fmt.Sprintf("missing required field %q", required)))
+			}
+		}
+
+		return problems
+	case *proto.Map:
+		if node.Kind != yaml.MappingNode {
+			return []*FieldError{o.error(node, path, "expected an object, got "+nodeType(node))}
+		}
+
+		problems := []*FieldError{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			problems = append(problems, o.validate(node.Content[i+1], t.SubType, fieldPath(path, node.Content[i].Value))...)
+		}
+
+		return problems
+	case *proto.Array:
+		if node.Kind != yaml.SequenceNode {
+			return []*FieldError{o.error(node, path, "expected an array, got "+nodeType(node))}
+		}
+
+		problems := []*FieldError{}
+		for i, item := range node.Content {
+			problems = append(problems, o.validate(item, t.SubType, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+
+		return problems
+	case *proto.Primitive:
+		if !primitiveMatches(node, t) {
+			expected := t.Type
+			if t.Format == "int-or-string" {
+				expected = "integer or string"
+			}
+
+			return []*FieldError{o.error(node, path, fmt.Sprintf("expected %s, got %s", expected, nodeType(node)))}
+		}
+	}
+
+	return nil
+}
+
+func primitiveMatches(node *yaml.Node, primitive *proto.Primitive) bool {
+	if node.Kind != yaml.ScalarNode {
+		return false
+	}
+
+	switch primitive.Type {
+	case "integer":
+		return node.Tag == "!!int" || (primitive.Format == "int-or-string" && node.Tag == "!!str")
+	case "number":
+		return node.Tag == "!!int" || node.Tag == "!!float"
+	case "boolean":
+		return node.Tag == "!!bool"
+	case "string":
+		return node.Tag == "!!str" || node.Tag == "!!timestamp" || (primitive.Format == "int-or-string" && node.Tag == "!!int")
+	}
+
+	return true
+}
+
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+
+	switch node.Tag {
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	}
+
+	return "string"
+}
+
+func fieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+
+	return path + "." + field
+}
+
+// source returns the template helm rendered the document from
+func source(document *yaml.Node) string {
+	comments := []string{document.HeadComment}
+	if len(document.Content) > 0 {
+		comments = append(comments, document.Content[0].HeadComment)
+		if len(document.Content[0].Content) > 0 {
+			comments = append(comments, document.Content[0].Content[0].HeadComment)
+		}
+	}
+
+	for _, comment := range comments {
+		for _, line := range strings.Split(comment, "\n") {
+			if strings.HasPrefix(line, sourcePrefix) {
+				return strings.TrimSpace(strings.TrimPrefix(line, sourcePrefix))
+			}
+		}
+	}
+
+	return ""
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func scalarValue(node *yaml.Node, key string) string {
+	value := mappingValue(node, key)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return ""
+	}
+
+	return value.Value
+}
@@ -0,0 +1,142 @@
+package validation
+
+import (
+	"testing"
+
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"gotest.tools/assert"
+)
+
+const testSchema = `{
+  "swagger": "2.0",
+  "info": {"title": "Kubernetes", "version": "v1.24.0"},
+  "paths": {},
+  "definitions": {
+    "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "labels": {"type": "object", "additionalProperties": {"type": "string"}}
+      }
+    },
+    "io.k8s.api.core.v1.ServicePort": {
+      "type": "object",
+      "required": ["port"],
+      "properties": {
+        "port": {"type": "integer", "format": "int32"},
+        "targetPort": {"type": "string", "format": "int-or-string"}
+      }
+    },
+    "io.k8s.api.core.v1.Service": {
+      "type": "object",
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"},
+        "spec": {
+          "type": "object",
+          "properties": {
+            "ports": {"type": "array", "items": {"$ref": "#/definitions/io.k8s.api.core.v1.ServicePort"}}
+          }
+        }
+      },
+      "x-kubernetes-group-version-kind": [{"group": "", "kind": "Service", "version": "v1"}]
+    }
+  }
+}`
+
+type validateTestCase struct {
+	name string
+
+	manifests string
+
+	expectedProblems     []string
+	expectedUnknownKinds []string
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []validateTestCase{
+		{
+			name: "Valid service",
+			manifests: `apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  ports:
+  - port: 80
+    targetPort: http
+`,
+			expectedProblems:     []string{},
+			expectedUnknownKinds: []string{},
+		},
+		{
+			name: "Typo in field",
+			manifests: `apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  prots:
+  - port: 80
+`,
+			expectedProblems:     []string{`line 6: Service app: spec: unknown field "prots"`},
+			expectedUnknownKinds: []string{},
+		},
+		{
+			name: "Wrong types and missing required field",
+			manifests: `apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  labels:
+    version: 2
+spec:
+  ports:
+  - port: "80"
+  - targetPort: 8080
+`,
+			expectedProblems: []string{
+				`line 6: Service app: metadata.labels.version: expected string, got integer`,
+				`line 9: Service app: spec.ports[0].port: expected integer, got string`,
+				`line 10: Service app: spec.ports[1]: missing required field "port"`,
+			},
+			expectedUnknownKinds: []string{},
+		},
+		{
+			name: "Helm source and second document",
+			manifests: `---
+# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  nmae: typo
+---
+apiVersion: example.com/v1
+kind: Custom
+metadata:
+  name: custom
+`,
+			expectedProblems:     []string{`app/templates/service.yaml (line 7): Service app: metadata: unknown field "nmae"`},
+			expectedUnknownKinds: []string{"example.com/v1/Custom"},
+		},
+	}
+
+	doc, err := openapi_v2.ParseDocument([]byte(testSchema))
+	assert.NilError(t, err)
+	validator, err := NewValidator(doc)
+	assert.NilError(t, err)
+
+	for _, testCase := range testCases {
+		problems, unknownKinds, err := validator.Validate(testCase.manifests)
+		assert.NilError(t, err, "Error in testCase %s", testCase.name)
+
+		messages := []string{}
+		for _, problem := range problems {
+			messages = append(messages, problem.Error())
+		}
+		assert.DeepEqual(t, messages, testCase.expectedProblems)
+		assert.DeepEqual(t, unknownKinds, testCase.expectedUnknownKinds)
+	}
+}
@@ -0,0 +1,375 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer"
+	helmclient "github.com/loft-sh/devspace/pkg/devspace/helm"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/pkg/errors"
+	"github.com/vmware-labs/yaml-jsonpath/pkg/yamlpath"
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// defaultWaitTimeout is the default amount of seconds to wait until a deployment is ready
+const defaultWaitTimeout = 300
+
+// workload is an object of a deployment the deploy waits for
+type workload struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// waitForDeployment waits until the workloads of the deployment are rolled out, its jobs are completed and
+// the custom conditions are met
+func waitForDeployment(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig) error {
+	waitConfig := deployConfig.Wait
+	timeout := time.Duration(waitConfig.Timeout) * time.Second
+	if waitConfig.Timeout == 0 {
+		timeout = defaultWaitTimeout * time.Second
+	}
+
+	namespace := deployConfig.Namespace
+	if namespace == "" {
+		namespace = ctx.KubeClient().Namespace()
+	}
+
+	workloads, err := deploymentWorkloads(ctx, deployConfig, namespace)
+	if err != nil {
+		return err
+	}
+
+	ctx.Log().Infof("Waiting for deployment %s to become ready...", deployConfig.Name)
+	lastMessage := ""
+	err = wait.PollImmediateWithContext(ctx.Context(), time.Second, timeout, func(context.Context) (bool, error) {
+		message, err := notReady(ctx, waitConfig, workloads, namespace)
+		if err != nil {
+			return false, err
+		} else if message != "" && message != lastMessage {
+			ctx.Log().Info(message)
+		}
+
+		lastMessage = message
+		return message == "", nil
+	})
+	if err != nil {
+		if err == wait.ErrWaitTimeout {
+			return errors.Errorf("timed out after %s waiting for deployment %s to become ready: %s", timeout.String(), deployConfig.Name, lastMessage)
+		}
+
+		return err
+	}
+
+	ctx.Log().Donef("Deployment %s is ready", deployConfig.Name)
+	return nil
+}
+
+// notReady returns a message that explains what the deployment is waiting for or an empty string if it is ready
+func notReady(ctx devspacecontext.Context, waitConfig *latest.DeploymentWaitConfig, workloads []workload, namespace string) (string, error) {
+	client := ctx.KubeClient().KubeClient()
+	for _, w := range workloads {
+		var (
+			message string
+			err     error
+		)
+		switch w.Kind {
+		case "Deployment":
+			if waitConfig.Rollout != nil && !*waitConfig.Rollout {
+				continue
+			}
+
+			var deployment *appsv1.Deployment
+			deployment, err = client.AppsV1().Deployments(w.Namespace).Get(ctx.Context(), w.Name, metav1.GetOptions{})
+			if err == nil {
+				message, err = deploymentStatus(deployment)
+			}
+		case "StatefulSet":
+			if waitConfig.Rollout != nil && !*waitConfig.Rollout {
+				continue
+			}
+
+			var statefulSet *appsv1.StatefulSet
+			statefulSet, err = client.AppsV1().StatefulSets(w.Namespace).Get(ctx.Context(), w.Name, metav1.GetOptions{})
+			if err == nil {
+				message = statefulSetStatus(statefulSet)
+			}
+		case "DaemonSet":
+			if waitConfig.Rollout != nil && !*waitConfig.Rollout {
+				continue
+			}
+
+			var daemonSet *appsv1.DaemonSet
+			daemonSet, err = client.AppsV1().DaemonSets(w.Namespace).Get(ctx.Context(), w.Name, metav1.GetOptions{})
+			if err == nil {
+				message = daemonSetStatus(daemonSet)
+			}
+		case "Job":
+			if waitConfig.Jobs != nil && !*waitConfig.Jobs {
+				continue
+			}
+
+			var job *batchv1.Job
+			job, err = client.BatchV1().Jobs(w.Namespace).Get(ctx.Context(), w.Name, metav1.GetOptions{})
+			if err == nil {
+				message, err = jobStatus(job)
+			}
+		default:
+			continue
+		}
+		if kerrors.IsNotFound(err) {
+			return fmt.Sprintf("Waiting for %s %s to be created...", strings.ToLower(w.Kind), w.Name), nil
+		} else if err != nil || message != "" {
+			return message, err
+		}
+	}
+
+	for i, condition := range waitConfig.Conditions {
+		message, err := conditionStatus(ctx, condition, namespace)
+		if err != nil {
+			return "", errors.Wrapf(err, "wait.conditions[%d]", i)
+		} else if message != "" {
+			return message, nil
+		}
+	}
+
+	return "", nil
+}
+
+// deploymentWorkloads returns the objects the deployment has deployed
+func deploymentWorkloads(ctx devspacecontext.Context, deployConfig *latest.DeploymentConfig, namespace string) ([]workload, error) {
+	deployCache, ok := ctx.Config().RemoteCache().GetDeployment(deployConfig.Name)
+	if !ok {
+		return nil, nil
+	}
+
+	workloads := []workload{}
+	if deployCache.Kubectl != nil {
+		for _, obj := range deployCache.Kubectl.Objects {
+			workloads = append(workloads, workload{Kind: obj.Kind, Name: obj.Name, Namespace: obj.Namespace})
+		}
+	} else if deployCache.Helm != nil {
+		helmClient, err := helmclient.NewClient(ctx.Log())
+		if err != nil {
+			return nil, err
+		}
+
+		manifest, err := helmClient.GetManifest(ctx, deployCache.Helm.Release, deployCache.Helm.ReleaseNamespace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get manifest of release %s", deployCache.Helm.Release)
+		}
+
+		objects, err := deployer.ParseObjects(manifest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse manifest of release %s", deployCache.Helm.Release)
+		}
+
+		for _, obj := range objects {
+			objNamespace := obj.GetNamespace()
+			if objNamespace == "" {
+				objNamespace = deployCache.Helm.ReleaseNamespace
+			}
+
+			workloads = append(workloads, workload{Kind: obj.GetKind(), Name: obj.GetName(), Namespace: objNamespace})
+		}
+	}
+
+	for i := range workloads {
+		if workloads[i].Namespace == "" {
+			workloads[i].Namespace = namespace
+		}
+	}
+
+	return workloads, nil
+}
+
+// deploymentStatus returns a message if the deployment is not rolled out yet, the same way kubectl rollout status does
+func deploymentStatus(deployment *appsv1.Deployment) (string, error) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return fmt.Sprintf("Waiting for deployment %s spec update to be observed...", deployment.Name), nil
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return "", errors.Errorf("deployment %s exceeded its progress deadline", deployment.Name)
+		}
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	if deployment.Status.UpdatedReplicas < replicas {
+		return fmt.Sprintf("Waiting for deployment %s rollout to finish: %d out of %d new replicas have been updated...", deployment.Name, deployment.Status.UpdatedReplicas, replicas), nil
+	}
+	if deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
+		return fmt.Sprintf("Waiting for deployment %s rollout to finish: %d old replicas are pending termination...", deployment.Name, deployment.Status.Replicas-deployment.Status.UpdatedReplicas), nil
+	}
+	if deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas {
+		return fmt.Sprintf("Waiting for deployment %s rollout to finish: %d of %d updated replicas are available...", deployment.Name, deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas), nil
+	}
+
+	return "", nil
+}
+
+// statefulSetStatus returns a message if the stateful set is not rolled out yet
+func statefulSetStatus(statefulSet *appsv1.StatefulSet) string {
+	if statefulSet.Spec.UpdateStrategy.Type != "" && statefulSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return ""
+	}
+	if statefulSet.Status.ObservedGeneration == 0 || statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		return fmt.Sprintf("Waiting for statefulset %s spec update to be observed...", statefulSet.Name)
+	}
+
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	if statefulSet.Status.ReadyReplicas < replicas {
+		return fmt.Sprintf("Waiting for statefulset %s: %d of %d pods are ready...", statefulSet.Name, statefulSet.Status.ReadyReplicas, replicas)
+	}
+	if statefulSet.Spec.UpdateStrategy.RollingUpdate != nil && statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		partition := *statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition
+		if statefulSet.Status.UpdatedReplicas < replicas-partition {
+			return fmt.Sprintf("Waiting for statefulset %s partitioned rollout to finish: %d out of %d new pods have been updated...", statefulSet.Name, statefulSet.Status.UpdatedReplicas, replicas-partition)
+		}
+
+		return ""
+	}
+	if statefulSet.Status.UpdateRevision != statefulSet.Status.CurrentRevision {
+		return fmt.Sprintf("Waiting for statefulset %s rolling update to complete: %d pods at revision %s...", statefulSet.Name, statefulSet.Status.UpdatedReplicas, statefulSet.Status.UpdateRevision)
+	}
+
+	return ""
+}
+
+// daemonSetStatus returns a message if the daemon set is not rolled out yet
+func daemonSetStatus(daemonSet *appsv1.DaemonSet) string {
+	if daemonSet.Spec.UpdateStrategy.Type != "" && daemonSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		return ""
+	}
+	if daemonSet.Generation > daemonSet.Status.ObservedGeneration {
+		return fmt.Sprintf("Waiting for daemonset %s spec update to be observed...", daemonSet.Name)
+	}
+	if daemonSet.Status.UpdatedNumberScheduled < daemonSet.Status.DesiredNumberScheduled {
+		return fmt.Sprintf("Waiting for daemonset %s rollout to finish: %d out of %d new pods have been updated...", daemonSet.Name, daemonSet.Status.UpdatedNumberScheduled, daemonSet.Status.DesiredNumberScheduled)
+	}
+	if daemonSet.Status.NumberAvailable < daemonSet.Status.DesiredNumberScheduled {
+		return fmt.Sprintf("Waiting for daemonset %s rollout to finish: %d of %d updated pods are available...", daemonSet.Name, daemonSet.Status.NumberAvailable, daemonSet.Status.DesiredNumberScheduled)
+	}
+
+	return ""
+}
+
+// jobStatus returns a message if the job is not completed yet and an error if it failed
+func jobStatus(job *batchv1.Job) (string, error) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return "", nil
+		case batchv1.JobFailed:
+			return "", errors.Errorf("job %s failed: %s", job.Name, condition.Message)
+		}
+	}
+
+	return fmt.Sprintf("Waiting for job %s to complete: %d succeeded, %d active...", job.Name, job.Status.Succeeded, job.Status.Active), nil
+}
+
+// conditionStatus returns a message if not all objects selected by the condition have the expected value
+func conditionStatus(ctx devspacecontext.Context, condition *latest.DeploymentWaitCondition, namespace string) (string, error) {
+	apiVersion, kind := condition.APIVersion, condition.Kind
+	if apiVersion == "" {
+		apiVersion = "v1"
+	}
+	if kind == "" {
+		kind = "Pod"
+	}
+
+	out, err := ctx.KubeClient().GenericRequest(ctx.Context(), &kubectl.GenericRequestOptions{
+		Kind:          kind,
+		APIVersion:    apiVersion,
+		Namespace:     namespace,
+		LabelSelector: labels.SelectorFromSet(condition.LabelSelector).String(),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "list %s", kind)
+	}
+
+	list := &struct {
+		Items []map[string]interface{} `json:"items"`
+	}{}
+	err = json.Unmarshal([]byte(out), list)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse %s list", kind)
+	}
+
+	selector := labels.SelectorFromSet(condition.LabelSelector).String()
+	if len(list.Items) == 0 {
+		return fmt.Sprintf("Waiting for %s with labels %s to be created...", strings.ToLower(kind), selector), nil
+	}
+
+	expected := condition.Value
+	if expected == "" {
+		expected = "True"
+	}
+	for _, item := range list.Items {
+		value, err := jsonPathValue(item, condition.JSONPath)
+		if err != nil {
+			return "", err
+		} else if value != expected {
+			name := ""
+			if metadata, ok := item["metadata"].(map[string]interface{}); ok {
+				name, _ = metadata["name"].(string)
+			}
+
+			return fmt.Sprintf("Waiting for %s %s: %s is '%s' instead of '%s'...", strings.ToLower(kind), name, condition.JSONPath, value, expected), nil
+		}
+	}
+
+	return "", nil
+}
+
+// jsonPathValue returns the value at the json path of the object or an empty string if there is none
+func jsonPathValue(obj map[string]interface{}, jsonPath string) (string, error) {
+	path, err := yamlpath.NewPath(jsonPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse json path %s", jsonPath)
+	}
+
+	out, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+
+	doc := &yaml.Node{}
+	err = yaml.Unmarshal(out, doc)
+	if err != nil {
+		return "", err
+	}
+
+	nodes, err := path.Find(doc)
+	if err != nil {
+		return "", errors.Wrapf(err, "find %s", jsonPath)
+	} else if len(nodes) == 0 || nodes[0].Kind != yaml.ScalarNode {
+		return "", nil
+	}
+
+	return nodes[0].Value, nil
+}
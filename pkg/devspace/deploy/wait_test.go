@@ -0,0 +1,211 @@
+package deploy
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+type deploymentStatusTestCase struct {
+	name string
+
+	deployment *appsv1.Deployment
+
+	expectedReady bool
+	expectedErr   bool
+}
+
+func TestDeploymentStatus(t *testing.T) {
+	testCases := []deploymentStatusTestCase{
+		{
+			name: "Not observed",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 2},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1},
+			},
+		},
+		{
+			name: "Replicas not updated",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 1},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1},
+			},
+		},
+		{
+			name: "Old replicas pending termination",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 1},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1},
+			},
+		},
+		{
+			name: "Progress deadline exceeded",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 1},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 1,
+					Conditions: []appsv1.DeploymentCondition{
+						{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "Ready",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 1},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			expectedReady: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		message, err := deploymentStatus(testCase.deployment)
+		if testCase.expectedErr {
+			assert.Assert(t, err != nil, "Expected error in testCase %s", testCase.name)
+			continue
+		}
+
+		assert.NilError(t, err, "Unexpected error in testCase %s", testCase.name)
+		assert.Equal(t, message == "", testCase.expectedReady, "Unexpected readiness in testCase %s: %s", testCase.name, message)
+	}
+}
+
+type statefulSetStatusTestCase struct {
+	name string
+
+	statefulSet *appsv1.StatefulSet
+
+	expectedReady bool
+}
+
+func TestStatefulSetStatus(t *testing.T) {
+	testCases := []statefulSetStatusTestCase{
+		{
+			name: "OnDelete strategy",
+			statefulSet: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}},
+			},
+			expectedReady: true,
+		},
+		{
+			name: "Pods not ready",
+			statefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 2},
+			},
+		},
+		{
+			name: "Revision not updated",
+			statefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(1)},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 1, CurrentRevision: "a", UpdateRevision: "b"},
+			},
+		},
+		{
+			name: "Partition updated",
+			statefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: int32Ptr(3),
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+						RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: int32Ptr(2)},
+					},
+				},
+				Status: appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "a", UpdateRevision: "b"},
+			},
+			expectedReady: true,
+		},
+		{
+			name: "Ready",
+			statefulSet: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(1)},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 1, CurrentRevision: "a", UpdateRevision: "a"},
+			},
+			expectedReady: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		message := statefulSetStatus(testCase.statefulSet)
+		assert.Equal(t, message == "", testCase.expectedReady, "Unexpected readiness in testCase %s: %s", testCase.name, message)
+	}
+}
+
+type jobStatusTestCase struct {
+	name string
+
+	job *batchv1.Job
+
+	expectedReady bool
+	expectedErr   bool
+}
+
+func TestJobStatus(t *testing.T) {
+	testCases := []jobStatusTestCase{
+		{
+			name: "Running",
+			job:  &batchv1.Job{Status: batchv1.JobStatus{Active: 1}},
+		},
+		{
+			name: "Complete",
+			job: &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+			}}},
+			expectedReady: true,
+		},
+		{
+			name: "Failed",
+			job: &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"},
+			}}},
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		message, err := jobStatus(testCase.job)
+		if testCase.expectedErr {
+			assert.Assert(t, err != nil, "Expected error in testCase %s", testCase.name)
+			continue
+		}
+
+		assert.NilError(t, err, "Unexpected error in testCase %s", testCase.name)
+		assert.Equal(t, message == "", testCase.expectedReady, "Unexpected readiness in testCase %s: %s", testCase.name, message)
+	}
+}
+
+func TestJSONPathValue(t *testing.T) {
+	pod := map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Initialized", "status": "True"},
+				map[string]interface{}{"type": "Ready", "status": "False"},
+			},
+		},
+	}
+
+	value, err := jsonPathValue(pod, "$.status.conditions[?(@.type=='Ready')].status")
+	assert.NilError(t, err)
+	assert.Equal(t, value, "False")
+
+	value, err = jsonPathValue(pod, "$.status.phase")
+	assert.NilError(t, err)
+	assert.Equal(t, value, "")
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/util/lockfactory"
 	logpkg "github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/stringutil"
+	"github.com/loft-sh/devspace/pkg/util/timing"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
 
@@ -183,7 +184,9 @@ func (d *devPodManager) Start(originalContext devspacecontext.Context, devPodCon
 	unionLogger := originalContext.Log().WithPrefix(prefix).WithSink(logpkg.GetDevPodFileLogger(prefix))
 
 	// start the dev pod
-	err := dp.Start(originalContext.WithLogger(unionLogger), devPodConfig, options)
+	spanCtx, done := timing.Start(originalContext.Context(), "start dev "+devPodConfig.Name)
+	err := dp.Start(originalContext.WithContext(spanCtx).WithLogger(unionLogger), devPodConfig, options)
+	done()
 	if err != nil {
 		return nil, err
 	}
@@ -15,7 +15,11 @@ import (
 
 // ImageBuildCLI builds an image with the docker cli
 func (c *client) ImageBuildCLI(ctx context.Context, workingDir string, environ expand.Environ, useBuildKit bool, context io.Reader, writer io.Writer, additionalArgs []string, options dockertypes.ImageBuildOptions, log log.Logger) error {
-	args := []string{"build"}
+	args := []string{}
+	if c.dockerContext != "" {
+		args = append(args, "--context", c.dockerContext)
+	}
+	args = append(args, "build")
 	if options.BuildArgs != nil {
 		for k, v := range options.BuildArgs {
 			if v == nil {
@@ -38,6 +42,9 @@ func (c *client) ImageBuildCLI(ctx context.Context, workingDir string, environ e
 	if options.Target != "" {
 		args = append(args, "--target", options.Target)
 	}
+	if options.Platform != "" {
+		args = append(args, "--platform", options.Platform)
+	}
 
 	args = append(args, additionalArgs...)
 	args = append(args, "-")
@@ -48,7 +48,8 @@ type Client interface {
 type client struct {
 	dockerclient.CommonAPIClient
 
-	minikubeEnv map[string]string
+	minikubeEnv   map[string]string
+	dockerContext string
 }
 
 // NewClient retrieves a new docker client
@@ -58,25 +59,59 @@ func NewClient(ctx context.Context, log log.Logger) (Client, error) {
 
 // NewClientWithMinikube creates a new docker client with optionally from the minikube vm
 func NewClientWithMinikube(ctx context.Context, kubectlClient kubectl.Client, preferMinikube bool, log log.Logger) (Client, error) {
+	return NewClientWithContext(ctx, kubectlClient, preferMinikube, "", log)
+}
+
+// NewClientWithContext creates a new docker client for the given docker context. If the docker context
+// is empty, the docker context from the DOCKER_CONTEXT environment variable or the docker config is used.
+// A docker context that is only selected in the docker config doesn't replace the minikube docker daemon
+func NewClientWithContext(ctx context.Context, kubectlClient kubectl.Client, preferMinikube bool, dockerContext string, log log.Logger) (Client, error) {
 	var cli Client
 	var err error
 
+	dockerContext, explicit := resolveDockerContext(dockerContext)
+	if explicit {
+		if dockerContext != defaultDockerContext {
+			cli, err = newDockerClientFromContext(dockerContext)
+			if err != nil {
+				return nil, err
+			}
+
+			cli.NegotiateAPIVersion(ctx)
+			return cli, nil
+		}
+
+		preferMinikube = false
+	}
+
 	if preferMinikube {
 		cli, err = newDockerClientFromMinikube(ctx, kubectlClient)
-		if err != nil && err != errNotMinikube {
+		if err == nil {
+			cli.NegotiateAPIVersion(ctx)
+			return cli, nil
+		} else if err != errNotMinikube {
 			log.Warnf("Error creating minikube docker client: %v", err)
 		}
 	}
-	if !preferMinikube || err != nil {
-		cli, err = newDockerClientFromEnvironment()
-		if err != nil {
-			log.Warnf("Error creating docker client from environment: %v", err)
 
-			// Last try to create it without the environment option
-			cli, err = newDockerClient()
-			if err != nil {
-				return nil, errors.Errorf("Cannot create docker client: %v", err)
-			}
+	if dockerContext != "" && dockerContext != defaultDockerContext {
+		cli, err = newDockerClientFromContext(dockerContext)
+		if err == nil {
+			cli.NegotiateAPIVersion(ctx)
+			return cli, nil
+		}
+
+		log.Warnf("Error creating docker client from docker context %s: %v", dockerContext, err)
+	}
+
+	cli, err = newDockerClientFromEnvironment()
+	if err != nil {
+		log.Warnf("Error creating docker client from environment: %v", err)
+
+		// Last try to create it without the environment option
+		cli, err = newDockerClient()
+		if err != nil {
+			return nil, errors.Errorf("Cannot create docker client: %v", err)
 		}
 	}
 
@@ -20,13 +20,18 @@ var configDir = os.Getenv("DOCKER_CONFIG")
 var configDirOnce sync.Once
 
 func LoadDockerConfig() (*configfile.ConfigFile, error) {
+	return config.Load(dockerConfigFolder())
+}
+
+// dockerConfigFolder returns the folder of the docker config
+func dockerConfigFolder() string {
 	configDirOnce.Do(func() {
 		if configDir == "" {
 			configDir = filepath.Join(homedir.Get(), dockerFileFolder)
 		}
 	})
 
-	return config.Load(configDir)
+	return configDir
 }
 
 // GetAllAuthConfigs returns every auth config found in the docker config
@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/cli/cli/connhelper"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/pkg/errors"
+)
+
+// DockerContextEnv is the environment variable the docker cli uses to select a docker context
+const DockerContextEnv = "DOCKER_CONTEXT"
+
+// defaultDockerContext is the context docker uses if no other context is selected
+const defaultDockerContext = "default"
+
+// contextMeta is the meta.json of a context in the docker context store
+type contextMeta struct {
+	Name      string                     `json:"Name,omitempty"`
+	Endpoints map[string]contextEndpoint `json:"Endpoints,omitempty"`
+}
+
+type contextEndpoint struct {
+	Host          string `json:"Host,omitempty"`
+	SkipTLSVerify bool   `json:"SkipTLSVerify,omitempty"`
+}
+
+// resolveDockerContext returns the docker context that should be used. The given context takes precedence
+// over the DOCKER_CONTEXT environment variable. If DOCKER_HOST is not set, the current context of the
+// docker config is used. The second return value is true if the context was explicitly selected
+func resolveDockerContext(dockerContext string) (string, bool) {
+	if dockerContext != "" {
+		return dockerContext, true
+	} else if envContext := os.Getenv(DockerContextEnv); envContext != "" {
+		return envContext, true
+	} else if os.Getenv(dockerclient.EnvOverrideHost) != "" {
+		return "", false
+	}
+
+	dockerConfig, err := LoadDockerConfig()
+	if err != nil || dockerConfig == nil {
+		return "", false
+	}
+
+	return dockerConfig.CurrentContext, false
+}
+
+// newDockerClientFromContext creates a new docker client for the docker endpoint of the given docker context
+func newDockerClientFromContext(dockerContext string) (Client, error) {
+	contextDir := filepath.Join(dockerConfigFolder(), "contexts")
+	digest := sha256.Sum256([]byte(dockerContext))
+	contextID := hex.EncodeToString(digest[:])
+
+	out, err := os.ReadFile(filepath.Join(contextDir, "meta", contextID, "meta.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("docker context %s doesn't exist", dockerContext)
+		}
+
+		return nil, errors.Wrapf(err, "read docker context %s", dockerContext)
+	}
+
+	meta := &contextMeta{}
+	err = json.Unmarshal(out, meta)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse docker context %s", dockerContext)
+	}
+
+	endpoint, ok := meta.Endpoints["docker"]
+	if !ok || endpoint.Host == "" {
+		return nil, errors.Errorf("docker context %s has no docker endpoint", dockerContext)
+	}
+
+	// ssh endpoints are dialed through ssh and docker system dial-stdio, the same way the docker cli does
+	helper, err := connhelper.GetConnectionHelper(endpoint.Host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "docker context %s", dockerContext)
+	} else if helper != nil {
+		cli, err := dockerclient.NewClientWithOpts(
+			dockerclient.WithHTTPClient(&http.Client{
+				Transport: &http.Transport{
+					DialContext: helper.Dialer,
+				},
+			}),
+			dockerclient.WithHost(helper.Host),
+			dockerclient.WithDialContext(helper.Dialer),
+		)
+		if err != nil {
+			return nil, errors.Errorf("Couldn't create docker client for context %s: %v", dockerContext, err)
+		}
+
+		return &client{
+			CommonAPIClient: cli,
+			dockerContext:   dockerContext,
+		}, nil
+	}
+
+	opts := []dockerclient.Opt{dockerclient.WithHost(endpoint.Host)}
+	tlsDir := filepath.Join(contextDir, "tls", contextID, "docker")
+	if _, err := os.Stat(tlsDir); err == nil || endpoint.SkipTLSVerify {
+		options := tlsconfig.Options{
+			InsecureSkipVerify: endpoint.SkipTLSVerify,
+		}
+		if _, err := os.Stat(filepath.Join(tlsDir, "ca.pem")); err == nil {
+			options.CAFile = filepath.Join(tlsDir, "ca.pem")
+		}
+		if _, err := os.Stat(filepath.Join(tlsDir, "cert.pem")); err == nil {
+			options.CertFile = filepath.Join(tlsDir, "cert.pem")
+			options.KeyFile = filepath.Join(tlsDir, "key.pem")
+		}
+
+		tlsc, err := tlsconfig.Client(options)
+		if err != nil {
+			return nil, errors.Wrapf(err, "create tls config for docker context %s", dockerContext)
+		}
+
+		opts = append([]dockerclient.Opt{dockerclient.WithHTTPClient(&http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsc,
+			},
+			CheckRedirect: dockerclient.CheckRedirect,
+		})}, opts...)
+	}
+
+	cli, err := dockerclient.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, errors.Errorf("Couldn't create docker client for context %s: %v", dockerContext, err)
+	}
+
+	return &client{
+		CommonAPIClient: cli,
+		dockerContext:   dockerContext,
+	}, nil
+}
@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	dockertypes "github.com/docker/docker/api/types"
+	fakekube "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+	"mvdan.cc/sh/v3/expand"
+)
+
+func TestNewDockerClientFromContext(t *testing.T) {
+	configDirBackup := configDir
+	configDir = t.TempDir()
+	defer func() {
+		configDir = configDirBackup
+	}()
+
+	digest := sha256.Sum256([]byte("colima"))
+	metaDir := filepath.Join(configDir, "contexts", "meta", hex.EncodeToString(digest[:]))
+	err := os.MkdirAll(metaDir, 0755)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(`{"Name":"colima","Metadata":{},"Endpoints":{"docker":{"Host":"unix:///tmp/colima/docker.sock","SkipTLSVerify":false}}}`), 0644)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"currentContext":"colima"}`), 0644)
+	assert.NilError(t, err)
+
+	cli, err := newDockerClientFromContext("colima")
+	assert.NilError(t, err)
+	assert.Equal(t, cli.DockerAPIClient().DaemonHost(), "unix:///tmp/colima/docker.sock")
+	assert.Equal(t, cli.(*client).dockerContext, "colima")
+
+	_, err = newDockerClientFromContext("does-not-exist")
+	assert.ErrorContains(t, err, "docker context does-not-exist doesn't exist")
+
+	t.Setenv(DockerContextEnv, "")
+	t.Setenv("DOCKER_HOST", "")
+	dockerContext, explicit := resolveDockerContext("")
+	assert.Equal(t, dockerContext, "colima")
+	assert.Equal(t, explicit, false)
+
+	t.Setenv(DockerContextEnv, "remote")
+	dockerContext, explicit = resolveDockerContext("")
+	assert.Equal(t, dockerContext, "remote")
+	assert.Equal(t, explicit, true)
+
+	dockerContext, explicit = resolveDockerContext("other")
+	assert.Equal(t, dockerContext, "other")
+	assert.Equal(t, explicit, true)
+}
+
+func TestNewDockerClientFromSSHContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake docker cli is a shell script")
+	}
+
+	configDirBackup := configDir
+	configDir = t.TempDir()
+	defer func() {
+		configDir = configDirBackup
+	}()
+
+	digest := sha256.Sum256([]byte("remote"))
+	metaDir := filepath.Join(configDir, "contexts", "meta", hex.EncodeToString(digest[:]))
+	err := os.MkdirAll(metaDir, 0755)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(`{"Name":"remote","Metadata":{},"Endpoints":{"docker":{"Host":"ssh://user@remote-host","SkipTLSVerify":false}}}`), 0644)
+	assert.NilError(t, err)
+
+	// ssh endpoints are dialed through the ssh cli
+	cli, err := NewClientWithContext(context.Background(), nil, false, "remote", log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, cli.DockerAPIClient().DaemonHost(), "http://docker.example.com")
+
+	// builds with the docker cli use the docker context
+	binDir := t.TempDir()
+	err = os.WriteFile(filepath.Join(binDir, "docker"), []byte("#!/bin/sh\necho \"$@\" > \"$(dirname \"$0\")/args\"\n"), 0755)
+	assert.NilError(t, err)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	err = cli.ImageBuildCLI(context.Background(), t.TempDir(), expand.ListEnviron(os.Environ()...), false, strings.NewReader(""), &bytes.Buffer{}, nil, dockertypes.ImageBuildOptions{Tags: []string{"my-image:latest"}}, log.Discard)
+	assert.NilError(t, err)
+	args, err := os.ReadFile(filepath.Join(binDir, "args"))
+	assert.NilError(t, err)
+	assert.Equal(t, strings.TrimSpace(string(args)), "--context remote build --tag my-image:latest -")
+}
+
+func TestNewClientWithContextMinikube(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake minikube cli is a shell script")
+	}
+
+	configDirBackup := configDir
+	configDir = t.TempDir()
+	defer func() {
+		configDir = configDirBackup
+	}()
+
+	digest := sha256.Sum256([]byte("desktop-linux"))
+	metaDir := filepath.Join(configDir, "contexts", "meta", hex.EncodeToString(digest[:]))
+	err := os.MkdirAll(metaDir, 0755)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(`{"Name":"desktop-linux","Metadata":{},"Endpoints":{"docker":{"Host":"unix:///tmp/desktop/docker.sock","SkipTLSVerify":false}}}`), 0644)
+	assert.NilError(t, err)
+	err = os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"currentContext":"desktop-linux"}`), 0644)
+	assert.NilError(t, err)
+
+	binDir := t.TempDir()
+	err = os.WriteFile(filepath.Join(binDir, "minikube"), []byte("#!/bin/sh\necho DOCKER_HOST=tcp://127.0.0.1:2376\necho DOCKER_API_VERSION=1.41\n"), 0755)
+	assert.NilError(t, err)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv(DockerContextEnv, "")
+	t.Setenv("DOCKER_HOST", "")
+
+	// the docker context of the docker config doesn't replace the minikube docker daemon
+	kubeClient := &fakekube.Client{Context: "minikube"}
+	cli, err := NewClientWithContext(context.Background(), kubeClient, true, "", log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, cli.DockerAPIClient().DaemonHost(), "tcp://127.0.0.1:2376")
+
+	// but is used if the cluster is not minikube
+	cli, err = NewClientWithContext(context.Background(), &fakekube.Client{Context: "kind-kind"}, true, "", log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, cli.DockerAPIClient().DaemonHost(), "unix:///tmp/desktop/docker.sock")
+
+	// an explicit docker context always wins
+	t.Setenv(DockerContextEnv, "desktop-linux")
+	cli, err = NewClientWithContext(context.Background(), kubeClient, true, "", log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, cli.DockerAPIClient().DaemonHost(), "unix:///tmp/desktop/docker.sock")
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/loft-sh/utils/pkg/command"
 
 	"gopkg.in/yaml.v3"
@@ -74,6 +75,7 @@ func (c *client) Exec(ctx devspacecontext.Context, args []string) ([]byte, error
 	if !ctx.KubeClient().IsInCluster() {
 		args = append(args, "--kube-context", ctx.KubeClient().CurrentContext())
 	}
+	args = append(args, kubectl.ImpersonationArgs("--kube-as-user", "--kube-as-group")...)
 
 	// disable log for list, because it prints same command multiple times if we've multiple deployments.
 	if args[0] != "list" && args[0] != "registry" && (len(args) == 1 || args[1] != "login") {
@@ -0,0 +1,255 @@
+package oci
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/loft-sh/devspace/pkg/devspace/build/localregistry"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/constraint"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+// Prefix is the prefix of helm charts that are stored in an oci registry
+const Prefix = "oci://"
+
+// chartLayerMediaType is the media type of the layer that holds the packaged chart
+const chartLayerMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+// ChartFolder is the folder in the home directory where pulled charts are cached
+const ChartFolder = ".devspace/charts"
+
+// digestFile holds the manifest digest of a cached chart
+const digestFile = "digest"
+
+// Chart is a helm chart that was pulled from an oci registry
+type Chart struct {
+	// Path is the path of the packaged chart
+	Path string
+	// Version is the resolved version of the chart
+	Version string
+	// Digest is the manifest digest of the chart
+	Digest string
+}
+
+// IsOCI returns true if the chart is stored in an oci registry
+func IsOCI(chartName string) bool {
+	return strings.HasPrefix(strings.TrimSpace(chartName), Prefix)
+}
+
+// Pull resolves the version of the chart, pulls it into the cache and returns the path of the packaged chart.
+// Charts with an exact version are only pulled once, because chart versions are immutable
+func Pull(ctx devspacecontext.Context, chartConfig *latest.ChartConfig) (*Chart, error) {
+	repository, err := name.NewRepository(strings.TrimPrefix(strings.TrimSpace(chartConfig.Name), Prefix))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse chart %s", chartConfig.Name)
+	}
+
+	options := []remote.Option{
+		remote.WithContext(ctx.Context()),
+		remote.WithAuthFromKeychain(keychain(ctx, chartConfig)),
+	}
+
+	version := chartConfig.Version
+	exactVersion := version != ""
+	if exactVersion {
+		_, err = constraint.NewVersion(version)
+		exactVersion = err == nil
+	}
+	if !exactVersion {
+		tags, err := remote.List(repository, options...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "list versions of chart %s", chartConfig.Name)
+		}
+
+		version, err = resolveVersion(tags, chartConfig.Version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "chart %s", chartConfig.Name)
+		}
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+	chartPath := filepath.Join(home, filepath.FromSlash(ChartFolder), repository.RegistryStr(), filepath.FromSlash(repository.RepositoryStr()), version)
+	chart := &Chart{
+		Path:    filepath.Join(chartPath, filepath.Base(repository.RepositoryStr())+"-"+version+".tgz"),
+		Version: version,
+	}
+
+	// use the cached chart
+	digest, err := os.ReadFile(filepath.Join(chartPath, digestFile))
+	if err == nil {
+		if _, err := os.Stat(chart.Path); err == nil {
+			chart.Digest = string(digest)
+			return chart, nil
+		}
+	}
+
+	// helm replaces the + of the version with _, because + is not allowed in tags
+	ctx.Log().Infof("Pulling chart %s:%s...", chartConfig.Name, version)
+	image, err := remote.Image(repository.Tag(strings.ReplaceAll(version, "+", "_")), options...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "pull chart %s:%s", chartConfig.Name, version)
+	}
+
+	manifestDigest, err := image.Digest()
+	if err != nil {
+		return nil, errors.Wrap(err, "get manifest digest")
+	}
+	layers, err := image.Layers()
+	if err != nil {
+		return nil, errors.Wrap(err, "get layers")
+	}
+
+	for _, layer := range layers {
+		mediaType, err := layer.MediaType()
+		if err != nil {
+			return nil, err
+		} else if string(mediaType) != chartLayerMediaType {
+			continue
+		}
+
+		err = writeChart(layer.Compressed, chartPath, chart.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "save chart %s:%s", chartConfig.Name, version)
+		}
+
+		chart.Digest = manifestDigest.String()
+		err = os.WriteFile(filepath.Join(chartPath, digestFile), []byte(chart.Digest), 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		return chart, nil
+	}
+
+	return nil, errors.Errorf("%s:%s is not a helm chart", chartConfig.Name, version)
+}
+
+// keychain returns the credentials of the chart config, of a pull secret for the registry or of the docker config
+func keychain(ctx devspacecontext.Context, chartConfig *latest.ChartConfig) authn.Keychain {
+	if chartConfig.Username != "" && chartConfig.Password != "" {
+		return staticKeychain{authn.FromConfig(authn.AuthConfig{
+			Username: chartConfig.Username,
+			Password: chartConfig.Password,
+		})}
+	}
+
+	keychains := []authn.Keychain{}
+	if ctx.Config() != nil && ctx.Config().Config() != nil {
+		keychains = append(keychains, pullSecretKeychain(ctx.Config().Config().PullSecrets))
+	}
+
+	return authn.NewMultiKeychain(append(keychains, localregistry.Keychain)...)
+}
+
+type staticKeychain struct {
+	authenticator authn.Authenticator
+}
+
+// Resolve implements authn.Keychain
+func (s staticKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return s.authenticator, nil
+}
+
+// pullSecretKeychain returns the credentials of the pull secret with the same registry
+type pullSecretKeychain map[string]*latest.PullSecretConfig
+
+// Resolve implements authn.Keychain
+func (p pullSecretKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	for _, pullSecret := range p {
+		if pullSecret.Username == "" || pullSecret.Password == "" {
+			continue
+		}
+
+		registry := pullSecret.Registry
+		if registry == "" {
+			registry = name.DefaultRegistry
+		}
+		if registry == target.RegistryStr() {
+			return authn.FromConfig(authn.AuthConfig{
+				Username: pullSecret.Username,
+				Password: pullSecret.Password,
+			}), nil
+		}
+	}
+
+	return authn.Anonymous, nil
+}
+
+// resolveVersion returns the highest version of the tags that matches the constraint, pre-releases are skipped
+func resolveVersion(tags []string, versionConstraint string) (string, error) {
+	var constraints constraint.Constraints
+	if versionConstraint != "" {
+		var err error
+		constraints, err = constraint.NewConstraint(versionConstraint)
+		if err != nil {
+			return "", errors.Wrapf(err, "parse version %s", versionConstraint)
+		}
+	}
+
+	var latestVersion *constraint.Version
+	for _, tag := range tags {
+		version, err := constraint.NewSemver(strings.ReplaceAll(tag, "_", "+"))
+		if err != nil || version.Prerelease() != "" {
+			continue
+		} else if constraints != nil && !constraints.Check(version) {
+			continue
+		}
+
+		if latestVersion == nil || version.GreaterThan(latestVersion) {
+			latestVersion = version
+		}
+	}
+	if latestVersion == nil {
+		if versionConstraint == "" {
+			return "", errors.New("couldn't find a version")
+		}
+
+		return "", errors.Errorf("couldn't find a version that matches %s", versionConstraint)
+	}
+
+	return latestVersion.Original(), nil
+}
+
+// writeChart writes the chart into a temporary file first, so that a failed pull doesn't leave a broken chart
+func writeChart(open func() (io.ReadCloser, error), chartPath, targetPath string) error {
+	err := os.MkdirAll(chartPath, 0755)
+	if err != nil {
+		return err
+	}
+
+	reader, err := open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	file, err := os.CreateTemp(chartPath, "chart-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, reader)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), targetPath)
+}
@@ -0,0 +1,91 @@
+package oci
+
+import (
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"gotest.tools/assert"
+)
+
+type resolveVersionTestCase struct {
+	name string
+
+	tags       []string
+	constraint string
+
+	expectedVersion string
+	expectedErr     string
+}
+
+func TestResolveVersion(t *testing.T) {
+	tags := []string{"0.9.0", "1.0.0", "1.2.0", "1.2.3", "1.3.0-rc.1", "2.0.0_build.1", "latest"}
+	testCases := []resolveVersionTestCase{
+		{
+			name:            "Latest version",
+			tags:            tags,
+			expectedVersion: "2.0.0+build.1",
+		},
+		{
+			name:            "Pessimistic constraint",
+			tags:            tags,
+			constraint:      "~> 1.2.0",
+			expectedVersion: "1.2.3",
+		},
+		{
+			name:            "Range",
+			tags:            tags,
+			constraint:      ">= 1.0, < 1.2",
+			expectedVersion: "1.0.0",
+		},
+		{
+			name:        "No match",
+			tags:        tags,
+			constraint:  "> 3.0",
+			expectedErr: "couldn't find a version that matches > 3.0",
+		},
+		{
+			name:        "No versions",
+			tags:        []string{"latest"},
+			expectedErr: "couldn't find a version",
+		},
+	}
+
+	for _, testCase := range testCases {
+		version, err := resolveVersion(testCase.tags, testCase.constraint)
+		if testCase.expectedErr != "" {
+			assert.Error(t, err, testCase.expectedErr, testCase.name)
+		} else {
+			assert.NilError(t, err, testCase.name)
+		}
+		assert.Equal(t, version, testCase.expectedVersion, testCase.name)
+	}
+}
+
+func TestPullSecretKeychain(t *testing.T) {
+	keychain := pullSecretKeychain{
+		"hub":     {Username: "hub", Password: "secret"},
+		"private": {Registry: "registry.example.com", Username: "user", Password: "password"},
+		"docker":  {Registry: "other.example.com"},
+	}
+
+	for registry, expected := range map[string]*authn.AuthConfig{
+		"registry.example.com": {Username: "user", Password: "password"},
+		name.DefaultRegistry:   {Username: "hub", Password: "secret"},
+		"other.example.com":    {},
+	} {
+		reg, err := name.NewRegistry(registry)
+		assert.NilError(t, err)
+		authenticator, err := keychain.Resolve(reg)
+		assert.NilError(t, err)
+		authConfig, err := authenticator.Authorization()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, authConfig, expected)
+	}
+}
+
+func TestIsOCI(t *testing.T) {
+	assert.Assert(t, IsOCI("oci://registry.example.com/charts/api"))
+	assert.Assert(t, !IsOCI("./chart"))
+	assert.Assert(t, !IsOCI("stable/nginx"))
+}
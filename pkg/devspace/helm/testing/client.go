@@ -35,11 +35,26 @@ func (f *Client) DeleteRelease(ctx devspacecontext.Context, releaseName string,
 	return fmt.Errorf("release %s not found", releaseName)
 }
 
+// RollbackRelease implements interface
+func (f *Client) RollbackRelease(ctx devspacecontext.Context, releaseName string, releaseNamespace string) error {
+	for _, release := range f.Releases {
+		if release.Name == releaseName {
+			return nil
+		}
+	}
+	return fmt.Errorf("release %s not found", releaseName)
+}
+
 // ListReleases lists all helm Releases
 func (f *Client) ListReleases(ctx devspacecontext.Context, releaseNamespace string) ([]*types.Release, error) {
 	return f.Releases, nil
 }
 
+// GetManifest implements interface
+func (f *Client) GetManifest(ctx devspacecontext.Context, releaseName string, releaseNamespace string) (string, error) {
+	return "", nil
+}
+
 // InstallChart implements interface
 func (f *Client) InstallChart(ctx devspacecontext.Context, releaseName string, releaseNamespace string, values map[string]interface{}, helmConfig *latest.HelmConfig) (*types.Release, error) {
 	for _, release := range f.Releases {
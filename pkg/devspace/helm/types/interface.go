@@ -11,7 +11,9 @@ type Client interface {
 	InstallChart(ctx devspacecontext.Context, releaseName string, releaseNamespace string, values map[string]interface{}, helmConfig *latest.HelmConfig) (*Release, error)
 	Template(ctx devspacecontext.Context, releaseName, releaseNamespace string, values map[string]interface{}, helmConfig *latest.HelmConfig) (string, error)
 	DeleteRelease(ctx devspacecontext.Context, releaseName string, releaseNamespace string) error
+	RollbackRelease(ctx devspacecontext.Context, releaseName string, releaseNamespace string) error
 	ListReleases(ctx devspacecontext.Context, releaseNamespace string) ([]*Release, error)
+	GetManifest(ctx devspacecontext.Context, releaseName string, releaseNamespace string) (string, error)
 }
 
 // Release is the helm release struct
@@ -1,20 +1,18 @@
 package v3
 
 import (
-	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	dependencyutil "github.com/loft-sh/devspace/pkg/devspace/dependency/util"
-	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/ghodss/yaml"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/helm/generic"
+	"github.com/loft-sh/devspace/pkg/devspace/helm/oci"
 	"github.com/loft-sh/devspace/pkg/devspace/helm/types"
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/utils/pkg/downloader/commands"
@@ -72,6 +70,14 @@ func (c *client) InstallChart(ctx devspacecontext.Context, releaseName string, r
 
 		chartPath = filepath.Dir(dependencyPath)
 		args = append(args, chartPath)
+	} else if oci.IsOCI(helmConfig.Chart.Name) {
+		chart, err := oci.Pull(ctx, helmConfig.Chart)
+		if err != nil {
+			return nil, err
+		}
+
+		chartPath = chart.Path
+		args = append(args, chartPath)
 	} else {
 		chartName, chartRepo := generic.ChartNameAndRepo(helmConfig)
 		chartPath = filepath.Join(ctx.WorkingDir(), chartName)
@@ -83,27 +89,11 @@ func (c *client) InstallChart(ctx devspacecontext.Context, releaseName string, r
 		if helmConfig.Chart.Version != "" {
 			args = append(args, "--version", helmConfig.Chart.Version)
 		}
-
-		// log into OCI registry if specified
-		if strings.HasPrefix(chartName, "oci://") {
-			if helmConfig.Chart.Username != "" && helmConfig.Chart.Password != "" {
-				chartNameURL, err := url.Parse(chartName)
-				if err != nil {
-					return nil, errors.Wrap(err, "chartName malformed for oci registry")
-				}
-
-				_, err = c.genericHelm.Exec(ctx, []string{"registry", "login", chartNameURL.Hostname(), "--username", helmConfig.Chart.Username, "--password", helmConfig.Chart.Password})
-				if err != nil {
-					return nil, errors.Wrap(err, "login oci registry")
-				}
-			}
-		} else {
-			if helmConfig.Chart.Username != "" {
-				args = append(args, "--username", helmConfig.Chart.Username)
-			}
-			if helmConfig.Chart.Password != "" {
-				args = append(args, "--password", helmConfig.Chart.Password)
-			}
+		if helmConfig.Chart.Username != "" {
+			args = append(args, "--username", helmConfig.Chart.Username)
+		}
+		if helmConfig.Chart.Password != "" {
+			args = append(args, "--password", helmConfig.Chart.Password)
 		}
 	}
 
@@ -119,6 +109,14 @@ func (c *client) InstallChart(ctx devspacecontext.Context, releaseName string, r
 			}
 		}
 	}
+	// Post renderer
+	postRendererFlags, cleanup, err := postRendererArgs(ctx, helmConfig.PostRenderer)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	args = append(args, postRendererFlags...)
+
 	// Upgrade options
 	args = append(args, helmConfig.UpgradeArgs...)
 	output, err := c.genericHelm.Exec(ctx, args)
@@ -176,6 +174,14 @@ func (c *client) Template(ctx devspacecontext.Context, releaseName, releaseNames
 
 		chartPath = filepath.Dir(dependencyPath)
 		args = append(args, chartPath)
+	} else if oci.IsOCI(helmConfig.Chart.Name) {
+		chart, err := oci.Pull(ctx, helmConfig.Chart)
+		if err != nil {
+			return "", err
+		}
+
+		chartPath = chart.Path
+		args = append(args, chartPath)
 	} else {
 		chartName, chartRepo := generic.ChartNameAndRepo(helmConfig)
 		chartPath = filepath.Join(ctx.WorkingDir(), chartName)
@@ -207,6 +213,12 @@ func (c *client) Template(ctx devspacecontext.Context, releaseName, releaseNames
 			}
 		}
 	}
+	postRendererFlags, cleanup, err := postRendererArgs(ctx, helmConfig.PostRenderer)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	args = append(args, postRendererFlags...)
 	args = append(args, helmConfig.TemplateArgs...)
 	result, err := c.genericHelm.Exec(ctx, args)
 	if err != nil {
@@ -236,6 +248,49 @@ func (c *client) DeleteRelease(ctx devspacecontext.Context, releaseName string,
 	return nil
 }
 
+// RollbackRelease rolls the release back to its previous revision
+func (c *client) RollbackRelease(ctx devspacecontext.Context, releaseName string, releaseNamespace string) error {
+	if releaseNamespace == "" {
+		releaseNamespace = ctx.KubeClient().Namespace()
+	}
+
+	args := []string{
+		"rollback",
+		releaseName,
+	}
+	if releaseNamespace != "" {
+		args = append(args, "--namespace", releaseNamespace)
+	}
+	_, err := c.genericHelm.Exec(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetManifest returns the manifest of the deployed release
+func (c *client) GetManifest(ctx devspacecontext.Context, releaseName string, releaseNamespace string) (string, error) {
+	if releaseNamespace == "" {
+		releaseNamespace = ctx.KubeClient().Namespace()
+	}
+
+	args := []string{
+		"get",
+		"manifest",
+		releaseName,
+	}
+	if releaseNamespace != "" {
+		args = append(args, "--namespace", releaseNamespace)
+	}
+	out, err := c.genericHelm.Exec(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
 func (c *client) ListReleases(ctx devspacecontext.Context, namespace string) ([]*types.Release, error) {
 	args := []string{
 		"list",
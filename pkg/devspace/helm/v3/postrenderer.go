@@ -0,0 +1,76 @@
+package v3
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/kustomize"
+	"github.com/loft-sh/utils/pkg/downloader"
+	"github.com/loft-sh/utils/pkg/downloader/commands"
+	"github.com/pkg/errors"
+)
+
+// postRendererArgs returns the helm flags for the post renderer of the helm config. The returned function
+// removes the files the post renderer needs after helm has finished
+func postRendererArgs(ctx devspacecontext.Context, postRenderer *latest.HelmPostRendererConfig) ([]string, func(), error) {
+	if postRenderer == nil {
+		return nil, func() {}, nil
+	} else if postRenderer.Kustomize == nil {
+		return postRendererFlags(resolveCommand(ctx, postRenderer.Command), postRenderer.Args), func() {}, nil
+	}
+
+	// helm can only run a binary, so devspace itself builds the overlay as post renderer
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "find devspace binary")
+	}
+
+	overlayDir, err := kustomize.NewPostRendererOverlay(ctx, postRenderer.Kustomize)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "create post renderer overlay")
+	}
+	cleanup := func() {
+		_ = os.RemoveAll(overlayDir)
+	}
+
+	args := []string{"--silent", "helm-post-renderer", overlayDir}
+	if postRenderer.Kustomize.KustomizeBinaryPath != "" {
+		args = append(args, "--kustomize", resolveCommand(ctx, postRenderer.Kustomize.KustomizeBinaryPath))
+	} else {
+		kubectlPath := resolveCommand(ctx, postRenderer.Kustomize.KubectlBinaryPath)
+		if kubectlPath == "" {
+			kubectlPath, err = downloader.NewDownloader(commands.NewKubectlCommand(), ctx.Log(), constants.DefaultHomeDevSpaceFolder).EnsureCommand(ctx.Context())
+			if err != nil {
+				cleanup()
+				return nil, nil, err
+			}
+		}
+
+		args = append(args, "--kubectl", kubectlPath)
+	}
+
+	return postRendererFlags(executable, args), cleanup, nil
+}
+
+func postRendererFlags(command string, args []string) []string {
+	flags := []string{"--post-renderer", command}
+	for _, arg := range args {
+		flags = append(flags, "--post-renderer-args", arg)
+	}
+
+	return flags
+}
+
+// resolveCommand resolves relative paths of commands against the working dir, commands without a path are
+// looked up in the PATH
+func resolveCommand(ctx devspacecontext.Context, command string) string {
+	if command == "" || filepath.IsAbs(command) || !strings.ContainsAny(command, `/\`) {
+		return command
+	}
+
+	return ctx.ResolvePath(command)
+}
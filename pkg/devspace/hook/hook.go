@@ -8,14 +8,15 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/plugin"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
 	logpkg "github.com/loft-sh/devspace/pkg/util/log"
-	"github.com/loft-sh/utils/pkg/command"
 	"github.com/mgutz/ansi"
 	dockerterm "github.com/moby/term"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"io"
 	"k8s.io/apimachinery/pkg/labels"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -48,10 +49,16 @@ type Hook interface {
 	Execute(ctx devspacecontext.Context, hook *latest.HookConfig, extraEnv map[string]string) error
 }
 
-// LogExecuteHooks executes plugin hooks and config hooks and prints errors to the log
-func LogExecuteHooks(ctx devspacecontext.Context, extraEnv map[string]interface{}, events ...string) {
+// LogExecuteHooks executes plugin hooks and config hooks and prints errors to the log. It only returns
+// an error if a plugin hook aborted, which the caller should treat as a failure
+func LogExecuteHooks(ctx devspacecontext.Context, extraEnv map[string]interface{}, events ...string) error {
 	// call plugin first
-	plugin.LogExecutePluginHookWithContext(extraEnv, events...)
+	mutations, err := plugin.ExecutePluginHookWithMutations(extraEnv, events...)
+	if plugin.IsAbort(err) {
+		return err
+	} else if err != nil {
+		logpkg.GetFileLogger("plugin").Errorf("%v", err)
+	}
 
 	// now execute hooks
 	if ctx != nil && ctx.Config() != nil {
@@ -59,21 +66,34 @@ func LogExecuteHooks(ctx devspacecontext.Context, extraEnv map[string]interface{
 			ctx = ctx.WithLogger(logpkg.GetInstance())
 		}
 
+		err = applyMutations(ctx, mutations)
+		if err != nil {
+			ctx.Log().Warn(err)
+		}
+
 		convertedExtraEnv := plugin.ConvertExtraEnv("DEVSPACE_HOOK", extraEnv)
+		if mutations != nil {
+			for k, v := range mutations.Env {
+				convertedExtraEnv[k] = v
+			}
+		}
+		executed := map[*latest.HookConfig]bool{}
 		for _, e := range events {
 			convertedExtraEnv["DEVSPACE_HOOK_EVENT"] = e
-			err := executeSingle(ctx, convertedExtraEnv, e)
+			err := executeSingle(ctx, convertedExtraEnv, e, executed)
 			if err != nil {
 				ctx.Log().Warn(err)
 			}
 		}
 	}
+
+	return nil
 }
 
 // ExecuteHooks executes plugin hooks and config hooks
 func ExecuteHooks(ctx devspacecontext.Context, extraEnv map[string]interface{}, events ...string) error {
 	// call plugin first
-	err := plugin.ExecutePluginHookWithContext(extraEnv, events...)
+	mutations, err := plugin.ExecutePluginHookWithMutations(extraEnv, events...)
 	if err != nil {
 		return err
 	}
@@ -84,10 +104,19 @@ func ExecuteHooks(ctx devspacecontext.Context, extraEnv map[string]interface{},
 			ctx = ctx.WithLogger(logpkg.GetInstance())
 		}
 
+		err = applyMutations(ctx, mutations)
+		if err != nil {
+			return err
+		}
+
 		convertedExtraEnv := plugin.ConvertExtraEnv("DEVSPACE_HOOK", extraEnv)
+		for k, v := range mutations.Env {
+			convertedExtraEnv[k] = v
+		}
+		executed := map[*latest.HookConfig]bool{}
 		for _, e := range events {
 			convertedExtraEnv["DEVSPACE_HOOK_EVENT"] = e
-			err := executeSingle(ctx, convertedExtraEnv, e)
+			err := executeSingle(ctx, convertedExtraEnv, e, executed)
 			if err != nil {
 				return err
 			}
@@ -97,8 +126,12 @@ func ExecuteHooks(ctx devspacecontext.Context, extraEnv map[string]interface{},
 	return nil
 }
 
-// executeSingle executes hooks at a specific time
-func executeSingle(ctx devspacecontext.Context, extraEnv map[string]string, event string) error {
+var onceHooksLock sync.Mutex
+var onceHooks = map[*latest.HookConfig]bool{}
+
+// executeSingle executes hooks at a specific time. Hooks that are contained in executed are skipped,
+// so that a hook matching multiple events of the same call is only executed once.
+func executeSingle(ctx devspacecontext.Context, extraEnv map[string]string, event string, executed map[*latest.HookConfig]bool) error {
 	config := ctx.Config()
 	if config == nil {
 		return nil
@@ -110,21 +143,38 @@ func executeSingle(ctx devspacecontext.Context, extraEnv map[string]string, even
 
 		// Gather all hooks we should execute
 		for _, hook := range c.Hooks {
+			if executed[hook] {
+				continue
+			}
+
 			for _, e := range hook.Events {
-				if e == event {
+				if plugin.MatchEvent(e, event) {
 					hooksToExecute = append(hooksToExecute, hook)
 					break
 				}
 			}
 		}
+		sort.SliceStable(hooksToExecute, func(i, j int) bool {
+			return hooksToExecute[i].Priority > hooksToExecute[j].Priority
+		})
 
 		// Execute hooks
 		for _, hookConfig := range hooksToExecute {
-			if hookConfig.Disabled || !command.ShouldExecuteOnOS(hookConfig.OperatingSystem) {
+			execute, err := shouldExecute(ctx, hookConfig, extraEnv)
+			if err != nil {
+				return errors.Wrapf(err, "evaluate conditions of hook '%s'", hookName(hookConfig))
+			} else if !execute {
 				continue
 			}
 
-			err := runHook(ctx, hookConfig, extraEnv, event)
+			// only mark hooks that actually run, so that a hook whose conditions don't match this event
+			// can still run for the other events of the same call
+			executed[hookConfig] = true
+			if hookConfig.Once && !markOnce(hookConfig) {
+				continue
+			}
+
+			err = runHook(ctx, hookConfig, extraEnv, event)
 			if err != nil {
 				return err
 			}
@@ -134,7 +184,25 @@ func executeSingle(ctx devspacecontext.Context, extraEnv map[string]string, even
 	return nil
 }
 
+// markOnce returns true if the hook was not marked before
+func markOnce(hookConfig *latest.HookConfig) bool {
+	onceHooksLock.Lock()
+	defer onceHooksLock.Unlock()
+
+	if onceHooks[hookConfig] {
+		return false
+	}
+
+	onceHooks[hookConfig] = true
+	return true
+}
+
 func runHook(ctx devspacecontext.Context, hookConfig *latest.HookConfig, extraEnv map[string]string, event string) error {
+	// Session hooks are kept running until the session ends
+	if hookConfig.Session != nil {
+		return startSessionHook(ctx, hookConfig, extraEnv, event)
+	}
+
 	// Determine output writer
 	var writer io.WriteCloser
 	if hookConfig.Silent {
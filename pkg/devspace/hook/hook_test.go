@@ -2,7 +2,14 @@ package hook
 
 import (
 	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/loft-sh/devspace/pkg/util/log"
@@ -13,6 +20,8 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
 	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 func TestHookWithoutExecution(t *testing.T) {
@@ -82,3 +91,361 @@ func TestHookWithExecution(t *testing.T) {
 		t.Fatalf("Failed to execute 1 hook with empty When.After: %v", err)
 	}
 }
+
+func TestHookWhen(t *testing.T) {
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		latest.NewRaw(),
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{"CI": "true"},
+		constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf)
+	extraEnv := map[string]string{"DEVSPACE_HOOK_EVENT": "after:deploy:my-app"}
+
+	testCases := []struct {
+		name     string
+		when     *latest.HookWhen
+		expected bool
+	}{
+		{
+			name:     "no conditions",
+			expected: true,
+		},
+		{
+			name:     "matching var",
+			when:     &latest.HookWhen{Vars: map[string]string{"CI": "true"}},
+			expected: true,
+		},
+		{
+			name:     "not matching var",
+			when:     &latest.HookWhen{Vars: map[string]string{"CI": "false"}},
+			expected: false,
+		},
+		{
+			name:     "matching event",
+			when:     &latest.HookWhen{Event: map[string]string{"event": "after:deploy:.*"}},
+			expected: true,
+		},
+		{
+			name:     "not matching event",
+			when:     &latest.HookWhen{Event: map[string]string{"event": "before:.*"}},
+			expected: false,
+		},
+		{
+			name:     "matching alternation",
+			when:     &latest.HookWhen{Vars: map[string]string{"CI": "false|true"}},
+			expected: true,
+		},
+		{
+			name:     "alternation matches the whole value",
+			when:     &latest.HookWhen{Vars: map[string]string{"CI": "tr|false"}},
+			expected: false,
+		},
+		{
+			name:     "anchored expression",
+			when:     &latest.HookWhen{Vars: map[string]string{"CI": "^true$"}},
+			expected: true,
+		},
+		{
+			name:     "not matching arch",
+			when:     &latest.HookWhen{Arch: "not-existing"},
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		match, err := shouldExecute(ctx, &latest.HookConfig{When: testCase.when}, extraEnv)
+		if err != nil {
+			t.Fatalf("Unexpected error in test case %s: %v", testCase.name, err)
+		}
+		if match != testCase.expected {
+			t.Fatalf("Unexpected result in test case %s: expected %v, got %v", testCase.name, testCase.expected, match)
+		}
+	}
+}
+
+func TestHookOutputVar(t *testing.T) {
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		&latest.Config{
+			Hooks: []*latest.HookConfig{
+				{
+					Events:    []string{"my-event"},
+					Command:   "echo",
+					Args:      []string{"my-token"},
+					OutputVar: "TOKEN",
+				},
+				{
+					Events:    []string{"my-event"},
+					Command:   "echo",
+					Args:      []string{`{"user": "admin"}`},
+					OutputVar: "CREDENTIALS",
+				},
+			},
+		},
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+
+	err := ExecuteHooks(devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf), nil, "my-event")
+	if err != nil {
+		t.Fatalf("Failed to execute hooks: %v", err)
+	}
+
+	runtimeVariables := conf.ListRuntimeVariables()
+	if runtimeVariables["TOKEN"] != "my-token" {
+		t.Fatalf("Unexpected runtime variable TOKEN: %v", runtimeVariables["TOKEN"])
+	}
+	if runtimeVariables["CREDENTIALS.user"] != "admin" {
+		t.Fatalf("Unexpected runtime variable CREDENTIALS.user: %v", runtimeVariables["CREDENTIALS.user"])
+	}
+}
+
+func TestHookWildcardOncePriority(t *testing.T) {
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		&latest.Config{
+			Hooks: []*latest.HookConfig{
+				{
+					Name:    "wildcard",
+					Events:  []string{"after:*Dependency"},
+					Command: "echo",
+					Args:    []string{"wildcard"},
+				},
+				{
+					Name:    "once",
+					Events:  []string{"before:deploy:*"},
+					Command: "echo",
+					Args:    []string{"once"},
+					Once:    true,
+				},
+			},
+		},
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf)
+
+	err := ExecuteHooks(ctx, nil, EventsForSingle("after:deployDependency", "my-dep")...)
+	if err != nil {
+		t.Fatalf("Failed to execute hooks: %v", err)
+	}
+	if conf.ListRuntimeVariables()["hooks.wildcard.stdout"] != "wildcard" {
+		t.Fatalf("Expected wildcard hook to be executed")
+	}
+
+	err = ExecuteHooks(ctx, nil, EventsForSingle("before:deploy", "my-app")...)
+	if err != nil {
+		t.Fatalf("Failed to execute hooks: %v", err)
+	}
+	conf.SetRuntimeVariable("hooks.once.stdout", "")
+	err = ExecuteHooks(ctx, nil, EventsForSingle("before:deploy", "my-app")...)
+	if err != nil {
+		t.Fatalf("Failed to execute hooks: %v", err)
+	}
+	if conf.ListRuntimeVariables()["hooks.once.stdout"] != "" {
+		t.Fatalf("Expected once hook to be executed only once")
+	}
+}
+
+func TestHookWhenMultipleEvents(t *testing.T) {
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		&latest.Config{
+			Hooks: []*latest.HookConfig{
+				{
+					Name:    "my-app",
+					Events:  []string{"before:deploy:*"},
+					Command: "echo",
+					Args:    []string{"my-app"},
+					When:    &latest.HookWhen{Event: map[string]string{"event": "before:deploy:my-app"}},
+				},
+			},
+		},
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf)
+
+	// the hook doesn't match the before:deploy:* event, but still has to run for before:deploy:my-app
+	err := ExecuteHooks(ctx, nil, EventsForSingle("before:deploy", "my-app")...)
+	if err != nil {
+		t.Fatalf("Failed to execute hooks: %v", err)
+	}
+	if conf.ListRuntimeVariables()["hooks.my-app.stdout"] != "my-app" {
+		t.Fatalf("Expected hook to be executed")
+	}
+}
+
+func TestApplyMutations(t *testing.T) {
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		&latest.Config{
+			Name:   "my-project",
+			Images: map[string]*latest.Image{"base": {Image: "base"}},
+		},
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+
+	err := applyMutations(devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf), &plugin.HookMutations{
+		Patches: []*latest.PatchConfig{
+			{
+				Operation: "replace",
+				Path:      "name",
+				Value:     "patched",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to apply mutations: %v", err)
+	}
+	if conf.Config().Name != "patched" {
+		t.Fatalf("Expected config name to be patched, got %s", conf.Config().Name)
+	}
+
+	// concurrent mutations are applied one after another
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			err := applyMutations(devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf), &plugin.HookMutations{
+				Patches: []*latest.PatchConfig{
+					{
+						Operation: "add",
+						Path:      "images.image-" + strconv.Itoa(i),
+						Value:     map[string]interface{}{"image": "my-image"},
+					},
+				},
+			})
+			if err != nil {
+				t.Errorf("Failed to apply mutations: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(conf.Config().Images) != 11 {
+		t.Fatalf("Expected 11 images after concurrent mutations, got %d", len(conf.Config().Images))
+	}
+}
+
+// abortPlugin is a plugin hook using the json protocol that aborts deployments and patches the config name
+// before builds
+const abortPlugin = `#!/bin/sh
+case "$DEVSPACE_PLUGIN_EVENT" in
+  before:deploy) echo '{"abort":true,"message":"deployments are frozen"}' ;;
+  *) echo '{"patches":[{"op":"replace","path":"name","value":"patched"}]}' ;;
+esac
+`
+
+func TestPluginHookAbort(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin binary is a shell script")
+	}
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, plugin.PluginBinary), []byte(abortPlugin), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugin.SetPlugins([]plugin.Metadata{{
+		Name:         "freeze",
+		PluginFolder: dir,
+		Hooks: []plugin.Hook{
+			{Event: "before:deploy", Protocol: plugin.ProtocolJSON},
+			{Event: "before:build", Protocol: plugin.ProtocolJSON},
+		},
+	}})
+
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		&latest.Config{Name: "my-project"},
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf)
+
+	err = ExecuteHooks(ctx, nil, "before:deploy")
+	if !plugin.IsAbort(err) {
+		t.Fatalf("Expected abort error, got %v", err)
+	}
+	err = LogExecuteHooks(ctx, nil, "before:deploy")
+	if !plugin.IsAbort(err) {
+		t.Fatalf("Expected abort error, got %v", err)
+	}
+
+	err = LogExecuteHooks(ctx, nil, "before:build")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if conf.Config().Name != "patched" {
+		t.Fatalf("Expected config name to be patched, got %s", conf.Config().Name)
+	}
+}
+
+func TestSessionHook(t *testing.T) {
+	maxRestarts := 1
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		&latest.Config{
+			Hooks: []*latest.HookConfig{
+				{
+					Name:    "session",
+					Events:  []string{"my-event"},
+					Command: "echo",
+					Args:    []string{"running"},
+					Session: &latest.HookSessionConfig{
+						RestartDelay: 1,
+						MaxRestarts:  &maxRestarts,
+					},
+				},
+			},
+		},
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+
+	err := ExecuteHooks(devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf), nil, "my-event")
+	if err != nil {
+		t.Fatalf("Failed to execute hooks: %v", err)
+	}
+
+	defer StopSessionHooks()
+	err = wait.PollImmediate(time.Millisecond*100, time.Second*10, func() (bool, error) {
+		return conf.ListRuntimeVariables()["hooks.session.stdout"] == "running", nil
+	})
+	if err != nil {
+		t.Fatalf("Expected session hook to be executed")
+	}
+}
+
+func TestSessionHookOutputTail(t *testing.T) {
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		latest.NewRaw(),
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf)
+
+	hook := &localCommandHook{Stdout: io.Discard, Stderr: io.Discard, OutputTail: 16}
+	err := hook.Execute(ctx, &latest.HookConfig{Name: "session", Command: "echo first line; echo second line; echo last"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to execute hook: %v", err)
+	}
+
+	expected := "[23 bytes of earlier output omitted]\nlast"
+	if stdout := conf.ListRuntimeVariables()["hooks.session.stdout"]; stdout != expected {
+		t.Fatalf("Expected only the tail of the output, got %q", stdout)
+	}
+}
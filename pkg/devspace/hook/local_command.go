@@ -9,10 +9,10 @@ import (
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine"
 	"github.com/loft-sh/devspace/pkg/devspace/pipeline/env"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
 	"io"
 	"os"
 	"regexp"
-	"strings"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
@@ -28,9 +28,39 @@ func NewLocalCommandHook(stdout io.Writer, stderr io.Writer) Hook {
 	}
 }
 
+// sessionOutputTailSize is the amount of output bytes kept of session hooks, which can run for the
+// whole session
+const sessionOutputTailSize = 64 * 1024
+
+// newSessionCommandHook creates a local command hook that only keeps the tail of its output
+func newSessionCommandHook(stdout io.Writer, stderr io.Writer) Hook {
+	return &localCommandHook{
+		Stdout:     stdout,
+		Stderr:     stderr,
+		OutputTail: sessionOutputTailSize,
+	}
+}
+
 type localCommandHook struct {
 	Stdout io.Writer
 	Stderr io.Writer
+
+	// OutputTail limits the captured output to the last bytes, if greater than zero
+	OutputTail int
+}
+
+// outputBuffer captures the output of a hook
+type outputBuffer interface {
+	io.Writer
+	String() string
+}
+
+func (l *localCommandHook) newOutputBuffer() outputBuffer {
+	if l.OutputTail > 0 {
+		return logpkg.NewTailBuffer(l.OutputTail)
+	}
+
+	return &bytes.Buffer{}
 }
 
 var EnvironmentVariableRegEx = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
@@ -66,21 +96,16 @@ func (l *localCommandHook) Execute(ctx devspacecontext.Context, hook *latest.Hoo
 	}
 
 	// if args are nil we execute the command in a shell
-	stdout := &bytes.Buffer{}
-	stderr := &bytes.Buffer{}
-	defer func() {
-		if hook.Name != "" {
-			ctx.Config().SetRuntimeVariable("hooks."+hook.Name+".stdout", strings.TrimSpace(stdout.String()))
-			ctx.Config().SetRuntimeVariable("hooks."+hook.Name+".stderr", strings.TrimSpace(stderr.String()))
-		}
-	}()
-
+	stdout := l.newOutputBuffer()
+	stderr := l.newOutputBuffer()
 	if hook.Args == nil {
-		return engine.ExecuteSimpleShellCommand(ctx.Context(), ctx.WorkingDir(), env.NewVariableEnvProvider(ctx.Environ(), extraEnv), io.MultiWriter(l.Stdout, stdout), io.MultiWriter(l.Stderr, stderr), nil, hookCommand)
+		err = engine.ExecuteSimpleShellCommand(ctx.Context(), ctx.WorkingDir(), env.NewVariableEnvProvider(ctx.Environ(), extraEnv), io.MultiWriter(l.Stdout, stdout), io.MultiWriter(l.Stderr, stderr), nil, hookCommand)
+	} else {
+		// else we execute it directly
+		err = command.Command(ctx.Context(), ctx.WorkingDir(), env.NewVariableEnvProvider(ctx.Environ(), extraEnv), io.MultiWriter(l.Stdout, stdout), io.MultiWriter(l.Stderr, stderr), nil, hookCommand, hookArgs...)
 	}
 
-	// else we execute it directly
-	return command.Command(ctx.Context(), ctx.WorkingDir(), env.NewVariableEnvProvider(ctx.Environ(), extraEnv), io.MultiWriter(l.Stdout, stdout), io.MultiWriter(l.Stderr, stderr), nil, hookCommand, hookArgs...)
+	return setRuntimeVariables(ctx, hook, stdout.String(), stderr.String(), err)
 }
 
 func ResolveCommand(ctx context.Context, command string, args []string, dir string, config config.Config, dependencies []types.Dependency) (string, []string, error) {
@@ -0,0 +1,68 @@
+package hook
+
+import (
+	"sync"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader/patch"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// mutationsLock makes sure that concurrent hooks don't overwrite each others mutations
+var mutationsLock sync.Mutex
+
+// applyMutations applies the config patches that were returned by plugin hooks to the
+// currently loaded config. The patched config replaces the loaded config, so callers that
+// still hold the previous config keep reading a consistent version of it
+func applyMutations(ctx devspacecontext.Context, mutations *plugin.HookMutations) error {
+	if mutations == nil || len(mutations.Patches) == 0 || ctx == nil || ctx.Config() == nil || ctx.Config().Config() == nil {
+		return nil
+	}
+
+	mutationsLock.Lock()
+	defer mutationsLock.Unlock()
+
+	c := ctx.Config().Config()
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	patches := patch.Patch{}
+	for idx, patchConfig := range mutations.Patches {
+		if patchConfig.Operation == "" || patchConfig.Path == "" {
+			return errors.Errorf("plugin hook patches.%d: op and path are required", idx)
+		}
+
+		newPatch := patch.Operation{
+			Op:   patch.Op(patchConfig.Operation),
+			Path: patch.OpPath(patch.TransformPath(patchConfig.Path)),
+		}
+		if patchConfig.Value != nil {
+			value, err := patch.NewNode(&patchConfig.Value)
+			if err != nil {
+				return errors.Errorf("plugin hook patches.%d.value is invalid", idx)
+			}
+			newPatch.Value = value
+		}
+
+		patches = append(patches, newPatch)
+	}
+
+	out, err = patches.Apply(out)
+	if err != nil {
+		return errors.Wrap(err, "apply plugin hook patches")
+	}
+
+	newConfig := &latest.Config{}
+	err = yaml.Unmarshal(out, newConfig)
+	if err != nil {
+		return err
+	}
+
+	ctx.Config().SetConfig(newConfig)
+	return nil
+}
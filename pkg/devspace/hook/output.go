@@ -0,0 +1,43 @@
+package hook
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/pkg/errors"
+)
+
+// setRuntimeVariables saves the captured output of a hook as runtime variables. If the hook
+// has defined an output variable and did execute successfully, stdout is stored under that name.
+// If stdout is a json object, every top level field is additionally stored as NAME.FIELD.
+func setRuntimeVariables(ctx devspacecontext.Context, hook *latest.HookConfig, stdout, stderr string, execErr error) error {
+	stdout = strings.TrimSpace(stdout)
+	stderr = strings.TrimSpace(stderr)
+	if hook.Name != "" {
+		ctx.Config().SetRuntimeVariable("hooks."+hook.Name+".stdout", stdout)
+		ctx.Config().SetRuntimeVariable("hooks."+hook.Name+".stderr", stderr)
+	}
+	if execErr != nil || hook.OutputVar == "" {
+		return execErr
+	}
+
+	// check if the output is a json object
+	if strings.HasPrefix(stdout, "{") {
+		obj := map[string]interface{}{}
+		err := json.Unmarshal([]byte(stdout), &obj)
+		if err != nil {
+			return errors.Wrapf(err, "parse output of hook '%s' as json", hookName(hook))
+		}
+
+		ctx.Config().SetRuntimeVariable(hook.OutputVar, obj)
+		for k, v := range obj {
+			ctx.Config().SetRuntimeVariable(hook.OutputVar+"."+k, v)
+		}
+		return nil
+	}
+
+	ctx.Config().SetRuntimeVariable(hook.OutputVar, stdout)
+	return nil
+}
@@ -59,13 +59,6 @@ func (r *remoteCommandHook) ExecuteRemotely(ctx devspacecontext.Context, hook *l
 	// if args are nil we execute the command in a shell
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
-	defer func() {
-		if hook.Name != "" {
-			ctx.Config().SetRuntimeVariable("hooks."+hook.Name+".stdout", strings.TrimSpace(stdout.String()))
-			ctx.Config().SetRuntimeVariable("hooks."+hook.Name+".stderr", strings.TrimSpace(stderr.String()))
-		}
-	}()
-
 	ctx.Log().Infof("Execute hook '%s' in container '%s/%s/%s'", ansi.Color(hookName(hook), "white+b"), podContainer.Pod.Namespace, podContainer.Pod.Name, podContainer.Container.Name)
 	err = ctx.KubeClient().ExecStream(ctx.Context(), &kubectl.ExecStreamOptions{
 		Pod:       podContainer.Pod,
@@ -74,6 +67,7 @@ func (r *remoteCommandHook) ExecuteRemotely(ctx devspacecontext.Context, hook *l
 		Stdout:    io.MultiWriter(r.Stdout, stdout),
 		Stderr:    io.MultiWriter(r.Stderr, stderr),
 	})
+	err = setRuntimeVariables(ctx, hook, stdout.String(), stderr.String(), err)
 	if err != nil {
 		return errors.Errorf("error in container '%s/%s/%s': %v", podContainer.Pod.Namespace, podContainer.Pod.Name, podContainer.Container.Name, err)
 	}
@@ -0,0 +1,86 @@
+package hook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/mgutz/ansi"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	sessionHooksLock sync.Mutex
+	sessionHooksWait sync.WaitGroup
+	sessionHooks     = map[*latest.HookConfig]context.CancelFunc{}
+)
+
+// startSessionHook starts the hook command in the background and restarts it whenever it
+// exits until StopSessionHooks is called
+func startSessionHook(ctx devspacecontext.Context, hookConfig *latest.HookConfig, extraEnv map[string]string, event string) error {
+	sessionHooksLock.Lock()
+	defer sessionHooksLock.Unlock()
+
+	if _, ok := sessionHooks[hookConfig]; ok {
+		ctx.Log().Debugf("Session hook '%s' is already running", hookName(hookConfig))
+		return nil
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	sessionHooks[hookConfig] = cancel
+
+	restartDelay := time.Second * 2
+	if hookConfig.Session.RestartDelay > 0 {
+		restartDelay = time.Second * time.Duration(hookConfig.Session.RestartDelay)
+	}
+
+	hookLog := ctx.Log().WithPrefix("hook:" + hookName(hookConfig) + " ")
+	ctx.Log().Infof("Start session hook '%s' at %s", ansi.Color(hookName(hookConfig), "white+b"), ansi.Color(event, "white+b"))
+	sessionHooksWait.Add(1)
+	go func() {
+		defer sessionHooksWait.Done()
+
+		writer := hookLog.Writer(logrus.InfoLevel, false)
+		defer writer.Close()
+
+		hookCtx := ctx.WithContext(cancelCtx).WithLogger(hookLog)
+		for restarts := 0; ; restarts++ {
+			err := newSessionCommandHook(writer, writer).Execute(hookCtx, hookConfig, extraEnv)
+			if cancelCtx.Err() != nil {
+				return
+			}
+
+			if hookConfig.Session.MaxRestarts != nil && restarts >= *hookConfig.Session.MaxRestarts {
+				hookLog.Errorf("Session hook exited (%v), giving up after %d restarts", err, restarts)
+				return
+			}
+			if err != nil {
+				hookLog.Warnf("Session hook exited with error: %v, restarting in %s", err, restartDelay.String())
+			} else {
+				hookLog.Infof("Session hook exited, restarting in %s", restartDelay.String())
+			}
+
+			select {
+			case <-cancelCtx.Done():
+				return
+			case <-time.After(restartDelay):
+			}
+		}
+	}()
+
+	return nil
+}
+
+// StopSessionHooks terminates all running session hooks and waits until they have exited
+func StopSessionHooks() {
+	sessionHooksLock.Lock()
+	for hookConfig, cancel := range sessionHooks {
+		cancel()
+		delete(sessionHooks, hookConfig)
+	}
+	sessionHooksLock.Unlock()
+
+	sessionHooksWait.Wait()
+}
@@ -0,0 +1,94 @@
+package hook
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/utils/pkg/command"
+	"github.com/pkg/errors"
+)
+
+// shouldExecute checks if the hook is enabled and all of its conditions match
+func shouldExecute(ctx devspacecontext.Context, hookConfig *latest.HookConfig, extraEnv map[string]string) (bool, error) {
+	if hookConfig.Disabled || !command.ShouldExecuteOnOS(hookConfig.OperatingSystem) {
+		return false, nil
+	}
+
+	return matchWhen(ctx, hookConfig.When, extraEnv)
+}
+
+func matchWhen(ctx devspacecontext.Context, when *latest.HookWhen, extraEnv map[string]string) (bool, error) {
+	if when == nil {
+		return true, nil
+	}
+	if !command.ShouldExecuteOnOS(when.OperatingSystem) || !matchArch(when.Arch) {
+		return false, nil
+	}
+
+	for k, v := range when.Environment {
+		match, err := matchExpression(v, os.Getenv(k))
+		if err != nil {
+			return false, errors.Wrapf(err, "match env %s", k)
+		} else if !match {
+			return false, nil
+		}
+	}
+
+	var variables map[string]interface{}
+	if ctx.Config() != nil {
+		variables = ctx.Config().Variables()
+	}
+	for k, v := range when.Vars {
+		value := ""
+		if variables[k] != nil {
+			value = fmt.Sprintf("%v", variables[k])
+		}
+
+		match, err := matchExpression(v, value)
+		if err != nil {
+			return false, errors.Wrapf(err, "match var %s", k)
+		} else if !match {
+			return false, nil
+		}
+	}
+
+	for k, v := range when.Event {
+		key := strings.ToUpper(strings.TrimSpace(k))
+		if !strings.HasPrefix(key, "DEVSPACE_HOOK_") {
+			key = "DEVSPACE_HOOK_" + key
+		}
+
+		match, err := matchExpression(v, extraEnv[key])
+		if err != nil {
+			return false, errors.Wrapf(err, "match event field %s", k)
+		} else if !match {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func matchArch(arch string) bool {
+	if arch == "" {
+		return true
+	}
+
+	for _, a := range strings.Split(arch, ",") {
+		if strings.TrimSpace(a) == runtime.GOARCH {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchExpression checks if the whole value matches the regular expression
+func matchExpression(expression, value string) (bool, error) {
+	return regexp.MatchString("^(?:"+expression+")$", value)
+}
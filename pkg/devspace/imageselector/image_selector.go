@@ -79,6 +79,11 @@ func Resolve(configImageName string, config config.Config, dependencies []types.
 func CompareImageNames(selector string, image2 string) bool {
 	image1 := selector
 
+	// images pinned to a digest are matched by their tag
+	if i := strings.Index(image2, "@"); i != -1 && !strings.Contains(image1, "@") {
+		image2 = image2[:i]
+	}
+
 	// we replace possible # with a's here to avoid an parsing error
 	// since the tag is stripped anyways it doesn't really matter if we lose
 	// information where the # were
@@ -0,0 +1,172 @@
+package kubectl
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// authWarningInterval is the minimum time between two re-authentication warnings
+var authWarningInterval = time.Minute
+
+var (
+	impersonateMutex  sync.Mutex
+	impersonateUser   string
+	impersonateGroups []string
+)
+
+// SetImpersonation sets the user and groups that all newly created kube clients should impersonate
+func SetImpersonation(user string, groups []string) {
+	impersonateMutex.Lock()
+	defer impersonateMutex.Unlock()
+
+	impersonateUser = user
+	impersonateGroups = groups
+}
+
+// impersonation returns the user and groups that kube clients should impersonate
+func impersonation() (string, []string) {
+	impersonateMutex.Lock()
+	defer impersonateMutex.Unlock()
+
+	return impersonateUser, append([]string{}, impersonateGroups...)
+}
+
+// withImpersonation returns a client config for the kube context that impersonates the user and groups
+// of the impersonation flags, so that everything derived from the client config impersonates them
+func withImpersonation(clientConfig clientcmd.ClientConfig, kubeContext string) (clientcmd.ClientConfig, error) {
+	user, groups := impersonation()
+	if user == "" && len(groups) == 0 {
+		return clientConfig, nil
+	}
+
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := &clientcmd.ConfigOverrides{
+		AuthInfo: clientcmdapi.AuthInfo{
+			Impersonate:       user,
+			ImpersonateGroups: groups,
+		},
+	}
+	return clientcmd.NewNonInteractiveClientConfig(rawConfig, kubeContext, overrides, clientcmd.NewDefaultClientConfigLoadingRules()), nil
+}
+
+// ImpersonateKubeConfig sets the user and groups of the impersonation flags for all users of the kube
+// config, so that tools started with the kube config impersonate them as well
+func ImpersonateKubeConfig(kubeConfig *clientcmdapi.Config) {
+	user, groups := impersonation()
+	for _, authInfo := range kubeConfig.AuthInfos {
+		if user != "" {
+			authInfo.Impersonate = user
+		}
+		if len(groups) > 0 {
+			authInfo.ImpersonateGroups = groups
+		}
+	}
+}
+
+// ImpersonationArgs returns the command line flags that pass the impersonation flags to a tool such as
+// kubectl (--as, --as-group) or helm (--kube-as-user, --kube-as-group)
+func ImpersonationArgs(userFlag, groupFlag string) []string {
+	user, groups := impersonation()
+
+	args := []string{}
+	if user != "" {
+		args = append(args, userFlag, user)
+	}
+	for _, group := range groups {
+		args = append(args, groupFlag, group)
+	}
+	return args
+}
+
+// authRoundTripper retries requests that failed because of expired credentials with credentials
+// that are freshly loaded from the kube config. This allows long running sessions to survive
+// expired oidc tokens, if the user re-authenticates in the meantime
+type authRoundTripper struct {
+	kubeContext string
+	reload      func() (http.RoundTripper, error)
+
+	mutex        sync.Mutex
+	roundTripper http.RoundTripper
+	lastWarning  time.Time
+}
+
+func newAuthRoundTripper(roundTripper http.RoundTripper, kubeContext string, reload func() (http.RoundTripper, error)) *authRoundTripper {
+	return &authRoundTripper{
+		kubeContext:  kubeContext,
+		reload:       reload,
+		roundTripper: roundTripper,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (a *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	a.mutex.Lock()
+	roundTripper := a.roundTripper
+	a.mutex.Unlock()
+
+	resp, err := roundTripper.RoundTrip(req)
+	if !isAuthFailure(resp, err) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return resp, err
+	}
+
+	// reload the credentials and try again
+	newRoundTripper, reloadErr := a.reload()
+	if reloadErr == nil {
+		retryReq := req.Clone(req.Context())
+		if req.GetBody != nil {
+			retryReq.Body, reloadErr = req.GetBody()
+		}
+		if reloadErr == nil {
+			retryResp, retryErr := newRoundTripper.RoundTrip(retryReq)
+			if !isAuthFailure(retryResp, retryErr) {
+				if resp != nil && resp.Body != nil {
+					_ = resp.Body.Close()
+				}
+
+				a.mutex.Lock()
+				a.roundTripper = newRoundTripper
+				a.mutex.Unlock()
+				return retryResp, retryErr
+			} else if retryResp != nil && retryResp.Body != nil {
+				_ = retryResp.Body.Close()
+			}
+		}
+	}
+
+	a.warn()
+	return resp, err
+}
+
+func (a *authRoundTripper) warn() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if time.Since(a.lastWarning) < authWarningInterval {
+		return
+	}
+
+	a.lastWarning = time.Now()
+	log.GetInstance().Warnf("The credentials for kube context '%s' have expired or are invalid. Please re-authenticate (e.g. log in again with your oidc provider), DevSpace will pick up the new credentials automatically", a.kubeContext)
+}
+
+// isAuthFailure checks if the response or error indicates expired or invalid credentials
+func isAuthFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		msg := strings.ToLower(err.Error())
+		return strings.Contains(msg, "failed to refresh token") ||
+			strings.Contains(msg, "getting credentials") ||
+			strings.Contains(msg, "oidc")
+	}
+
+	return resp != nil && resp.StatusCode == http.StatusUnauthorized
+}
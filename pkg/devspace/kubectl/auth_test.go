@@ -0,0 +1,85 @@
+package kubectl
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+type fakeRoundTripper struct {
+	statusCode int
+	requests   int
+}
+
+func (f *fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.requests++
+	return &http.Response{
+		StatusCode: f.statusCode,
+		Body:       io.NopCloser(strings.NewReader("")),
+	}, nil
+}
+
+func TestAuthRoundTripper(t *testing.T) {
+	expired := &fakeRoundTripper{statusCode: http.StatusUnauthorized}
+	refreshed := &fakeRoundTripper{statusCode: http.StatusOK}
+	reloads := 0
+
+	roundTripper := newAuthRoundTripper(expired, "test", func() (http.RoundTripper, error) {
+		reloads++
+		return refreshed, nil
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "https://localhost/api", nil)
+	assert.NilError(t, err)
+
+	resp, err := roundTripper.RoundTrip(req)
+	assert.NilError(t, err)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	assert.Equal(t, reloads, 1)
+
+	// the refreshed credentials should be used from now on
+	resp, err = roundTripper.RoundTrip(req)
+	assert.NilError(t, err)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	assert.Equal(t, reloads, 1)
+	assert.Equal(t, expired.requests, 1)
+	assert.Equal(t, refreshed.requests, 2)
+}
+
+func TestImpersonation(t *testing.T) {
+	defer SetImpersonation("", nil)
+
+	kubeConfig := clientcmdapi.NewConfig()
+	kubeConfig.Clusters["cluster"] = &clientcmdapi.Cluster{Server: "https://127.0.0.1:6443"}
+	kubeConfig.AuthInfos["user"] = &clientcmdapi.AuthInfo{Token: "token"}
+	kubeConfig.Contexts["context"] = &clientcmdapi.Context{Cluster: "cluster", AuthInfo: "user"}
+	clientConfig := clientcmd.NewNonInteractiveClientConfig(*kubeConfig, "context", &clientcmd.ConfigOverrides{}, nil)
+
+	impersonated, err := withImpersonation(clientConfig, "context")
+	assert.NilError(t, err)
+	restConfig, err := impersonated.ClientConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, restConfig.Impersonate.UserName, "")
+	assert.DeepEqual(t, ImpersonationArgs("--as", "--as-group"), []string{})
+
+	// the client config, the kube config and the flags for kubectl and helm impersonate the user
+	SetImpersonation("jane", []string{"developers"})
+	impersonated, err = withImpersonation(clientConfig, "context")
+	assert.NilError(t, err)
+	restConfig, err = impersonated.ClientConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, restConfig.Impersonate.UserName, "jane")
+	assert.DeepEqual(t, restConfig.Impersonate.Groups, []string{"developers"})
+
+	ImpersonateKubeConfig(kubeConfig)
+	assert.Equal(t, kubeConfig.AuthInfos["user"].Impersonate, "jane")
+	assert.DeepEqual(t, kubeConfig.AuthInfos["user"].ImpersonateGroups, []string{"developers"})
+
+	assert.DeepEqual(t, ImpersonationArgs("--as", "--as-group"), []string{"--as", "jane", "--as-group", "developers"})
+	assert.DeepEqual(t, ImpersonationArgs("--kube-as-user", "--kube-as-group"), []string{"--kube-as-user", "jane", "--kube-as-group", "developers"})
+}
@@ -107,25 +107,40 @@ func NewClientFromContext(context, namespace string, switchContext bool, kubeLoa
 	if err != nil {
 		return nil, err
 	}
+	clientConfig, err = withImpersonation(clientConfig, activeContext)
+	if err != nil {
+		return nil, err
+	}
 
-	restConfig, err := clientConfig.ClientConfig()
+	restConfig, err := newRestConfig(clientConfig)
 	if err != nil {
 		return nil, err
 	}
-	restConfig.UserAgent = "DevSpace Version " + upgrade.GetVersion()
-	restConfig.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
-		return &devSpaceRoundTripper{
-			roundTripper: rt,
-			requestType:  "Regular",
-			callback: func(response *http.Response) {
-				if response.Header.Get("X-DevSpace-Response-Type") == "Blocked" {
-					kill.StopDevSpace("Targeted Kubernetes environment has begun sleeping. Please restart DevSpace to wake up the environment")
-				}
-			},
-		}
+
+	// reload the credentials from the kube config if they have expired
+	httpClient, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "new http client")
 	}
+	httpClient.Transport = newAuthRoundTripper(httpClient.Transport, activeContext, func() (http.RoundTripper, error) {
+		clientConfig, _, _, _, err := util.NewClientByContext(activeContext, activeNamespace, false, kubeLoader)
+		if err != nil {
+			return nil, err
+		}
+		clientConfig, err = withImpersonation(clientConfig, activeContext)
+		if err != nil {
+			return nil, err
+		}
 
-	kubeClient, err := kubernetes.NewForConfig(restConfig)
+		restConfig, err := newRestConfig(clientConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return rest.TransportFor(restConfig)
+	})
+
+	kubeClient, err := kubernetes.NewForConfigAndClient(restConfig, httpClient)
 	if err != nil {
 		return nil, errors.Wrap(err, "new client")
 	}
@@ -142,6 +157,28 @@ func NewClientFromContext(context, namespace string, switchContext bool, kubeLoa
 	}, nil
 }
 
+func newRestConfig(clientConfig clientcmd.ClientConfig) (*rest.Config, error) {
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig.UserAgent = "DevSpace Version " + upgrade.GetVersion()
+	restConfig.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		return &devSpaceRoundTripper{
+			roundTripper: rt,
+			requestType:  "Regular",
+			callback: func(response *http.Response) {
+				if response.Header.Get("X-DevSpace-Response-Type") == "Blocked" {
+					kill.StopDevSpace("Targeted Kubernetes environment has begun sleeping. Please restart DevSpace to wake up the environment")
+				}
+			},
+		}
+	}
+
+	return restConfig, nil
+}
+
 // ClientConfig returns the underlying kube client config
 func (client *client) ClientConfig() clientcmd.ClientConfig {
 	return client.clientConfig
@@ -37,11 +37,6 @@ func (client *client) execStreamWithTransport(ctx context.Context, options *Exec
 		options.SubResource = SubResourceExec
 	}
 
-	wrapper, upgradeRoundTripper, err := GetUpgraderWrapper(client)
-	if err != nil {
-		return err
-	}
-
 	execRequest := client.KubeClient().CoreV1().RESTClient().Post().
 		Resource("pods").
 		Name(options.Pod.Name).
@@ -98,7 +93,7 @@ func (client *client) execStreamWithTransport(ctx context.Context, options *Exec
 		}, scheme.ParameterCodec)
 	}
 
-	exec, err := remotecommand.NewSPDYExecutorForTransports(wrapper, upgradeRoundTripper, "POST", execRequest.URL())
+	exec, err := NewExecutor(client, execRequest.URL())
 	if err != nil {
 		return err
 	}
@@ -112,7 +107,7 @@ func (client *client) execStreamWithTransport(ctx context.Context, options *Exec
 
 	select {
 	case <-ctx.Done():
-		upgradeRoundTripper.Close()
+		_ = exec.Close()
 		<-errChan
 		return nil
 	case err = <-errChan:
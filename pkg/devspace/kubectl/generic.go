@@ -2,11 +2,11 @@ package kubectl
 
 import (
 	"context"
-	"fmt"
 	"k8s.io/client-go/discovery"
 	"strings"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -57,7 +57,7 @@ func (client *client) GenericRequest(ctx context.Context, options *GenericReques
 		}
 
 		if options.Resource == "" {
-			return "", fmt.Errorf("couldn't find resource for kind %s in api version %s", options.Kind, options.APIVersion)
+			return "", &meta.NoKindMatchError{GroupKind: schema.FromAPIVersionAndKind(options.APIVersion, options.Kind).GroupKind(), SearchedVersions: []string{options.APIVersion}}
 		}
 	}
 
@@ -397,6 +397,7 @@ func (pf *PortForwarder) handleConnection(conn io.ReadWriteCloser, port Forwarde
 		// runtime.HandleError(fmt.Errorf("error creating forwarding stream for port %d -> %d: %v", port.Local, port.Remote, err))
 		return
 	}
+	defer pf.streamConn.RemoveStreams(errorStream, dataStream)
 
 	localError := make(chan struct{})
 	remoteDone := make(chan struct{})
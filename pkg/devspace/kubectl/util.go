@@ -194,7 +194,14 @@ func NewPortForwarder(client Client, pod *corev1.Pod, ports []string, addresses
 	}
 
 	logFile := log.GetFileLogger("portforwarding")
-	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", execRequest.URL())
+	dialer := &fallbackDialer{
+		config: client.RestConfig(),
+		spdy:   spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", execRequest.URL()),
+		websocket: &websocketDialer{
+			config: client.RestConfig(),
+			url:    execRequest.URL(),
+		},
+	}
 
 	fw, err := portforward.NewOnAddresses(dialer, addresses, ports, stopChan, readyChan, errorChan, logFile.Writer(logrus.InfoLevel, false), logFile.Writer(logrus.WarnLevel, false))
 	if err != nil {
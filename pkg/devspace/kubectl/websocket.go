@@ -0,0 +1,448 @@
+package kubectl
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiremotecommand "k8s.io/apimachinery/pkg/util/remotecommand"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+const (
+	// StreamProtocolEnv can be used to force a streaming protocol for exec, attach and port-forward.
+	// Valid values are spdy and websocket, if empty devspace will try spdy first and fall back to websocket
+	StreamProtocolEnv = "DEVSPACE_STREAM_PROTOCOL"
+
+	// StreamProtocolSPDY uses spdy for streaming connections
+	StreamProtocolSPDY = "spdy"
+	// StreamProtocolWebsocket uses websockets for streaming connections
+	StreamProtocolWebsocket = "websocket"
+)
+
+const (
+	channelWebsocketProtocolV5 = "v5.channel.k8s.io"
+	channelWebsocketProtocolV4 = "v4.channel.k8s.io"
+
+	streamStdin  byte = 0
+	streamStdout byte = 1
+	streamStderr byte = 2
+	streamError  byte = 3
+	streamResize byte = 4
+	streamClose  byte = 255
+)
+
+// websocketHosts holds the api servers where a spdy upgrade failed, but a websocket connection succeeded
+var websocketHosts sync.Map
+
+// streamProtocol returns the protocol that should be used for the given rest config, an empty
+// string means spdy should be tried first
+func streamProtocol(config *rest.Config) string {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(StreamProtocolEnv))) {
+	case StreamProtocolSPDY:
+		return StreamProtocolSPDY
+	case StreamProtocolWebsocket:
+		return StreamProtocolWebsocket
+	}
+
+	if _, ok := websocketHosts.Load(config.Host); ok {
+		return StreamProtocolWebsocket
+	}
+	return ""
+}
+
+// isUpgradeFailure checks if the given error was caused by a failed spdy upgrade, which is the
+// case if a proxy or the api server in front doesn't support spdy anymore
+func isUpgradeFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "unable to upgrade connection") ||
+		strings.Contains(msg, "error upgrading connection") ||
+		strings.Contains(msg, "connection upgrade")
+}
+
+// dialWebsocket opens a new websocket connection to the given url with the authentication
+// of the rest config
+func dialWebsocket(config *rest.Config, u *url.URL, protocols []string) (*websocket.Conn, error) {
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	rt := &websocketRoundTripper{
+		TLSConfig: tlsConfig,
+		Proxy:     config.Proxy,
+		Protocols: protocols,
+	}
+	wrapper, err := rest.HTTPWrappersForConfig(config, rt)
+	if err != nil {
+		return nil, err
+	}
+
+	wsURL := *u
+	switch wsURL.Scheme {
+	case "https":
+		wsURL.Scheme = "wss"
+	case "http":
+		wsURL.Scheme = "ws"
+	}
+
+	req, err := http.NewRequest(http.MethodGet, wsURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// the round tripper will dial the connection after the wrappers have added the authentication
+	// headers, the response is only used to report errors
+	resp, err := wrapper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+
+	return rt.Conn, nil
+}
+
+// websocketRoundTripper dials a websocket connection with the headers of the given request
+type websocketRoundTripper struct {
+	TLSConfig *tls.Config
+	Proxy     func(*http.Request) (*url.URL, error)
+	Protocols []string
+
+	Conn *websocket.Conn
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (rt *websocketRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxy := rt.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+
+	dialer := &websocket.Dialer{
+		Proxy:           proxy,
+		TLSClientConfig: rt.TLSConfig,
+		Subprotocols:    rt.Protocols,
+	}
+
+	header := req.Header.Clone()
+	for _, h := range []string{"Upgrade", "Connection", "Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions", "Sec-Websocket-Protocol"} {
+		header.Del(h)
+	}
+
+	conn, resp, err := dialer.DialContext(req.Context(), req.URL.String(), header)
+	if err != nil {
+		if resp != nil {
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("unable to open websocket connection: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		}
+
+		return nil, errors.Wrap(err, "unable to open websocket connection")
+	}
+
+	rt.Conn = conn
+	return resp, nil
+}
+
+// channelConn wraps a websocket connection that uses the kubernetes channel protocol where the first
+// byte of each message is the channel
+type channelConn struct {
+	conn *websocket.Conn
+
+	writeMutex sync.Mutex
+	closed     bool
+}
+
+func (c *channelConn) write(channel byte, data []byte) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	message := make([]byte, 0, len(data)+1)
+	message = append(message, channel)
+	message = append(message, data...)
+	return c.conn.WriteMessage(websocket.BinaryMessage, message)
+}
+
+func (c *channelConn) read() (byte, []byte, error) {
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			return 0, nil, err
+		} else if len(message) == 0 {
+			continue
+		}
+
+		return message[0], message[1:], nil
+	}
+}
+
+func (c *channelConn) Close() error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return c.conn.Close()
+}
+
+// websocketExecutor implements the remotecommand.Executor interface and streams
+// an exec or attach over a websocket connection
+type websocketExecutor struct {
+	config *rest.Config
+	url    *url.URL
+
+	connMutex sync.Mutex
+	conn      *channelConn
+	closed    bool
+}
+
+// Stream implements the remotecommand.Executor interface
+func (e *websocketExecutor) Stream(options remotecommand.StreamOptions) error {
+	wsConn, err := dialWebsocket(e.config, e.url, []string{channelWebsocketProtocolV5, channelWebsocketProtocolV4})
+	if err != nil {
+		return err
+	}
+
+	conn := &channelConn{conn: wsConn}
+	e.connMutex.Lock()
+	if e.closed {
+		e.connMutex.Unlock()
+		_ = conn.Close()
+		return nil
+	}
+	e.conn = conn
+	e.connMutex.Unlock()
+	defer conn.Close()
+
+	if options.TerminalSizeQueue != nil {
+		go func() {
+			for {
+				size := options.TerminalSizeQueue.Next()
+				if size == nil {
+					return
+				}
+
+				data, err := json.Marshal(size)
+				if err != nil {
+					return
+				}
+				err = conn.write(streamResize, data)
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	if options.Stdin != nil {
+		go func() {
+			buf := make([]byte, 32*1024)
+			for {
+				n, err := options.Stdin.Read(buf)
+				if n > 0 {
+					if writeErr := conn.write(streamStdin, buf[:n]); writeErr != nil {
+						return
+					}
+				}
+				if err != nil {
+					// only the v5 protocol is able to signal that stdin was closed
+					if err == io.EOF && wsConn.Subprotocol() == channelWebsocketProtocolV5 {
+						_ = conn.write(streamClose, []byte{streamStdin})
+					}
+					return
+				}
+			}
+		}()
+	}
+
+	var (
+		streamErr     error
+		receivedError bool
+	)
+	for {
+		channel, data, err := conn.read()
+		if err != nil {
+			if receivedError || websocket.IsCloseError(err, websocket.CloseNormalClosure) || e.isClosed() {
+				return streamErr
+			}
+
+			return errors.Wrap(err, "read from websocket")
+		}
+
+		switch channel {
+		case streamStdout:
+			if options.Stdout != nil {
+				_, err = options.Stdout.Write(data)
+			}
+		case streamStderr:
+			if options.Stderr != nil {
+				_, err = options.Stderr.Write(data)
+			}
+		case streamError:
+			if len(data) > 0 {
+				receivedError = true
+				streamErr = decodeErrorStatus(data)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (e *websocketExecutor) isClosed() bool {
+	e.connMutex.Lock()
+	defer e.connMutex.Unlock()
+
+	return e.closed
+}
+
+// Close closes the underlying websocket connection
+func (e *websocketExecutor) Close() error {
+	e.connMutex.Lock()
+	defer e.connMutex.Unlock()
+
+	e.closed = true
+	if e.conn != nil {
+		return e.conn.Close()
+	}
+
+	return nil
+}
+
+// decodeErrorStatus interprets the json status that is sent on the error channel of the v4 and v5 protocols
+func decodeErrorStatus(message []byte) error {
+	status := metav1.Status{}
+	err := json.Unmarshal(message, &status)
+	if err != nil {
+		return fmt.Errorf("error stream protocol error: %v in %q", err, string(message))
+	}
+
+	switch status.Status {
+	case metav1.StatusSuccess:
+		return nil
+	case metav1.StatusFailure:
+		if status.Reason == apiremotecommand.NonZeroExitCodeReason && status.Details != nil {
+			for _, cause := range status.Details.Causes {
+				if cause.Type != apiremotecommand.ExitCodeCauseType {
+					continue
+				}
+
+				rc, err := strconv.ParseUint(cause.Message, 10, 8)
+				if err != nil {
+					return fmt.Errorf("error stream protocol error: invalid exit code value %q", cause.Message)
+				}
+				return exec.CodeExitError{
+					Err:  fmt.Errorf("command terminated with exit code %d", rc),
+					Code: int(rc),
+				}
+			}
+		}
+
+		return errors.New(status.Message)
+	}
+
+	return errors.New("error stream protocol error: unknown error")
+}
+
+// fallbackExecutor tries to stream over spdy first and falls back to websockets
+// if the spdy upgrade fails
+type fallbackExecutor struct {
+	config *rest.Config
+
+	spdy         remotecommand.Executor
+	spdyUpgrader UpgraderWrapper
+	websocket    *websocketExecutor
+}
+
+// Stream implements the remotecommand.Executor interface
+func (e *fallbackExecutor) Stream(options remotecommand.StreamOptions) error {
+	protocol := streamProtocol(e.config)
+	if protocol == StreamProtocolWebsocket {
+		return e.websocket.Stream(options)
+	}
+
+	err := e.spdy.Stream(options)
+	if protocol == StreamProtocolSPDY || !isUpgradeFailure(err) {
+		return err
+	}
+
+	log.GetFileLogger("kubectl").Infof("Falling back to websocket, because spdy upgrade failed: %v", err)
+	err = e.websocket.Stream(options)
+	if err == nil || isExitError(err) {
+		websocketHosts.Store(e.config.Host, true)
+	}
+	return err
+}
+
+// Close closes all open connections of the executor
+func (e *fallbackExecutor) Close() error {
+	err := e.websocket.Close()
+	if e.spdyUpgrader != nil {
+		if spdyErr := e.spdyUpgrader.Close(); spdyErr != nil {
+			return spdyErr
+		}
+	}
+
+	return err
+}
+
+func isExitError(err error) bool {
+	_, ok := err.(exec.CodeExitError)
+	return ok
+}
+
+// Executor is a remotecommand.Executor that can be closed
+type Executor interface {
+	remotecommand.Executor
+	io.Closer
+}
+
+// NewExecutor creates a new executor for the given exec or attach url that uses spdy and falls back
+// to websockets if the spdy upgrade is not possible
+func NewExecutor(client Client, u *url.URL) (Executor, error) {
+	executor := &fallbackExecutor{
+		config:    client.RestConfig(),
+		websocket: &websocketExecutor{config: client.RestConfig(), url: u},
+	}
+	if streamProtocol(client.RestConfig()) == StreamProtocolWebsocket {
+		return executor, nil
+	}
+
+	wrapper, upgradeRoundTripper, err := GetUpgraderWrapper(client)
+	if err != nil {
+		return nil, err
+	}
+
+	spdyExecutor, err := remotecommand.NewSPDYExecutorForTransports(wrapper, upgradeRoundTripper, "POST", u)
+	if err != nil {
+		return nil, err
+	}
+
+	executor.spdy = spdyExecutor
+	executor.spdyUpgrader = upgradeRoundTripper
+	return executor, nil
+}
@@ -0,0 +1,280 @@
+package kubectl
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	portForwardDataChannel  byte = 0
+	portForwardErrorChannel byte = 1
+)
+
+// fallbackDialer dials a spdy connection and falls back to websockets if the spdy upgrade fails
+type fallbackDialer struct {
+	config *rest.Config
+
+	spdy      httpstream.Dialer
+	websocket *websocketDialer
+}
+
+// Dial implements the httpstream.Dialer interface
+func (d *fallbackDialer) Dial(protocols ...string) (httpstream.Connection, string, error) {
+	protocol := streamProtocol(d.config)
+	if protocol == StreamProtocolWebsocket {
+		return d.websocket.Dial(protocols...)
+	}
+
+	conn, negotiated, err := d.spdy.Dial(protocols...)
+	if protocol == StreamProtocolSPDY || !isUpgradeFailure(err) {
+		return conn, negotiated, err
+	}
+
+	log.GetFileLogger("portforwarding").Infof("Falling back to websocket, because spdy upgrade failed: %v", err)
+	return d.websocket.Dial(protocols...)
+}
+
+// websocketDialer creates port forwarding connections over websockets. Because the websocket
+// protocol requires the ports to be known upfront, a new websocket is opened for each forwarded
+// connection
+type websocketDialer struct {
+	config *rest.Config
+	url    *url.URL
+}
+
+// Dial implements the httpstream.Dialer interface
+func (d *websocketDialer) Dial(protocols ...string) (httpstream.Connection, string, error) {
+	protocol := ""
+	if len(protocols) > 0 {
+		protocol = protocols[0]
+	}
+
+	return &websocketConnection{
+		config:    d.config,
+		url:       d.url,
+		requests:  map[string]*websocketRequest{},
+		closeChan: make(chan bool),
+	}, protocol, nil
+}
+
+// websocketConnection implements the httpstream.Connection interface and maps the
+// streams of a port forwarding request to a websocket
+type websocketConnection struct {
+	config *rest.Config
+	url    *url.URL
+
+	streamID uint32
+
+	requestsMutex sync.Mutex
+	requests      map[string]*websocketRequest
+
+	closeOnce sync.Once
+	closeChan chan bool
+}
+
+// CreateStream implements the httpstream.Connection interface
+func (c *websocketConnection) CreateStream(headers http.Header) (httpstream.Stream, error) {
+	requestID := headers.Get(corev1.PortForwardRequestIDHeader)
+
+	c.requestsMutex.Lock()
+	defer c.requestsMutex.Unlock()
+
+	request, ok := c.requests[requestID]
+	if !ok {
+		u := *c.url
+		query := u.Query()
+		query.Set("ports", headers.Get(corev1.PortHeader))
+		u.RawQuery = query.Encode()
+
+		wsConn, err := dialWebsocket(c.config, &u, []string{channelWebsocketProtocolV5, channelWebsocketProtocolV4})
+		if err != nil {
+			return nil, err
+		}
+		websocketHosts.Store(c.config.Host, true)
+
+		request = &websocketRequest{
+			conn: &channelConn{conn: wsConn},
+		}
+		request.data = c.newStream(request, portForwardDataChannel)
+		request.error = c.newStream(request, portForwardErrorChannel)
+		c.requests[requestID] = request
+		go c.readLoop(requestID, request)
+	}
+
+	h := headers.Clone()
+	if h.Get(corev1.StreamType) == corev1.StreamTypeError {
+		request.error.headers = h
+		return request.error, nil
+	}
+
+	request.data.headers = h
+	return request.data, nil
+}
+
+func (c *websocketConnection) newStream(request *websocketRequest, channel byte) *websocketStream {
+	reader, writer := io.Pipe()
+	return &websocketStream{
+		id:      atomic.AddUint32(&c.streamID, 1),
+		channel: channel,
+		request: request,
+		reader:  reader,
+		writer:  writer,
+	}
+}
+
+// readLoop distributes the received messages to the data and error stream. The first message of each
+// channel contains the port and is skipped
+func (c *websocketConnection) readLoop(requestID string, request *websocketRequest) {
+	defer func() {
+		_ = request.data.writer.Close()
+		_ = request.error.writer.Close()
+		_ = request.conn.Close()
+
+		c.requestsMutex.Lock()
+		delete(c.requests, requestID)
+		c.requestsMutex.Unlock()
+	}()
+
+	portRead := map[byte]bool{}
+	for {
+		channel, data, err := request.conn.read()
+		if err != nil {
+			return
+		}
+
+		if !portRead[channel] {
+			portRead[channel] = true
+			if len(data) < 2 {
+				continue
+			}
+			data = data[2:]
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		switch channel {
+		case portForwardDataChannel:
+			_, err = request.data.writer.Write(data)
+		case portForwardErrorChannel:
+			_, err = request.error.writer.Write(data)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Close implements the httpstream.Connection interface
+func (c *websocketConnection) Close() error {
+	c.requestsMutex.Lock()
+	for _, request := range c.requests {
+		_ = request.conn.Close()
+	}
+	c.requestsMutex.Unlock()
+
+	c.closeOnce.Do(func() {
+		close(c.closeChan)
+	})
+	return nil
+}
+
+// CloseChan implements the httpstream.Connection interface
+func (c *websocketConnection) CloseChan() <-chan bool {
+	return c.closeChan
+}
+
+// SetIdleTimeout implements the httpstream.Connection interface
+func (c *websocketConnection) SetIdleTimeout(timeout time.Duration) {}
+
+// RemoveStreams implements the httpstream.Connection interface. It closes the websockets of the
+// streams, which also stops their read loops
+func (c *websocketConnection) RemoveStreams(streams ...httpstream.Stream) {
+	for _, stream := range streams {
+		if s, ok := stream.(*websocketStream); ok {
+			_ = s.request.conn.Close()
+		}
+	}
+}
+
+// websocketRequest is a single forwarded connection with its own websocket
+type websocketRequest struct {
+	conn *channelConn
+
+	data  *websocketStream
+	error *websocketStream
+}
+
+// websocketStream implements the httpstream.Stream interface for a single channel of a websocket
+type websocketStream struct {
+	id      uint32
+	channel byte
+	headers http.Header
+	request *websocketRequest
+
+	reader *io.PipeReader
+	writer *io.PipeWriter
+
+	writeClosed int32
+}
+
+// Read implements the httpstream.Stream interface
+func (s *websocketStream) Read(p []byte) (int, error) {
+	return s.reader.Read(p)
+}
+
+// Write implements the httpstream.Stream interface
+func (s *websocketStream) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&s.writeClosed) == 1 {
+		return 0, io.ErrClosedPipe
+	}
+
+	err := s.request.conn.write(s.channel, p)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close implements the httpstream.Stream interface. With the v5 protocol only the write side of the
+// stream is closed and the stream can still be read until the remote side closes the websocket. The
+// v4 protocol has no way to signal a half close, so closing the data stream closes the websocket,
+// as the remote side would otherwise wait for more data forever
+func (s *websocketStream) Close() error {
+	if !atomic.CompareAndSwapInt32(&s.writeClosed, 0, 1) {
+		return nil
+	}
+
+	if s.request.conn.conn.Subprotocol() == channelWebsocketProtocolV5 {
+		return s.request.conn.write(streamClose, []byte{s.channel})
+	} else if s.channel == portForwardDataChannel {
+		return s.request.conn.Close()
+	}
+
+	return nil
+}
+
+// Reset implements the httpstream.Stream interface
+func (s *websocketStream) Reset() error {
+	return s.request.conn.Close()
+}
+
+// Headers implements the httpstream.Stream interface
+func (s *websocketStream) Headers() http.Header {
+	return s.headers
+}
+
+// Identifier implements the httpstream.Stream interface
+func (s *websocketStream) Identifier() uint32 {
+	return s.id
+}
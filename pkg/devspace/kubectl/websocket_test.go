@@ -0,0 +1,240 @@
+package kubectl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiremotecommand "k8s.io/apimachinery/pkg/util/remotecommand"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+func TestWebsocketExecutor(t *testing.T) {
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{channelWebsocketProtocolV5, channelWebsocketProtocolV4},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// echo stdin to stdout until stdin is closed
+		stdin := []byte{}
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			} else if message[0] == streamClose {
+				break
+			}
+
+			stdin = append(stdin, message[1:]...)
+		}
+
+		_ = conn.WriteMessage(websocket.BinaryMessage, append([]byte{streamStdout}, stdin...))
+		_ = conn.WriteMessage(websocket.BinaryMessage, append([]byte{streamStderr}, []byte("error")...))
+		status, _ := json.Marshal(metav1.Status{
+			Status: metav1.StatusFailure,
+			Reason: apiremotecommand.NonZeroExitCodeReason,
+			Details: &metav1.StatusDetails{
+				Causes: []metav1.StatusCause{
+					{
+						Type:    apiremotecommand.ExitCodeCauseType,
+						Message: "3",
+					},
+				},
+			},
+		})
+		_ = conn.WriteMessage(websocket.BinaryMessage, append([]byte{streamError}, status...))
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL + "/api/v1/namespaces/test/pods/test/exec")
+	assert.NilError(t, err)
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	executor := &websocketExecutor{
+		config: &rest.Config{Host: server.URL, BearerToken: "test-token"},
+		url:    u,
+	}
+	err = executor.Stream(remotecommand.StreamOptions{
+		Stdin:  io.NopCloser(strings.NewReader("hello world")),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+
+	exitErr, ok := err.(exec.CodeExitError)
+	assert.Assert(t, ok, "expected exit error, got %v", err)
+	assert.Equal(t, exitErr.Code, 3)
+	assert.Equal(t, stdout.String(), "hello world")
+	assert.Equal(t, stderr.String(), "error")
+}
+
+func TestWebsocketPortForwardHalfClose(t *testing.T) {
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{channelWebsocketProtocolV5},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("ports"), "8080")
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// the first message of each channel contains the port
+		_ = conn.WriteMessage(websocket.BinaryMessage, []byte{portForwardDataChannel, 0x90, 0x1f})
+		_ = conn.WriteMessage(websocket.BinaryMessage, []byte{portForwardErrorChannel, 0x90, 0x1f})
+
+		// answer the request after the client closed the data channel
+		request := []byte{}
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			} else if message[0] == streamClose && message[1] == portForwardDataChannel {
+				break
+			} else if message[0] == portForwardDataChannel {
+				request = append(request, message[1:]...)
+			}
+		}
+		_ = conn.WriteMessage(websocket.BinaryMessage, append([]byte{portForwardDataChannel}, []byte("pong:")...))
+		_ = conn.WriteMessage(websocket.BinaryMessage, append([]byte{portForwardDataChannel}, request...))
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL + "/api/v1/namespaces/test/pods/test/portforward")
+	assert.NilError(t, err)
+
+	dialer := &websocketDialer{config: &rest.Config{Host: server.URL}, url: u}
+	conn, _, err := dialer.Dial()
+	assert.NilError(t, err)
+	defer conn.Close()
+
+	headers := http.Header{}
+	headers.Set(corev1.PortHeader, "8080")
+	headers.Set(corev1.PortForwardRequestIDHeader, "0")
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, err := conn.CreateStream(headers)
+	assert.NilError(t, err)
+
+	_, err = dataStream.Write([]byte("ping"))
+	assert.NilError(t, err)
+	assert.NilError(t, dataStream.Close())
+
+	// writing is not possible anymore, but the response can still be read
+	_, err = dataStream.Write([]byte("ping"))
+	assert.Equal(t, err, io.ErrClosedPipe)
+	response, err := io.ReadAll(dataStream)
+	assert.NilError(t, err)
+	assert.Equal(t, string(response), "pong:ping")
+}
+
+func TestWebsocketPortForwardRelease(t *testing.T) {
+	for _, protocol := range []string{channelWebsocketProtocolV4, channelWebsocketProtocolV5} {
+		upgrader := websocket.Upgrader{
+			Subprotocols: []string{protocol},
+		}
+		released := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			// the remote side never closes the websocket on its own
+			_ = conn.WriteMessage(websocket.BinaryMessage, []byte{portForwardDataChannel, 0x90, 0x1f})
+			_ = conn.WriteMessage(websocket.BinaryMessage, []byte{portForwardErrorChannel, 0x90, 0x1f})
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					close(released)
+					return
+				}
+			}
+		}))
+
+		u, err := url.Parse(server.URL + "/api/v1/namespaces/test/pods/test/portforward")
+		assert.NilError(t, err)
+
+		dialer := &websocketDialer{config: &rest.Config{Host: server.URL}, url: u}
+		conn, _, err := dialer.Dial()
+		assert.NilError(t, err)
+
+		headers := http.Header{}
+		headers.Set(corev1.PortHeader, "8080")
+		headers.Set(corev1.PortForwardRequestIDHeader, "0")
+		headers.Set(corev1.StreamType, corev1.StreamTypeData)
+		dataStream, err := conn.CreateStream(headers)
+		assert.NilError(t, err)
+		headers.Set(corev1.StreamType, corev1.StreamTypeError)
+		errorStream, err := conn.CreateStream(headers)
+		assert.NilError(t, err)
+
+		// the local side hits EOF and the port forwarder removes the streams
+		_, err = dataStream.Write([]byte("ping"))
+		assert.NilError(t, err)
+		assert.NilError(t, errorStream.Close())
+		assert.NilError(t, dataStream.Close())
+		conn.RemoveStreams(errorStream, dataStream)
+
+		select {
+		case <-released:
+		case <-time.After(time.Second * 5):
+			t.Fatalf("websocket was not closed with protocol %s", protocol)
+		}
+
+		// the read loop stopped and removed the request
+		_, err = io.ReadAll(dataStream)
+		assert.NilError(t, err)
+		wsConn := conn.(*websocketConnection)
+		assert.Assert(t, waitFor(func() bool {
+			wsConn.requestsMutex.Lock()
+			defer wsConn.requestsMutex.Unlock()
+			return len(wsConn.requests) == 0
+		}), "read loop still running with protocol %s", protocol)
+
+		_ = conn.Close()
+		server.Close()
+	}
+}
+
+func waitFor(condition func() bool) bool {
+	for i := 0; i < 50; i++ {
+		if condition() {
+			return true
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	return false
+}
+
+func TestIsUpgradeFailure(t *testing.T) {
+	assert.Equal(t, isUpgradeFailure(nil), false)
+	assert.Equal(t, isUpgradeFailure(io.EOF), false)
+	assert.Equal(t, isUpgradeFailure(exec.CodeExitError{Err: errors.New("command terminated with exit code 1"), Code: 1}), false)
+	assert.Equal(t, isUpgradeFailure(&url.Error{Err: io.EOF}), false)
+	assert.Equal(t, isUpgradeFailure(errors.New("unable to upgrade connection: 400 Bad Request")), true)
+}
@@ -0,0 +1,157 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/hash"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// Steps are the pipeline commands that are recorded as completed steps and skipped when a failed pipeline
+// is resumed. Commands that print values for later steps, such as get_image, are always run
+var Steps = map[string]bool{
+	"build_images":             true,
+	"create_deployments":       true,
+	"purge_deployments":        true,
+	"ensure_pull_secrets":      true,
+	"run_dependencies":         true,
+	"run_dependency_pipelines": true,
+}
+
+// dataKey is the prefix of the key in the local cache the completed steps of a pipeline are stored at
+const dataKey = "pipelineCheckpoint:"
+
+type state struct {
+	// Hash is the hash of the pipeline script and arguments the steps were completed with
+	Hash string `json:"hash"`
+
+	// Steps are the completed steps
+	Steps []string `json:"steps"`
+}
+
+// Checkpoint records the completed steps of a pipeline run in the local cache, so that a failed run can be
+// resumed without running the completed steps again
+type Checkpoint struct {
+	m sync.Mutex
+
+	cache localcache.Cache
+	key   string
+	hash  string
+
+	previous  map[string]bool
+	seen      map[string]int
+	completed []string
+}
+
+// New creates a checkpoint for the pipeline. If resume is true, the steps completed by the last run are
+// skipped, unless the pipeline or its arguments changed since then
+func New(cache localcache.Cache, pipeline *latest.Pipeline, args []string, resume bool, log log.Logger) (*Checkpoint, error) {
+	c := &Checkpoint{
+		cache:    cache,
+		key:      dataKey + pipeline.Name,
+		hash:     hash.String(pipeline.Run + "\n" + strings.Join(args, " ")),
+		previous: map[string]bool{},
+		seen:     map[string]int{},
+	}
+
+	value, ok := cache.GetData(c.key)
+	if !ok || value == "" {
+		if resume {
+			log.Infof("No failed run of pipeline %s found to resume, running all steps", pipeline.Name)
+		}
+		return c, nil
+	} else if !resume {
+		// start over and forget the steps of the last run
+		return c, c.reset()
+	}
+
+	previous := &state{}
+	err := json.Unmarshal([]byte(value), previous)
+	if err != nil || previous.Hash != c.hash {
+		log.Warnf("Pipeline %s or its arguments changed since the last run, running all steps", pipeline.Name)
+		return c, c.reset()
+	}
+
+	for _, step := range previous.Steps {
+		c.previous[step] = true
+	}
+	return c, nil
+}
+
+// Next returns the key of the step that runs the command in the given pipeline and whether it was completed
+// by the last run. A command that runs multiple times with the same arguments is a different step every
+// time. Steps are counted per pipeline, so that pipelines running in parallel can't swap their steps
+func (c *Checkpoint) Next(pipeline, command string, args []string) (string, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	name := pipeline + ":" + strings.TrimSpace(command+" "+strings.Join(args, " "))
+	c.seen[name]++
+	step := name + "#" + strconv.Itoa(c.seen[name])
+	if !c.previous[step] {
+		return step, false
+	}
+
+	c.completed = append(c.completed, step)
+	return step, true
+}
+
+// Complete records the step as completed in the local cache
+func (c *Checkpoint) Complete(step string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.completed = append(c.completed, step)
+	out, err := json.Marshal(&state{Hash: c.hash, Steps: c.completed})
+	if err != nil {
+		return err
+	}
+
+	c.cache.SetData(c.key, string(out))
+	return c.cache.Save()
+}
+
+// Finish removes the completed steps after the pipeline succeeded, so that the next run runs all steps
+func (c *Checkpoint) Finish() error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return c.reset()
+}
+
+func (c *Checkpoint) reset() error {
+	c.cache.SetData(c.key, "")
+	return c.cache.Save()
+}
+
+type checkpointKey struct{}
+
+// WithCheckpoint returns a copy of the context in which the steps of the pipeline are recorded by the checkpoint
+func WithCheckpoint(parent context.Context, checkpoint *Checkpoint) context.Context {
+	return context.WithValue(parent, checkpointKey{}, checkpoint)
+}
+
+// From returns the checkpoint of the context or nil if steps are not recorded
+func From(ctx context.Context) *Checkpoint {
+	checkpoint, _ := ctx.Value(checkpointKey{}).(*Checkpoint)
+	return checkpoint
+}
+
+type pipelineKey struct{}
+
+// WithPipeline returns a copy of the context in which the steps are recorded as steps of the named pipeline
+func WithPipeline(parent context.Context, name string) context.Context {
+	return context.WithValue(parent, pipelineKey{}, name)
+}
+
+// PipelineFrom returns the name of the pipeline the steps of the context belong to
+func PipelineFrom(ctx context.Context) string {
+	name, _ := ctx.Value(pipelineKey{}).(string)
+	return name
+}
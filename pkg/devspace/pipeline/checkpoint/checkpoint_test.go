@@ -0,0 +1,61 @@
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+func TestResume(t *testing.T) {
+	cache := localcache.New(filepath.Join(t.TempDir(), "cache.yaml"))
+	pipeline := &latest.Pipeline{
+		Name: "deploy",
+		Run: `build_images --all
+create_deployments api
+create_deployments api
+create_deployments web`,
+	}
+
+	// first run fails after the first deploy of api
+	c, err := New(cache, pipeline, nil, false, log.Discard)
+	assert.NilError(t, err)
+	for _, step := range [][]string{{"build_images", "--all"}, {"create_deployments", "api"}} {
+		key, completed := c.Next("deploy", step[0], step[1:])
+		assert.Assert(t, !completed)
+		assert.NilError(t, c.Complete(key))
+	}
+
+	// resumed run skips the completed steps, but deploys api a second time
+	c, err = New(cache, pipeline, nil, true, log.Discard)
+	assert.NilError(t, err)
+	_, completed := c.Next("deploy", "build_images", []string{"--all"})
+	assert.Assert(t, completed)
+	_, completed = c.Next("deploy", "create_deployments", []string{"api"})
+	assert.Assert(t, completed)
+	key, completed := c.Next("deploy", "create_deployments", []string{"api"})
+	assert.Assert(t, !completed)
+	assert.NilError(t, c.Complete(key))
+
+	// resumed run after a changed pipeline runs all steps
+	changed := &latest.Pipeline{Name: "deploy", Run: pipeline.Run + "\ncreate_deployments db"}
+	c, err = New(cache, changed, nil, true, log.Discard)
+	assert.NilError(t, err)
+	_, completed = c.Next("deploy", "build_images", []string{"--all"})
+	assert.Assert(t, !completed)
+
+	// a finished pipeline leaves nothing to resume
+	c, err = New(cache, pipeline, nil, false, log.Discard)
+	assert.NilError(t, err)
+	key, _ = c.Next("deploy", "build_images", []string{"--all"})
+	assert.NilError(t, c.Complete(key))
+	assert.NilError(t, c.Finish())
+
+	c, err = New(cache, pipeline, nil, true, log.Discard)
+	assert.NilError(t, err)
+	_, completed = c.Next("deploy", "build_images", []string{"--all"})
+	assert.Assert(t, !completed)
+}
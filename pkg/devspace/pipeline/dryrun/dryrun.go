@@ -0,0 +1,502 @@
+package dryrun
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine/pipelinehandler/commands"
+	enginetypes "github.com/loft-sh/devspace/pkg/devspace/pipeline/engine/types"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/loft-sh/devspace/pkg/util/stringutil"
+	"github.com/pkg/errors"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// replaceVariablesRegEx matches variables with dots, such as ${runtime.images.api.image}, which are replaced
+// by the pipeline engine before the script is parsed
+var replaceVariablesRegEx = regexp.MustCompile(`\$\{[a-zA-Z_.]+?\}`)
+
+// Step is a single command the pipeline would execute
+type Step struct {
+	ID int
+
+	// Pipeline is the name of the pipeline that runs the command
+	Pipeline string
+	Command  string
+	Args     []string
+
+	// Needs are the ids of the steps that have to be finished before the step is started
+	Needs []int
+
+	// Conditional is true if the step only runs depending on the control flow of the script,
+	// e.g. within an if clause or after &&
+	Conditional bool
+	Background  bool
+
+	Images       []string
+	Deployments  []string
+	Dependencies []string
+	PullSecrets  []string
+	Dev          []string
+
+	// Err is set if the command would fail because of invalid arguments
+	Err error
+}
+
+// Plan is the execution graph of a pipeline
+type Plan struct {
+	Pipeline string
+	Steps    []*Step
+}
+
+// New parses the script of the pipeline and resolves the images, deployments, dependencies, pull
+// secrets and dev configurations every command would use without executing anything
+func New(config *latest.Config, pipeline *latest.Pipeline) (*Plan, error) {
+	b := &builder{
+		config:    config,
+		plan:      &Plan{Pipeline: pipeline.Name},
+		functions: map[string]*syntax.Stmt{},
+		visiting:  map[string]bool{},
+	}
+
+	_, err := b.pipeline(pipeline, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.plan, nil
+}
+
+// Errors returns the errors of all steps that would fail because of invalid arguments
+func (p *Plan) Errors() []error {
+	errs := []error{}
+	for _, step := range p.Steps {
+		if step.Err != nil {
+			errs = append(errs, fmt.Errorf("step %d (%s): %v", step.ID, step.Command, step.Err))
+		}
+	}
+
+	return errs
+}
+
+// String prints every step of the plan with the steps it needs and the objects it would use
+func (p *Plan) String() string {
+	out := &bytes.Buffer{}
+	for _, step := range p.Steps {
+		line := fmt.Sprintf("[%d] %s", step.ID, strings.TrimSpace(step.Command+" "+strings.Join(step.Args, " ")))
+		if step.Pipeline != p.Pipeline {
+			line = fmt.Sprintf("[%d] (%s) %s", step.ID, step.Pipeline, strings.TrimSpace(step.Command+" "+strings.Join(step.Args, " ")))
+		}
+		if len(step.Needs) > 0 {
+			needs := []string{}
+			for _, id := range step.Needs {
+				needs = append(needs, strconv.Itoa(id))
+			}
+			line += " <- " + strings.Join(needs, ", ")
+		}
+		if step.Conditional {
+			line += " (conditional)"
+		}
+		if step.Background {
+			line += " (background)"
+		}
+		fmt.Fprintln(out, line)
+
+		printObjects(out, "images", step.Images)
+		printObjects(out, "deployments", step.Deployments)
+		printObjects(out, "dependencies", step.Dependencies)
+		printObjects(out, "pull secrets", step.PullSecrets)
+		printObjects(out, "dev", step.Dev)
+		if step.Err != nil {
+			fmt.Fprintf(out, "    error: %v\n", step.Err)
+		}
+	}
+
+	return out.String()
+}
+
+func printObjects(out *bytes.Buffer, kind string, objects []string) {
+	if objects == nil {
+		return
+	} else if len(objects) == 0 {
+		fmt.Fprintf(out, "    %s: none\n", kind)
+		return
+	}
+
+	fmt.Fprintf(out, "    %s: %s\n", kind, strings.Join(objects, ", "))
+}
+
+type builder struct {
+	config *latest.Config
+	plan   *Plan
+
+	// functions are the shell functions declared by the scripts
+	functions map[string]*syntax.Stmt
+
+	// visiting are the pipelines and config functions that are currently resolved, to stop on recursion
+	visiting map[string]bool
+}
+
+func (b *builder) pipeline(pipeline *latest.Pipeline, after []int, conditional bool) ([]int, error) {
+	key := "pipeline:" + pipeline.Name
+	if b.visiting[key] {
+		return after, nil
+	}
+	b.visiting[key] = true
+	defer delete(b.visiting, key)
+
+	run := pipeline.Run
+	if run == "" {
+		defaultPipeline, _ := types.GetDefaultPipeline(pipeline.Name)
+		if defaultPipeline != nil {
+			run = defaultPipeline.Run
+		}
+	}
+
+	return b.script(pipeline.Name, run, after, conditional)
+}
+
+func (b *builder) script(pipeline, script string, after []int, conditional bool) ([]int, error) {
+	script = replaceVariablesRegEx.ReplaceAllStringFunc(script, func(s string) string {
+		return strings.ReplaceAll(s, ".", enginetypes.DotReplacement)
+	})
+
+	file, err := syntax.NewParser().Parse(strings.NewReader(script), "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse pipeline %s", pipeline)
+	}
+
+	return b.stmts(pipeline, file.Stmts, after, conditional), nil
+}
+
+// stmts adds the statements one after another. Statements in the background don't block the following
+// statements, but a wait and the end of the statements wait for them
+func (b *builder) stmts(pipeline string, stmts []*syntax.Stmt, after []int, conditional bool) []int {
+	background := []int{}
+	for _, stmt := range stmts {
+		if stmt.Background {
+			tails := b.stmt(pipeline, stmt, after, conditional)
+			for _, id := range tails {
+				b.step(id).Background = true
+			}
+			background = append(background, tails...)
+			continue
+		}
+
+		if call, ok := stmt.Cmd.(*syntax.CallExpr); ok && len(call.Args) > 0 && call.Args[0].Lit() == "wait" {
+			after = append(after, background...)
+			background = []int{}
+		}
+		after = b.stmt(pipeline, stmt, after, conditional)
+	}
+
+	return append(after, background...)
+}
+
+func (b *builder) stmt(pipeline string, stmt *syntax.Stmt, after []int, conditional bool) []int {
+	switch cmd := stmt.Cmd.(type) {
+	case *syntax.CallExpr:
+		return b.call(pipeline, cmd, after, conditional)
+	case *syntax.BinaryCmd:
+		after = b.stmt(pipeline, cmd.X, after, conditional)
+		return b.stmt(pipeline, cmd.Y, after, conditional || cmd.Op != syntax.Pipe)
+	case *syntax.Block:
+		return b.stmts(pipeline, cmd.Stmts, after, conditional)
+	case *syntax.Subshell:
+		return b.stmts(pipeline, cmd.Stmts, after, conditional)
+	case *syntax.IfClause:
+		after = b.stmts(pipeline, cmd.Cond, after, conditional)
+		tails := b.stmts(pipeline, cmd.Then, after, true)
+		if cmd.Else == nil {
+			return merge(after, tails)
+		}
+
+		return merge(tails, b.stmt(pipeline, &syntax.Stmt{Cmd: cmd.Else}, after, true))
+	case *syntax.WhileClause:
+		after = b.stmts(pipeline, cmd.Cond, after, conditional)
+		return merge(after, b.stmts(pipeline, cmd.Do, after, true))
+	case *syntax.ForClause:
+		return merge(after, b.stmts(pipeline, cmd.Do, after, true))
+	case *syntax.CaseClause:
+		tails := []int{}
+		for _, item := range cmd.Items {
+			tails = merge(tails, b.stmts(pipeline, item.Stmts, after, true))
+		}
+		return merge(after, tails)
+	case *syntax.FuncDecl:
+		b.functions[cmd.Name.Value] = cmd.Body
+	}
+
+	return after
+}
+
+func (b *builder) call(pipeline string, call *syntax.CallExpr, after []int, conditional bool) []int {
+	if len(call.Args) == 0 {
+		return after
+	}
+
+	words := []string{}
+	for _, word := range call.Args {
+		words = append(words, printWord(word))
+	}
+	command, args := words[0], words[1:]
+
+	// shell functions and functions of the config are resolved in place
+	if body, ok := b.functions[command]; ok && !b.visiting["function:"+command] {
+		b.visiting["function:"+command] = true
+		defer delete(b.visiting, "function:"+command)
+		return b.stmt(pipeline, body, after, conditional)
+	} else if function, ok := b.config.Functions[command]; ok && !b.visiting["function:"+command] {
+		b.visiting["function:"+command] = true
+		defer delete(b.visiting, "function:"+command)
+		tails, err := b.script(pipeline, function, after, conditional)
+		if err == nil {
+			return tails
+		}
+	}
+
+	step := b.add(pipeline, command, args, after, conditional)
+	switch command {
+	case "build_images":
+		options := &commands.BuildImagesOptions{}
+		step.Images, step.Err = b.resolve(options, args, &options.All, &options.Except, b.images(), "image")
+	case "create_deployments":
+		options := &commands.CreateDeploymentsOptions{}
+		step.Deployments, step.Err = b.resolve(options, args, &options.All, &options.Except, b.deployments(), "deployment")
+	case "purge_deployments":
+		options := &commands.PurgeDeploymentsOptions{}
+		step.Deployments, step.Err = b.resolve(options, args, &options.All, &options.Except, b.deployments(), "deployment")
+	case "run_dependencies", "run_dependency_pipelines":
+		options := &commands.RunDependencyPipelinesOptions{}
+		step.Dependencies, step.Err = b.resolve(options, args, &options.All, &options.Except, b.dependencies(), "dependency")
+	case "ensure_pull_secrets":
+		options := &commands.EnsurePullSecretsOptions{}
+		step.PullSecrets, step.Err = b.resolve(options, args, &options.All, &options.Except, b.pullSecrets(), "pull secret")
+	case "start_dev":
+		options := &commands.StartDevOptions{}
+		step.Dev, step.Err = b.resolve(options, args, &options.All, &options.Except, b.dev(), "dev configuration")
+	case "stop_dev":
+		options := &commands.StopDevOptions{}
+		step.Dev, step.Err = b.resolve(options, args, &options.All, &options.Except, b.dev(), "dev configuration")
+	case "run_parallel":
+		options := &commands.RunParallelOptions{}
+		scripts, err := parseArgs(options, args)
+		if err != nil {
+			step.Err = err
+			break
+		}
+
+		tails := []int{}
+		for _, script := range scripts {
+			branch, err := b.script(pipeline, script, []int{step.ID}, conditional)
+			if err != nil {
+				step.Err = err
+				continue
+			}
+			tails = merge(tails, branch)
+		}
+		if len(tails) == 0 {
+			break
+		}
+		return tails
+	case "run_pipelines":
+		options := &commands.RunPipelineOptions{}
+		names, err := parseArgs(options, args)
+		if err != nil {
+			step.Err = err
+			break
+		}
+
+		tails := []int{}
+		for _, name := range names {
+			if name == "" {
+				continue
+			} else if b.config.Pipelines[name] == nil {
+				step.Err = fmt.Errorf("couldn't find pipeline %s", name)
+				continue
+			}
+
+			branch, err := b.pipeline(b.config.Pipelines[name], []int{step.ID}, conditional)
+			if err != nil {
+				step.Err = err
+				continue
+			}
+			tails = merge(tails, branch)
+		}
+		if len(tails) == 0 {
+			break
+		}
+		return tails
+	case "run_default_pipeline":
+		options := &commands.RunDefaultPipelineOptions{}
+		names, err := parseArgs(options, args)
+		if err != nil {
+			step.Err = err
+			break
+		} else if len(names) != 1 {
+			step.Err = fmt.Errorf("usage: run_default_pipeline [pipeline]")
+			break
+		}
+
+		defaultPipeline, err := types.GetDefaultPipeline(names[0])
+		if err != nil {
+			step.Err = err
+			break
+		}
+
+		tails, err := b.script(pipeline, defaultPipeline.Run, []int{step.ID}, conditional)
+		if err != nil {
+			step.Err = err
+			break
+		}
+		return tails
+	}
+
+	return []int{step.ID}
+}
+
+// resolve parses the arguments of a command that either accepts --all or a list of names and returns the
+// names the command would use
+func (b *builder) resolve(options interface{}, args []string, all *bool, except *[]string, available []string, kind string) ([]string, error) {
+	names, err := parseArgs(options, args)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse args")
+	}
+
+	if *all {
+		retNames := []string{}
+		for _, name := range available {
+			if !stringutil.Contains(*except, name) {
+				retNames = append(retNames, name)
+			}
+		}
+		return retNames, nil
+	} else if len(names) == 0 {
+		return nil, fmt.Errorf("either specify --all or the names of the %s", kind)
+	}
+
+	for _, name := range names {
+		if !stringutil.Contains(available, name) && !strings.Contains(name, "$") {
+			return nil, fmt.Errorf("couldn't find %s %s", kind, name)
+		}
+	}
+	return names, nil
+}
+
+func (b *builder) add(pipeline, command string, args []string, after []int, conditional bool) *Step {
+	step := &Step{
+		ID:          len(b.plan.Steps) + 1,
+		Pipeline:    pipeline,
+		Command:     command,
+		Args:        args,
+		Needs:       merge(nil, after),
+		Conditional: conditional,
+	}
+	b.plan.Steps = append(b.plan.Steps, step)
+	return step
+}
+
+func (b *builder) step(id int) *Step {
+	return b.plan.Steps[id-1]
+}
+
+func (b *builder) images() []string {
+	names := []string{}
+	for name := range b.config.Images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (b *builder) deployments() []string {
+	names := []string{}
+	for name := range b.config.Deployments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (b *builder) dependencies() []string {
+	names := []string{}
+	for name := range b.config.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (b *builder) pullSecrets() []string {
+	names := []string{}
+	for name := range b.config.PullSecrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (b *builder) dev() []string {
+	names := []string{}
+	for name := range b.config.Dev {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// merge returns the sorted ids of both lists without duplicates
+func merge(a, b []int) []int {
+	seen := map[int]bool{}
+	ids := []int{}
+	for _, id := range append(append([]int{}, a...), b...) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// parseArgs parses the flags like the pipeline command, but without printing errors
+func parseArgs(options interface{}, args []string) ([]string, error) {
+	return flags.NewParser(options, flags.Default&^flags.PrintErrors).ParseArgs(args)
+}
+
+// printWord returns the value of words without expansions and the word as written in the script otherwise
+func printWord(word *syntax.Word) string {
+	value := ""
+	for _, part := range word.Parts {
+		switch t := part.(type) {
+		case *syntax.Lit:
+			value += t.Value
+		case *syntax.SglQuoted:
+			value += t.Value
+		case *syntax.DblQuoted:
+			if len(t.Parts) == 0 {
+				continue
+			} else if lit, ok := t.Parts[0].(*syntax.Lit); ok && len(t.Parts) == 1 {
+				value += lit.Value
+				continue
+			}
+			return printExpanded(word)
+		default:
+			return printExpanded(word)
+		}
+	}
+
+	return value
+}
+
+func printExpanded(word *syntax.Word) string {
+	out := &bytes.Buffer{}
+	_ = syntax.NewPrinter().Print(out, word)
+	return strings.ReplaceAll(out.String(), enginetypes.DotReplacement, ".")
+}
@@ -0,0 +1,71 @@
+package dryrun
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"gotest.tools/assert"
+)
+
+func TestDryRun(t *testing.T) {
+	config := &latest.Config{
+		Images: map[string]*latest.Image{
+			"api": {},
+			"web": {},
+		},
+		Deployments: map[string]*latest.DeploymentConfig{
+			"api": {},
+			"db":  {},
+		},
+		Dependencies: map[string]*latest.DependencyConfig{
+			"auth": {},
+		},
+		Functions: map[string]string{
+			"deploy_all": "create_deployments --all --except db",
+		},
+		Pipelines: map[string]*latest.Pipeline{
+			"seed": {Name: "seed", Run: `echo seeding`},
+			"dev":  {Name: "dev"},
+		},
+	}
+
+	pipeline := &latest.Pipeline{
+		Name: "custom",
+		Run: `run_dependencies --all
+run_parallel "build_images api" 'build_images web'
+deploy_all
+if is_equal ${DEVSPACE_NAMESPACE} prod; then
+  run_pipelines seed
+fi
+create_deployments missing &
+wait`,
+	}
+
+	plan, err := New(config, pipeline)
+	assert.NilError(t, err)
+	assert.Equal(t, plan.String(), `[1] run_dependencies --all
+    dependencies: auth
+[2] run_parallel build_images api build_images web <- 1
+[3] build_images api <- 2
+    images: api
+[4] build_images web <- 2
+    images: web
+[5] create_deployments --all --except db <- 3, 4
+    deployments: api
+[6] is_equal ${DEVSPACE_NAMESPACE} prod <- 5
+[7] run_pipelines seed <- 6 (conditional)
+[8] (seed) echo seeding <- 7 (conditional)
+[9] create_deployments missing <- 6, 8 (background)
+    error: couldn't find deployment missing
+[10] wait <- 6, 8, 9
+`)
+	assert.Equal(t, len(plan.Errors()), 1)
+
+	// default pipelines are used if the config pipeline has no script
+	plan, err = New(config, config.Pipelines["dev"])
+	assert.NilError(t, err)
+	assert.Equal(t, len(plan.Steps), 5)
+	assert.DeepEqual(t, plan.Steps[4].Needs, []int{4})
+	assert.DeepEqual(t, plan.Steps[2].Images, []string{"api", "web"})
+	assert.DeepEqual(t, plan.Steps[1].PullSecrets, []string{})
+}
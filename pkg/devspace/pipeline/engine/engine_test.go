@@ -17,7 +17,28 @@ type testCaseShell struct {
 	expectedOutput string
 }
 
+// withTempWorkingDir runs the test in a temporary directory, so that the files the shell and its file
+// loggers create are not written into the source tree
+func withTempWorkingDir(t *testing.T) {
+	wdBackup, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Error getting current working directory: %v", err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatalf("Error changing working directory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		err := os.Chdir(wdBackup)
+		if err != nil {
+			t.Fatalf("Error changing dir back: %v", err)
+		}
+	})
+}
+
 func TestShellCat(t *testing.T) {
+	withTempWorkingDir(t)
 	file, err := os.CreateTemp(".", "testFile")
 	if err != nil {
 		t.Fatal(err)
@@ -50,6 +71,7 @@ func TestShellCat(t *testing.T) {
 }
 
 func TestShellCatError(t *testing.T) {
+	withTempWorkingDir(t)
 	testCases := []testCaseShell{
 		{
 			command:        "cat noFile.txt",
@@ -75,6 +97,7 @@ func TestShellCatError(t *testing.T) {
 
 // this test forces the cat implementation to execute
 func TestShellCatEnforce(t *testing.T) {
+	withTempWorkingDir(t)
 	file, err := os.CreateTemp(".", "testFile")
 	if err != nil {
 		t.Fatal(err)
@@ -107,6 +130,7 @@ func TestShellCatEnforce(t *testing.T) {
 }
 
 func TestKubectlDownload(t *testing.T) {
+	withTempWorkingDir(t)
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
 	err := ExecuteSimpleShellCommand(context.Background(), ".", expand.ListEnviron(os.Environ()...), stdout, stderr, nil, "kubectl")
@@ -121,6 +145,7 @@ func TestKubectlDownload(t *testing.T) {
 }
 
 func TestHelmDownload(t *testing.T) {
+	withTempWorkingDir(t)
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
 	err := ExecuteSimpleShellCommand(context.Background(), ".", expand.ListEnviron(os.Environ()...), stdout, stderr, nil, "helm")
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"strings"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"github.com/loft-sh/devspace/pkg/util/exit"
+	"github.com/pkg/errors"
+	"mvdan.cc/sh/v3/expand"
+	"mvdan.cc/sh/v3/interp"
+)
+
+// PluginFunction validates the flags of a function that was registered by a plugin and calls the
+// plugin with the parsed flags, the current kube context and namespace and the environment of the pipeline
+func PluginFunction(ctx devspacecontext.Context, pipeline types.Pipeline, p *plugin.Metadata, function *plugin.Function, args []string) error {
+	ctx.Log().Debugf("%s %s", function.Name, strings.Join(args, " "))
+	flags, rest, err := plugin.ParseFunctionFlags(function, args)
+	if err != nil {
+		return errors.Wrap(err, "parse args")
+	}
+
+	env, err := plugin.FunctionEnv(function, flags, rest)
+	if err != nil {
+		return err
+	}
+	if pipeline != nil {
+		env[plugin.FunctionPipelineEnv] = pipeline.Name()
+	}
+	if ctx.KubeClient() != nil {
+		env[plugin.FunctionKubeContextEnv] = ctx.KubeClient().CurrentContext()
+		env[plugin.FunctionNamespaceEnv] = ctx.KubeClient().Namespace()
+	}
+
+	hc := interp.HandlerCtx(ctx.Context())
+	err = plugin.CallFunction(ctx.Context(), p, function, args, exportedEnv(hc.Env), env, hc.Dir, hc.Stdin, hc.Stdout, hc.Stderr)
+	if err != nil {
+		if exitErr, ok := err.(*exit.ReturnCodeError); ok {
+			return interp.NewExitStatus(uint8(exitErr.ExitCode))
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func exportedEnv(environ expand.Environ) []string {
+	env := []string{}
+	environ.Each(func(name string, vr expand.Variable) bool {
+		if vr.Exported && vr.Kind == expand.String {
+			env = append(env, name+"="+vr.Str)
+		}
+		return true
+	})
+	return env
+}
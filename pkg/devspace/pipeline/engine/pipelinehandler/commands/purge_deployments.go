@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/jessevdk/go-flags"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/context/values"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy"
 	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
 	"github.com/loft-sh/devspace/pkg/util/stringutil"
@@ -29,9 +30,6 @@ func PurgeDeployments(ctx devspacecontext.Context, pipeline types.Pipeline, args
 	if err != nil {
 		return err
 	}
-	if ctx.KubeClient() == nil {
-		return errors.Errorf(ErrMsg)
-	}
 	options := &PurgeDeploymentsOptions{
 		PurgeOptions: pipeline.Options().PurgeOptions,
 	}
@@ -39,6 +37,13 @@ func PurgeDeployments(ctx devspacecontext.Context, pipeline types.Pipeline, args
 	if err != nil {
 		return errors.Wrap(err, "parse args")
 	}
+	if dependencyName, ok := values.ProtectedDependencyFrom(ctx.Context()); ok && !options.ForcePurge {
+		ctx.Log().Infof("Skip purging deployments of dependency %s as it is protected. Run with '--force-purge' to force deletion", dependencyName)
+		return nil
+	}
+	if ctx.KubeClient() == nil {
+		return errors.Errorf(ErrMsg)
+	}
 
 	if !options.All && len(args) == 0 {
 		return fmt.Errorf("either specify 'purge_deployments --all' or 'purge_deployments deployment1 deployment2'")
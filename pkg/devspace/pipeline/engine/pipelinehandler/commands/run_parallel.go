@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/pkg/errors"
+	"mvdan.cc/sh/v3/interp"
+)
+
+// RunParallelOptions describe how the parallel block is run
+type RunParallelOptions struct {
+	MaxConcurrent int `long:"max-concurrent" description:"The maximum number of scripts run in parallel (0 for infinite)"`
+}
+
+// RunParallel runs every argument as a script of the pipeline in parallel and waits until all of them are
+// finished. If a script fails, no further scripts are started and the running scripts are cancelled
+func RunParallel(ctx devspacecontext.Context, pipeline types.Pipeline, args []string, newHandler NewHandlerFn) error {
+	ctx.Log().Debugf("run_parallel %s", strings.Join(args, " "))
+	options := &RunParallelOptions{}
+	args, err := flags.ParseArgs(options, args)
+	if err != nil {
+		return errors.Wrap(err, "parse args")
+	} else if options.MaxConcurrent < 0 {
+		return fmt.Errorf("--max-concurrent cannot be negative")
+	}
+
+	scripts := []string{}
+	for _, arg := range args {
+		if strings.TrimSpace(arg) != "" {
+			scripts = append(scripts, arg)
+		}
+	}
+	if len(scripts) == 0 {
+		return fmt.Errorf("usage: run_parallel [--max-concurrent n] script1 script2 ...")
+	}
+
+	hc := interp.HandlerCtx(ctx.Context())
+	ctx, t := ctx.WithNewTomb()
+	t.GoLimited(options.MaxConcurrent, len(scripts), func(i int) error {
+		_, err := engine.ExecutePipelineShellCommand(ctx.Context(), scripts[i], nil, hc.Dir, false, hc.Stdout, hc.Stderr, nil, hc.Env, newHandler(ctx, hc.Stdout, hc.Stderr, pipeline))
+		if err != nil {
+			return errors.Wrapf(err, "run %q", scripts[i])
+		}
+
+		return nil
+	})
+
+	return t.Wait()
+}
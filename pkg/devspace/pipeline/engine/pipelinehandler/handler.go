@@ -10,6 +10,8 @@ import (
 	"github.com/sirupsen/logrus"
 
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/context/values"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/checkpoint"
 	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine/basichandler"
 	basichandlercommands "github.com/loft-sh/devspace/pkg/devspace/pipeline/engine/basichandler/commands"
 	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine/pipelinehandler/commands"
@@ -41,6 +43,9 @@ var PipelineCommands = map[string]func(devCtx devspacecontext.Context, pipeline
 		hc := interp.HandlerCtx(devCtx.Context())
 		return basichandlercommands.RunWatch(devCtx.Context(), args, NewPipelineExecHandler(devCtx, hc.Stdout, hc.Stderr, pipeline), devCtx.Log())
 	},
+	"run_parallel": func(devCtx devspacecontext.Context, pipeline types.Pipeline, args []string) error {
+		return commands.RunParallel(devCtx, pipeline, args, NewPipelineExecHandler)
+	},
 	"run_pipelines": func(devCtx devspacecontext.Context, pipeline types.Pipeline, args []string) error {
 		hc := interp.HandlerCtx(devCtx.Context())
 		return commands.RunPipelines(devCtx, pipeline, args, hc.Env)
@@ -85,20 +90,48 @@ var PipelineCommands = map[string]func(devCtx devspacecontext.Context, pipeline
 }
 
 func init() {
+	for k := range PipelineCommands {
+		registerBasicCommand(k)
+	}
+}
+
+// RegisterPipelineCommand adds a new command that can be used within pipelines, e.g. a function
+// of a plugin. A command cannot replace an existing pipeline command or a command built into the shell
+func RegisterPipelineCommand(name string, command func(devCtx devspacecontext.Context, pipeline types.Pipeline, args []string) error) error {
+	if _, ok := PipelineCommands[name]; ok {
+		return fmt.Errorf("pipeline command %s already exists", name)
+	} else if _, ok := PipelineCommands[strings.TrimPrefix(name, "__")]; ok {
+		return fmt.Errorf("pipeline command %s already exists", strings.TrimPrefix(name, "__"))
+	} else if isBuiltinCommand(name) {
+		return fmt.Errorf("command %s is built into the shell", name)
+	}
+
+	PipelineCommands[name] = command
+	registerBasicCommand(name)
+	return nil
+}
+
+func isBuiltinCommand(name string) bool {
+	if _, ok := basichandler.BasicCommands[name]; ok {
+		return true
+	} else if _, ok := basichandler.OverwriteCommands[name]; ok {
+		return true
+	} else if _, ok := basichandler.EnsureCommands[name]; ok {
+		return true
+	}
+	return false
+}
+
+func registerBasicCommand(name string) {
 	// Add pipeline commands to basic handler to show an appropriate
 	// error message if the command cannot be found due to running
 	// outside of a pipeline
-	for k := range PipelineCommands {
-		name := k
-		basichandler.BasicCommands[k] = func(ctx context.Context, args []string) error {
-			hc := interp.HandlerCtx(ctx)
-			_, _ = fmt.Fprintln(hc.Stderr, fmt.Errorf("cannot use command %s outside of a pipeline. Please make sure that you are calling %s within a pipeline execution. If you run a DevSpace command via `devspace run my-command` inside the pipeline, please use `run_command my-command` instead", name, name))
-			return interp.NewExitStatus(1)
-		}
-	}
-	for k := range PipelineCommands {
-		basichandlercommands.XArgsFocusCommands[k] = true
+	basichandler.BasicCommands[name] = func(ctx context.Context, args []string) error {
+		hc := interp.HandlerCtx(ctx)
+		_, _ = fmt.Fprintln(hc.Stderr, fmt.Errorf("cannot use command %s outside of a pipeline. Please make sure that you are calling %s within a pipeline execution. If you run a DevSpace command via `devspace run my-command` inside the pipeline, please use `run_command my-command` instead", name, name))
+		return interp.NewExitStatus(1)
 	}
+	basichandlercommands.XArgsFocusCommands[name] = true
 }
 
 func NewPipelineExecHandler(ctx devspacecontext.Context, stdout, stderr io.Writer, pipeline types.Pipeline) enginetypes.ExecHandler {
@@ -160,7 +193,9 @@ func (e *execHandler) handlePipelineCommands(ctx context.Context, command string
 	pipelineCommand, ok := PipelineCommands[command]
 	if ok {
 		return e.executePipelineCommand(ctx, command, func() error {
-			return pipelineCommand(devCtx, e.pipeline, args)
+			return runStep(devCtx, command, args, func() error {
+				return pipelineCommand(devCtx, e.pipeline, args)
+			})
 		})
 	}
 
@@ -168,13 +203,43 @@ func (e *execHandler) handlePipelineCommands(ctx context.Context, command string
 	pipelineCommand, ok = PipelineCommands[strings.TrimPrefix(command, "__")]
 	if ok {
 		return e.executePipelineCommand(ctx, command, func() error {
-			return pipelineCommand(devCtx, e.pipeline, args)
+			return runStep(devCtx, strings.TrimPrefix(command, "__"), args, func() error {
+				return pipelineCommand(devCtx, e.pipeline, args)
+			})
 		})
 	}
 
 	return false, nil
 }
 
+// runStep runs the pipeline command and records it in the checkpoint of the pipeline. Steps that were
+// completed by the last run are skipped if the pipeline is resumed. Dependency pipelines are not
+// recorded, as they are a single step of the pipeline that runs them
+func runStep(ctx devspacecontext.Context, command string, args []string, fn func() error) error {
+	cp := checkpoint.From(ctx.Context())
+	isDependency, _ := values.IsDependencyFrom(ctx.Context())
+	if cp == nil || isDependency || !checkpoint.Steps[command] {
+		return fn()
+	}
+
+	step, completed := cp.Next(checkpoint.PipelineFrom(ctx.Context()), command, args)
+	if completed {
+		ctx.Log().Infof("Skipping %s as it was completed by the last run", strings.TrimSpace(command+" "+strings.Join(args, " ")))
+		return nil
+	}
+
+	err := fn()
+	if err != nil {
+		return err
+	}
+
+	err = cp.Complete(step)
+	if err != nil {
+		ctx.Log().Debugf("Error saving pipeline checkpoint: %v", err)
+	}
+	return nil
+}
+
 func (e *execHandler) executePipelineCommand(ctx context.Context, command string, commandFn func() error) (bool, error) {
 	if e.pipeline == nil {
 		hc := interp.HandlerCtx(ctx)
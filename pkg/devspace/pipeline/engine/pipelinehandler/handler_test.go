@@ -0,0 +1,107 @@
+package pipelinehandler
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/checkpoint"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine/basichandler"
+	basichandlercommands "github.com/loft-sh/devspace/pkg/devspace/pipeline/engine/basichandler/commands"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+)
+
+func TestRunStepParallelPipelines(t *testing.T) {
+	cache := localcache.New(filepath.Join(t.TempDir(), "cache.yaml"))
+	pipeline := &latest.Pipeline{Name: "deploy", Run: "run_pipelines api web"}
+	args := []string{"app"}
+
+	pipelineContext := func(cp *checkpoint.Checkpoint, name string) devspacecontext.Context {
+		ctx := checkpoint.WithPipeline(checkpoint.WithCheckpoint(context.Background(), cp), name)
+		return devspacecontext.NewContext(ctx, nil, log.Discard)
+	}
+
+	// first run: the child pipeline api deploys app, the child pipeline web fails to deploy app
+	cp, err := checkpoint.New(cache, pipeline, nil, false, log.Discard)
+	assert.NilError(t, err)
+	err = runStep(pipelineContext(cp, "api"), "create_deployments", args, func() error { return nil })
+	assert.NilError(t, err)
+	err = runStep(pipelineContext(cp, "web"), "create_deployments", args, func() error { return errors.New("failed") })
+	assert.Error(t, err, "failed")
+
+	// resumed run: web runs first this time and must not be skipped in place of api
+	ran := []string{}
+	cp, err = checkpoint.New(cache, pipeline, nil, true, log.Discard)
+	assert.NilError(t, err)
+	for _, name := range []string{"web", "api"} {
+		name := name
+		err = runStep(pipelineContext(cp, name), "create_deployments", args, func() error {
+			ran = append(ran, name)
+			return nil
+		})
+		assert.NilError(t, err)
+	}
+	assert.DeepEqual(t, ran, []string{"web"})
+}
+
+type registerPipelineCommandTestCase struct {
+	name string
+
+	command string
+
+	expectedErr string
+}
+
+func TestRegisterPipelineCommand(t *testing.T) {
+	testCases := []registerPipelineCommandTestCase{
+		{
+			name:    "New command",
+			command: "my_function",
+		},
+		{
+			name:        "Pipeline command",
+			command:     "create_deployments",
+			expectedErr: "pipeline command create_deployments already exists",
+		},
+		{
+			name:        "Internal pipeline command",
+			command:     "__create_deployments",
+			expectedErr: "pipeline command create_deployments already exists",
+		},
+		{
+			name:        "Basic command",
+			command:     "cat",
+			expectedErr: "command cat is built into the shell",
+		},
+		{
+			name:        "Ensure command",
+			command:     "kubectl",
+			expectedErr: "command kubectl is built into the shell",
+		},
+	}
+
+	noop := func(devCtx devspacecontext.Context, pipeline types.Pipeline, args []string) error {
+		return nil
+	}
+	for _, testCase := range testCases {
+		err := RegisterPipelineCommand(testCase.command, noop)
+		if testCase.expectedErr == "" {
+			assert.NilError(t, err, "Error in testCase %s", testCase.name)
+			assert.Assert(t, PipelineCommands[testCase.command] != nil, "Command not registered in testCase %s", testCase.name)
+			delete(PipelineCommands, testCase.command)
+			delete(basichandler.BasicCommands, testCase.command)
+			delete(basichandlercommands.XArgsFocusCommands, testCase.command)
+			continue
+		}
+
+		assert.Error(t, err, testCase.expectedErr, "Wrong error in testCase %s", testCase.name)
+		_, ok := PipelineCommands[testCase.command]
+		assert.Equal(t, ok, testCase.command == "create_deployments", "Unexpected pipeline command in testCase %s", testCase.name)
+	}
+}
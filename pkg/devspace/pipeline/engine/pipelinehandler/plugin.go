@@ -0,0 +1,30 @@
+package pipelinehandler
+
+import (
+	"sync"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine/pipelinehandler/commands"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+var pluginFunctionsOnce sync.Once
+
+// RegisterPluginFunctions registers the functions of the installed plugins as pipeline commands.
+// Functions that have the name of an existing pipeline command are skipped
+func RegisterPluginFunctions(log log.Logger) {
+	pluginFunctionsOnce.Do(func() {
+		plugins, functions := plugin.GetFunctions()
+		for i := range functions {
+			p, function := plugins[i], functions[i]
+			err := RegisterPipelineCommand(function.Name, func(devCtx devspacecontext.Context, pipeline types.Pipeline, args []string) error {
+				return commands.PluginFunction(devCtx, pipeline, p, function, args)
+			})
+			if err != nil {
+				log.Warnf("Skipping function %s of plugin %s: %v", function.Name, p.Name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,457 @@
+package pipeline
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	dependencypkg "github.com/loft-sh/devspace/pkg/devspace/dependency"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/registry"
+	types2 "github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/dryrun"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine/pipelinehandler/commands"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/loft-sh/devspace/pkg/util/stringutil"
+)
+
+// scheduleNode is a dependency in the graph of a pipeline that runs the dependencies of all levels itself
+type scheduleNode struct {
+	ctx        devspacecontext.Context
+	dependency types2.Dependency
+	lockType   registry.LockType
+
+	// direct is true if the dependency is used by the scheduling pipeline itself,
+	// parents are the other dependencies that use this dependency
+	direct   bool
+	parents  []*scheduleNode
+	children []*scheduleNode
+
+	// remaining is the number of nodes this node waits for, which are the parents
+	// when purging and the children otherwise
+	remaining int
+	purged    bool
+	failed    bool
+
+	// picked is true once the node was started or skipped, ready is
+	// the time the node was queued
+	picked bool
+	ready  time.Time
+
+	// scheduled is true if the children are run by the scheduling pipeline instead
+	// of the pipeline of the dependency
+	scheduled bool
+
+	// options are the options of the run_dependencies call that selected the dependency
+	options types.DependencyOptions
+}
+
+// waitsFor returns the nodes that have to be finished before the node can be started
+// and the nodes that wait for the node
+func (n *scheduleNode) waitsFor(purge bool) ([]*scheduleNode, []*scheduleNode) {
+	if purge {
+		return n.parents, n.children
+	}
+
+	return n.children, n.parents
+}
+
+// scheduleGraph holds the dependencies to schedule in the order they were found
+type scheduleGraph struct {
+	nodes map[string]*scheduleNode
+	order []*scheduleNode
+}
+
+// topologicalOrder returns the nodes in a fixed order, in which every node comes after the nodes it waits
+// for. Nodes that could be started at the same time are sorted by their priority and name
+func (g *scheduleGraph) topologicalOrder(purge bool) []*scheduleNode {
+	remaining := map[*scheduleNode]int{}
+	ready := []*scheduleNode{}
+	for _, node := range g.order {
+		waitsFor, _ := node.waitsFor(purge)
+		remaining[node] = len(waitsFor)
+		if remaining[node] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	order := []*scheduleNode{}
+	for len(ready) > 0 {
+		sort.SliceStable(ready, func(i, j int) bool {
+			first, second := nodePriority(ready[i]), nodePriority(ready[j])
+			if first != second {
+				return first > second
+			}
+
+			return ready[i].dependency.Name() < ready[j].dependency.Name()
+		})
+
+		node := ready[0]
+		ready = ready[1:]
+		order = append(order, node)
+
+		_, waiting := node.waitsFor(purge)
+		for _, other := range waiting {
+			remaining[other]--
+			if remaining[other] == 0 {
+				ready = append(ready, other)
+			}
+		}
+	}
+
+	return order
+}
+
+func nodePriority(node *scheduleNode) int {
+	if node.dependency.DependencyConfig() == nil {
+		return 0
+	}
+
+	return node.dependency.DependencyConfig().Priority
+}
+
+// nodeWeight returns the build weight of the node. Only dependencies that are run by this
+// pipeline count against the weight budget
+func nodeWeight(node *scheduleNode) int {
+	if node.lockType != registry.Locked {
+		return 0
+	} else if node.dependency.DependencyConfig() == nil || node.dependency.DependencyConfig().BuildWeight == nil {
+		return 1
+	}
+
+	return *node.dependency.DependencyConfig().BuildWeight
+}
+
+// scheduleQueue holds the nodes that are ready to be started
+type scheduleQueue struct {
+	ready []*scheduleNode
+
+	// order is the fixed order of a deterministic run and next is
+	// the position of the next node that is started
+	order []*scheduleNode
+	next  int
+}
+
+// newScheduleQueue creates a queue for the graph. If the run is deterministic, the nodes
+// are started in the topological order of the graph
+func newScheduleQueue(graph *scheduleGraph, options types.DependencyOptions, purge bool) *scheduleQueue {
+	queue := &scheduleQueue{}
+	if options.Deterministic {
+		queue.order = graph.topologicalOrder(purge)
+	}
+
+	return queue
+}
+
+func (q *scheduleQueue) push(node *scheduleNode) {
+	node.ready = time.Now()
+	q.ready = append(q.ready, node)
+}
+
+func (q *scheduleQueue) len() int {
+	return len(q.ready)
+}
+
+// peek returns the next node to start without removing it from the queue. Without a fixed order, high
+// priority and long running nodes are started first. With a fixed order, nil is returned if the next node
+// in that order is not ready yet, even if other nodes are ready
+func (q *scheduleQueue) peek() *scheduleNode {
+	index := q.nextIndex()
+	if index < 0 {
+		return nil
+	}
+
+	return q.ready[index]
+}
+
+// pop removes the next node to start from the queue and marks it as picked
+func (q *scheduleQueue) pop() *scheduleNode {
+	index := q.nextIndex()
+	if index < 0 {
+		return nil
+	}
+
+	node := q.ready[index]
+	q.ready = append(q.ready[:index], q.ready[index+1:]...)
+	node.picked = true
+	return node
+}
+
+func (q *scheduleQueue) nextIndex() int {
+	if len(q.ready) == 0 {
+		return -1
+	} else if q.order == nil {
+		q.ready = sortNodesByPriority(q.ready)
+		return 0
+	}
+
+	for q.next < len(q.order) && q.order[q.next].picked {
+		q.next++
+	}
+	if q.next == len(q.order) {
+		return -1
+	}
+	for i, node := range q.ready {
+		if node == q.order[q.next] {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// scheduleResult is sent when a dependency is finished. Ran is false if the dependency was
+// not run by this pipeline
+type scheduleResult struct {
+	node     *scheduleNode
+	worker   int
+	ran      bool
+	err      error
+	duration time.Duration
+}
+
+// workerSlots hands out the numbers of the workers that run dependencies. A finished worker's
+// number is reused by the next dependency, so the numbers never exceed the concurrency
+type workerSlots struct {
+	used []bool
+}
+
+// take returns the lowest free worker number, starting at 1
+func (w *workerSlots) take() int {
+	for i, used := range w.used {
+		if !used {
+			w.used[i] = true
+			return i + 1
+		}
+	}
+
+	w.used = append(w.used, true)
+	return len(w.used)
+}
+
+// release frees the worker number again
+func (w *workerSlots) release(worker int) {
+	w.used[worker-1] = false
+}
+
+// collectScheduleNodes locks the selected dependencies and adds them with their children to the graph. Edges
+// that would create a cycle are left out, so that the graph can always be scheduled
+func (p *pipeline) collectScheduleNodes(ctx devspacecontext.Context, fromDependency string, parent *scheduleNode, dependencies []types2.Dependency, options types.DependencyOptions, graph *scheduleGraph) error {
+	dependencyNames := []string{}
+	for _, dependency := range dependencies {
+		if !selectDependency(dependency, options) || stringutil.Contains(options.Exclude, dependency.Name()) {
+			ctx.Log().Debugf("Skipping dependency %s because it was excluded", dependency.Name())
+			continue
+		}
+
+		dependencyNames = append(dependencyNames, dependency.Name())
+	}
+	if len(dependencyNames) == 0 {
+		return nil
+	}
+
+	lockedDependencies, err := p.dependencyRegistry.TryLockDependencies(ctx, fromDependency, dependencyNames, false)
+	if err != nil {
+		return err
+	}
+
+	for _, dependency := range dependencies {
+		lockType, ok := lockedDependencies[dependency.Name()]
+		if !ok {
+			continue
+		} else if lockType == registry.InUseCyclic {
+			ctx.Log().Infof("Skipping dependency %s as it was already deployed (cyclic)", dependency.Name())
+			continue
+		}
+
+		node, ok := graph.nodes[dependency.Name()]
+		if !ok {
+			node = &scheduleNode{
+				ctx:        ctx,
+				dependency: dependency,
+				lockType:   lockType,
+				options:    options,
+			}
+			graph.nodes[dependency.Name()] = node
+			graph.order = append(graph.order, node)
+
+			// only follow the children of dependencies that are run by this pipeline and
+			// that would run their children within their own pipeline
+			if lockType == registry.Locked {
+				pipelineOptions := p.dependencyPipelineOptions(ctx, dependency, options).DependencyOptions
+				calls := runsDependencies(dependency, options.Pipeline, pipelineOptions)
+				node.scheduled = calls != nil
+				for _, call := range calls {
+					err = p.collectScheduleNodes(ctx.AsDependency(dependency), dependency.Name(), node, call.dependencies, call.childOptions(options), graph)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if parent != nil {
+			parent.children = append(parent.children, node)
+			node.parents = append(node.parents, parent)
+		} else {
+			node.direct = true
+		}
+	}
+
+	return nil
+}
+
+// runScheduledDependency runs the pipeline of the dependency with the options of the call that selected it if
+// it was locked by this pipeline or waits for the pipeline that runs it. Returns false if the dependency was
+// not run by this pipeline
+func (p *pipeline) runScheduledDependency(node *scheduleNode, worker int) (bool, error) {
+	switch node.lockType {
+	case registry.Locked:
+		options := node.options
+		options.Scheduled = node.scheduled
+		return true, p.startNewDependency(node.ctx, node.dependency, options, worker)
+	case registry.InUse:
+		node.ctx.Log().Infof("Skipping dependency %s as it was already deployed", node.dependency.Name())
+		waitForDependency(node.ctx.Context(), p, node.dependency.Name(), node.ctx.Log())
+	case registry.InUseByOtherInstance:
+		node.ctx.Log().Infof("Skipping dependency %s as it is currently in use by another DevSpace instance in the same namespace", node.dependency.Name())
+	}
+
+	return false, nil
+}
+
+// dependencyCall is a run_dependencies call of a dependency pipeline with the dependencies
+// it runs and the options it runs them with
+type dependencyCall struct {
+	dependencies []types2.Dependency
+	options      types.DependencyOptions
+}
+
+// childOptions returns the options of the scheduler with the pipeline, flags and filters of the call
+func (c dependencyCall) childOptions(options types.DependencyOptions) types.DependencyOptions {
+	options.Pipeline = c.options.Pipeline
+	options.SetFlag = c.options.SetFlag
+	options.Exclude = c.options.Exclude
+	options.Only = c.options.Only
+	options.Tags = c.options.Tags
+	return options
+}
+
+// runsDependencies returns the run_dependencies calls of the pipeline that is executed for the dependency, if the
+// scheduling pipeline can run them instead. This is not the case if a call only runs depending on the control
+// flow of the script or if its arguments can only be known at runtime. A purge pipeline purges its dependencies
+// after the pipeline itself, so its calls can only be scheduled if nothing else runs after them. The arguments of
+// the calls are parsed on top of the given options of the dependency pipeline, as run_dependencies would do
+func runsDependencies(dependency types2.Dependency, pipeline string, options types.DependencyOptions) []dependencyCall {
+	executePipeline := dependencyPipelineName(dependency, pipeline)
+	pipelineConfig, err := dependencyPipelineConfig(dependency, executePipeline)
+	if err != nil {
+		return nil
+	}
+
+	plan, err := dryrun.New(dependency.Config().Config(), pipelineConfig)
+	if err != nil {
+		return nil
+	}
+
+	calls := []dependencyCall{}
+	for _, step := range plan.Steps {
+		if !isRunDependencies(step.Command) {
+			continue
+		} else if step.Conditional || (executePipeline == purgePipeline && stepHasSuccessors(plan, step)) {
+			return nil
+		}
+
+		call, ok := parseDependencyCall(dependency, step.Args, options)
+		if !ok || (executePipeline == purgePipeline && call.options.Pipeline != purgePipeline) {
+			return nil
+		}
+
+		calls = append(calls, call)
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+
+	return calls
+}
+
+// parseDependencyCall parses the arguments of a run_dependencies call and resolves the children of the dependency
+// it runs. Returns false if the arguments are invalid or contain expansions
+func parseDependencyCall(dependency types2.Dependency, args []string, options types.DependencyOptions) (dependencyCall, bool) {
+	for _, arg := range args {
+		if strings.Contains(arg, "$") {
+			return dependencyCall{}, false
+		}
+	}
+
+	callOptions := &commands.RunDependencyPipelinesOptions{DependencyOptions: options}
+	names, err := flags.NewParser(callOptions, flags.Default&^flags.PrintErrors).ParseArgs(args)
+	if err != nil {
+		return dependencyCall{}, false
+	}
+
+	call := dependencyCall{options: callOptions.DependencyOptions}
+	if callOptions.All {
+		for _, child := range dependency.Children() {
+			if !stringutil.Contains(callOptions.Except, child.Name()) {
+				call.dependencies = append(call.dependencies, child)
+			}
+		}
+
+		return call, true
+	} else if len(names) == 0 {
+		return dependencyCall{}, false
+	}
+
+	for _, name := range names {
+		found := false
+		for _, child := range dependency.Children() {
+			if child.Name() == name {
+				call.dependencies = append(call.dependencies, child)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return dependencyCall{}, false
+		}
+	}
+
+	return call, true
+}
+
+// isRunDependencies checks if the command runs the dependencies of the pipeline
+func isRunDependencies(command string) bool {
+	return command == "run_dependencies" || command == "run_dependency_pipelines"
+}
+
+// stepHasSuccessors checks if any other step of the plan waits for the given step
+func stepHasSuccessors(plan *dryrun.Plan, step *dryrun.Step) bool {
+	for _, other := range plan.Steps {
+		for _, id := range other.Needs {
+			if id == step.ID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sortNodesByPriority orders the nodes by the priority of their dependencies
+func sortNodesByPriority(nodes []*scheduleNode) []*scheduleNode {
+	byName := map[string]*scheduleNode{}
+	dependencies := []types2.Dependency{}
+	for _, node := range nodes {
+		byName[node.dependency.Name()] = node
+		dependencies = append(dependencies, node.dependency)
+	}
+
+	sorted := []*scheduleNode{}
+	for _, dependency := range dependencypkg.SortByPriority(dependencies) {
+		sorted = append(sorted, byName[dependency.Name()])
+	}
+	return sorted
+}
@@ -12,6 +12,7 @@ import (
 	"mvdan.cc/sh/v3/expand"
 	"os"
 	"sync"
+	"time"
 )
 
 type Job struct {
@@ -118,6 +119,12 @@ func (j *Job) execute(ctx devspacecontext.Context, args []string, parent *tomb.T
 	})
 
 	handler := pipelinehandler.NewPipelineExecHandler(ctx, stdoutWriter, stderrWriter, j.Pipeline)
+	if j.Config.StepTimeout > 0 {
+		handler = &stepTimeoutHandler{
+			handler: handler,
+			timeout: time.Duration(j.Config.StepTimeout) * time.Second,
+		}
+	}
 	_, err := engine.ExecutePipelineShellCommand(ctx.Context(), j.Config.Run, args, ctx.WorkingDir(), j.Config.ContinueOnError, stdoutWriter, stderrWriter, os.Stdin, environ, handler)
 	return err
 }
@@ -0,0 +1,110 @@
+package pipeline
+
+import (
+	"sort"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// schedulerMetrics records how long the dependencies waited for a free worker and how busy the
+// workers were, so that the concurrency of the dependencies can be tuned
+type schedulerMetrics struct {
+	start time.Time
+
+	// slots is the number of workers, 0 if the number of workers is unlimited
+	slots   int
+	running int
+	peak    int
+
+	ready  map[string]time.Time
+	waited map[string]time.Duration
+	ran    map[string]time.Duration
+	busy   time.Duration
+
+	maxQueued int
+}
+
+func newSchedulerMetrics(slots int) *schedulerMetrics {
+	return &schedulerMetrics{
+		start:  time.Now(),
+		slots:  slots,
+		ready:  map[string]time.Time{},
+		waited: map[string]time.Duration{},
+		ran:    map[string]time.Duration{},
+	}
+}
+
+// Ready records that the dependency can be started, because all dependencies it waits for are finished.
+// Dependencies that are not reported are ready since the scheduler was started
+func (m *schedulerMetrics) Ready(name string) {
+	m.ready[name] = time.Now()
+}
+
+// Started records that a worker started the dependency while the given number of dependencies were still queued
+func (m *schedulerMetrics) Started(name string, queued int) {
+	ready, ok := m.ready[name]
+	if !ok {
+		ready = m.start
+	}
+	m.waited[name] = time.Since(ready)
+
+	m.running++
+	if m.running > m.peak {
+		m.peak = m.running
+	}
+	if queued > m.maxQueued {
+		m.maxQueued = queued
+	}
+}
+
+// Finished records that the dependency ran for the given duration
+func (m *schedulerMetrics) Finished(name string, duration time.Duration) {
+	m.running--
+	m.ran[name] = duration
+	m.busy += duration
+}
+
+// Utilization returns the share of the time the workers were busy, between 0 and 1. If the
+// number of workers is unlimited, the most workers that were busy at once are used instead
+func (m *schedulerMetrics) Utilization() float64 {
+	slots := m.slots
+	if slots == 0 {
+		slots = m.peak
+	}
+
+	elapsed := time.Since(m.start)
+	if slots == 0 || elapsed <= 0 {
+		return 0
+	}
+
+	return float64(m.busy) / (float64(slots) * float64(elapsed))
+}
+
+// Print logs the summary, the dependencies that waited longest come first
+func (m *schedulerMetrics) Print(logger log.Logger, debug bool) {
+	printf := logger.Infof
+	if debug {
+		printf = logger.Debugf
+	}
+	if len(m.ran) == 0 {
+		return
+	}
+
+	printf("Dependencies took %s, workers were busy %.0f%% of the time with at most %d running and %d queued dependencies", time.Since(m.start).Round(time.Millisecond), m.Utilization()*100, m.peak, m.maxQueued)
+
+	names := []string{}
+	for name := range m.ran {
+		names = append(names, name)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		if m.waited[names[i]] != m.waited[names[j]] {
+			return m.waited[names[i]] > m.waited[names[j]]
+		}
+
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		printf("Dependency %s waited %s for a worker and ran %s", name, m.waited[name].Round(time.Millisecond), m.ran[name].Round(time.Millisecond))
+	}
+}
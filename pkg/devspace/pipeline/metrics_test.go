@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	types2 "github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+)
+
+func TestSchedulerMetrics(t *testing.T) {
+	metrics := newSchedulerMetrics(2)
+	metrics.start = time.Now().Add(-time.Second)
+
+	// api was ready since the start, web only now
+	metrics.Started("api", 1)
+	metrics.Ready("web")
+	metrics.Started("web", 0)
+	metrics.Finished("api", 500*time.Millisecond)
+	metrics.Finished("web", 500*time.Millisecond)
+
+	// two workers were busy for one of two seconds
+	utilization := metrics.Utilization()
+	assert.Assert(t, utilization > 0.45 && utilization <= 0.5, utilization)
+
+	out := &bytes.Buffer{}
+	metrics.Print(log.NewStreamLogger(out, out, logrus.InfoLevel), false)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, len(lines), 3, out.String())
+	assert.Assert(t, strings.Contains(lines[0], "with at most 2 running and 1 queued dependencies"), lines[0])
+	assert.Assert(t, strings.Contains(lines[1], "Dependency api waited 1s for a worker and ran 500ms"), lines[1])
+	assert.Assert(t, strings.Contains(lines[2], "Dependency web waited 0s for a worker and ran 500ms"), lines[2])
+
+	// the summary is only logged in debug mode unless requested
+	out.Reset()
+	metrics.Print(log.NewStreamLogger(out, out, logrus.InfoLevel), true)
+	assert.Equal(t, out.String(), "")
+
+	// without a limit, the most workers that were busy at once are used
+	metrics.slots = 0
+	utilization = metrics.Utilization()
+	assert.Assert(t, utilization > 0.45 && utilization <= 0.5, utilization)
+}
+
+func TestScheduleDependenciesMetrics(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	dependencies := []types2.Dependency{}
+	for _, name := range []string{"dep1", "dep2", "dep3"} {
+		dependencies = append(dependencies, newFakeDependency(t, name, map[string]*latest.Pipeline{
+			"deploy": recordingPipeline("deploy", logFile, "sleep 0.1"),
+		}))
+	}
+
+	out := &bytes.Buffer{}
+	p, ctx := newTestPipeline(t, types.Options{}, dependencies...)
+	ctx = ctx.WithLogger(log.NewStreamLogger(out, out, logrus.InfoLevel))
+	err := p.StartNewDependencies(ctx, dependencies, types.DependencyOptions{MaxConcurrentDependencies: 1, Trace: true})
+	assert.NilError(t, err)
+
+	// the dependencies queue behind the only worker and the summary reports every dependency
+	assert.Assert(t, strings.Contains(out.String(), "with at most 1 running and 2 queued dependencies"), out.String())
+	for _, dependency := range dependencies {
+		assert.Assert(t, strings.Contains(out.String(), "Dependency "+dependency.Name()+" waited "), out.String())
+	}
+}
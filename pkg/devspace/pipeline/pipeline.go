@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -15,15 +16,21 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"mvdan.cc/sh/v3/expand"
 
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader/variable/runtime"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	dependencypkg "github.com/loft-sh/devspace/pkg/devspace/dependency"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/registry"
 	types2 "github.com/loft-sh/devspace/pkg/devspace/dependency/types"
 	"github.com/loft-sh/devspace/pkg/devspace/devpod"
+	"github.com/loft-sh/devspace/pkg/devspace/hook"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/checkpoint"
 	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/loft-sh/devspace/pkg/devspace/plugin"
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/randutil"
 	"github.com/loft-sh/devspace/pkg/util/stringutil"
+	"github.com/loft-sh/devspace/pkg/util/timing"
 	"github.com/pkg/errors"
 )
 
@@ -186,17 +193,33 @@ func (p *pipeline) Dependencies() map[string]types.Pipeline {
 }
 
 func (p *pipeline) Run(ctx devspacecontext.Context, args []string) error {
-	return p.executeJob(ctx, p.main, args, ctx.Environ())
+	return p.executeJob(ctx, p.main, args, ctx.Environ(), 0)
 }
 
 func (p *pipeline) StartNewDependencies(ctx devspacecontext.Context, dependencies []types2.Dependency, options types.DependencyOptions) error {
 	// mark all commands from here that they are running within a dependency
 	ctx = ctx.WithContext(values.WithDependency(ctx.Context(), true))
+	if options.DryRun {
+		return dryRunDependencies(ctx, dependencies, options, map[string]bool{})
+	} else if options.Scheduled {
+		// children are run by the top most pipeline that schedules all levels
+		ctx.Log().Debugf("Dependencies of %s are run by the parent pipeline", p.name)
+		return nil
+	} else if options.Pipeline == purgePipeline {
+		// children are purged in reverse order
+		return p.purgeDependencies(ctx, dependencies, options)
+	} else if (options.MaxConcurrentDependencies > 0 || options.MaxWeight > 0) && !options.Sequential {
+		// children are run as soon as their own dependencies are finished, so that
+		// no worker waits for the dependencies of the dependency it runs
+		return p.scheduleDependencies(ctx, dependencies, options)
+	}
+
 	dependencyNames := []string{}
 	for _, dependency := range dependencies {
 		dependencyNames = append(dependencyNames, dependency.Name())
 	}
 
+	trace := newSchedulerTrace(ctx, p.name, options)
 	lockedDependencies, err := p.dependencyRegistry.TryLockDependencies(ctx, p.name, dependencyNames, false)
 	if err != nil {
 		return errors.Wrap(err, "check if dependencies can be deployed")
@@ -204,17 +227,23 @@ func (p *pipeline) StartNewDependencies(ctx devspacecontext.Context, dependencie
 
 	deployDependencies := []types2.Dependency{}
 	for _, dependency := range dependencies {
-		if len(options.Only) > 0 && !stringutil.Contains(options.Only, dependency.Name()) {
+		if !selectDependency(dependency, options) {
 			ctx.Log().Debugf("Skipping dependency %s because it was excluded", dependency.Name())
+			trace.Tracef("Dependency %s is not selected by the filters", dependency.Name())
 			continue
 		} else if stringutil.Contains(options.Exclude, dependency.Name()) {
 			ctx.Log().Debugf("Skipping dependency %s because it was excluded", dependency.Name())
+			trace.Tracef("Dependency %s is excluded", dependency.Name())
 			continue
-		} else if lockedDependencies[dependency.Name()] != registry.Locked {
+		}
+
+		trace.Tracef("Dependency %s is %s", dependency.Name(), lockTypeName(lockedDependencies[dependency.Name()]))
+		if lockedDependencies[dependency.Name()] != registry.Locked {
 			// search for dependency pipeline and wait
 			if lockedDependencies[dependency.Name()] == registry.InUse {
 				ctx.Log().Infof("Skipping dependency %s as it was already deployed", dependency.Name())
 				waitForDependency(ctx.Context(), p, dependency.Name(), ctx.Log())
+				trace.Tracef("Dependency %s was finished by another pipeline after %s", dependency.Name(), trace.Since(time.Time{}))
 			} else if lockedDependencies[dependency.Name()] == registry.InUseCyclic {
 				ctx.Log().Infof("Skipping dependency %s as it was already deployed (cyclic)", dependency.Name())
 			} else if lockedDependencies[dependency.Name()] == registry.InUseByOtherInstance {
@@ -225,47 +254,188 @@ func (p *pipeline) StartNewDependencies(ctx devspacecontext.Context, dependencie
 
 		deployDependencies = append(deployDependencies, dependency)
 	}
+	if options.Deterministic {
+		sort.SliceStable(deployDependencies, func(i, j int) bool {
+			return deployDependencies[i].Name() < deployDependencies[j].Name()
+		})
+	}
+	for _, dependency := range deployDependencies {
+		options.Events.Emit(types2.Event{Type: types2.EventPending, Dependency: dependency.Name()})
+	}
+
+	// collect the errors of failed dependencies instead of stopping the other dependencies
+	failed := &failedDependencies{}
 
 	// Start sequentially
 	if options.Sequential {
 		ctx.Log().Debug("Deploying dependencies sequentially")
-		for _, dependency := range deployDependencies {
-			err := p.startNewDependency(ctx, dependency, options)
+		for i, dependency := range deployDependencies {
+			if ctx.Context().Err() != nil {
+				cancelDependencies(options, trace, deployDependencies[i:])
+				return ctx.Context().Err()
+			}
+
+			err := p.runDependencyOnWorker(ctx, dependency, options, trace, 1)
 			if err != nil {
+				if options.ContinueOnError {
+					failed.add(ctx, dependency, err)
+					continue
+				}
+
 				return errors.Wrapf(err, "run dependency %s", dependency.Name())
 			} else {
 				ctx.Log().Debugf("Dependency '%s' deployed", dependency.Name())
 			}
 		}
 
-		return nil
+		return failed.aggregate()
 	}
 
-	// Start concurrently
+	// Start concurrently, every dependency runs on its own worker. A limited number
+	// of workers is handled by scheduleDependencies
 	ctx, t := ctx.WithNewTomb()
 	t.Go(func() error {
-		for _, dependency := range deployDependencies {
-			func(dependency types2.Dependency) {
+		for i, dependency := range deployDependencies {
+			// stop handing out dependencies if the pipeline was cancelled or a dependency failed
+			if !t.Alive() {
+				cancelDependencies(options, trace, deployDependencies[i:])
+				return nil
+			}
+
+			func(dependency types2.Dependency, worker int) {
 				t.Go(func() error {
-					return p.startNewDependency(ctx, dependency, options)
+					err := p.runDependencyOnWorker(ctx, dependency, options, trace, worker)
+					if err != nil && options.ContinueOnError {
+						failed.add(ctx, dependency, err)
+						return nil
+					}
+
+					return err
 				})
-			}(dependency)
+			}(dependency, i+1)
 		}
 		return nil
 	})
 
-	return t.Wait()
+	err = t.Wait()
+	if err != nil {
+		return err
+	}
+
+	return failed.aggregate()
+}
+
+// runDependencyOnWorker starts the dependency and traces when the worker picked and finished it
+func (p *pipeline) runDependencyOnWorker(ctx devspacecontext.Context, dependency types2.Dependency, options types.DependencyOptions, trace *schedulerTrace, worker int) error {
+	trace.Tracef("Worker %d picked dependency %s after waiting %s", worker, dependency.Name(), trace.Since(time.Time{}))
+	start := time.Now()
+	err := p.startNewDependency(ctx, dependency, options, worker)
+	if err != nil {
+		trace.Tracef("Worker %d failed dependency %s after %s: %v", worker, dependency.Name(), time.Since(start).Round(time.Millisecond), err)
+	} else {
+		trace.Tracef("Worker %d finished dependency %s after %s", worker, dependency.Name(), time.Since(start).Round(time.Millisecond))
+	}
+
+	return err
+}
+
+// cancelDependencies reports the pending dependencies that are not started anymore
+func cancelDependencies(options types.DependencyOptions, trace *schedulerTrace, dependencies []types2.Dependency) {
+	for _, dependency := range dependencies {
+		trace.Tracef("Dependency %s is not started, because the pipeline is stopping", dependency.Name())
+		options.Events.Emit(types2.Event{Type: types2.EventCancelled, Dependency: dependency.Name()})
+	}
+}
+
+// failedDependencies collects the errors of dependencies that failed while
+// the other dependencies continue to run
+type failedDependencies struct {
+	m      sync.Mutex
+	errors []error
+}
+
+func (f *failedDependencies) add(ctx devspacecontext.Context, dependency types2.Dependency, err error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	ctx.Log().Errorf("Dependency %s failed, continuing with the other dependencies: %v", dependency.Name(), err)
+	f.errors = append(f.errors, errors.Wrapf(err, "run dependency %s", dependency.Name()))
+}
+
+func (f *failedDependencies) aggregate() error {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	return utilerrors.NewAggregate(f.errors)
+}
+
+// dryRunDependencies reports which of the given dependencies and their children would be built or
+// deployed and why, without locking or running any of them
+func dryRunDependencies(ctx devspacecontext.Context, dependencies []types2.Dependency, options types.DependencyOptions, visited map[string]bool) error {
+	for _, dependency := range dependencies {
+		if visited[dependency.Name()] {
+			continue
+		} else if !selectDependency(dependency, options) || stringutil.Contains(options.Exclude, dependency.Name()) {
+			continue
+		}
+		visited[dependency.Name()] = true
+
+		// children are run by the pipeline of the dependency with the same filters
+		err := dryRunDependencies(ctx, dependency.Children(), options, visited)
+		if err != nil {
+			return err
+		}
+
+		status, err := dependencypkg.Status(ctx.AsDependency(dependency), dependency)
+		if err != nil {
+			return errors.Wrapf(err, "dry run dependency %s", dependency.Name())
+		}
+
+		switch status.State {
+		case dependencypkg.DependencyStateNotDeployed:
+			ctx.Log().Infof("Dependency %s would be deployed: %s", dependency.Name(), strings.Join(status.Reasons, ", "))
+		case dependencypkg.DependencyStateOutOfDate:
+			ctx.Log().Infof("Dependency %s would be updated: %s", dependency.Name(), strings.Join(status.Reasons, ", "))
+		default:
+			ctx.Log().Infof("Dependency %s is up to date", dependency.Name())
+		}
+	}
+
+	return nil
 }
 
-func ensureNamespace(ctx devspacecontext.Context, namespace string) error {
-	// If localregistry namespace is the same as devspace, we don't have
-	// anything to do.
-	if namespace == ctx.KubeClient().Namespace() || namespace == "" {
-		ctx.Log().Debugf("Namespace %s is the default Devspace namespace", namespace)
+// selectDependency checks if the dependency was selected by its name or one of its tags. If
+// neither names nor tags are specified, all dependencies are selected
+func selectDependency(dependency types2.Dependency, options types.DependencyOptions) bool {
+	if len(options.Only) == 0 && len(options.Tags) == 0 {
+		return true
+	} else if stringutil.Contains(options.Only, dependency.Name()) {
+		return true
+	} else if dependency.DependencyConfig() == nil {
+		return false
+	}
+
+	for _, tag := range dependency.DependencyConfig().Tags {
+		if stringutil.Contains(options.Tags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ensureDependencyNamespace creates the namespace of the dependency with the client of the dependency,
+// if the dependency runs in another namespace or kube context than its parent
+func ensureDependencyNamespace(ctx devspacecontext.Context, dependency types2.Dependency) error {
+	client := dependency.KubeClient()
+	if client == nil {
+		return nil
+	} else if ctx.KubeClient() != nil && client.CurrentContext() == ctx.KubeClient().CurrentContext() && client.Namespace() == ctx.KubeClient().Namespace() {
+		ctx.Log().Debugf("Namespace %s is the default Devspace namespace", client.Namespace())
 		return nil
 	}
 
-	return kubectl.EnsureNamespace(ctx.Context(), ctx.KubeClient(), namespace, ctx.Log())
+	return kubectl.EnsureNamespace(ctx.Context(), client, client.Namespace(), ctx.Log())
 }
 
 func waitForDependency(ctx context.Context, start types.Pipeline, dependencyName string, log log.Logger) {
@@ -331,17 +501,10 @@ func (p *pipeline) StartNewPipelines(ctx devspacecontext.Context, pipelines []*l
 		return nil
 	}
 
-	// Start concurrently
+	// Start concurrently, at most max concurrent pipelines at the same time
 	ctx, t := ctx.WithNewTomb()
-	t.Go(func() error {
-		for _, configPipeline := range pipelines {
-			func(configPipeline *latest.Pipeline) {
-				t.Go(func() error {
-					return p.startNewPipeline(ctx, configPipeline, randutil.GenerateRandomString(5), options)
-				})
-			}(configPipeline)
-		}
-		return nil
+	t.GoLimited(options.MaxConcurrent, len(pipelines), func(i int) error {
+		return p.startNewPipeline(ctx, pipelines[i], randutil.GenerateRandomString(5), options)
 	})
 
 	return t.Wait()
@@ -360,7 +523,7 @@ func (p *pipeline) startNewPipeline(ctx devspacecontext.Context, configPipeline
 	}
 	defer p.removeJob(j, id)
 
-	err = p.executeJob(ctx, j, nil, options.Environ)
+	err = p.executeJob(ctx, j, nil, options.Environ, options.Timeout)
 	if err != nil {
 		return err
 	}
@@ -368,58 +531,243 @@ func (p *pipeline) startNewPipeline(ctx devspacecontext.Context, configPipeline
 	return nil
 }
 
-func (p *pipeline) startNewDependency(ctx devspacecontext.Context, dependency types2.Dependency, options types.DependencyOptions) error {
+func (p *pipeline) startNewDependency(ctx devspacecontext.Context, dependency types2.Dependency, options types.DependencyOptions, worker int) error {
 	// find the dependency pipeline to execute
-	executePipeline := options.Pipeline
-	if executePipeline == "" {
-		if dependency.DependencyConfig().Pipeline != "" {
-			executePipeline = dependency.DependencyConfig().Pipeline
-		} else {
-			executePipeline = "deploy"
+	executePipeline := dependencyPipelineName(dependency, options.Pipeline)
+
+	// dependencies that only run commands are skipped if their files didn't change
+	if executePipeline != purgePipeline && !p.options.BuildOptions.ForceRebuild {
+		unchanged, err := dependencypkg.VirtualUnchanged(ctx.Context(), dependency)
+		if err != nil {
+			return err
+		} else if unchanged {
+			ctx.Log().Infof("Skipping dependency %s as its files didn't change since its commands ran", dependency.Name())
+			options.Events.Track(dependency.Name(), executePipeline)(nil)
+			return nil
 		}
 	}
 
-	// find pipeline
-	var (
-		pipelineConfig *latest.Pipeline
-		err            error
-	)
+	// deployments of protected dependencies and their children are only purged with force purge, even if
+	// they are purged by a custom pipeline
+	if dependency.DependencyConfig() != nil && dependency.DependencyConfig().Protect {
+		ctx = ctx.WithContext(values.WithProtectedDependency(ctx.Context(), dependency.Name()))
+	}
 
 	// Ensure dependency namespace exists
-	err = ensureNamespace(ctx, dependency.DependencyConfig().Namespace)
+	err := ensureDependencyNamespace(ctx, dependency)
 	if err != nil {
 		return errors.Wrapf(err, "cannot run dependency %s", dependency.Name())
 	}
 
-	if dependency.Config().Config().Pipelines == nil || dependency.Config().Config().Pipelines[executePipeline] == nil {
-		pipelineConfig, err = types.GetDefaultPipeline(executePipeline)
+	// find pipeline
+	pipelineConfig, err := dependencyPipelineConfig(dependency, executePipeline)
+	if err != nil {
+		return err
+	}
+
+	ctx, err = applyFlags(ctx, pipelineConfig, options.SetFlag)
+	if err != nil {
+		return err
+	}
+
+	if streamLogger, ok := ctx.Log().(*log.StreamLogger); !ok || streamLogger.GetFormat() != log.RawFormat {
+		ctx = ctx.WithLogger(ctx.Log().WithPrefix(dependency.Name() + " "))
+	}
+
+	// keep the last output of the dependency to add it to the error, because the output of
+	// dependencies that run in parallel is mixed
+	output := log.NewTailBuffer(dependencyOutputTailSize)
+	ctx = ctx.WithLogger(ctx.Log().WithSink(output.Sink()))
+
+	// a failed dependency pipeline is retried with a new pipeline, so that the
+	// other dependencies don't need to be run again
+	retry := types2.RetryOptions{
+		Attempts: options.RetryAttempts,
+		Backoff:  options.RetryBackoff,
+	}
+	timeout := options.Timeout
+	if dependency.DependencyConfig() != nil && dependency.DependencyConfig().Timeout > 0 {
+		timeout = time.Duration(dependency.DependencyConfig().Timeout) * time.Second
+	}
+	pipelineOptions := p.dependencyPipelineOptions(ctx, dependency, options)
+	pipelineOptions.DependencyOptions.Scheduled = pipelineOptions.DependencyOptions.Scheduled || options.Scheduled
+	err = plugin.ExecutePluginHookWithContext(dependencyHookPayload(dependency, worker, nil), dependencyHookEvents("before", executePipeline, dependency.Name())...)
+	if err != nil {
+		return err
+	}
+
+	done := options.Events.Track(dependency.Name(), executePipeline)
+	start := time.Now()
+	var previousAttempt chan struct{}
+	err = retry.Retry(ctx.Context(), dependency.Name(), ctx.Log(), func() error {
+		// an attempt that timed out might still be stopping, it must not run at the same time as the next one
+		if previousAttempt != nil {
+			select {
+			case <-previousAttempt:
+			case <-ctx.Context().Done():
+				return ctx.Context().Err()
+			}
+		}
+
+		attemptCtx, cancelAttempt := context.WithCancel(ctx.Context())
+		defer cancelAttempt()
+
+		devCtx, _ := values.DevContextFrom(ctx.Context())
+		devCtxCancel, cancelDevCtx := context.WithCancel(devCtx)
+		dependencyCtx := ctx.WithContext(values.WithDevContext(attemptCtx, devCtxCancel))
+		dependencyDevPodManager := devpod.NewManager(cancelDevCtx)
+		pip := NewPipeline(dependency.Name(), dependencyDevPodManager, p.dependencyRegistry, pipelineConfig, pipelineOptions)
+		pip.(*pipeline).parent = p
+
+		p.m.Lock()
+		p.dependencies[dependency.Name()] = pip
+		p.m.Unlock()
+
+		attemptDone := make(chan struct{})
+		previousAttempt = attemptDone
+		err := types2.RunWithTimeout(dependencyCtx.Context(), dependency.Name(), timeout, func(timeoutCtx context.Context) error {
+			defer close(attemptDone)
+			return pip.Run(dependencyCtx.WithContext(timeoutCtx).AsDependency(dependency), nil)
+		})
 		if err != nil {
-			return err
+			// stop everything the failed attempt has started
+			cancelDevCtx()
+		}
+
+		return err
+	})
+	if err == nil {
+		err = runtime.ResolveOutputs(ctx.Context(), dependency)
+	}
+	if err == nil && executePipeline != purgePipeline {
+		err = dependencypkg.SaveDuration(dependency, time.Since(start))
+		if err == nil {
+			err = dependencypkg.SaveFingerprint(ctx.Context(), dependency)
+		}
+		if err == nil {
+			err = dependencypkg.StoreCache(ctx.Context(), options.CacheStore, dependency)
 		}
-	} else {
-		pipelineConfig = dependency.Config().Config().Pipelines[executePipeline]
 	}
 
-	ctx, err = applyFlags(ctx, pipelineConfig, options.SetFlag)
+	done(err)
 	if err != nil {
+		pluginErr := plugin.ExecutePluginHookWithContext(dependencyHookPayload(dependency, worker, map[string]interface{}{
+			"dependency_started":     start.Format(time.RFC3339),
+			"dependency_duration_ms": time.Since(start).Milliseconds(),
+			"dependency_status":      string(types2.EventFailed),
+			"dependency_error":       err.Error(),
+		}), dependencyHookEvents("error", executePipeline, dependency.Name())...)
+		if pluginErr != nil {
+			return pluginErr
+		} else if output.String() != "" {
+			return errors.Wrapf(err, "last output of dependency %s:\n%s", dependency.Name(), output.String())
+		}
+
 		return err
 	}
 
-	devCtx, _ := values.DevContextFrom(ctx.Context())
-	devCtxCancel, cancelDevCtx := context.WithCancel(devCtx)
-	ctx = ctx.WithContext(values.WithDevContext(ctx.Context(), devCtxCancel))
-	dependencyDevPodManager := devpod.NewManager(cancelDevCtx)
-	pip := NewPipeline(dependency.Name(), dependencyDevPodManager, p.dependencyRegistry, pipelineConfig, p.options)
-	pip.(*pipeline).parent = p
+	return plugin.ExecutePluginHookWithContext(dependencyHookPayload(dependency, worker, map[string]interface{}{
+		"dependency_started":     start.Format(time.RFC3339),
+		"dependency_duration_ms": time.Since(start).Milliseconds(),
+		"dependency_status":      string(types2.EventSucceeded),
+	}), dependencyHookEvents("after", executePipeline, dependency.Name())...)
+}
 
-	p.m.Lock()
-	p.dependencies[dependency.Name()] = pip
-	p.m.Unlock()
+// dependencyOutputTailSize is the number of bytes of the output of a dependency pipeline
+// that are kept to show them if the dependency fails
+var dependencyOutputTailSize = 64 * 1024
 
-	if streamLogger, ok := ctx.Log().(*log.StreamLogger); !ok || streamLogger.GetFormat() != log.RawFormat {
-		ctx = ctx.WithLogger(ctx.Log().WithPrefix(dependency.Name() + " "))
+// dependencyHookEvents returns the plugin hook events of the pipeline of a single dependency,
+// e.g. before:deployDependency:api and dependencies.beforeDeploy
+func dependencyHookEvents(stage, pipeline, name string) []string {
+	return hook.EventsForSingle(stage+":"+pipeline+"Dependency", name).With("dependencies." + stage + strings.ToUpper(pipeline[:1]) + pipeline[1:])
+}
+
+// dependencyHookPayload returns the payload of the plugin hooks of a single dependency. The worker is
+// the scheduler slot that runs the dependency and the images are the last built images of the dependency
+func dependencyHookPayload(dependency types2.Dependency, worker int, extra map[string]interface{}) map[string]interface{} {
+	images := map[string]string{}
+	if dependency.Config().LocalCache() != nil {
+		for imageConfigName, imageCache := range dependency.Config().LocalCache().ListImageCache() {
+			if imageCache.Tag != "" {
+				images[imageConfigName] = imageCache.ResolveImage() + ":" + imageCache.Tag
+			}
+		}
+	}
+
+	payload := map[string]interface{}{
+		"dependency_name":        dependency.Name(),
+		"dependency_config":      dependency.Config().Config(),
+		"dependency_config_path": dependency.Config().Path(),
+		"dependency_slot":        worker,
+		"dependency_images":      images,
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+
+	return payload
+}
+
+// dependencyPipelineName returns the name of the pipeline that is executed for the dependency
+func dependencyPipelineName(dependency types2.Dependency, pipeline string) string {
+	if pipeline != "" {
+		return pipeline
+	} else if dependency.DependencyConfig() != nil && dependency.DependencyConfig().Pipeline != "" {
+		return dependency.DependencyConfig().Pipeline
+	}
+
+	return "deploy"
+}
+
+// dependencyPipelineConfig returns the configuration of the pipeline of the dependency or the default pipeline
+func dependencyPipelineConfig(dependency types2.Dependency, pipeline string) (*latest.Pipeline, error) {
+	pipelines := dependency.Config().Config().Pipelines
+	if pipelines == nil || pipelines[pipeline] == nil {
+		return types.GetDefaultPipeline(pipeline)
+	}
+
+	return pipelines[pipeline], nil
+}
+
+// dependencyPipelineOptions returns the options for the pipeline of the given dependency. If a recursive
+// purge was requested, the name and tag filters are not applied to the children of a selected dependency,
+// so that the complete subtree is purged. Children that are also used by dependencies outside of the
+// selected subtree are excluded
+func (p *pipeline) dependencyPipelineOptions(ctx devspacecontext.Context, dependency types2.Dependency, options types.DependencyOptions) types.Options {
+	if !p.options.PurgeOptions.Recursive || (len(options.Only) == 0 && len(options.Tags) == 0) || !selectDependency(dependency, options) {
+		return p.options
+	}
+
+	// find all dependencies that are used outside of the selected subtree
+	used := map[string]bool{}
+	for _, other := range ctx.Dependencies() {
+		if !selectDependency(other, options) {
+			collectDependencyNames(other, used)
+		}
+	}
+
+	newOptions := p.options
+	newOptions.DependencyOptions.Only = nil
+	newOptions.DependencyOptions.Tags = nil
+	newOptions.DependencyOptions.Exclude = append([]string{}, options.Exclude...)
+	for name := range used {
+		ctx.Log().Debugf("Skipping dependency %s in subtree of %s, because it is also used by other dependencies", name, dependency.Name())
+		newOptions.DependencyOptions.Exclude = append(newOptions.DependencyOptions.Exclude, name)
+	}
+
+	return newOptions
+}
+
+func collectDependencyNames(dependency types2.Dependency, names map[string]bool) {
+	if names[dependency.Name()] {
+		return
+	}
+
+	names[dependency.Name()] = true
+	for _, child := range dependency.Children() {
+		collectDependencyNames(child, names)
 	}
-	return pip.Run(ctx.AsDependency(dependency), nil)
 }
 
 func applyFlags(ctx devspacecontext.Context, pipeline *latest.Pipeline, setFlags []string) (devspacecontext.Context, error) {
@@ -469,13 +817,26 @@ func (p *pipeline) removeJob(j *Job, id string) {
 	}
 }
 
-func (p *pipeline) executeJob(ctx devspacecontext.Context, j *Job, args []string, environ expand.Environ) error {
+// executeJob runs the job and cancels it, if it doesn't finish within the timeout of its pipeline or the
+// given default timeout, if the pipeline doesn't specify one
+func (p *pipeline) executeJob(ctx devspacecontext.Context, j *Job, args []string, environ expand.Environ, defaultTimeout time.Duration) error {
 	// don't start jobs on a cancelled context
 	if ctx.IsDone() {
 		return nil
 	}
 
-	err := j.Run(ctx, args, environ)
+	timeout := defaultTimeout
+	if j.Config.Timeout > 0 {
+		timeout = time.Duration(j.Config.Timeout) * time.Second
+	}
+
+	spanCtx, done := timing.Start(ctx.Context(), "pipeline "+j.Config.Name)
+	defer done()
+	ctx = ctx.WithContext(checkpoint.WithPipeline(spanCtx, j.Config.Name))
+
+	err := runWithTimeout(ctx, j.Config.Name, timeout, func(ctx devspacecontext.Context) error {
+		return j.Run(ctx, args, environ)
+	})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,652 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/build"
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/context/values"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/registry"
+	types2 "github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/devspace/deploy"
+	"github.com/loft-sh/devspace/pkg/devspace/devpod"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/loft-sh/devspace/pkg/devspace/plugin"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/stringutil"
+	"github.com/sirupsen/logrus"
+	flag "github.com/spf13/pflag"
+	"gotest.tools/assert"
+)
+
+type fakeDependency struct {
+	name             string
+	path             string
+	config           config.Config
+	children         []types2.Dependency
+	dependencyConfig *latest.DependencyConfig
+}
+
+func (f *fakeDependency) Name() string                               { return f.name }
+func (f *fakeDependency) Config() config.Config                      { return f.config }
+func (f *fakeDependency) KubeClient() kubectl.Client                 { return nil }
+func (f *fakeDependency) Children() []types2.Dependency              { return f.children }
+func (f *fakeDependency) Root() bool                                 { return false }
+func (f *fakeDependency) Path() string                               { return f.path }
+func (f *fakeDependency) DependencyConfig() *latest.DependencyConfig { return f.dependencyConfig }
+
+// newFakeDependency creates a dependency in its own folder with the given pipelines. Pipelines that
+// are not configured run the default pipelines
+func newFakeDependency(t *testing.T, name string, pipelines map[string]*latest.Pipeline, children ...types2.Dependency) *fakeDependency {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "devspace.yaml")
+	return &fakeDependency{
+		name: name,
+		path: dir,
+		config: config.NewConfig(map[string]interface{}{},
+			map[string]interface{}{},
+			&latest.Config{Name: name, Pipelines: pipelines},
+			localcache.New(localcache.CachePath(configPath)),
+			&remotecache.RemoteCache{},
+			map[string]interface{}{"DEVSPACE_NAME": name},
+			configPath),
+		children:         children,
+		dependencyConfig: &latest.DependencyConfig{Name: name},
+	}
+}
+
+// recordingPipeline returns a pipeline that writes when it started and stopped into the log file
+func recordingPipeline(name, logFile, run string) *latest.Pipeline {
+	return &latest.Pipeline{
+		Name: name,
+		Run:  `echo "start $DEVSPACE_NAME" >> ` + logFile + "\n" + run + "\necho \"end $DEVSPACE_NAME\" >> " + logFile,
+	}
+}
+
+// readRecording returns the lines that were written by recording pipelines
+func readRecording(t *testing.T, logFile string) []string {
+	out, err := os.ReadFile(logFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	assert.NilError(t, err)
+	return strings.Split(strings.TrimSpace(string(out)), "\n")
+}
+
+// maxConcurrent returns the maximum number of pipelines that were running at the same time
+func maxConcurrent(recording []string) int {
+	running, max := 0, 0
+	for _, line := range recording {
+		if strings.HasPrefix(line, "start ") {
+			running++
+		} else if strings.HasPrefix(line, "end ") {
+			running--
+		}
+		if running > max {
+			max = running
+		}
+	}
+
+	return max
+}
+
+// newTestPipeline creates the pipeline of the root project and a context that uses the given dependencies
+func newTestPipeline(t *testing.T, options types.Options, dependencies ...types2.Dependency) (*pipeline, devspacecontext.Context) {
+	root := newFakeDependency(t, "root", nil, dependencies...)
+	ctx := devspacecontext.NewContext(context.Background(), nil, log.Discard).AsDependency(root)
+	ctx = ctx.WithContext(values.WithDevContext(context.Background(), context.Background()))
+
+	p := NewPipeline("root", devpod.NewManager(func() {}), registry.NewDependencyRegistry("root", true), &latest.Pipeline{Name: "deploy"}, options).(*pipeline)
+	return p, ctx
+}
+
+func TestStartNewDependenciesMaxConcurrent(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	dependencies := []types2.Dependency{}
+	for _, name := range []string{"dep1", "dep2", "dep3", "dep4", "dep5"} {
+		dependencies = append(dependencies, newFakeDependency(t, name, map[string]*latest.Pipeline{
+			"deploy": recordingPipeline("deploy", logFile, "sleep 0.2"),
+		}))
+	}
+
+	p, ctx := newTestPipeline(t, types.Options{}, dependencies...)
+	err := p.StartNewDependencies(ctx, dependencies, types.DependencyOptions{MaxConcurrentDependencies: 2})
+	assert.NilError(t, err)
+
+	recording := readRecording(t, logFile)
+	assert.Equal(t, len(recording), 10)
+	assert.Equal(t, maxConcurrent(recording), 2)
+	for _, dependency := range dependencies {
+		assert.Assert(t, stringutil.Contains(recording, "end "+dependency.Name()), "dependency %s didn't run", dependency.Name())
+	}
+
+	// without a limit all dependencies run at the same time
+	_ = os.Remove(logFile)
+	p, ctx = newTestPipeline(t, types.Options{}, dependencies...)
+	err = p.StartNewDependencies(ctx, dependencies, types.DependencyOptions{})
+	assert.NilError(t, err)
+
+	recording = readRecording(t, logFile)
+	assert.Equal(t, len(recording), 10)
+	assert.Equal(t, maxConcurrent(recording), 5)
+}
+
+func TestPurgeDependencySubtree(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	purgePipelines := func() map[string]*latest.Pipeline {
+		pipeline := recordingPipeline("purge", logFile, "")
+		pipeline.Run += "\nrun_dependencies --all --pipeline purge"
+		return map[string]*latest.Pipeline{"purge": pipeline}
+	}
+
+	// cache is used by api and web, db only by api
+	db := newFakeDependency(t, "db", purgePipelines())
+	cache := newFakeDependency(t, "cache", purgePipelines())
+	api := newFakeDependency(t, "api", purgePipelines(), db, cache)
+	web := newFakeDependency(t, "web", purgePipelines(), cache)
+
+	// the filters of the command are passed to all pipelines
+	dependencyOptions := types.DependencyOptions{Only: []string{"api"}}
+	p, ctx := newTestPipeline(t, types.Options{PurgeOptions: deploy.PurgeOptions{Recursive: true}, DependencyOptions: dependencyOptions}, api, web)
+	err := p.StartNewDependencies(ctx, []types2.Dependency{api, web}, types.DependencyOptions{
+		Pipeline: "purge",
+		Only:     []string{"api"},
+	})
+	assert.NilError(t, err)
+
+	// api is purged before its children and shared children are kept
+	assert.DeepEqual(t, readRecording(t, logFile), []string{"start api", "end api", "start db", "end db"})
+
+	// without recursive only the selected dependency is purged
+	_ = os.Remove(logFile)
+	p, ctx = newTestPipeline(t, types.Options{DependencyOptions: dependencyOptions}, api, web)
+	err = p.StartNewDependencies(ctx, []types2.Dependency{api, web}, types.DependencyOptions{
+		Pipeline: "purge",
+		Only:     []string{"api"},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readRecording(t, logFile), []string{"start api", "end api"})
+}
+
+func TestPurgeDependencyStepsAfterChildren(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+
+	// the steps after run_dependencies run after the children were purged
+	db := newFakeDependency(t, "db", map[string]*latest.Pipeline{
+		"purge": recordingPipeline("purge", logFile, ""),
+	})
+	api := newFakeDependency(t, "api", map[string]*latest.Pipeline{
+		"purge": recordingPipeline("purge", logFile, "run_dependencies --all --pipeline purge"),
+	}, db)
+	p, ctx := newTestPipeline(t, types.Options{}, api)
+	err := p.StartNewDependencies(ctx, []types2.Dependency{api}, types.DependencyOptions{Pipeline: "purge"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readRecording(t, logFile), []string{"start api", "start db", "end db", "end api"})
+
+	// a pipeline that only mentions run_dependencies doesn't purge the children
+	_ = os.Remove(logFile)
+	db = newFakeDependency(t, "db", map[string]*latest.Pipeline{
+		"purge": recordingPipeline("purge", logFile, ""),
+	})
+	api = newFakeDependency(t, "api", map[string]*latest.Pipeline{
+		"purge": {Name: "purge", Run: `echo "run_dependencies" >> ` + logFile},
+	}, db)
+	p, ctx = newTestPipeline(t, types.Options{}, api)
+	err = p.StartNewDependencies(ctx, []types2.Dependency{api}, types.DependencyOptions{Pipeline: "purge"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readRecording(t, logFile), []string{"run_dependencies"})
+}
+
+func TestPurgeProtectedDependencyCustomPipeline(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+
+	// a custom pipeline doesn't purge the deployments of a protected dependency
+	protected := newFakeDependency(t, "protected", map[string]*latest.Pipeline{
+		"cleanup": recordingPipeline("cleanup", logFile, "purge_deployments --all"),
+	})
+	protected.dependencyConfig.Protect = true
+	p, ctx := newTestPipeline(t, types.Options{}, protected)
+	err := p.StartNewDependencies(ctx, []types2.Dependency{protected}, types.DependencyOptions{Pipeline: "cleanup"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readRecording(t, logFile), []string{"start protected", "end protected"})
+
+	// unless force purge is used, the fake dependency has no kube client to purge with
+	_ = os.Remove(logFile)
+	p, ctx = newTestPipeline(t, types.Options{PurgeOptions: deploy.PurgeOptions{ForcePurge: true}}, protected)
+	err = p.StartNewDependencies(ctx, []types2.Dependency{protected}, types.DependencyOptions{Pipeline: "cleanup"})
+	assert.ErrorContains(t, err, "exit status 1")
+	assert.DeepEqual(t, readRecording(t, logFile), []string{"start protected"})
+}
+
+func TestStartNewDependenciesDryRun(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	changed := newFakeDependency(t, "changed", map[string]*latest.Pipeline{
+		"deploy": recordingPipeline("deploy", logFile, ""),
+	})
+	changed.dependencyConfig.ChangeDetection = &latest.DependencyChangeDetection{}
+	unchanged := newFakeDependency(t, "unchanged", map[string]*latest.Pipeline{
+		"deploy": recordingPipeline("deploy", logFile, ""),
+	})
+	excluded := newFakeDependency(t, "excluded", map[string]*latest.Pipeline{
+		"deploy": recordingPipeline("deploy", logFile, ""),
+	})
+
+	out := &bytes.Buffer{}
+	p, ctx := newTestPipeline(t, types.Options{}, changed, unchanged, excluded)
+	ctx = ctx.WithLogger(log.NewStreamLogger(out, out, logrus.InfoLevel))
+	err := p.StartNewDependencies(ctx, []types2.Dependency{changed, unchanged, excluded}, types.DependencyOptions{
+		DryRun:  true,
+		Exclude: []string{"excluded"},
+	})
+	assert.NilError(t, err)
+
+	// nothing is run, but the changes are reported
+	assert.Equal(t, len(readRecording(t, logFile)), 0)
+	assert.Assert(t, strings.Contains(out.String(), "Dependency changed would be updated: files have changed"), out.String())
+	assert.Assert(t, strings.Contains(out.String(), "Dependency unchanged is up to date"), out.String())
+	assert.Assert(t, !strings.Contains(out.String(), "excluded"), out.String())
+}
+
+func TestStartNewDependenciesContinueOnError(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	newDependencies := func() []types2.Dependency {
+		return []types2.Dependency{
+			newFakeDependency(t, "broken1", map[string]*latest.Pipeline{"deploy": {Name: "deploy", Run: "exit 1"}}),
+			newFakeDependency(t, "dep1", map[string]*latest.Pipeline{"deploy": recordingPipeline("deploy", logFile, "")}),
+			newFakeDependency(t, "broken2", map[string]*latest.Pipeline{"deploy": {Name: "deploy", Run: "exit 2"}}),
+			newFakeDependency(t, "dep2", map[string]*latest.Pipeline{"deploy": recordingPipeline("deploy", logFile, "")}),
+		}
+	}
+
+	for _, sequential := range []bool{true, false} {
+		_ = os.Remove(logFile)
+		dependencies := newDependencies()
+		p, ctx := newTestPipeline(t, types.Options{}, dependencies...)
+		err := p.StartNewDependencies(ctx, dependencies, types.DependencyOptions{Sequential: sequential, ContinueOnError: true})
+
+		// all failed dependencies are reported and the others still ran
+		assert.ErrorContains(t, err, "run dependency broken1", "sequential: %v", sequential)
+		assert.ErrorContains(t, err, "run dependency broken2", "sequential: %v", sequential)
+		recording := readRecording(t, logFile)
+		assert.Assert(t, stringutil.Contains(recording, "end dep1"), "sequential: %v", sequential)
+		assert.Assert(t, stringutil.Contains(recording, "end dep2"), "sequential: %v", sequential)
+	}
+
+	// without continue on error the first failure stops the remaining dependencies
+	_ = os.Remove(logFile)
+	dependencies := newDependencies()
+	p, ctx := newTestPipeline(t, types.Options{}, dependencies...)
+	err := p.StartNewDependencies(ctx, dependencies, types.DependencyOptions{Sequential: true})
+	assert.ErrorContains(t, err, "run dependency broken1")
+	assert.Assert(t, !strings.Contains(err.Error(), "broken2"), err.Error())
+	assert.Equal(t, len(readRecording(t, logFile)), 0)
+}
+
+// fakeHookPlugin records the dependency hooks it was called for
+const fakeHookPlugin = `#!/bin/sh
+echo "$1 $DEVSPACE_PLUGIN_DEPENDENCY_NAME $DEVSPACE_PLUGIN_DEPENDENCY_SLOT $DEVSPACE_PLUGIN_DEPENDENCY_STATUS" >> "$(dirname "$0")/hooks"
+`
+
+func TestStartNewDependenciesHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin binary is a shell script")
+	}
+
+	pluginDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(pluginDir, plugin.PluginBinary), []byte(fakeHookPlugin), 0755)
+	assert.NilError(t, err)
+
+	// plugins can only be set once, so the hooks only match the dependencies of this test
+	plugin.SetPlugins([]plugin.Metadata{{
+		Name:         "dashboard",
+		PluginFolder: pluginDir,
+		Hooks: []plugin.Hook{
+			{Event: "before:deployDependency:hooked*", BaseArgs: []string{"before"}},
+			{Event: "after:deployDependency:hooked*", BaseArgs: []string{"after"}},
+			{Event: "error:deployDependency:hooked*", BaseArgs: []string{"error"}},
+		},
+	}})
+
+	dependencies := []types2.Dependency{
+		newFakeDependency(t, "hooked1", map[string]*latest.Pipeline{"deploy": {Name: "deploy", Run: "sleep 0.1"}}),
+		newFakeDependency(t, "hooked2", map[string]*latest.Pipeline{"deploy": {Name: "deploy", Run: "sleep 0.1"}}),
+		newFakeDependency(t, "hooked3", map[string]*latest.Pipeline{"deploy": {Name: "deploy", Run: "exit 1"}}),
+	}
+	p, ctx := newTestPipeline(t, types.Options{}, dependencies...)
+	err = p.StartNewDependencies(ctx, dependencies, types.DependencyOptions{MaxConcurrentDependencies: 2, ContinueOnError: true})
+	assert.ErrorContains(t, err, "run dependency hooked3")
+
+	out, err := os.ReadFile(filepath.Join(pluginDir, "hooks"))
+	assert.NilError(t, err)
+	calls := strings.Split(strings.TrimSpace(string(out)), "\n")
+	sort.Strings(calls)
+
+	// the hooks get the worker that ran the dependency, which is never higher than the limit
+	assert.Equal(t, len(calls), 6, string(out))
+	for _, call := range calls {
+		fields := strings.Fields(call)
+		assert.Assert(t, fields[2] == "1" || fields[2] == "2", call)
+		switch fields[0] {
+		case "before":
+			assert.Equal(t, len(fields), 3, call)
+		case "after":
+			assert.Equal(t, fields[3], string(types2.EventSucceeded), call)
+		case "error":
+			assert.Equal(t, fields[1], "hooked3", call)
+			assert.Equal(t, fields[3], string(types2.EventFailed), call)
+		}
+	}
+	assert.Equal(t, strings.Fields(calls[0])[0]+" "+strings.Fields(calls[0])[1], "after hooked1")
+	assert.Equal(t, strings.Fields(calls[1])[0]+" "+strings.Fields(calls[1])[1], "after hooked2")
+}
+
+func TestStartNewDependencyVirtualUnchanged(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	terraform := newFakeDependency(t, "terraform", map[string]*latest.Pipeline{
+		"deploy": recordingPipeline("deploy", logFile, ""),
+	})
+	terraform.dependencyConfig.Commands = []string{"terraform apply"}
+	assert.NilError(t, os.WriteFile(filepath.Join(terraform.path, "main.tf"), []byte("a"), 0644))
+
+	run := func(options types.Options) {
+		p, ctx := newTestPipeline(t, options, terraform)
+		err := p.StartNewDependencies(ctx, []types2.Dependency{terraform}, types.DependencyOptions{})
+		assert.NilError(t, err)
+	}
+
+	// the commands only run again if the files changed
+	run(types.Options{})
+	assert.Equal(t, len(readRecording(t, logFile)), 2)
+	run(types.Options{})
+	assert.Equal(t, len(readRecording(t, logFile)), 2)
+	assert.NilError(t, os.WriteFile(filepath.Join(terraform.path, "main.tf"), []byte("changed"), 0644))
+	run(types.Options{})
+	assert.Equal(t, len(readRecording(t, logFile)), 4)
+
+	// unless a rebuild is forced
+	run(types.Options{BuildOptions: build.Options{ForceRebuild: true}})
+	assert.Equal(t, len(readRecording(t, logFile)), 6)
+}
+
+func TestScheduleDependenciesTrace(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	db := newFakeDependency(t, "db", map[string]*latest.Pipeline{
+		"deploy": recordingPipeline("deploy", logFile, "sleep 0.3"),
+	})
+	api := newFakeDependency(t, "api", map[string]*latest.Pipeline{
+		"deploy": recordingPipeline("deploy", logFile, "run_dependencies --all"),
+	}, db)
+
+	out := &bytes.Buffer{}
+	p, ctx := newTestPipeline(t, types.Options{}, api)
+	ctx = ctx.WithLogger(log.NewStreamLogger(out, out, logrus.InfoLevel))
+	err := p.StartNewDependencies(ctx, []types2.Dependency{api}, types.DependencyOptions{MaxConcurrentDependencies: 2, Trace: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readRecording(t, logFile), []string{"start db", "end db", "start api", "end api"})
+
+	// the wait time of a dependency is measured from when it became ready instead of
+	// when the scheduler was started
+	waited := map[string]time.Duration{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		index := strings.Index(line, "Picked dependency ")
+		if index < 0 {
+			continue
+		}
+
+		fields := strings.Fields(line[index:])
+		assert.Equal(t, fields[3], "(locked)", line)
+		duration, err := time.ParseDuration(strings.TrimSuffix(fields[5], ","))
+		assert.NilError(t, err, line)
+		waited[fields[2]] = duration
+	}
+	assert.Equal(t, len(waited), 2, out.String())
+	assert.Assert(t, waited["api"] < 300*time.Millisecond, out.String())
+	assert.Assert(t, strings.Contains(out.String(), "Dependency db finished after"), out.String())
+	assert.Assert(t, strings.Contains(out.String(), "Dependency api finished after"), out.String())
+
+	// without tracing the decisions are not logged
+	out.Reset()
+	p, ctx = newTestPipeline(t, types.Options{}, api)
+	ctx = ctx.WithLogger(log.NewStreamLogger(out, out, logrus.InfoLevel))
+	err = p.StartNewDependencies(ctx, []types2.Dependency{api}, types.DependencyOptions{MaxConcurrentDependencies: 2})
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(out.String(), "Picked dependency"), out.String())
+}
+
+func TestScheduleDependenciesUnevenTree(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	deployPipelines := func(run string) map[string]*latest.Pipeline {
+		return map[string]*latest.Pipeline{"deploy": recordingPipeline("deploy", logFile, run)}
+	}
+
+	// one deep chain next to many leaves
+	chain3 := newFakeDependency(t, "chain3", deployPipelines("sleep 0.2"))
+	chain2 := newFakeDependency(t, "chain2", deployPipelines("run_dependencies --all\nsleep 0.2"), chain3)
+	chain1 := newFakeDependency(t, "chain1", deployPipelines("run_dependencies --all\nsleep 0.2"), chain2)
+	dependencies := []types2.Dependency{chain1}
+	for _, name := range []string{"leaf1", "leaf2", "leaf3", "leaf4"} {
+		dependencies = append(dependencies, newFakeDependency(t, name, deployPipelines("sleep 0.2")))
+	}
+
+	p, ctx := newTestPipeline(t, types.Options{}, dependencies...)
+	err := p.StartNewDependencies(ctx, dependencies, types.DependencyOptions{MaxConcurrentDependencies: 2})
+	assert.NilError(t, err)
+
+	// the chain is run by the scheduler instead of the pipelines of the chain, so
+	// that the leaves fill the workers that would otherwise wait for the chain
+	recording := readRecording(t, logFile)
+	assert.Equal(t, len(recording), 14)
+	assert.Equal(t, maxConcurrent(recording), 2)
+	index := func(line string) int {
+		for i, l := range recording {
+			if l == line {
+				return i
+			}
+		}
+		return -1
+	}
+	assert.Assert(t, index("end chain3") < index("start chain2"), recording)
+	assert.Assert(t, index("end chain2") < index("start chain1"), recording)
+}
+
+func TestScheduleDependenciesNestedCalls(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	migratePipelines := func() map[string]*latest.Pipeline {
+		return map[string]*latest.Pipeline{
+			"deploy": recordingPipeline("deploy", logFile, ""),
+			"migrate": {
+				Name:  "migrate",
+				Run:   `echo "migrate $DEVSPACE_NAME $(get_flag version)" >> ` + logFile,
+				Flags: []latest.PipelineFlag{{Name: "version"}},
+			},
+		}
+	}
+
+	// the pipeline, flags and filters of a nested call are used for the scheduled children
+	db := newFakeDependency(t, "db", migratePipelines())
+	cache := newFakeDependency(t, "cache", migratePipelines())
+	api := newFakeDependency(t, "api", map[string]*latest.Pipeline{
+		"deploy": recordingPipeline("deploy", logFile, "run_dependencies --all --pipeline migrate --set-flag version=2 --exclude cache"),
+	}, db, cache)
+
+	out := &bytes.Buffer{}
+	p, ctx := newTestPipeline(t, types.Options{}, api)
+	ctx = ctx.WithLogger(log.NewStreamLogger(out, out, logrus.InfoLevel))
+	ctx = ctx.WithContext(values.WithCommandFlags(ctx.Context(), flag.NewFlagSet("deploy", flag.ContinueOnError)))
+	err := p.StartNewDependencies(ctx, []types2.Dependency{api}, types.DependencyOptions{MaxConcurrentDependencies: 2, Trace: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readRecording(t, logFile), []string{"migrate db 2", "start api", "end api"})
+	assert.Assert(t, strings.Contains(out.String(), "Picked dependency db"), out.String())
+
+	// conditional calls are not scheduled, the dependency runs its children itself
+	_ = os.Remove(logFile)
+	out.Reset()
+	db = newFakeDependency(t, "db", migratePipelines())
+	api = newFakeDependency(t, "api", map[string]*latest.Pipeline{
+		"deploy": recordingPipeline("deploy", logFile, "if true; then run_dependencies --all --pipeline migrate --set-flag version=3; fi"),
+	}, db)
+	p, ctx = newTestPipeline(t, types.Options{}, api)
+	ctx = ctx.WithLogger(log.NewStreamLogger(out, out, logrus.InfoLevel))
+	ctx = ctx.WithContext(values.WithCommandFlags(ctx.Context(), flag.NewFlagSet("deploy", flag.ContinueOnError)))
+	err = p.StartNewDependencies(ctx, []types2.Dependency{api}, types.DependencyOptions{MaxConcurrentDependencies: 2, Trace: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readRecording(t, logFile), []string{"start api", "migrate db 3", "end api"})
+	assert.Assert(t, !strings.Contains(out.String(), "Picked dependency db"), out.String())
+}
+
+func TestScheduleDependenciesDeterministic(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	newDependencies := func() []types2.Dependency {
+		deployPipelines := func(run string) map[string]*latest.Pipeline {
+			return map[string]*latest.Pipeline{"deploy": recordingPipeline("deploy", logFile, run)}
+		}
+
+		db := newFakeDependency(t, "db", deployPipelines("sleep 0.4"))
+		zeta := newFakeDependency(t, "zeta", deployPipelines("sleep 0.1"))
+		zeta.dependencyConfig.Priority = 1
+		return []types2.Dependency{
+			newFakeDependency(t, "gamma", deployPipelines("sleep 0.05")),
+			newFakeDependency(t, "api", deployPipelines("run_dependencies --all"), db),
+			newFakeDependency(t, "alpha", deployPipelines("sleep 0.3")),
+			zeta,
+		}
+	}
+	picked := func(out string) []string {
+		names := []string{}
+		for _, line := range strings.Split(out, "\n") {
+			index := strings.Index(line, "Picked dependency ")
+			if index >= 0 {
+				names = append(names, strings.Fields(line[index:])[2])
+			}
+		}
+		return names
+	}
+
+	// the dependencies are started by priority and name, after the dependencies they wait for, no matter
+	// how long the other dependencies run. gamma waits for api, even though a worker is free before
+	for i := 0; i < 3; i++ {
+		out := &bytes.Buffer{}
+		dependencies := newDependencies()
+		p, ctx := newTestPipeline(t, types.Options{}, dependencies...)
+		ctx = ctx.WithLogger(log.NewStreamLogger(out, out, logrus.InfoLevel))
+		err := p.StartNewDependencies(ctx, dependencies, types.DependencyOptions{MaxConcurrentDependencies: 2, Deterministic: true, Trace: true})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, picked(out.String()), []string{"zeta", "alpha", "db", "api", "gamma"})
+	}
+
+	// without a fixed order, a free worker starts gamma while api waits for db
+	out := &bytes.Buffer{}
+	dependencies := newDependencies()
+	p, ctx := newTestPipeline(t, types.Options{}, dependencies...)
+	ctx = ctx.WithLogger(log.NewStreamLogger(out, out, logrus.InfoLevel))
+	err := p.StartNewDependencies(ctx, dependencies, types.DependencyOptions{MaxConcurrentDependencies: 2, Trace: true})
+	assert.NilError(t, err)
+	order := picked(out.String())
+	assert.Equal(t, order[len(order)-1], "api", order)
+}
+
+func TestStartNewDependencyOutputTail(t *testing.T) {
+	defer func(size int) { dependencyOutputTailSize = size }(dependencyOutputTailSize)
+	dependencyOutputTailSize = 64
+
+	broken := newFakeDependency(t, "broken", map[string]*latest.Pipeline{
+		"deploy": {Name: "deploy", Run: "for i in 0 1 2 3 4 5 6 7 8 9; do echo \"output line $i\"; done\nexit 1"},
+	})
+	p, ctx := newTestPipeline(t, types.Options{}, broken)
+	ctx = ctx.WithLogger(log.NewStreamLogger(&bytes.Buffer{}, &bytes.Buffer{}, logrus.InfoLevel))
+	err := p.StartNewDependencies(ctx, []types2.Dependency{broken}, types.DependencyOptions{MaxConcurrentDependencies: 2})
+
+	// only the last output is added to the error
+	assert.ErrorContains(t, err, "last output of dependency broken")
+	assert.ErrorContains(t, err, "output line 9")
+	assert.ErrorContains(t, err, "bytes of earlier output omitted")
+	assert.Assert(t, !strings.Contains(err.Error(), "output line 0"), err.Error())
+}
+
+func TestStartNewDependencyRetryTimeout(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	marker := filepath.Join(t.TempDir(), "marker")
+
+	// the first attempt hangs until it times out, the retry succeeds
+	slow := newFakeDependency(t, "slow", map[string]*latest.Pipeline{
+		"deploy": recordingPipeline("deploy", logFile, "if [ ! -f "+marker+" ]; then touch "+marker+"; sleep 10; fi"),
+	})
+	slow.dependencyConfig.Timeout = 1
+
+	// dependencies without a dependency config use the default timeout
+	unconfigured := newFakeDependency(t, "unconfigured", map[string]*latest.Pipeline{
+		"deploy": recordingPipeline("deploy", logFile, ""),
+	})
+	unconfigured.dependencyConfig = nil
+
+	p, ctx := newTestPipeline(t, types.Options{}, slow, unconfigured)
+	start := time.Now()
+	err := p.StartNewDependencies(ctx, []types2.Dependency{slow, unconfigured}, types.DependencyOptions{RetryAttempts: 2})
+	assert.NilError(t, err)
+	assert.Assert(t, time.Since(start) < time.Second*10, "retry waited for the hanging attempt to finish")
+
+	// the timed out attempt was stopped before the retry started
+	recording := readRecording(t, logFile)
+	slowRecording := []string{}
+	for _, line := range recording {
+		if strings.HasSuffix(line, " slow") {
+			slowRecording = append(slowRecording, line)
+		}
+	}
+	assert.DeepEqual(t, slowRecording, []string{"start slow", "start slow", "end slow"})
+	assert.Assert(t, stringutil.Contains(recording, "end unconfigured"))
+}
+
+func TestScheduleDependenciesMaxWeight(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	weights := map[string]int{"heavy1": 3, "heavy2": 3, "light1": 1, "light2": 1, "light3": 1, "huge": 10}
+	dependencies := []types2.Dependency{}
+	for _, name := range []string{"heavy1", "heavy2", "light1", "light2", "light3", "huge"} {
+		dependency := newFakeDependency(t, name, map[string]*latest.Pipeline{
+			"deploy": recordingPipeline("deploy", logFile, "sleep 0.1"),
+		})
+		weight := weights[name]
+		dependency.dependencyConfig.BuildWeight = &weight
+		dependencies = append(dependencies, dependency)
+	}
+
+	p, ctx := newTestPipeline(t, types.Options{}, dependencies...)
+	err := p.StartNewDependencies(ctx, dependencies, types.DependencyOptions{MaxWeight: 4})
+	assert.NilError(t, err)
+
+	// the running dependencies never exceed the budget and a dependency that is heavier
+	// than the whole budget runs alone
+	recording := readRecording(t, logFile)
+	assert.Equal(t, len(recording), 12)
+	running := map[string]bool{}
+	for _, line := range recording {
+		fields := strings.Fields(line)
+		running[fields[1]] = fields[0] == "start"
+
+		weight, count := 0, 0
+		for name, isRunning := range running {
+			if isRunning {
+				weight += weights[name]
+				count++
+			}
+		}
+		if running["huge"] {
+			assert.Equal(t, count, 1, recording)
+		} else {
+			assert.Assert(t, weight <= 4, recording)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package pipeline
+
+import (
+	"time"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	types2 "github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/pkg/errors"
+)
+
+// purgePipeline is the name of the pipeline whose dependencies are purged in reverse order
+const purgePipeline = "purge"
+
+// purgeDependencies purges the given dependencies and all of their children. A dependency is only purged
+// after all dependencies that use it were purged, dependencies that don't depend on each other are purged
+// in parallel. The pipelines of the dependencies don't purge their children themselves
+func (p *pipeline) purgeDependencies(ctx devspacecontext.Context, dependencies []types2.Dependency, options types.DependencyOptions) error {
+	trace := newSchedulerTrace(ctx, p.name, options)
+	graph := &scheduleGraph{nodes: map[string]*scheduleNode{}}
+	err := p.collectScheduleNodes(ctx, p.name, nil, dependencies, options, graph)
+	if err != nil {
+		return errors.Wrap(err, "check if dependencies can be purged")
+	}
+
+	// start with the dependencies no other dependency uses
+	queue := newScheduleQueue(graph, options, true)
+	for _, node := range graph.order {
+		node.remaining = len(node.parents)
+		if node.remaining == 0 {
+			queue.push(node)
+		}
+	}
+
+	limit := 0
+	if options.Sequential {
+		ctx.Log().Debug("Purging dependencies sequentially")
+		limit = 1
+	} else if options.MaxConcurrentDependencies > 0 {
+		ctx.Log().Debugf("Purging at most %d dependencies in parallel", options.MaxConcurrentDependencies)
+		limit = options.MaxConcurrentDependencies
+	}
+
+	metrics := newSchedulerMetrics(limit)
+
+	var (
+		results = make(chan scheduleResult)
+		workers = &workerSlots{}
+		running = 0
+		stopped = false
+		failed  = &failedDependencies{}
+	)
+
+	// finish marks the node as done and queues all children whose parents are finished. Children
+	// of dependencies that were skipped or failed are skipped as well
+	var finish func(node *scheduleNode, purged bool)
+	finish = func(node *scheduleNode, purged bool) {
+		node.purged = purged
+		for _, child := range node.children {
+			child.remaining--
+			if child.remaining > 0 {
+				continue
+			}
+
+			purgeChild := child.direct
+			for _, parent := range child.parents {
+				if parent.purged {
+					purgeChild = true
+					break
+				}
+			}
+			if purgeChild {
+				queue.push(child)
+				metrics.Ready(child.dependency.Name())
+			} else {
+				ctx.Log().Debugf("Skipping dependency %s because none of its parents was purged", child.dependency.Name())
+				trace.Tracef("Dependency %s is skipped, because none of its parents was purged", child.dependency.Name())
+				child.picked = true
+				finish(child, false)
+			}
+		}
+	}
+
+	var firstErr error
+	for {
+		// stop picking dependencies if the pipeline was cancelled, running purges are cancelled
+		// through their context
+		if !stopped && ctx.Context().Err() != nil {
+			trace.Tracef("Stop picking dependencies, because the pipeline was cancelled")
+			firstErr = ctx.Context().Err()
+			stopped = true
+		}
+		for !stopped && (limit == 0 || running < limit) {
+			node := queue.pop()
+			if node == nil {
+				break
+			} else if protectedDependency(node.dependency) && !p.options.PurgeOptions.ForcePurge {
+				ctx.Log().Infof("Skip purging dependency %s as it is protected. Run with '--force-purge' to force deletion", node.dependency.Name())
+				trace.Tracef("Dependency %s is skipped, because it is protected", node.dependency.Name())
+				finish(node, false)
+				continue
+			}
+
+			running++
+			metrics.Started(node.dependency.Name(), queue.len())
+			trace.Tracef("Picked dependency %s (%s) after %s, %d running, %d queued", node.dependency.Name(), lockTypeName(node.lockType), trace.Since(node.ready), running, queue.len())
+			go func(node *scheduleNode, worker int) {
+				start := time.Now()
+				purged, err := p.runScheduledDependency(node, worker)
+				trace.Tracef("Dependency %s finished after %s", node.dependency.Name(), time.Since(start).Round(time.Millisecond))
+				results <- scheduleResult{node: node, worker: worker, ran: purged, err: err, duration: time.Since(start)}
+			}(node, workers.take())
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		workers.release(result.worker)
+		metrics.Finished(result.node.dependency.Name(), result.duration)
+		if result.err != nil {
+			if options.ContinueOnError {
+				failed.add(result.node.ctx, result.node.dependency, result.err)
+			} else if firstErr == nil {
+				firstErr = errors.Wrapf(result.err, "run dependency %s", result.node.dependency.Name())
+				stopped = true
+			}
+		} else if result.ran {
+			ctx.Log().Debugf("Dependency '%s' purged", result.node.dependency.Name())
+		}
+
+		finish(result.node, result.ran && result.err == nil)
+	}
+	metrics.Print(ctx.Log(), !options.Trace)
+	if firstErr != nil {
+		return firstErr
+	}
+
+	return failed.aggregate()
+}
+
+// protectedDependency checks if the dependency is protected from being purged
+func protectedDependency(dependency types2.Dependency) bool {
+	dependencyConfig := dependency.DependencyConfig()
+	return dependencyConfig != nil && dependencyConfig.Protect
+}
@@ -0,0 +1,152 @@
+package pipeline
+
+import (
+	"time"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/registry"
+	types2 "github.com/loft-sh/devspace/pkg/devspace/dependency/types"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/pkg/errors"
+)
+
+// scheduleDependencies runs the given dependencies and all of their children with at most the maximum
+// number of concurrent dependencies and within the build weight budget. A dependency is started as soon
+// as all of its own dependencies are finished, so that a worker never waits for the children of the
+// dependency it runs. The next dependency is picked whenever a dependency finishes. The pipelines of the dependencies don't run their children themselves
+func (p *pipeline) scheduleDependencies(ctx devspacecontext.Context, dependencies []types2.Dependency, options types.DependencyOptions) error {
+	trace := newSchedulerTrace(ctx, p.name, options)
+	graph := &scheduleGraph{nodes: map[string]*scheduleNode{}}
+	err := p.collectScheduleNodes(ctx, p.name, nil, dependencies, options, graph)
+	if err != nil {
+		return errors.Wrap(err, "check if dependencies can be deployed")
+	}
+
+	// start with the dependencies that don't have any dependencies themselves
+	queue := newScheduleQueue(graph, options, false)
+	for _, node := range graph.order {
+		node.remaining = len(node.children)
+		if node.remaining == 0 {
+			queue.push(node)
+		}
+		if node.lockType == registry.Locked {
+			options.Events.Emit(types2.Event{Type: types2.EventPending, Dependency: node.dependency.Name()})
+		}
+	}
+
+	if options.MaxConcurrentDependencies > 0 {
+		ctx.Log().Debugf("Deploying at most %d dependencies in parallel", options.MaxConcurrentDependencies)
+	}
+	if options.MaxWeight > 0 {
+		ctx.Log().Debugf("Deploying dependencies in parallel up to a build weight of %d", options.MaxWeight)
+	}
+	limit := options.MaxConcurrentDependencies
+
+	metrics := newSchedulerMetrics(limit)
+
+	var (
+		results  = make(chan scheduleResult)
+		workers  = &workerSlots{}
+		running  = 0
+		weight   = 0
+		stopped  = false
+		failed   = &failedDependencies{}
+		firstErr error
+	)
+
+	// fits checks if the node can be started within the weight budget. A node that is heavier
+	// than the whole budget is started once nothing else is running
+	fits := func(node *scheduleNode) bool {
+		return options.MaxWeight == 0 || running == 0 || weight+nodeWeight(node) <= options.MaxWeight
+	}
+
+	// finish queues all parents whose dependencies are finished. Parents of failed
+	// dependencies are not run, because their own pipeline would fail as well
+	finish := func(node *scheduleNode) {
+		for _, parent := range node.parents {
+			parent.failed = parent.failed || node.failed
+			parent.remaining--
+			if parent.remaining == 0 {
+				queue.push(parent)
+				metrics.Ready(parent.dependency.Name())
+			}
+		}
+	}
+
+	for {
+		// stop picking dependencies if the pipeline was cancelled, running dependencies
+		// are cancelled through their context
+		if !stopped && ctx.Context().Err() != nil {
+			trace.Tracef("Stop picking dependencies, because the pipeline was cancelled")
+			firstErr = ctx.Context().Err()
+			stopped = true
+		}
+		for !stopped && (limit == 0 || running < limit) {
+			node := queue.peek()
+			if node == nil {
+				break
+			} else if !node.failed && !fits(node) {
+				trace.Tracef("Dependency %s waits, because its build weight %d exceeds the remaining budget of %d", node.dependency.Name(), nodeWeight(node), options.MaxWeight-weight)
+				break
+			}
+
+			queue.pop()
+			if node.failed {
+				trace.Tracef("Dependency %s is skipped, because one of its dependencies failed", node.dependency.Name())
+				failed.add(node.ctx, node.dependency, errors.New("one of its dependencies failed"))
+				if node.lockType == registry.Locked {
+					options.Events.Emit(types2.Event{Type: types2.EventCancelled, Dependency: node.dependency.Name()})
+				}
+				finish(node)
+				continue
+			}
+
+			running++
+			metrics.Started(node.dependency.Name(), queue.len())
+			weight += nodeWeight(node)
+			trace.Tracef("Picked dependency %s (%s) after %s, %d running, %d queued", node.dependency.Name(), lockTypeName(node.lockType), trace.Since(node.ready), running, queue.len())
+			go func(node *scheduleNode, worker int) {
+				start := time.Now()
+				ran, err := p.runScheduledDependency(node, worker)
+				trace.Tracef("Dependency %s finished after %s", node.dependency.Name(), time.Since(start).Round(time.Millisecond))
+				results <- scheduleResult{node: node, worker: worker, ran: ran, err: err, duration: time.Since(start)}
+			}(node, workers.take())
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		workers.release(result.worker)
+		metrics.Finished(result.node.dependency.Name(), result.duration)
+		weight -= nodeWeight(result.node)
+		if result.err != nil {
+			result.node.failed = true
+			if options.ContinueOnError {
+				failed.add(result.node.ctx, result.node.dependency, result.err)
+			} else if firstErr == nil {
+				firstErr = errors.Wrapf(result.err, "run dependency %s", result.node.dependency.Name())
+				stopped = true
+			}
+		} else if result.ran {
+			ctx.Log().Debugf("Dependency '%s' deployed", result.node.dependency.Name())
+		}
+
+		finish(result.node)
+	}
+
+	// report the dependencies that were never started
+	for _, node := range graph.order {
+		if node.lockType == registry.Locked && !node.picked {
+			trace.Tracef("Dependency %s is not started, because the pipeline is stopping", node.dependency.Name())
+			options.Events.Emit(types2.Event{Type: types2.EventCancelled, Dependency: node.dependency.Name()})
+		}
+	}
+	metrics.Print(ctx.Log(), !options.Trace)
+	if firstErr != nil {
+		return firstErr
+	}
+
+	return failed.aggregate()
+}
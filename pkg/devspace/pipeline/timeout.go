@@ -0,0 +1,51 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	enginetypes "github.com/loft-sh/devspace/pkg/devspace/pipeline/engine/types"
+	"github.com/pkg/errors"
+	"mvdan.cc/sh/v3/interp"
+)
+
+// runWithTimeout runs the pipeline and cancels it, if it doesn't finish within the timeout. The cancellation
+// is propagated through the context to the pipelines it started and stops the dev configurations it started.
+// A timeout of zero or less disables the timeout
+func runWithTimeout(ctx devspacecontext.Context, name string, timeout time.Duration, action func(ctx devspacecontext.Context) error) error {
+	if timeout <= 0 {
+		return action(ctx)
+	}
+
+	timeoutCtx, cancel := ctx.WithTimeout(timeout)
+	defer cancel()
+
+	err := action(timeoutCtx)
+	if timeoutCtx.Context().Err() == context.DeadlineExceeded && ctx.Context().Err() == nil {
+		return errors.Errorf("pipeline %s timed out after %s", name, timeout.String())
+	}
+
+	return err
+}
+
+// stepTimeoutHandler cancels every command of a pipeline that doesn't finish within the timeout
+type stepTimeoutHandler struct {
+	handler enginetypes.ExecHandler
+	timeout time.Duration
+}
+
+func (s *stepTimeoutHandler) ExecHandler(ctx context.Context, args []string) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	err := s.handler.ExecHandler(timeoutCtx, args)
+	if timeoutCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		hc := interp.HandlerCtx(ctx)
+		_, _ = fmt.Fprintf(hc.Stderr, "%s: timed out after %s\n", args[0], s.timeout.String())
+		return interp.NewExitStatus(124)
+	}
+
+	return err
+}
@@ -0,0 +1,63 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/context/values"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+	"mvdan.cc/sh/v3/expand"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	devCtx, cancelDevCtx := context.WithCancel(context.Background())
+	defer cancelDevCtx()
+	ctx := devspacecontext.NewContext(values.WithDevContext(context.Background(), devCtx), nil, log.Discard)
+
+	// dev configurations keep running after a pipeline finished in time
+	var pipelineDevCtx context.Context
+	err := runWithTimeout(ctx, "dev", time.Minute, func(ctx devspacecontext.Context) error {
+		pipelineDevCtx, _ = values.DevContextFrom(ctx.Context())
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, pipelineDevCtx.Err())
+
+	// dev configurations are stopped if the pipeline times out
+	err = runWithTimeout(ctx, "dev", time.Millisecond, func(ctx devspacecontext.Context) error {
+		pipelineDevCtx, _ = values.DevContextFrom(ctx.Context())
+		<-ctx.Context().Done()
+		return nil
+	})
+	assert.Error(t, err, "pipeline dev timed out after 1ms")
+	select {
+	case <-pipelineDevCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("dev context was not cancelled")
+	}
+	assert.NilError(t, devCtx.Err())
+}
+
+type sleepHandler struct{}
+
+func (sleepHandler) ExecHandler(ctx context.Context, args []string) error {
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second * 10):
+	}
+	return nil
+}
+
+func TestStepTimeoutHandler(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	handler := &stepTimeoutHandler{handler: sleepHandler{}, timeout: time.Millisecond}
+	_, err := engine.ExecutePipelineShellCommand(context.Background(), "sleep_forever", nil, "", false, &bytes.Buffer{}, stderr, strings.NewReader(""), expand.ListEnviron(), handler)
+	assert.Error(t, err, "exit status 124")
+	assert.Equal(t, stderr.String(), "sleep_forever: timed out after 1ms\n")
+}
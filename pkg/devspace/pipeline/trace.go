@@ -0,0 +1,61 @@
+package pipeline
+
+import (
+	"time"
+
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/devspace/dependency/registry"
+	"github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// schedulerTrace logs the decisions of the dependency scheduler, such as which dependency was
+// picked by which worker and how long it waited, if tracing is enabled
+type schedulerTrace struct {
+	log     log.Logger
+	enabled bool
+	start   time.Time
+}
+
+func newSchedulerTrace(ctx devspacecontext.Context, pipeline string, options types.DependencyOptions) *schedulerTrace {
+	return &schedulerTrace{
+		log:     ctx.Log().WithPrefixColor("trace "+pipeline+" ", "magenta+b"),
+		enabled: options.Trace,
+		start:   time.Now(),
+	}
+}
+
+// Tracef logs the message if tracing is enabled
+func (t *schedulerTrace) Tracef(format string, args ...interface{}) {
+	if !t.enabled {
+		return
+	}
+
+	t.log.Infof(format, args...)
+}
+
+// Since returns how long a dependency that is picked now has waited since it became ready. Without
+// a ready time, the dependency was ready when the scheduler was started
+func (t *schedulerTrace) Since(ready time.Time) time.Duration {
+	if ready.IsZero() {
+		ready = t.start
+	}
+
+	return time.Since(ready).Round(time.Millisecond)
+}
+
+// lockTypeName returns a readable name of the lock type
+func lockTypeName(lockType registry.LockType) string {
+	switch lockType {
+	case registry.Locked:
+		return "locked"
+	case registry.InUse:
+		return "in use by this run"
+	case registry.InUseCyclic:
+		return "in use by a parent"
+	case registry.InUseByOtherInstance:
+		return "in use by another instance"
+	}
+
+	return "unknown"
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/deploy"
 	"github.com/loft-sh/devspace/pkg/devspace/devpod"
 	"mvdan.cc/sh/v3/expand"
+	"time"
 )
 
 type Options struct {
@@ -23,9 +24,40 @@ type DependencyOptions struct {
 	Pipeline   string   `long:"pipeline" description:"The pipeline to deploy from the dependency"`
 	Exclude    []string `long:"exclude" description:"Dependencies to exclude"`
 	Only       []string `long:"only" description:"Dependencies to include"`
+	Tags       []string `long:"tag" description:"Dependencies with one of these tags to include"`
 	Sequential bool     `long:"sequential" description:"Run dependencies one after another"`
 
+	MaxConcurrentDependencies int `long:"max-concurrent" description:"The maximum number of dependencies run in parallel (0 for infinite)"`
+	MaxWeight                 int `long:"max-weight" description:"The maximum sum of the build weights of the dependencies run in parallel (0 for infinite)"`
+
+	RetryAttempts int           `long:"retry-attempts" description:"The maximum number of times a dependency pipeline is run if it fails"`
+	RetryBackoff  time.Duration `long:"retry-backoff" description:"The time to wait before retrying a failed dependency pipeline, doubled after every attempt"`
+
+	Timeout time.Duration `long:"timeout" description:"The maximum time a single dependency pipeline may run (0 for no timeout)"`
+
+	DryRun bool `long:"dry-run" description:"Only report which dependencies would be built or deployed and why, without running them"`
+
+	ContinueOnError bool `long:"continue-on-error" description:"Run the remaining dependencies if one fails and report all failed dependencies at the end"`
+
+	Deterministic bool `long:"deterministic" description:"Start the dependencies in the same order on every run, sorted by their priority and name"`
+
 	SetFlag []string `long:"set-flag" description:"Set a pipeline flag"`
+
+	Trace bool `long:"trace" description:"Log the decisions of the dependency scheduler, such as which dependency was picked by which worker and how long it waited"`
+
+	// Events receives an event whenever a dependency pipeline starts, succeeds or fails
+	Events types2.EventHandler
+
+	// CyclePolicy defines how cyclic dependencies are resolved before the pipeline is started
+	CyclePolicy types2.CyclePolicy
+
+	// CacheStore keeps the local caches of the dependencies after every successful
+	// dependency pipeline, so that they can be restored in a clean checkout
+	CacheStore types2.CacheStore
+
+	// Scheduled is set for dependency pipelines whose children are run by a parent pipeline
+	// that schedules the dependencies of all levels, so that they don't run their children themselves
+	Scheduled bool
 }
 
 // PipelineOptions describe how pipelines should be run
@@ -34,6 +66,10 @@ type PipelineOptions struct {
 	Sequential bool     `long:"sequential" description:"Run pipelines one after another"`
 	SetFlag    []string `long:"set-flag" description:"Set a pipeline flag"`
 
+	MaxConcurrent int `long:"max-concurrent" description:"The maximum number of pipelines run in parallel (0 for infinite)"`
+
+	Timeout time.Duration `long:"timeout" description:"The maximum time a single pipeline may run, if the pipeline doesn't specify a timeout itself (0 for no timeout)"`
+
 	Environ expand.Environ
 }
 
@@ -0,0 +1,42 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	BuildImageConfigNameEnv = "DEVSPACE_PLUGIN_BUILD_IMAGE_CONFIG_NAME"
+	BuildImageEnv           = "DEVSPACE_PLUGIN_BUILD_IMAGE"
+	BuildImageTagsEnv       = "DEVSPACE_PLUGIN_BUILD_IMAGE_TAGS"
+	BuildConfigEnv          = "DEVSPACE_PLUGIN_BUILD_CONFIG"
+	BuildSkipPushEnv        = "DEVSPACE_PLUGIN_BUILD_SKIP_PUSH"
+)
+
+const BuilderActionBuild = "build"
+
+// GetBuilder returns the plugin and builder that registered the given build engine
+func GetBuilder(builderType string) (*Metadata, *Builder) {
+	builderType = strings.TrimSpace(builderType)
+	for i := range plugins {
+		for j := range plugins[i].Builders {
+			if plugins[i].Builders[j].Name == builderType {
+				return &plugins[i], &plugins[i].Builders[j]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// CallBuilder executes the build action of a plugin build engine and writes the output
+// of the plugin to out
+func CallBuilder(plugin *Metadata, builder *Builder, extraEnv map[string]string, out io.Writer) error {
+	err := callPluginAction(plugin, builder.BaseArgs, BuilderActionBuild, extraEnv, out)
+	if err != nil {
+		return fmt.Errorf("error calling plugin %s builder %s: %v", plugin.Name, builder.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	DeployNameEnv        = "DEVSPACE_PLUGIN_DEPLOY_NAME"
+	DeployConfigEnv      = "DEVSPACE_PLUGIN_DEPLOY_CONFIG"
+	DeployKubeContextEnv = "DEVSPACE_PLUGIN_DEPLOY_KUBE_CONTEXT"
+	DeployNamespaceEnv   = "DEVSPACE_PLUGIN_DEPLOY_NAMESPACE"
+	DeployForceEnv       = "DEVSPACE_PLUGIN_DEPLOY_FORCE"
+)
+
+const (
+	DeployerActionDeploy = "deploy"
+	DeployerActionRender = "render"
+	DeployerActionPurge  = "purge"
+	DeployerActionStatus = "status"
+)
+
+// GetDeployer returns the plugin and deployer that registered the given deployment type
+func GetDeployer(deploymentType string) (*Metadata, *Deployer) {
+	deploymentType = strings.TrimSpace(deploymentType)
+	for i := range plugins {
+		for j := range plugins[i].Deployers {
+			if plugins[i].Deployers[j].Name == deploymentType {
+				return &plugins[i], &plugins[i].Deployers[j]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// CallDeployer executes the given action (deploy, render, purge or status) of a plugin deployer and
+// writes the output of the plugin to out
+func CallDeployer(plugin *Metadata, deployer *Deployer, action string, extraEnv map[string]string, out io.Writer) error {
+	err := callPluginAction(plugin, deployer.BaseArgs, action, extraEnv, out)
+	if err != nil {
+		return fmt.Errorf("error calling plugin %s deployer %s at %s: %v", plugin.Name, deployer.Name, action, err)
+	}
+
+	return nil
+}
+
+// callPluginAction calls the plugin binary with the base args and action appended and
+// the global plugin context as environment
+func callPluginAction(plugin *Metadata, baseArgs []string, action string, extraEnv map[string]string, out io.Writer) error {
+	// apply global plugin context
+	env := map[string]string{}
+	pluginContextLock.Lock()
+	for k, v := range pluginContext {
+		env[k] = v
+	}
+	pluginContextLock.Unlock()
+	for k, v := range extraEnv {
+		env[k] = v
+	}
+
+	args := []string{}
+	args = append(args, baseArgs...)
+	args = append(args, action)
+	return CallPluginExecutable(filepath.Join(plugin.PluginFolder, PluginBinary), args, env, out)
+}
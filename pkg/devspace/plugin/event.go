@@ -0,0 +1,33 @@
+package plugin
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MatchEvent checks if the given event pattern matches the event. Patterns can contain '*' as
+// wildcard that matches any sequence of characters, e.g. after:*Dependency or before:deploy:*.
+// Events of the form base:* are matched against the pattern with their base only.
+func MatchEvent(pattern, event string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == event {
+		return true
+	} else if !strings.Contains(pattern, "*") {
+		return false
+	}
+
+	if strings.HasSuffix(event, ":*") {
+		event = strings.TrimSuffix(event, ":*")
+	}
+	if strings.Contains(event, "*") {
+		return false
+	}
+
+	expression := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	match, err := regexp.MatchString(expression, event)
+	if err != nil {
+		return false
+	}
+
+	return match
+}
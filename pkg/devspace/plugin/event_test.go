@@ -0,0 +1,30 @@
+package plugin
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestMatchEvent(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		event    string
+		expected bool
+	}{
+		{pattern: "before:deploy", event: "before:deploy", expected: true},
+		{pattern: "before:deploy", event: "after:deploy", expected: false},
+		{pattern: "after:*Dependency", event: "after:deployDependency:*", expected: true},
+		{pattern: "after:*Dependency", event: "after:purgeDependency:*", expected: true},
+		{pattern: "after:*Dependency", event: "after:purgeDependency:my-dep", expected: false},
+		{pattern: "after:*Dependency", event: "before:deployDependency:*", expected: false},
+		{pattern: "before:deploy:*", event: "before:deploy:*", expected: true},
+		{pattern: "before:deploy:*", event: "before:deploy:my-app", expected: true},
+		{pattern: "before:*", event: "before:deploy:*", expected: true},
+		{pattern: "*", event: "devCommand:before:execute", expected: true},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, MatchEvent(testCase.pattern, testCase.event), testCase.expected, "pattern %s and event %s", testCase.pattern, testCase.event)
+	}
+}
@@ -0,0 +1,212 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/util/exit"
+)
+
+const (
+	FunctionNameEnv        = "DEVSPACE_PLUGIN_FUNCTION_NAME"
+	FunctionFlagsEnv       = "DEVSPACE_PLUGIN_FUNCTION_FLAGS"
+	FunctionArgsEnv        = "DEVSPACE_PLUGIN_FUNCTION_ARGS"
+	FunctionFlagEnvPrefix  = "DEVSPACE_PLUGIN_FUNCTION_FLAG"
+	FunctionPipelineEnv    = "DEVSPACE_PLUGIN_FUNCTION_PIPELINE"
+	FunctionKubeContextEnv = "DEVSPACE_PLUGIN_FUNCTION_KUBE_CONTEXT"
+	FunctionNamespaceEnv   = "DEVSPACE_PLUGIN_FUNCTION_NAMESPACE"
+)
+
+const (
+	FunctionFlagTypeString      = "string"
+	FunctionFlagTypeBool        = "bool"
+	FunctionFlagTypeInt         = "int"
+	FunctionFlagTypeStringArray = "stringArray"
+)
+
+// GetFunctions returns all pipeline functions that were registered by the installed plugins
+// together with the plugin that registered them
+func GetFunctions() ([]*Metadata, []*Function) {
+	retPlugins := []*Metadata{}
+	retFunctions := []*Function{}
+	for i := range plugins {
+		for j := range plugins[i].Functions {
+			retPlugins = append(retPlugins, &plugins[i])
+			retFunctions = append(retFunctions, &plugins[i].Functions[j])
+		}
+	}
+
+	return retPlugins, retFunctions
+}
+
+// ParseFunctionFlags parses the flags of the function from args. It returns the typed values of
+// all flags, including the defaults of flags that were not specified, and the remaining arguments
+func ParseFunctionFlags(function *Function, args []string) (map[string]interface{}, []string, error) {
+	flags := map[string]*FunctionFlag{}
+	for i := range function.Flags {
+		flags[function.Flags[i].Name] = &function.Flags[i]
+	}
+
+	values := map[string]interface{}{}
+	rest := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i+1:]...)
+			break
+		} else if !strings.HasPrefix(arg, "--") || len(arg) == 2 {
+			rest = append(rest, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		flag, ok := flags[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown flag --%s", name)
+		}
+		if !hasValue {
+			if flag.Type == FunctionFlagTypeBool {
+				value = "true"
+			} else if i+1 < len(args) {
+				i++
+				value = args[i]
+			} else {
+				return nil, nil, fmt.Errorf("flag --%s needs an argument", name)
+			}
+		}
+
+		err := setFunctionFlag(values, flag, value)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for i := range function.Flags {
+		flag := &function.Flags[i]
+		if _, ok := values[flag.Name]; ok {
+			continue
+		} else if flag.Required {
+			return nil, nil, fmt.Errorf("required flag --%s is missing", flag.Name)
+		}
+
+		if flag.Default != "" {
+			err := setFunctionFlag(values, flag, flag.Default)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid default of function %s: %v", function.Name, err)
+			}
+		} else {
+			values[flag.Name] = zeroFunctionFlag(flag)
+		}
+	}
+
+	return values, rest, nil
+}
+
+func setFunctionFlag(values map[string]interface{}, flag *FunctionFlag, value string) error {
+	switch flag.Type {
+	case "", FunctionFlagTypeString:
+		values[flag.Name] = value
+	case FunctionFlagTypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for bool flag --%s", value, flag.Name)
+		}
+		values[flag.Name] = b
+	case FunctionFlagTypeInt:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for int flag --%s", value, flag.Name)
+		}
+		values[flag.Name] = i
+	case FunctionFlagTypeStringArray:
+		arr, _ := values[flag.Name].([]string)
+		values[flag.Name] = append(arr, value)
+	default:
+		return fmt.Errorf("flag --%s has unsupported type %s", flag.Name, flag.Type)
+	}
+
+	return nil
+}
+
+func zeroFunctionFlag(flag *FunctionFlag) interface{} {
+	switch flag.Type {
+	case FunctionFlagTypeBool:
+		return false
+	case FunctionFlagTypeInt:
+		return 0
+	case FunctionFlagTypeStringArray:
+		return []string{}
+	}
+
+	return ""
+}
+
+// FunctionEnv returns the environment variables the plugin receives the parsed flags and
+// arguments of the function with. Besides a json encoded object of all flags, every flag is
+// available as DEVSPACE_PLUGIN_FUNCTION_FLAG_NAME
+func FunctionEnv(function *Function, flags map[string]interface{}, args []string) (map[string]string, error) {
+	flagsBytes, err := json.Marshal(flags)
+	if err != nil {
+		return nil, err
+	}
+	argsBytes, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	flagEnv := map[string]interface{}{}
+	for name, value := range flags {
+		if arr, ok := value.([]string); ok {
+			value = strings.Join(arr, " ")
+		}
+		flagEnv[strings.ReplaceAll(name, "-", "_")] = value
+	}
+
+	env := ConvertExtraEnv(FunctionFlagEnvPrefix, flagEnv)
+	env[FunctionNameEnv] = function.Name
+	env[FunctionFlagsEnv] = string(flagsBytes)
+	env[FunctionArgsEnv] = string(argsBytes)
+	return env, nil
+}
+
+// CallFunction executes the pipeline function of a plugin in dir. The plugin receives the
+// given environment, the global plugin context and extraEnv as environment variables and is
+// called with the base args of the function and args
+func CallFunction(ctx context.Context, plugin *Metadata, function *Function, args []string, environ []string, extraEnv map[string]string, dir string, stdin io.Reader, stdout, stderr io.Writer) error {
+	env := append([]string{}, environ...)
+	pluginContextLock.Lock()
+	for k, v := range pluginContext {
+		env = append(env, k+"="+v)
+	}
+	pluginContextLock.Unlock()
+	for k, v := range extraEnv {
+		env = append(env, k+"="+v)
+	}
+
+	argv := []string{}
+	argv = append(argv, function.BaseArgs...)
+	argv = append(argv, args...)
+	prog := exec.CommandContext(ctx, filepath.Join(plugin.PluginFolder, PluginBinary), argv...)
+	prog.Env = env
+	prog.Dir = dir
+	prog.Stdin = stdin
+	prog.Stdout = stdout
+	prog.Stderr = stderr
+	if err := prog.Run(); err != nil {
+		if eerr, ok := err.(*exec.ExitError); ok {
+			return &exit.ReturnCodeError{ExitCode: eerr.ExitCode()}
+		} else if strings.Contains(err.Error(), "no such file or directory") {
+			return fmt.Errorf("the binary of plugin %s was not found (%v). Please uninstall and reinstall the plugin", plugin.Name, err)
+		}
+
+		return fmt.Errorf("error calling plugin %s function %s: %v", plugin.Name, function.Name, err)
+	}
+
+	return nil
+}
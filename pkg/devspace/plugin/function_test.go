@@ -0,0 +1,103 @@
+package plugin
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+type parseFunctionFlagsTestCase struct {
+	name string
+
+	args []string
+
+	expectedFlags map[string]interface{}
+	expectedArgs  []string
+	expectedErr   string
+}
+
+func TestParseFunctionFlags(t *testing.T) {
+	function := &Function{
+		Name: "wait_for_db",
+		Flags: []FunctionFlag{
+			{Name: "host", Required: true},
+			{Name: "timeout", Type: FunctionFlagTypeInt, Default: "30"},
+			{Name: "tls", Type: FunctionFlagTypeBool},
+			{Name: "table", Type: FunctionFlagTypeStringArray},
+		},
+	}
+
+	testCases := []parseFunctionFlagsTestCase{
+		{
+			name: "Defaults",
+			args: []string{"--host", "db"},
+			expectedFlags: map[string]interface{}{
+				"host":    "db",
+				"timeout": 30,
+				"tls":     false,
+				"table":   []string{},
+			},
+			expectedArgs: []string{},
+		},
+		{
+			name: "All flags and args",
+			args: []string{"users", "--host=db", "--tls", "--timeout", "5", "--table", "a", "--table=b", "--", "--tls"},
+			expectedFlags: map[string]interface{}{
+				"host":    "db",
+				"timeout": 5,
+				"tls":     true,
+				"table":   []string{"a", "b"},
+			},
+			expectedArgs: []string{"users", "--tls"},
+		},
+		{
+			name:        "Missing required flag",
+			args:        []string{"--tls=false"},
+			expectedErr: "required flag --host is missing",
+		},
+		{
+			name:        "Invalid int",
+			args:        []string{"--host", "db", "--timeout", "soon"},
+			expectedErr: "invalid value \"soon\" for int flag --timeout",
+		},
+		{
+			name:        "Unknown flag",
+			args:        []string{"--port", "5432"},
+			expectedErr: "unknown flag --port",
+		},
+		{
+			name:        "Missing value",
+			args:        []string{"--host"},
+			expectedErr: "flag --host needs an argument",
+		},
+	}
+
+	for _, testCase := range testCases {
+		flags, args, err := ParseFunctionFlags(function, testCase.args)
+		if testCase.expectedErr != "" {
+			assert.Error(t, err, testCase.expectedErr, "Unexpected error in test case %s", testCase.name)
+			continue
+		}
+
+		assert.NilError(t, err, "Error in test case %s", testCase.name)
+		assert.DeepEqual(t, flags, testCase.expectedFlags)
+		assert.DeepEqual(t, args, testCase.expectedArgs)
+	}
+}
+
+func TestFunctionEnv(t *testing.T) {
+	function := &Function{Name: "seed_fixtures"}
+	env, err := FunctionEnv(function, map[string]interface{}{
+		"dry-run": true,
+		"count":   3,
+		"file":    []string{"a.sql", "b.sql"},
+	}, []string{"users"})
+	assert.NilError(t, err)
+
+	assert.Equal(t, env[FunctionNameEnv], "seed_fixtures")
+	assert.Equal(t, env[FunctionArgsEnv], `["users"]`)
+	assert.Equal(t, env[FunctionFlagsEnv], `{"count":3,"dry-run":true,"file":["a.sql","b.sql"]}`)
+	assert.Equal(t, env["DEVSPACE_PLUGIN_FUNCTION_FLAG_DRY_RUN"], "true")
+	assert.Equal(t, env["DEVSPACE_PLUGIN_FUNCTION_FLAG_COUNT"], "3")
+	assert.Equal(t, env["DEVSPACE_PLUGIN_FUNCTION_FLAG_FILE"], "a.sql b.sql")
+}
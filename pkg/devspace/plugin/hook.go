@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -164,8 +165,16 @@ func LogExecutePluginHookWithContext(extraEnv map[string]interface{}, events ...
 }
 
 func ExecutePluginHookWithContext(extraEnv map[string]interface{}, events ...string) error {
+	_, err := ExecutePluginHookWithMutations(extraEnv, events...)
+	return err
+}
+
+// ExecutePluginHookWithMutations executes the plugin hooks for the given events and returns the
+// mutations that were requested by hooks using the json protocol
+func ExecutePluginHookWithMutations(extraEnv map[string]interface{}, events ...string) (*HookMutations, error) {
+	mutations := &HookMutations{}
 	if len(plugins) == 0 {
-		return nil
+		return mutations, nil
 	}
 
 	// apply global plugin context
@@ -182,17 +191,16 @@ func ExecutePluginHookWithContext(extraEnv map[string]interface{}, events ...str
 		newEnv[k] = v
 	}
 
-	for _, plugin := range plugins {
-		for _, e := range events {
-			newEnv["DEVSPACE_PLUGIN_EVENT"] = e
-			err := executePluginHookAt(plugin, e, newEnv)
-			if err != nil {
-				return err
-			}
+	executed := map[*Hook]bool{}
+	for _, e := range events {
+		newEnv["DEVSPACE_PLUGIN_EVENT"] = e
+		err := executePluginHooks(plugins, e, newEnv, extraEnv, executed, mutations)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	return nil
+	return mutations, nil
 }
 
 func ConvertExtraEnv(base string, extraEnv map[string]interface{}) map[string]string {
@@ -231,7 +239,7 @@ func ExecutePluginHookAt(plugin Metadata, events ...string) error {
 		}
 		pluginContextLock.Unlock()
 
-		err := executePluginHookAt(plugin, e, newEnv)
+		err := executePluginHooks([]Metadata{plugin}, e, newEnv, nil, map[*Hook]bool{}, &HookMutations{})
 		if err != nil {
 			return err
 		}
@@ -240,24 +248,71 @@ func ExecutePluginHookAt(plugin Metadata, events ...string) error {
 	return nil
 }
 
-func executePluginHookAt(plugin Metadata, event string, env map[string]string) error {
-	pluginFolder := plugin.PluginFolder
-	for _, pluginHook := range plugin.Hooks {
-		if strings.TrimSpace(pluginHook.Event) == event {
-			var err error
-			if pluginHook.Background {
-				err = CallPluginExecutableInBackground(filepath.Join(pluginFolder, PluginBinary), pluginHook.BaseArgs, env)
-			} else {
-				err = CallPluginExecutable(filepath.Join(pluginFolder, PluginBinary), pluginHook.BaseArgs, env, os.Stdout)
-			}
-			if err != nil {
-				return fmt.Errorf("error calling plugin hook %s at event %s: %v", plugin.Name, event, err)
+type pluginHook struct {
+	plugin *Metadata
+	hook   *Hook
+}
+
+var onceHooksLock sync.Mutex
+var onceHooks = map[string]bool{}
+
+// executePluginHooks executes all hooks of the given plugins that match the event ordered by their
+// priority. Hooks that are contained in executed are skipped, so that a hook matching multiple events
+// of the same call is only executed once. Mutations returned by hooks using the json protocol are
+// applied to env and collected in mutations.
+func executePluginHooks(plugins []Metadata, event string, env map[string]string, data map[string]interface{}, executed map[*Hook]bool, mutations *HookMutations) error {
+	hooks := []pluginHook{}
+	for i := range plugins {
+		for j := range plugins[i].Hooks {
+			hook := &plugins[i].Hooks[j]
+			if executed[hook] || !MatchEvent(hook.Event, event) {
+				continue
 			}
+
+			executed[hook] = true
+			hooks = append(hooks, pluginHook{plugin: &plugins[i], hook: hook})
+		}
+	}
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return hooks[i].hook.Priority > hooks[j].hook.Priority
+	})
+
+	for _, h := range hooks {
+		if h.hook.Once && !markOnce(h.plugin.Name+"/"+h.hook.Event+"/"+strings.Join(h.hook.BaseArgs, " ")) {
+			continue
+		}
+
+		var err error
+		pluginFolder := h.plugin.PluginFolder
+		if h.hook.Background {
+			err = CallPluginExecutableInBackground(filepath.Join(pluginFolder, PluginBinary), h.hook.BaseArgs, env)
+		} else if h.hook.Protocol == ProtocolJSON {
+			err = callPluginHookWithProtocol(h.plugin, h.hook, event, env, data, mutations)
+		} else {
+			err = CallPluginExecutable(filepath.Join(pluginFolder, PluginBinary), h.hook.BaseArgs, env, os.Stdout)
+		}
+		if IsAbort(err) {
+			return err
+		} else if err != nil {
+			return fmt.Errorf("error calling plugin hook %s at event %s: %v", h.plugin.Name, event, err)
 		}
 	}
 	return nil
 }
 
+// markOnce returns true if the given key was not marked before
+func markOnce(key string) bool {
+	onceHooksLock.Lock()
+	defer onceHooksLock.Unlock()
+
+	if onceHooks[key] {
+		return false
+	}
+
+	onceHooks[key] = true
+	return true
+}
+
 func CallPluginExecutableInBackground(main string, argv []string, extraEnvVars map[string]string) error {
 	env := os.Environ()
 	for k, v := range extraEnvVars {
@@ -290,6 +345,10 @@ func CallPluginExecutableInBackground(main string, argv []string, extraEnvVars m
 // CallPluginExecutable is used to setup the environment for the plugin and then
 // call the executable specified by the parameter 'main'
 func CallPluginExecutable(main string, argv []string, extraEnvVars map[string]string, out io.Writer) error {
+	return callPluginExecutableWithInput(main, argv, extraEnvVars, os.Stdin, out)
+}
+
+func callPluginExecutableWithInput(main string, argv []string, extraEnvVars map[string]string, in io.Reader, out io.Writer) error {
 	env := os.Environ()
 	for k, v := range extraEnvVars {
 		env = append(env, k+"="+v)
@@ -297,7 +356,7 @@ func CallPluginExecutable(main string, argv []string, extraEnvVars map[string]st
 
 	prog := exec.Command(main, argv...)
 	prog.Env = env
-	prog.Stdin = os.Stdin
+	prog.Stdin = in
 	prog.Stdout = out
 	prog.Stderr = os.Stderr
 	if err := prog.Run(); err != nil {
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	json "github.com/json-iterator/go"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/yamlutil"
+	"github.com/pkg/errors"
+)
+
+// ProtocolJSON is the hook protocol where the event is passed as json on stdin
+// and the hook can respond with a json document on stdout
+const ProtocolJSON = "json"
+
+// HookRequest is written to the stdin of hooks that use the json protocol
+type HookRequest struct {
+	// Event is the event that triggered the hook
+	Event string `json:"event"`
+
+	// Env holds the environment variables that are also passed to the hook
+	Env map[string]string `json:"env,omitempty"`
+
+	// Data holds the unconverted event payload
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// AbortError is returned if a hook using the json protocol aborted DevSpace
+type AbortError struct {
+	Plugin  string
+	Message string
+}
+
+func (a *AbortError) Error() string {
+	return fmt.Sprintf("plugin %s aborted: %s", a.Plugin, a.Message)
+}
+
+// IsAbort checks if the error was returned because a plugin hook aborted DevSpace
+func IsAbort(err error) bool {
+	_, ok := err.(*AbortError)
+	return ok
+}
+
+// HookResponse can be printed by hooks that use the json protocol
+type HookResponse struct {
+	// Abort will stop DevSpace with the given message
+	Abort bool `json:"abort,omitempty"`
+
+	// Message is printed if abort is true
+	Message string `json:"message,omitempty"`
+
+	// Env are extra environment variables that are passed to all following hooks
+	// of the same event
+	Env map[string]string `json:"env,omitempty"`
+
+	// Patches are applied to the loaded config
+	Patches []*latest.PatchConfig `json:"patches,omitempty"`
+}
+
+// HookMutations are the collected mutations of all hooks for a set of events
+type HookMutations struct {
+	Env     map[string]string
+	Patches []*latest.PatchConfig
+}
+
+func callPluginHookWithProtocol(plugin *Metadata, hook *Hook, event string, env map[string]string, data map[string]interface{}, mutations *HookMutations) error {
+	request := &HookRequest{
+		Event: event,
+		Env:   env,
+	}
+	if data != nil {
+		converted, ok := yamlutil.Convert(data).(map[string]interface{})
+		if ok {
+			request.Data = converted
+		}
+	}
+
+	in, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrap(err, "marshal hook request")
+	}
+
+	out := &bytes.Buffer{}
+	err = callPluginExecutableWithInput(filepath.Join(plugin.PluginFolder, PluginBinary), hook.BaseArgs, env, bytes.NewReader(in), out)
+	if err != nil {
+		return err
+	} else if len(strings.TrimSpace(out.String())) == 0 {
+		return nil
+	}
+
+	response := &HookResponse{}
+	err = json.Unmarshal(out.Bytes(), response)
+	if err != nil {
+		return errors.Wrap(err, "parse hook response")
+	} else if response.Abort {
+		return &AbortError{Plugin: plugin.Name, Message: response.Message}
+	}
+
+	for k, v := range response.Env {
+		if mutations.Env == nil {
+			mutations.Env = map[string]string{}
+		}
+
+		env[k] = v
+		mutations.Env[k] = v
+	}
+	mutations.Patches = append(mutations.Patches, response.Patches...)
+	return nil
+}
@@ -23,17 +23,38 @@ type Metadata struct {
 	// Hooks are commands that will be executed at specific events
 	Hooks []Hook `json:"hooks,omitempty"`
 
+	// Deployers are custom deployment types that can be used within the deployments section
+	Deployers []Deployer `json:"deployers,omitempty"`
+
+	// Builders are custom build engines that can be used within the images section
+	Builders []Builder `json:"builders,omitempty"`
+
+	// Functions are custom functions that can be used within pipelines
+	Functions []Function `json:"functions,omitempty"`
+
 	// This will be filled after parsing the metadata
 	PluginFolder string `json:"pluginFolder,omitempty"`
 }
 
 type Hook struct {
-	// Event is the name of the event when to execute this hook
+	// Event is the name of the event when to execute this hook. Can contain '*' as wildcard,
+	// e.g. after:*Dependency
 	Event string `json:"event"`
 
+	// Priority defines the execution order of hooks for the same event. Hooks with a higher
+	// priority are executed first
+	Priority int `json:"priority,omitempty"`
+
+	// Once specifies that the hook is only executed a single time during a DevSpace run
+	Once bool `json:"once,omitempty"`
+
 	// Background specifies if the given command should be executed in the background
 	Background bool `json:"background"`
 
+	// Protocol specifies how the hook communicates with DevSpace. If set to json, the hook receives
+	// the event as json on stdin and can print a json response with mutations to stdout
+	Protocol string `json:"protocol,omitempty"`
+
 	// BaseArgs that will be prepended to all supplied user flags for this plugin command
 	BaseArgs []string `json:"baseArgs,omitempty"`
 }
@@ -73,3 +94,51 @@ type Variable struct {
 	// BaseArgs that will be prepended to all supplied user flags for this plugin command
 	BaseArgs []string `json:"baseArgs,omitempty"`
 }
+
+type Deployer struct {
+	// Name is the name of the deployment type, e.g. pulumi
+	Name string `json:"name"`
+
+	// BaseArgs that will be prepended to the deployer action (deploy, render, purge or status)
+	BaseArgs []string `json:"baseArgs,omitempty"`
+}
+
+type Builder struct {
+	// Name is the name of the build engine
+	Name string `json:"name"`
+
+	// BaseArgs that will be prepended to the build action
+	BaseArgs []string `json:"baseArgs,omitempty"`
+}
+
+type Function struct {
+	// Name is the name of the function that can be called within pipelines, e.g. wait_for_db
+	Name string `json:"name"`
+
+	// Description is a short description of the function
+	Description string `json:"description,omitempty"`
+
+	// Flags are the flags the function accepts. The flags are validated by DevSpace before the
+	// plugin is called
+	Flags []FunctionFlag `json:"flags,omitempty"`
+
+	// BaseArgs that will be prepended to the supplied user flags and arguments of the function
+	BaseArgs []string `json:"baseArgs,omitempty"`
+}
+
+type FunctionFlag struct {
+	// Name is the name of the flag without leading dashes, e.g. timeout
+	Name string `json:"name"`
+
+	// Type is the type of the flag. Can be string, bool, int or stringArray. Defaults to string
+	Type string `json:"type,omitempty"`
+
+	// Default is the value of the flag if it is not specified
+	Default string `json:"default,omitempty"`
+
+	// Required specifies if the flag has to be specified
+	Required bool `json:"required,omitempty"`
+
+	// Description is a short description of the flag
+	Description string `json:"description,omitempty"`
+}
@@ -1,6 +1,7 @@
 package pullsecrets
 
 import (
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
 	"github.com/loft-sh/devspace/pkg/devspace/docker"
 )
@@ -9,6 +10,7 @@ import (
 type Client interface {
 	EnsurePullSecrets(ctx devspacecontext.Context, dockerClient docker.Client, pullSecrets []string) error
 	EnsurePullSecret(ctx devspacecontext.Context, dockerClient docker.Client, namespace, registryURL string) error
+	EnsurePullSecretConfig(ctx devspacecontext.Context, namespace string, pullSecret *latest.PullSecretConfig) error
 }
 
 // NewClient creates a client for a registry
@@ -36,6 +36,12 @@ func (r *client) EnsurePullSecret(ctx devspacecontext.Context, dockerClient dock
 	return r.ensurePullSecret(ctx, dockerClient, namespace, pullSecret)
 }
 
+// EnsurePullSecretConfig creates the image pull secret for the given config, without looking up
+// credentials in the docker store
+func (r *client) EnsurePullSecretConfig(ctx devspacecontext.Context, namespace string, pullSecret *latest.PullSecretConfig) error {
+	return r.ensurePullSecret(ctx, nil, namespace, pullSecret)
+}
+
 func (r *client) ensurePullSecret(ctx devspacecontext.Context, dockerClient docker.Client, namespace string, pullSecretConf *latest.PullSecretConfig) error {
 	displayRegistryURL := pullSecretConf.Registry
 	if displayRegistryURL == "" {
@@ -0,0 +1,333 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	pipelinetypes "github.com/loft-sh/devspace/pkg/devspace/pipeline/types"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// The v1 api of the ui server can be used by IDE extensions and other tools to control a running
+// DevSpace session. All responses are json encoded, requests that change the state of the session
+// are only accepted via POST from localhost and not from other origins. Requests must address the
+// server as localhost or by a loopback ip, so that other websites can't reach it via DNS rebinding.
+//
+//	GET  /api/v1/sessions                   lists the running DevSpace session including its dependencies and dev pods
+//	GET  /api/v1/pipelines                  lists the pipelines that can be run
+//	POST /api/v1/pipelines/run?name=NAME    starts the pipeline NAME in the background
+//	GET  /api/v1/forwards                   lists the port-forwards started by the ui
+//	POST /api/v1/forwards/stop?key=KEY      stops the port-forward with the given key
+//	GET  /api/v1/logs/devspace?name=NAME    websocket that streams the DevSpace log file NAME (e.g. sync, portforwarding or dev.NAME)
+//	GET  /api/logs                          websocket that streams the logs of a container
+
+// APISession describes a running DevSpace session
+type APISession struct {
+	Name         string       `json:"name"`
+	DevPods      []string     `json:"devPods"`
+	Dependencies []APISession `json:"dependencies,omitempty"`
+}
+
+// APIPipeline describes a pipeline that can be run
+type APIPipeline struct {
+	Name string `json:"name"`
+}
+
+// APIForward describes a port-forward started by the ui
+type APIForward struct {
+	Key       string `json:"key"`
+	LocalPort int    `json:"localPort"`
+	PodUID    string `json:"podUID"`
+}
+
+// APIStatus is returned by requests that trigger an action
+type APIStatus struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+var logNameRegEx = regexp.MustCompile(`^[a-zA-Z0-9_\-.]+$`)
+
+// localUpgrader only accepts websocket connections that are not opened by a website of another origin
+var localUpgrader = websocket.Upgrader{
+	CheckOrigin: isSameOrigin,
+}
+
+func (h *handler) registerAPI() {
+	h.mux.HandleFunc("/api/v1/sessions", checkLocalHost(h.apiSessions))
+	h.mux.HandleFunc("/api/v1/pipelines", checkLocalHost(h.apiPipelines))
+	h.mux.HandleFunc("/api/v1/pipelines/run", checkLocalHost(h.apiRunPipeline))
+	h.mux.HandleFunc("/api/v1/forwards", checkLocalHost(h.apiForwards))
+	h.mux.HandleFunc("/api/v1/forwards/stop", checkLocalHost(h.apiStopForward))
+	h.mux.HandleFunc("/api/v1/logs/devspace", checkLocalHost(h.apiDevSpaceLogs))
+}
+
+func (h *handler) apiSessions(w http.ResponseWriter, r *http.Request) {
+	sessions := []APISession{}
+	if h.pipeline != nil {
+		sessions = append(sessions, sessionFromPipeline(h.pipeline))
+	}
+
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func sessionFromPipeline(pipeline pipelinetypes.Pipeline) APISession {
+	session := APISession{
+		Name:    pipeline.Name(),
+		DevPods: []string{},
+	}
+	if pipeline.DevPodManager() != nil {
+		session.DevPods = append(session.DevPods, pipeline.DevPodManager().List()...)
+	}
+
+	dependencies := pipeline.Dependencies()
+	names := make([]string, 0, len(dependencies))
+	for name := range dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		session.Dependencies = append(session.Dependencies, sessionFromPipeline(dependencies[name]))
+	}
+
+	return session
+}
+
+func (h *handler) apiPipelines(w http.ResponseWriter, r *http.Request) {
+	names := map[string]bool{"build": true, "deploy": true, "dev": true, "purge": true}
+	if h.ctx.Config() != nil && h.ctx.Config().Config() != nil {
+		for name := range h.ctx.Config().Config().Pipelines {
+			names[name] = true
+		}
+	}
+
+	pipelines := []APIPipeline{}
+	for name := range names {
+		pipelines = append(pipelines, APIPipeline{Name: name})
+	}
+	sort.Slice(pipelines, func(i, j int) bool {
+		return pipelines[i].Name < pipelines[j].Name
+	})
+
+	writeJSON(w, http.StatusOK, pipelines)
+}
+
+func (h *handler) apiRunPipeline(w http.ResponseWriter, r *http.Request) {
+	if !checkLocalPost(w, r) {
+		return
+	} else if h.pipeline == nil {
+		writeJSON(w, http.StatusConflict, &APIStatus{Status: "error", Message: "no DevSpace pipeline is running"})
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeJSON(w, http.StatusBadRequest, &APIStatus{Status: "error", Message: "name is missing"})
+		return
+	}
+
+	var configPipeline *latest.Pipeline
+	if h.ctx.Config() != nil && h.ctx.Config().Config() != nil {
+		configPipeline = h.ctx.Config().Config().Pipelines[name]
+	}
+	if configPipeline == nil {
+		var err error
+		configPipeline, err = pipelinetypes.GetDefaultPipeline(name)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, &APIStatus{Status: "error", Message: err.Error()})
+			return
+		}
+	}
+
+	err := h.pipeline.StartNewPipelines(h.ctx, []*latest.Pipeline{configPipeline}, pipelinetypes.PipelineOptions{
+		Background: true,
+		Environ:    h.ctx.Environ(),
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, &APIStatus{Status: "error", Message: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, &APIStatus{Status: "started"})
+}
+
+func (h *handler) apiForwards(w http.ResponseWriter, r *http.Request) {
+	h.portsMutex.Lock()
+	forwards := []APIForward{}
+	for key, f := range h.ports {
+		forwards = append(forwards, APIForward{
+			Key:       key,
+			LocalPort: f.portForwarderPort,
+			PodUID:    f.podUUID,
+		})
+	}
+	h.portsMutex.Unlock()
+
+	sort.Slice(forwards, func(i, j int) bool {
+		return forwards[i].Key < forwards[j].Key
+	})
+	writeJSON(w, http.StatusOK, forwards)
+}
+
+func (h *handler) apiStopForward(w http.ResponseWriter, r *http.Request) {
+	if !checkLocalPost(w, r) {
+		return
+	}
+
+	key := r.URL.Query().Get("key")
+	h.portsMutex.Lock()
+	defer h.portsMutex.Unlock()
+
+	f, ok := h.ports[key]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, &APIStatus{Status: "error", Message: "port-forward " + key + " not found"})
+		return
+	}
+
+	close(f.portForwarderStop)
+	delete(h.ports, key)
+	writeJSON(w, http.StatusOK, &APIStatus{Status: "stopped"})
+}
+
+func (h *handler) apiDevSpaceLogs(w http.ResponseWriter, r *http.Request) {
+	if !checkLocalRequest(w, r) {
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if !logNameRegEx.MatchString(name) {
+		http.Error(w, "name is missing or invalid", http.StatusBadRequest)
+		return
+	}
+
+	file, err := os.Open(h.ctx.ResolvePath(filepath.Join(log.Logdir, name+".log")))
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "log "+name+" not found", http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	ws, err := localUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.ctx.Log().Errorf("Error upgrading connection in %s: %v", r.URL.String(), err)
+		return
+	}
+	defer ws.Close()
+
+	// close the connection as soon as the client goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := ws.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// follow the log file
+	stream := &wsStream{WebSocket: ws}
+	for {
+		_, err = io.Copy(stream, file)
+		if err != nil {
+			websocketError(ws, err)
+			return
+		}
+
+		select {
+		case <-closed:
+			return
+		case <-h.ctx.Context().Done():
+			_ = ws.SetWriteDeadline(time.Now().Add(time.Second * 5))
+			_ = ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		case <-time.After(time.Millisecond * 500):
+		}
+	}
+}
+
+// checkLocalHost rejects requests that don't address the server as localhost or by a loopback ip
+func checkLocalHost(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			writeJSON(w, http.StatusForbidden, &APIStatus{Status: "error", Message: "host " + r.Host + " is not allowed"})
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// checkLocalPost makes sure the request is a POST request from localhost
+func checkLocalPost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, &APIStatus{Status: "error", Message: "method not allowed"})
+		return false
+	}
+
+	return checkLocalRequest(w, r)
+}
+
+// checkLocalRequest makes sure the request is from localhost and not sent by a website of another origin
+func checkLocalRequest(w http.ResponseWriter, r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		writeJSON(w, http.StatusForbidden, &APIStatus{Status: "error", Message: "requests from " + host + " are not allowed"})
+		return false
+	}
+
+	// prevent websites from triggering actions through the browser
+	if !isSameOrigin(r) {
+		writeJSON(w, http.StatusForbidden, &APIStatus{Status: "error", Message: "cross origin requests are not allowed"})
+		return false
+	}
+
+	return true
+}
+
+// isSameOrigin returns true if the request was not sent by a website of another origin
+func isSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	originURL, err := url.Parse(origin)
+	return err == nil && originURL.Host == r.Host
+}
+
+func writeJSON(w http.ResponseWriter, status int, obj interface{}) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
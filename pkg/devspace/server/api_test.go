@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/constants"
+	"github.com/loft-sh/devspace/pkg/devspace/config/localcache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/remotecache"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	devspacecontext "github.com/loft-sh/devspace/pkg/devspace/context"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"gotest.tools/assert"
+)
+
+type apiTestCase struct {
+	name string
+
+	method     string
+	path       string
+	host       string
+	remoteAddr string
+	origin     string
+
+	expectedStatus int
+	expectedBody   string
+}
+
+func newTestHandler() *handler {
+	conf := config.NewConfig(map[string]interface{}{},
+		map[string]interface{}{},
+		&latest.Config{Pipelines: map[string]*latest.Pipeline{"lint": {Name: "lint"}}},
+		localcache.New(constants.DefaultCacheFolder),
+		&remotecache.RemoteCache{},
+		map[string]interface{}{},
+		constants.DefaultConfigPath)
+
+	h := &handler{
+		ctx:   devspacecontext.NewContext(context.Background(), nil, log.Discard).WithConfig(conf),
+		mux:   http.NewServeMux(),
+		ports: map[string]*forward{},
+	}
+	h.registerAPI()
+	return h
+}
+
+func TestAPI(t *testing.T) {
+	testCases := []apiTestCase{
+		{
+			name:           "List pipelines",
+			method:         http.MethodGet,
+			path:           "/api/v1/pipelines",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"name":"build"},{"name":"deploy"},{"name":"dev"},{"name":"lint"},{"name":"purge"}]`,
+		},
+		{
+			name:           "List sessions via loopback ip",
+			method:         http.MethodGet,
+			path:           "/api/v1/sessions",
+			host:           "127.0.0.1:8090",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:           "List sessions via ipv6 loopback ip",
+			method:         http.MethodGet,
+			path:           "/api/v1/sessions",
+			host:           "[::1]:8090",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:           "DNS rebinding",
+			method:         http.MethodGet,
+			path:           "/api/v1/sessions",
+			host:           "attacker.example.com:8090",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":"error","message":"host attacker.example.com:8090 is not allowed"}`,
+		},
+		{
+			name:           "DNS rebinding on post",
+			method:         http.MethodPost,
+			path:           "/api/v1/forwards/stop?key=abc",
+			host:           "attacker.example.com",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":"error","message":"host attacker.example.com is not allowed"}`,
+		},
+		{
+			name:           "Run pipeline via GET",
+			method:         http.MethodGet,
+			path:           "/api/v1/pipelines/run?name=dev",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   `{"status":"error","message":"method not allowed"}`,
+		},
+		{
+			name:           "Run pipeline from remote address",
+			method:         http.MethodPost,
+			path:           "/api/v1/pipelines/run?name=dev",
+			remoteAddr:     "10.0.0.5:51234",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":"error","message":"requests from 10.0.0.5 are not allowed"}`,
+		},
+		{
+			name:           "Run pipeline cross origin",
+			method:         http.MethodPost,
+			path:           "/api/v1/pipelines/run?name=dev",
+			origin:         "http://attacker.example.com",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":"error","message":"cross origin requests are not allowed"}`,
+		},
+		{
+			name:           "Run pipeline without session",
+			method:         http.MethodPost,
+			path:           "/api/v1/pipelines/run?name=dev",
+			origin:         "http://localhost:8090",
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"status":"error","message":"no DevSpace pipeline is running"}`,
+		},
+		{
+			name:           "Stop unknown forward",
+			method:         http.MethodPost,
+			path:           "/api/v1/forwards/stop?key=abc",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"status":"error","message":"port-forward abc not found"}`,
+		},
+		{
+			name:           "DevSpace logs cross origin",
+			method:         http.MethodGet,
+			path:           "/api/v1/logs/devspace?name=sync",
+			origin:         "http://attacker.example.com",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"status":"error","message":"cross origin requests are not allowed"}`,
+		},
+		{
+			name:           "Invalid log name",
+			method:         http.MethodGet,
+			path:           "/api/v1/logs/devspace?name=../secret",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "name is missing or invalid\n",
+		},
+	}
+
+	h := newTestHandler()
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(testCase.method, testCase.path, nil)
+		req.Host = "localhost:8090"
+		if testCase.host != "" {
+			req.Host = testCase.host
+		}
+		req.RemoteAddr = "127.0.0.1:51234"
+		if testCase.remoteAddr != "" {
+			req.RemoteAddr = testCase.remoteAddr
+		}
+		if testCase.origin != "" {
+			req.Header.Set("Origin", testCase.origin)
+		}
+
+		recorder := httptest.NewRecorder()
+		h.mux.ServeHTTP(recorder, req)
+		assert.Equal(t, recorder.Code, testCase.expectedStatus, "Unexpected status in testCase %s", testCase.name)
+		assert.Equal(t, recorder.Body.String(), testCase.expectedBody, "Unexpected body in testCase %s", testCase.name)
+	}
+}
+
+func TestAPIStopForward(t *testing.T) {
+	h := newTestHandler()
+	stopChan := make(chan struct{})
+	h.ports["abc"] = &forward{portForwarderStop: stopChan, portForwarderPort: 8080, podUUID: "uid"}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/forwards", nil)
+	req.Host = "localhost:8090"
+	recorder := httptest.NewRecorder()
+	h.mux.ServeHTTP(recorder, req)
+	forwards := []APIForward{}
+	assert.NilError(t, json.Unmarshal(recorder.Body.Bytes(), &forwards))
+	assert.DeepEqual(t, forwards, []APIForward{{Key: "abc", LocalPort: 8080, PodUID: "uid"}})
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/forwards/stop?key=abc", nil)
+	req.Host = "localhost:8090"
+	req.RemoteAddr = "127.0.0.1:51234"
+	recorder = httptest.NewRecorder()
+	h.mux.ServeHTTP(recorder, req)
+	assert.Equal(t, recorder.Code, http.StatusOK)
+	assert.Equal(t, recorder.Body.String(), `{"status":"stopped"}`)
+	assert.Equal(t, len(h.ports), 0)
+	select {
+	case <-stopChan:
+	default:
+		t.Fatal("port-forward was not stopped")
+	}
+
+	// a port-forward that stops doesn't remove a newer port-forward with the same key
+	h.ports["abc"] = &forward{portForwarderStop: make(chan struct{})}
+	h.deleteForward("abc", stopChan)
+	assert.Equal(t, len(h.ports), 1)
+}
+
+func TestAPIDevSpaceLogsOrigin(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, log.Logdir), 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, log.Logdir, "sync.log"), []byte("sync started"), 0644))
+
+	h := newTestHandler()
+	h.ctx = h.ctx.WithWorkingDir(dir)
+	server := httptest.NewServer(h.mux)
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	wsURL := "ws://" + host + "/api/v1/logs/devspace?name=sync"
+
+	// a website of another origin can't read the logs
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": []string{"http://attacker.example.com"}})
+	assert.Assert(t, err != nil)
+	assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+
+	// the ui of the server can
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": []string{"http://" + host}})
+	assert.NilError(t, err)
+	defer conn.Close()
+
+	_, message, err := conn.ReadMessage()
+	assert.NilError(t, err)
+	assert.Equal(t, string(message), "sync started")
+}
@@ -105,18 +105,13 @@ func (h *handler) forward(w http.ResponseWriter, r *http.Request) {
 			h.ctx.Log().Warnf("Error forwarding ports: %v", err)
 		}
 
-		h.portsMutex.Lock()
-		defer h.portsMutex.Unlock()
-
-		delete(h.ports, key)
+		h.deleteForward(key, stopChan)
 	}(key, checkPort)
 
 	go func(key string) {
 		err := <-errorChan
 		if err != nil {
-			h.portsMutex.Lock()
-			delete(h.ports, key)
-			h.portsMutex.Unlock()
+			h.deleteForward(key, stopChan)
 		}
 
 		pf.Close()
@@ -141,3 +136,13 @@ func (h *handler) forward(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// deleteForward removes the port-forward with the given key, if it wasn't replaced by a newer port-forward
+func (h *handler) deleteForward(key string, stopChan chan struct{}) {
+	h.portsMutex.Lock()
+	defer h.portsMutex.Unlock()
+
+	if f, ok := h.ports[key]; ok && f.portForwarderStop == stopChan {
+		delete(h.ports, key)
+	}
+}
@@ -160,6 +160,7 @@ func newHandler(ctx devspacecontext.Context, path string, pipeline types.Pipelin
 	handler.mux.HandleFunc("/api/enter", handler.enter)
 	handler.mux.HandleFunc("/api/resize", handler.resize)
 	handler.mux.HandleFunc("/api/logs", handler.logs)
+	handler.registerAPI()
 	return handler, nil
 }
 
@@ -190,11 +190,15 @@ func StartForwarding(ctx devspacecontext.Context, name string, portMappings []*l
 				ctx.Log().Errorf("Restarting because: %v", err)
 				shouldExit := sync.PrintPodError(ctx.Context(), ctx.KubeClient(), pod, ctx.Log())
 				pf.Close()
-				hook.LogExecuteHooks(ctx, map[string]interface{}{
+				abortErr := hook.LogExecuteHooks(ctx, map[string]interface{}{
 					"port_forwarding_config": portMappings,
 					"error":                  err,
 				}, hook.EventsForSingle("restart:portForwarding", name).With("portForwarding.restart")...)
-				if shouldExit {
+				if abortErr != nil {
+					parent.Kill(abortErr)
+					stopPortForwarding(ctx, name, portMappings, parent)
+					return abortErr
+				} else if shouldExit {
 					stopPortForwarding(ctx, name, portMappings, parent)
 					return nil
 				}
@@ -202,10 +206,15 @@ func StartForwarding(ctx devspacecontext.Context, name string, portMappings []*l
 				for {
 					err = StartForwarding(ctx, name, portMappings, selector, parent)
 					if err != nil {
-						hook.LogExecuteHooks(ctx, map[string]interface{}{
+						abortErr := hook.LogExecuteHooks(ctx, map[string]interface{}{
 							"port_forwarding_config": portMappings,
 							"error":                  err,
 						}, hook.EventsForSingle("restart:portForwarding", name).With("portForwarding.restart")...)
+						if abortErr != nil {
+							parent.Kill(abortErr)
+							stopPortForwarding(ctx, name, portMappings, parent)
+							return abortErr
+						}
 						ctx.Log().Errorf("Error restarting port-forwarding: %v", err)
 						ctx.Log().Errorf("Will try again in 15 seconds")
 
@@ -229,10 +238,10 @@ func StartForwarding(ctx devspacecontext.Context, name string, portMappings []*l
 }
 
 func stopPortForwarding(ctx devspacecontext.Context, name string, portMappings []*latest.PortMapping, parent *tomb.Tomb) {
-	hook.LogExecuteHooks(ctx, map[string]interface{}{
+	abortErr := hook.LogExecuteHooks(ctx, map[string]interface{}{
 		"port_forwarding_config": portMappings,
 	}, hook.EventsForSingle("stop:portForwarding", name).With("portForwarding.stop")...)
-	parent.Kill(nil)
+	parent.Kill(abortErr)
 	for _, m := range portMappings {
 		ctx.Log().Debugf("Stopped port forwarding %v", m.Port)
 	}
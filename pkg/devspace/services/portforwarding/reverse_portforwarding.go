@@ -72,11 +72,15 @@ func StartReversePortForwarding(ctx devspacecontext.Context, name, arch string,
 				close(closeChan)
 				_ = stdinWriter.Close()
 				_ = stdoutWriter.Close()
-				hook.LogExecuteHooks(ctx, map[string]interface{}{
+				abortErr := hook.LogExecuteHooks(ctx, map[string]interface{}{
 					"reverse_port_forwarding_config": portForwarding,
 					"error":                          err,
 				}, hook.EventsForSingle("restart:reversePortForwarding", name).With("reversePortForwarding.restart")...)
-				if shouldExit {
+				if abortErr != nil {
+					parent.Kill(abortErr)
+					doneReverseForwarding(ctx, name, portForwarding, parent)
+					return abortErr
+				} else if shouldExit {
 					doneReverseForwarding(ctx, name, portForwarding, parent)
 					return nil
 				}
@@ -84,10 +88,15 @@ func StartReversePortForwarding(ctx devspacecontext.Context, name, arch string,
 				for {
 					err = StartReversePortForwarding(ctx, name, arch, portForwarding, selector, parent)
 					if err != nil {
-						hook.LogExecuteHooks(ctx, map[string]interface{}{
+						abortErr := hook.LogExecuteHooks(ctx, map[string]interface{}{
 							"reverse_port_forwarding_config": portForwarding,
 							"error":                          err,
 						}, hook.EventsForSingle("restart:reversePortForwarding", name).With("reversePortForwarding.restart")...)
+						if abortErr != nil {
+							parent.Kill(abortErr)
+							doneReverseForwarding(ctx, name, portForwarding, parent)
+							return abortErr
+						}
 						ctx.Log().Errorf("Error restarting reverse port-forwarding: %v", err)
 						ctx.Log().Errorf("Will try again in 15 seconds")
 
@@ -111,10 +120,10 @@ func StartReversePortForwarding(ctx devspacecontext.Context, name, arch string,
 }
 
 func doneReverseForwarding(ctx devspacecontext.Context, name string, portForwarding []*latest.PortMapping, parent *tomb.Tomb) {
-	hook.LogExecuteHooks(ctx, map[string]interface{}{
+	abortErr := hook.LogExecuteHooks(ctx, map[string]interface{}{
 		"reverse_port_forwarding_config": portForwarding,
 	}, hook.EventsForSingle("stop:reversePortForwarding", name).With("reversePortForwarding.stop")...)
-	parent.Kill(nil)
+	parent.Kill(abortErr)
 	for _, m := range portForwarding {
 		ctx.Log().Debugf("Stopped reverse port forwarding %v", m.Port)
 	}
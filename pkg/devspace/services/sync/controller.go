@@ -188,10 +188,15 @@ func (c *controller) startWithWait(ctx devspacecontext.Context, options *Options
 					syncStop(ctx, client, options, parent)
 					return nil
 				}
-				hook.LogExecuteHooks(ctx.WithLogger(options.SyncLog), map[string]interface{}{
+				abortErr := hook.LogExecuteHooks(ctx.WithLogger(options.SyncLog), map[string]interface{}{
 					"sync_config": options.SyncConfig,
 					"ERROR":       err,
 				}, hook.EventsForSingle("restart:sync", options.Name).With("sync.restart")...)
+				if abortErr != nil {
+					parent.Kill(abortErr)
+					syncStop(ctx, client, options, parent)
+					return abortErr
+				}
 
 				ctx.Log().Errorf("Restarting because: %v", err)
 				shouldExit := PrintPodError(ctx.Context(), ctx.KubeClient(), pod.Pod, ctx.Log())
@@ -202,10 +207,15 @@ func (c *controller) startWithWait(ctx devspacecontext.Context, options *Options
 				for {
 					err := c.startWithWait(ctx.WithLogger(options.SyncLog), options, parent)
 					if err != nil {
-						hook.LogExecuteHooks(ctx.WithLogger(options.SyncLog), map[string]interface{}{
+						abortErr := hook.LogExecuteHooks(ctx.WithLogger(options.SyncLog), map[string]interface{}{
 							"sync_config": options.SyncConfig,
 							"ERROR":       err,
 						}, hook.EventsForSingle("restart:sync", options.Name).With("sync.restart")...)
+						if abortErr != nil {
+							parent.Kill(abortErr)
+							syncDone(ctx, options, parent)
+							return abortErr
+						}
 						options.SyncLog.Errorf("Error restarting sync: %v", err)
 						options.SyncLog.Errorf("Will try again in 15 seconds")
 
@@ -236,10 +246,10 @@ func syncStop(ctx devspacecontext.Context, syncClient *sync.Sync, options *Optio
 }
 
 func syncDone(ctx devspacecontext.Context, options *Options, parent *tomb.Tomb) {
-	parent.Kill(nil)
-	hook.LogExecuteHooks(ctx.WithLogger(options.SyncLog), map[string]interface{}{
+	abortErr := hook.LogExecuteHooks(ctx.WithLogger(options.SyncLog), map[string]interface{}{
 		"sync_config": options.SyncConfig,
 	}, hook.EventsForSingle("stop:sync", options.Name).With("sync.stop")...)
+	parent.Kill(abortErr)
 	ctx.Log().Debugf("Stopped sync %s", options.SyncConfig.Path)
 }
 
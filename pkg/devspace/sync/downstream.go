@@ -14,6 +14,7 @@ import (
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
 	"github.com/loft-sh/devspace/helper/util"
 	"github.com/loft-sh/devspace/pkg/util/fsutil"
+	"github.com/loft-sh/devspace/pkg/util/pathutil"
 
 	"github.com/fujiwara/shapeio"
 	"github.com/pkg/errors"
@@ -244,6 +245,9 @@ func (d *downstream) applyChanges(changes []*remote.Change, force bool) error {
 		}
 	}
 
+	// on case-insensitive file systems files that only differ in case would overwrite each other
+	download = d.skipCollisions(download)
+
 	// Remove all files and folders that should be deleted first and we ignore errors
 	d.remove(remove, force)
 
@@ -270,6 +274,33 @@ func (d *downstream) applyChanges(changes []*remote.Change, force bool) error {
 	return nil
 }
 
+func (d *downstream) skipCollisions(download []*remote.Change) []*remote.Change {
+	paths := make([]string, 0, len(download))
+	for _, change := range download {
+		paths = append(paths, change.Path)
+	}
+
+	collisions := pathutil.Collisions(paths)
+	if len(collisions) == 0 {
+		return download
+	}
+
+	skip := map[string]bool{}
+	for _, collision := range collisions {
+		d.sync.log.Warnf("Downstream - Skip download of '.%s', because it only differs in case from another file and the local file system is case-insensitive", collision)
+		skip[collision] = true
+	}
+
+	newChanges := make([]*remote.Change, 0, len(download))
+	for _, change := range download {
+		if !skip[change.Path] {
+			newChanges = append(newChanges, change)
+		}
+	}
+
+	return newChanges
+}
+
 func (d *downstream) updateDownloadChanges(download []*remote.Change) []*remote.Change {
 	d.sync.fileIndex.fileMapMutex.Lock()
 	defer d.sync.fileIndex.fileMapMutex.Unlock()
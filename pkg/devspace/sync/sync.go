@@ -13,6 +13,7 @@ import (
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/pathutil"
 
 	"github.com/loft-sh/notify"
 	"github.com/pkg/errors"
@@ -120,7 +121,7 @@ func NewSync(ctx context.Context, localPath string, options Options) (*Sync, err
 		ctx:       cancelCtx,
 		cancelCtx: cancel,
 
-		LocalPath: absoluteRealLocalPath,
+		LocalPath: pathutil.Normalize(absoluteRealLocalPath),
 		Options:   options,
 
 		fileIndex: newFileIndex(),
@@ -22,6 +22,7 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/pipeline/engine"
 	"github.com/loft-sh/devspace/pkg/util/fsutil"
+	"github.com/loft-sh/devspace/pkg/util/pathutil"
 
 	"github.com/loft-sh/utils/pkg/command"
 	"github.com/loft-sh/notify"
@@ -441,13 +442,13 @@ func (u *upstream) getFileInformationFromEvent(events []notify.EventInfo) ([]*Fi
 
 			// check if path is correct
 			fullPath := event.Path()
-			if !strings.HasPrefix(filepath.ToSlash(fullPath), filepath.ToSlash(u.sync.LocalPath)+"/") {
+			if pathutil.Equal(fullPath, u.sync.LocalPath) || !pathutil.HasPrefix(fullPath, u.sync.LocalPath) {
 				u.sync.log.Infof("Upstream - unexpected upload path %s", fullPath)
 				continue
 			}
 
 			// Determine what kind of change we got (Create or Remove)
-			relativePath := getRelativeFromFullPath(fullPath, u.sync.LocalPath)
+			relativePath := getRelativeFromFullPath(pathutil.Normalize(fullPath), u.sync.LocalPath)
 			newChanges, err := u.evaluateChange(relativePath, fullPath)
 			if err != nil {
 				return nil, errors.Wrap(err, "evaluate change")
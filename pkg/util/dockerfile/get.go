@@ -85,6 +85,36 @@ func GetPorts(filename string) ([]int, error) {
 	return ports, nil
 }
 
+// GetInstructions returns the instructions of a dockerfile in upper case, e.g. FROM or RUN
+func GetInstructions(filename string) ([]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	data = NormalizeNewlines(data)
+	lines := strings.Split(string(data), "\n")
+	instructions := []string{}
+	continued := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// skip the lines of an instruction that spans multiple lines
+		wasContinued := continued
+		continued = strings.HasSuffix(line, "\\")
+		if wasContinued || line == "" {
+			continue
+		}
+
+		instructions = append(instructions, strings.ToUpper(strings.Fields(line)[0]))
+	}
+
+	return instructions, nil
+}
+
 // NormalizeNewlines normalizes \r\n (windows) and \r (mac)
 // into \n (unix)
 func NormalizeNewlines(d []byte) []byte {
@@ -2,6 +2,7 @@ package dockerfile
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"gotest.tools/assert"
@@ -50,3 +51,21 @@ EXPOSE `))
 	assert.Equal(t, 8080, ports[0], "Wrong port returned")
 
 }
+
+func TestGetInstructions(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "Dockerfile")
+	err := os.WriteFile(filename, []byte(`# syntax=docker/dockerfile:1
+FROM alpine
+RUN apk add \
+    # the compiler
+    gcc \
+    make
+
+copy . .
+CMD ["make"]`), 0644)
+	assert.NilError(t, err)
+
+	instructions, err := GetInstructions(filename)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, instructions, []string{"FROM", "RUN", "COPY", "CMD"})
+}
@@ -3,6 +3,8 @@ package git
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/loft-sh/utils/pkg/command"
@@ -39,6 +41,20 @@ type CloneOptions struct {
 	Commit         string
 	Args           []string
 	DisableShallow bool
+
+	// Pinned is a commit of the branch or tag that is checked out instead of its latest
+	// commit. Unlike Commit, the branch or tag is still cloned shallow
+	Pinned string
+
+	// Depth is the depth of the shallow clone, defaults to 1
+	Depth int
+
+	// SparsePaths are the directories that are checked out. If empty, the complete
+	// repository is checked out
+	SparsePaths []string
+
+	// Submodules specifies if the submodules of the repository are cloned as well
+	Submodules bool
 }
 
 // Clone pulls the repository or clones it into the local path
@@ -60,8 +76,25 @@ func (gr *GitCLIRepository) Clone(ctx context.Context, options CloneOptions) err
 		}
 
 		// do a shallow clone by default
-		if options.Commit == "" && !options.DisableShallow {
-			args = append(args, "--depth", "1")
+		shallow := options.Commit == "" && !options.DisableShallow
+		if shallow {
+			depth := options.Depth
+			if depth <= 0 {
+				depth = 1
+			}
+
+			args = append(args, "--depth", strconv.Itoa(depth))
+		}
+
+		// only fetch the files of the checked out paths
+		if len(options.SparsePaths) > 0 {
+			args = append(args, "--filter=blob:none", "--sparse")
+		}
+		if options.Submodules {
+			args = append(args, "--recurse-submodules")
+			if shallow {
+				args = append(args, "--shallow-submodules")
+			}
 		}
 
 		args = append(args, options.Args...)
@@ -76,23 +109,141 @@ func (gr *GitCLIRepository) Clone(ctx context.Context, options CloneOptions) err
 			return errors.Errorf("Error running 'git %s': %v -> %s", strings.Join(args, " "), err, string(out))
 		}
 
+		// restrict the checkout to the sparse paths
+		err = gr.sparseCheckout(ctx, options.SparsePaths)
+		if err != nil {
+			return err
+		}
+
 		// checkout the commit if necessary
 		if options.Commit != "" {
 			out, err := command.CombinedOutput(ctx, gr.LocalPath, expand.ListEnviron(os.Environ()...), "git", "-C", gr.LocalPath, "checkout", options.Commit)
 			if err != nil {
 				return errors.Errorf("Error running 'git checkout %s': %v -> %s", options.Commit, err, string(out))
 			}
+
+			err = gr.updateSubmodules(ctx, options.Submodules)
+			if err != nil {
+				return err
+			}
+		} else if options.Pinned != "" {
+			return gr.checkoutPinned(ctx, options)
 		}
 
 		return nil
 	}
 
+	// the sparse paths might have changed since the repository was cloned
+	err = gr.sparseCheckout(ctx, options.SparsePaths)
+	if err != nil {
+		return err
+	}
+
 	// make sure the repo is up-to-date
-	if options.Commit == "" {
+	if options.Pinned != "" && options.Commit == "" {
+		return gr.checkoutPinned(ctx, options)
+	} else if options.Commit == "" {
+		if options.Tag == "" {
+			err = gr.checkoutBranch(ctx, options.Branch)
+			if err != nil {
+				return err
+			}
+		}
+
 		out, err := command.CombinedOutput(ctx, gr.LocalPath, expand.ListEnviron(os.Environ()...), "git", "-C", gr.LocalPath, "pull")
 		if err != nil {
 			return errors.Errorf("Error running 'git pull %s': %v -> %s", options.URL, err, string(out))
 		}
+
+		return gr.updateSubmodules(ctx, options.Submodules)
+	}
+
+	return nil
+}
+
+// checkoutPinned checks out the pinned commit. The commit is fetched first, if a shallow clone doesn't contain it yet
+func (gr *GitCLIRepository) checkoutPinned(ctx context.Context, options CloneOptions) error {
+	head, err := GetHash(ctx, gr.LocalPath)
+	if err == nil && head == options.Pinned {
+		return nil
+	}
+
+	_, err = command.CombinedOutput(ctx, gr.LocalPath, expand.ListEnviron(os.Environ()...), "git", "-C", gr.LocalPath, "cat-file", "-e", options.Pinned+"^{commit}")
+	if err != nil {
+		args := []string{"-C", gr.LocalPath, "fetch", "origin", options.Pinned}
+		if _, err := os.Stat(filepath.Join(gr.LocalPath, ".git", "shallow")); err == nil {
+			depth := options.Depth
+			if depth <= 0 {
+				depth = 1
+			}
+
+			args = append(args, "--depth", strconv.Itoa(depth))
+		}
+
+		gitEnv := append([]string{"GIT_TERMINAL_PROMPT=0", "GIT_SSH_COMMAND=ssh -oBatchMode=yes"}, os.Environ()...)
+		out, err := command.CombinedOutput(ctx, gr.LocalPath, expand.ListEnviron(gitEnv...), "git", args...)
+		if err != nil {
+			return errors.Errorf("Error running 'git fetch origin %s': %v -> %s", options.Pinned, err, string(out))
+		}
+	}
+
+	out, err := command.CombinedOutput(ctx, gr.LocalPath, expand.ListEnviron(os.Environ()...), "git", "-C", gr.LocalPath, "checkout", options.Pinned)
+	if err != nil {
+		return errors.Errorf("Error running 'git checkout %s': %v -> %s", options.Pinned, err, string(out))
+	}
+
+	return gr.updateSubmodules(ctx, options.Submodules)
+}
+
+// checkoutBranch checks out the branch again, if a pinned commit was checked out before
+func (gr *GitCLIRepository) checkoutBranch(ctx context.Context, branch string) error {
+	_, err := command.CombinedOutput(ctx, gr.LocalPath, expand.ListEnviron(os.Environ()...), "git", "-C", gr.LocalPath, "symbolic-ref", "-q", "HEAD")
+	if err == nil {
+		return nil
+	}
+
+	// use the default branch of the remote if no branch was specified
+	if branch == "" {
+		out, err := command.CombinedOutput(ctx, gr.LocalPath, expand.ListEnviron(os.Environ()...), "git", "-C", gr.LocalPath, "rev-parse", "--abbrev-ref", "origin/HEAD")
+		if err != nil {
+			return errors.Errorf("Error running 'git rev-parse --abbrev-ref origin/HEAD': %v -> %s", err, string(out))
+		}
+
+		branch = strings.TrimPrefix(strings.TrimSpace(string(out)), "origin/")
+	}
+
+	out, err := command.CombinedOutput(ctx, gr.LocalPath, expand.ListEnviron(os.Environ()...), "git", "-C", gr.LocalPath, "checkout", branch)
+	if err != nil {
+		return errors.Errorf("Error running 'git checkout %s': %v -> %s", branch, err, string(out))
+	}
+
+	return nil
+}
+
+// sparseCheckout restricts the working tree to the given directories
+func (gr *GitCLIRepository) sparseCheckout(ctx context.Context, sparsePaths []string) error {
+	if len(sparsePaths) == 0 {
+		return nil
+	}
+
+	args := append([]string{"-C", gr.LocalPath, "sparse-checkout", "set"}, sparsePaths...)
+	out, err := command.CombinedOutput(ctx, gr.LocalPath, expand.ListEnviron(os.Environ()...), "git", args...)
+	if err != nil {
+		return errors.Errorf("Error running 'git sparse-checkout set %s': %v -> %s", strings.Join(sparsePaths, " "), err, string(out))
+	}
+
+	return nil
+}
+
+// updateSubmodules checks out the submodules at the commits of the current revision
+func (gr *GitCLIRepository) updateSubmodules(ctx context.Context, submodules bool) error {
+	if !submodules {
+		return nil
+	}
+
+	out, err := command.CombinedOutput(ctx, gr.LocalPath, expand.ListEnviron(os.Environ()...), "git", "-C", gr.LocalPath, "submodule", "update", "--init", "--recursive")
+	if err != nil {
+		return errors.Errorf("Error running 'git submodule update': %v -> %s", err, string(out))
 	}
 
 	return nil
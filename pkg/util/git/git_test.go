@@ -2,6 +2,9 @@ package git
 
 import (
 	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 )
 
@@ -121,3 +124,148 @@ func TestGoGit(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestGitCliSparse(t *testing.T) {
+	// create a local repository with two services
+	sourceDir := t.TempDir()
+	for _, file := range []string{"devspace.yaml", "services/api/devspace.yaml", "services/web/devspace.yaml"} {
+		err := os.MkdirAll(filepath.Join(sourceDir, filepath.Dir(file)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(sourceDir, file), []byte("version: v2beta1"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", sourceDir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	tempDir := t.TempDir()
+	gitRepo, err := NewGitCLIRepository(context.Background(), tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := CloneOptions{
+		URL:         "file://" + filepath.ToSlash(sourceDir),
+		Depth:       1,
+		SparsePaths: []string{"services/api"},
+	}
+	err = gitRepo.Clone(context.Background(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for file, expected := range map[string]bool{
+		"devspace.yaml":              true,
+		"services/api/devspace.yaml": true,
+		"services/web/devspace.yaml": false,
+	} {
+		_, err := os.Stat(filepath.Join(tempDir, file))
+		if exists := err == nil; exists != expected {
+			t.Fatalf("Expected %s to exist: %v", file, expected)
+		}
+	}
+
+	// changed sparse paths are applied when pulling
+	options.SparsePaths = []string{"services/web"}
+	err = gitRepo.Clone(context.Background(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = os.Stat(filepath.Join(tempDir, "services/web/devspace.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitCliPinned(t *testing.T) {
+	// create a local repository with two commits
+	sourceDir := t.TempDir()
+	commits := []string{}
+	for _, content := range []string{"first", "second"} {
+		err := os.WriteFile(filepath.Join(sourceDir, "file"), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, args := range [][]string{
+			{"init", "-q", "-b", "main"},
+			{"add", "."},
+			{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", content},
+		} {
+			out, err := exec.Command("git", append([]string{"-C", sourceDir}, args...)...).CombinedOutput()
+			if err != nil {
+				t.Fatalf("git %v: %v: %s", args, err, out)
+			}
+		}
+
+		hash, err := GetHash(context.Background(), sourceDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commits = append(commits, hash)
+	}
+
+	tempDir := t.TempDir()
+	gitRepo, err := NewGitCLIRepository(context.Background(), tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the branch is cloned shallow and reset to the pinned commit
+	options := CloneOptions{
+		URL:    "file://" + filepath.ToSlash(sourceDir),
+		Branch: "main",
+		Pinned: commits[0],
+	}
+	err = gitRepo.Clone(context.Background(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectCheckout(t, tempDir, commits[0], "first")
+	if _, err := os.Stat(filepath.Join(tempDir, ".git", "shallow")); err != nil {
+		t.Fatalf("Expected a shallow clone: %v", err)
+	}
+
+	// without a pin the latest commit of the branch is pulled
+	options.Pinned = ""
+	err = gitRepo.Clone(context.Background(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectCheckout(t, tempDir, commits[1], "second")
+
+	// existing clones are reset to the pinned commit
+	options.Pinned = commits[0]
+	err = gitRepo.Clone(context.Background(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectCheckout(t, tempDir, commits[0], "first")
+}
+
+func expectCheckout(t *testing.T, dir, commit, content string) {
+	hash, err := GetHash(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	} else if hash != commit {
+		t.Fatalf("Wrong commit, got %s, expected %s", hash, commit)
+	}
+
+	out, err := os.ReadFile(filepath.Join(dir, "file"))
+	if err != nil {
+		t.Fatal(err)
+	} else if string(out) != content {
+		t.Fatalf("Wrong content, got %s, expected %s", string(out), content)
+	}
+}
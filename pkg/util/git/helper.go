@@ -2,6 +2,8 @@ package git
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"os"
@@ -57,6 +59,59 @@ func GetHash(ctx context.Context, localPath string) (string, error) {
 	return head.Hash().String(), nil
 }
 
+// GetDiffHash returns a hash of the last commit that changed the given pathspecs, the uncommitted changes
+// and the names of the untracked files of the repository. Only the changes within the pathspecs are considered,
+// so commits that only change other paths don't change the hash
+func GetDiffHash(ctx context.Context, localPath string, pathspecs []string) (string, error) {
+	environ := expand.ListEnviron(os.Environ()...)
+	commands := [][]string{
+		append([]string{"log", "-1", "--format=%H", "--"}, pathspecs...),
+		append([]string{"diff", "HEAD", "--"}, pathspecs...),
+		append([]string{"ls-files", "--others", "--exclude-standard", "--"}, pathspecs...),
+	}
+
+	hash := sha256.New()
+	for _, args := range commands {
+		out, err := command.Output(ctx, localPath, environ, "git", args...)
+		if err != nil {
+			return "", errors.Errorf("Error running 'git %s': %v", strings.Join(args, " "), err)
+		}
+
+		_, _ = hash.Write(out)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// GetRepositoryRoot returns the top level folder of the repository the given path belongs to
+func GetRepositoryRoot(ctx context.Context, localPath string) (string, error) {
+	out, err := command.Output(ctx, localPath, expand.ListEnviron(os.Environ()...), "git", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", errors.Errorf("Error running 'git rev-parse --show-toplevel': %v", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// AddWorktree checks out the given ref of the repository as detached worktree into the given
+// path. The returned function removes the worktree again
+func AddWorktree(ctx context.Context, localPath, ref, worktreePath string) (func() error, error) {
+	environ := expand.ListEnviron(os.Environ()...)
+	out, err := command.CombinedOutput(ctx, localPath, environ, "git", "worktree", "add", "--detach", worktreePath, ref)
+	if err != nil {
+		return nil, errors.Errorf("Error running 'git worktree add --detach %s %s': %v -> %s", worktreePath, ref, err, string(out))
+	}
+
+	return func() error {
+		out, err := command.CombinedOutput(context.Background(), localPath, environ, "git", "worktree", "remove", "--force", worktreePath)
+		if err != nil {
+			return errors.Errorf("Error running 'git worktree remove --force %s': %v -> %s", worktreePath, err, string(out))
+		}
+
+		return nil
+	}, nil
+}
+
 // GetRemote retrieves the remote origin
 func GetRemote(localPath string) (string, error) {
 	_, err := os.Stat(localPath + "/.git")
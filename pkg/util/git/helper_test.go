@@ -0,0 +1,66 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetDiffHash(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		out, err := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	writeFile := func(file, content string) {
+		err := os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(dir, file), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	diffHash := func() string {
+		hash, err := GetDiffHash(context.Background(), dir, []string{"api"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+
+	writeFile("api/main.go", "package main")
+	writeFile("web/index.html", "<html>")
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	initial := diffHash()
+
+	// commits outside of the pathspecs don't change the hash
+	writeFile("web/index.html", "<html></html>")
+	git("commit", "-q", "-am", "web")
+	if hash := diffHash(); hash != initial {
+		t.Fatal("Expected the hash to stay the same after a commit outside of the pathspecs")
+	}
+
+	// uncommitted changes, untracked files and commits within the pathspecs change the hash
+	writeFile("api/main.go", "package main\n")
+	changed := diffHash()
+	if changed == initial {
+		t.Fatal("Expected the hash to change with uncommitted changes")
+	}
+	git("commit", "-q", "-am", "api")
+	committed := diffHash()
+	if committed == initial || committed == changed {
+		t.Fatal("Expected the hash to change with a commit within the pathspecs")
+	}
+	writeFile("api/util.go", "package main")
+	if hash := diffHash(); hash == committed {
+		t.Fatal("Expected the hash to change with untracked files")
+	}
+}
@@ -1,6 +1,7 @@
 package hash
 
 import (
+	"bufio"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -14,7 +15,7 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/docker/docker/pkg/longpath"
+	"github.com/loft-sh/devspace/pkg/util/pathutil"
 	"github.com/pkg/errors"
 )
 
@@ -106,9 +107,7 @@ func DirectoryExcludes(srcPath string, excludePatterns []string, fast bool) (str
 
 	// Fix the source path to work with long path names. This is a no-op
 	// on platforms other than Windows.
-	if runtime.GOOS == "windows" {
-		srcPath = longpath.AddPrefix(srcPath)
-	}
+	srcPath = pathutil.LongPath(srcPath)
 
 	pm, err := patternmatcher.New(excludePatterns)
 	if err != nil {
@@ -195,6 +194,10 @@ func DirectoryExcludes(srcPath string, excludePatterns []string, fast bool) (str
 			return nil
 		}
 		seen[relFilePath] = true
+
+		// use the normalized path, so that the hash doesn't change if the drive letter
+		// or the casing of the path is different on windows
+		filePath = pathutil.Key(filePath)
 		if f.IsDir() {
 			// Path is enough
 			_, _ = io.WriteString(hash, filePath)
@@ -256,8 +259,17 @@ func hashFileCRC32(filePath string, polynomial uint32) (string, error) {
 	//Open a new hash interface to write the file to
 	hash := crc32.New(tablePolynomial)
 
-	//Copy the file in the interface
-	if _, err := io.Copy(hash, file); err != nil {
+	//Copy the file in the interface, on windows line endings of text files are
+	//normalized so that checkouts with autocrlf result in the same hash
+	reader := bufio.NewReaderSize(file, pathutil.TextDetectionSize)
+	if runtime.GOOS == "windows" && isTextFile(reader) {
+		writer := pathutil.NewLineEndingWriter(hash)
+		if _, err := io.Copy(writer, reader); err != nil {
+			return returnCRC32String, err
+		} else if err := writer.Close(); err != nil {
+			return returnCRC32String, err
+		}
+	} else if _, err := io.Copy(hash, reader); err != nil {
 		return returnCRC32String, err
 	}
 
@@ -270,3 +282,9 @@ func hashFileCRC32(filePath string, polynomial uint32) (string, error) {
 	//Return the output
 	return returnCRC32String, nil
 }
+
+// isTextFile peeks at the start of the file without consuming it
+func isTextFile(reader *bufio.Reader) bool {
+	head, _ := reader.Peek(pathutil.TextDetectionSize)
+	return pathutil.IsText(head)
+}
@@ -0,0 +1,108 @@
+package log
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StatusLogger is implemented by loggers that can keep a block of status lines below
+// their log output, such as the stdout logger in a terminal
+type StatusLogger interface {
+	// SetStatus replaces the status lines. Passing no lines removes the status block
+	SetStatus(lines []string)
+}
+
+// statusLines is the block of status lines of a terminal logger. It is shared with
+// all loggers that are derived from the same logger
+type statusLines struct {
+	m sync.Mutex
+
+	lines   []string
+	printed int
+
+	// partial is true if the last written message did not end with a new line
+	partial bool
+
+	// hidden is true while the terminal is handed to a question
+	hidden bool
+}
+
+// clear removes the printed status lines, so that the cursor is at the beginning
+// of the first status line
+func (s *statusLines) clear(w io.Writer) {
+	if s.printed == 0 {
+		return
+	}
+
+	_, _ = w.Write([]byte("\033[" + strconv.Itoa(s.printed) + "A\r\033[J"))
+	s.printed = 0
+}
+
+// draw prints the status lines below the current cursor position
+func (s *statusLines) draw(w io.Writer) {
+	if s.partial || s.hidden || len(s.lines) == 0 {
+		return
+	}
+
+	_, _ = w.Write([]byte(strings.Join(s.lines, "\n") + "\n"))
+	s.printed = len(s.lines)
+}
+
+// hide removes the status lines until show is called. Other loggers can still write
+// and update the status lines in the meantime, they are just not printed
+func (s *statusLines) hide(w io.Writer) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.clear(w)
+	s.hidden = true
+}
+
+// show prints the status lines again after hide
+func (s *statusLines) show(w io.Writer) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.hidden = false
+	s.draw(w)
+}
+
+// around removes the status lines while write is executed and prints them again afterwards.
+// If the written message does not end with a new line, the status lines are printed again
+// with the next complete line
+func (s *statusLines) around(w io.Writer, message []byte, write func()) {
+	if s == nil {
+		write()
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.clear(w)
+	write()
+	if len(message) > 0 {
+		s.partial = message[len(message)-1] != '\n'
+	}
+	s.draw(w)
+}
+
+// SetStatus implements the StatusLogger interface. The status is only shown if the
+// logger writes text to a terminal
+func (s *StreamLogger) SetStatus(lines []string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.status == nil || (s.format != TextFormat && s.format != TimeFormat) {
+		return
+	}
+
+	s.status.m.Lock()
+	defer s.status.m.Unlock()
+
+	s.status.clear(s.stream)
+	s.status.lines = lines
+	s.status.draw(s.stream)
+}
@@ -60,7 +60,7 @@ const (
 
 func NewStdoutLogger(stdin io.Reader, stdout, stderr io.Writer, level logrus.Level) Logger {
 	isTerminal, _ := terminal.SetupTTY(stdin, stdout)
-	return &StreamLogger{
+	logger := &StreamLogger{
 		m:           &sync.Mutex{},
 		level:       level,
 		format:      TextFormat,
@@ -69,6 +69,10 @@ func NewStdoutLogger(stdin io.Reader, stdout, stderr io.Writer, level logrus.Lev
 		errorStream: stderr,
 		survey:      survey.NewSurvey(),
 	}
+	if isTerminal {
+		logger.status = &statusLines{}
+	}
+	return logger
 }
 
 func NewStreamLogger(stdout, stderr io.Writer, level logrus.Level) Logger {
@@ -106,6 +110,9 @@ type StreamLogger struct {
 
 	survey survey.Survey
 
+	// status holds the status lines that are kept below the log output
+	status *statusLines
+
 	sinks []Logger
 }
 
@@ -286,25 +293,31 @@ func (s *StreamLogger) writeMessage(fnType logFunctionType, message string) {
 	}
 
 	if s.level >= fnInformation.logLevel {
-		stream := s.getStream(fnInformation.logLevel)
-		if s.format == RawFormat {
-			_, _ = stream.Write([]byte(message))
-		} else if s.format == TimeFormat {
-			if env.GlobalGetEnv(DevSpaceLogTimestamps) == "true" || s.level == logrus.DebugLevel {
-				now := time.Now()
-				_, _ = stream.Write([]byte(ansi.Color(formatInt(now.Hour())+":"+formatInt(now.Minute())+":"+formatInt(now.Second())+" ", "white+b")))
-			}
-			_, _ = stream.Write([]byte(message))
-		} else if s.format == TextFormat {
-			if env.GlobalGetEnv(DevSpaceLogTimestamps) == "true" || s.level == logrus.DebugLevel {
-				now := time.Now()
-				_, _ = stream.Write([]byte(ansi.Color(formatInt(now.Hour())+":"+formatInt(now.Minute())+":"+formatInt(now.Second())+" ", "white+b")))
-			}
-			_, _ = stream.Write([]byte(ansi.Color(fnInformation.tag, fnInformation.color)))
-			_, _ = stream.Write([]byte(message))
-		} else if s.format == JSONFormat {
-			s.writeJSON(message, fnInformation.logLevel)
+		s.status.around(s.stream, []byte(message), func() {
+			s.writeStream(fnInformation, message)
+		})
+	}
+}
+
+func (s *StreamLogger) writeStream(fnInformation *fnTypeInformation, message string) {
+	stream := s.getStream(fnInformation.logLevel)
+	if s.format == RawFormat {
+		_, _ = stream.Write([]byte(message))
+	} else if s.format == TimeFormat {
+		if env.GlobalGetEnv(DevSpaceLogTimestamps) == "true" || s.level == logrus.DebugLevel {
+			now := time.Now()
+			_, _ = stream.Write([]byte(ansi.Color(formatInt(now.Hour())+":"+formatInt(now.Minute())+":"+formatInt(now.Second())+" ", "white+b")))
+		}
+		_, _ = stream.Write([]byte(message))
+	} else if s.format == TextFormat {
+		if env.GlobalGetEnv(DevSpaceLogTimestamps) == "true" || s.level == logrus.DebugLevel {
+			now := time.Now()
+			_, _ = stream.Write([]byte(ansi.Color(formatInt(now.Hour())+":"+formatInt(now.Minute())+":"+formatInt(now.Second())+" ", "white+b")))
 		}
+		_, _ = stream.Write([]byte(ansi.Color(fnInformation.tag, fnInformation.color)))
+		_, _ = stream.Write([]byte(message))
+	} else if s.format == JSONFormat {
+		s.writeJSON(message, fnInformation.logLevel)
 	}
 }
 
@@ -493,7 +506,9 @@ func (s *StreamLogger) WriteString(level logrus.Level, message string) {
 	if s.level < level {
 		return
 	}
-	_, _ = s.write(level, []byte(message))
+	s.status.around(s.stream, []byte(message), func() {
+		_, _ = s.write(level, []byte(message))
+	})
 }
 
 func (s *StreamLogger) write(level logrus.Level, message []byte) (int, error) {
@@ -533,6 +548,12 @@ func (s *StreamLogger) Question(params *survey.QuestionOptions) (string, error)
 	}
 	defer ReleaseGlobalSilence(id)
 
+	// Hide the status lines while the question is asked
+	if s.status != nil {
+		s.status.hide(s.stream)
+		defer s.status.show(s.stream)
+	}
+
 	_, _ = s.write(logrus.InfoLevel, []byte("\n"))
 	return s.survey.Question(params)
 }
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TailBuffer is a writer that only keeps the last bytes written to it, so that the
+// output of a long running action can be shown on failure without holding all of it in memory
+type TailBuffer struct {
+	m sync.Mutex
+
+	// buffer is used as a ring, start is the position of the oldest byte
+	buffer []byte
+	start  int
+	size   int
+
+	// dropped is the number of bytes that were overwritten
+	dropped int64
+}
+
+// NewTailBuffer creates a buffer that keeps the last maxBytes bytes
+func NewTailBuffer(maxBytes int) *TailBuffer {
+	return &TailBuffer{
+		buffer: make([]byte, maxBytes),
+	}
+}
+
+// Write implements io.Writer
+func (t *TailBuffer) Write(p []byte) (int, error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	n := len(p)
+	if len(t.buffer) == 0 {
+		t.dropped += int64(n)
+		return n, nil
+	} else if len(p) > len(t.buffer) {
+		t.dropped += int64(len(p) - len(t.buffer))
+		p = p[len(p)-len(t.buffer):]
+	}
+
+	for len(p) > 0 {
+		end := (t.start + t.size) % len(t.buffer)
+		if t.size == len(t.buffer) {
+			// overwrite the oldest bytes
+			written := copy(t.buffer[t.start:], p)
+			t.start = (t.start + written) % len(t.buffer)
+			t.dropped += int64(written)
+			p = p[written:]
+			continue
+		}
+
+		free := len(t.buffer) - t.size
+		if end+free > len(t.buffer) {
+			free = len(t.buffer) - end
+		}
+		if free > len(p) {
+			free = len(p)
+		}
+
+		copy(t.buffer[end:], p[:free])
+		t.size += free
+		p = p[free:]
+	}
+
+	return n, nil
+}
+
+// Reset removes all bytes from the buffer
+func (t *TailBuffer) Reset() {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.start = 0
+	t.size = 0
+	t.dropped = 0
+}
+
+// String returns the kept bytes. If older bytes were dropped, the first incomplete line is
+// left out and a note with the number of dropped bytes is added instead
+func (t *TailBuffer) String() string {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	out := make([]byte, 0, t.size)
+	if t.start+t.size > len(t.buffer) {
+		out = append(out, t.buffer[t.start:]...)
+		out = append(out, t.buffer[:t.start+t.size-len(t.buffer)]...)
+	} else {
+		out = append(out, t.buffer[t.start:t.start+t.size]...)
+	}
+	if t.dropped == 0 {
+		return string(out)
+	}
+
+	dropped := t.dropped
+	if index := strings.IndexByte(string(out), '\n'); index >= 0 {
+		dropped += int64(index + 1)
+		out = out[index+1:]
+	}
+
+	return fmt.Sprintf("[%d bytes of earlier output omitted]\n%s", dropped, string(out))
+}
+
+// Sink returns a logger that writes the messages of the logger it is added to as sink into the
+// buffer. The messages are written without colors and without the level of the message
+func (t *TailBuffer) Sink() Logger {
+	return &tailSink{buffer: t}
+}
+
+// tailSink only implements the methods a logger calls on its sinks
+type tailSink struct {
+	DiscardLogger
+
+	buffer *TailBuffer
+}
+
+// Print implements logger interface
+func (s *tailSink) Print(level logrus.Level, args ...interface{}) {
+	_, _ = s.buffer.Write([]byte(stripEscapeSequences(fmt.Sprint(args...)) + "\n"))
+}
+
+// WriteString implements logger interface
+func (s *tailSink) WriteString(level logrus.Level, message string) {
+	_, _ = s.buffer.Write([]byte(stripEscapeSequences(message) + "\n"))
+}
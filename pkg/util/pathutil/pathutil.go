@@ -0,0 +1,196 @@
+package pathutil
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// maxPath is the maximum length of a path on windows without the long path prefix
+const maxPath = 260
+
+const (
+	longPathPrefix = `\\?\`
+	longUNCPrefix  = `\\?\UNC\`
+)
+
+// windows is a variable so the windows specific behaviour can be tested on other platforms
+var windows = runtime.GOOS == "windows"
+
+// Normalize returns a canonical form of the given path. On windows the long path prefix is removed,
+// forward slashes are converted to backslashes and the drive letter is upper cased, so that
+// c:/Project and C:\Project result in the same path. On other platforms the path is only cleaned
+func Normalize(p string) string {
+	if p == "" {
+		return p
+	} else if !windows {
+		return path.Clean(p)
+	}
+
+	// remove long path prefix
+	if strings.HasPrefix(p, longUNCPrefix) {
+		p = `\\` + p[len(longUNCPrefix):]
+	} else if strings.HasPrefix(p, longPathPrefix) {
+		p = p[len(longPathPrefix):]
+	}
+
+	// clean the path in slash form and keep the leading double slash of unc paths
+	p = strings.ReplaceAll(p, `\`, "/")
+	unc := strings.HasPrefix(p, "//")
+	volume := ""
+	if len(p) >= 2 && p[1] == ':' && isLetter(p[0]) {
+		volume = strings.ToUpper(p[:1]) + ":"
+		p = p[2:]
+	}
+	if p != "" {
+		p = path.Clean(p)
+	}
+	if unc {
+		p = "/" + p
+	}
+
+	return volume + strings.ReplaceAll(p, "/", `\`)
+}
+
+// Key returns a key for the given path that can be used to compare paths or store them in a map.
+// On windows paths are case-insensitive, so the key is the lower cased normalized path
+func Key(p string) string {
+	p = Normalize(p)
+	if windows {
+		return strings.ToLower(p)
+	}
+
+	return p
+}
+
+// Equal checks if both paths point to the same location
+func Equal(a, b string) bool {
+	return Key(a) == Key(b)
+}
+
+// HasPrefix checks if the given path is the prefix path itself or is located within it
+func HasPrefix(p, prefix string) bool {
+	p = Key(p)
+	prefix = Key(prefix)
+	if p == prefix {
+		return true
+	}
+
+	separator := "/"
+	if windows {
+		separator = `\`
+	}
+	return strings.HasPrefix(p, strings.TrimSuffix(prefix, separator)+separator)
+}
+
+// LongPath adds the long path prefix to absolute paths on windows that exceed the maximum
+// path length, which allows reading and walking deeply nested directories. On other
+// platforms the path is returned unchanged
+func LongPath(p string) string {
+	if !windows || len(p) < maxPath || strings.HasPrefix(p, longPathPrefix) {
+		return p
+	}
+
+	p = Normalize(p)
+	if strings.HasPrefix(p, `\\`) {
+		return longUNCPrefix + p[2:]
+	} else if len(p) >= 3 && p[1] == ':' && p[2] == '\\' {
+		return longPathPrefix + p
+	}
+
+	return p
+}
+
+// Collisions returns the paths that only differ in case from a path earlier in the list.
+// On platforms with case-sensitive file systems no collisions are returned
+func Collisions(paths []string) []string {
+	if !windows {
+		return nil
+	}
+
+	collisions := []string{}
+	seen := map[string]string{}
+	for _, p := range paths {
+		key := Key(p)
+		if existing, ok := seen[key]; ok {
+			if existing != p {
+				collisions = append(collisions, p)
+			}
+			continue
+		}
+
+		seen[key] = p
+	}
+
+	return collisions
+}
+
+// TextDetectionSize is the amount of bytes at the start of a file that IsText inspects
+const TextDetectionSize = 8000
+
+// IsText returns true if the given start of a file looks like text. Like git, files
+// that contain a NUL byte within the first TextDetectionSize bytes are treated as binary
+func IsText(head []byte) bool {
+	if len(head) > TextDetectionSize {
+		head = head[:TextDetectionSize]
+	}
+
+	return bytes.IndexByte(head, 0) == -1
+}
+
+// NormalizeLineEndings converts windows line endings to unix line endings
+func NormalizeLineEndings(data []byte) []byte {
+	return []byte(strings.ReplaceAll(string(data), "\r\n", "\n"))
+}
+
+// NewLineEndingWriter returns a writer that converts windows line endings to unix line
+// endings before writing to the underlying writer. This is useful for hashing text files
+// that might be checked out with different line endings. Close must be called to flush
+// a trailing carriage return
+func NewLineEndingWriter(w io.Writer) io.WriteCloser {
+	return &lineEndingWriter{w: w}
+}
+
+type lineEndingWriter struct {
+	w io.Writer
+
+	// pendingCR is true if the last written byte was a carriage return
+	pendingCR bool
+}
+
+func (l *lineEndingWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p)+1)
+	for _, b := range p {
+		if l.pendingCR && b != '\n' {
+			out = append(out, '\r')
+		}
+
+		l.pendingCR = b == '\r'
+		if !l.pendingCR {
+			out = append(out, b)
+		}
+	}
+
+	_, err := l.w.Write(out)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (l *lineEndingWriter) Close() error {
+	if l.pendingCR {
+		l.pendingCR = false
+		_, err := l.w.Write([]byte{'\r'})
+		return err
+	}
+
+	return nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
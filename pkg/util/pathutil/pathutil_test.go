@@ -0,0 +1,94 @@
+package pathutil
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func withWindows(t *testing.T) {
+	windowsBackup := windows
+	windows = true
+	t.Cleanup(func() {
+		windows = windowsBackup
+	})
+}
+
+func TestNormalizeWindows(t *testing.T) {
+	withWindows(t)
+
+	testCases := map[string]string{
+		`c:\Users\dev\project\`:         `C:\Users\dev\project`,
+		`C:/Users/dev/project/../other`: `C:\Users\dev\other`,
+		`\\?\c:\Users\dev\project`:      `C:\Users\dev\project`,
+		`\\?\UNC\server\share\project`:  `\\server\share\project`,
+		`//server/share/project/`:       `\\server\share\project`,
+		`C:\`:                           `C:\`,
+		`project\sub`:                   `project\sub`,
+		``:                              ``,
+	}
+	for in, expected := range testCases {
+		assert.Equal(t, Normalize(in), expected, in)
+	}
+
+	assert.Assert(t, Equal(`c:\Users\Dev\Project`, `C:/users/dev/project/`))
+	assert.Assert(t, HasPrefix(`c:\users\dev\project\file.txt`, `C:\Users\Dev\Project`))
+	assert.Assert(t, HasPrefix(`C:\Users\Dev\Project`, `C:\Users\Dev\Project\`))
+	assert.Assert(t, !HasPrefix(`C:\Users\Dev\Project2\file.txt`, `C:\Users\Dev\Project`))
+}
+
+func TestNormalizeUnix(t *testing.T) {
+	windowsBackup := windows
+	windows = false
+	defer func() {
+		windows = windowsBackup
+	}()
+
+	assert.Equal(t, Normalize("/home/dev/project/"), "/home/dev/project")
+	assert.Assert(t, !Equal("/home/dev/Project", "/home/dev/project"))
+	assert.Assert(t, HasPrefix("/home/dev/project/file.txt", "/home/dev/project"))
+	assert.Assert(t, !HasPrefix("/home/dev/project2", "/home/dev/project"))
+	assert.Equal(t, len(Collisions([]string{"/README.md", "/readme.md"})), 0)
+	assert.Equal(t, LongPath("/"+strings.Repeat("a", 300)), "/"+strings.Repeat("a", 300))
+}
+
+func TestLongPath(t *testing.T) {
+	withWindows(t)
+
+	long := strings.Repeat(`\directory`, 30)
+	assert.Equal(t, LongPath(`C:\short`), `C:\short`)
+	assert.Equal(t, LongPath(`c:`+long), `\\?\C:`+long)
+	assert.Equal(t, LongPath(`\\?\C:`+long), `\\?\C:`+long)
+	assert.Equal(t, LongPath(`\\server\share`+long), `\\?\UNC\server\share`+long)
+}
+
+func TestCollisions(t *testing.T) {
+	withWindows(t)
+
+	collisions := Collisions([]string{"/README.md", "/docs/index.md", "/readme.md", "/README.md", "/Docs/Index.md"})
+	assert.DeepEqual(t, collisions, []string{"/readme.md", "/Docs/Index.md"})
+}
+
+func TestIsText(t *testing.T) {
+	assert.Assert(t, IsText([]byte("line1\r\nline2\r\n")))
+	assert.Assert(t, IsText(nil))
+	assert.Assert(t, !IsText([]byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00}))
+
+	// only the start of the file is inspected
+	assert.Assert(t, IsText(append(bytes.Repeat([]byte("a"), TextDetectionSize), 0)))
+}
+
+func TestLineEndings(t *testing.T) {
+	assert.Equal(t, string(NormalizeLineEndings([]byte("a\r\nb\rc\n"))), "a\nb\rc\n")
+
+	buf := &bytes.Buffer{}
+	writer := NewLineEndingWriter(buf)
+	for _, part := range []string{"line1\r", "\nline2\r", "x\r\n", "end\r"} {
+		_, err := writer.Write([]byte(part))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, writer.Close())
+	assert.Equal(t, buf.String(), "line1\nline2\rx\nend\r")
+}
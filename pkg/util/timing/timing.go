@@ -0,0 +1,180 @@
+package timing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type spanKey struct{}
+
+var (
+	enabled   bool
+	startTime time.Time
+
+	spansMutex sync.Mutex
+	spans      []*Span
+)
+
+// Span is a single timed phase of a DevSpace command
+type Span struct {
+	Name       string    `json:"name"`
+	Start      time.Time `json:"start"`
+	DurationMs int64     `json:"durationMs"`
+	Children   []*Span   `json:"children,omitempty"`
+
+	duration time.Duration
+}
+
+// Report is the performance report that is written to disk
+type Report struct {
+	Command    string    `json:"command"`
+	Start      time.Time `json:"start"`
+	DurationMs int64     `json:"durationMs"`
+	Spans      []*Span   `json:"spans"`
+}
+
+// Enable turns on the timing of phases, by default no spans are recorded
+func Enable() {
+	spansMutex.Lock()
+	defer spansMutex.Unlock()
+
+	enabled = true
+	startTime = time.Now()
+}
+
+// Enabled returns if timing is enabled
+func Enabled() bool {
+	spansMutex.Lock()
+	defer spansMutex.Unlock()
+
+	return enabled
+}
+
+// Start starts a new span with the given name. If the context already holds a span,
+// the new span is added as a child of it. The returned function ends the span
+func Start(ctx context.Context, name string) (context.Context, func()) {
+	if !Enabled() {
+		return ctx, func() {}
+	}
+
+	span := &Span{
+		Name:  name,
+		Start: time.Now(),
+	}
+
+	spansMutex.Lock()
+	var parent *Span
+	if ctx != nil {
+		parent, _ = ctx.Value(spanKey{}).(*Span)
+	}
+	if parent != nil {
+		parent.Children = append(parent.Children, span)
+	} else {
+		spans = append(spans, span)
+	}
+	spansMutex.Unlock()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	once := sync.Once{}
+	return context.WithValue(ctx, spanKey{}, span), func() {
+		once.Do(func() {
+			spansMutex.Lock()
+			defer spansMutex.Unlock()
+
+			span.duration = time.Since(span.Start)
+			span.DurationMs = span.duration.Milliseconds()
+		})
+	}
+}
+
+// GetReport returns the report with all spans recorded so far
+func GetReport(command string) *Report {
+	spansMutex.Lock()
+	defer spansMutex.Unlock()
+
+	report := &Report{
+		Command:    command,
+		Start:      startTime,
+		DurationMs: time.Since(startTime).Milliseconds(),
+		Spans:      make([]*Span, 0, len(spans)),
+	}
+	for _, span := range spans {
+		report.Spans = append(report.Spans, span.copy())
+	}
+
+	return report
+}
+
+// copy copies the span and sets the duration of unfinished spans to the time until now
+func (s *Span) copy() *Span {
+	n := &Span{
+		Name:       s.Name,
+		Start:      s.Start,
+		DurationMs: s.DurationMs,
+		duration:   s.duration,
+	}
+	if n.duration == 0 {
+		n.duration = time.Since(s.Start)
+		n.DurationMs = n.duration.Milliseconds()
+	}
+	for _, child := range s.Children {
+		n.Children = append(n.Children, child.copy())
+	}
+
+	return n
+}
+
+// WriteReport writes the report as json to the given path and in the folded stack format next to it,
+// which can be used with flamegraph tools such as flamegraph.pl or speedscope
+func WriteReport(path, command string) error {
+	report := GetReport(command)
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path, out, 0644)
+	if err != nil {
+		return err
+	}
+
+	lines := []string{}
+	for _, span := range report.Spans {
+		lines = append(lines, span.folded(sanitize(command))...)
+	}
+	sort.Strings(lines)
+
+	foldedPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".folded"
+	return os.WriteFile(foldedPath, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// folded returns the folded stack lines of the span and its children, the value of each line
+// is the time in milliseconds the span spent itself
+func (s *Span) folded(prefix string) []string {
+	stack := prefix + ";" + sanitize(s.Name)
+	self := s.duration
+	lines := []string{}
+	for _, child := range s.Children {
+		self -= child.duration
+		lines = append(lines, child.folded(stack)...)
+	}
+	if self < 0 {
+		self = 0
+	}
+
+	return append(lines, fmt.Sprintf("%s %d", stack, self.Milliseconds()))
+}
+
+func sanitize(name string) string {
+	return strings.NewReplacer(";", "_", " ", "_", "\n", "_").Replace(name)
+}
@@ -0,0 +1,59 @@
+package timing
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestTiming(t *testing.T) {
+	// spans are not recorded if timing is disabled
+	_, done := Start(context.Background(), "disabled")
+	done()
+	assert.Equal(t, len(GetReport("test").Spans), 0)
+
+	Enable()
+	defer func() {
+		enabled = false
+		spans = nil
+	}()
+
+	ctx, done := Start(context.Background(), "deploy app")
+	_, childDone := Start(ctx, "build image")
+	childDone()
+	done()
+	_, done = Start(context.Background(), "load config")
+	done()
+
+	report := GetReport("dev")
+	assert.Equal(t, len(report.Spans), 2)
+	assert.Equal(t, report.Spans[0].Name, "deploy app")
+	assert.Equal(t, len(report.Spans[0].Children), 1)
+	assert.Equal(t, report.Spans[0].Children[0].Name, "build image")
+	assert.Equal(t, report.Spans[1].Name, "load config")
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	err := WriteReport(path, "dev")
+	assert.NilError(t, err)
+
+	out, err := os.ReadFile(path)
+	assert.NilError(t, err)
+	written := &Report{}
+	err = json.Unmarshal(out, written)
+	assert.NilError(t, err)
+	assert.Equal(t, written.Command, "dev")
+	assert.Equal(t, len(written.Spans), 2)
+
+	out, err = os.ReadFile(filepath.Join(filepath.Dir(path), "report.folded"))
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	assert.Equal(t, len(lines), 3)
+	assert.Assert(t, strings.HasPrefix(lines[0], "dev;deploy_app "))
+	assert.Assert(t, strings.HasPrefix(lines[1], "dev;deploy_app;build_image "))
+	assert.Assert(t, strings.HasPrefix(lines[2], "dev;load_config "))
+}
@@ -0,0 +1,43 @@
+package tomb
+
+// GoLimited runs f for every index from 0 to n in tracked goroutines,
+// with at most limit goroutines running at the same time. A limit of
+// 0 runs all of them at once. No new goroutines are started once the
+// tomb is dying
+func (t *Tomb) GoLimited(limit, n int, f func(i int) error) {
+	var slots chan struct{}
+	if limit > 0 {
+		slots = make(chan struct{}, limit)
+	}
+
+	t.Go(func() error {
+		for i := 0; i < n; i++ {
+			if slots != nil {
+				select {
+				case slots <- struct{}{}:
+				case <-t.Dying():
+					return nil
+				}
+			}
+			if !t.Alive() {
+				return nil
+			}
+
+			i := i
+			t.Go(func() error {
+				err := f(i)
+				if slots != nil {
+					// kill the tomb before the slot is freed, so that no
+					// further goroutine is started after an error
+					if err != nil {
+						t.Kill(err)
+					}
+					<-slots
+				}
+
+				return err
+			})
+		}
+		return nil
+	})
+}
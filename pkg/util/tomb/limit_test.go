@@ -0,0 +1,63 @@
+package tomb
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestGoLimited(t *testing.T) {
+	var (
+		m       sync.Mutex
+		running = 0
+		maxSeen = 0
+		ran     = 0
+	)
+
+	tomb := &Tomb{}
+	tomb.GoLimited(2, 6, func(i int) error {
+		m.Lock()
+		running++
+		ran++
+		if running > maxSeen {
+			maxSeen = running
+		}
+		m.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		m.Lock()
+		running--
+		m.Unlock()
+		return nil
+	})
+
+	assert.NilError(t, tomb.Wait())
+	assert.Equal(t, ran, 6)
+	assert.Equal(t, maxSeen, 2)
+}
+
+func TestGoLimitedStopsOnError(t *testing.T) {
+	var (
+		m   sync.Mutex
+		ran = 0
+	)
+
+	tomb := &Tomb{}
+	tomb.GoLimited(1, 5, func(i int) error {
+		m.Lock()
+		ran++
+		m.Unlock()
+
+		if i == 1 {
+			return fmt.Errorf("failed %d", i)
+		}
+		return nil
+	})
+
+	assert.Error(t, tomb.Wait(), "failed 1")
+	assert.Equal(t, ran, 2)
+}